@@ -0,0 +1,450 @@
+package main
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+)
+
+type inputKind string
+
+const (
+	kindAuto        inputKind = "auto"
+	kindResponse    inputKind = "response"
+	kindClientData  inputKind = "clientdata"
+	kindAuthData    inputKind = "authdata"
+	kindAttestation inputKind = "attestation"
+	kindCOSEKey     inputKind = "cosekey"
+)
+
+var errUnknownInput = errors.New("unable to detect the type of the input, use the -type flag to provide it")
+
+type credentialResponse struct {
+	ID                     string                                         `json:"id"`
+	Type                   string                                         `json:"type"`
+	ClientData             interface{}                                    `json:"clientData"`
+	AttestationObject      *attestationObject                             `json:"attestationObject,omitempty"`
+	AuthenticatorData      *authenticatorData                             `json:"authenticatorData,omitempty"`
+	Signature              string                                         `json:"signature,omitempty"`
+	UserHandle             string                                         `json:"userHandle,omitempty"`
+	Transports             []string                                       `json:"transports,omitempty"`
+	ClientExtensionResults protocol.AuthenticationExtensionsClientOutputs `json:"clientExtensionResults,omitempty"`
+}
+
+type authenticatorData struct {
+	RPIDHash               string                  `json:"rpIdHash"`
+	Flags                  authenticatorFlags      `json:"flags"`
+	SignCount              uint32                  `json:"signCount"`
+	AttestedCredentialData *attestedCredentialData `json:"attestedCredentialData,omitempty"`
+	Extensions             interface{}             `json:"extensions,omitempty"`
+}
+
+type authenticatorFlags struct {
+	Value string   `json:"value"`
+	Names []string `json:"names"`
+}
+
+type attestedCredentialData struct {
+	AAGUID              string   `json:"aaguid"`
+	CredentialID        string   `json:"credentialId"`
+	CredentialPublicKey *coseKey `json:"credentialPublicKey"`
+}
+
+type attestationObject struct {
+	Format       string             `json:"fmt"`
+	Statement    interface{}        `json:"attStmt"`
+	Certificates []certificate      `json:"certificates,omitempty"`
+	AuthData     *authenticatorData `json:"authData"`
+}
+
+type certificate struct {
+	Subject      string `json:"subject"`
+	Issuer       string `json:"issuer"`
+	SerialNumber string `json:"serialNumber"`
+	NotBefore    string `json:"notBefore"`
+	NotAfter     string `json:"notAfter"`
+}
+
+type coseKey struct {
+	KeyType    string      `json:"kty"`
+	Algorithm  string      `json:"alg"`
+	Curve      string      `json:"crv,omitempty"`
+	Parameters interface{} `json:"parameters"`
+	PEM        string      `json:"pem,omitempty"`
+}
+
+var flagNames = []struct {
+	flag protocol.AuthenticatorFlags
+	name string
+}{
+	{protocol.FlagUserPresent, "UP"},
+	{protocol.FlagRFU1, "RFU1"},
+	{protocol.FlagUserVerified, "UV"},
+	{protocol.FlagBackupEligible, "BE"},
+	{protocol.FlagBackupState, "BS"},
+	{protocol.FlagRFU2, "RFU2"},
+	{protocol.FlagAttestedCredentialData, "AT"},
+	{protocol.FlagHasExtensions, "ED"},
+}
+
+var keyTypeNames = map[int64]string{
+	int64(webauthncose.OctetKey):    "OKP",
+	int64(webauthncose.EllipticKey): "EC2",
+	int64(webauthncose.RSAKey):      "RSA",
+}
+
+var curveNames = map[int64]string{
+	int64(webauthncose.P256):      "P-256",
+	int64(webauthncose.P384):      "P-384",
+	int64(webauthncose.P521):      "P-521",
+	int64(webauthncose.X25519):    "X25519",
+	int64(webauthncose.X448):      "X448",
+	int64(webauthncose.Ed25519):   "Ed25519",
+	int64(webauthncose.Ed448):     "Ed448",
+	int64(webauthncose.Secp256k1): "secp256k1",
+}
+
+var algorithmNames = map[int64]string{
+	int64(webauthncose.AlgES256):  "ES256",
+	int64(webauthncose.AlgES384):  "ES384",
+	int64(webauthncose.AlgES512):  "ES512",
+	int64(webauthncose.AlgRS1):    "RS1",
+	int64(webauthncose.AlgRS256):  "RS256",
+	int64(webauthncose.AlgRS384):  "RS384",
+	int64(webauthncose.AlgRS512):  "RS512",
+	int64(webauthncose.AlgPS256):  "PS256",
+	int64(webauthncose.AlgPS384):  "PS384",
+	int64(webauthncose.AlgPS512):  "PS512",
+	int64(webauthncose.AlgEdDSA):  "EdDSA",
+	int64(webauthncose.AlgES256K): "ES256K",
+}
+
+// decode the input of the provided kind, detecting the kind if it's kindAuto.
+func decode(kind inputKind, input string) (interface{}, error) {
+	if kind == kindResponse || (kind == kindAuto && strings.HasPrefix(input, "{")) {
+		return decodeResponse([]byte(input))
+	}
+
+	raw, err := decodeBase64(input)
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case kindClientData:
+		return decodeClientData(raw)
+	case kindAuthData:
+		return decodeAuthenticatorData(raw)
+	case kindAttestation:
+		return decodeAttestationObject(raw)
+	case kindCOSEKey:
+		return decodeCOSEKey(raw)
+	case kindAuto:
+		return decodeAuto(raw)
+	default:
+		return nil, fmt.Errorf("unknown type %q", kind)
+	}
+}
+
+// decodeAuto tries each kind of binary input in order of how specific its structure is.
+func decodeAuto(raw []byte) (interface{}, error) {
+	if json.Valid(raw) {
+		return decodeClientData(raw)
+	}
+
+	if decoded, err := decodeAttestationObject(raw); err == nil {
+		return decoded, nil
+	}
+
+	if decoded, err := decodeCOSEKey(raw); err == nil {
+		return decoded, nil
+	}
+
+	if decoded, err := decodeAuthenticatorData(raw); err == nil {
+		return decoded, nil
+	}
+
+	return nil, errUnknownInput
+}
+
+func decodeResponse(raw []byte) (*credentialResponse, error) {
+	var response struct {
+		protocol.PublicKeyCredential
+
+		Response struct {
+			protocol.AuthenticatorResponse
+
+			AttestationObject protocol.URLEncodedBase64 `json:"attestationObject"`
+			AuthenticatorData protocol.URLEncodedBase64 `json:"authenticatorData"`
+			Signature         protocol.URLEncodedBase64 `json:"signature"`
+			UserHandle        protocol.URLEncodedBase64 `json:"userHandle"`
+			Transports        []string                  `json:"transports"`
+		} `json:"response"`
+	}
+
+	if err := json.Unmarshal(raw, &response); err != nil {
+		return nil, fmt.Errorf("error decoding the credential response: %w", err)
+	}
+
+	decoded := &credentialResponse{
+		ID:                     response.ID,
+		Type:                   response.Type,
+		Signature:              encodeBase64(response.Response.Signature),
+		UserHandle:             encodeBase64(response.Response.UserHandle),
+		Transports:             response.Response.Transports,
+		ClientExtensionResults: response.ClientExtensionResults,
+	}
+
+	var err error
+
+	if decoded.ClientData, err = decodeClientData(response.Response.ClientDataJSON); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(response.Response.AttestationObject) != 0:
+		decoded.AttestationObject, err = decodeAttestationObject(response.Response.AttestationObject)
+	case len(response.Response.AuthenticatorData) != 0:
+		decoded.AuthenticatorData, err = decodeAuthenticatorData(response.Response.AuthenticatorData)
+	default:
+		err = errors.New("the credential response contains neither an attestation object nor authenticator data")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return decoded, nil
+}
+
+// decodeClientData decodes the clientDataJSON into a generic value so that every member is displayed, including
+// those which are unknown to the protocol package.
+func decodeClientData(raw []byte) (clientData interface{}, err error) {
+	if err = json.Unmarshal(raw, &clientData); err != nil {
+		return nil, fmt.Errorf("error decoding the client data: %w", err)
+	}
+
+	return clientData, nil
+}
+
+func decodeAuthenticatorData(raw []byte) (*authenticatorData, error) {
+	var data protocol.AuthenticatorData
+
+	if err := data.Unmarshal(raw); err != nil {
+		return nil, fmt.Errorf("error decoding the authenticator data: %w", err)
+	}
+
+	decoded := &authenticatorData{
+		RPIDHash:  hex.EncodeToString(data.RPIDHash),
+		Flags:     decodeFlags(data.Flags),
+		SignCount: data.Counter,
+	}
+
+	if data.Flags.HasAttestedCredentialData() {
+		key, err := decodeCOSEKey(data.AttData.CredentialPublicKey)
+		if err != nil {
+			return nil, err
+		}
+
+		aaguid, err := uuid.FromBytes(data.AttData.AAGUID)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding the AAGUID: %w", err)
+		}
+
+		decoded.AttestedCredentialData = &attestedCredentialData{
+			AAGUID:              aaguid.String(),
+			CredentialID:        encodeBase64(data.AttData.CredentialID),
+			CredentialPublicKey: key,
+		}
+	}
+
+	if data.Flags.HasExtensions() {
+		var extensions interface{}
+
+		if err := webauthncbor.Unmarshal(data.ExtData, &extensions); err != nil {
+			return nil, fmt.Errorf("error decoding the extensions: %w", err)
+		}
+
+		decoded.Extensions = jsonValue(extensions)
+	}
+
+	return decoded, nil
+}
+
+func decodeFlags(flags protocol.AuthenticatorFlags) authenticatorFlags {
+	decoded := authenticatorFlags{
+		Value: fmt.Sprintf("0x%02x", byte(flags)),
+		Names: []string{},
+	}
+
+	for _, f := range flagNames {
+		if flags&f.flag == f.flag {
+			decoded.Names = append(decoded.Names, f.name)
+		}
+	}
+
+	return decoded
+}
+
+func decodeAttestationObject(raw []byte) (*attestationObject, error) {
+	var object protocol.AttestationObject
+
+	if err := webauthncbor.Unmarshal(raw, &object); err != nil {
+		return nil, fmt.Errorf("error decoding the attestation object: %w", err)
+	}
+
+	if object.Format == "" || len(object.RawAuthData) == 0 {
+		return nil, errors.New("error decoding the attestation object: the fmt or authData is missing")
+	}
+
+	authData, err := decodeAuthenticatorData(object.RawAuthData)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded := &attestationObject{
+		Format:    object.Format,
+		Statement: jsonValue(object.AttStatement),
+		AuthData:  authData,
+	}
+
+	if x5c, ok := object.AttStatement["x5c"].([]interface{}); ok {
+		for _, c := range x5c {
+			der, ok := c.([]byte)
+			if !ok {
+				return nil, errors.New("error decoding the attestation statement: the x5c contains a value which is not a certificate")
+			}
+
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return nil, fmt.Errorf("error decoding the attestation statement certificate: %w", err)
+			}
+
+			decoded.Certificates = append(decoded.Certificates, certificate{
+				Subject:      cert.Subject.String(),
+				Issuer:       cert.Issuer.String(),
+				SerialNumber: cert.SerialNumber.String(),
+				NotBefore:    cert.NotBefore.UTC().Format(time.RFC3339),
+				NotAfter:     cert.NotAfter.UTC().Format(time.RFC3339),
+			})
+		}
+	}
+
+	return decoded, nil
+}
+
+func decodeCOSEKey(raw []byte) (*coseKey, error) {
+	var parameters interface{}
+
+	if err := webauthncbor.Unmarshal(raw, &parameters); err != nil {
+		return nil, fmt.Errorf("error decoding the COSE key: %w", err)
+	}
+
+	var data webauthncose.PublicKeyData
+
+	if err := webauthncbor.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("error decoding the COSE key: %w", err)
+	}
+
+	if data.KeyType == 0 || data.Algorithm == 0 {
+		return nil, errors.New("error decoding the COSE key: the kty or alg is missing")
+	}
+
+	decoded := &coseKey{
+		KeyType:    name(keyTypeNames, data.KeyType),
+		Algorithm:  name(algorithmNames, data.Algorithm),
+		Parameters: jsonValue(parameters),
+	}
+
+	if key, err := webauthncose.ParsePublicKey(raw); err == nil {
+		switch k := key.(type) {
+		case webauthncose.EC2PublicKeyData:
+			decoded.Curve = name(curveNames, k.Curve)
+		case webauthncose.OKPPublicKeyData:
+			decoded.Curve = name(curveNames, k.Curve)
+		}
+
+		if pem := webauthncose.DisplayPublicKey(raw); strings.HasPrefix(pem, "-----BEGIN") {
+			decoded.PEM = pem
+		}
+	}
+
+	return decoded, nil
+}
+
+// name returns the name of a registered value, or the value itself followed by unknown.
+func name(names map[int64]string, value int64) string {
+	if n, ok := names[value]; ok {
+		return n
+	}
+
+	return fmt.Sprintf("%v (unknown)", value)
+}
+
+// jsonValue converts a decoded CBOR value into a value which can be encoded as JSON. Maps with non-string keys have
+// their keys formatted as strings and byte strings are base64url encoded.
+func jsonValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return encodeBase64(value)
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Map:
+		converted := make(map[string]interface{}, rv.Len())
+
+		for _, key := range rv.MapKeys() {
+			converted[fmt.Sprint(key.Interface())] = jsonValue(rv.MapIndex(key).Interface())
+		}
+
+		return converted
+	case reflect.Slice, reflect.Array:
+		converted := make([]interface{}, rv.Len())
+
+		for i := range converted {
+			converted[i] = jsonValue(rv.Index(i).Interface())
+		}
+
+		return converted
+	default:
+		return v
+	}
+}
+
+// decodeBase64 decodes standard or URL safe base64 with or without padding.
+func decodeBase64(input string) ([]byte, error) {
+	input = strings.TrimRight(input, "=")
+
+	encoding := base64.RawURLEncoding
+
+	if strings.ContainsAny(input, "+/") {
+		encoding = base64.RawStdEncoding
+	}
+
+	raw, err := encoding.DecodeString(input)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding the base64 input: %w", err)
+	}
+
+	return raw, nil
+}
+
+func encodeBase64(raw []byte) string {
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/webauthntest/vectors"
+)
+
+type testResponse struct {
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON"`
+		AttestationObject string `json:"attestationObject"`
+		AuthenticatorData string `json:"authenticatorData"`
+	} `json:"response"`
+}
+
+func TestDecodeVectors(t *testing.T) {
+	all, err := vectors.All()
+	require.NoError(t, err)
+
+	for _, vector := range all {
+		t.Run(vector.Name, func(t *testing.T) {
+			decoded, err := decode(kindAuto, string(vector.Response))
+			require.NoError(t, err)
+
+			response, ok := decoded.(*credentialResponse)
+			require.True(t, ok)
+
+			clientData, ok := response.ClientData.(map[string]interface{})
+			require.True(t, ok)
+			assert.Equal(t, vector.Challenge, clientData["challenge"])
+			assert.Equal(t, vector.Origin, clientData["origin"])
+
+			var raw testResponse
+
+			require.NoError(t, json.Unmarshal(vector.Response, &raw))
+
+			switch vector.Ceremony {
+			case vectors.CeremonyRegistration:
+				require.NotNil(t, response.AttestationObject)
+				assert.Equal(t, vector.Format, response.AttestationObject.Format)
+				require.NotNil(t, response.AttestationObject.AuthData.AttestedCredentialData)
+				assert.Contains(t, response.AttestationObject.AuthData.Flags.Names, "AT")
+
+				attestation, err := decode(kindAuto, raw.Response.AttestationObject)
+				require.NoError(t, err)
+				assert.Equal(t, response.AttestationObject, attestation)
+
+				key, err := decode(kindAuto, encodeBase64(attestedCredentialPublicKey(t, raw.Response.AttestationObject)))
+				require.NoError(t, err)
+				assert.Equal(t, response.AttestationObject.AuthData.AttestedCredentialData.CredentialPublicKey, key)
+			case vectors.CeremonyAuthentication:
+				require.NotNil(t, response.AuthenticatorData)
+				assert.Contains(t, response.AuthenticatorData.Flags.Names, "UP")
+				assert.NotEmpty(t, response.Signature)
+
+				authData, err := decode(kindAuthData, raw.Response.AuthenticatorData)
+				require.NoError(t, err)
+				assert.Equal(t, response.AuthenticatorData, authData)
+
+				key, err := decode(kindCOSEKey, vector.CredentialPublicKey)
+				require.NoError(t, err)
+				assert.NotEqual(t, "", key.(*coseKey).PEM)
+			}
+
+			clientDataJSON, err := decode(kindAuto, raw.Response.ClientDataJSON)
+			require.NoError(t, err)
+			assert.Equal(t, response.ClientData, clientDataJSON)
+		})
+	}
+}
+
+func TestDecodeCOSEKey(t *testing.T) {
+	vector, err := vectors.Load("assertion-es256-virtual")
+	require.NoError(t, err)
+
+	decoded, err := decode(kindCOSEKey, vector.CredentialPublicKey)
+	require.NoError(t, err)
+
+	key := decoded.(*coseKey)
+
+	assert.Equal(t, "EC2", key.KeyType)
+	assert.Equal(t, "ES256", key.Algorithm)
+	assert.Equal(t, "P-256", key.Curve)
+	assert.True(t, strings.HasPrefix(key.PEM, "-----BEGIN PUBLIC KEY-----"))
+
+	parameters := key.Parameters.(map[string]interface{})
+
+	assert.Len(t, parameters, 5)
+	assert.IsType(t, "", parameters["-2"])
+}
+
+func TestDecodeErrors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		kind  inputKind
+		input string
+		err   string
+	}{
+		{"ShouldFailInvalidBase64", kindAuto, "!!!", "error decoding the base64 input"},
+		{"ShouldFailUnknownInput", kindAuto, "AQID", errUnknownInput.Error()},
+		{"ShouldFailUnknownType", "unknown", "AQID", `unknown type "unknown"`},
+		{"ShouldFailInvalidAuthData", kindAuthData, "AQID", "error decoding the authenticator data"},
+		{"ShouldFailInvalidCOSEKey", kindCOSEKey, "AQID", "error decoding the COSE key"},
+		{"ShouldFailInvalidResponse", kindAuto, "{", "error decoding the credential response"},
+		{"ShouldFailResponseWithoutData", kindAuto, `{"response":{"clientDataJSON":"e30"}}`, "neither an attestation object nor authenticator data"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := decode(tc.kind, tc.input)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+func TestDecodeBase64(t *testing.T) {
+	for _, input := range []string{"-_8", "-_8=", "+/8", "+/8="} {
+		raw, err := decodeBase64(input)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0xfb, 0xff}, raw)
+	}
+}
+
+func TestRun(t *testing.T) {
+	vector, err := vectors.Load("assertion-es256-virtual")
+	require.NoError(t, err)
+
+	var stdout bytes.Buffer
+
+	require.NoError(t, run(&stdout, bytes.NewReader(vector.Response), "auto", nil))
+
+	var decoded map[string]interface{}
+
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &decoded))
+	assert.Contains(t, decoded, "authenticatorData")
+	assert.Contains(t, stdout.String(), "\n  \"clientData\": {")
+
+	stdout.Reset()
+
+	require.NoError(t, run(&stdout, nil, "clientdata", []string{base64.StdEncoding.EncodeToString([]byte(`{"origin":"https://example.com"}`))}))
+	assert.Equal(t, "{\n  \"origin\": \"https://example.com\"\n}\n", stdout.String())
+
+	assert.EqualError(t, run(&stdout, nil, "auto", []string{"a", "b"}), "expected at most one input but got 2")
+}
+
+// attestedCredentialPublicKey returns the credential public key from the attested credential data of an attestation
+// object.
+func attestedCredentialPublicKey(t *testing.T, attestationObject string) []byte {
+	raw, err := decodeBase64(attestationObject)
+	require.NoError(t, err)
+
+	var object protocol.AttestationObject
+
+	require.NoError(t, webauthncbor.Unmarshal(raw, &object))
+	require.NoError(t, object.AuthData.Unmarshal(object.RawAuthData))
+
+	return object.AuthData.AttData.CredentialPublicKey
+}
@@ -0,0 +1,67 @@
+// Command webauthn-decode pretty-prints the binary and encoded structures of WebAuthn payloads as JSON, which is
+// useful when triaging interoperability issues.
+//
+// The input is read from the first argument or from stdin if there are no arguments. It's either a base64 or base64url
+// encoded clientDataJSON, authenticator data, attestation object or COSE public key, or a JSON encoded credential
+// creation or assertion response as sent by the client. The kind of input is detected automatically unless the -type
+// flag is provided.
+//
+// Usage:
+//
+//	webauthn-decode [-type auto|response|clientdata|authdata|attestation|cosekey] [input]
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func main() {
+	kind := flag.String("type", string(kindAuto), "the type of the input: auto, response, clientdata, authdata, attestation or cosekey")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-type type] [input]\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if err := run(os.Stdout, os.Stdin, *kind, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(w io.Writer, stdin io.Reader, kind string, args []string) error {
+	var input string
+
+	switch len(args) {
+	case 0:
+		raw, err := io.ReadAll(stdin)
+		if err != nil {
+			return err
+		}
+
+		input = string(raw)
+	case 1:
+		input = args[0]
+	default:
+		return fmt.Errorf("expected at most one input but got %d", len(args))
+	}
+
+	decoded, err := decode(inputKind(kind), strings.TrimSpace(input))
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(decoded)
+}
@@ -0,0 +1,56 @@
+// Command conformance-server is an example server for running the FIDO2 Server Conformance Test suite against the
+// library. The metadata service URLs provided by the conformance tool are passed with the -mds flag.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-webauthn/webauthn/conformance"
+	"github.com/go-webauthn/webauthn/metadata"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+func main() {
+	var (
+		addr   = flag.String("addr", ":8080", "the address to listen on")
+		rpID   = flag.String("rpid", "localhost", "the relying party ID")
+		origin = flag.String("origin", "http://localhost:8080", "the relying party origin")
+		mds    = flag.String("mds", "", "comma separated list of the metadata service URLs of the conformance tool")
+	)
+
+	flag.Parse()
+
+	metadata.Conformance = true
+	metadata.MDSRoot = metadata.ConformanceMDSRoot
+
+	for _, url := range strings.Split(*mds, ",") {
+		if url == "" {
+			continue
+		}
+
+		if err := metadata.PopulateMetadata(url); err != nil {
+			log.Fatalf("error loading metadata from %s: %v", url, err)
+		}
+	}
+
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          *rpID,
+		RPDisplayName: "Conformance",
+		RPOrigins:     []string{*origin},
+		AttestationPolicy: protocol.AttestationPolicy{
+			RequireMetadata: true,
+			VerifyTrustPath: true,
+		},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("listening on %s", *addr)
+
+	log.Fatal(http.ListenAndServe(*addr, conformance.New(w)))
+}
@@ -0,0 +1,78 @@
+// Package conformance provides an http.Handler which implements the transport binding expected by the FIDO2 Server
+// Conformance Test suite on top of a *webauthn.WebAuthn. It is intended for running the conformance tool against the
+// library and as a reference for the request and response shapes of the tool, not for production use, as the users
+// and sessions are only kept in memory.
+package conformance
+
+import (
+	"encoding/json"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// ServerPublicKeyCredentialCreationOptionsRequest is the body of the request to the /attestation/options endpoint.
+type ServerPublicKeyCredentialCreationOptionsRequest struct {
+	Username               string                            `json:"username"`
+	DisplayName            string                            `json:"displayName"`
+	AuthenticatorSelection *protocol.AuthenticatorSelection  `json:"authenticatorSelection,omitempty"`
+	Attestation            protocol.ConveyancePreference     `json:"attestation,omitempty"`
+	Extensions             protocol.AuthenticationExtensions `json:"extensions,omitempty"`
+}
+
+// ServerPublicKeyCredentialGetOptionsRequest is the body of the request to the /assertion/options endpoint.
+type ServerPublicKeyCredentialGetOptionsRequest struct {
+	Username         string                               `json:"username"`
+	UserVerification protocol.UserVerificationRequirement `json:"userVerification,omitempty"`
+	Extensions       protocol.AuthenticationExtensions    `json:"extensions,omitempty"`
+}
+
+// ServerPublicKeyCredentialCreationOptionsResponse is the body of the response of the /attestation/options endpoint.
+// The options are encoded in the same object as the status.
+type ServerPublicKeyCredentialCreationOptionsResponse struct {
+	protocol.ServerResponse
+
+	Options protocol.PublicKeyCredentialCreationOptions
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r ServerPublicKeyCredentialCreationOptionsResponse) MarshalJSON() ([]byte, error) {
+	return marshalServerResponse(r.ServerResponse, r.Options)
+}
+
+// ServerPublicKeyCredentialGetOptionsResponse is the body of the response of the /assertion/options endpoint. The
+// options are encoded in the same object as the status.
+type ServerPublicKeyCredentialGetOptionsResponse struct {
+	protocol.ServerResponse
+
+	Options protocol.PublicKeyCredentialRequestOptions
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r ServerPublicKeyCredentialGetOptionsResponse) MarshalJSON() ([]byte, error) {
+	return marshalServerResponse(r.ServerResponse, r.Options)
+}
+
+// serverPublicKeyCredential contains the client extension results under the name used by the conformance tool.
+type serverPublicKeyCredential struct {
+	ClientExtensionResults protocol.AuthenticationExtensionsClientOutputs `json:"getClientExtensionResults,omitempty"`
+}
+
+// marshalServerResponse encodes the options as members of the same JSON object as the status. The options are
+// encoded separately, instead of embedding them, as they may implement json.Marshaler themselves.
+func marshalServerResponse(response protocol.ServerResponse, options interface{}) ([]byte, error) {
+	status, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(options)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(encoded) <= len("{}") {
+		return status, nil
+	}
+
+	return append(append(status[:len(status)-1], ','), encoded[1:]...), nil
+}
@@ -0,0 +1,363 @@
+package conformance
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+var (
+	errMissingUsername = errors.New("missing username")
+	errUnknownUser     = errors.New("user does not exist")
+	errNoCredentials   = errors.New("user does not have any registered credentials")
+	errUnknownSession  = errors.New("no pending session for the challenge")
+)
+
+// Server is an http.Handler which serves the /attestation/options, /attestation/result, /assertion/options and
+// /assertion/result endpoints used by the FIDO2 Server Conformance Test suite.
+//
+// The sessions are identified by the challenge of the client data instead of a cookie, as the conformance tool sends
+// the responses without any other state.
+type Server struct {
+	webauthn *webauthn.WebAuthn
+	mux      *http.ServeMux
+
+	mu       sync.Mutex
+	users    map[string]*user
+	sessions map[string]pendingSession
+}
+
+type pendingSession struct {
+	user *user
+	data webauthn.SessionData
+}
+
+type user struct {
+	id          []byte
+	name        string
+	displayName string
+	credentials []webauthn.Credential
+}
+
+func (u *user) WebAuthnID() []byte {
+	return u.id
+}
+
+func (u *user) WebAuthnName() string {
+	return u.name
+}
+
+func (u *user) WebAuthnDisplayName() string {
+	return u.displayName
+}
+
+func (u *user) WebAuthnIcon() string {
+	return ""
+}
+
+func (u *user) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+// New returns a *Server performing the ceremonies with the provided *webauthn.WebAuthn.
+func New(w *webauthn.WebAuthn) *Server {
+	s := &Server{
+		webauthn: w,
+		mux:      http.NewServeMux(),
+		users:    map[string]*user{},
+		sessions: map[string]pendingSession{},
+	}
+
+	s.mux.HandleFunc("/attestation/options", s.attestationOptions)
+	s.mux.HandleFunc("/attestation/result", s.attestationResult)
+	s.mux.HandleFunc("/assertion/options", s.assertionOptions)
+	s.mux.HandleFunc("/assertion/result", s.assertionResult)
+
+	return s
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeFailure(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) attestationOptions(w http.ResponseWriter, r *http.Request) {
+	var request ServerPublicKeyCredentialCreationOptionsRequest
+
+	if err := readJSON(r, &request); err != nil {
+		writeFailure(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	if request.Username == "" {
+		writeFailure(w, http.StatusBadRequest, errMissingUsername)
+
+		return
+	}
+
+	u, err := s.user(request.Username, request.DisplayName)
+	if err != nil {
+		writeFailure(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	opts := []webauthn.RegistrationOption{
+		webauthn.WithExclusions(webauthn.CredentialDescriptors(u.credentials)),
+	}
+
+	if request.AuthenticatorSelection != nil {
+		opts = append(opts, webauthn.WithAuthenticatorSelection(*request.AuthenticatorSelection))
+	}
+
+	if request.Attestation != "" {
+		opts = append(opts, webauthn.WithConveyancePreference(request.Attestation))
+	}
+
+	if request.Extensions != nil {
+		opts = append(opts, webauthn.WithExtensions(request.Extensions))
+	}
+
+	creation, session, err := s.webauthn.BeginRegistration(u, opts...)
+	if err != nil {
+		writeFailure(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	s.storeSession(u, session)
+
+	writeJSON(w, http.StatusOK, ServerPublicKeyCredentialCreationOptionsResponse{
+		ServerResponse: protocol.ServerResponse{Status: protocol.StatusOk},
+		Options:        creation.Response,
+	})
+}
+
+func (s *Server) attestationResult(w http.ResponseWriter, r *http.Request) {
+	var (
+		response protocol.CredentialCreationResponse
+		results  serverPublicKeyCredential
+	)
+
+	if err := readJSON(r, &response, &results); err != nil {
+		writeFailure(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	if response.ClientExtensionResults == nil {
+		response.ClientExtensionResults = results.ClientExtensionResults
+	}
+
+	parsed, err := response.Parse()
+	if err != nil {
+		writeFailure(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	session, ok := s.takeSession(parsed.Response.CollectedClientData.Challenge)
+	if !ok {
+		writeFailure(w, http.StatusBadRequest, errUnknownSession)
+
+		return
+	}
+
+	credential, err := s.webauthn.CreateCredential(session.user, session.data, parsed)
+	if err != nil {
+		writeFailure(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	s.mu.Lock()
+	session.user.credentials = append(session.user.credentials, *credential)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, protocol.ServerResponse{Status: protocol.StatusOk})
+}
+
+func (s *Server) assertionOptions(w http.ResponseWriter, r *http.Request) {
+	var request ServerPublicKeyCredentialGetOptionsRequest
+
+	if err := readJSON(r, &request); err != nil {
+		writeFailure(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	if request.Username == "" {
+		writeFailure(w, http.StatusBadRequest, errMissingUsername)
+
+		return
+	}
+
+	s.mu.Lock()
+	u, ok := s.users[request.Username]
+	s.mu.Unlock()
+
+	if !ok {
+		writeFailure(w, http.StatusBadRequest, errUnknownUser)
+
+		return
+	}
+
+	if len(u.credentials) == 0 {
+		writeFailure(w, http.StatusBadRequest, errNoCredentials)
+
+		return
+	}
+
+	var opts []webauthn.LoginOption
+
+	if request.UserVerification != "" {
+		opts = append(opts, webauthn.WithUserVerification(request.UserVerification))
+	}
+
+	if request.Extensions != nil {
+		opts = append(opts, webauthn.WithAssertionExtensions(request.Extensions))
+	}
+
+	assertion, session, err := s.webauthn.BeginLogin(u, opts...)
+	if err != nil {
+		writeFailure(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	s.storeSession(u, session)
+
+	writeJSON(w, http.StatusOK, ServerPublicKeyCredentialGetOptionsResponse{
+		ServerResponse: protocol.ServerResponse{Status: protocol.StatusOk},
+		Options:        assertion.Response,
+	})
+}
+
+func (s *Server) assertionResult(w http.ResponseWriter, r *http.Request) {
+	var (
+		response protocol.CredentialAssertionResponse
+		results  serverPublicKeyCredential
+	)
+
+	if err := readJSON(r, &response, &results); err != nil {
+		writeFailure(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	if response.ClientExtensionResults == nil {
+		response.ClientExtensionResults = results.ClientExtensionResults
+	}
+
+	parsed, err := response.Parse()
+	if err != nil {
+		writeFailure(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	session, ok := s.takeSession(parsed.Response.CollectedClientData.Challenge)
+	if !ok {
+		writeFailure(w, http.StatusBadRequest, errUnknownSession)
+
+		return
+	}
+
+	credential, err := s.webauthn.ValidateLogin(session.user, session.data, parsed)
+	if err != nil {
+		writeFailure(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	s.mu.Lock()
+	for i := range session.user.credentials {
+		if string(session.user.credentials[i].ID) == string(credential.ID) {
+			session.user.credentials[i] = *credential
+		}
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, protocol.ServerResponse{Status: protocol.StatusOk})
+}
+
+// user returns the user with the username, creating it with a random user handle if it does not exist yet.
+func (s *Server) user(name, displayName string) (*user, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u, ok := s.users[name]; ok {
+		return u, nil
+	}
+
+	id := make([]byte, 32)
+
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	u := &user{id: id, name: name, displayName: displayName}
+
+	s.users[name] = u
+
+	return u, nil
+}
+
+func (s *Server) storeSession(u *user, session *webauthn.SessionData) {
+	s.mu.Lock()
+	s.sessions[session.Challenge] = pendingSession{user: u, data: *session}
+	s.mu.Unlock()
+}
+
+// takeSession returns and removes the pending session for the challenge so that every session is only used once.
+func (s *Server) takeSession(challenge string) (session pendingSession, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if session, ok = s.sessions[challenge]; ok {
+		delete(s.sessions, challenge)
+	}
+
+	return session, ok
+}
+
+// readJSON decodes the request body into every one of the values.
+func readJSON(r *http.Request, values ...interface{}) error {
+	body, err := io.ReadAll(io.LimitReader(r.Body, protocol.DefaultResponseBodyLimit))
+	if err != nil {
+		return err
+	}
+
+	for _, v := range values {
+		if err = json.Unmarshal(body, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeFailure(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, protocol.ServerResponse{Status: protocol.StatusFailed, Message: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,190 @@
+package conformance
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+const testOrigin = "https://example.com"
+
+func newTestServer(t *testing.T, policy protocol.AttestationPolicy) *Server {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:              "example.com",
+		RPDisplayName:     "Example",
+		RPOrigins:         []string{testOrigin},
+		AttestationPolicy: policy,
+	})
+	require.NoError(t, err)
+
+	return New(w)
+}
+
+func post(t *testing.T, s *Server, path string, body interface{}, v interface{}) (int, protocol.ServerResponse) {
+	raw, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(raw)))
+
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var response protocol.ServerResponse
+
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+
+	if v != nil {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), v))
+	}
+
+	return rec.Code, response
+}
+
+func register(t *testing.T, s *Server, authenticator *webauthntest.Authenticator, username string) (int, protocol.ServerResponse) {
+	var options protocol.PublicKeyCredentialCreationOptions
+
+	code, response := post(t, s, "/attestation/options", ServerPublicKeyCredentialCreationOptionsRequest{
+		Username:    username,
+		DisplayName: "John",
+		Attestation: protocol.PreferDirectAttestation,
+		AuthenticatorSelection: &protocol.AuthenticatorSelection{
+			UserVerification: protocol.VerificationPreferred,
+		},
+	}, &options)
+	require.Equal(t, http.StatusOK, code, response.Message)
+	require.Equal(t, protocol.StatusOk, response.Status)
+	assert.Equal(t, "", response.Message)
+	assert.Equal(t, username, options.User.Name)
+	assert.Equal(t, protocol.PreferDirectAttestation, options.Attestation)
+
+	// The user handle is encoded as base64url in the options, and is decoded by the client before calling the
+	// authenticator.
+	id, err := base64.RawURLEncoding.DecodeString(options.User.ID.(string))
+	require.NoError(t, err)
+
+	options.User.ID = id
+
+	attestation, _, err := authenticator.CreateCredential(options, testOrigin)
+	require.NoError(t, err)
+
+	return post(t, s, "/attestation/result", attestation, nil)
+}
+
+func TestServer(t *testing.T) {
+	s := newTestServer(t, protocol.AttestationPolicy{})
+	authenticator := &webauthntest.Authenticator{Format: webauthntest.FormatPacked}
+
+	code, response := register(t, s, authenticator, "john")
+	require.Equal(t, http.StatusOK, code, response.Message)
+	require.Equal(t, protocol.StatusOk, response.Status)
+
+	for i := 0; i < 2; i++ {
+		var options protocol.PublicKeyCredentialRequestOptions
+
+		code, response = post(t, s, "/assertion/options", ServerPublicKeyCredentialGetOptionsRequest{
+			Username:         "john",
+			UserVerification: protocol.VerificationPreferred,
+		}, &options)
+		require.Equal(t, http.StatusOK, code, response.Message)
+		require.Equal(t, protocol.StatusOk, response.Status)
+		assert.Len(t, options.AllowedCredentials, 1)
+		assert.Equal(t, protocol.VerificationPreferred, options.UserVerification)
+
+		assertion, err := authenticator.GetAssertion(options, testOrigin)
+		require.NoError(t, err)
+
+		code, response = post(t, s, "/assertion/result", assertion, nil)
+		require.Equal(t, http.StatusOK, code, response.Message)
+		assert.Equal(t, protocol.StatusOk, response.Status)
+
+		// The session is only valid once.
+		code, response = post(t, s, "/assertion/result", assertion, nil)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, protocol.StatusFailed, response.Status)
+		assert.Equal(t, errUnknownSession.Error(), response.Message)
+	}
+
+	assert.Equal(t, uint32(2), s.users["john"].credentials[0].Authenticator.SignCount)
+
+	var options protocol.PublicKeyCredentialCreationOptions
+
+	code, _ = post(t, s, "/attestation/options", ServerPublicKeyCredentialCreationOptionsRequest{Username: "john"}, &options)
+	require.Equal(t, http.StatusOK, code, response.Message)
+	assert.Len(t, options.CredentialExcludeList, 1)
+}
+
+func TestServerFailures(t *testing.T) {
+	s := newTestServer(t, protocol.AttestationPolicy{})
+
+	testCases := []struct {
+		name    string
+		path    string
+		body    interface{}
+		message string
+	}{
+		{"ShouldFailAttestationOptionsWithoutUsername", "/attestation/options", ServerPublicKeyCredentialCreationOptionsRequest{}, errMissingUsername.Error()},
+		{"ShouldFailAssertionOptionsWithoutUsername", "/assertion/options", ServerPublicKeyCredentialGetOptionsRequest{}, errMissingUsername.Error()},
+		{"ShouldFailAssertionOptionsForUnknownUser", "/assertion/options", ServerPublicKeyCredentialGetOptionsRequest{Username: "jane"}, errUnknownUser.Error()},
+		{"ShouldFailAttestationResultWithInvalidBody", "/attestation/result", "invalid", ""},
+		{"ShouldFailAssertionResultWithInvalidBody", "/assertion/result", map[string]string{"id": "invalid"}, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, response := post(t, s, tc.path, tc.body, nil)
+			assert.Equal(t, http.StatusBadRequest, code)
+			assert.Equal(t, protocol.StatusFailed, response.Status)
+
+			if tc.message != "" {
+				assert.Equal(t, tc.message, response.Message)
+			} else {
+				assert.NotEmpty(t, response.Message)
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/attestation/options", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestServerRequireMetadata(t *testing.T) {
+	s := newTestServer(t, protocol.AttestationPolicy{RequireMetadata: true})
+
+	code, response := register(t, s, &webauthntest.Authenticator{Format: webauthntest.FormatU2F}, "john")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, protocol.StatusFailed, response.Status)
+	assert.Contains(t, response.Message, "not found in metadata")
+
+	code, response = register(t, s, &webauthntest.Authenticator{Format: webauthntest.FormatNone}, "john")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, protocol.StatusOk, response.Status)
+}
+
+func TestServerResponseMarshalJSON(t *testing.T) {
+	raw, err := json.Marshal(ServerPublicKeyCredentialGetOptionsResponse{
+		ServerResponse: protocol.ServerResponse{Status: protocol.StatusOk},
+		Options: protocol.PublicKeyCredentialRequestOptions{
+			Challenge:      protocol.URLEncodedBase64("challenge"),
+			RelyingPartyID: "example.com",
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"status":"ok","errorMessage":"","challenge":"Y2hhbGxlbmdl","rpId":"example.com"}`, string(raw))
+
+	raw, err = marshalServerResponse(protocol.ServerResponse{Status: protocol.StatusFailed, Message: "error"}, struct{}{})
+	require.NoError(t, err)
+	assert.Equal(t, `{"status":"failed","errorMessage":"error"}`, string(raw))
+}
@@ -0,0 +1,47 @@
+// Command example is a complete example of a relying party using the library. It serves a minimal frontend which
+// demonstrates registration, login with a username and passkey autofill, backed by net/http handlers and an in-memory
+// user and credential store.
+//
+// WebAuthn requires a secure context, which includes http://localhost, so the example can be run locally with:
+//
+//	go run ./example
+//
+// and visiting http://localhost:8080. When serving it from another host the -rpid and -origin flags must match the
+// URL used in the browser, and the server must be behind TLS.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+func main() {
+	var (
+		addr   = flag.String("addr", "localhost:8080", "the address to listen on")
+		rpID   = flag.String("rpid", "localhost", "the relying party ID, which is the domain of the origin")
+		origin = flag.String("origin", "http://localhost:8080", "the origin the frontend is served from")
+	)
+
+	flag.Parse()
+
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          *rpID,
+		RPDisplayName: "go-webauthn example",
+		RPOrigins:     []string{*origin},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s, err := newServer(w)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("listening on %s, visit %s", *addr, *origin)
+
+	log.Fatal(http.ListenAndServe(*addr, s))
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+const sessionCookieName = "webauthn_session"
+
+var (
+	errMissingUsername = errors.New("a username is required")
+	errUnknownUser     = errors.New("the user does not exist")
+	errNoSession       = errors.New("there is no ceremony in progress")
+)
+
+//go:embed static
+var static embed.FS
+
+// server serves the frontend and the JSON API used by it to perform the registration and login ceremonies.
+type server struct {
+	webauthn *webauthn.WebAuthn
+	store    *store
+	mux      *http.ServeMux
+}
+
+type usernameRequest struct {
+	Username string `json:"username"`
+}
+
+type usernameResponse struct {
+	Username string `json:"username"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func newServer(w *webauthn.WebAuthn) (*server, error) {
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &server{
+		webauthn: w,
+		store:    newStore(),
+		mux:      http.NewServeMux(),
+	}
+
+	s.mux.Handle("/", http.FileServer(http.FS(files)))
+	s.mux.HandleFunc("/api/register/begin", post(s.beginRegistration))
+	s.mux.HandleFunc("/api/register/finish", post(s.finishRegistration))
+	s.mux.HandleFunc("/api/login/begin", post(s.beginLogin))
+	s.mux.HandleFunc("/api/login/finish", post(s.finishLogin))
+
+	return s, nil
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// beginRegistration creates the user if it does not exist yet and returns the options for navigator.credentials.create.
+// Discoverable credentials are preferred so that the credential can be used as a passkey.
+func (s *server) beginRegistration(w http.ResponseWriter, r *http.Request) {
+	var request usernameRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	if request.Username = strings.TrimSpace(request.Username); request.Username == "" {
+		writeError(w, http.StatusBadRequest, errMissingUsername)
+
+		return
+	}
+
+	u, err := s.store.userOrCreate(request.Username)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	creation, data, err := s.webauthn.BeginRegistration(u,
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred),
+		webauthn.WithExclusions(webauthn.CredentialDescriptors(u.credentials)),
+	)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	if err = s.saveSession(w, r, func(sess *session) {
+		sess.username, sess.registration = u.name, data
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, creation)
+}
+
+func (s *server) finishRegistration(w http.ResponseWriter, r *http.Request) {
+	var (
+		username string
+		data     *webauthn.SessionData
+	)
+
+	s.takeSession(r, func(sess *session) {
+		username, data = sess.username, sess.registration
+		sess.registration = nil
+	})
+
+	if data == nil {
+		writeError(w, http.StatusBadRequest, errNoSession)
+
+		return
+	}
+
+	u, ok := s.store.user(username)
+	if !ok {
+		writeError(w, http.StatusBadRequest, errUnknownUser)
+
+		return
+	}
+
+	credential, err := s.webauthn.FinishRegistration(u, *data, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	s.store.saveCredential(u.name, *credential)
+
+	writeJSON(w, http.StatusOK, usernameResponse{Username: u.name})
+}
+
+// beginLogin returns the options for navigator.credentials.get. The allowed credentials are those of the user when a
+// username is provided, otherwise a discoverable login is started which is used for passkey autofill.
+func (s *server) beginLogin(w http.ResponseWriter, r *http.Request) {
+	var request usernameRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	var (
+		assertion *protocol.CredentialAssertion
+		data      *webauthn.SessionData
+		err       error
+	)
+
+	if request.Username = strings.TrimSpace(request.Username); request.Username == "" {
+		assertion, data, err = s.webauthn.BeginDiscoverableLogin()
+	} else {
+		u, ok := s.store.user(request.Username)
+		if !ok {
+			writeError(w, http.StatusBadRequest, errUnknownUser)
+
+			return
+		}
+
+		assertion, data, err = s.webauthn.BeginLogin(u)
+	}
+
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	if err = s.saveSession(w, r, func(sess *session) {
+		sess.username, sess.login = request.Username, data
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, assertion)
+}
+
+func (s *server) finishLogin(w http.ResponseWriter, r *http.Request) {
+	var (
+		username string
+		data     *webauthn.SessionData
+	)
+
+	s.takeSession(r, func(sess *session) {
+		username, data = sess.username, sess.login
+		sess.login = nil
+	})
+
+	if data == nil {
+		writeError(w, http.StatusBadRequest, errNoSession)
+
+		return
+	}
+
+	var (
+		u          *user
+		credential *webauthn.Credential
+		err        error
+	)
+
+	if username == "" {
+		credential, err = s.webauthn.FinishDiscoverableLogin(func(_, userHandle []byte) (webauthn.User, error) {
+			var ok bool
+
+			if u, ok = s.store.userByID(userHandle); !ok {
+				return nil, errUnknownUser
+			}
+
+			return u, nil
+		}, *data, r)
+	} else {
+		var ok bool
+
+		if u, ok = s.store.user(username); !ok {
+			writeError(w, http.StatusBadRequest, errUnknownUser)
+
+			return
+		}
+
+		credential, err = s.webauthn.FinishLogin(u, *data, r)
+	}
+
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	s.store.saveCredential(u.name, *credential)
+
+	writeJSON(w, http.StatusOK, usernameResponse{Username: u.name})
+}
+
+// saveSession updates the session of the request, creating a new session and setting its cookie if the request does
+// not have one.
+func (s *server) saveSession(w http.ResponseWriter, r *http.Request, update func(*session)) error {
+	if cookie, err := r.Cookie(sessionCookieName); err == nil && s.store.updateSession(cookie.Value, update) {
+		return nil
+	}
+
+	id, err := s.store.newSession()
+	if err != nil {
+		return err
+	}
+
+	s.store.updateSession(id, update)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    id,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	return nil
+}
+
+// takeSession calls take with the session of the request if it has one.
+func (s *server) takeSession(r *http.Request, take func(*session)) {
+	if cookie, err := r.Cookie(sessionCookieName); err == nil {
+		s.store.updateSession(cookie.Value, take)
+	}
+}
+
+// post only allows the POST method for the handler.
+func post(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+const testOrigin = "https://example.com"
+
+type testClient struct {
+	t      *testing.T
+	server *httptest.Server
+	client *http.Client
+}
+
+func newTestClient(t *testing.T) *testClient {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{testOrigin},
+	})
+	require.NoError(t, err)
+
+	s, err := newServer(w)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(s)
+
+	t.Cleanup(server.Close)
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+
+	return &testClient{t: t, server: server, client: &http.Client{Jar: jar}}
+}
+
+// post sends the body to the path and decodes the response into v, returning the status code and the error message of
+// the response.
+func (c *testClient) post(path string, body interface{}, v interface{}) (int, string) {
+	raw, err := json.Marshal(body)
+	require.NoError(c.t, err)
+
+	res, err := c.client.Post(c.server.URL+path, "application/json", bytes.NewReader(raw))
+	require.NoError(c.t, err)
+
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	require.NoError(c.t, err)
+
+	var e errorResponse
+
+	require.NoError(c.t, json.Unmarshal(data, &e))
+
+	if v != nil && res.StatusCode == http.StatusOK {
+		require.NoError(c.t, json.Unmarshal(data, v))
+	}
+
+	return res.StatusCode, e.Error
+}
+
+func (c *testClient) register(authenticator *webauthntest.Authenticator, username string) (int, string) {
+	var creation protocol.CredentialCreation
+
+	code, message := c.post("/api/register/begin", usernameRequest{Username: username}, &creation)
+	require.Equal(c.t, http.StatusOK, code, message)
+
+	// The user handle is encoded as base64url in the options, and is decoded by the frontend.
+	id, err := base64.RawURLEncoding.DecodeString(creation.Response.User.ID.(string))
+	require.NoError(c.t, err)
+
+	creation.Response.User.ID = id
+
+	response, _, err := authenticator.CreateCredential(creation.Response, testOrigin)
+	require.NoError(c.t, err)
+
+	return c.post("/api/register/finish", response, nil)
+}
+
+func (c *testClient) login(authenticator *webauthntest.Authenticator, username string) (int, string, usernameResponse) {
+	var assertion protocol.CredentialAssertion
+
+	code, message := c.post("/api/login/begin", usernameRequest{Username: username}, &assertion)
+	require.Equal(c.t, http.StatusOK, code, message)
+
+	response, err := authenticator.GetAssertion(assertion.Response, testOrigin)
+	require.NoError(c.t, err)
+
+	var result usernameResponse
+
+	code, message = c.post("/api/login/finish", response, &result)
+
+	return code, message, result
+}
+
+func TestServer(t *testing.T) {
+	c := newTestClient(t)
+	authenticator := &webauthntest.Authenticator{Format: webauthntest.FormatPacked}
+
+	code, message := c.register(authenticator, "john")
+	require.Equal(t, http.StatusOK, code, message)
+
+	code, message, result := c.login(authenticator, "john")
+	require.Equal(t, http.StatusOK, code, message)
+	assert.Equal(t, "john", result.Username)
+
+	// Passkey autofill performs a discoverable login without a username.
+	code, message, result = c.login(authenticator, "")
+	require.Equal(t, http.StatusOK, code, message)
+	assert.Equal(t, "john", result.Username)
+
+	u, ok := c.server.Config.Handler.(*server).store.user("john")
+	require.True(t, ok)
+	require.Len(t, u.credentials, 1)
+	assert.Equal(t, uint32(2), u.credentials[0].Authenticator.SignCount)
+
+	// The existing credentials are excluded when registering another authenticator.
+	var creation protocol.CredentialCreation
+
+	code, message = c.post("/api/register/begin", usernameRequest{Username: "john"}, &creation)
+	require.Equal(t, http.StatusOK, code, message)
+	assert.Len(t, creation.Response.CredentialExcludeList, 1)
+	assert.Equal(t, protocol.ResidentKeyRequirementPreferred, creation.Response.AuthenticatorSelection.ResidentKey)
+}
+
+func TestServerErrors(t *testing.T) {
+	c := newTestClient(t)
+
+	code, message := c.post("/api/register/begin", usernameRequest{Username: " "}, nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, errMissingUsername.Error(), message)
+
+	code, message = c.post("/api/login/begin", usernameRequest{Username: "jane"}, nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, errUnknownUser.Error(), message)
+
+	code, message = c.post("/api/register/finish", struct{}{}, nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, errNoSession.Error(), message)
+
+	code, message = c.post("/api/login/finish", struct{}{}, nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, errNoSession.Error(), message)
+
+	authenticator := &webauthntest.Authenticator{}
+
+	code, message = c.register(authenticator, "john")
+	require.Equal(t, http.StatusOK, code, message)
+
+	// A login response is rejected when it's for a different ceremony than the one in progress.
+	var assertion protocol.CredentialAssertion
+
+	code, message = c.post("/api/login/begin", usernameRequest{Username: "john"}, &assertion)
+	require.Equal(t, http.StatusOK, code, message)
+
+	assertion.Response.Challenge = protocol.URLEncodedBase64("invalid")
+
+	response, err := authenticator.GetAssertion(assertion.Response, testOrigin)
+	require.NoError(t, err)
+
+	code, _ = c.post("/api/login/finish", response, nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// The session is consumed by the failed attempt.
+	code, message = c.post("/api/login/finish", response, nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, errNoSession.Error(), message)
+
+	res, err := c.client.Get(c.server.URL + "/api/login/begin")
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+}
+
+func TestServerStatic(t *testing.T) {
+	c := newTestClient(t)
+
+	for path, contains := range map[string]string{
+		"/":       `autocomplete="username webauthn"`,
+		"/app.js": "mediation = 'conditional'",
+	} {
+		res, err := c.client.Get(c.server.URL + path)
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Contains(t, string(body), contains)
+	}
+}
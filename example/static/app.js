@@ -0,0 +1,160 @@
+'use strict';
+
+// The options returned by the server encode the binary values as base64url, and the credentials returned by the
+// browser contain ArrayBuffers, so both have to be converted.
+function decode(value) {
+  const base64 = value.replace(/-/g, '+').replace(/_/g, '/');
+  const padded = base64 + '='.repeat((4 - (base64.length % 4)) % 4);
+
+  return Uint8Array.from(atob(padded), (c) => c.charCodeAt(0)).buffer;
+}
+
+function encode(buffer) {
+  const bytes = String.fromCharCode(...new Uint8Array(buffer));
+
+  return btoa(bytes).replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '');
+}
+
+function decodeDescriptors(descriptors) {
+  return (descriptors || []).map((descriptor) => ({ ...descriptor, id: decode(descriptor.id) }));
+}
+
+function encodeCredential(credential) {
+  const response = { clientDataJSON: encode(credential.response.clientDataJSON) };
+
+  if (credential.response.attestationObject) {
+    response.attestationObject = encode(credential.response.attestationObject);
+
+    if (credential.response.getTransports) {
+      response.transports = credential.response.getTransports();
+    }
+  } else {
+    response.authenticatorData = encode(credential.response.authenticatorData);
+    response.signature = encode(credential.response.signature);
+
+    if (credential.response.userHandle) {
+      response.userHandle = encode(credential.response.userHandle);
+    }
+  }
+
+  return {
+    id: credential.id,
+    rawId: encode(credential.rawId),
+    type: credential.type,
+    authenticatorAttachment: credential.authenticatorAttachment || undefined,
+    clientExtensionResults: credential.getClientExtensionResults(),
+    response,
+  };
+}
+
+async function api(path, body) {
+  const response = await fetch(path, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(body),
+  });
+
+  const result = await response.json();
+
+  if (!response.ok) {
+    throw new Error(result.error);
+  }
+
+  return result;
+}
+
+const status = document.getElementById('status');
+const username = document.getElementById('username');
+
+function show(message, error) {
+  status.textContent = message;
+  status.className = error ? 'error' : '';
+}
+
+// autofill is the AbortController of the conditional login in progress, which has to be aborted before any other
+// navigator.credentials call can be made.
+let autofill = null;
+
+async function register() {
+  if (autofill) {
+    autofill.abort();
+  }
+
+  try {
+    const options = await api('/api/register/begin', { username: username.value });
+
+    options.publicKey.challenge = decode(options.publicKey.challenge);
+    options.publicKey.user.id = decode(options.publicKey.user.id);
+    options.publicKey.excludeCredentials = decodeDescriptors(options.publicKey.excludeCredentials);
+
+    const credential = await navigator.credentials.create(options);
+    const result = await api('/api/register/finish', encodeCredential(credential));
+
+    show(`Registered a credential for ${result.username}.`);
+  } catch (err) {
+    show(err.message, true);
+  }
+
+  startAutofill();
+}
+
+async function login(conditional) {
+  const options = await api('/api/login/begin', { username: conditional ? '' : username.value });
+
+  options.publicKey.challenge = decode(options.publicKey.challenge);
+  options.publicKey.allowCredentials = decodeDescriptors(options.publicKey.allowCredentials);
+
+  if (conditional) {
+    autofill = new AbortController();
+    options.mediation = 'conditional';
+    options.signal = autofill.signal;
+  }
+
+  const credential = await navigator.credentials.get(options);
+  const result = await api('/api/login/finish', encodeCredential(credential));
+
+  show(`Logged in as ${result.username}.`);
+}
+
+async function modalLogin() {
+  if (autofill) {
+    autofill.abort();
+  }
+
+  try {
+    await login(false);
+  } catch (err) {
+    show(err.message, true);
+  }
+
+  startAutofill();
+}
+
+// startAutofill starts a discoverable login with conditional mediation so that the browser offers the passkeys of the
+// site in the autofill of the username input.
+async function startAutofill() {
+  if (!window.PublicKeyCredential || !PublicKeyCredential.isConditionalMediationAvailable) {
+    return;
+  }
+
+  if (!(await PublicKeyCredential.isConditionalMediationAvailable())) {
+    return;
+  }
+
+  try {
+    await login(true);
+  } catch (err) {
+    if (err.name !== 'AbortError') {
+      show(err.message, true);
+    }
+  }
+}
+
+if (!window.PublicKeyCredential) {
+  show('WebAuthn is not supported by this browser.', true);
+} else {
+  document.getElementById('register').addEventListener('click', register);
+  document.getElementById('login').addEventListener('click', modalLogin);
+
+  startAutofill();
+}
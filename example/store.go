@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"sync"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// user is the in-memory implementation of webauthn.User.
+type user struct {
+	id          []byte
+	name        string
+	displayName string
+	credentials []webauthn.Credential
+}
+
+func (u *user) WebAuthnID() []byte {
+	return u.id
+}
+
+func (u *user) WebAuthnName() string {
+	return u.name
+}
+
+func (u *user) WebAuthnDisplayName() string {
+	return u.displayName
+}
+
+func (u *user) WebAuthnIcon() string {
+	return ""
+}
+
+func (u *user) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+// session holds the webauthn.SessionData of the ceremonies in progress for a browser. The registration and login are
+// kept separately as the frontend starts a conditional login in the background while a registration may be performed.
+type session struct {
+	username     string
+	registration *webauthn.SessionData
+	login        *webauthn.SessionData
+}
+
+// store keeps the users, their credentials and the sessions in memory. Every value returned by the store is a copy so
+// that it can be used without holding the lock while the ceremonies are performed.
+type store struct {
+	mu       sync.Mutex
+	users    map[string]*user
+	sessions map[string]*session
+}
+
+func newStore() *store {
+	return &store{
+		users:    map[string]*user{},
+		sessions: map[string]*session{},
+	}
+}
+
+// user returns the user with the name.
+func (s *store) user(name string) (*user, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[name]
+	if !ok {
+		return nil, false
+	}
+
+	return u.clone(), true
+}
+
+// userByID returns the user with the user handle.
+func (s *store) userByID(id []byte) (*user, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if bytes.Equal(u.id, id) {
+			return u.clone(), true
+		}
+	}
+
+	return nil, false
+}
+
+// userOrCreate returns the user with the name, creating it with a random user handle if it does not exist yet.
+func (s *store) userOrCreate(name string) (*user, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u, ok := s.users[name]; ok {
+		return u.clone(), nil
+	}
+
+	id, err := random(32)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &user{id: id, name: name, displayName: name}
+
+	s.users[name] = u
+
+	return u.clone(), nil
+}
+
+// saveCredential adds the credential to the user or replaces the credential with the same ID.
+func (s *store) saveCredential(name string, credential webauthn.Credential) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[name]
+	if !ok {
+		return
+	}
+
+	for i := range u.credentials {
+		if bytes.Equal(u.credentials[i].ID, credential.ID) {
+			u.credentials[i] = credential
+
+			return
+		}
+	}
+
+	u.credentials = append(u.credentials, credential)
+}
+
+// newSession returns the ID of a new empty session.
+func (s *store) newSession() (string, error) {
+	raw, err := random(32)
+	if err != nil {
+		return "", err
+	}
+
+	id := base64.RawURLEncoding.EncodeToString(raw)
+
+	s.mu.Lock()
+	s.sessions[id] = &session{}
+	s.mu.Unlock()
+
+	return id, nil
+}
+
+// updateSession calls update with the session with the ID while holding the lock, and returns false if there is no
+// session with the ID.
+func (s *store) updateSession(id string, update func(*session)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[id]
+	if !ok {
+		return false
+	}
+
+	update(sess)
+
+	return true
+}
+
+func (u *user) clone() *user {
+	c := *u
+
+	c.credentials = append([]webauthn.Credential(nil), u.credentials...)
+
+	return &c
+}
+
+func random(n int) ([]byte, error) {
+	raw := make([]byte, n)
+
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+
+	return raw, nil
+}
@@ -0,0 +1,139 @@
+package metadata
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Catalog is a queryable catalog of the human friendly names and icons of authenticator models, such as the passkey
+// providers iCloud Keychain, Google Password Manager, 1Password, and Bitwarden, keyed by their AAGUID. It's intended
+// for account management interfaces which list the passkeys of a user. A Catalog is immutable and safe for concurrent
+// use.
+type Catalog struct {
+	names map[uuid.UUID]AuthenticatorName
+}
+
+// CatalogEntry is an AuthenticatorName of a Catalog along with its AAGUID.
+type CatalogEntry struct {
+	AAGUID uuid.UUID `json:"aaguid"`
+
+	AuthenticatorName
+}
+
+var (
+	defaultCatalogOnce sync.Once
+	defaultCatalog     *Catalog
+)
+
+// DefaultCatalog returns the Catalog of the embedded community list of passkey provider AAGUIDs.
+func DefaultCatalog() *Catalog {
+	defaultCatalogOnce.Do(func() {
+		defaultCatalog = &Catalog{names: parseAuthenticatorNames(aaguids)}
+	})
+
+	return defaultCatalog
+}
+
+// NewCatalog returns a Catalog parsed from the JSON format of the community list of passkey provider AAGUIDs, which
+// is an object with the AAGUIDs as the keys and the AuthenticatorName as the values. It allows a newer snapshot of the
+// list, or a list of the authenticators of a deployment, to be used instead of the embedded list.
+func NewCatalog(data []byte) (catalog *Catalog, err error) {
+	catalog = &Catalog{}
+
+	if catalog.names, err = decodeAuthenticatorNames(data); err != nil {
+		return nil, fmt.Errorf("metadata: error parsing catalog: %w", err)
+	}
+
+	return catalog, nil
+}
+
+// With returns a copy of the Catalog with the entries added, replacing any entries with the same AAGUID. This allows
+// the names or icons of the embedded list to be customized.
+func (c *Catalog) With(entries ...CatalogEntry) *Catalog {
+	catalog := &Catalog{names: make(map[uuid.UUID]AuthenticatorName, len(c.names)+len(entries))}
+
+	for aaguid, name := range c.names {
+		catalog.names[aaguid] = name
+	}
+
+	for _, entry := range entries {
+		catalog.names[entry.AAGUID] = entry.AuthenticatorName
+	}
+
+	return catalog
+}
+
+// Len returns the number of entries of the Catalog.
+func (c *Catalog) Len() int {
+	return len(c.names)
+}
+
+// Lookup returns the AuthenticatorName of the AAGUID if it's in the Catalog.
+func (c *Catalog) Lookup(aaguid uuid.UUID) (name AuthenticatorName, ok bool) {
+	name, ok = c.names[aaguid]
+
+	return name, ok
+}
+
+// LookupWithProvider returns the AuthenticatorName of the AAGUID. The name and icons of the Catalog are preferred as
+// they're generally the more familiar to users, and the description and icon of the metadata statement from the
+// provider are used for anything the Catalog doesn't include.
+func (c *Catalog) LookupWithProvider(provider Provider, aaguid uuid.UUID) (name AuthenticatorName, ok bool) {
+	name, ok = c.Lookup(aaguid)
+
+	if provider == nil {
+		return name, ok
+	}
+
+	entry, found := provider.LookupByAAGUID(aaguid)
+	if !found {
+		return name, ok
+	}
+
+	if name.Name == "" {
+		name.Name = entry.MetadataStatement.Description
+	}
+
+	if name.IconDark == "" {
+		name.IconDark = entry.MetadataStatement.Icon
+	}
+
+	if name.IconLight == "" {
+		name.IconLight = entry.MetadataStatement.Icon
+	}
+
+	return name, name.Name != ""
+}
+
+// Entries returns every entry of the Catalog sorted by name and then by AAGUID.
+func (c *Catalog) Entries() []CatalogEntry {
+	return c.Search("")
+}
+
+// Search returns the entries of the Catalog with a name which contains the query ignoring case, sorted by name and
+// then by AAGUID. An empty query matches every entry.
+func (c *Catalog) Search(query string) (entries []CatalogEntry) {
+	query = strings.ToLower(query)
+
+	for aaguid, name := range c.names {
+		if query != "" && !strings.Contains(strings.ToLower(name.Name), query) {
+			continue
+		}
+
+		entries = append(entries, CatalogEntry{AAGUID: aaguid, AuthenticatorName: name})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Name != entries[j].Name {
+			return entries[i].Name < entries[j].Name
+		}
+
+		return entries[i].AAGUID.String() < entries[j].AAGUID.String()
+	})
+
+	return entries
+}
@@ -0,0 +1,88 @@
+package metadata
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCatalog(t *testing.T) {
+	testCases := []struct {
+		name     string
+		have     string
+		expected int
+		err      string
+	}{
+		{"ShouldParse", `{"fbfc3007-154e-4ecc-8c0b-6e020557d7bd":{"name":"iCloud Keychain","icon_dark":"data:image/svg+xml;base64,AAAA"}}`, 1, ""},
+		{"ShouldParseEmpty", `{}`, 0, ""},
+		{"ShouldFailInvalidAAGUID", `{"invalid":{"name":"Invalid"}}`, 0, "metadata: error parsing catalog: invalid aaguid 'invalid'"},
+		{"ShouldFailInvalidJSON", `[]`, 0, "metadata: error parsing catalog: json: cannot unmarshal array into Go value of type map[string]metadata.AuthenticatorName"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			catalog, err := NewCatalog([]byte(tc.have))
+
+			if tc.err == "" {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, catalog.Len())
+			} else {
+				assert.EqualError(t, err, tc.err)
+				assert.Nil(t, catalog)
+			}
+		})
+	}
+}
+
+func TestCatalog_Search(t *testing.T) {
+	catalog := DefaultCatalog()
+
+	assert.Equal(t, catalog.Len(), len(catalog.Entries()))
+
+	entries := catalog.Search("windows")
+
+	require.Len(t, entries, 3)
+
+	for i, entry := range entries {
+		assert.Equal(t, "Windows Hello", entry.Name)
+
+		if i > 0 {
+			assert.Less(t, entries[i-1].AAGUID.String(), entry.AAGUID.String())
+		}
+	}
+
+	entries = catalog.Search("YUBIKEY 5 series with")
+
+	require.Len(t, entries, 2)
+	assert.Equal(t, "YubiKey 5 Series with NFC", entries[0].Name)
+
+	assert.Empty(t, catalog.Search("unknown provider"))
+}
+
+func TestCatalog_With(t *testing.T) {
+	custom := uuid.MustParse("7b0c0d9b-7e5c-4b7a-9d4f-2c1a0e6e9c11")
+	known := uuid.MustParse("bada5566-a7aa-401f-bd96-45619a55120d")
+
+	catalog := DefaultCatalog().With(
+		CatalogEntry{AAGUID: custom, AuthenticatorName: AuthenticatorName{Name: "Corporate Key"}},
+		CatalogEntry{AAGUID: known, AuthenticatorName: AuthenticatorName{Name: "1Password", IconLight: "data:image/png;base64,AAAA"}},
+	)
+
+	assert.Equal(t, DefaultCatalog().Len()+1, catalog.Len())
+
+	name, ok := catalog.Lookup(custom)
+	assert.True(t, ok)
+	assert.Equal(t, "Corporate Key", name.Name)
+
+	name, ok = catalog.Lookup(known)
+	assert.True(t, ok)
+	assert.Equal(t, "data:image/png;base64,AAAA", name.IconLight)
+
+	_, ok = DefaultCatalog().Lookup(custom)
+	assert.False(t, ok)
+
+	name, _ = DefaultCatalog().Lookup(known)
+	assert.Empty(t, name.IconLight)
+}
@@ -0,0 +1,73 @@
+package metadata
+
+import (
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// KeyIdentifiers is a map of attestation certificate key identifiers to corresponding metadata statements. This is
+// used to index the entries of U2F authenticators which don't have an AAGUID.
+var KeyIdentifiers = make(map[string]MetadataBLOBPayloadEntry)
+
+// AddEntry adds the MetadataBLOBPayloadEntry to the Metadata index if it has an AAGUID and to the KeyIdentifiers index
+// for each of its attestation certificate key identifiers.
+func AddEntry(entry MetadataBLOBPayloadEntry) {
+	if entry.AaGUID != "" {
+		if aaguid, err := uuid.Parse(entry.AaGUID); err == nil {
+			Metadata[aaguid] = entry
+		}
+	}
+
+	for _, keyIdentifier := range entry.AttestationCertificateKeyIdentifiers {
+		KeyIdentifiers[strings.ToLower(keyIdentifier)] = entry
+	}
+}
+
+// LookupByAAGUID returns the MetadataBLOBPayloadEntry for the AAGUID if it is present in the Metadata.
+func LookupByAAGUID(aaguid uuid.UUID) (entry MetadataBLOBPayloadEntry, ok bool) {
+	entry, ok = Metadata[aaguid]
+
+	return entry, ok
+}
+
+// LookupByKeyIdentifier returns the MetadataBLOBPayloadEntry for the hex encoded attestation certificate key
+// identifier if it is present in the Metadata.
+func LookupByKeyIdentifier(keyIdentifier string) (entry MetadataBLOBPayloadEntry, ok bool) {
+	entry, ok = KeyIdentifiers[strings.ToLower(keyIdentifier)]
+
+	return entry, ok
+}
+
+// LookupByCertificate returns the MetadataBLOBPayloadEntry for the key identifier of the attestation certificate if
+// it is present in the Metadata.
+func LookupByCertificate(cert *x509.Certificate) (entry MetadataBLOBPayloadEntry, ok bool) {
+	keyIdentifier, err := KeyIdentifier(cert)
+	if err != nil {
+		return entry, false
+	}
+
+	return LookupByKeyIdentifier(keyIdentifier)
+}
+
+// KeyIdentifier returns the attestation certificate key identifier of the certificate, which is the hex encoded SHA-1
+// hash of the subjectPublicKey of the certificate.
+func KeyIdentifier(cert *x509.Certificate) (string, error) {
+	var spki struct {
+		Algorithm        pkix.AlgorithmIdentifier
+		SubjectPublicKey asn1.BitString
+	}
+
+	if _, err := asn1.Unmarshal(cert.RawSubjectPublicKeyInfo, &spki); err != nil {
+		return "", err
+	}
+
+	sum := sha1.Sum(spki.SubjectPublicKey.Bytes)
+
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,80 @@
+package metadata
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddEntry(t *testing.T) {
+	aaguid := uuid.MustParse("3e2a5b1c-7d4f-4a8e-9b6c-1f0e2d3c4b5a")
+
+	defer func() {
+		delete(Metadata, aaguid)
+		delete(KeyIdentifiers, "923881fe2f214ee465484371aeb72e97f5a58e0a")
+	}()
+
+	AddEntry(MetadataBLOBPayloadEntry{AaGUID: aaguid.String()})
+	AddEntry(MetadataBLOBPayloadEntry{AttestationCertificateKeyIdentifiers: []string{"923881FE2F214EE465484371AEB72E97F5A58E0A"}})
+
+	entry, ok := LookupByAAGUID(aaguid)
+	assert.True(t, ok)
+	assert.Equal(t, aaguid.String(), entry.AaGUID)
+
+	_, ok = LookupByAAGUID(uuid.Nil)
+	assert.False(t, ok)
+
+	entry, ok = LookupByKeyIdentifier("923881fe2f214ee465484371aeb72e97f5a58e0a")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"923881FE2F214EE465484371AEB72E97F5A58E0A"}, entry.AttestationCertificateKeyIdentifiers)
+
+	_, ok = LookupByKeyIdentifier("0000000000000000000000000000000000000000")
+	assert.False(t, ok)
+}
+
+func TestLookupByCertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "U2F Attestation"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(raw)
+	require.NoError(t, err)
+
+	sum := sha1.Sum(elliptic.Marshal(elliptic.P256(), key.X, key.Y))
+	expected := hex.EncodeToString(sum[:])
+
+	keyIdentifier, err := KeyIdentifier(cert)
+	require.NoError(t, err)
+	assert.Equal(t, expected, keyIdentifier)
+
+	_, ok := LookupByCertificate(cert)
+	assert.False(t, ok)
+
+	defer delete(KeyIdentifiers, expected)
+
+	AddEntry(MetadataBLOBPayloadEntry{AttestationCertificateKeyIdentifiers: []string{expected}})
+
+	entry, ok := LookupByCertificate(cert)
+	assert.True(t, ok)
+	assert.Equal(t, []string{expected}, entry.AttestationCertificateKeyIdentifiers)
+}
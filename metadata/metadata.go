@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"context"
 	"crypto/x509"
 	"encoding/base64"
 	"errors"
@@ -16,6 +17,7 @@ import (
 	"github.com/go-webauthn/x/revoke"
 
 	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/tracing"
 )
 
 type PublicKeyCredentialParameters struct {
@@ -42,6 +44,9 @@ var Conformance = false
 
 var MDSRoot = ProductionMDSRoot
 
+// TracerProvider enables tracing of the metadata fetches and chain validation when configured.
+var TracerProvider tracing.TracerProvider
+
 // MetadataBLOBPayloadEntry - Represents the MetadataBLOBPayloadEntry
 // https://fidoalliance.org/specs/mds/fido-metadata-service-v3.0-ps-20210518.html#metadata-blob-payload-entry-dictionary
 type MetadataBLOBPayloadEntry struct {
@@ -183,6 +188,62 @@ func IsUndesiredAuthenticatorStatus(status AuthenticatorStatus) bool {
 	return false
 }
 
+// CertificationLevels are the FIDO certification statuses ordered from the least to the most strict level.
+var CertificationLevels = [...]AuthenticatorStatus{
+	FidoCertified,
+	FidoCertifiedL1,
+	FidoCertifiedL1plus,
+	FidoCertifiedL2,
+	FidoCertifiedL2plus,
+	FidoCertifiedL3,
+	FidoCertifiedL3plus,
+}
+
+// CertificationRank returns the rank of the supplied authenticator status in the CertificationLevels starting at 1,
+// or 0 if it's not a certification status.
+func CertificationRank(status AuthenticatorStatus) int {
+	for i, s := range CertificationLevels {
+		if s == status {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// CertificationLevel returns the highest FIDO certification status of the status reports of the entry, or
+// NotFidoCertified if there are none.
+func (e MetadataBLOBPayloadEntry) CertificationLevel() AuthenticatorStatus {
+	level := NotFidoCertified
+
+	for _, report := range e.StatusReports {
+		if CertificationRank(report.Status) > CertificationRank(level) {
+			level = report.Status
+		}
+	}
+
+	return level
+}
+
+// MeetsCertificationLevel returns true if the highest FIDO certification status of the status reports of the entry is
+// at least as strict as the supplied minimum certification status.
+func (e MetadataBLOBPayloadEntry) MeetsCertificationLevel(minimum AuthenticatorStatus) bool {
+	return CertificationRank(e.CertificationLevel()) >= CertificationRank(minimum)
+}
+
+// AuthenticatorVersion returns the firmware version of the authenticator model identified by the entry, which is the
+// firmwareVersion of the authenticatorGetInfo of the metadata statement or the earliest trustworthy
+// authenticatorVersion of the metadata statement, whichever is higher.
+func (e MetadataBLOBPayloadEntry) AuthenticatorVersion() uint32 {
+	version := e.MetadataStatement.AuthenticatorVersion
+
+	if firmware := uint32(e.MetadataStatement.AuthenticatorGetInfo.FirmwareVersion); firmware > version {
+		version = firmware
+	}
+
+	return version
+}
+
 // RogueListEntry - Contains a list of individual authenticators known to be rogue
 type RogueListEntry struct {
 	// Base64url encoding of the rogue authenticator's secret key
@@ -196,7 +257,7 @@ type MetadataBLOBPayload struct {
 	// The legalHeader, if present, contains a legal guide for accessing and using metadata, which itself MAY contain URL(s) pointing to further information, such as a full Terms and Conditions statement.
 	LegalHeader string `json:"legalHeader"`
 	// The serial number of this UAF Metadata TOC Payload. Serial numbers MUST be consecutive and strictly monotonic, i.e. the successor TOC will have a no value exactly incremented by one.
-	Number int `json:"no"`
+	Number int `json:"no" mapstructure:"no"`
 	// ISO-8601 formatted date when the next update will be provided at latest.
 	NextUpdate string `json:"nextUpdate"`
 	// List of zero or more MetadataTOCPayloadEntry objects.
@@ -545,7 +606,15 @@ type MDSGetEndpointsResponse struct {
 	Result []string `json:"result"`
 }
 
-func unmarshalMDSBLOB(body []byte, c http.Client) (MetadataBLOBPayload, error) {
+func unmarshalMDSBLOB(ctx context.Context, body []byte, c http.Client) (MetadataBLOBPayload, error) {
+	return verifyMDSBLOB(ctx, body, func(ctx context.Context, chain []interface{}) (bool, error) {
+		return validateChain(ctx, chain, c)
+	})
+}
+
+// verifyMDSBLOB verifies the signature of the metadata BLOB after validating its certificate chain with the validate
+// function, and decodes its payload.
+func verifyMDSBLOB(ctx context.Context, body []byte, validate func(ctx context.Context, chain []interface{}) (bool, error)) (MetadataBLOBPayload, error) {
 	var payload MetadataBLOBPayload
 
 	token, err := jwt.Parse(string(body), func(token *jwt.Token) (interface{}, error) {
@@ -565,7 +634,12 @@ func unmarshalMDSBLOB(body []byte, c http.Client) (MetadataBLOBPayload, error) {
 		}
 
 		// The certificate chain MUST be verified to properly chain to the metadata TOC signing trust anchor.
-		valid, err := validateChain(chain, c)
+		_, span := tracing.Start(ctx, TracerProvider, "metadata.validate_chain")
+
+		valid, err := validate(ctx, chain)
+
+		tracing.End(span, err)
+
 		if !valid || err != nil {
 			return nil, err
 		}
@@ -600,23 +674,12 @@ func unmarshalMDSBLOB(body []byte, c http.Client) (MetadataBLOBPayload, error) {
 	return payload, err
 }
 
-func validateChain(chain []interface{}, c http.Client) (bool, error) {
-	oRoot := make([]byte, base64.StdEncoding.DecodedLen(len(MDSRoot)))
-
-	nRoot, err := base64.StdEncoding.Decode(oRoot, []byte(MDSRoot))
-	if err != nil {
-		return false, err
-	}
-
-	rootcert, err := x509.ParseCertificate(oRoot[:nRoot])
+func validateChain(ctx context.Context, chain []interface{}, c http.Client) (bool, error) {
+	roots, err := mdsRootCertPool()
 	if err != nil {
 		return false, err
 	}
 
-	roots := x509.NewCertPool()
-
-	roots.AddCert(rootcert)
-
 	o := make([]byte, base64.StdEncoding.DecodedLen(len(chain[1].(string))))
 
 	n, err := base64.StdEncoding.Decode(o, []byte(chain[1].(string)))
@@ -629,6 +692,11 @@ func validateChain(chain []interface{}, c http.Client) (bool, error) {
 		return false, err
 	}
 
+	// The revocation checks do not accept a context so the context is checked before each of them.
+	if err = ctx.Err(); err != nil {
+		return false, err
+	}
+
 	if revoked, ok := revoke.VerifyCertificate(intcert); !ok {
 		issuer := intcert.IssuingCertificateURL
 
@@ -654,6 +722,10 @@ func validateChain(chain []interface{}, c http.Client) (bool, error) {
 		return false, err
 	}
 
+	if err = ctx.Err(); err != nil {
+		return false, err
+	}
+
 	if revoked, ok := revoke.VerifyCertificate(leafcert); !ok {
 		return false, errCRLUnavailable
 	} else if revoked {
@@ -698,12 +770,30 @@ func (err *MetadataError) Error() string {
 	return err.Details
 }
 
-func PopulateMetadata(url string) error {
+// PopulateMetadata downloads the metadata BLOB from the url, verifies it, and adds its entries to the Metadata.
+func PopulateMetadata(url string) (err error) {
+	return PopulateMetadataContext(context.Background(), url)
+}
+
+// PopulateMetadataContext is the same as PopulateMetadata except the download and the revocation checks of the
+// certificate chain are performed with the context.
+func PopulateMetadataContext(ctx context.Context, url string) (err error) {
+	ctx, span := tracing.Start(ctx, TracerProvider, "metadata.populate_metadata", tracing.String(tracing.AttributeMetadataURL, url))
+
+	defer func() {
+		tracing.End(span, err)
+	}()
+
 	c := &http.Client{
 		Timeout: time.Second * 30,
 	}
 
-	res, err := c.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.Do(req)
 	if err != nil {
 		return err
 	}
@@ -715,14 +805,13 @@ func PopulateMetadata(url string) error {
 		return err
 	}
 
-	blob, err := unmarshalMDSBLOB(body, *c)
+	blob, err := unmarshalMDSBLOB(ctx, body, *c)
 	if err != nil {
 		return err
 	}
 
 	for _, entry := range blob.Entries {
-		aaguid, _ := uuid.Parse(entry.AaGUID)
-		Metadata[aaguid] = entry
+		AddEntry(entry)
 	}
 
 	return err
@@ -2,6 +2,7 @@ package metadata
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -146,7 +147,7 @@ func TestConformanceMetadataTOCParsing(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		blob, err := unmarshalMDSBLOB(bytes, *httpClient)
+		blob, err := unmarshalMDSBLOB(context.Background(), bytes, *httpClient)
 		if err != nil {
 			if me, ok := err.(*MetadataError); ok {
 				t.Log(me.Details)
@@ -200,7 +201,7 @@ func TestExampleMetadataTOCParsing(t *testing.T) {
 
 	exampleMetadataBLOBBytes := bytes.NewBufferString(exampleMetadataBLOB)
 
-	_, err := unmarshalMDSBLOB(exampleMetadataBLOBBytes.Bytes(), *httpClient)
+	_, err := unmarshalMDSBLOB(context.Background(), exampleMetadataBLOBBytes.Bytes(), *httpClient)
 	if err != nil {
 		t.Fail()
 	}
@@ -282,6 +283,69 @@ func TestIsUndesiredAuthenticatorStatus(t *testing.T) {
 	}
 }
 
+func TestMetadataBLOBPayloadEntry_CertificationLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		reports  []AuthenticatorStatus
+		minimum  AuthenticatorStatus
+		level    AuthenticatorStatus
+		expected bool
+	}{
+		{"ShouldHandleNoReports", nil, FidoCertifiedL1, NotFidoCertified, false},
+		{"ShouldHandleNotCertified", []AuthenticatorStatus{NotFidoCertified, UpdateAvailable}, FidoCertifiedL1, NotFidoCertified, false},
+		{"ShouldMeetEqualLevel", []AuthenticatorStatus{FidoCertifiedL1}, FidoCertifiedL1, FidoCertifiedL1, true},
+		{"ShouldMeetLowerLevel", []AuthenticatorStatus{FidoCertifiedL2}, FidoCertifiedL1plus, FidoCertifiedL2, true},
+		{"ShouldNotMeetHigherLevel", []AuthenticatorStatus{FidoCertifiedL1plus}, FidoCertifiedL2, FidoCertifiedL1plus, false},
+		{"ShouldUseHighestLevel", []AuthenticatorStatus{FidoCertifiedL3, FidoCertifiedL1, UpdateAvailable}, FidoCertifiedL3, FidoCertifiedL3, true},
+		{"ShouldRankLegacyBelowL1", []AuthenticatorStatus{FidoCertified}, FidoCertifiedL1, FidoCertified, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := MetadataBLOBPayloadEntry{}
+
+			for _, status := range tt.reports {
+				entry.StatusReports = append(entry.StatusReports, StatusReport{Status: status})
+			}
+
+			if level := entry.CertificationLevel(); level != tt.level {
+				t.Errorf("CertificationLevel() = %s, want %s", level, tt.level)
+			}
+
+			if meets := entry.MeetsCertificationLevel(tt.minimum); meets != tt.expected {
+				t.Errorf("MeetsCertificationLevel(%s) = %t, want %t", tt.minimum, meets, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMetadataBLOBPayloadEntry_AuthenticatorVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  uint32
+		firmware uint
+		expected uint32
+	}{
+		{"ShouldHandleZero", 0, 0, 0},
+		{"ShouldUseAuthenticatorVersion", 5, 0, 5},
+		{"ShouldUseFirmwareVersion", 0, 7, 7},
+		{"ShouldUseHighestVersion", 9, 7, 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := MetadataBLOBPayloadEntry{}
+
+			entry.MetadataStatement.AuthenticatorVersion = tt.version
+			entry.MetadataStatement.AuthenticatorGetInfo.FirmwareVersion = tt.firmware
+
+			if version := entry.AuthenticatorVersion(); version != tt.expected {
+				t.Errorf("AuthenticatorVersion() = %d, want %d", version, tt.expected)
+			}
+		})
+	}
+}
+
 func TestAlgKeyMatch(t *testing.T) {
 	tests := []struct {
 		name string
@@ -0,0 +1,71 @@
+package metadata
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// aaguids is a snapshot of the community maintained list of passkey provider AAGUIDs, see
+// https://github.com/passkeydeveloper/passkey-authenticator-aaguids. Many of these providers, such as the platform and
+// password manager authenticators, are not present in the FIDO Metadata Service.
+//
+//go:embed aaguids.json
+var aaguids []byte
+
+// AuthenticatorName is the human friendly name and icons of an authenticator model, suitable for display to a user
+// managing their credentials.
+type AuthenticatorName struct {
+	// Name is the human friendly name of the authenticator, for example "YubiKey 5 Series".
+	Name string `json:"name"`
+
+	// IconDark is the icon of the authenticator for a dark background as a data URL, if one is known.
+	IconDark string `json:"icon_dark,omitempty"`
+
+	// IconLight is the icon of the authenticator for a light background as a data URL, if one is known.
+	IconLight string `json:"icon_light,omitempty"`
+}
+
+// LookupAuthenticatorName returns the AuthenticatorName of the AAGUID from the embedded community list if it's known.
+func LookupAuthenticatorName(aaguid uuid.UUID) (name AuthenticatorName, ok bool) {
+	return DefaultCatalog().Lookup(aaguid)
+}
+
+// LookupAuthenticatorNameWithProvider returns the AuthenticatorName of the AAGUID. The name and icons of the embedded
+// community list are preferred as they're generally the more familiar to users, and the description and icon of the
+// metadata statement from the provider are used for anything the list doesn't include.
+func LookupAuthenticatorNameWithProvider(provider Provider, aaguid uuid.UUID) (name AuthenticatorName, ok bool) {
+	return DefaultCatalog().LookupWithProvider(provider, aaguid)
+}
+
+func parseAuthenticatorNames(data []byte) map[uuid.UUID]AuthenticatorName {
+	names, err := decodeAuthenticatorNames(data)
+	if err != nil {
+		panic("metadata: invalid embedded aaguids.json: " + err.Error())
+	}
+
+	return names
+}
+
+func decodeAuthenticatorNames(data []byte) (map[uuid.UUID]AuthenticatorName, error) {
+	var raw map[string]AuthenticatorName
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	names := make(map[uuid.UUID]AuthenticatorName, len(raw))
+
+	for key, name := range raw {
+		aaguid, err := uuid.Parse(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid aaguid '%s'", key)
+		}
+
+		names[aaguid] = name
+	}
+
+	return names, nil
+}
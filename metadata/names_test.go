@@ -0,0 +1,70 @@
+package metadata
+
+import (
+	"crypto/x509"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type testNameProvider struct {
+	entries map[uuid.UUID]MetadataBLOBPayloadEntry
+}
+
+func (p testNameProvider) LookupByAAGUID(aaguid uuid.UUID) (MetadataBLOBPayloadEntry, bool) {
+	entry, ok := p.entries[aaguid]
+
+	return entry, ok
+}
+
+func (testNameProvider) LookupByCertificate(_ *x509.Certificate) (MetadataBLOBPayloadEntry, bool) {
+	return MetadataBLOBPayloadEntry{}, false
+}
+
+func TestLookupAuthenticatorName(t *testing.T) {
+	known := uuid.MustParse("fa2b99dc-9e39-4257-8f92-4a30d23c4118")
+	unknown := uuid.MustParse("7b0c0d9b-7e5c-4b7a-9d4f-2c1a0e6e9c11")
+
+	provider := testNameProvider{entries: map[uuid.UUID]MetadataBLOBPayloadEntry{
+		known:   {MetadataStatement: MetadataStatement{Description: "Official Description", Icon: "data:image/png;base64,AAAA"}},
+		unknown: {MetadataStatement: MetadataStatement{Description: "Metadata Only", Icon: "data:image/png;base64,BBBB"}},
+	}}
+
+	testCases := []struct {
+		name     string
+		provider Provider
+		aaguid   uuid.UUID
+		expected AuthenticatorName
+		ok       bool
+	}{
+		{"ShouldReturnEmbeddedName", nil, known, AuthenticatorName{Name: "YubiKey 5 Series with NFC"}, true},
+		{"ShouldNotReturnUnknown", nil, unknown, AuthenticatorName{}, false},
+		{"ShouldPreferEmbeddedNameOverMetadata", provider, known, AuthenticatorName{Name: "YubiKey 5 Series with NFC", IconDark: "data:image/png;base64,AAAA", IconLight: "data:image/png;base64,AAAA"}, true},
+		{"ShouldFallbackToMetadata", provider, unknown, AuthenticatorName{Name: "Metadata Only", IconDark: "data:image/png;base64,BBBB", IconLight: "data:image/png;base64,BBBB"}, true},
+		{"ShouldNotReturnUnknownWithProvider", provider, uuid.Nil, AuthenticatorName{}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			name, ok := LookupAuthenticatorNameWithProvider(tc.provider, tc.aaguid)
+
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, name)
+		})
+	}
+}
+
+func TestParseAuthenticatorNames(t *testing.T) {
+	names := parseAuthenticatorNames(aaguids)
+
+	assert.NotEmpty(t, names)
+
+	for aaguid, name := range names {
+		assert.NotEqual(t, uuid.Nil, aaguid)
+		assert.NotEmpty(t, name.Name)
+	}
+
+	assert.Panics(t, func() { parseAuthenticatorNames([]byte(`{"invalid":{"name":"Invalid"}}`)) })
+	assert.Panics(t, func() { parseAuthenticatorNames([]byte(`[]`)) })
+}
@@ -0,0 +1,68 @@
+package metadata
+
+import (
+	"context"
+	"crypto/x509"
+
+	"github.com/google/uuid"
+)
+
+// Provider looks up the metadata of authenticators during the verification of attestation statements. It allows the
+// package level Metadata to be replaced, for example with a per tenant set of entries or with a mock in tests.
+// Implementations must be safe for concurrent use.
+type Provider interface {
+	// LookupByAAGUID returns the MetadataBLOBPayloadEntry for the AAGUID if it's known.
+	LookupByAAGUID(aaguid uuid.UUID) (entry MetadataBLOBPayloadEntry, ok bool)
+
+	// LookupByCertificate returns the MetadataBLOBPayloadEntry for the attestation certificate if it's known. It's
+	// used for U2F authenticators which don't have an AAGUID.
+	LookupByCertificate(cert *x509.Certificate) (entry MetadataBLOBPayloadEntry, ok bool)
+}
+
+// DefaultProvider is the Provider which looks up the package level Metadata and KeyIdentifiers.
+var DefaultProvider Provider = defaultProvider{}
+
+type defaultProvider struct{}
+
+func (defaultProvider) LookupByAAGUID(aaguid uuid.UUID) (MetadataBLOBPayloadEntry, bool) {
+	return LookupByAAGUID(aaguid)
+}
+
+func (defaultProvider) LookupByCertificate(cert *x509.Certificate) (MetadataBLOBPayloadEntry, bool) {
+	return LookupByCertificate(cert)
+}
+
+// ContextProvider is a Provider which uses a context.Context for the lookups, for example a Provider backed by a remote
+// metadata service which must honor the cancellation and deadline of the ceremony.
+type ContextProvider interface {
+	Provider
+
+	// LookupByAAGUIDContext is the same as LookupByAAGUID except it accepts a context.Context.
+	LookupByAAGUIDContext(ctx context.Context, aaguid uuid.UUID) (entry MetadataBLOBPayloadEntry, ok bool)
+
+	// LookupByCertificateContext is the same as LookupByCertificate except it accepts a context.Context.
+	LookupByCertificateContext(ctx context.Context, cert *x509.Certificate) (entry MetadataBLOBPayloadEntry, ok bool)
+}
+
+// WithContext returns a Provider which performs the lookups of the provider with the context if it's a
+// ContextProvider, otherwise the provider is returned as is.
+func WithContext(ctx context.Context, provider Provider) Provider {
+	if p, ok := provider.(ContextProvider); ok {
+		return contextProvider{ctx: ctx, provider: p}
+	}
+
+	return provider
+}
+
+type contextProvider struct {
+	ctx      context.Context
+	provider ContextProvider
+}
+
+func (p contextProvider) LookupByAAGUID(aaguid uuid.UUID) (MetadataBLOBPayloadEntry, bool) {
+	return p.provider.LookupByAAGUIDContext(p.ctx, aaguid)
+}
+
+func (p contextProvider) LookupByCertificate(cert *x509.Certificate) (MetadataBLOBPayloadEntry, bool) {
+	return p.provider.LookupByCertificateContext(p.ctx, cert)
+}
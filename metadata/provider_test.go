@@ -0,0 +1,40 @@
+package metadata
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type contextKey struct{}
+
+type testContextProvider struct {
+	defaultProvider
+}
+
+func (testContextProvider) LookupByAAGUIDContext(ctx context.Context, _ uuid.UUID) (MetadataBLOBPayloadEntry, bool) {
+	return MetadataBLOBPayloadEntry{AaGUID: ctx.Value(contextKey{}).(string)}, true
+}
+
+func (testContextProvider) LookupByCertificateContext(ctx context.Context, _ *x509.Certificate) (MetadataBLOBPayloadEntry, bool) {
+	return MetadataBLOBPayloadEntry{AaGUID: ctx.Value(contextKey{}).(string)}, true
+}
+
+func TestWithContext(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contextKey{}, "context")
+
+	provider := WithContext(ctx, testContextProvider{})
+
+	entry, ok := provider.LookupByAAGUID(uuid.Nil)
+	assert.True(t, ok)
+	assert.Equal(t, "context", entry.AaGUID)
+
+	entry, ok = provider.LookupByCertificate(&x509.Certificate{})
+	assert.True(t, ok)
+	assert.Equal(t, "context", entry.AaGUID)
+
+	assert.Equal(t, DefaultProvider, WithContext(ctx, DefaultProvider))
+}
@@ -0,0 +1,117 @@
+package metadata
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// certPoolCache caches the parsed *x509.CertPool of a set of base64 encoded DER certificates so that the trust roots
+// are only parsed once instead of on every metadata fetch or registration.
+type certPoolCache struct {
+	mu    sync.RWMutex
+	pools map[interface{}]cachedCertPool
+}
+
+type cachedCertPool struct {
+	certs []string
+	pool  *x509.CertPool
+}
+
+var (
+	mdsRootCertPools         = &certPoolCache{pools: map[interface{}]cachedCertPool{}}
+	attestationRootCertPools = &certPoolCache{pools: map[interface{}]cachedCertPool{}}
+)
+
+// get returns the cached *x509.CertPool for the key if it was parsed from the same certificates, otherwise the
+// certificates are parsed and the result is cached.
+func (c *certPoolCache) get(key interface{}, certs []string) (pool *x509.CertPool, err error) {
+	c.mu.RLock()
+	cached, ok := c.pools[key]
+	c.mu.RUnlock()
+
+	if ok && equalCerts(cached.certs, certs) {
+		return cached.pool, nil
+	}
+
+	if pool, err = parseCertPool(certs); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.pools[key] = cachedCertPool{certs: certs, pool: pool}
+	c.mu.Unlock()
+
+	return pool, nil
+}
+
+// AttestationRootCertPool returns the attestationRootCertificates of the metadata statement for the provided AAGUID
+// as a *x509.CertPool. The certificates are only parsed the first time the pool is requested for the metadata
+// statement and the same pool is returned until the metadata statement changes. The bool is false if the AAGUID is not
+// present in the Metadata.
+func AttestationRootCertPool(aaguid uuid.UUID) (pool *x509.CertPool, found bool, err error) {
+	entry, ok := Metadata[aaguid]
+	if !ok {
+		return nil, false, nil
+	}
+
+	if pool, err = attestationRootCertPools.get(aaguid, entry.MetadataStatement.AttestationRootCertificates); err != nil {
+		return nil, true, err
+	}
+
+	return pool, true, nil
+}
+
+// RootCertPool returns the attestationRootCertificates of the metadata statement of the entry as a *x509.CertPool
+// using the same cache as AttestationRootCertPool. Entries without an AAGUID, such as those of U2F authenticators, are
+// cached by their attestation certificate key identifiers instead.
+func RootCertPool(entry MetadataBLOBPayloadEntry) (*x509.CertPool, error) {
+	var key interface{} = strings.Join(entry.AttestationCertificateKeyIdentifiers, ",")
+
+	if aaguid, err := uuid.Parse(entry.AaGUID); err == nil {
+		key = aaguid
+	}
+
+	return attestationRootCertPools.get(key, entry.MetadataStatement.AttestationRootCertificates)
+}
+
+func mdsRootCertPool() (*x509.CertPool, error) {
+	return mdsRootCertPools.get(MDSRoot, []string{MDSRoot})
+}
+
+func parseCertPool(certs []string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+
+	for _, encoded := range certs {
+		raw, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, err
+		}
+
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		pool.AddCert(cert)
+	}
+
+	return pool, nil
+}
+
+func equalCerts(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
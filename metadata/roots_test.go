@@ -0,0 +1,118 @@
+package metadata
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMDSRootCertPool(t *testing.T) {
+	defer func(root string) {
+		MDSRoot = root
+	}(MDSRoot)
+
+	MDSRoot = ProductionMDSRoot
+
+	production, err := mdsRootCertPool()
+	require.NoError(t, err)
+
+	cached, err := mdsRootCertPool()
+	require.NoError(t, err)
+
+	assert.True(t, production == cached)
+
+	MDSRoot = ConformanceMDSRoot
+
+	conformance, err := mdsRootCertPool()
+	require.NoError(t, err)
+
+	assert.False(t, production == conformance)
+
+	MDSRoot = "invalid"
+
+	_, err = mdsRootCertPool()
+	assert.Error(t, err)
+}
+
+func TestAttestationRootCertPool(t *testing.T) {
+	aaguid := uuid.MustParse("2c0df832-92de-4be1-8412-88a8f074df4a")
+
+	defer delete(Metadata, aaguid)
+
+	pool, found, err := AttestationRootCertPool(aaguid)
+	assert.NoError(t, err)
+	assert.False(t, found)
+	assert.Nil(t, pool)
+
+	Metadata[aaguid] = MetadataBLOBPayloadEntry{
+		AaGUID:            aaguid.String(),
+		MetadataStatement: MetadataStatement{AttestationRootCertificates: []string{ProductionMDSRoot}},
+	}
+
+	pool, found, err = AttestationRootCertPool(aaguid)
+	require.NoError(t, err)
+	assert.True(t, found)
+	require.NotNil(t, pool)
+
+	cached, _, err := AttestationRootCertPool(aaguid)
+	require.NoError(t, err)
+	assert.True(t, pool == cached)
+
+	Metadata[aaguid] = MetadataBLOBPayloadEntry{
+		AaGUID:            aaguid.String(),
+		MetadataStatement: MetadataStatement{AttestationRootCertificates: []string{ConformanceMDSRoot}},
+	}
+
+	updated, _, err := AttestationRootCertPool(aaguid)
+	require.NoError(t, err)
+	assert.False(t, pool == updated)
+
+	Metadata[aaguid] = MetadataBLOBPayloadEntry{
+		AaGUID:            aaguid.String(),
+		MetadataStatement: MetadataStatement{AttestationRootCertificates: []string{"invalid"}},
+	}
+
+	_, found, err = AttestationRootCertPool(aaguid)
+	assert.True(t, found)
+	assert.Error(t, err)
+}
+
+func TestRootCertPool(t *testing.T) {
+	aaguid := uuid.MustParse("6d1e4f2a-8b3c-4e5d-9f0a-1b2c3d4e5f60")
+
+	defer delete(Metadata, aaguid)
+
+	entry := MetadataBLOBPayloadEntry{
+		AaGUID:            aaguid.String(),
+		MetadataStatement: MetadataStatement{AttestationRootCertificates: []string{ProductionMDSRoot}},
+	}
+
+	Metadata[aaguid] = entry
+
+	pool, err := RootCertPool(entry)
+	require.NoError(t, err)
+
+	cached, _, err := AttestationRootCertPool(aaguid)
+	require.NoError(t, err)
+	assert.True(t, pool == cached)
+
+	u2f := MetadataBLOBPayloadEntry{
+		AttestationCertificateKeyIdentifiers: []string{"923881fe2f214ee465484371aeb72e97f5a58e0a"},
+		MetadataStatement:                    MetadataStatement{AttestationRootCertificates: []string{ConformanceMDSRoot}},
+	}
+
+	keyed, err := RootCertPool(u2f)
+	require.NoError(t, err)
+	assert.False(t, pool == keyed)
+
+	cached, err = RootCertPool(u2f)
+	require.NoError(t, err)
+	assert.True(t, keyed == cached)
+
+	u2f.MetadataStatement.AttestationRootCertificates = []string{"invalid"}
+
+	_, err = RootCertPool(u2f)
+	assert.Error(t, err)
+}
@@ -0,0 +1,226 @@
+package metadata
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var errSnapshotStale = &MetadataError{
+	Type:    "snapshot_stale",
+	Details: "Metadata snapshot is past its next update",
+}
+
+var errSnapshotRollback = &MetadataError{
+	Type:    "snapshot_rollback",
+	Details: "Metadata snapshot is older than the current snapshot",
+}
+
+// Snapshot is a metadata BLOB stored locally, which allows operating without access to the metadata service such as
+// in air-gapped deployments. It's a Provider which looks up the entries of the BLOB, so it can be used as the Metadata
+// of the protocol.AttestationPolicy, or its entries can be added to the Metadata with Populate.
+//
+// The metadata service publishes a new BLOB at least by the next update date of the current one, so a Snapshot which
+// is past that date may be missing new authenticators and status reports such as revoked attestation keys. It's the
+// responsibility of the deployment to replace the stored BLOB regularly, and the OnStale handler provides an explicit
+// warning when it's not replaced in time.
+type Snapshot struct {
+	// Payload is the verified payload of the BLOB.
+	Payload MetadataBLOBPayload
+
+	// NextUpdate is the date the next BLOB is published at latest.
+	NextUpdate time.Time
+
+	// OnStale is called the first time an entry is looked up while the Snapshot is stale, so the deployment can warn
+	// the operators the stored BLOB must be replaced.
+	OnStale func(snapshot *Snapshot, now time.Time)
+
+	// Clock returns the current time used to determine if the Snapshot is stale. The default is time.Now.
+	Clock func() time.Time
+
+	aaguids        map[uuid.UUID]MetadataBLOBPayloadEntry
+	keyIdentifiers map[string]MetadataBLOBPayloadEntry
+	warned         sync.Once
+}
+
+// LoadSnapshot reads the metadata BLOB stored at the path and parses it with ParseSnapshot.
+func LoadSnapshot(path string, now time.Time) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseSnapshot(data, now)
+}
+
+// ParseSnapshot verifies the signature of the metadata BLOB and its certificate chain to the MDSRoot at the provided
+// time, and returns the Snapshot of its payload. The revocation of the certificates of the chain is not checked, as
+// the revocation lists can't be fetched offline. A Snapshot which is stale is not rejected, see Snapshot.Stale.
+func ParseSnapshot(data []byte, now time.Time) (snapshot *Snapshot, err error) {
+	payload, err := verifyMDSBLOB(context.Background(), []byte(strings.TrimSpace(string(data))), func(_ context.Context, chain []interface{}) (bool, error) {
+		return validateChainOffline(chain, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot = &Snapshot{
+		Payload:        payload,
+		aaguids:        make(map[uuid.UUID]MetadataBLOBPayloadEntry),
+		keyIdentifiers: make(map[string]MetadataBLOBPayloadEntry),
+	}
+
+	if snapshot.NextUpdate, err = time.Parse("2006-01-02", payload.NextUpdate); err != nil {
+		return nil, fmt.Errorf("error parsing the next update of the metadata snapshot: %w", err)
+	}
+
+	for _, entry := range payload.Entries {
+		if aaguid, err := uuid.Parse(entry.AaGUID); err == nil {
+			snapshot.aaguids[aaguid] = entry
+		}
+
+		for _, keyIdentifier := range entry.AttestationCertificateKeyIdentifiers {
+			snapshot.keyIdentifiers[strings.ToLower(keyIdentifier)] = entry
+		}
+	}
+
+	return snapshot, nil
+}
+
+// Stale returns an error if the Snapshot is past its next update at the provided time.
+func (s *Snapshot) Stale(now time.Time) error {
+	if !now.After(s.NextUpdate) {
+		return nil
+	}
+
+	err := *errSnapshotStale
+
+	err.DevInfo = fmt.Sprintf("The next update was due on %s, the snapshot is stale by %s", s.NextUpdate.Format("2006-01-02"), now.Sub(s.NextUpdate).Truncate(time.Hour))
+
+	return &err
+}
+
+// Rollback returns an error if the serial number of the BLOB of the Snapshot is lower than the one of the current
+// Snapshot. The serial numbers are strictly monotonic, so it should be checked before the current Snapshot is replaced
+// to prevent the stored BLOB from being rolled back to an older one which may be missing status reports such as
+// revoked attestation keys. A nil current Snapshot is never rolled back.
+func (s *Snapshot) Rollback(current *Snapshot) error {
+	if current == nil || s.Payload.Number >= current.Payload.Number {
+		return nil
+	}
+
+	err := *errSnapshotRollback
+
+	err.DevInfo = fmt.Sprintf("The serial number of the snapshot is %d and the serial number of the current snapshot is %d", s.Payload.Number, current.Payload.Number)
+
+	return &err
+}
+
+// Populate adds the entries of the Snapshot to the Metadata, which is the equivalent of PopulateMetadata for a
+// Snapshot.
+func (s *Snapshot) Populate() {
+	for _, entry := range s.Payload.Entries {
+		AddEntry(entry)
+	}
+}
+
+// LookupByAAGUID returns the MetadataBLOBPayloadEntry for the AAGUID if it's present in the Snapshot.
+func (s *Snapshot) LookupByAAGUID(aaguid uuid.UUID) (entry MetadataBLOBPayloadEntry, ok bool) {
+	s.checkStale()
+
+	entry, ok = s.aaguids[aaguid]
+
+	return entry, ok
+}
+
+// LookupByCertificate returns the MetadataBLOBPayloadEntry for the key identifier of the attestation certificate if
+// it's present in the Snapshot.
+func (s *Snapshot) LookupByCertificate(cert *x509.Certificate) (entry MetadataBLOBPayloadEntry, ok bool) {
+	s.checkStale()
+
+	keyIdentifier, err := KeyIdentifier(cert)
+	if err != nil {
+		return entry, false
+	}
+
+	entry, ok = s.keyIdentifiers[keyIdentifier]
+
+	return entry, ok
+}
+
+// checkStale calls the OnStale handler if the Snapshot is stale and the handler was not called before.
+func (s *Snapshot) checkStale() {
+	if s.OnStale == nil {
+		return
+	}
+
+	now := time.Now()
+
+	if s.Clock != nil {
+		now = s.Clock()
+	}
+
+	if s.Stale(now) == nil {
+		return
+	}
+
+	s.warned.Do(func() {
+		s.OnStale(s, now)
+	})
+}
+
+// validateChainOffline verifies the certificate chain of the metadata BLOB to the MDSRoot at the provided time without
+// checking the revocation of the certificates.
+func validateChainOffline(chain []interface{}, now time.Time) (bool, error) {
+	if len(chain) == 0 {
+		return false, errors.New("the certificate chain of the metadata BLOB is empty")
+	}
+
+	roots, err := mdsRootCertPool()
+	if err != nil {
+		return false, err
+	}
+
+	certs := make([]*x509.Certificate, len(chain))
+
+	for i, value := range chain {
+		encoded, ok := value.(string)
+		if !ok {
+			return false, errors.New("the certificate chain of the metadata BLOB is malformed")
+		}
+
+		raw, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return false, err
+		}
+
+		if certs[i], err = x509.ParseCertificate(raw); err != nil {
+			return false, err
+		}
+	}
+
+	intermediates := x509.NewCertPool()
+
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+
+	_, err = certs[0].Verify(opts)
+
+	return err == nil, err
+}
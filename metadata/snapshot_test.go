@@ -0,0 +1,164 @@
+package metadata
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func useExampleMDSRoot(t *testing.T) {
+	root := MDSRoot
+
+	MDSRoot = ExampleMDSRoot
+
+	t.Cleanup(func() {
+		MDSRoot = root
+	})
+}
+
+func TestParseSnapshot(t *testing.T) {
+	useExampleMDSRoot(t)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name string
+		data string
+		root string
+		now  time.Time
+		err  bool
+	}{
+		{"ShouldParse", exampleMetadataBLOB, ExampleMDSRoot, now, false},
+		{"ShouldParseWithTrailingNewline", exampleMetadataBLOB + "\n", ExampleMDSRoot, now, false},
+		{"ShouldFailInvalidSignature", exampleMetadataBLOB[:len(exampleMetadataBLOB)-4] + "AAAA", ExampleMDSRoot, now, true},
+		{"ShouldFailOtherRoot", exampleMetadataBLOB, ProductionMDSRoot, now, true},
+		{"ShouldFailExpiredChain", exampleMetadataBLOB, ExampleMDSRoot, time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC), true},
+		{"ShouldFailMalformed", "not a blob", ExampleMDSRoot, now, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			MDSRoot = tc.root
+
+			snapshot, err := ParseSnapshot([]byte(tc.data), tc.now)
+
+			if tc.err {
+				assert.Error(t, err)
+				assert.Nil(t, snapshot)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			assert.Equal(t, 15, snapshot.Payload.Number)
+			assert.Equal(t, time.Date(2020, 3, 30, 0, 0, 0, 0, time.UTC), snapshot.NextUpdate)
+			assert.Len(t, snapshot.Payload.Entries, 2)
+		})
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	useExampleMDSRoot(t)
+
+	path := filepath.Join(t.TempDir(), "blob.jwt")
+
+	require.NoError(t, os.WriteFile(path, []byte(exampleMetadataBLOB), 0o600))
+
+	snapshot, err := LoadSnapshot(path, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	aaguid := uuid.MustParse("0132d110-bf4e-4208-a403-ab4f5f12efe5")
+
+	t.Run("ShouldLookupByAAGUID", func(t *testing.T) {
+		entry, ok := snapshot.LookupByAAGUID(aaguid)
+		require.True(t, ok)
+
+		assert.Equal(t, FidoCertifiedL1, entry.CertificationLevel())
+
+		_, ok = snapshot.LookupByAAGUID(uuid.Nil)
+		assert.False(t, ok)
+	})
+
+	t.Run("ShouldReportStale", func(t *testing.T) {
+		assert.NoError(t, snapshot.Stale(time.Date(2020, 3, 30, 0, 0, 0, 0, time.UTC)))
+
+		err := snapshot.Stale(time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC))
+		require.Error(t, err)
+
+		var me *MetadataError
+
+		require.ErrorAs(t, err, &me)
+		assert.Equal(t, "snapshot_stale", me.Type)
+		assert.Equal(t, "The next update was due on 2020-03-30, the snapshot is stale by 48h0m0s", me.DevInfo)
+	})
+
+	t.Run("ShouldWarnOnceWhenStale", func(t *testing.T) {
+		var warnings []time.Time
+
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		snapshot, err := LoadSnapshot(path, now)
+		require.NoError(t, err)
+
+		snapshot.Clock = func() time.Time { return now }
+		snapshot.OnStale = func(s *Snapshot, at time.Time) {
+			assert.Same(t, snapshot, s)
+
+			warnings = append(warnings, at)
+		}
+
+		snapshot.LookupByAAGUID(aaguid)
+		snapshot.LookupByAAGUID(aaguid)
+
+		assert.Equal(t, []time.Time{now}, warnings)
+	})
+
+	t.Run("ShouldNotWarnWhenFresh", func(t *testing.T) {
+		snapshot, err := LoadSnapshot(path, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+
+		snapshot.Clock = func() time.Time { return time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC) }
+		snapshot.OnStale = func(_ *Snapshot, _ time.Time) {
+			t.Error("the snapshot is not stale")
+		}
+
+		snapshot.LookupByAAGUID(aaguid)
+	})
+
+	t.Run("ShouldReportRollback", func(t *testing.T) {
+		assert.NoError(t, snapshot.Rollback(nil))
+		assert.NoError(t, snapshot.Rollback(snapshot))
+		assert.NoError(t, snapshot.Rollback(&Snapshot{Payload: MetadataBLOBPayload{Number: 14}}))
+
+		err := snapshot.Rollback(&Snapshot{Payload: MetadataBLOBPayload{Number: 16}})
+		require.Error(t, err)
+
+		var me *MetadataError
+
+		require.ErrorAs(t, err, &me)
+		assert.Equal(t, "snapshot_rollback", me.Type)
+		assert.Equal(t, "The serial number of the snapshot is 15 and the serial number of the current snapshot is 16", me.DevInfo)
+	})
+
+	t.Run("ShouldPopulate", func(t *testing.T) {
+		t.Cleanup(func() {
+			delete(Metadata, aaguid)
+		})
+
+		snapshot.Populate()
+
+		_, ok := LookupByAAGUID(aaguid)
+		assert.True(t, ok)
+	})
+
+	t.Run("ShouldFailMissingFile", func(t *testing.T) {
+		_, err := LoadSnapshot(filepath.Join(t.TempDir(), "missing.jwt"), time.Now())
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,100 @@
+// Package metrics provides ready-made implementations of the webauthn.MetricsSink interface.
+package metrics
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+const (
+	resultSuccess = "success"
+	resultFailure = "failure"
+)
+
+// PrometheusLabels are the label names, in order, of the label values passed to the PrometheusSink vectors. They
+// should be used when creating the *prometheus.CounterVec and *prometheus.HistogramVec.
+var PrometheusLabels = []string{"ceremony", "result", "code", "format", "algorithm"}
+
+// Counter is the subset of the prometheus.Counter interface used by the PrometheusSink.
+type Counter interface {
+	Inc()
+}
+
+// Observer is the subset of the prometheus.Observer interface used by the PrometheusSink.
+type Observer interface {
+	Observe(float64)
+}
+
+// CounterVec returns the Counter for the provided label values. This is typically the WithLabelValues method of a
+// *prometheus.CounterVec.
+type CounterVec func(labelValues ...string) Counter
+
+// ObserverVec returns the Observer for the provided label values. This is typically the WithLabelValues method of a
+// *prometheus.HistogramVec or *prometheus.SummaryVec.
+type ObserverVec func(labelValues ...string) Observer
+
+// PrometheusSink is a webauthn.MetricsSink which counts every ceremony step and observes its duration in seconds. It
+// deliberately does not import the Prometheus client library so that it can be used with any version of it, for
+// example:
+//
+//	ceremonies := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "webauthn_ceremonies_total"}, metrics.PrometheusLabels)
+//	durations := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "webauthn_ceremony_duration_seconds"}, metrics.PrometheusLabels)
+//
+//	sink := metrics.NewPrometheusSink(
+//		func(lvs ...string) metrics.Counter { return ceremonies.WithLabelValues(lvs...) },
+//		func(lvs ...string) metrics.Observer { return durations.WithLabelValues(lvs...) },
+//	)
+type PrometheusSink struct {
+	ceremonies CounterVec
+	durations  ObserverVec
+}
+
+// NewPrometheusSink returns a new *PrometheusSink. Either of the provided vectors may be nil.
+func NewPrometheusSink(ceremonies CounterVec, durations ObserverVec) *PrometheusSink {
+	return &PrometheusSink{
+		ceremonies: ceremonies,
+		durations:  durations,
+	}
+}
+
+// ObserveCeremony implements the webauthn.MetricsSink interface.
+func (s *PrometheusSink) ObserveCeremony(outcome webauthn.CeremonyOutcome) {
+	labelValues := PrometheusLabelValues(outcome)
+
+	if s.ceremonies != nil {
+		s.ceremonies(labelValues...).Inc()
+	}
+
+	if s.durations != nil {
+		s.durations(labelValues...).Observe(outcome.Duration.Seconds())
+	}
+}
+
+// PrometheusLabelValues returns the label values for the provided webauthn.CeremonyOutcome in the order of the
+// PrometheusLabels. All values have a bounded cardinality.
+func PrometheusLabelValues(outcome webauthn.CeremonyOutcome) []string {
+	var (
+		result    = resultSuccess
+		code      string
+		algorithm string
+	)
+
+	if !outcome.Success {
+		result = resultFailure
+
+		var e *protocol.Error
+
+		if errors.As(outcome.Err, &e) {
+			code = string(e.Code)
+		}
+	}
+
+	if outcome.Algorithm != 0 {
+		algorithm = strconv.Itoa(int(outcome.Algorithm))
+	}
+
+	return []string{string(outcome.Ceremony), result, code, outcome.Format, algorithm}
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+type testCounter struct {
+	count int
+}
+
+func (c *testCounter) Inc() {
+	c.count++
+}
+
+type testObserver struct {
+	values []float64
+}
+
+func (o *testObserver) Observe(value float64) {
+	o.values = append(o.values, value)
+}
+
+func TestPrometheusLabelValues(t *testing.T) {
+	testCases := []struct {
+		name     string
+		have     webauthn.CeremonyOutcome
+		expected []string
+	}{
+		{
+			"ShouldHandleSuccess",
+			webauthn.CeremonyOutcome{Ceremony: webauthn.CeremonyFinishRegistration, Success: true, Format: "packed", Algorithm: webauthncose.AlgES256},
+			[]string{"finish_registration", "success", "", "packed", "-7"},
+		},
+		{
+			"ShouldHandleFailureWithCode",
+			webauthn.CeremonyOutcome{Ceremony: webauthn.CeremonyFinishLogin, Err: protocol.ErrVerification.WithCode(protocol.CodeChallengeMismatch)},
+			[]string{"finish_login", "failure", "challenge_mismatch", "", ""},
+		},
+		{
+			"ShouldHandleFailureWithoutCode",
+			webauthn.CeremonyOutcome{Ceremony: webauthn.CeremonyBeginLogin, Err: protocol.ErrBadRequest},
+			[]string{"begin_login", "failure", "", "", ""},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := PrometheusLabelValues(tc.have)
+
+			assert.Equal(t, tc.expected, actual)
+			assert.Len(t, actual, len(PrometheusLabels))
+		})
+	}
+}
+
+func TestPrometheusSink(t *testing.T) {
+	counters := map[string]*testCounter{}
+	observer := &testObserver{}
+
+	sink := NewPrometheusSink(
+		func(labelValues ...string) Counter {
+			key := labelValues[0] + "/" + labelValues[1]
+
+			if _, ok := counters[key]; !ok {
+				counters[key] = &testCounter{}
+			}
+
+			return counters[key]
+		},
+		func(labelValues ...string) Observer {
+			return observer
+		},
+	)
+
+	sink.ObserveCeremony(webauthn.CeremonyOutcome{Ceremony: webauthn.CeremonyBeginLogin, Success: true, Duration: time.Second})
+	sink.ObserveCeremony(webauthn.CeremonyOutcome{Ceremony: webauthn.CeremonyBeginLogin, Success: true, Duration: time.Second})
+	sink.ObserveCeremony(webauthn.CeremonyOutcome{Ceremony: webauthn.CeremonyBeginLogin, Err: protocol.ErrBadRequest, Duration: time.Millisecond})
+
+	assert.Equal(t, 2, counters["begin_login/success"].count)
+	assert.Equal(t, 1, counters["begin_login/failure"].count)
+	assert.Equal(t, []float64{1, 1, 0.001}, observer.values)
+
+	assert.NotPanics(t, func() {
+		NewPrometheusSink(nil, nil).ObserveCeremony(webauthn.CeremonyOutcome{})
+	})
+}
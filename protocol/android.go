@@ -0,0 +1,107 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+// AndroidOriginPrefix is the prefix of the origin of the client data of native Android applications, which is followed
+// by the base64url encoded SHA-256 hash of the certificate the application is signed with.
+const AndroidOriginPrefix = "android:apk-key-hash:"
+
+// AndroidOriginVerifier returns an OriginVerifier which verifies the origin with the verifier, or the
+// StrictOriginVerifier if it's nil, and which also accepts the origins of native Android applications when the hash
+// of the signing certificate equals the hash of one of the origins of the Relying Party. The hashes are compared
+// regardless of the base64 variant they're encoded with, as the hashes reported by the Android FIDO2 API and the ones
+// produced by tools such as keytool differ in their alphabet and padding.
+func AndroidOriginVerifier(verifier OriginVerifier) OriginVerifier {
+	return OriginVerifierFunc(func(c *CollectedClientData, rpOrigins []string) error {
+		err := VerifyOriginWith(verifier, c, rpOrigins)
+		if err == nil {
+			return nil
+		}
+
+		hash, ok := decodeAndroidOrigin(c.Origin)
+		if !ok {
+			return err
+		}
+
+		for _, origin := range rpOrigins {
+			if expected, ok := decodeAndroidOrigin(origin); ok && bytes.Equal(hash, expected) {
+				return nil
+			}
+		}
+
+		return err
+	})
+}
+
+// decodeAndroidOrigin returns the certificate hash of an origin of a native Android application.
+func decodeAndroidOrigin(origin string) (hash []byte, ok bool) {
+	if !strings.HasPrefix(origin, AndroidOriginPrefix) {
+		return nil, false
+	}
+
+	hash, err := base64.RawURLEncoding.DecodeString(normalizeBase64(strings.TrimPrefix(origin, AndroidOriginPrefix)))
+	if err != nil || len(hash) == 0 {
+		return nil, false
+	}
+
+	return hash, true
+}
+
+// NormalizeResponseBase64 returns the JSON encoded registration or authentication response with the binary fields
+// which are encoded with the standard base64 alphabet or padding re-encoded as base64url without padding, which is
+// the only encoding accepted by the parsers. Some clients, notably applications using the Android FIDO2 API, encode
+// the fields of the response themselves and use the other variants. The data is returned as is if it's not a JSON
+// object, leaving the error to the parser.
+func NormalizeResponseBase64(data []byte) []byte {
+	var fields map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return data
+	}
+
+	normalizeBase64Fields(fields, "id", "rawId")
+
+	if raw, ok := fields["response"]; ok {
+		var response map[string]json.RawMessage
+
+		if err := json.Unmarshal(raw, &response); err == nil {
+			normalizeBase64Fields(response, "clientDataJSON", "attestationObject", "authenticatorData", "signature", "userHandle", "publicKey")
+
+			if raw, err = json.Marshal(response); err == nil {
+				fields["response"] = raw
+			}
+		}
+	}
+
+	normalized, err := json.Marshal(fields)
+	if err != nil {
+		return data
+	}
+
+	return normalized
+}
+
+// normalizeBase64Fields normalizes the base64 encoding of the string fields with the names.
+func normalizeBase64Fields(fields map[string]json.RawMessage, names ...string) {
+	for _, name := range names {
+		var value string
+
+		if err := json.Unmarshal(fields[name], &value); err != nil {
+			continue
+		}
+
+		if raw, err := json.Marshal(normalizeBase64(value)); err == nil {
+			fields[name] = raw
+		}
+	}
+}
+
+// normalizeBase64 converts a value encoded with any base64 variant to base64url without padding.
+func normalizeBase64(value string) string {
+	return strings.NewReplacer("+", "-", "/", "_").Replace(strings.TrimRight(value, "="))
+}
@@ -0,0 +1,114 @@
+package protocol
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// androidEncodedResponse re-encodes the binary fields of a captured response with padded standard base64, which is
+// how applications using the Android FIDO2 API commonly encode the responses they send to the Relying Party.
+func androidEncodedResponse(t *testing.T, response string) []byte {
+	var fields map[string]interface{}
+
+	require.NoError(t, json.Unmarshal([]byte(response), &fields))
+
+	reencode := func(values map[string]interface{}, names ...string) {
+		for _, name := range names {
+			value, ok := values[name].(string)
+			if !ok {
+				continue
+			}
+
+			decoded, err := base64.RawURLEncoding.DecodeString(value)
+			require.NoError(t, err)
+
+			values[name] = base64.StdEncoding.EncodeToString(decoded)
+		}
+	}
+
+	reencode(fields, "id", "rawId")
+	reencode(fields["response"].(map[string]interface{}), "clientDataJSON", "attestationObject")
+
+	data, err := json.Marshal(fields)
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestNormalizeResponseBase64(t *testing.T) {
+	testCases := []struct {
+		name     string
+		response string
+	}{
+		{"ShouldNormalizeAndroidKeyResponse", androidKeyTestResponse0["success"]},
+		{"ShouldNormalizeAndroidKeyResponseWithStringChallenge", androidKeyTestResponse1["success"]},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expected, err := ParseCredentialCreationResponseBytes([]byte(tc.response))
+			require.NoError(t, err)
+
+			data := androidEncodedResponse(t, tc.response)
+
+			_, err = ParseCredentialCreationResponseBytes(data)
+			require.Error(t, err)
+
+			actual, err := ParseCredentialCreationResponseBytes(NormalizeResponseBase64(data))
+			require.NoError(t, err)
+
+			assert.Equal(t, expected.ID, actual.ID)
+			assert.Equal(t, expected.RawID, actual.RawID)
+			assert.Equal(t, expected.Response.CollectedClientData, actual.Response.CollectedClientData)
+			assert.Equal(t, expected.Response.AttestationObject.RawAuthData, actual.Response.AttestationObject.RawAuthData)
+		})
+	}
+
+	t.Run("ShouldReturnInvalidDataAsIs", func(t *testing.T) {
+		assert.Equal(t, []byte("not json"), NormalizeResponseBase64([]byte("not json")))
+	})
+}
+
+func TestAndroidOriginVerifier(t *testing.T) {
+	hash := sha256.Sum256([]byte("signing certificate"))
+
+	rawURL := AndroidOriginPrefix + base64.RawURLEncoding.EncodeToString(hash[:])
+	std := AndroidOriginPrefix + base64.StdEncoding.EncodeToString(hash[:])
+
+	other := sha256.Sum256([]byte("other certificate"))
+
+	testCases := []struct {
+		name      string
+		origin    string
+		rpOrigins []string
+		code      ErrorCode
+	}{
+		{"ShouldPassSameEncoding", rawURL, []string{rawURL}, ""},
+		{"ShouldPassStandardOriginWithURLConfiguration", std, []string{"https://example.com", rawURL}, ""},
+		{"ShouldPassURLOriginWithStandardConfiguration", rawURL, []string{std}, ""},
+		{"ShouldPassWebOrigin", "https://example.com", []string{"https://example.com", std}, ""},
+		{"ShouldFailOtherCertificate", AndroidOriginPrefix + base64.StdEncoding.EncodeToString(other[:]), []string{rawURL}, CodeOriginMismatch},
+		{"ShouldFailInvalidHash", AndroidOriginPrefix + "!", []string{rawURL}, CodeOriginMismatch},
+		{"ShouldFailWebOrigin", "https://example.com", []string{rawURL}, CodeOriginMismatch},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := VerifyOriginWith(AndroidOriginVerifier(nil), &CollectedClientData{Origin: tc.origin}, tc.rpOrigins)
+
+			if tc.code == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			require.IsType(t, &Error{}, err)
+			assert.Equal(t, tc.code, err.(*Error).Code)
+		})
+	}
+}
@@ -0,0 +1,308 @@
+// Package appattest verifies the attestation and assertion objects of Apple App Attest, which allows backends serving
+// native iOS apps to verify that requests come from a genuine instance of their app alongside WebAuthn with the same
+// CBOR and authenticator data handling.
+//
+// See: https://developer.apple.com/documentation/devicecheck/validating_apps_that_connect_to_your_server
+package appattest
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+)
+
+// Format is the attestation statement format of App Attest attestation objects.
+const Format = "apple-appattest"
+
+// AAGUIDs of the App Attest environments.
+var (
+	AAGUIDProduction  = []byte("appattest\x00\x00\x00\x00\x00\x00\x00")
+	AAGUIDDevelopment = []byte("appattestdevelop")
+)
+
+// oidNonce is the OID of the extension of the credential certificate which contains the nonce.
+var oidNonce = asn1.ObjectIdentifier{1, 2, 840, 113635, 100, 8, 2}
+
+// Verifier verifies the App Attest objects of an app.
+type Verifier struct {
+	// AppID of the app which is the team identifier and the bundle identifier joined by a period, such as
+	// "ABCDE12345.com.example.app".
+	AppID string
+
+	// Roots is the pool of trusted root certificates which must contain the Apple App Attestation Root CA, which can
+	// be downloaded from https://www.apple.com/certificateauthority/private/.
+	Roots *x509.CertPool
+
+	// AllowDevelopment accepts keys from the development environment of App Attest, which must only be enabled for
+	// development builds of the app.
+	AllowDevelopment bool
+
+	// Clock returns the current time used to verify the validity of the certificates. The default is time.Now.
+	Clock func() time.Time
+}
+
+// Attestation is a verified App Attest attestation object.
+type Attestation struct {
+	// KeyID is the identifier of the key, which is the SHA-256 hash of the public key.
+	KeyID []byte
+
+	// PublicKey is the COSE encoded public key which must be stored with the KeyID to verify the assertions.
+	PublicKey []byte
+
+	// Receipt is the receipt which can be exchanged with Apple for a fraud risk metric.
+	Receipt []byte
+
+	// Development is true if the key was generated in the development environment.
+	Development bool
+}
+
+type attestationObject struct {
+	Format       string                 `cbor:"fmt"`
+	AttStatement map[string]interface{} `cbor:"attStmt"`
+	RawAuthData  []byte                 `cbor:"authData"`
+}
+
+type assertionObject struct {
+	Signature         []byte `cbor:"signature"`
+	AuthenticatorData []byte `cbor:"authenticatorData"`
+}
+
+// VerifyAttestation verifies the attestation object returned by DCAppAttestService attestKey for the key with the ID
+// and the client data whose SHA-256 hash was passed to attestKey, which must contain the one-time challenge issued by
+// the server.
+func (v *Verifier) VerifyAttestation(attestation, clientData, keyID []byte) (verified *Attestation, err error) {
+	var object attestationObject
+
+	if err = webauthncbor.Unmarshal(attestation, &object); err != nil {
+		return nil, protocol.ErrParsingData.WithCode(protocol.CodeResponseInvalid).WithInfo(err.Error())
+	}
+
+	if object.Format != Format {
+		return nil, protocol.ErrAttestationFormat.
+			WithCode(protocol.CodeAttestationFormatUnsupported).
+			WithDetails(fmt.Sprintf("Attestation format %s is not %s", object.Format, Format))
+	}
+
+	var authData protocol.AuthenticatorData
+
+	if err = authData.Unmarshal(object.RawAuthData); err != nil {
+		return nil, err
+	}
+
+	// Step 1. Verify that the x5c array contains the intermediate and leaf certificates for App Attest, starting from
+	// the credential certificate, and verify the chain up to the Apple App Attestation Root CA.
+	credCert, err := v.verifyChain(object.AttStatement)
+	if err != nil {
+		return nil, err
+	}
+
+	// Steps 2 through 4. Verify that the nonce, which is the SHA-256 hash of the authenticator data and the hash of the
+	// client data, equals the extension of the credential certificate.
+	clientDataHash := sha256.Sum256(clientData)
+
+	if err = verifyNonce(credCert, object.RawAuthData, clientDataHash[:]); err != nil {
+		return nil, err
+	}
+
+	// Step 5. Verify that the key identifier is the SHA-256 hash of the public key of the credential certificate.
+	publicKey, ok := credCert.PublicKey.(*ecdsa.PublicKey)
+	if !ok || publicKey.Curve != elliptic.P256() {
+		return nil, protocol.ErrInvalidAttestation.
+			WithCode(protocol.CodeAttestationInvalid).
+			WithDetails("Credential certificate public key is not a P-256 key")
+	}
+
+	point, err := publicKey.ECDH()
+	if err != nil {
+		return nil, protocol.ErrInvalidAttestation.
+			WithCode(protocol.CodeAttestationInvalid).
+			WithDetails(fmt.Sprintf("Error encoding the credential certificate public key: %+v", err))
+	}
+
+	// The key identifier is the hash of the uncompressed point.
+	keyHash := sha256.Sum256(point.Bytes())
+
+	if subtle.ConstantTimeCompare(keyHash[:], keyID) != 1 {
+		return nil, protocol.ErrInvalidAttestation.
+			WithCode(protocol.CodeAttestationInvalid).
+			WithDetails("Key identifier does not match the credential certificate public key")
+	}
+
+	// Step 6. Verify that the RP ID hash is the SHA-256 hash of the App ID.
+	if err = v.verifyAppID(authData); err != nil {
+		return nil, err
+	}
+
+	// Step 7. Verify that the counter is 0.
+	if authData.Counter != 0 {
+		return nil, protocol.ErrInvalidAttestation.
+			WithCode(protocol.CodeAttestationInvalid).
+			WithDetails("Attestation counter is not zero").
+			WithInfo(fmt.Sprintf("Counter: %d", authData.Counter))
+	}
+
+	// Step 8. Verify that the AAGUID is the one of the production environment, or the development environment if
+	// allowed.
+	verified = &Attestation{KeyID: keyID}
+
+	switch {
+	case bytes.Equal(authData.AttData.AAGUID, AAGUIDProduction):
+	case bytes.Equal(authData.AttData.AAGUID, AAGUIDDevelopment) && v.AllowDevelopment:
+		verified.Development = true
+	default:
+		return nil, protocol.ErrInvalidAttestation.
+			WithCode(protocol.CodeAttestationInvalid).
+			WithDetails("Attestation AAGUID is not an allowed App Attest environment").
+			WithInfo(fmt.Sprintf("AAGUID: %q", authData.AttData.AAGUID))
+	}
+
+	// Step 9. Verify that the credential ID is the key identifier.
+	if !bytes.Equal(authData.AttData.CredentialID, keyID) {
+		return nil, protocol.ErrInvalidAttestation.
+			WithCode(protocol.CodeAttestationInvalid).
+			WithDetails("Credential ID does not match the key identifier")
+	}
+
+	verified.PublicKey = authData.AttData.CredentialPublicKey
+	verified.Receipt, _ = object.AttStatement["receipt"].([]byte)
+
+	return verified, nil
+}
+
+// VerifyAssertion verifies the assertion object returned by DCAppAttestService generateAssertion with the COSE encoded
+// public key of a verified Attestation and the client data whose SHA-256 hash was passed to generateAssertion. It
+// returns the counter of the assertion which must be stored and passed as the previous counter of the next assertion.
+func (v *Verifier) VerifyAssertion(assertion, clientData, publicKey []byte, previousCounter uint32) (counter uint32, err error) {
+	var object assertionObject
+
+	if err = webauthncbor.Unmarshal(assertion, &object); err != nil {
+		return 0, protocol.ErrParsingData.WithCode(protocol.CodeResponseInvalid).WithInfo(err.Error())
+	}
+
+	var authData protocol.AuthenticatorData
+
+	if err = authData.Unmarshal(object.AuthenticatorData); err != nil {
+		return 0, err
+	}
+
+	// Steps 1 through 3. Verify that the signature is valid for the nonce, which is the SHA-256 hash of the
+	// authenticator data and the hash of the client data.
+	key, err := webauthncose.ParsePublicKey(publicKey)
+	if err != nil {
+		return 0, protocol.ErrUnsupportedKey.WithCode(protocol.CodePublicKeyInvalid).WithDetails(fmt.Sprintf("Error parsing the public key: %+v", err))
+	}
+
+	clientDataHash := sha256.Sum256(clientData)
+	nonce := sha256.Sum256(append(append([]byte(nil), object.AuthenticatorData...), clientDataHash[:]...))
+
+	if valid, err := webauthncose.VerifySignature(key, nonce[:], object.Signature); err != nil || !valid {
+		return 0, protocol.ErrAssertionSignature.WithCode(protocol.CodeSignatureInvalid).WithDetails("Assertion signature is not valid for the nonce")
+	}
+
+	// Step 4. Verify that the RP ID hash is the SHA-256 hash of the App ID.
+	if err = v.verifyAppID(authData); err != nil {
+		return 0, err
+	}
+
+	// Step 5. Verify that the counter is greater than the counter of the previous assertion.
+	if authData.Counter <= previousCounter {
+		return 0, protocol.ErrVerification.
+			WithCode(protocol.CodeCounterRegressed).
+			WithDetails("Assertion counter did not increase").
+			WithInfo(fmt.Sprintf("Previous: %d, Counter: %d", previousCounter, authData.Counter))
+	}
+
+	return authData.Counter, nil
+}
+
+func (v *Verifier) verifyChain(statement map[string]interface{}) (credCert *x509.Certificate, err error) {
+	x5c, ok := statement["x5c"].([]interface{})
+	if !ok || len(x5c) == 0 {
+		return nil, protocol.ErrAttestationFormat.WithCode(protocol.CodeAttestationInvalid).WithDetails("Error retrieving x5c value")
+	}
+
+	intermediates := x509.NewCertPool()
+
+	for i, raw := range x5c {
+		data, ok := raw.([]byte)
+		if !ok {
+			return nil, protocol.ErrAttestationFormat.WithCode(protocol.CodeAttestationInvalid).WithDetails(fmt.Sprintf("Error getting certificate %d from x5c cert chain", i))
+		}
+
+		cert, err := x509.ParseCertificate(data)
+		if err != nil {
+			return nil, protocol.ErrAttestationFormat.WithCode(protocol.CodeAttestationInvalid).WithDetails(fmt.Sprintf("Error parsing certificate %d from ASN.1 data: %+v", i, err))
+		}
+
+		if i == 0 {
+			credCert = cert
+		} else {
+			intermediates.AddCert(cert)
+		}
+	}
+
+	now := time.Now()
+	if v.Clock != nil {
+		now = v.Clock()
+	}
+
+	if _, err = credCert.Verify(x509.VerifyOptions{
+		Roots:         v.Roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return nil, protocol.ErrInvalidAttestation.
+			WithCode(protocol.CodeAttestationInvalid).
+			WithDetails(fmt.Sprintf("Error verifying the certificate chain: %+v", err))
+	}
+
+	return credCert, nil
+}
+
+func verifyNonce(credCert *x509.Certificate, authData, clientDataHash []byte) error {
+	nonce := sha256.Sum256(append(append([]byte(nil), authData...), clientDataHash...))
+
+	for _, ext := range credCert.Extensions {
+		if !ext.Id.Equal(oidNonce) {
+			continue
+		}
+
+		var decoded protocol.AppleAnonymousAttestation
+
+		if _, err := asn1.Unmarshal(ext.Value, &decoded); err != nil {
+			return protocol.ErrAttestationFormat.WithCode(protocol.CodeAttestationInvalid).WithDetails("Unable to parse the nonce extension of the credential certificate")
+		}
+
+		if subtle.ConstantTimeCompare(decoded.Nonce, nonce[:]) != 1 {
+			return protocol.ErrInvalidAttestation.WithCode(protocol.CodeAttestationInvalid).WithDetails("Credential certificate does not contain the expected nonce")
+		}
+
+		return nil
+	}
+
+	return protocol.ErrAttestationFormat.WithCode(protocol.CodeAttestationInvalid).WithDetails("Credential certificate extensions missing 1.2.840.113635.100.8.2")
+}
+
+func (v *Verifier) verifyAppID(authData protocol.AuthenticatorData) error {
+	appIDHash := sha256.Sum256([]byte(v.AppID))
+
+	if subtle.ConstantTimeCompare(authData.RPIDHash, appIDHash[:]) != 1 {
+		return protocol.ErrVerification.
+			WithCode(protocol.CodeRPIDHashMismatch).
+			WithDetails("RP ID hash does not match the App ID").
+			WithInfo(fmt.Sprintf("App ID: %s", v.AppID))
+	}
+
+	return nil
+}
@@ -0,0 +1,241 @@
+package appattest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/webauthntest/ctap2"
+)
+
+const testAppID = "ABCDE12345.com.example.app"
+
+type testApp struct {
+	t            *testing.T
+	roots        *x509.CertPool
+	intermediate *x509.Certificate
+	caKey        *ecdsa.PrivateKey
+	credential   *ctap2.Credential
+	keyID        []byte
+}
+
+func newTestApp(t *testing.T) *testApp {
+	rootKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	root := newTestCertificate(t, "Test App Attestation Root CA", nil, nil, &rootKey.PublicKey, rootKey, nil)
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	intermediate := newTestCertificate(t, "Test App Attestation CA 1", root, rootKey, &caKey.PublicKey, rootKey, nil)
+
+	credential, err := ctap2.NewCredential(testAppID, nil, webauthncose.AlgES256)
+	require.NoError(t, err)
+
+	point, err := credential.PrivateKey.Public().(*ecdsa.PublicKey).ECDH()
+	require.NoError(t, err)
+
+	keyID := sha256.Sum256(point.Bytes())
+
+	credential.ID = keyID[:]
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+
+	return &testApp{t: t, roots: roots, intermediate: intermediate, caKey: caKey, credential: credential, keyID: keyID[:]}
+}
+
+func newTestCertificate(t *testing.T, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, publicKey *ecdsa.PublicKey, selfKey *ecdsa.PrivateKey, extensions []pkix.Extension) *x509.Certificate {
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  extensions == nil,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtraExtensions:       extensions,
+	}
+
+	signer, issuer := selfKey, template
+	if parent != nil {
+		signer, issuer = parentKey, parent
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, publicKey, signer)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert
+}
+
+func (a *testApp) authData(aaguid []byte, counter uint32, attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(testAppID))
+
+	data := append([]byte(nil), rpIDHash[:]...)
+
+	flags := protocol.FlagUserPresent
+	if attested {
+		flags |= protocol.FlagAttestedCredentialData
+	}
+
+	data = append(data, byte(flags))
+	data = binary.BigEndian.AppendUint32(data, counter)
+
+	if !attested {
+		return data
+	}
+
+	publicKey, err := a.credential.PublicKey()
+	require.NoError(a.t, err)
+
+	data = append(data, aaguid...)
+	data = binary.BigEndian.AppendUint16(data, uint16(len(a.keyID)))
+	data = append(data, a.keyID...)
+
+	return append(data, publicKey...)
+}
+
+func (a *testApp) attestation(aaguid, clientData []byte) []byte {
+	authData := a.authData(aaguid, 0, true)
+	clientDataHash := sha256.Sum256(clientData)
+	nonce := sha256.Sum256(append(append([]byte(nil), authData...), clientDataHash[:]...))
+
+	value, err := asn1.Marshal(protocol.AppleAnonymousAttestation{Nonce: nonce[:]})
+	require.NoError(a.t, err)
+
+	credCert := newTestCertificate(a.t, "key", a.intermediate, a.caKey, a.credential.PrivateKey.Public().(*ecdsa.PublicKey), nil, []pkix.Extension{{Id: oidNonce, Value: value}})
+
+	object, err := webauthncbor.Marshal(map[string]interface{}{
+		"fmt": Format,
+		"attStmt": map[string]interface{}{
+			"x5c":     []interface{}{credCert.Raw, a.intermediate.Raw},
+			"receipt": []byte("receipt"),
+		},
+		"authData": authData,
+	})
+	require.NoError(a.t, err)
+
+	return object
+}
+
+func (a *testApp) assertion(counter uint32, clientData []byte) []byte {
+	authData := a.authData(nil, counter, false)
+	clientDataHash := sha256.Sum256(clientData)
+	nonce := sha256.Sum256(append(append([]byte(nil), authData...), clientDataHash[:]...))
+
+	signature, err := a.credential.Sign(nonce[:])
+	require.NoError(a.t, err)
+
+	object, err := webauthncbor.Marshal(map[string]interface{}{
+		"signature":         signature,
+		"authenticatorData": authData,
+	})
+	require.NoError(a.t, err)
+
+	return object
+}
+
+func TestVerifier_VerifyAttestation(t *testing.T) {
+	app := newTestApp(t)
+	challenge := []byte("challenge")
+
+	testCases := []struct {
+		name        string
+		verifier    Verifier
+		attestation []byte
+		clientData  []byte
+		keyID       []byte
+		development bool
+		err         protocol.ErrorCode
+	}{
+		{"ShouldVerifyProduction", Verifier{AppID: testAppID, Roots: app.roots}, app.attestation(AAGUIDProduction, challenge), challenge, app.keyID, false, ""},
+		{"ShouldVerifyDevelopment", Verifier{AppID: testAppID, Roots: app.roots, AllowDevelopment: true}, app.attestation(AAGUIDDevelopment, challenge), challenge, app.keyID, true, ""},
+		{"ShouldFailDevelopment", Verifier{AppID: testAppID, Roots: app.roots}, app.attestation(AAGUIDDevelopment, challenge), challenge, app.keyID, false, protocol.CodeAttestationInvalid},
+		{"ShouldFailChallenge", Verifier{AppID: testAppID, Roots: app.roots}, app.attestation(AAGUIDProduction, challenge), []byte("other"), app.keyID, false, protocol.CodeAttestationInvalid},
+		{"ShouldFailKeyID", Verifier{AppID: testAppID, Roots: app.roots}, app.attestation(AAGUIDProduction, challenge), challenge, []byte("other"), false, protocol.CodeAttestationInvalid},
+		{"ShouldFailAppID", Verifier{AppID: "ABCDE12345.com.example.other", Roots: app.roots}, app.attestation(AAGUIDProduction, challenge), challenge, app.keyID, false, protocol.CodeRPIDHashMismatch},
+		{"ShouldFailRoots", Verifier{AppID: testAppID, Roots: x509.NewCertPool()}, app.attestation(AAGUIDProduction, challenge), challenge, app.keyID, false, protocol.CodeAttestationInvalid},
+		{"ShouldFailMalformed", Verifier{AppID: testAppID, Roots: app.roots}, []byte("malformed"), challenge, app.keyID, false, protocol.CodeResponseInvalid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			verified, err := tc.verifier.VerifyAttestation(tc.attestation, tc.clientData, tc.keyID)
+
+			if tc.err != "" {
+				var e *protocol.Error
+
+				require.ErrorAs(t, err, &e)
+				assert.Equal(t, tc.err, e.Code)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			publicKey, err := app.credential.PublicKey()
+			require.NoError(t, err)
+
+			assert.Equal(t, app.keyID, verified.KeyID)
+			assert.Equal(t, publicKey, verified.PublicKey)
+			assert.Equal(t, []byte("receipt"), verified.Receipt)
+			assert.Equal(t, tc.development, verified.Development)
+		})
+	}
+}
+
+func TestVerifier_VerifyAssertion(t *testing.T) {
+	app := newTestApp(t)
+	verifier := Verifier{AppID: testAppID, Roots: app.roots}
+	clientData := []byte(`{"challenge":"challenge"}`)
+
+	publicKey, err := app.credential.PublicKey()
+	require.NoError(t, err)
+
+	counter, err := verifier.VerifyAssertion(app.assertion(1, clientData), clientData, publicKey, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), counter)
+
+	testCases := []struct {
+		name       string
+		verifier   Verifier
+		assertion  []byte
+		clientData []byte
+		previous   uint32
+		err        protocol.ErrorCode
+	}{
+		{"ShouldFailCounter", verifier, app.assertion(1, clientData), clientData, 1, protocol.CodeCounterRegressed},
+		{"ShouldFailClientData", verifier, app.assertion(2, clientData), []byte("other"), 1, protocol.CodeSignatureInvalid},
+		{"ShouldFailAppID", Verifier{AppID: "ABCDE12345.com.example.other"}, app.assertion(2, clientData), clientData, 1, protocol.CodeRPIDHashMismatch},
+		{"ShouldFailMalformed", verifier, []byte("malformed"), clientData, 1, protocol.CodeResponseInvalid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.verifier.VerifyAssertion(tc.assertion, tc.clientData, publicKey, tc.previous)
+
+			var e *protocol.Error
+
+			require.ErrorAs(t, err, &e)
+			assert.Equal(t, tc.err, e.Code)
+		})
+	}
+}
@@ -1,14 +1,14 @@
 package protocol
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-
-	"github.com/go-webauthn/webauthn/protocol/webauthncose"
 )
 
 // The CredentialAssertionResponse is the raw response returned to the Relying Party from an authenticator when we request a
@@ -46,15 +46,30 @@ type ParsedAssertionResponse struct {
 // ParseCredentialRequestResponse parses the credential request response into a format that is either required by the
 // specification or makes the assertion verification steps easier to complete. This takes a http.Request that contains
 // the assertion response data in a raw, mostly base64 encoded format, and parses the data into manageable structures.
+// The body is form encoded when the Content-Type of the request is ContentTypeForm, see ParseCredentialCreationResponse.
 func ParseCredentialRequestResponse(response *http.Request) (*ParsedCredentialAssertionData, error) {
+	return ParseCredentialRequestResponseWithLimit(response, DefaultResponseBodyLimit)
+}
+
+// ParseCredentialRequestResponseWithLimit is the same as ParseCredentialRequestResponse except the body is limited to the
+// provided number of bytes instead of DefaultResponseBodyLimit. If the limit is less than 1 the body is not limited.
+func ParseCredentialRequestResponseWithLimit(response *http.Request, limit int64) (*ParsedCredentialAssertionData, error) {
 	if response == nil || response.Body == nil {
-		return nil, ErrBadRequest.WithDetails("No response given")
+		return nil, ErrBadRequest.WithCode(CodeResponseInvalid).WithDetails("No response given")
 	}
 
 	defer response.Body.Close()
-	defer io.Copy(io.Discard, response.Body)
 
-	return ParseCredentialRequestResponseBody(response.Body)
+	body := limitBody(response.Body, limit)
+
+	defer io.Copy(io.Discard, body)
+
+	reader, err := requestBody(response, body)
+	if err != nil {
+		return nil, decodeBodyError(err, "Parse error for Assertion")
+	}
+
+	return ParseCredentialRequestResponseBody(reader)
 }
 
 // ParseCredentialRequestResponseBody parses the credential request response into a format that is either required by
@@ -64,27 +79,44 @@ func ParseCredentialRequestResponseBody(body io.Reader) (par *ParsedCredentialAs
 	var car CredentialAssertionResponse
 
 	if err = decodeBody(body, &car); err != nil {
-		return nil, ErrBadRequest.WithDetails("Parse error for Assertion").WithInfo(err.Error())
+		return nil, decodeBodyError(err, "Parse error for Assertion")
 	}
 
 	return car.Parse()
 }
 
+// ParseCredentialRequestResponseBytes is the same as ParseCredentialRequestResponseBody except the assertion response is a byte slice, which suits
+// frameworks such as fasthttp which do not use the http library from stdlib. The response is limited to
+// DefaultResponseBodyLimit.
+func ParseCredentialRequestResponseBytes(data []byte) (*ParsedCredentialAssertionData, error) {
+	return ParseCredentialRequestResponseBytesWithLimit(data, DefaultResponseBodyLimit)
+}
+
+// ParseCredentialRequestResponseBytesWithLimit is the same as ParseCredentialRequestResponseBytes except the response is limited to the
+// provided number of bytes instead of DefaultResponseBodyLimit. If the limit is less than 1 the response is not limited.
+func ParseCredentialRequestResponseBytesWithLimit(data []byte, limit int64) (*ParsedCredentialAssertionData, error) {
+	if len(data) == 0 {
+		return nil, ErrBadRequest.WithCode(CodeResponseInvalid).WithDetails("No response given")
+	}
+
+	return ParseCredentialRequestResponseBody(limitBody(bytes.NewReader(data), limit))
+}
+
 // Parse validates and parses the CredentialAssertionResponse into a ParseCredentialCreationResponseBody. This receiver
 // is unlikely to be expressly guaranteed under the versioning policy. Users looking for this guarantee should see
 // ParseCredentialRequestResponseBody instead, and this receiver should only be used if that function is inadequate
 // for their use case.
 func (car CredentialAssertionResponse) Parse() (par *ParsedCredentialAssertionData, err error) {
 	if car.ID == "" {
-		return nil, ErrBadRequest.WithDetails("CredentialAssertionResponse with ID missing")
+		return nil, ErrBadRequest.WithCode(CodeResponseInvalid).WithDetails("CredentialAssertionResponse with ID missing")
 	}
 
 	if _, err = base64.RawURLEncoding.DecodeString(car.ID); err != nil {
-		return nil, ErrBadRequest.WithDetails("CredentialAssertionResponse with ID not base64url encoded")
+		return nil, ErrBadRequest.WithCode(CodeResponseInvalid).WithDetails("CredentialAssertionResponse with ID not base64url encoded")
 	}
 
 	if car.Type != "public-key" {
-		return nil, ErrBadRequest.WithDetails("CredentialAssertionResponse with bad type")
+		return nil, ErrBadRequest.WithCode(CodeResponseInvalid).WithDetails("CredentialAssertionResponse with bad type")
 	}
 
 	var attachment AuthenticatorAttachment
@@ -97,14 +129,14 @@ func (car CredentialAssertionResponse) Parse() (par *ParsedCredentialAssertionDa
 	}
 
 	par = &ParsedCredentialAssertionData{
-		ParsedPublicKeyCredential{
+		ParsedPublicKeyCredential: ParsedPublicKeyCredential{
 			ParsedCredential{car.ID, car.Type}, car.RawID, car.ClientExtensionResults, attachment,
 		},
-		ParsedAssertionResponse{
+		Response: ParsedAssertionResponse{
 			Signature:  car.AssertionResponse.Signature,
 			UserHandle: car.AssertionResponse.UserHandle,
 		},
-		car,
+		Raw: car,
 	}
 
 	// Step 5. Let JSONtext be the result of running UTF-8 decode on the value of cData.
@@ -114,7 +146,7 @@ func (car CredentialAssertionResponse) Parse() (par *ParsedCredentialAssertionDa
 	}
 
 	if err = par.Response.AuthenticatorData.Unmarshal(car.AssertionResponse.AuthenticatorData); err != nil {
-		return nil, ErrParsingData.WithDetails("Error unmarshalling auth data")
+		return nil, ErrParsingData.WithCode(CodeAuthDataInvalid).WithDetails("Error unmarshalling auth data")
 	}
 
 	return par, nil
@@ -125,14 +157,61 @@ func (car CredentialAssertionResponse) Parse() (par *ParsedCredentialAssertionDa
 //
 // Specification: §7.2 Verifying an Authentication Assertion (https://www.w3.org/TR/webauthn/#sctn-verifying-assertion)
 func (p *ParsedCredentialAssertionData) Verify(storedChallenge string, relyingPartyID string, relyingPartyOrigins []string, appID string, verifyUser bool, credentialBytes []byte) error {
+	return p.VerifyWithTrace(nil, storedChallenge, relyingPartyID, relyingPartyOrigins, nil, appID, verifyUser, credentialBytes)
+}
+
+// VerifyWithTrace is the same as Verify except it records each verification step in the provided *VerificationTrace,
+// and the origin of the client data is verified by the OriginVerifier, or the StrictOriginVerifier if it's nil.
+func (p *ParsedCredentialAssertionData) VerifyWithTrace(trace *VerificationTrace, storedChallenge string, relyingPartyID string, relyingPartyOrigins []string, originVerifier OriginVerifier, appID string, verifyUser bool, credentialBytes []byte) error {
+	verifier := func(signedData, signature []byte) error {
+		// If the Session Data does not contain the appID extension or it wasn't reported as used by the Client/RP then
+		// we use the standard CTAP2 public key parser.
+		return VerifySignature(signedData, signature, credentialBytes, appID != "")
+	}
+
+	return p.verify(trace, storedChallenge, relyingPartyID, relyingPartyOrigins, originVerifier, appID, verifyUser, verifier, map[string]string{"public_key_hash": traceHash(credentialBytes)})
+}
+
+// SignatureVerifier verifies the signature over the signed data, which is the binary concatenation of the
+// authenticator data and the hash of the client data. The signed data is reused once it returns, so it must be copied
+// if it's retained.
+type SignatureVerifier func(signedData, signature []byte) error
+
+// VerifyWithSignatureVerifier is the same as VerifyWithTrace except the signature is verified by the SignatureVerifier
+// instead of with the credential public key, such as when the public key is held by a HSM. Errors returned by the
+// SignatureVerifier which are not an *Error are returned as an ErrAssertionSignature.
+func (p *ParsedCredentialAssertionData) VerifyWithSignatureVerifier(trace *VerificationTrace, storedChallenge string, relyingPartyID string, relyingPartyOrigins []string, originVerifier OriginVerifier, appID string, verifyUser bool, verifier SignatureVerifier) error {
+	delegated := func(signedData, signature []byte) error {
+		err := verifier(signedData, signature)
+		if err == nil {
+			return nil
+		}
+
+		var e *Error
+
+		if errors.As(err, &e) {
+			return err
+		}
+
+		return ErrAssertionSignature.WithCode(CodeSignatureInvalid).WithDetails(fmt.Sprintf("Error validating the assertion signature: %+v", err))
+	}
+
+	return p.verify(trace, storedChallenge, relyingPartyID, relyingPartyOrigins, originVerifier, appID, verifyUser, delegated, nil)
+}
+
+func (p *ParsedCredentialAssertionData) verify(trace *VerificationTrace, storedChallenge string, relyingPartyID string, relyingPartyOrigins []string, originVerifier OriginVerifier, appID string, verifyUser bool, verifier SignatureVerifier, signatureInputs map[string]string) error {
 	// Steps 4 through 6 in verifying the assertion data (https://www.w3.org/TR/webauthn/#verifying-assertion) are
 	// "assertive" steps, i.e "Let JSONtext be the result of running UTF-8 decode on the value of cData."
 	// We handle these steps in part as we verify but also beforehand
 
+	// Step 15. Let hash be the result of computing a hash over the cData using SHA-256.
+	clientDataHash := sha256.Sum256(p.Raw.AssertionResponse.ClientDataJSON)
+
 	// Handle steps 7 through 10 of assertion by verifying stored data against the Collected Client Data
 	// returned by the authenticator
-	validError := p.Response.CollectedClientData.Verify(storedChallenge, AssertCeremony, relyingPartyOrigins)
-	if validError != nil {
+	validError := p.Response.CollectedClientData.VerifyWithOriginVerifier(storedChallenge, AssertCeremony, relyingPartyOrigins, originVerifier)
+
+	if validError = trace.Step(VerificationStepClientData, validError, clientDataTraceInputs(p.Response.CollectedClientData, clientDataHash[:])); validError != nil {
 		return validError
 	}
 
@@ -146,41 +225,30 @@ func (p *ParsedCredentialAssertionData) Verify(storedChallenge string, relyingPa
 
 	// Handle steps 11 through 14, verifying the authenticator data.
 	validError = p.Response.AuthenticatorData.Verify(rpIDHash[:], appIDHash[:], verifyUser)
-	if validError != nil {
+
+	if validError = trace.Step(VerificationStepAuthenticatorData, validError, authenticatorDataTraceInputs(p.Response.AuthenticatorData, rpIDHash[:], verifyUser)); validError != nil {
 		return validError
 	}
 
 	// allowedUserCredentialIDs := session.AllowedCredentialIDs
 
-	// Step 15. Let hash be the result of computing a hash over the cData using SHA-256.
-	clientDataHash := sha256.Sum256(p.Raw.AssertionResponse.ClientDataJSON)
-
 	// Step 16. Using the credential public key looked up in step 3, verify that sig is
 	// a valid signature over the binary concatenation of authData and hash.
 
-	sigData := append(p.Raw.AssertionResponse.AuthenticatorData, clientDataHash[:]...)
+	sigData := getSignedData(p.Raw.AssertionResponse.AuthenticatorData, clientDataHash[:])
+	defer putSignedData(sigData)
 
-	var (
-		key interface{}
-		err error
-	)
+	err := verifier(*sigData, p.Response.Signature)
 
-	// If the Session Data does not contain the appID extension or it wasn't reported as used by the Client/RP then we
-	// use the standard CTAP2 public key parser.
-	if appID == "" {
-		key, err = webauthncose.ParsePublicKey(credentialBytes)
-	} else {
-		key, err = webauthncose.ParseFIDOPublicKey(credentialBytes)
-	}
+	if trace != nil {
+		inputs := map[string]string{"signed_data_hash": traceHash(*sigData)}
 
-	if err != nil {
-		return ErrAssertionSignature.WithDetails(fmt.Sprintf("Error parsing the assertion public key: %+v", err))
-	}
+		for key, value := range signatureInputs {
+			inputs[key] = value
+		}
 
-	valid, err := webauthncose.VerifySignature(key, sigData, p.Response.Signature)
-	if !valid || err != nil {
-		return ErrAssertionSignature.WithDetails(fmt.Sprintf("Error validating the assertion signature: %+v", err))
+		trace.Record(VerificationStepSignature, err, inputs)
 	}
 
-	return nil
+	return err
 }
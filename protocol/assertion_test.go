@@ -2,14 +2,23 @@ package protocol
 
 import (
 	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/json"
 	"io"
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
 )
 
 func TestParseCredentialRequestResponse(t *testing.T) {
@@ -187,6 +196,115 @@ func TestParsedCredentialAssertionData_Verify(t *testing.T) {
 	}
 }
 
+func BenchmarkParsedCredentialAssertionData_Verify(b *testing.B) {
+	testCases := []struct {
+		name string
+		alg  webauthncose.COSEAlgorithmIdentifier
+	}{
+		{"ES256", webauthncose.AlgES256},
+		{"RS256", webauthncose.AlgRS256},
+	}
+
+	for _, tc := range testCases {
+		b.Run(tc.name, func(b *testing.B) {
+			body, credentialBytes := benchmarkAssertionResponse(b, tc.alg)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				par, err := ParseCredentialRequestResponseBody(bytes.NewReader(body))
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				if err = par.Verify(benchmarkChallenge, "example.com", []string{"https://example.com"}, "", false, credentialBytes); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+const benchmarkChallenge = "E4PTcIH_HfX1pC6Sigk1SC9NAlgeztN0439vi8z_c9k"
+
+// benchmarkAssertionResponse returns the body of a valid assertion response for the RP ID example.com signed by a new
+// key of the provided algorithm, and the COSE encoded public key of the credential.
+func benchmarkAssertionResponse(b testing.TB, alg webauthncose.COSEAlgorithmIdentifier) (body, credentialBytes []byte) {
+	b.Helper()
+
+	clientDataJSON, err := json.Marshal(CollectedClientData{Type: AssertCeremony, Challenge: benchmarkChallenge, Origin: "https://example.com"})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	rpIDHash := sha256.Sum256([]byte("example.com"))
+	authData := append(rpIDHash[:], byte(FlagUserPresent), 0, 0, 0, 1)
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+
+	var signature []byte
+
+	switch alg {
+	case webauthncose.AlgES256:
+		var key *ecdsa.PrivateKey
+
+		if key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+			b.Fatal(err)
+		}
+
+		if signature, err = ecdsa.SignASN1(rand.Reader, key, digest[:]); err != nil {
+			b.Fatal(err)
+		}
+
+		credentialBytes, err = webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+			PublicKeyData: webauthncose.PublicKeyData{KeyType: int64(webauthncose.EllipticKey), Algorithm: int64(alg)},
+			Curve:         int64(webauthncose.P256),
+			XCoord:        key.X.FillBytes(make([]byte, 32)),
+			YCoord:        key.Y.FillBytes(make([]byte, 32)),
+		})
+	case webauthncose.AlgRS256:
+		var key *rsa.PrivateKey
+
+		if key, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+			b.Fatal(err)
+		}
+
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:]); err != nil {
+			b.Fatal(err)
+		}
+
+		credentialBytes, err = webauthncbor.Marshal(webauthncose.RSAPublicKeyData{
+			PublicKeyData: webauthncose.PublicKeyData{KeyType: int64(webauthncose.RSAKey), Algorithm: int64(alg)},
+			Modulus:       key.N.Bytes(),
+			Exponent:      big.NewInt(int64(key.E)).Bytes(),
+		})
+	}
+
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	car := CredentialAssertionResponse{
+		PublicKeyCredential: PublicKeyCredential{
+			Credential: Credential{ID: "AQID", Type: "public-key"},
+			RawID:      URLEncodedBase64{1, 2, 3},
+		},
+		AssertionResponse: AuthenticatorAssertionResponse{
+			AuthenticatorResponse: AuthenticatorResponse{ClientDataJSON: clientDataJSON},
+			AuthenticatorData:     authData,
+			Signature:             signature,
+		},
+	}
+
+	if body, err = json.Marshal(car); err != nil {
+		b.Fatal(err)
+	}
+
+	return body, credentialBytes
+}
+
 var testAssertionResponses = map[string]string{
 	// None Attestation - MacOS TouchID.
 	`success`: `{
@@ -4,12 +4,13 @@ import (
 	"crypto/sha256"
 	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/go-webauthn/webauthn/metadata"
-	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
 )
 
 // AuthenticatorAttestationResponse is the initial unpacked 'response' object received by the relying party. This
@@ -35,6 +36,13 @@ type AuthenticatorAttestationResponse struct {
 	AttestationObject URLEncodedBase64 `json:"attestationObject"`
 
 	Transports []string `json:"transports,omitempty"`
+
+	// AuthenticatorData, PublicKey, and PublicKeyAlgorithm are the values which WebAuthn Level 3 clients duplicate from
+	// the attestation object in the RegistrationResponseJSON. The AuthenticatorData and PublicKeyAlgorithm are
+	// verified against the attestation object with ParsedCredentialCreationData.VerifyResponseJSON.
+	AuthenticatorData  URLEncodedBase64 `json:"authenticatorData,omitempty"`
+	PublicKey          URLEncodedBase64 `json:"publicKey,omitempty"`
+	PublicKeyAlgorithm int64            `json:"publicKeyAlgorithm,omitempty"`
 }
 
 // ParsedAttestationResponse is the parsed version of AuthenticatorAttestationResponse.
@@ -66,6 +74,109 @@ type AttestationObject struct {
 	Format string `json:"fmt"`
 	// The attestation statement data sent back if attestation is requested.
 	AttStatement map[string]interface{} `json:"attStmt,omitempty"`
+	// The attestation statements of the compound attestation statement format, which replace the AttStatement.
+	CompoundStatements []CompoundAttestationStatement `json:"-"`
+}
+
+// AttestationPolicy configures the optional strictness of the verification of attestation statements against the
+// metadata. The zero value performs the same verification as earlier versions.
+type AttestationPolicy struct {
+	// RequireMetadata rejects attestation statements from authenticators which are not present in the metadata. This
+	// is the same as enabling metadata.Conformance but only applies to the ceremonies using the policy.
+	RequireMetadata bool
+
+	// VerifyTrustPath verifies the attestation certificate chains up to one of the attestation root certificates of
+	// the metadata statement of the authenticator.
+	VerifyTrustPath bool
+
+	// Metadata is the provider used to look up the metadata of the authenticators. The default is
+	// metadata.DefaultProvider which uses the package level metadata.
+	Metadata metadata.Provider
+
+	// MinimumCertificationLevel rejects attestation statements from authenticators whose metadata does not report a
+	// FIDO certification status at least as strict as this level, such as metadata.FidoCertifiedL2. This implies
+	// RequireMetadata and also rejects the none attestation format as it does not identify the authenticator.
+	MinimumCertificationLevel metadata.AuthenticatorStatus
+
+	// MinimumAuthenticatorVersions maps the AAGUID of an authenticator model to the minimum firmware version accepted
+	// for it, such as to exclude models affected by a known vulnerability. The firmware version is determined from the
+	// metadata of the authenticator, so registrations from an AAGUID in the map are rejected if the authenticator is not
+	// present in the metadata or if the none attestation format is used.
+	MinimumAuthenticatorVersions map[uuid.UUID]uint32
+
+	// RequireHardwareBackedAndroidKey rejects android-key attestation statements unless the key description reports
+	// the key is protected by a trusted execution environment or StrongBox, which excludes keys generated by the
+	// software keystore.
+	RequireHardwareBackedAndroidKey bool
+
+	// RequireVerifiableAttestation rejects attestation statements which do not include an attestation certificate, i.e.
+	// the none attestation format and self attestation, as the authenticator can not be verified in either case.
+	RequireVerifiableAttestation bool
+
+	// RejectAnonymizedAttestation rejects attestation statements anonymized by an attestation CA, which are of the
+	// AttCA or AnonCA attestation type, while still accepting direct attestation. An anonymized attestation statement
+	// has a certificate issued for the credential by a CA of the vendor, so it only identifies the authenticator model
+	// through the CA. The trust path of AnonCA attestation statements from authenticators present in the metadata is
+	// always verified against the attestation root certificates of the metadata, which are the roots of the
+	// anonymization CA, regardless of the VerifyTrustPath.
+	RejectAnonymizedAttestation bool
+
+	// Intermediates are intermediate certificates used to build the chain from the attestation certificate to the
+	// attestation root certificates of the metadata when the trust path is verified, for authenticators whose
+	// attestation statements only include the attestation certificate in the x5c rather than the full chain.
+	Intermediates []*x509.Certificate
+
+	// RequireCompleteChain rejects packed and tpm attestation statements whose x5c does not include every
+	// intermediate certificate between the attestation certificate and the attestation root certificate of the
+	// metadata, so the chain is built without the Intermediates. It implies the trust path of these formats is verified
+	// for authenticators present in the metadata, and is intended for deployments with high assurance requirements.
+	RequireCompleteChain bool
+
+	// SafetyNetMaxAge rejects android-safetynet attestation statements with a timestampMs older than the duration,
+	// which indicates the statement is being replayed. The default only rejects statements older than one minute when
+	// metadata.Conformance is enabled.
+	SafetyNetMaxAge time.Duration
+
+	// SafetyNetClockSkew is the tolerance for the difference between the clocks of the Relying Party and the SafetyNet
+	// service. Statements with a timestampMs in the future are rejected unless they're within the tolerance, and the
+	// tolerance is added to the SafetyNetMaxAge.
+	SafetyNetClockSkew time.Duration
+
+	// Clock returns the current time used to verify the timestampMs of android-safetynet attestation statements. The
+	// default is time.Now.
+	Clock func() time.Time
+
+	// WindowsHelloCompatibility tolerates the known quirks of the tpm attestation statements of Windows Hello, which
+	// cause these very common registrations to fail otherwise. The quirks are an alg which is the algorithm of the
+	// credential public key instead of the algorithm of the AIK certificate, and an extraData of the certInfo which is
+	// computed with a hash function other than the one of the alg. A statement is only verified with the quirks
+	// tolerated when the strict verification fails, and every other requirement of the format is still enforced.
+	WindowsHelloCompatibility bool
+
+	// AllowTPMRS1 accepts the RS1 algorithm, RSASSA-PKCS1-v1_5 with SHA-1, for the signature of tpm attestation
+	// statements and for the certificates of their trust path, which some older TPMs of legacy fleets still use. The
+	// default is to reject them as SHA-1 is no longer considered secure. It only applies to the verification of tpm
+	// attestation statements and never to credential public keys, and statements are only verified with RS1 accepted
+	// when the strict verification fails.
+	AllowTPMRS1 bool
+}
+
+// now returns the current time according to the Clock of the policy.
+func (policy AttestationPolicy) now() time.Time {
+	if policy.Clock == nil {
+		return time.Now()
+	}
+
+	return policy.Clock()
+}
+
+// metadata returns the effective metadata.Provider of the policy.
+func (policy AttestationPolicy) metadata() metadata.Provider {
+	if policy.Metadata == nil {
+		return metadata.DefaultProvider
+	}
+
+	return policy.Metadata
 }
 
 type attestationFormatValidationHandler func(AttestationObject, []byte) (string, []interface{}, error)
@@ -88,7 +199,7 @@ func (ccr *AuthenticatorAttestationResponse) Parse() (p *ParsedAttestationRespon
 		return nil, ErrParsingData.WithInfo(err.Error())
 	}
 
-	if err = webauthncbor.Unmarshal(ccr.AttestationObject, &p.AttestationObject); err != nil {
+	if err = unmarshalAttestationObject(ccr.AttestationObject, &p.AttestationObject); err != nil {
 		return nil, ErrParsingData.WithInfo(err.Error())
 	}
 
@@ -100,7 +211,7 @@ func (ccr *AuthenticatorAttestationResponse) Parse() (p *ParsedAttestationRespon
 	}
 
 	if !p.AttestationObject.AuthData.Flags.HasAttestedCredentialData() {
-		return nil, ErrAttestationFormat.WithInfo("Attestation missing attested credential data flag")
+		return nil, ErrAttestationFormat.WithCode(CodeAuthDataInvalid).WithInfo("Attestation missing attested credential data flag")
 	}
 
 	for _, t := range ccr.Transports {
@@ -115,14 +226,127 @@ func (ccr *AuthenticatorAttestationResponse) Parse() (p *ParsedAttestationRespon
 // Steps 9 through 12 are verified against the auth data. These steps are identical to 11 through 14 for assertion so we
 // handle them with AuthData.
 func (attestationObject *AttestationObject) Verify(relyingPartyID string, clientDataHash []byte, verificationRequired bool) error {
+	return attestationObject.verify(nil, relyingPartyID, clientDataHash, verificationRequired)
+}
+
+func (attestationObject *AttestationObject) verify(trace *VerificationTrace, relyingPartyID string, clientDataHash []byte, verificationRequired bool) error {
+	if err := attestationObject.verifyAuthData(trace, relyingPartyID, verificationRequired); err != nil {
+		return err
+	}
+
+	return attestationObject.verifyAttestation(trace, clientDataHash, AttestationPolicy{})
+}
+
+func (attestationObject *AttestationObject) verifyAuthData(trace *VerificationTrace, relyingPartyID string, verificationRequired bool) error {
 	rpIDHash := sha256.Sum256([]byte(relyingPartyID))
 
 	// Begin Step 9 through 12. Verify that the rpIdHash in authData is the SHA-256 hash of the RP ID expected by the RP.
-	authDataVerificationError := attestationObject.AuthData.Verify(rpIDHash[:], nil, verificationRequired)
-	if authDataVerificationError != nil {
-		return authDataVerificationError
+	err := attestationObject.AuthData.Verify(rpIDHash[:], nil, verificationRequired)
+
+	return trace.Step(VerificationStepAuthenticatorData, err, authenticatorDataTraceInputs(attestationObject.AuthData, rpIDHash[:], verificationRequired))
+}
+
+func (attestationObject *AttestationObject) verifyAttestation(trace *VerificationTrace, clientDataHash []byte, policy AttestationPolicy) error {
+	if attestationObject.Format == compoundAttestationKey {
+		return attestationObject.verifyCompoundAttestation(trace, clientDataHash, policy)
+	}
+
+	attestationType, x5c, err := attestationObject.verifyAttestationStatement(clientDataHash, policy)
+
+	trace.Record(VerificationStepAttestationStatement, err, map[string]string{
+		"format":           attestationObject.Format,
+		"attestation_type": attestationType,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if attestationObject.Format == "none" {
+		return trace.Step(VerificationStepMetadata, attestationObject.verifyNoneMetadata(policy), nil)
 	}
 
+	attestationType, err = attestationObject.verifyAttestationMetadata(attestationType, x5c, policy)
+
+	trace.Record(VerificationStepMetadata, err, map[string]string{
+		"aaguid":           traceAAGUID(attestationObject.AuthData.AttData.AAGUID),
+		"attestation_type": attestationType,
+	})
+
+	return err
+}
+
+// verifyAttestationStatement verifies the attestation statement with the verification procedure of its format and
+// the requirements of the policy which only depend on the statement.
+func (attestationObject *AttestationObject) verifyAttestationStatement(clientDataHash []byte, policy AttestationPolicy) (attestationType string, x5c []interface{}, err error) {
+	attestationType, x5c, err = attestationObject.verifyStatement(clientDataHash)
+
+	if quirks, enabled := policy.tpmQuirks(); err != nil && enabled && attestationObject.Format == tpmAttestationKey {
+		attestationType, x5c, err = attestationObject.verifyStatementWith(quirks.handler(), clientDataHash)
+	}
+
+	switch {
+	case err != nil:
+		break
+	case policy.RequireVerifiableAttestation && len(x5c) == 0:
+		err = ErrInvalidAttestation.
+			WithCode(CodeAttestationUnverifiable).
+			WithDetails("Attestation statement does not include an attestation certificate").
+			WithInfo(fmt.Sprintf("Format: %s", attestationObject.Format))
+	case policy.RequireHardwareBackedAndroidKey && attestationObject.Format == androidAttestationKey:
+		err = verifyAndroidKeyHardwareBacked(x5c)
+	case attestationObject.Format == safetyNetAttestationKey:
+		err = attestationObject.verifySafetyNetTimestamp(policy)
+	}
+
+	return attestationType, x5c, err
+}
+
+// verifyAttestationMetadata verifies the verified attestation statement against the metadata according to the policy,
+// and returns the attestation type classified with the metadata.
+func (attestationObject *AttestationObject) verifyAttestationMetadata(attestationType string, x5c []interface{}, policy AttestationPolicy) (string, error) {
+	if attestationObject.Format == "none" {
+		return attestationType, attestationObject.verifyNoneMetadata(policy)
+	}
+
+	attestationType, err := attestationObject.verifyMetadata(attestationType, x5c, policy)
+
+	if err == nil && policy.RejectAnonymizedAttestation && isAnonymizedAttestationType(attestationType) {
+		err = ErrInvalidAttestation.
+			WithCode(CodeAttestationAnonymized).
+			WithDetails("Attestation statement was anonymized by an attestation CA").
+			WithInfo(fmt.Sprintf("Format: %s, Attestation Type: %s", attestationObject.Format, attestationType))
+	}
+
+	return attestationType, err
+}
+
+// verifyNoneMetadata rejects the none attestation format if the policy requires information from the metadata, as the
+// authenticator is not identified by the attestation.
+func (attestationObject *AttestationObject) verifyNoneMetadata(policy AttestationPolicy) error {
+	if policy.MinimumCertificationLevel != "" {
+		return ErrInvalidAttestation.
+			WithCode(CodeCertificationLevelInsufficient).
+			WithDetails("Authenticator certification level can not be determined without attestation").
+			WithInfo(fmt.Sprintf("Required: %s", policy.MinimumCertificationLevel))
+	}
+
+	aaguid, err := uuid.FromBytes(attestationObject.AuthData.AttData.AAGUID)
+	if err != nil {
+		return nil
+	}
+
+	if minimum, ok := policy.MinimumAuthenticatorVersions[aaguid]; ok {
+		return ErrInvalidAttestation.
+			WithCode(CodeAuthenticatorVersionInsufficient).
+			WithDetails("Authenticator version can not be determined without attestation").
+			WithInfo(fmt.Sprintf("AAGUID: %s, Required: %d", aaguid, minimum))
+	}
+
+	return nil
+}
+
+func (attestationObject *AttestationObject) verifyStatement(clientDataHash []byte) (attestationType string, x5c []interface{}, err error) {
 	// Step 13. Determine the attestation statement format by performing a
 	// USASCII case-sensitive match on fmt against the set of supported
 	// WebAuthn Attestation Statement Format Identifier values. The up-to-date
@@ -137,59 +361,174 @@ func (attestationObject *AttestationObject) Verify(relyingPartyID string, client
 	// any of the following steps
 	if attestationObject.Format == "none" {
 		if len(attestationObject.AttStatement) != 0 {
-			return ErrAttestationFormat.WithInfo("Attestation format none with attestation present")
+			return "", nil, ErrAttestationFormat.WithCode(CodeAttestationInvalid).WithInfo("Attestation format none with attestation present")
 		}
 
-		return nil
+		return "", nil, nil
 	}
 
 	formatHandler, valid := attestationRegistry[attestationObject.Format]
 	if !valid {
-		return ErrAttestationFormat.WithInfo(fmt.Sprintf("Attestation format %s is unsupported", attestationObject.Format))
+		return "", nil, ErrAttestationFormat.WithCode(CodeAttestationFormatUnsupported).WithInfo(fmt.Sprintf("Attestation format %s is unsupported", attestationObject.Format))
 	}
 
+	return attestationObject.verifyStatementWith(formatHandler, clientDataHash)
+}
+
+// verifyStatementWith verifies the attestation statement with the verification procedure of the format.
+func (attestationObject *AttestationObject) verifyStatementWith(formatHandler attestationFormatValidationHandler, clientDataHash []byte) (attestationType string, x5c []interface{}, err error) {
 	// Step 14. Verify that attStmt is a correct attestation statement, conveying a valid attestation signature, by using
 	// the attestation statement format fmt’s verification procedure given attStmt, authData and the hash of the serialized
 	// client data computed in step 7.
-	attestationType, x5c, err := formatHandler(*attestationObject, clientDataHash)
-	if err != nil {
-		return err.(*Error).WithInfo(attestationType)
+	if attestationType, x5c, err = formatHandler(*attestationObject, clientDataHash); err != nil {
+		var e *Error
+
+		if !errors.As(err, &e) {
+			e = ErrInvalidAttestation.WithDetails(err.Error())
+		}
+
+		e = e.WithInfo(attestationType)
+
+		if e.Code == "" {
+			e.Code = CodeAttestationInvalid
+		}
+
+		return attestationType, nil, e
 	}
 
+	return attestationType, x5c, nil
+}
+
+func (attestationObject *AttestationObject) verifyMetadata(attestationType string, x5c []interface{}, policy AttestationPolicy) (string, error) {
 	aaguid, err := uuid.FromBytes(attestationObject.AuthData.AttData.AAGUID)
 	if err != nil {
-		return err
+		return attestationType, err
 	}
 
-	if meta, ok := metadata.Metadata[aaguid]; ok {
+	provider := policy.metadata()
+
+	meta, ok := provider.LookupByAAGUID(aaguid)
+
+	// U2F authenticators don't have an AAGUID so their metadata is identified by the attestation certificate instead.
+	if !ok && aaguid == uuid.Nil && len(x5c) != 0 {
+		if raw, isBytes := x5c[0].([]byte); isBytes {
+			if x5cAtt, err := x509.ParseCertificate(raw); err == nil {
+				meta, ok = provider.LookupByCertificate(x5cAtt)
+			}
+		}
+	}
+
+	if ok {
+		attestationType = classifyAttestationType(attestationType, meta.MetadataStatement)
+
 		for _, s := range meta.StatusReports {
 			if metadata.IsUndesiredAuthenticatorStatus(s.Status) {
-				return ErrInvalidAttestation.WithDetails("Authenticator with undesirable status encountered")
+				return attestationType, ErrInvalidAttestation.WithCode(CodeAuthenticatorStatusUndesired).WithDetails("Authenticator with undesirable status encountered")
 			}
 		}
 
+		if policy.MinimumCertificationLevel != "" && !meta.MeetsCertificationLevel(policy.MinimumCertificationLevel) {
+			return attestationType, ErrInvalidAttestation.
+				WithCode(CodeCertificationLevelInsufficient).
+				WithDetails(fmt.Sprintf("Authenticator certification level %s does not meet the required level %s", meta.CertificationLevel(), policy.MinimumCertificationLevel)).
+				WithInfo(fmt.Sprintf("AAGUID: %s, Description: %s", aaguid, meta.MetadataStatement.Description))
+		}
+
+		if minimum, required := policy.MinimumAuthenticatorVersions[aaguid]; required && meta.AuthenticatorVersion() < minimum {
+			return attestationType, ErrInvalidAttestation.
+				WithCode(CodeAuthenticatorVersionInsufficient).
+				WithDetails(fmt.Sprintf("Authenticator version %d does not meet the required version %d", meta.AuthenticatorVersion(), minimum)).
+				WithInfo(fmt.Sprintf("AAGUID: %s, Description: %s", aaguid, meta.MetadataStatement.Description))
+		}
+
 		if x5c != nil {
 			x5cAtt, err := x509.ParseCertificate(x5c[0].([]byte))
 			if err != nil {
-				return ErrInvalidAttestation.WithDetails("Unable to parse attestation certificate from x5c")
+				return attestationType, ErrInvalidAttestation.WithCode(CodeAttestationInvalid).WithDetails("Unable to parse attestation certificate from x5c")
 			}
 
 			if x5cAtt.Subject.CommonName != x5cAtt.Issuer.CommonName {
-				var hasBasicFull = false
-
-				for _, a := range meta.MetadataStatement.AttestationTypes {
-					if a == metadata.BasicFull || a == metadata.AttCA {
-						hasBasicFull = true
-					}
+				if !hasAttestationType(meta.MetadataStatement, metadata.BasicFull, metadata.AttCA, metadata.AnonCA) {
+					return attestationType, ErrInvalidAttestation.WithCode(CodeAttestationInvalid).WithDetails("Attestation with full attestation from authenticator that does not support full attestation")
 				}
+			}
 
-				if !hasBasicFull {
-					return ErrInvalidAttestation.WithDetails("Attestation with full attestation from authenticator that does not support full attestation")
-				}
+			if policy.VerifyTrustPath || attestationType == string(metadata.AnonCA) || policy.requiresCompleteChain(attestationObject.Format) {
+				return attestationType, attestationObject.verifyAttestationTrustPath(meta, x5cAtt, x5c[1:], policy)
 			}
 		}
-	} else if metadata.Conformance {
-		return ErrInvalidAttestation.WithDetails(fmt.Sprintf("AAGUID %s not found in metadata during conformance testing", aaguid.String()))
+	} else if _, required := policy.MinimumAuthenticatorVersions[aaguid]; required || policy.RequireMetadata || policy.MinimumCertificationLevel != "" || metadata.Conformance {
+		return attestationType, ErrInvalidAttestation.WithCode(CodeAuthenticatorUnknown).WithDetails(fmt.Sprintf("AAGUID %s not found in metadata during conformance testing", aaguid.String()))
+	}
+
+	return attestationType, nil
+}
+
+// classifyAttestationType returns the AttCA or AnonCA attestation type for a statement verified as basic_full when the
+// metadata statement declares the authenticator anonymizes its attestation by an attestation CA instead of using a
+// batch certificate.
+func classifyAttestationType(attestationType string, statement metadata.MetadataStatement) string {
+	if attestationType != string(metadata.BasicFull) || hasAttestationType(statement, metadata.BasicFull) {
+		return attestationType
+	}
+
+	for _, anonymized := range []metadata.AuthenticatorAttestationType{metadata.AnonCA, metadata.AttCA} {
+		if hasAttestationType(statement, anonymized) {
+			return string(anonymized)
+		}
+	}
+
+	return attestationType
+}
+
+// hasAttestationType returns true if the metadata statement declares any of the attestation types.
+func hasAttestationType(statement metadata.MetadataStatement, types ...metadata.AuthenticatorAttestationType) bool {
+	for _, a := range statement.AttestationTypes {
+		for _, t := range types {
+			if a == t {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isAnonymizedAttestationType returns true if the attestation type is anonymized by an attestation CA.
+func isAnonymizedAttestationType(attestationType string) bool {
+	return attestationType == string(metadata.AttCA) || attestationType == string(metadata.AnonCA)
+}
+
+// verifyTrustPath verifies the attestation certificate chains up to one of the attestation root certificates of the
+// metadata statement using the remaining certificates of the x5c as intermediates.
+func verifyTrustPath(meta metadata.MetadataBLOBPayloadEntry, x5cAtt *x509.Certificate, intermediates []interface{}) error {
+	roots, err := metadata.RootCertPool(meta)
+	if err != nil {
+		return ErrInvalidAttestation.WithCode(CodeAttestationInvalid).WithDetails("Unable to parse the attestation root certificates from the metadata statement")
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: x509.NewCertPool(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+
+	for _, c := range intermediates {
+		raw, ok := c.([]byte)
+		if !ok {
+			return ErrInvalidAttestation.WithCode(CodeAttestationInvalid).WithDetails("Unable to parse intermediate certificate from x5c")
+		}
+
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return ErrInvalidAttestation.WithCode(CodeAttestationInvalid).WithDetails("Unable to parse intermediate certificate from x5c")
+		}
+
+		opts.Intermediates.AddCert(cert)
+	}
+
+	if _, err = x5cAtt.Verify(opts); err != nil {
+		return ErrInvalidAttestation.WithCode(CodeAttestationInvalid).WithDetails("Attestation certificate does not chain to an attestation root certificate of the metadata statement").WithInfo(err.Error())
 	}
 
 	return nil
@@ -1,7 +1,7 @@
 package protocol
 
 import (
-	"bytes"
+	"crypto/subtle"
 	"crypto/x509"
 	"encoding/asn1"
 	"fmt"
@@ -83,7 +83,10 @@ func verifyAndroidKeyFormat(att AttestationObject, clientDataHash []byte) (strin
 		return "", nil, ErrInvalidAttestation.WithDetails(fmt.Sprintf("Error parsing public key: %+v\n", err))
 	}
 
-	e := pubKey.(webauthncose.EC2PublicKeyData)
+	e, ok := pubKey.(webauthncose.EC2PublicKeyData)
+	if !ok {
+		return "", nil, ErrInvalidAttestation.WithDetails("Public key is not an EC2 public key")
+	}
 
 	valid, err = e.Verify(signatureData, sig)
 	if err != nil || !valid {
@@ -91,7 +94,37 @@ func verifyAndroidKeyFormat(att AttestationObject, clientDataHash []byte) (strin
 	}
 
 	// §8.4.3. Verify that the attestationChallenge field in the attestation certificate extension data is identical to clientDataHash.
-	// attCert.Extensions
+	decoded, err := parseAndroidKeyDescription(attCert)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Verify that the attestationChallenge field in the attestation certificate extension data is identical to clientDataHash.
+	if subtle.ConstantTimeCompare(decoded.AttestationChallenge, clientDataHash) != 1 {
+		return "", nil, ErrAttestationFormat.WithDetails("Attestation challenge not equal to clientDataHash")
+	}
+
+	// The AuthorizationList.allApplications field is not present on either authorization list (softwareEnforced nor teeEnforced), since PublicKeyCredential MUST be scoped to the RP ID.
+	if nil != decoded.SoftwareEnforced.AllApplications || nil != decoded.TeeEnforced.AllApplications {
+		return "", nil, ErrAttestationFormat.WithDetails("Attestation certificate extensions contains all applications field")
+	}
+
+	// For the following, use only the teeEnforced authorization list if the RP wants to accept only keys from a trusted execution environment, otherwise use the union of teeEnforced and softwareEnforced.
+	// The value in the AuthorizationList.origin field is equal to KM_ORIGIN_GENERATED.  (which == 0)
+	if KM_ORIGIN_GENERATED != decoded.SoftwareEnforced.Origin || KM_ORIGIN_GENERATED != decoded.TeeEnforced.Origin {
+		return "", nil, ErrAttestationFormat.WithDetails("Attestation certificate extensions contains authorization list with origin not equal KM_ORIGIN_GENERATED")
+	}
+
+	// The value in the AuthorizationList.purpose field is equal to KM_PURPOSE_SIGN.  (which == 2)
+	if !contains(decoded.SoftwareEnforced.Purpose, KM_PURPOSE_SIGN) && !contains(decoded.TeeEnforced.Purpose, KM_PURPOSE_SIGN) {
+		return "", nil, ErrAttestationFormat.WithDetails("Attestation certificate extensions contains authorization list with purpose not equal KM_PURPOSE_SIGN")
+	}
+
+	return string(metadata.BasicFull), x5c, err
+}
+
+// parseAndroidKeyDescription parses the Android key attestation certificate extension of the attestation certificate.
+func parseAndroidKeyDescription(attCert *x509.Certificate) (decoded keyDescription, err error) {
 	var attExtBytes []byte
 
 	for _, ext := range attCert.Extensions {
@@ -101,39 +134,59 @@ func verifyAndroidKeyFormat(att AttestationObject, clientDataHash []byte) (strin
 	}
 
 	if len(attExtBytes) == 0 {
-		return "", nil, ErrAttestationFormat.WithDetails("Attestation certificate extensions missing 1.3.6.1.4.1.11129.2.1.17")
+		return decoded, ErrAttestationFormat.WithDetails("Attestation certificate extensions missing 1.3.6.1.4.1.11129.2.1.17")
 	}
 
 	// As noted in §8.4.1 (https://www.w3.org/TR/webauthn/#key-attstn-cert-requirements) the Android Key Attestation attestation certificate's
 	// android key attestation certificate extension data is identified by the OID "1.3.6.1.4.1.11129.2.1.17".
-	decoded := keyDescription{}
-
 	if _, err = asn1.Unmarshal(attExtBytes, &decoded); err != nil {
-		return "", nil, ErrAttestationFormat.WithDetails("Unable to parse Android key attestation certificate extensions")
+		return decoded, ErrAttestationFormat.WithDetails("Unable to parse Android key attestation certificate extensions")
 	}
 
-	// Verify that the attestationChallenge field in the attestation certificate extension data is identical to clientDataHash.
-	if 0 != bytes.Compare(decoded.AttestationChallenge, clientDataHash) {
-		return "", nil, ErrAttestationFormat.WithDetails("Attestation challenge not equal to clientDataHash")
+	return decoded, nil
+}
+
+// verifyAndroidKeyHardwareBacked verifies the android-key attestation certificate of the x5c reports the key is
+// protected by a trusted execution environment or StrongBox rather than by the software keystore. As recommended by
+// §8.4.2 only the teeEnforced authorization list is used in this case.
+func verifyAndroidKeyHardwareBacked(x5c []interface{}) error {
+	if len(x5c) == 0 {
+		return ErrAttestationFormat.WithDetails("Error retrieving x5c value")
 	}
 
-	// The AuthorizationList.allApplications field is not present on either authorization list (softwareEnforced nor teeEnforced), since PublicKeyCredential MUST be scoped to the RP ID.
-	if nil != decoded.SoftwareEnforced.AllApplications || nil != decoded.TeeEnforced.AllApplications {
-		return "", nil, ErrAttestationFormat.WithDetails("Attestation certificate extensions contains all applications field")
+	raw, ok := x5c[0].([]byte)
+	if !ok {
+		return ErrAttestation.WithDetails("Error getting certificate from x5c cert chain")
 	}
 
-	// For the following, use only the teeEnforced authorization list if the RP wants to accept only keys from a trusted execution environment, otherwise use the union of teeEnforced and softwareEnforced.
-	// The value in the AuthorizationList.origin field is equal to KM_ORIGIN_GENERATED.  (which == 0)
-	if KM_ORIGIN_GENERATED != decoded.SoftwareEnforced.Origin || KM_ORIGIN_GENERATED != decoded.TeeEnforced.Origin {
-		return "", nil, ErrAttestationFormat.WithDetails("Attestation certificate extensions contains authorization list with origin not equal KM_ORIGIN_GENERATED")
+	attCert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		return ErrAttestationFormat.WithDetails(fmt.Sprintf("Error parsing certificate from ASN.1 data: %+v", err))
 	}
 
-	// The value in the AuthorizationList.purpose field is equal to KM_PURPOSE_SIGN.  (which == 2)
-	if !contains(decoded.SoftwareEnforced.Purpose, KM_PURPOSE_SIGN) && !contains(decoded.TeeEnforced.Purpose, KM_PURPOSE_SIGN) {
-		return "", nil, ErrAttestationFormat.WithDetails("Attestation certificate extensions contains authorization list with purpose not equal KM_PURPOSE_SIGN")
+	decoded, err := parseAndroidKeyDescription(attCert)
+	if err != nil {
+		return err
 	}
 
-	return string(metadata.BasicFull), x5c, err
+	return decoded.verifyHardwareBacked()
+}
+
+func (d keyDescription) verifyHardwareBacked() error {
+	if d.AttestationSecurityLevel == KM_SECURITY_LEVEL_SOFTWARE || d.KeymasterSecurityLevel == KM_SECURITY_LEVEL_SOFTWARE {
+		return ErrInvalidAttestation.
+			WithCode(CodeKeySecurityLevelInsufficient).
+			WithDetails("Android key attestation reports a software backed key").
+			WithInfo(fmt.Sprintf("Attestation Security Level: %d, Keymaster Security Level: %d", d.AttestationSecurityLevel, d.KeymasterSecurityLevel))
+	}
+
+	if !contains(d.TeeEnforced.Purpose, KM_PURPOSE_SIGN) {
+		return ErrInvalidAttestation.
+			WithCode(CodeKeySecurityLevelInsufficient).
+			WithDetails("Android key attestation hardware enforced authorization list does not contain purpose KM_PURPOSE_SIGN")
+	}
+
+	return nil
 }
 
 func contains(s []int, e int) bool {
@@ -212,6 +265,17 @@ const (
 	Failed
 )
 
+/**
+ * The security level of the attestation or of the keymaster, i.e. where the key is protected.
+ */
+type KM_SECURITY_LEVEL int
+
+const (
+	KM_SECURITY_LEVEL_SOFTWARE            = iota /* Protected by the Android system in software. */
+	KM_SECURITY_LEVEL_TRUSTED_ENVIRONMENT        /* Protected by a trusted execution environment. */
+	KM_SECURITY_LEVEL_STRONGBOX                  /* Protected by a dedicated secure element. */
+)
+
 /**
  * The origin of a key (or pair), i.e. where it was generated.  Note that KM_TAG_ORIGIN can be found
  * in either the hardware-enforced or software-enforced list for a key, indicating whether the key
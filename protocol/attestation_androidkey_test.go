@@ -4,6 +4,9 @@ import (
 	"crypto/sha256"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/go-webauthn/webauthn/metadata"
 )
 
@@ -64,6 +67,91 @@ func TestVerifyAndroidKeyFormat(t *testing.T) {
 	}
 }
 
+func TestKeyDescription_VerifyHardwareBacked(t *testing.T) {
+	testCases := []struct {
+		name     string
+		have     keyDescription
+		expected string
+	}{
+		{
+			"ShouldAllowTrustedEnvironment",
+			keyDescription{
+				AttestationSecurityLevel: KM_SECURITY_LEVEL_TRUSTED_ENVIRONMENT,
+				KeymasterSecurityLevel:   KM_SECURITY_LEVEL_TRUSTED_ENVIRONMENT,
+				TeeEnforced:              authorizationList{Purpose: []int{KM_PURPOSE_SIGN}},
+			},
+			"",
+		},
+		{
+			"ShouldAllowStrongBox",
+			keyDescription{
+				AttestationSecurityLevel: KM_SECURITY_LEVEL_STRONGBOX,
+				KeymasterSecurityLevel:   KM_SECURITY_LEVEL_STRONGBOX,
+				TeeEnforced:              authorizationList{Purpose: []int{KM_PURPOSE_SIGN}},
+			},
+			"",
+		},
+		{
+			"ShouldRejectSoftwareAttestation",
+			keyDescription{
+				AttestationSecurityLevel: KM_SECURITY_LEVEL_SOFTWARE,
+				KeymasterSecurityLevel:   KM_SECURITY_LEVEL_TRUSTED_ENVIRONMENT,
+				TeeEnforced:              authorizationList{Purpose: []int{KM_PURPOSE_SIGN}},
+			},
+			"Android key attestation reports a software backed key",
+		},
+		{
+			"ShouldRejectSoftwareKeymaster",
+			keyDescription{
+				AttestationSecurityLevel: KM_SECURITY_LEVEL_TRUSTED_ENVIRONMENT,
+				KeymasterSecurityLevel:   KM_SECURITY_LEVEL_SOFTWARE,
+				TeeEnforced:              authorizationList{Purpose: []int{KM_PURPOSE_SIGN}},
+			},
+			"Android key attestation reports a software backed key",
+		},
+		{
+			"ShouldRejectSoftwareEnforcedPurpose",
+			keyDescription{
+				AttestationSecurityLevel: KM_SECURITY_LEVEL_TRUSTED_ENVIRONMENT,
+				KeymasterSecurityLevel:   KM_SECURITY_LEVEL_TRUSTED_ENVIRONMENT,
+				SoftwareEnforced:         authorizationList{Purpose: []int{KM_PURPOSE_SIGN}},
+			},
+			"Android key attestation hardware enforced authorization list does not contain purpose KM_PURPOSE_SIGN",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.have.verifyHardwareBacked()
+
+			if tc.expected == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			require.IsType(t, &Error{}, err)
+			assert.Equal(t, CodeKeySecurityLevelInsufficient, err.(*Error).Code)
+			assert.Equal(t, tc.expected, err.(*Error).Details)
+		})
+	}
+}
+
+func TestAttestationObject_VerifyAttestationHardwareBackedAndroidKey(t *testing.T) {
+	response := attestationTestUnpackResponse(t, androidKeyTestResponse0["success"])
+	clientDataHash := sha256.Sum256(response.Raw.AttestationResponse.ClientDataJSON)
+
+	att := response.Response.AttestationObject
+
+	assert.NoError(t, att.verifyAttestation(nil, clientDataHash[:], AttestationPolicy{}))
+
+	err := att.verifyAttestation(nil, clientDataHash[:], AttestationPolicy{RequireHardwareBackedAndroidKey: true})
+
+	require.IsType(t, &Error{}, err)
+	assert.Equal(t, CodeKeySecurityLevelInsufficient, err.(*Error).Code)
+	assert.Equal(t, FailureReasonAttestationRejected, GetFailureReason(err))
+}
+
 var androidKeyTestResponse0 = map[string]string{
 	`success`: `{
 		"rawId": "U5cxFNxLbU9-SAi1K7k9atYwXhghkAMbxpL__VPtBlw",
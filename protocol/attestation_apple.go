@@ -1,10 +1,10 @@
 package protocol
 
 import (
-	"bytes"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/sha256"
+	"crypto/subtle"
 	"crypto/x509"
 	"encoding/asn1"
 	"fmt"
@@ -79,7 +79,7 @@ func verifyAppleFormat(att AttestationObject, clientDataHash []byte) (string, []
 		return "", nil, ErrAttestationFormat.WithDetails("Unable to parse apple attestation certificate extensions")
 	}
 
-	if !bytes.Equal(decoded.Nonce, nonce[:]) || err != nil {
+	if subtle.ConstantTimeCompare(decoded.Nonce, nonce[:]) != 1 || err != nil {
 		return "", nil, ErrInvalidAttestation.WithDetails("Attestation certificate does not contain expected nonce")
 	}
 
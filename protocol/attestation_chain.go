@@ -0,0 +1,74 @@
+package protocol
+
+import (
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/go-webauthn/webauthn/metadata"
+)
+
+// completeChainFormats are the attestation statement formats whose x5c may only include the attestation certificate,
+// which are subject to the RequireCompleteChain of the AttestationPolicy.
+var completeChainFormats = map[string]bool{
+	packedAttestationKey: true,
+	tpmAttestationKey:    true,
+}
+
+// requiresCompleteChain returns true if the policy requires the x5c of the attestation statement format to include the
+// complete certificate chain.
+func (policy AttestationPolicy) requiresCompleteChain(format string) bool {
+	return policy.RequireCompleteChain && completeChainFormats[format]
+}
+
+// verifyAttestationTrustPath verifies the attestation certificate chains up to one of the attestation root certificates
+// of the metadata statement, using the Intermediates of the policy in addition to the intermediates of the x5c unless
+// the policy requires the complete chain for the format.
+func (attestationObject *AttestationObject) verifyAttestationTrustPath(meta metadata.MetadataBLOBPayloadEntry, x5cAtt *x509.Certificate, intermediates []interface{}, policy AttestationPolicy) error {
+	if !policy.requiresCompleteChain(attestationObject.Format) {
+		return attestationObject.verifyTrustPathWith(meta, x5cAtt, withIntermediates(intermediates, policy.Intermediates), policy)
+	}
+
+	err := attestationObject.verifyTrustPathWith(meta, x5cAtt, intermediates, policy)
+	if err == nil || len(policy.Intermediates) == 0 {
+		return err
+	}
+
+	// Distinguish an x5c which only lacks intermediates from an attestation certificate which is not trusted at all.
+	if attestationObject.verifyTrustPathWith(meta, x5cAtt, withIntermediates(intermediates, policy.Intermediates), policy) == nil {
+		return ErrInvalidAttestation.
+			WithCode(CodeAttestationChainIncomplete).
+			WithDetails("Attestation statement does not include the complete certificate chain").
+			WithInfo(fmt.Sprintf("Format: %s, Certificates: %d", attestationObject.Format, len(intermediates)+1))
+	}
+
+	return err
+}
+
+// verifyTrustPathWith verifies the trust path with the intermediates, falling back to the verification of the RS1
+// signatures for the tpm format when allowed by the policy.
+func (attestationObject *AttestationObject) verifyTrustPathWith(meta metadata.MetadataBLOBPayloadEntry, x5cAtt *x509.Certificate, intermediates []interface{}, policy AttestationPolicy) error {
+	err := verifyTrustPath(meta, x5cAtt, intermediates)
+
+	if err != nil && policy.AllowTPMRS1 && attestationObject.Format == tpmAttestationKey {
+		err = verifyRS1TrustPath(meta, x5cAtt, intermediates, time.Now())
+	}
+
+	return err
+}
+
+// withIntermediates returns the intermediates of the x5c followed by the raw additional certificates.
+func withIntermediates(intermediates []interface{}, certs []*x509.Certificate) []interface{} {
+	if len(certs) == 0 {
+		return intermediates
+	}
+
+	combined := make([]interface{}, 0, len(intermediates)+len(certs))
+	combined = append(combined, intermediates...)
+
+	for _, cert := range certs {
+		combined = append(combined, cert.Raw)
+	}
+
+	return combined
+}
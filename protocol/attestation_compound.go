@@ -0,0 +1,141 @@
+package protocol
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+)
+
+var compoundAttestationKey = "compound"
+
+func init() {
+	RegisterAttestationFormat(compoundAttestationKey, verifyCompoundFormat)
+}
+
+// CompoundAttestationStatement is one of the attestation statements of a compound attestation statement.
+type CompoundAttestationStatement struct {
+	// The format of the attestation statement, which is any format other than compound.
+	Format string `json:"fmt"`
+	// The attestation statement data.
+	AttStatement map[string]interface{} `json:"attStmt"`
+}
+
+// The compound attestation statement looks like:
+//
+//	$$attStmtType //= (
+//		fmt: "compound",
+//		attStmt: [2* nonCompoundAttStmt]
+//	)
+//
+//	nonCompoundAttStmt = { $$attStmtType } .within { fmt: text .ne "compound", * any => any }
+//
+// Every attestation statement must verify with the verification procedure of its format. The attestation type of the
+// compound statement is the combination of the attestation types of its statements.
+//
+// Specification: §8.9. Compound Attestation Statement Format (https://www.w3.org/TR/webauthn-3/#sctn-compound-attestation)
+func verifyCompoundFormat(att AttestationObject, clientDataHash []byte) (string, []interface{}, error) {
+	_, attestationTypes, _, err := att.verifyCompoundStatements(clientDataHash, AttestationPolicy{})
+
+	return strings.Join(attestationTypes, ","), nil, err
+}
+
+// verifyCompoundAttestation verifies every attestation statement of a compound attestation statement and then every
+// statement against the metadata, recording the combined outcome of each in the trace.
+func (attestationObject *AttestationObject) verifyCompoundAttestation(trace *VerificationTrace, clientDataHash []byte, policy AttestationPolicy) error {
+	statements, attestationTypes, chains, err := attestationObject.verifyCompoundStatements(clientDataHash, policy)
+
+	formats := make([]string, len(statements))
+
+	for i, statement := range statements {
+		formats[i] = statement.Format
+	}
+
+	trace.Record(VerificationStepAttestationStatement, err, map[string]string{
+		"format":           attestationObject.Format,
+		"formats":          strings.Join(formats, ","),
+		"attestation_type": strings.Join(attestationTypes, ","),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for i, statement := range statements {
+		if attestationTypes[i], err = statement.verifyAttestationMetadata(attestationTypes[i], chains[i], policy); err != nil {
+			break
+		}
+	}
+
+	trace.Record(VerificationStepMetadata, err, map[string]string{
+		"aaguid":           traceAAGUID(attestationObject.AuthData.AttData.AAGUID),
+		"attestation_type": strings.Join(attestationTypes, ","),
+	})
+
+	return err
+}
+
+// verifyCompoundStatements verifies every attestation statement of a compound attestation statement with the
+// authenticator data of the attestation object, and returns the statements as attestation objects along with their
+// attestation types and certificate chains.
+func (attestationObject *AttestationObject) verifyCompoundStatements(clientDataHash []byte, policy AttestationPolicy) (statements []AttestationObject, attestationTypes []string, chains [][]interface{}, err error) {
+	if len(attestationObject.CompoundStatements) < 2 {
+		return nil, nil, nil, ErrAttestationFormat.
+			WithCode(CodeAttestationInvalid).
+			WithDetails("Compound attestation statement must contain at least two attestation statements").
+			WithInfo(fmt.Sprintf("Statements: %d", len(attestationObject.CompoundStatements)))
+	}
+
+	statements = make([]AttestationObject, len(attestationObject.CompoundStatements))
+	attestationTypes = make([]string, len(statements))
+	chains = make([][]interface{}, len(statements))
+
+	for i, statement := range attestationObject.CompoundStatements {
+		if statement.Format == compoundAttestationKey {
+			return statements, attestationTypes, chains, ErrAttestationFormat.
+				WithCode(CodeAttestationInvalid).
+				WithDetails("Compound attestation statement must not contain a compound attestation statement")
+		}
+
+		statements[i] = AttestationObject{
+			AuthData:     attestationObject.AuthData,
+			RawAuthData:  attestationObject.RawAuthData,
+			Format:       statement.Format,
+			AttStatement: statement.AttStatement,
+		}
+	}
+
+	for i := range statements {
+		if attestationTypes[i], chains[i], err = statements[i].verifyAttestationStatement(clientDataHash, policy); err != nil {
+			return statements, attestationTypes, chains, err
+		}
+	}
+
+	return statements, attestationTypes, chains, nil
+}
+
+// unmarshalAttestationObject decodes the CBOR encoded attestation object. The attestation statement is decoded into
+// the CompoundStatements for the compound format, as it's an array of attestation statements rather than a map.
+func unmarshalAttestationObject(data []byte, attestationObject *AttestationObject) error {
+	var raw struct {
+		RawAuthData  []byte                  `json:"authData"`
+		Format       string                  `json:"fmt"`
+		AttStatement webauthncbor.RawMessage `json:"attStmt,omitempty"`
+	}
+
+	if err := webauthncbor.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	attestationObject.RawAuthData, attestationObject.Format = raw.RawAuthData, raw.Format
+
+	if len(raw.AttStatement) == 0 {
+		return nil
+	}
+
+	if raw.Format == compoundAttestationKey {
+		return webauthncbor.Unmarshal(raw.AttStatement, &attestationObject.CompoundStatements)
+	}
+
+	return webauthncbor.Unmarshal(raw.AttStatement, &attestationObject.AttStatement)
+}
@@ -0,0 +1,145 @@
+package protocol
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+)
+
+// compoundTestAttestationObject re-encodes the attestation object of the response as a compound attestation object
+// with the statements returned by the function from the original statement.
+func compoundTestAttestationObject(t *testing.T, response string, statements func(format string, stmt map[string]interface{}) []interface{}) (AuthenticatorAttestationResponse, []byte) {
+	pcc := attestationTestUnpackResponse(t, response)
+
+	var original struct {
+		AuthData []byte                 `cbor:"authData"`
+		Format   string                 `cbor:"fmt"`
+		AttStmt  map[string]interface{} `cbor:"attStmt"`
+	}
+
+	require.NoError(t, webauthncbor.Unmarshal(pcc.Raw.AttestationResponse.AttestationObject, &original))
+
+	data, err := webauthncbor.Marshal(map[string]interface{}{
+		"fmt":      compoundAttestationKey,
+		"authData": original.AuthData,
+		"attStmt":  statements(original.Format, original.AttStmt),
+	})
+	require.NoError(t, err)
+
+	ccr := pcc.Raw.AttestationResponse
+	ccr.AttestationObject = data
+
+	clientDataHash := sha256.Sum256(ccr.ClientDataJSON)
+
+	return ccr, clientDataHash[:]
+}
+
+func TestVerifyCompoundAttestation(t *testing.T) {
+	statement := func(format string, stmt map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"fmt": format, "attStmt": stmt}
+	}
+
+	tampered := func(stmt map[string]interface{}) map[string]interface{} {
+		copied := make(map[string]interface{}, len(stmt))
+
+		for key, value := range stmt {
+			copied[key] = value
+		}
+
+		sig := append([]byte(nil), stmt["sig"].([]byte)...)
+		sig[len(sig)-1] ^= 0xff
+
+		copied["sig"] = sig
+
+		return copied
+	}
+
+	testCases := []struct {
+		name       string
+		statements func(format string, stmt map[string]interface{}) []interface{}
+		policy     AttestationPolicy
+		expected   string
+		code       ErrorCode
+	}{
+		{
+			"ShouldVerifyEveryStatement",
+			func(format string, stmt map[string]interface{}) []interface{} {
+				return []interface{}{statement(format, stmt), statement(format, stmt)}
+			},
+			AttestationPolicy{},
+			"basic_full,basic_full",
+			"",
+		},
+		{
+			"ShouldRejectSingleStatement",
+			func(format string, stmt map[string]interface{}) []interface{} {
+				return []interface{}{statement(format, stmt)}
+			},
+			AttestationPolicy{},
+			"",
+			CodeAttestationInvalid,
+		},
+		{
+			"ShouldRejectNestedCompoundStatement",
+			func(format string, stmt map[string]interface{}) []interface{} {
+				return []interface{}{statement(format, stmt), statement(compoundAttestationKey, stmt)}
+			},
+			AttestationPolicy{},
+			"",
+			CodeAttestationInvalid,
+		},
+		{
+			"ShouldRejectInvalidStatement",
+			func(format string, stmt map[string]interface{}) []interface{} {
+				return []interface{}{statement(format, stmt), statement(format, tampered(stmt))}
+			},
+			AttestationPolicy{},
+			"",
+			CodeAttestationInvalid,
+		},
+		{
+			"ShouldApplyPolicyToEveryStatement",
+			func(format string, stmt map[string]interface{}) []interface{} {
+				return []interface{}{statement(format, stmt), statement("none", map[string]interface{}{})}
+			},
+			AttestationPolicy{RequireVerifiableAttestation: true},
+			"",
+			CodeAttestationUnverifiable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ccr, clientDataHash := compoundTestAttestationObject(t, testAttestationResponses[3], tc.statements)
+
+			p, err := ccr.Parse()
+			require.NoError(t, err)
+
+			assert.Equal(t, compoundAttestationKey, p.AttestationObject.Format)
+			assert.Empty(t, p.AttestationObject.AttStatement)
+
+			trace := &VerificationTrace{}
+
+			err = p.AttestationObject.verifyAttestation(trace, clientDataHash, tc.policy)
+
+			if tc.code != "" {
+				var e *Error
+
+				require.ErrorAs(t, err, &e)
+				assert.Equal(t, tc.code, e.Code)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, trace.Steps, 2)
+
+			assert.Equal(t, "packed,packed", trace.Steps[0].Inputs["formats"])
+			assert.Equal(t, tc.expected, trace.Steps[1].Inputs["attestation_type"])
+		})
+	}
+}
@@ -98,7 +98,8 @@ func handleBasicAttestation(signature, clientDataHash, authData, aaguid []byte,
 		return "", x5c, ErrAttestation.WithDetails("Error getting certificate from x5c cert chain")
 	}
 
-	signatureData := append(authData, clientDataHash...)
+	signatureData := getSignedData(authData, clientDataHash)
+	defer putSignedData(signatureData)
 
 	attCert, err := x509.ParseCertificate(attCertBytes)
 	if err != nil {
@@ -108,7 +109,7 @@ func handleBasicAttestation(signature, clientDataHash, authData, aaguid []byte,
 	coseAlg := webauthncose.COSEAlgorithmIdentifier(alg)
 	sigAlg := webauthncose.SigAlgFromCOSEAlg(coseAlg)
 
-	if err = attCert.CheckSignature(x509.SignatureAlgorithm(sigAlg), signatureData, signature); err != nil {
+	if err = attCert.CheckSignature(x509.SignatureAlgorithm(sigAlg), *signatureData, signature); err != nil {
 		return "", x5c, ErrInvalidAttestation.WithDetails(fmt.Sprintf("Signature validation error: %+v\n", err))
 	}
 
@@ -210,7 +211,8 @@ func handleSelfAttestation(alg int64, pubKey, authData, clientDataHash, signatur
 
 	// §4.2 Verify that sig is a valid signature over the concatenation of authenticatorData and
 	// clientDataHash using the credential public key with alg.
-	verificationData := append(authData, clientDataHash...)
+	verificationData := getSignedData(authData, clientDataHash)
+	defer putSignedData(verificationData)
 
 	key, err := webauthncose.ParsePublicKey(pubKey)
 	if err != nil {
@@ -232,7 +234,7 @@ func handleSelfAttestation(alg int64, pubKey, authData, clientDataHash, signatur
 		return "", nil, err
 	}
 
-	valid, err := webauthncose.VerifySignature(key, verificationData, signature)
+	valid, err := webauthncose.VerifySignature(key, *verificationData, signature)
 	if !valid && err == nil {
 		return "", nil, ErrInvalidAttestation.WithDetails("Unable to verify signature")
 	}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/go-webauthn/webauthn/metadata"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
 )
 
 func Test_verifyPackedFormat(t *testing.T) {
@@ -61,6 +62,10 @@ func Test_verifyPackedFormat(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			if webauthncose.FIPSMode && tt.name == "success 512" {
+				t.Skip("ES512 is not FIPS approved")
+			}
+
 			got, _, err := verifyPackedFormat(tt.args.att, tt.args.clientDataHash)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("verifyPackedFormat() error = %v, wantErr %v", err, tt.wantErr)
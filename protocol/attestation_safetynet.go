@@ -1,8 +1,8 @@
 package protocol
 
 import (
-	"bytes"
 	"crypto/sha256"
+	"crypto/subtle"
 	"crypto/x509"
 	"encoding/base64"
 	"fmt"
@@ -16,6 +16,10 @@ import (
 
 var safetyNetAttestationKey = "android-safetynet"
 
+// defaultSafetyNetMaxAge is the maximum age of the timestamp of a SafetyNet response when metadata.Conformance is
+// enabled and the policy does not configure one.
+const defaultSafetyNetMaxAge = time.Minute
+
 func init() {
 	RegisterAttestationFormat(safetyNetAttestationKey, verifySafetyNetFormat)
 }
@@ -105,7 +109,7 @@ func verifySafetyNetFormat(att AttestationObject, clientDataHash []byte) (string
 	nonceBuffer := sha256.Sum256(append(att.RawAuthData, clientDataHash...))
 
 	nonceBytes, err := base64.StdEncoding.DecodeString(safetyNetResponse.Nonce)
-	if !bytes.Equal(nonceBuffer[:], nonceBytes) || err != nil {
+	if subtle.ConstantTimeCompare(nonceBuffer[:], nonceBytes) != 1 || err != nil {
 		return "", nil, ErrInvalidAttestation.WithDetails("Invalid nonce for in SafetyNet response")
 	}
 
@@ -134,23 +138,62 @@ func verifySafetyNetFormat(att AttestationObject, clientDataHash []byte) (string
 		return "", nil, ErrInvalidAttestation.WithDetails("ctsProfileMatch attribute of the JWT payload is false")
 	}
 
-	// Verify sanity of timestamp in the payload
-	now := time.Now()
-	oneMinuteAgo := now.Add(-time.Minute)
-
-	if t := time.Unix(safetyNetResponse.TimestampMs/1000, 0); t.After(now) {
-		// zero tolerance for post-dated timestamps
-		return "", nil, ErrInvalidAttestation.WithDetails("SafetyNet response with timestamp after current time")
-	} else if t.Before(oneMinuteAgo) {
-		// allow old timestamp for testing purposes
-		// TODO: Make this user configurable
-		msg := "SafetyNet response with timestamp before one minute ago"
-		if metadata.Conformance {
-			return "", nil, ErrInvalidAttestation.WithDetails(msg)
-		}
-	}
+	// The sanity of the timestamp in the payload is verified by verifySafetyNetTimestamp according to the policy.
 
 	// §8.5.7 If successful, return implementation-specific values representing attestation type Basic and attestation
 	// trust path attestationCert.
 	return string(metadata.BasicFull), nil, nil
 }
+
+// verifySafetyNetTimestamp verifies the freshness of the timestampMs of the SafetyNet response of a verified
+// attestation statement according to the SafetyNetMaxAge, SafetyNetClockSkew, and Clock of the policy.
+func (attestationObject *AttestationObject) verifySafetyNetTimestamp(policy AttestationPolicy) error {
+	response, ok := attestationObject.AttStatement["response"].([]byte)
+	if !ok {
+		return ErrAttestationFormat.WithDetails("Unable to find the SafetyNet response")
+	}
+
+	claims := jwt.MapClaims{}
+
+	if _, _, err := jwt.NewParser().ParseUnverified(string(response), claims); err != nil {
+		return ErrAttestationFormat.WithDetails(fmt.Sprintf("Error parsing the SafetyNet response: %+v", err))
+	}
+
+	var safetyNetResponse SafetyNetResponse
+
+	if err := mapstructure.Decode(claims, &safetyNetResponse); err != nil {
+		return ErrAttestationFormat.WithDetails(fmt.Sprintf("Error parsing the SafetyNet response: %+v", err))
+	}
+
+	return verifySafetyNetTimestamp(time.UnixMilli(safetyNetResponse.TimestampMs), policy.now(), policy)
+}
+
+// verifySafetyNetTimestamp rejects timestamps in the future and timestamps older than the maximum age, both with the
+// tolerance of the clock skew. The maximum age is only enforced by default when metadata.Conformance is enabled.
+func verifySafetyNetTimestamp(timestamp, now time.Time, policy AttestationPolicy) error {
+	if timestamp.After(now.Add(policy.SafetyNetClockSkew)) {
+		return ErrInvalidAttestation.
+			WithCode(CodeAttestationStale).
+			WithDetails("SafetyNet response with timestamp after current time").
+			WithInfo(fmt.Sprintf("Timestamp: %s, Now: %s", timestamp.UTC().Format(time.RFC3339), now.UTC().Format(time.RFC3339)))
+	}
+
+	maxAge := policy.SafetyNetMaxAge
+
+	if maxAge == 0 {
+		if !metadata.Conformance {
+			return nil
+		}
+
+		maxAge = defaultSafetyNetMaxAge
+	}
+
+	if timestamp.Before(now.Add(-maxAge - policy.SafetyNetClockSkew)) {
+		return ErrInvalidAttestation.
+			WithCode(CodeAttestationStale).
+			WithDetails(fmt.Sprintf("SafetyNet response with timestamp older than %s", maxAge)).
+			WithInfo(fmt.Sprintf("Timestamp: %s, Now: %s", timestamp.UTC().Format(time.RFC3339), now.UTC().Format(time.RFC3339)))
+	}
+
+	return nil
+}
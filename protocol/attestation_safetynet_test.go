@@ -2,8 +2,13 @@ package protocol
 
 import (
 	"crypto/sha256"
+	"errors"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/go-webauthn/webauthn/metadata"
 )
@@ -53,6 +58,66 @@ func Test_verifySafetyNetFormat(t *testing.T) {
 	}
 }
 
+func Test_verifySafetyNetTimestamp(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name        string
+		timestamp   time.Time
+		policy      AttestationPolicy
+		conformance bool
+		expected    bool
+	}{
+		{"ShouldAllowCurrent", now, AttestationPolicy{}, false, true},
+		{"ShouldAllowOldByDefault", now.Add(-time.Hour), AttestationPolicy{}, false, true},
+		{"ShouldRejectOldInConformance", now.Add(-time.Hour), AttestationPolicy{}, true, false},
+		{"ShouldRejectFuture", now.Add(time.Second), AttestationPolicy{}, false, false},
+		{"ShouldAllowFutureWithinSkew", now.Add(time.Second), AttestationPolicy{SafetyNetClockSkew: time.Minute}, false, true},
+		{"ShouldAllowWithinMaxAge", now.Add(-time.Minute), AttestationPolicy{SafetyNetMaxAge: time.Minute * 5}, false, true},
+		{"ShouldRejectOlderThanMaxAge", now.Add(-time.Minute * 6), AttestationPolicy{SafetyNetMaxAge: time.Minute * 5}, false, false},
+		{"ShouldAllowOlderThanMaxAgeWithinSkew", now.Add(-time.Minute * 6), AttestationPolicy{SafetyNetMaxAge: time.Minute * 5, SafetyNetClockSkew: time.Minute * 2}, false, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conformance := metadata.Conformance
+			metadata.Conformance = tc.conformance
+
+			defer func() { metadata.Conformance = conformance }()
+
+			err := verifySafetyNetTimestamp(tc.timestamp, now, tc.policy)
+
+			if tc.expected {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			var e *Error
+
+			require.True(t, errors.As(err, &e))
+			assert.Equal(t, CodeAttestationStale, e.Code)
+		})
+	}
+}
+
+func TestAttestationObject_verifySafetyNetTimestamp(t *testing.T) {
+	att := attestationTestUnpackResponse(t, safetyNetTestResponse["success"]).Response.AttestationObject
+
+	// The timestampMs of the response.
+	timestamp := time.UnixMilli(1553028043529)
+
+	clock := func(d time.Duration) func() time.Time {
+		return func() time.Time { return timestamp.Add(d) }
+	}
+
+	assert.NoError(t, att.verifySafetyNetTimestamp(AttestationPolicy{}))
+	assert.Error(t, att.verifySafetyNetTimestamp(AttestationPolicy{SafetyNetMaxAge: time.Hour}))
+	assert.NoError(t, att.verifySafetyNetTimestamp(AttestationPolicy{SafetyNetMaxAge: time.Minute, Clock: clock(time.Second * 30)}))
+	assert.Error(t, att.verifySafetyNetTimestamp(AttestationPolicy{SafetyNetMaxAge: time.Minute, Clock: clock(time.Minute * 2)}))
+	assert.Error(t, att.verifySafetyNetTimestamp(AttestationPolicy{Clock: clock(-time.Minute)}))
+}
+
 var safetyNetTestRequest = map[string]string{
 	`success`: `{
 		"publicKey": {
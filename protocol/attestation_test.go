@@ -1,10 +1,24 @@
 package protocol
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"math/big"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/go-webauthn/webauthn/metadata"
 )
@@ -44,7 +58,7 @@ func TestAttestationVerify(t *testing.T) {
 	}
 }
 
-func attestationTestUnpackRequest(t *testing.T, request string) CredentialCreation {
+func attestationTestUnpackRequest(t testing.TB, request string) CredentialCreation {
 	options := CredentialCreation{}
 
 	if err := json.Unmarshal([]byte(request), &options); err != nil {
@@ -54,7 +68,7 @@ func attestationTestUnpackRequest(t *testing.T, request string) CredentialCreati
 	return options
 }
 
-func attestationTestUnpackResponse(t *testing.T, response string) (pcc ParsedCredentialCreationData) {
+func attestationTestUnpackResponse(t testing.TB, response string) (pcc ParsedCredentialCreationData) {
 	ccr := CredentialCreationResponse{}
 	if err := json.Unmarshal([]byte(response), &ccr); err != nil {
 		t.Fatal(err)
@@ -88,6 +102,37 @@ func TestPackedAttestationVerification(t *testing.T) {
 	})
 }
 
+func BenchmarkParsedCredentialCreationData_Verify(b *testing.B) {
+	testCases := []struct {
+		name     string
+		request  string
+		response string
+	}{
+		{"PackedSelf", testAttestationOptions[0], testAttestationResponses[0]},
+		{"PackedDirect", testAttestationOptions[1], testAttestationResponses[1]},
+	}
+
+	for _, tc := range testCases {
+		b.Run(tc.name, func(b *testing.B) {
+			options := attestationTestUnpackRequest(b, tc.request)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				pcc, err := ParseCredentialCreationResponseBody(strings.NewReader(tc.response))
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				if err = pcc.Verify(options.Response.Challenge.String(), false, options.Response.RelyingParty.ID, []string{options.Response.RelyingParty.Name}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
 var testAttestationOptions = []string{
 	// Direct Self Attestation with EC256 - MacOS.
 	`{"publicKey": {
@@ -230,3 +275,248 @@ var testAttestationResponses = []string{
 		}
 	}`,
 }
+
+func TestVerifyMetadataAttestationPolicy(t *testing.T) {
+	aaguid := uuid.MustParse("0b5c2f1e-3a4d-4e6f-8a9b-7c6d5e4f3a2b")
+	unknown := uuid.MustParse("9f8e7d6c-5b4a-4c3d-8e2f-1a0b9c8d7e6f")
+
+	root, rootKey := testCertificate(t, "Attestation Root", nil, nil, true)
+	intermediate, intermediateKey := testCertificate(t, "Attestation Intermediate", root, rootKey, true)
+	leaf, _ := testCertificate(t, "Attestation Leaf", root, rootKey, false)
+	chained, _ := testCertificate(t, "Attestation Chained Leaf", intermediate, intermediateKey, false)
+	other, _ := testCertificate(t, "Other Root", nil, nil, true)
+
+	defer delete(metadata.Metadata, aaguid)
+
+	metadata.AddEntry(metadata.MetadataBLOBPayloadEntry{
+		AaGUID: aaguid.String(),
+		MetadataStatement: metadata.MetadataStatement{
+			AttestationTypes:            []metadata.AuthenticatorAttestationType{metadata.BasicFull},
+			AttestationRootCertificates: []string{base64.StdEncoding.EncodeToString(root.Raw)},
+		},
+	})
+
+	testCases := []struct {
+		name   string
+		aaguid uuid.UUID
+		x5c    []interface{}
+		policy AttestationPolicy
+		code   ErrorCode
+	}{
+		{"ShouldAllowUnknownAuthenticator", unknown, []interface{}{leaf.Raw}, AttestationPolicy{}, ""},
+		{"ShouldRejectUnknownAuthenticatorWhenMetadataRequired", unknown, []interface{}{leaf.Raw}, AttestationPolicy{RequireMetadata: true}, CodeAuthenticatorUnknown},
+		{"ShouldAllowUntrustedCertificateWithoutTrustPath", aaguid, []interface{}{other.Raw}, AttestationPolicy{}, ""},
+		{"ShouldVerifyTrustPath", aaguid, []interface{}{leaf.Raw}, AttestationPolicy{VerifyTrustPath: true}, ""},
+		{"ShouldVerifyTrustPathWithIntermediate", aaguid, []interface{}{chained.Raw, intermediate.Raw}, AttestationPolicy{VerifyTrustPath: true}, ""},
+		{"ShouldRejectTrustPathWithoutIntermediate", aaguid, []interface{}{chained.Raw}, AttestationPolicy{VerifyTrustPath: true}, CodeAttestationInvalid},
+		{"ShouldRejectUntrustedTrustPath", aaguid, []interface{}{other.Raw}, AttestationPolicy{VerifyTrustPath: true}, CodeAttestationInvalid},
+		{"ShouldRejectInvalidIntermediate", aaguid, []interface{}{chained.Raw, "invalid"}, AttestationPolicy{VerifyTrustPath: true}, CodeAttestationInvalid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			attestationObject := AttestationObject{
+				AuthData: AuthenticatorData{AttData: AttestedCredentialData{AAGUID: tc.aaguid[:]}},
+			}
+
+			_, err := attestationObject.verifyMetadata(string(metadata.BasicFull), tc.x5c, tc.policy)
+
+			if tc.code == "" {
+				assert.NoError(t, err)
+			} else {
+				var e *Error
+
+				require.ErrorAs(t, err, &e)
+				assert.Equal(t, tc.code, e.Code)
+			}
+		})
+	}
+}
+
+func TestVerifyMetadataCompleteChain(t *testing.T) {
+	aaguid := uuid.MustParse("5e8a1c3d-2b4f-4a6e-9c7d-8f0b1a2c3d4e")
+
+	root, rootKey := testCertificate(t, "Attestation Root", nil, nil, true)
+	intermediate, intermediateKey := testCertificate(t, "Attestation Intermediate", root, rootKey, true)
+	chained, _ := testCertificate(t, "Attestation Chained Leaf", intermediate, intermediateKey, false)
+	other, _ := testCertificate(t, "Other Root", nil, nil, true)
+
+	defer delete(metadata.Metadata, aaguid)
+
+	metadata.AddEntry(metadata.MetadataBLOBPayloadEntry{
+		AaGUID: aaguid.String(),
+		MetadataStatement: metadata.MetadataStatement{
+			AttestationTypes:            []metadata.AuthenticatorAttestationType{metadata.BasicFull},
+			AttestationRootCertificates: []string{base64.StdEncoding.EncodeToString(root.Raw)},
+		},
+	})
+
+	intermediates := []*x509.Certificate{intermediate}
+
+	testCases := []struct {
+		name   string
+		format string
+		x5c    []interface{}
+		policy AttestationPolicy
+		code   ErrorCode
+	}{
+		{"ShouldBuildChainWithIntermediates", packedAttestationKey, []interface{}{chained.Raw}, AttestationPolicy{VerifyTrustPath: true, Intermediates: intermediates}, ""},
+		{"ShouldRejectLeafOnlyWithoutIntermediates", packedAttestationKey, []interface{}{chained.Raw}, AttestationPolicy{VerifyTrustPath: true}, CodeAttestationInvalid},
+		{"ShouldAcceptCompleteChain", packedAttestationKey, []interface{}{chained.Raw, intermediate.Raw}, AttestationPolicy{RequireCompleteChain: true, Intermediates: intermediates}, ""},
+		{"ShouldRejectIncompleteChain", packedAttestationKey, []interface{}{chained.Raw}, AttestationPolicy{RequireCompleteChain: true, Intermediates: intermediates}, CodeAttestationChainIncomplete},
+		{"ShouldRejectIncompleteTPMChain", tpmAttestationKey, []interface{}{chained.Raw}, AttestationPolicy{RequireCompleteChain: true, Intermediates: intermediates}, CodeAttestationChainIncomplete},
+		{"ShouldRejectUntrustedCertificate", packedAttestationKey, []interface{}{other.Raw}, AttestationPolicy{RequireCompleteChain: true, Intermediates: intermediates}, CodeAttestationInvalid},
+		{"ShouldNotRequireCompleteChainForOtherFormats", u2fAttestationKey, []interface{}{chained.Raw}, AttestationPolicy{VerifyTrustPath: true, RequireCompleteChain: true, Intermediates: intermediates}, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			attestationObject := AttestationObject{
+				Format:   tc.format,
+				AuthData: AuthenticatorData{AttData: AttestedCredentialData{AAGUID: aaguid[:]}},
+			}
+
+			_, err := attestationObject.verifyMetadata(string(metadata.BasicFull), tc.x5c, tc.policy)
+
+			if tc.code == "" {
+				assert.NoError(t, err)
+			} else {
+				var e *Error
+
+				require.ErrorAs(t, err, &e)
+				assert.Equal(t, tc.code, e.Code)
+			}
+		})
+	}
+}
+
+func TestVerifyMetadataAnonymizedAttestation(t *testing.T) {
+	anonymized := uuid.MustParse("3c1a7e5d-9b2f-4d8e-a6c4-5f0e1b2d3a4c")
+	direct := uuid.MustParse("7d2e4f6a-8b1c-4e3d-9f5a-0c6b2d4e8f1a")
+
+	root, rootKey := testCertificate(t, "Anonymization Root", nil, nil, true)
+	leaf, _ := testCertificate(t, "Anonymized Credential", root, rootKey, false)
+	other, _ := testCertificate(t, "Other Root", nil, nil, true)
+
+	defer delete(metadata.Metadata, anonymized)
+	defer delete(metadata.Metadata, direct)
+
+	metadata.AddEntry(metadata.MetadataBLOBPayloadEntry{
+		AaGUID: anonymized.String(),
+		MetadataStatement: metadata.MetadataStatement{
+			AttestationTypes:            []metadata.AuthenticatorAttestationType{metadata.AnonCA},
+			AttestationRootCertificates: []string{base64.StdEncoding.EncodeToString(root.Raw)},
+		},
+	})
+
+	metadata.AddEntry(metadata.MetadataBLOBPayloadEntry{
+		AaGUID: direct.String(),
+		MetadataStatement: metadata.MetadataStatement{
+			AttestationTypes:            []metadata.AuthenticatorAttestationType{metadata.BasicFull, metadata.AnonCA},
+			AttestationRootCertificates: []string{base64.StdEncoding.EncodeToString(root.Raw)},
+		},
+	})
+
+	testCases := []struct {
+		name            string
+		aaguid          uuid.UUID
+		attestationType metadata.AuthenticatorAttestationType
+		x5c             []interface{}
+		expected        metadata.AuthenticatorAttestationType
+		code            ErrorCode
+	}{
+		{"ShouldClassifyAnonCA", anonymized, metadata.BasicFull, []interface{}{leaf.Raw}, metadata.AnonCA, ""},
+		{"ShouldVerifyAnonCATrustPath", anonymized, metadata.BasicFull, []interface{}{other.Raw}, metadata.AnonCA, CodeAttestationInvalid},
+		{"ShouldNotClassifyDirectAttestation", direct, metadata.BasicFull, []interface{}{other.Raw}, metadata.BasicFull, ""},
+		{"ShouldKeepAttCA", anonymized, metadata.AttCA, []interface{}{other.Raw}, metadata.AttCA, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			attestationObject := AttestationObject{
+				AuthData: AuthenticatorData{AttData: AttestedCredentialData{AAGUID: tc.aaguid[:]}},
+			}
+
+			attestationType, err := attestationObject.verifyMetadata(string(tc.attestationType), tc.x5c, AttestationPolicy{})
+
+			assert.Equal(t, string(tc.expected), attestationType)
+
+			if tc.code == "" {
+				assert.NoError(t, err)
+			} else {
+				var e *Error
+
+				require.ErrorAs(t, err, &e)
+				assert.Equal(t, tc.code, e.Code)
+			}
+		})
+	}
+}
+
+func TestVerifyAttestationRejectAnonymizedAttestation(t *testing.T) {
+	response := attestationTestUnpackResponse(t, appleTestResponse["success"])
+	clientDataHash := sha256.Sum256(response.Raw.AttestationResponse.ClientDataJSON)
+
+	testCases := []struct {
+		name   string
+		policy AttestationPolicy
+		code   ErrorCode
+	}{
+		{"ShouldAcceptAnonymizedAttestationByDefault", AttestationPolicy{}, ""},
+		{"ShouldRejectAnonymizedAttestation", AttestationPolicy{RejectAnonymizedAttestation: true}, CodeAttestationAnonymized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			trace := &VerificationTrace{}
+
+			err := response.Response.AttestationObject.verifyAttestation(trace, clientDataHash[:], tc.policy)
+
+			if tc.code == "" {
+				assert.NoError(t, err)
+			} else {
+				var e *Error
+
+				require.ErrorAs(t, err, &e)
+				assert.Equal(t, tc.code, e.Code)
+			}
+
+			require.NotEmpty(t, trace.Steps)
+
+			step := trace.Steps[len(trace.Steps)-1]
+
+			assert.Equal(t, VerificationStepMetadata, step.Name)
+			assert.Equal(t, string(metadata.AnonCA), step.Inputs["attestation_type"])
+		})
+	}
+}
+
+func testCertificate(t *testing.T, name string, parent *x509.Certificate, parentKey crypto.Signer, ca bool) (*x509.Certificate, crypto.Signer) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  ca,
+	}
+
+	if ca {
+		template.KeyUsage = x509.KeyUsageCertSign
+	}
+
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	raw, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(raw)
+	require.NoError(t, err)
+
+	return cert, key
+}
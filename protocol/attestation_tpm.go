@@ -2,12 +2,19 @@ package protocol
 
 import (
 	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/subtle"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/asn1"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"hash"
 	"strings"
+	"time"
 
 	"github.com/google/go-tpm/legacy/tpm2"
 
@@ -22,9 +29,34 @@ func init() {
 }
 
 func verifyTPMFormat(att AttestationObject, clientDataHash []byte) (string, []interface{}, error) {
-	// Given the verification procedure inputs attStmt, authenticatorData
-	// and clientDataHash, the verification procedure is as follows
+	return verifyTPMStatement(att, clientDataHash, tpmQuirks{})
+}
+
+// tpmQuirks are the deviations from the tpm attestation statement format tolerated by the verification, which are
+// enabled by the AttestationPolicy.
+type tpmQuirks struct {
+	// windowsHello tolerates the quirks of Windows Hello, see AttestationPolicy.WindowsHelloCompatibility.
+	windowsHello bool
+
+	// rs1 accepts the RS1 algorithm, see AttestationPolicy.AllowTPMRS1.
+	rs1 bool
+}
+
+// tpmQuirks returns the tpmQuirks enabled by the policy.
+func (policy AttestationPolicy) tpmQuirks() (quirks tpmQuirks, enabled bool) {
+	quirks = tpmQuirks{windowsHello: policy.WindowsHelloCompatibility, rs1: policy.AllowTPMRS1}
+
+	return quirks, quirks != tpmQuirks{}
+}
+
+// handler returns the verification procedure of the tpm attestation statement format with the quirks tolerated.
+func (quirks tpmQuirks) handler() attestationFormatValidationHandler {
+	return func(att AttestationObject, clientDataHash []byte) (string, []interface{}, error) {
+		return verifyTPMStatement(att, clientDataHash, quirks)
+	}
+}
 
+func verifyTPMStatement(att AttestationObject, clientDataHash []byte, quirks tpmQuirks) (string, []interface{}, error) {
 	// Verify that attStmt is valid CBOR conforming to the syntax defined
 	// above and perform CBOR decoding on it to extract the contained fields
 
@@ -79,7 +111,7 @@ func verifyTPMFormat(att AttestationObject, clientDataHash []byte) (string, []in
 
 	key, err := webauthncose.ParsePublicKey(att.AuthData.AttData.CredentialPublicKey)
 	if err != nil {
-		return "", nil, err
+		return "", nil, ErrUnsupportedKey.WithCode(CodePublicKeyInvalid).WithDetails(err.Error())
 	}
 
 	switch k := key.(type) {
@@ -90,18 +122,19 @@ func verifyTPMFormat(att AttestationObject, clientDataHash []byte) (string, []in
 			return "", nil, ErrAttestationFormat.WithDetails("Mismatch between ECCParameters in pubArea and credentialPublicKey")
 		}
 	case webauthncose.RSAPublicKeyData:
+		if len(k.Exponent) != 3 {
+			return "", nil, ErrAttestationFormat.WithDetails("Mismatch between RSAParameters in pubArea and credentialPublicKey")
+		}
+
 		exp := uint32(k.Exponent[0]) + uint32(k.Exponent[1])<<8 + uint32(k.Exponent[2])<<16
 		if !bytes.Equal(pubArea.RSAParameters.ModulusRaw, k.Modulus) ||
 			pubArea.RSAParameters.Exponent() != exp {
 			return "", nil, ErrAttestationFormat.WithDetails("Mismatch between RSAParameters in pubArea and credentialPublicKey")
 		}
 	default:
-		return "", nil, ErrUnsupportedKey
+		return "", nil, ErrUnsupportedKey.WithCode(CodePublicKeyInvalid)
 	}
 
-	// Concatenate authenticatorData and clientDataHash to form attToBeSigned
-	attToBeSigned := append(att.RawAuthData, clientDataHash...)
-
 	// Validate that certInfo is valid:
 	// 1/4 Verify that magic is set to TPM_GENERATED_VALUE, handled here
 	certInfo, err := tpm2.DecodeAttestationData(certInfoBytes)
@@ -114,12 +147,19 @@ func verifyTPMFormat(att AttestationObject, clientDataHash []byte) (string, []in
 		return "", nil, ErrAttestationFormat.WithDetails("Type is not set to TPM_ST_ATTEST_CERTIFY")
 	}
 
-	// 3/4 Verify that extraData is set to the hash of attToBeSigned using the hash algorithm employed in "alg".
+	// 3/4 Verify that extraData is set to the hash of attToBeSigned using the hash algorithm employed in "alg". The
+	// attToBeSigned is the concatenation of authenticatorData and clientDataHash which is written to the hash directly.
 	f := webauthncose.HasherFromCOSEAlg(coseAlg)
+
+	if quirks.windowsHello {
+		f = windowsHelloExtraDataHasher(f, certInfo.ExtraData)
+	}
+
 	h := f()
 
-	h.Write(attToBeSigned)
-	if !bytes.Equal(certInfo.ExtraData, h.Sum(nil)) {
+	h.Write(att.RawAuthData)
+	h.Write(clientDataHash)
+	if subtle.ConstantTimeCompare(certInfo.ExtraData, h.Sum(nil)) != 1 {
 		return "", nil, ErrAttestationFormat.WithDetails("ExtraData is not set to hash of attToBeSigned")
 	}
 
@@ -154,10 +194,19 @@ func verifyTPMFormat(att AttestationObject, clientDataHash []byte) (string, []in
 			return "", nil, ErrAttestationFormat.WithDetails("Error parsing certificate from ASN.1")
 		}
 
-		sigAlg := webauthncose.SigAlgFromCOSEAlg(coseAlg)
+		sigAlg := x509.SignatureAlgorithm(webauthncose.SigAlgFromCOSEAlg(coseAlg))
 
-		err = aikCert.CheckSignature(x509.SignatureAlgorithm(sigAlg), certInfoBytes, sigBytes)
-		if err != nil {
+		if quirks.windowsHello {
+			sigAlg = windowsHelloSignatureAlgorithm(sigAlg, webauthncose.HasherFromCOSEAlg(coseAlg)().Size(), aikCert.PublicKeyAlgorithm)
+		}
+
+		// The crypto/x509 package verifies SHA-1 signatures of data, so the RS1 algorithm has to be rejected here unless
+		// it's explicitly allowed by the policy.
+		if (coseAlg == webauthncose.AlgRS1 || sigAlg == x509.SHA1WithRSA) && !quirks.rs1 {
+			return "", nil, ErrAttestationFormat.WithDetails("Signature validation error: the RS1 algorithm is not allowed")
+		}
+
+		if err = aikCert.CheckSignature(sigAlg, certInfoBytes, sigBytes); err != nil {
 			return "", nil, ErrAttestationFormat.WithDetails(fmt.Sprintf("Signature validation error: %+v\n", err))
 		}
 		// Verify that aikCert meets the requirements in §8.3.1 TPM Attestation Statement Certificate Requirements
@@ -372,3 +421,181 @@ func isValidTPMManufacturer(id string) bool {
 
 	return false
 }
+
+// windowsHelloExtraDataHasher returns the hash function the extraData of the certInfo was computed with. Some Windows
+// Hello TPMs compute it with a hash function other than the one of the alg of the attestation statement, so the
+// function is selected by the length of the extraData when it does not match the length of the hash of the alg.
+func windowsHelloExtraDataHasher(f func() hash.Hash, extraData []byte) func() hash.Hash {
+	if len(extraData) == f().Size() {
+		return f
+	}
+
+	if h, ok := tpmHashes[len(extraData)]; ok {
+		return h.New
+	}
+
+	return f
+}
+
+// windowsHelloSignatureAlgorithm returns the algorithm of the signature over the certInfo. Some Windows Hello TPMs
+// report the algorithm of the credential public key as the alg of the attestation statement instead of the algorithm
+// of the AIK certificate, so when the algorithm can not be used with the public key of the AIK certificate the
+// algorithm with the same hash function for the type of the public key is used instead.
+func windowsHelloSignatureAlgorithm(sigAlg x509.SignatureAlgorithm, size int, keyAlg x509.PublicKeyAlgorithm) x509.SignatureAlgorithm {
+	if signatureKeyAlgorithm(sigAlg) == keyAlg {
+		return sigAlg
+	}
+
+	h, ok := tpmHashes[size]
+	if !ok {
+		return sigAlg
+	}
+
+	if alg, ok := tpmSignatureAlgorithms[keyAlg][h]; ok {
+		return alg
+	}
+
+	return sigAlg
+}
+
+// signatureKeyAlgorithm returns the type of the public key the signature algorithm is used with.
+func signatureKeyAlgorithm(sigAlg x509.SignatureAlgorithm) x509.PublicKeyAlgorithm {
+	switch sigAlg {
+	case x509.SHA1WithRSA, x509.SHA256WithRSA, x509.SHA384WithRSA, x509.SHA512WithRSA,
+		x509.SHA256WithRSAPSS, x509.SHA384WithRSAPSS, x509.SHA512WithRSAPSS:
+		return x509.RSA
+	case x509.ECDSAWithSHA1, x509.ECDSAWithSHA256, x509.ECDSAWithSHA384, x509.ECDSAWithSHA512:
+		return x509.ECDSA
+	case x509.PureEd25519:
+		return x509.Ed25519
+	default:
+		return x509.UnknownPublicKeyAlgorithm
+	}
+}
+
+// tpmHashes are the hash functions used by TPMs keyed by the length of their output.
+var tpmHashes = map[int]crypto.Hash{
+	crypto.SHA1.Size():   crypto.SHA1,
+	crypto.SHA256.Size(): crypto.SHA256,
+	crypto.SHA384.Size(): crypto.SHA384,
+	crypto.SHA512.Size(): crypto.SHA512,
+}
+
+var tpmSignatureAlgorithms = map[x509.PublicKeyAlgorithm]map[crypto.Hash]x509.SignatureAlgorithm{
+	x509.RSA: {
+		crypto.SHA1:   x509.SHA1WithRSA,
+		crypto.SHA256: x509.SHA256WithRSA,
+		crypto.SHA384: x509.SHA384WithRSA,
+		crypto.SHA512: x509.SHA512WithRSA,
+	},
+	x509.ECDSA: {
+		crypto.SHA1:   x509.ECDSAWithSHA1,
+		crypto.SHA256: x509.ECDSAWithSHA256,
+		crypto.SHA384: x509.ECDSAWithSHA384,
+		crypto.SHA512: x509.ECDSAWithSHA512,
+	},
+}
+
+// checkRS1Signature verifies the RSASSA-PKCS1-v1_5 signature with SHA-1 of the signed data by the public key of the
+// certificate. It's only used for the trust path when the AttestationPolicy.AllowTPMRS1 is enabled, as the crypto/x509
+// package rejects certificates signed with SHA-1 when it verifies a certificate against its parent.
+func checkRS1Signature(cert *x509.Certificate, signed, signature []byte) error {
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return x509.ErrUnsupportedAlgorithm
+	}
+
+	digest := sha1.Sum(signed)
+
+	return rsa.VerifyPKCS1v15(key, crypto.SHA1, digest[:], signature)
+}
+
+// checkRS1SignatureFrom is the same as (*x509.Certificate).CheckSignatureFrom except certificates signed with the
+// SHA1WithRSA algorithm are accepted.
+func checkRS1SignatureFrom(cert, parent *x509.Certificate) error {
+	if cert.SignatureAlgorithm != x509.SHA1WithRSA {
+		return cert.CheckSignatureFrom(parent)
+	}
+
+	if !parent.BasicConstraintsValid || !parent.IsCA {
+		return x509.ConstraintViolationError{}
+	}
+
+	if parent.KeyUsage != 0 && parent.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return x509.ConstraintViolationError{}
+	}
+
+	return checkRS1Signature(parent, cert.RawTBSCertificate, cert.Signature)
+}
+
+// verifyRS1TrustPath is the same as verifyTrustPath except certificates of the chain signed with the SHA1WithRSA
+// algorithm are accepted. The chain is built by following the issuers of the certificates from the attestation
+// certificate to one of the attestation root certificates of the metadata statement.
+func verifyRS1TrustPath(meta metadata.MetadataBLOBPayloadEntry, x5cAtt *x509.Certificate, intermediates []interface{}, now time.Time) error {
+	var roots, certs []*x509.Certificate
+
+	for _, encoded := range meta.MetadataStatement.AttestationRootCertificates {
+		raw, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return ErrInvalidAttestation.WithCode(CodeAttestationInvalid).WithDetails("Unable to parse the attestation root certificates from the metadata statement")
+		}
+
+		root, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return ErrInvalidAttestation.WithCode(CodeAttestationInvalid).WithDetails("Unable to parse the attestation root certificates from the metadata statement")
+		}
+
+		roots = append(roots, root)
+	}
+
+	for _, c := range intermediates {
+		raw, ok := c.([]byte)
+		if !ok {
+			return ErrInvalidAttestation.WithCode(CodeAttestationInvalid).WithDetails("Unable to parse intermediate certificate from x5c")
+		}
+
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return ErrInvalidAttestation.WithCode(CodeAttestationInvalid).WithDetails("Unable to parse intermediate certificate from x5c")
+		}
+
+		certs = append(certs, cert)
+	}
+
+	fail := func(info string) error {
+		return ErrInvalidAttestation.WithCode(CodeAttestationInvalid).WithDetails("Attestation certificate does not chain to an attestation root certificate of the metadata statement").WithInfo(info)
+	}
+
+	cert := x5cAtt
+
+	// Each intermediate is used at most once, so the chain is at most as long as the x5c.
+	for i := 0; i <= len(certs); i++ {
+		if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+			return fail(fmt.Sprintf("Certificate '%s' is expired or not yet valid", cert.Subject))
+		}
+
+		for _, root := range roots {
+			if bytes.Equal(cert.RawIssuer, root.RawSubject) && checkRS1SignatureFrom(cert, root) == nil {
+				return nil
+			}
+		}
+
+		var parent *x509.Certificate
+
+		for _, candidate := range certs {
+			if bytes.Equal(cert.RawIssuer, candidate.RawSubject) && checkRS1SignatureFrom(cert, candidate) == nil {
+				parent = candidate
+
+				break
+			}
+		}
+
+		if parent == nil {
+			break
+		}
+
+		cert = parent
+	}
+
+	return fail(fmt.Sprintf("No issuer of certificate '%s' was found", cert.Subject))
+}
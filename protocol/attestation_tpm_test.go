@@ -1,6 +1,7 @@
 package protocol
 
 import (
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/elliptic"
@@ -10,13 +11,18 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/asn1"
+	"encoding/base64"
 	"encoding/binary"
+	"fmt"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/google/go-tpm/legacy/tpm2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/go-webauthn/webauthn/metadata"
 	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
 	"github.com/go-webauthn/webauthn/protocol/webauthncose"
 )
@@ -27,7 +33,8 @@ func TestTPMAttestationVerificationSuccess(t *testing.T) {
 			pcc := attestationTestUnpackResponse(t, testAttestationTPMResponses[i])
 			clientDataHash := sha256.Sum256(pcc.Raw.AttestationResponse.ClientDataJSON)
 
-			attestationType, _, err := verifyTPMFormat(pcc.Response.AttestationObject, clientDataHash[:])
+			// Some of the statements are signed with the RS1 algorithm, which is only accepted by the AllowTPMRS1 policy.
+			attestationType, _, err := tpmQuirks{rs1: true}.handler()(pcc.Response.AttestationObject, clientDataHash[:])
 			if err != nil {
 				t.Fatalf("Not valid: %+v", err)
 			}
@@ -36,6 +43,19 @@ func TestTPMAttestationVerificationSuccess(t *testing.T) {
 	}
 }
 
+func BenchmarkTPMAttestationVerification(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		pcc := attestationTestUnpackResponse(b, testAttestationTPMResponses[i%len(testAttestationTPMResponses)])
+		clientDataHash := sha256.Sum256(pcc.Raw.AttestationResponse.ClientDataJSON)
+
+		if _, _, err := verifyTPMFormat(pcc.Response.AttestationObject, clientDataHash[:]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 var testAttestationTPMResponses = []string{
 	// TPM attestation with ECC P256.
 	`{
@@ -130,6 +150,7 @@ func TestTPMAttestationVerificationFailAttStatement(t *testing.T) {
 		attestationType, _, err := verifyTPMFormat(tt.att, nil)
 		if tt.wantErr != "" {
 			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.IsType(t, &Error{}, err)
 		} else {
 			assert.Equal(t, "attca", attestationType)
 		}
@@ -578,3 +599,250 @@ func TestTPMAttestationVerificationFailX5c(t *testing.T) {
 		}
 	}
 }
+
+// tpmTestAttestation returns a tpm attestation object with an ES256 credential public key and an RSA AIK certificate
+// which signs the certInfo with RSASSA-PKCS1-v1_5 and the signatureHash, along with the client data hash. The
+// statement reports the alg and the extraData of the certInfo is computed with the extraDataHash.
+func tpmTestAttestation(t *testing.T, alg webauthncose.COSEAlgorithmIdentifier, extraDataHash, signatureHash crypto.Hash) (AttestationObject, []byte) {
+	credentialKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	cpk, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{KeyType: int64(webauthncose.EllipticKey), Algorithm: int64(webauthncose.AlgES256)},
+		Curve:         int64(webauthncose.P256),
+		XCoord:        credentialKey.X.FillBytes(make([]byte, 32)),
+		YCoord:        credentialKey.Y.FillBytes(make([]byte, 32)),
+	})
+	require.NoError(t, err)
+
+	public := defaultECCPublic
+	public.ECCParameters = &tpm2.ECCParams{
+		Sign:    defaultECCPublic.ECCParameters.Sign,
+		CurveID: tpm2.CurveNISTP256,
+		Point:   tpm2.ECPoint{XRaw: credentialKey.X.FillBytes(make([]byte, 32)), YRaw: credentialKey.Y.FillBytes(make([]byte, 32))},
+	}
+
+	pubArea, err := public.Encode()
+	require.NoError(t, err)
+
+	name, err := public.Name()
+	require.NoError(t, err)
+
+	authData := make([]byte, 37)
+	clientDataHash := sha256.Sum256([]byte("client data"))
+
+	h := extraDataHash.New()
+	h.Write(authData)
+	h.Write(clientDataHash[:])
+
+	certInfo, err := tpm2.AttestationData{
+		Magic:               0xff544347,
+		Type:                tpm2.TagAttestCertify,
+		AttestedCertifyInfo: &tpm2.CertifyInfo{Name: name},
+		ExtraData:           h.Sum(nil),
+	}.Encode()
+	require.NoError(t, err)
+
+	aikKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	digest := signatureHash.New()
+	digest.Write(certInfo)
+
+	sig, err := rsa.SignPKCS1v15(rand.Reader, aikKey, signatureHash, digest.Sum(nil))
+	require.NoError(t, err)
+
+	attributes, err := asn1.Marshal(pkix.RDNSequence{
+		{{Type: tcgAtTpmManufacturer, Value: "id:FFFFF1D0"}},
+		{{Type: tcgAtTpmModel, Value: "Windows Hello"}},
+		{{Type: tcgAtTpmVersion, Value: "id:1"}},
+	})
+	require.NoError(t, err)
+
+	san, err := asn1.Marshal([]asn1.RawValue{{Class: asn1.ClassContextSpecific, Tag: nameTypeDN, IsCompound: true, Bytes: attributes}})
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		UnknownExtKeyUsage:    []asn1.ObjectIdentifier{tcgKpAIKCertificate},
+		BasicConstraintsValid: true,
+		ExtraExtensions:       []pkix.Extension{{Id: asn1.ObjectIdentifier{2, 5, 29, 17}, Critical: true, Value: san}},
+	}
+
+	aikCert, err := x509.CreateCertificate(rand.Reader, template, template, &aikKey.PublicKey, aikKey)
+	require.NoError(t, err)
+
+	return AttestationObject{
+		RawAuthData: authData,
+		AuthData:    AuthenticatorData{AttData: AttestedCredentialData{CredentialPublicKey: cpk}},
+		Format:      tpmAttestationKey,
+		AttStatement: map[string]interface{}{
+			"ver":      "2.0",
+			"alg":      int64(alg),
+			"x5c":      []interface{}{aikCert},
+			"sig":      sig,
+			"certInfo": certInfo,
+			"pubArea":  pubArea,
+		},
+	}, clientDataHash[:]
+}
+
+func TestTPMAttestationWindowsHelloQuirks(t *testing.T) {
+	testCases := []struct {
+		name          string
+		alg           webauthncose.COSEAlgorithmIdentifier
+		extraDataHash crypto.Hash
+		strict        string
+		windowsHello  string
+	}{
+		{"ShouldPassConformingStatement", webauthncose.AlgRS256, crypto.SHA256, "", ""},
+		{"ShouldPassCredentialAlgorithm", webauthncose.AlgES256, crypto.SHA256, "Signature validation error", ""},
+		{"ShouldPassExtraDataHash", webauthncose.AlgRS256, crypto.SHA384, "ExtraData is not set to hash of attToBeSigned", ""},
+		{"ShouldFailExtraDataHashOfUnknownLength", webauthncose.AlgRS256, crypto.SHA224, "ExtraData is not set to hash of attToBeSigned", "ExtraData is not set to hash of attToBeSigned"},
+		{"ShouldFailSignatureHash", webauthncose.AlgES384, crypto.SHA384, "Signature validation error", "Signature validation error"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			att, clientDataHash := tpmTestAttestation(t, tc.alg, tc.extraDataHash, crypto.SHA256)
+
+			for _, c := range []struct {
+				handler attestationFormatValidationHandler
+				wantErr string
+			}{
+				{verifyTPMFormat, tc.strict},
+				{tpmQuirks{windowsHello: true}.handler(), tc.windowsHello},
+			} {
+				attestationType, _, err := c.handler(att, clientDataHash)
+
+				if c.wantErr == "" {
+					require.NoError(t, err)
+					assert.Equal(t, "attca", attestationType)
+				} else {
+					require.Error(t, err)
+					assert.Contains(t, err.Error(), c.wantErr)
+				}
+			}
+		})
+	}
+
+	t.Run("ShouldRetryWithPolicy", func(t *testing.T) {
+		att, clientDataHash := tpmTestAttestation(t, webauthncose.AlgES256, crypto.SHA256, crypto.SHA256)
+
+		_, _, err := att.verifyStatement(clientDataHash)
+		require.Error(t, err)
+
+		err = att.verifyAttestation(&VerificationTrace{}, clientDataHash, AttestationPolicy{WindowsHelloCompatibility: true})
+		assert.NotContains(t, fmt.Sprint(err), "Signature validation error")
+	})
+}
+
+func TestTPMAttestationRS1(t *testing.T) {
+	att, clientDataHash := tpmTestAttestation(t, webauthncose.AlgRS1, crypto.SHA1, crypto.SHA1)
+
+	_, _, err := verifyTPMFormat(att, clientDataHash)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Signature validation error")
+
+	_, _, err = tpmQuirks{windowsHello: true}.handler()(att, clientDataHash)
+	require.Error(t, err)
+
+	attestationType, _, err := tpmQuirks{rs1: true}.handler()(att, clientDataHash)
+	require.NoError(t, err)
+	assert.Equal(t, "attca", attestationType)
+
+	t.Run("ShouldOnlyAcceptRS1", func(t *testing.T) {
+		att, clientDataHash := tpmTestAttestation(t, webauthncose.AlgRS256, crypto.SHA256, crypto.SHA1)
+
+		_, _, err := tpmQuirks{rs1: true}.handler()(att, clientDataHash)
+		require.Error(t, err)
+	})
+}
+
+func TestVerifyRS1TrustPath(t *testing.T) {
+	newCertificate := func(t *testing.T, template, parent *x509.Certificate, parentKey *rsa.PrivateKey) (*x509.Certificate, *rsa.PrivateKey) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		if parent == nil {
+			parent, parentKey = template, key
+		}
+
+		raw, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+		require.NoError(t, err)
+
+		cert, err := x509.ParseCertificate(raw)
+		require.NoError(t, err)
+
+		return cert, key
+	}
+
+	now := time.Now()
+
+	ca := func(name string, alg x509.SignatureAlgorithm) *x509.Certificate {
+		return &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: name},
+			NotBefore:             now.Add(-time.Hour),
+			NotAfter:              now.Add(time.Hour),
+			KeyUsage:              x509.KeyUsageCertSign,
+			BasicConstraintsValid: true,
+			IsCA:                  true,
+			SignatureAlgorithm:    alg,
+		}
+	}
+
+	root, rootKey := newCertificate(t, ca("Root", x509.SHA256WithRSA), nil, nil)
+	other, _ := newCertificate(t, ca("Other", x509.SHA256WithRSA), nil, nil)
+	intermediate, intermediateKey := newCertificate(t, ca("Intermediate", x509.SHA1WithRSA), root, rootKey)
+
+	leaf, _ := newCertificate(t, &x509.Certificate{
+		SerialNumber:       big.NewInt(2),
+		NotBefore:          now.Add(-time.Hour),
+		NotAfter:           now.Add(time.Hour),
+		SignatureAlgorithm: x509.SHA1WithRSA,
+	}, intermediate, intermediateKey)
+
+	entry := func(roots ...*x509.Certificate) (meta metadata.MetadataBLOBPayloadEntry) {
+		for _, r := range roots {
+			meta.MetadataStatement.AttestationRootCertificates = append(meta.MetadataStatement.AttestationRootCertificates, base64.StdEncoding.EncodeToString(r.Raw))
+		}
+
+		return meta
+	}
+
+	testCases := []struct {
+		name          string
+		meta          metadata.MetadataBLOBPayloadEntry
+		intermediates []interface{}
+		now           time.Time
+		err           string
+	}{
+		{"ShouldPassSHA1Chain", entry(other, root), []interface{}{intermediate.Raw}, now, ""},
+		{"ShouldFailUnknownRoot", entry(other), []interface{}{intermediate.Raw}, now, "No issuer of certificate 'CN=Intermediate' was found"},
+		{"ShouldFailMissingIntermediate", entry(root), nil, now, "No issuer of certificate '' was found"},
+		{"ShouldFailExpired", entry(root), []interface{}{intermediate.Raw}, now.Add(time.Hour * 2), "is expired or not yet valid"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.err == "" {
+				require.Error(t, verifyTrustPath(tc.meta, leaf, tc.intermediates))
+			}
+
+			err := verifyRS1TrustPath(tc.meta, leaf, tc.intermediates, tc.now)
+
+			if tc.err == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			require.Error(t, err)
+			assert.Equal(t, CodeAttestationInvalid, err.(*Error).Code)
+			assert.Contains(t, err.(*Error).DevInfo, tc.err)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/x509"
+	"fmt"
 
 	"github.com/go-webauthn/webauthn/metadata"
 	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
@@ -26,12 +27,19 @@ func verifyU2FFormat(att AttestationObject, clientDataHash []byte) (string, []in
 	// Signing procedure step - If the credential public key of the given credential is not of
 	// algorithm -7 ("ES256"), stop and return an error.
 	key := webauthncose.EC2PublicKeyData{}
-	webauthncbor.Unmarshal(att.AuthData.AttData.CredentialPublicKey, &key)
+
+	if err := webauthncbor.Unmarshal(att.AuthData.AttData.CredentialPublicKey, &key.PublicKeyData); err != nil {
+		return "", nil, ErrAttestationFormat.WithDetails(fmt.Sprintf("Error parsing the public key: %+v", err))
+	}
 
 	if webauthncose.COSEAlgorithmIdentifier(key.PublicKeyData.Algorithm) != webauthncose.AlgES256 {
 		return "", nil, ErrUnsupportedAlgorithm.WithDetails("Non-ES256 Public Key algorithm used")
 	}
 
+	if err := webauthncbor.Unmarshal(att.AuthData.AttData.CredentialPublicKey, &key); err != nil {
+		return "", nil, ErrAttestationFormat.WithDetails(fmt.Sprintf("Error parsing the public key: %+v", err))
+	}
+
 	// U2F Step 1. Verify that attStmt is valid CBOR conforming to the syntax defined above
 	// and perform CBOR decoding on it to extract the contained fields.
 
@@ -82,7 +90,7 @@ func verifyU2FFormat(att AttestationObject, clientDataHash []byte) (string, []in
 	}
 
 	// Step 2.3
-	if attCert.PublicKeyAlgorithm != x509.ECDSA && attCert.PublicKey.(*ecdsa.PublicKey).Curve != elliptic.P256() {
+	if attPublicKey, ok := attCert.PublicKey.(*ecdsa.PublicKey); !ok || attPublicKey.Curve != elliptic.P256() {
 		return "", nil, ErrAttestationFormat.WithDetails("Attestation certificate is in invalid format")
 	}
 
@@ -2,8 +2,12 @@ package protocol
 
 import (
 	"crypto/sha256"
+	"errors"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/go-webauthn/webauthn/metadata"
 )
 
@@ -52,6 +56,17 @@ func TestVerifyU2FFormat(t *testing.T) {
 	}
 }
 
+func TestVerifyU2FFormatInvalidSignature(t *testing.T) {
+	att := attestationTestUnpackResponse(t, u2fTestResponse["success"]).Response.AttestationObject
+
+	_, _, err := att.verifyStatement(make([]byte, 32))
+
+	var e *Error
+
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, CodeAttestationInvalid, e.Code)
+}
+
 var u2fTestResponse = map[string]string{
 	`success`: `{
 		"rawId": "7nJsttr4dLSsmrWnaHB3espJ0ua9rsJ2ws-93BFcNOP64g_s_4wLFDvklrNYcg0BCN6ddUjJLxDfDSBreKQLAw",
@@ -1,11 +1,12 @@
 package protocol
 
 import (
-	"bytes"
 	"encoding/binary"
 	"fmt"
+	"strings"
 
 	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
 )
 
 const (
@@ -246,6 +247,27 @@ func (flag AuthenticatorFlags) HasBackupState() bool {
 	return (flag & FlagBackupState) == FlagBackupState
 }
 
+// String returns the abbreviated names of the flags which are set separated by a '|' in bit order, such as
+// "UP|UV|AT", or "none" if no flags are set. It's intended for logs.
+func (flag AuthenticatorFlags) String() string {
+	names := make([]string, 0, len(authenticatorFlagNames))
+
+	for i, name := range authenticatorFlagNames {
+		if flag&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return "none"
+	}
+
+	return strings.Join(names, "|")
+}
+
+// authenticatorFlagNames are the abbreviated names of the flags in bit order.
+var authenticatorFlagNames = [8]string{"UP", "RFU1", "UV", "BE", "BS", "RFU2", "AT", "ED"}
+
 // Unmarshal will take the raw Authenticator Data and marshals it into AuthenticatorData for further validation.
 // The authenticator data has a compact but extensible encoding. This is desired since authenticators can be
 // devices with limited capabilities and low power requirements, with much simpler software stacks than the client platform.
@@ -254,6 +276,7 @@ func (flag AuthenticatorFlags) HasBackupState() bool {
 func (a *AuthenticatorData) Unmarshal(rawAuthData []byte) (err error) {
 	if minAuthDataLength > len(rawAuthData) {
 		return ErrBadRequest.
+			WithCode(CodeAuthDataInvalid).
 			WithDetails("Authenticator data length too short").
 			WithInfo(fmt.Sprintf("Expected data greater than %d bytes. Got %d bytes", minAuthDataLength, len(rawAuthData)))
 	}
@@ -270,14 +293,13 @@ func (a *AuthenticatorData) Unmarshal(rawAuthData []byte) (err error) {
 				return err
 			}
 
-			attDataLen := len(a.AttData.AAGUID) + 2 + len(a.AttData.CredentialID) + len(a.AttData.CredentialPublicKey)
-			remaining = remaining - attDataLen
+			remaining -= len(a.AttData.AAGUID) + 2 + len(a.AttData.CredentialID) + len(a.AttData.CredentialPublicKey)
 		} else {
-			return ErrBadRequest.WithDetails("Attested credential flag set but data is missing")
+			return ErrBadRequest.WithCode(CodeAuthDataInvalid).WithDetails("Attested credential flag set but data is missing")
 		}
 	} else {
 		if !a.Flags.HasExtensions() && len(rawAuthData) != 37 {
-			return ErrBadRequest.WithDetails("Attested credential flag not set")
+			return ErrBadRequest.WithCode(CodeAuthDataInvalid).WithDetails("Attested credential flag not set")
 		}
 	}
 
@@ -286,12 +308,12 @@ func (a *AuthenticatorData) Unmarshal(rawAuthData []byte) (err error) {
 			a.ExtData = rawAuthData[len(rawAuthData)-remaining:]
 			remaining -= len(a.ExtData)
 		} else {
-			return ErrBadRequest.WithDetails("Extensions flag set but extensions data is missing")
+			return ErrBadRequest.WithCode(CodeAuthDataInvalid).WithDetails("Extensions flag set but extensions data is missing")
 		}
 	}
 
 	if remaining != 0 {
-		return ErrBadRequest.WithDetails("Leftover bytes decoding AuthenticatorData")
+		return ErrBadRequest.WithCode(CodeAuthDataInvalid).WithDetails("Leftover bytes decoding AuthenticatorData")
 	}
 
 	return nil
@@ -303,36 +325,38 @@ func (a *AuthenticatorData) unmarshalAttestedData(rawAuthData []byte) (err error
 
 	idLength := binary.BigEndian.Uint16(rawAuthData[53:55])
 	if len(rawAuthData) < int(55+idLength) {
-		return ErrBadRequest.WithDetails("Authenticator attestation data length too short")
+		return ErrBadRequest.WithCode(CodeAuthDataInvalid).WithDetails("Authenticator attestation data length too short")
 	}
 
 	if idLength > maxCredentialIDLength {
-		return ErrBadRequest.WithDetails("Authenticator attestation data credential id length too long")
+		return ErrBadRequest.WithCode(CodeAuthDataInvalid).WithDetails("Authenticator attestation data credential id length too long")
 	}
 
 	a.AttData.CredentialID = rawAuthData[55 : 55+idLength]
 
 	a.AttData.CredentialPublicKey, err = unmarshalCredentialPublicKey(rawAuthData[55+idLength:])
 	if err != nil {
-		return ErrBadRequest.WithDetails(fmt.Sprintf("Could not unmarshal Credential Public Key: %v", err))
+		return ErrBadRequest.WithCode(CodeAuthDataInvalid).WithDetails(fmt.Sprintf("Could not unmarshal Credential Public Key: %v", err))
 	}
 
 	return nil
 }
 
-// Unmarshall the credential's Public Key into CBOR encoding.
+// Unmarshall the credential's Public Key into CBOR encoding. The returned key is a sub-slice of keyBytes which only
+// contains the first CBOR data item, i.e. any extension data which follows the key is excluded.
 func unmarshalCredentialPublicKey(keyBytes []byte) (rawBytes []byte, err error) {
-	var m interface{}
+	var (
+		key  webauthncose.PublicKeyData
+		rest []byte
+	)
 
-	if err = webauthncbor.Unmarshal(keyBytes, &m); err != nil {
+	if rest, err = webauthncbor.UnmarshalFirst(keyBytes, &key); err != nil {
 		return nil, err
 	}
 
-	if rawBytes, err = webauthncbor.Marshal(m); err != nil {
-		return nil, err
-	}
+	n := len(keyBytes) - len(rest)
 
-	return rawBytes, nil
+	return keyBytes[:n:n], nil
 }
 
 // ResidentKeyRequired - Require that the key be private key resident to the client device.
@@ -356,25 +380,12 @@ func ResidentKeyUnrequired() *bool {
 // Verify on AuthenticatorData handles Steps 9 through 12 for Registration
 // and Steps 11 through 14 for Assertion.
 func (a *AuthenticatorData) Verify(rpIdHash []byte, appIDHash []byte, userVerificationRequired bool) error {
-
-	// Registration Step 9 & Assertion Step 11
-	// Verify that the RP ID hash in authData is indeed the SHA-256
-	// hash of the RP ID expected by the RP.
-	if !bytes.Equal(a.RPIDHash[:], rpIdHash) && !bytes.Equal(a.RPIDHash[:], appIDHash) {
-		return ErrVerification.WithInfo(fmt.Sprintf("RP Hash mismatch. Expected %x and Received %x", a.RPIDHash, rpIdHash))
-	}
-
-	// Registration Step 10 & Assertion Step 12
-	// Verify that the User Present bit of the flags in authData is set.
-	if !a.Flags.UserPresent() {
-		return ErrVerification.WithInfo(fmt.Sprintln("User presence flag not set by authenticator"))
+	if err := VerifyRPIDHash(a, rpIdHash, appIDHash); err != nil {
+		return err
 	}
 
-	// Registration Step 11 & Assertion Step 13
-	// If user verification is required for this assertion, verify that
-	// the User Verified bit of the flags in authData is set.
-	if userVerificationRequired && !a.Flags.UserVerified() {
-		return ErrVerification.WithInfo(fmt.Sprintln("User verification required but flag not set by authenticator"))
+	if err := VerifyFlags(a, userVerificationRequired); err != nil {
+		return err
 	}
 
 	// Registration Step 12 & Assertion Step 14
@@ -1,6 +1,7 @@
 package protocol
 
 import (
+	"crypto/sha256"
 	"encoding/base64"
 	"reflect"
 	"testing"
@@ -134,6 +135,52 @@ func TestAuthenticatorFlags_HasExtensions(t *testing.T) {
 	}
 }
 
+func TestAuthenticatorFlags_Backup(t *testing.T) {
+	tests := []struct {
+		name         string
+		flag         AuthenticatorFlags
+		wantEligible bool
+		wantState    bool
+	}{
+		{"None", AuthenticatorFlags(0x01), false, false},
+		{"Eligible", AuthenticatorFlags(0x09), true, false},
+		{"EligibleAndBackedUp", AuthenticatorFlags(0x19), true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.flag.HasBackupEligible(); got != tt.wantEligible {
+				t.Errorf("AuthenticatorFlags.HasBackupEligible() = %v, want %v", got, tt.wantEligible)
+			}
+
+			if got := tt.flag.HasBackupState(); got != tt.wantState {
+				t.Errorf("AuthenticatorFlags.HasBackupState() = %v, want %v", got, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestAuthenticatorFlags_String(t *testing.T) {
+	tests := []struct {
+		name string
+		flag AuthenticatorFlags
+		want string
+	}{
+		{"None", AuthenticatorFlags(0x00), "none"},
+		{"UserPresent", FlagUserPresent, "UP"},
+		{"Registration", FlagUserPresent | FlagUserVerified | FlagBackupEligible | FlagAttestedCredentialData, "UP|UV|BE|AT"},
+		{"All", AuthenticatorFlags(0xFF), "UP|RFU1|UV|BE|BS|RFU2|AT|ED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.flag.String(); got != tt.want {
+				t.Errorf("AuthenticatorFlags.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestAuthenticatorData_Unmarshal(t *testing.T) {
 	type fields struct {
 		RPIDHash []byte
@@ -233,12 +280,25 @@ func Test_unmarshalCredentialPublicKey(t *testing.T) {
 		keyBytes []byte
 	}
 
+	authData, _ := base64.StdEncoding.DecodeString("pkLSG3xtVeHOI8U5mCjSx0m/am7y/gPMnhDN9O1TCItBAAAAAAAAAAAAAAAAAAAAAAAAAAAAQMAxl6G32ykWaLrv/ouCs5HoGsvONqBtOb7ZmyMs8K8PccnwyyqPzWn/yZuyQmQBguvjYSvH6gDBlFG65quUDCSlAQIDJiABIVggyJGP+ra/u/eVjqN4OeYXUShRWxrEeC6Sb5/bZmJ9q8MiWCCHIkRdg5oRb1RHoFVYUpogcjlObCKFsV1ls1T+uUc6rA==")
+	key := authData[55+64:]
+	keyWithExtensions := append(append([]byte{}, key...), 0xa1, 0x6b, 'c', 'r', 'e', 'd', 'P', 'r', 'o', 't', 'e', 'c', 't', 0x01)
+
 	tests := []struct {
 		name string
 		args args
 		want []byte
 	}{
-		// TODO: Add test cases.
+		{
+			"ShouldReturnKey",
+			args{key},
+			key,
+		},
+		{
+			"ShouldExcludeExtensions",
+			args{keyWithExtensions},
+			key,
+		},
 	}
 
 	for _, tt := range tests {
@@ -249,6 +309,8 @@ func Test_unmarshalCredentialPublicKey(t *testing.T) {
 				t.Errorf("unmarshalCredentialPublicKey() returned err %v", err)
 			} else if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("unmarshalCredentialPublicKey() = %v, want %v", got, tt.want)
+			} else if &got[0] != &tt.args.keyBytes[0] {
+				t.Errorf("unmarshalCredentialPublicKey() returned a copy of the key")
 			}
 		})
 	}
@@ -265,16 +327,47 @@ func TestAuthenticatorData_Verify(t *testing.T) {
 
 	type args struct {
 		rpIdHash                 []byte
+		appIDHash                []byte
 		userVerificationRequired bool
 	}
 
+	rpIdHash := sha256.Sum256([]byte("example.com"))
+	appIDHash := sha256.Sum256([]byte("https://example.com"))
+
 	tests := []struct {
 		name    string
 		fields  fields
 		args    args
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{
+			name:   "ShouldMatchRPIDHash",
+			fields: fields{RPIDHash: rpIdHash[:], Flags: FlagUserPresent},
+			args:   args{rpIdHash: rpIdHash[:], appIDHash: appIDHash[:]},
+		},
+		{
+			name:   "ShouldMatchAppIDHash",
+			fields: fields{RPIDHash: appIDHash[:], Flags: FlagUserPresent},
+			args:   args{rpIdHash: rpIdHash[:], appIDHash: appIDHash[:]},
+		},
+		{
+			name:    "ShouldFailRPIDHashMismatch",
+			fields:  fields{RPIDHash: appIDHash[:], Flags: FlagUserPresent},
+			args:    args{rpIdHash: rpIdHash[:]},
+			wantErr: true,
+		},
+		{
+			name:    "ShouldFailTruncatedRPIDHash",
+			fields:  fields{RPIDHash: rpIdHash[:16], Flags: FlagUserPresent},
+			args:    args{rpIdHash: rpIdHash[:]},
+			wantErr: true,
+		},
+		{
+			name:    "ShouldFailUserNotPresent",
+			fields:  fields{RPIDHash: rpIdHash[:]},
+			args:    args{rpIdHash: rpIdHash[:]},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -286,7 +379,7 @@ func TestAuthenticatorData_Verify(t *testing.T) {
 				AttData:  tt.fields.AttData,
 				ExtData:  tt.fields.ExtData,
 			}
-			if err := a.Verify(tt.args.rpIdHash, nil, tt.args.userVerificationRequired); (err != nil) != tt.wantErr {
+			if err := a.Verify(tt.args.rpIdHash, tt.args.appIDHash, tt.args.userVerificationRequired); (err != nil) != tt.wantErr {
 				t.Errorf("AuthenticatorData.Verify() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -0,0 +1,93 @@
+package protocol
+
+import (
+	"context"
+	"crypto/sha256"
+	"runtime"
+	"sync"
+)
+
+// AssertionSignature is a previously received assertion and the public key of the credential which produced it. It
+// contains everything required to verify the assertion signature without any session state.
+type AssertionSignature struct {
+	// AuthenticatorData is the raw authenticator data of the assertion.
+	AuthenticatorData []byte
+
+	// ClientDataJSON is the raw client data of the assertion.
+	ClientDataJSON []byte
+
+	// Signature is the assertion signature.
+	Signature []byte
+
+	// PublicKey is the COSE encoded credential public key.
+	PublicKey []byte
+
+	// FIDOPublicKey indicates the PublicKey is an uncompressed FIDO U2F public key instead of a COSE encoded key, which
+	// is the case for credentials registered with the FIDO U2F API and used with the appid extension.
+	FIDOPublicKey bool
+}
+
+// VerifyAssertionSignatures verifies the signature of every AssertionSignature concurrently using the provided number
+// of workers, or runtime.GOMAXPROCS if it's less than 1. This is intended for offline analysis such as auditing stored
+// assertions during a credential migration, and unlike ParsedCredentialAssertionData.Verify only the signature is
+// verified.
+//
+// The returned slice contains the result of each AssertionSignature at the same index, which is nil if the signature
+// is valid. If the context is cancelled the assertions which were not yet verified have the context error as their
+// result.
+func VerifyAssertionSignatures(ctx context.Context, assertions []AssertionSignature, workers int) []error {
+	results := make([]error, len(assertions))
+
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	if workers > len(assertions) {
+		workers = len(assertions)
+	}
+
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for index := range indexes {
+				results[index] = assertions[index].verify()
+			}
+		}()
+	}
+
+	for index := range assertions {
+		if err := ctx.Err(); err != nil {
+			results[index] = err
+
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			results[index] = ctx.Err()
+		case indexes <- index:
+		}
+	}
+
+	close(indexes)
+
+	wg.Wait()
+
+	return results
+}
+
+func (a AssertionSignature) verify() error {
+	clientDataHash := sha256.Sum256(a.ClientDataJSON)
+
+	sigData := getSignedData(a.AuthenticatorData, clientDataHash[:])
+	defer putSignedData(sigData)
+
+	return VerifySignature(*sigData, a.Signature, a.PublicKey, a.FIDOPublicKey)
+}
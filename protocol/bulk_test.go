@@ -0,0 +1,85 @@
+package protocol
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyAssertionSignatures(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	publicKey := elliptic.Marshal(elliptic.P256(), key.X, key.Y)
+
+	sign := func(authData, clientDataJSON []byte) AssertionSignature {
+		clientDataHash := sha256.Sum256(clientDataJSON)
+		digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+
+		signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+		require.NoError(t, err)
+
+		return AssertionSignature{
+			AuthenticatorData: authData,
+			ClientDataJSON:    clientDataJSON,
+			Signature:         signature,
+			PublicKey:         publicKey,
+			FIDOPublicKey:     true,
+		}
+	}
+
+	valid := sign(make([]byte, 37), []byte(`{"type":"webauthn.get"}`))
+
+	tampered := sign(make([]byte, 37), []byte(`{"type":"webauthn.get"}`))
+	tampered.ClientDataJSON = []byte(`{"type":"webauthn.create"}`)
+
+	invalidKey := sign(make([]byte, 37), []byte(`{"type":"webauthn.get"}`))
+	invalidKey.PublicKey = []byte{1, 2, 3}
+
+	assertions := []AssertionSignature{valid, tampered, invalidKey, valid}
+
+	testCases := []struct {
+		name    string
+		workers int
+	}{
+		{"ShouldVerifyWithDefaultWorkers", 0},
+		{"ShouldVerifyWithSingleWorker", 1},
+		{"ShouldVerifyWithMoreWorkersThanAssertions", 10},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			results := VerifyAssertionSignatures(context.Background(), assertions, tc.workers)
+
+			require.Len(t, results, len(assertions))
+
+			assert.NoError(t, results[0])
+			assert.Equal(t, CodeSignatureInvalid, results[1].(*Error).Code)
+			assert.Equal(t, CodePublicKeyInvalid, results[2].(*Error).Code)
+			assert.NoError(t, results[3])
+		})
+	}
+
+	t.Run("ShouldReturnContextError", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		results := VerifyAssertionSignatures(ctx, assertions, 2)
+
+		require.Len(t, results, len(assertions))
+
+		for _, result := range results {
+			assert.Equal(t, context.Canceled, result)
+		}
+	})
+
+	t.Run("ShouldHandleEmpty", func(t *testing.T) {
+		assert.Len(t, VerifyAssertionSignatures(context.Background(), nil, 0), 0)
+	})
+}
@@ -1,20 +1,132 @@
 package protocol
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"io"
 )
 
 // ChallengeLength - Length of bytes to generate for a challenge.
 const ChallengeLength = 32
 
+// MinChallengeLength is the minimum length of bytes of a challenge, which is the length recommended by the spec.
+const MinChallengeLength = 16
+
+// Challenge is the challenge of a ceremony which is signed and returned by the authenticator.
+type Challenge []byte
+
+// String returns the base64url encoding of the Challenge without padding, which is the encoding of the challenge in
+// the options and the client data.
+func (c Challenge) String() string {
+	return base64.RawURLEncoding.EncodeToString(c)
+}
+
+// Equal returns true if the Challenge is equal to the other challenge. The comparison is performed in constant time.
+func (c Challenge) Equal(other Challenge) bool {
+	return subtle.ConstantTimeCompare(c, other) == 1
+}
+
+// EqualString returns true if the base64url encoding of the Challenge is equal to the encoded challenge, such as the
+// challenge of the CollectedClientData. The comparison is performed in constant time.
+func (c Challenge) EqualString(encoded string) bool {
+	return subtle.ConstantTimeCompare([]byte(c.String()), []byte(encoded)) == 1
+}
+
+// ChallengeSource generates the challenges of the ceremonies.
+type ChallengeSource interface {
+	// NewChallenge returns a new unpredictable challenge which must be at least MinChallengeLength bytes.
+	NewChallenge() (Challenge, error)
+}
+
+// RandomChallengeSource is a ChallengeSource which reads the challenges from a source of randomness.
+type RandomChallengeSource struct {
+	// Reader is the source of randomness which must be cryptographically secure outside of tests. The default is
+	// crypto/rand.Reader.
+	Reader io.Reader
+
+	// Length of bytes of the challenges which must be at least MinChallengeLength. The default is ChallengeLength.
+	Length int
+}
+
+// NewChallenge returns a new challenge read from the Reader.
+func (s RandomChallengeSource) NewChallenge() (Challenge, error) {
+	return CreateChallengeWithLength(s.Reader, s.Length)
+}
+
 // CreateChallenge creates a new challenge that should be signed and returned by the authenticator. The spec recommends
 // using at least 16 bytes with 100 bits of entropy. We use 32 bytes.
 func CreateChallenge() (challenge URLEncodedBase64, err error) {
-	challenge = make([]byte, ChallengeLength)
+	return CreateChallengeWithReader(rand.Reader)
+}
+
+// CreateChallengeWithReader is the same as CreateChallenge except the challenge is read from the provided io.Reader
+// instead of crypto/rand. It's intended to make the challenges deterministic in tests, and the reader must be a
+// cryptographically secure source of randomness otherwise.
+func CreateChallengeWithReader(r io.Reader) (challenge URLEncodedBase64, err error) {
+	return CreateChallengeWithLength(r, ChallengeLength)
+}
+
+// CreateChallengeWithLength is the same as CreateChallengeWithReader except the challenge is the provided length of
+// bytes, which must be at least MinChallengeLength. A nil reader reads from crypto/rand, and a length of 0 is
+// ChallengeLength.
+func CreateChallengeWithLength(r io.Reader, length int) (challenge []byte, err error) {
+	if r == nil {
+		r = rand.Reader
+	}
 
-	if _, err = rand.Read(challenge); err != nil {
+	if length == 0 {
+		length = ChallengeLength
+	}
+
+	if length < MinChallengeLength {
+		return nil, fmt.Errorf("challenge length of %d bytes is less than the minimum of %d bytes", length, MinChallengeLength)
+	}
+
+	challenge = make([]byte, length)
+
+	if _, err = io.ReadFull(r, challenge); err != nil {
 		return nil, err
 	}
 
 	return challenge, nil
 }
+
+// DeriveChallenge returns a challenge which commits to the binding, such as the fingerprint of a session or the
+// details of a transaction, so the binding can be verified from the challenge returned by the client with
+// VerifyDerivedChallenge without relying on the stored state alone. The challenge is the nonce followed by the
+// HMAC-SHA256 of the nonce and the SHA-256 hash of the binding under the key. The nonce must be unpredictable and at
+// least MinChallengeLength bytes.
+func DeriveChallenge(key, nonce, binding []byte) Challenge {
+	challenge := make(Challenge, len(nonce), len(nonce)+sha256.Size)
+
+	copy(challenge, nonce)
+
+	return append(challenge, deriveChallengeMAC(key, nonce, binding)...)
+}
+
+// VerifyDerivedChallenge returns true if the challenge was returned by DeriveChallenge for the key and binding. The
+// comparison is performed in constant time.
+func VerifyDerivedChallenge(challenge Challenge, key, binding []byte) bool {
+	if len(challenge) < MinChallengeLength+sha256.Size {
+		return false
+	}
+
+	nonce, mac := challenge[:len(challenge)-sha256.Size], challenge[len(challenge)-sha256.Size:]
+
+	return hmac.Equal(mac, deriveChallengeMAC(key, nonce, binding))
+}
+
+func deriveChallengeMAC(key, nonce, binding []byte) []byte {
+	hash := sha256.Sum256(binding)
+
+	mac := hmac.New(sha256.New, key)
+
+	mac.Write(nonce)
+	mac.Write(hash[:])
+
+	return mac.Sum(nil)
+}
@@ -1,9 +1,13 @@
 package protocol
 
 import (
+	"bytes"
 	"encoding/base64"
 	"reflect"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateChallenge(t *testing.T) {
@@ -34,6 +38,42 @@ func TestCreateChallenge(t *testing.T) {
 	}
 }
 
+func TestCreateChallengeWithReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		have    []byte
+		want    URLEncodedBase64
+		wantErr bool
+	}{
+		{
+			"ShouldReadChallenge",
+			bytes.Repeat([]byte{0x01}, ChallengeLength+1),
+			URLEncodedBase64(bytes.Repeat([]byte{0x01}, ChallengeLength)),
+			false,
+		},
+		{
+			"ShouldFailShortRead",
+			bytes.Repeat([]byte{0x01}, ChallengeLength-1),
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CreateChallengeWithReader(bytes.NewReader(tt.have))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateChallengeWithReader() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CreateChallengeWithReader() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestChallenge_String(t *testing.T) {
 	newChallenge, err := CreateChallenge()
 	if err != nil {
@@ -63,3 +103,87 @@ func TestChallenge_String(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateChallengeWithLength(t *testing.T) {
+	testCases := []struct {
+		name     string
+		length   int
+		expected int
+		err      string
+	}{
+		{"ShouldUseDefaultLength", 0, ChallengeLength, ""},
+		{"ShouldUseMinimumLength", MinChallengeLength, MinChallengeLength, ""},
+		{"ShouldUseLongLength", 64, 64, ""},
+		{"ShouldFailShortLength", MinChallengeLength - 1, 0, "challenge length of 15 bytes is less than the minimum of 16 bytes"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			challenge, err := CreateChallengeWithLength(nil, tc.length)
+
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, challenge, tc.expected)
+		})
+	}
+}
+
+func TestRandomChallengeSource(t *testing.T) {
+	source := RandomChallengeSource{Reader: bytes.NewReader(bytes.Repeat([]byte{0x01}, 64)), Length: 20}
+
+	challenge, err := source.NewChallenge()
+	require.NoError(t, err)
+	assert.Equal(t, Challenge(bytes.Repeat([]byte{0x01}, 20)), challenge)
+
+	_, err = RandomChallengeSource{Reader: bytes.NewReader(nil)}.NewChallenge()
+	assert.Error(t, err)
+}
+
+func TestChallenge_Equal(t *testing.T) {
+	challenge := Challenge("challenge value!")
+
+	assert.Equal(t, "Y2hhbGxlbmdlIHZhbHVlIQ", challenge.String())
+	assert.True(t, challenge.Equal(Challenge("challenge value!")))
+	assert.False(t, challenge.Equal(Challenge("challenge value?")))
+	assert.False(t, challenge.Equal(nil))
+	assert.True(t, challenge.EqualString("Y2hhbGxlbmdlIHZhbHVlIQ"))
+	assert.False(t, challenge.EqualString("Y2hhbGxlbmdlIHZhbHVlPw"))
+}
+
+func TestDeriveChallenge(t *testing.T) {
+	key := bytes.Repeat([]byte{0x02}, 32)
+	nonce := bytes.Repeat([]byte{0x01}, MinChallengeLength)
+
+	challenge := DeriveChallenge(key, nonce, []byte("transaction"))
+
+	assert.Len(t, challenge, MinChallengeLength+32)
+	assert.Equal(t, nonce, []byte(challenge[:MinChallengeLength]))
+	assert.Equal(t, challenge, DeriveChallenge(key, nonce, []byte("transaction")))
+
+	testCases := []struct {
+		name      string
+		challenge Challenge
+		key       []byte
+		binding   []byte
+		expected  bool
+	}{
+		{"ShouldVerify", challenge, key, []byte("transaction"), true},
+		{"ShouldFailOtherBinding", challenge, key, []byte("other transaction"), false},
+		{"ShouldFailEmptyBinding", challenge, key, nil, false},
+		{"ShouldFailOtherKey", challenge, bytes.Repeat([]byte{0x03}, 32), []byte("transaction"), false},
+		{"ShouldFailOtherNonce", append(Challenge{0x00}, challenge[1:]...), key, []byte("transaction"), false},
+		{"ShouldFailShortChallenge", challenge[1:], key, []byte("transaction"), false},
+		{"ShouldVerifyEmptyBinding", DeriveChallenge(key, nonce, nil), key, []byte{}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, VerifyDerivedChallenge(tc.challenge, tc.key, tc.binding))
+		})
+	}
+}
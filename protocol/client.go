@@ -2,6 +2,7 @@ package protocol
 
 import (
 	"crypto/subtle"
+	"encoding/base64"
 	"fmt"
 	"net/url"
 	"strings"
@@ -22,6 +23,13 @@ type CollectedClientData struct {
 	Origin       string        `json:"origin"`
 	TokenBinding *TokenBinding `json:"tokenBinding,omitempty"`
 
+	// CrossOrigin is true if the credential was created or used within an iframe which is not same-origin with its
+	// ancestors. It's part of WebAuthn Level 3.
+	CrossOrigin bool `json:"crossOrigin,omitempty"`
+
+	// TopOrigin is the origin of the top level document when CrossOrigin is true. It's part of WebAuthn Level 3.
+	TopOrigin string `json:"topOrigin,omitempty"`
+
 	// Chromium (Chrome) returns a hint sometimes about how to handle clientDataJSON in a safe manner.
 	Hint string `json:"new_keys_may_be_added_here,omitempty"`
 }
@@ -54,7 +62,7 @@ const (
 
 // FullyQualifiedOrigin returns the origin per the HTML spec: (scheme)://(host)[:(port)].
 func FullyQualifiedOrigin(rawOrigin string) (fqOrigin string, err error) {
-	if strings.HasPrefix(rawOrigin, "android:apk-key-hash:") {
+	if strings.HasPrefix(rawOrigin, AndroidOriginPrefix) {
 		return rawOrigin, nil
 	}
 
@@ -78,65 +86,162 @@ func FullyQualifiedOrigin(rawOrigin string) (fqOrigin string, err error) {
 // See https://www.w3.org/TR/webauthn/#registering-a-new-credential
 // and https://www.w3.org/TR/webauthn/#verifying-assertion
 func (c *CollectedClientData) Verify(storedChallenge string, ceremony CeremonyType, rpOrigins []string) error {
-	// Registration Step 3. Verify that the value of C.type is webauthn.create.
+	return c.VerifyWithOriginVerifier(storedChallenge, ceremony, rpOrigins, nil)
+}
 
-	// Assertion Step 7. Verify that the value of C.type is the string webauthn.get.
-	if c.Type != ceremony {
-		return ErrVerification.WithDetails("Error validating ceremony type").WithInfo(fmt.Sprintf("Expected Value: %s, Received: %s", ceremony, c.Type))
-	}
+// VerifyWithOriginVerifier is the same as Verify except the origin is verified by the OriginVerifier, or the
+// StrictOriginVerifier if it's nil.
+func (c *CollectedClientData) VerifyWithOriginVerifier(storedChallenge string, ceremony CeremonyType, rpOrigins []string, verifier OriginVerifier) error {
+	return c.VerifyChecks(ClientDataCheckAll, storedChallenge, ceremony, rpOrigins, verifier)
+}
 
-	// Registration Step 4. Verify that the value of C.challenge matches the challenge
-	// that was sent to the authenticator in the create() call.
+// ClientDataChecks is a set of the checks performed by VerifyChecks.
+type ClientDataChecks uint8
 
-	// Assertion Step 8. Verify that the value of C.challenge matches the challenge
-	// that was sent to the authenticator in the PublicKeyCredentialRequestOptions
-	// passed to the get() call.
+const (
+	// ClientDataCheckType checks the type with VerifyCeremonyType, which returns an error with CodeCeremonyMismatch.
+	ClientDataCheckType ClientDataChecks = 1 << iota
 
-	challenge := c.Challenge
-	if subtle.ConstantTimeCompare([]byte(storedChallenge), []byte(challenge)) != 1 {
-		return ErrVerification.
-			WithDetails("Error validating challenge").
-			WithInfo(fmt.Sprintf("Expected b Value: %#v\nReceived b: %#v\n", storedChallenge, challenge))
+	// ClientDataCheckChallenge checks the challenge with VerifyChallenge, which returns an error with
+	// CodeChallengeMismatch.
+	ClientDataCheckChallenge
+
+	// ClientDataCheckOrigin checks the origin with VerifyOriginWith, which returns an error with CodeOriginInvalid or
+	// CodeOriginMismatch unless the OriginVerifier returns an *Error with another code.
+	ClientDataCheckOrigin
+
+	// ClientDataCheckTokenBinding checks the format of the token binding with VerifyTokenBindingFormat, which returns
+	// an error with CodeTokenBindingInvalid.
+	ClientDataCheckTokenBinding
+
+	// ClientDataCheckAll is every check, which is the verification performed by Verify.
+	ClientDataCheckAll = ClientDataCheckType | ClientDataCheckChallenge | ClientDataCheckOrigin | ClientDataCheckTokenBinding
+)
+
+// VerifyChecks is the same as VerifyWithOriginVerifier except only the checks included in the ClientDataChecks are
+// performed, in the order of the steps of the specification. This allows exactly one check to be relaxed without
+// losing the others, for example ClientDataCheckAll &^ ClientDataCheckOrigin skips the origin in tests. Each check
+// returns an error with a code specific to the check.
+func (c *CollectedClientData) VerifyChecks(checks ClientDataChecks, storedChallenge string, ceremony CeremonyType, rpOrigins []string, verifier OriginVerifier) error {
+	if checks&ClientDataCheckType != 0 {
+		if err := VerifyCeremonyType(c, ceremony); err != nil {
+			return err
+		}
 	}
 
-	// Registration Step 5 & Assertion Step 9. Verify that the value of C.origin matches
-	// the Relying Party's origin.
-	fqOrigin, err := FullyQualifiedOrigin(c.Origin)
-	if err != nil {
-		return ErrParsingData.WithDetails("Error decoding clientData origin as URL")
+	if checks&ClientDataCheckChallenge != 0 {
+		if err := VerifyChallenge(c, storedChallenge); err != nil {
+			return err
+		}
 	}
 
-	found := false
+	if checks&ClientDataCheckOrigin != 0 {
+		if err := VerifyOriginWith(verifier, c, rpOrigins); err != nil {
+			return err
+		}
+	}
 
-	for _, origin := range rpOrigins {
-		if strings.EqualFold(fqOrigin, origin) {
-			found = true
-			break
+	if checks&ClientDataCheckTokenBinding != 0 {
+		if err := VerifyTokenBindingFormat(c); err != nil {
+			return err
 		}
 	}
 
-	if !found {
+	// The token binding is verified against the state of the TLS connection by VerifyTokenBinding, as the state is
+	// not known here.
+
+	return nil
+}
+
+// TokenBindingPolicy determines how the token binding of the client data is verified against the state of the TLS
+// connection over which the response was obtained.
+type TokenBindingPolicy int
+
+const (
+	// TokenBindingPolicyIgnore does not verify the token binding against the state of the connection. Only the format
+	// of the token binding is validated by Verify. This is the default.
+	TokenBindingPolicyIgnore TokenBindingPolicy = iota
+
+	// TokenBindingPolicyVerify verifies the token binding was used by the client if and only if it was used on the
+	// connection, in which case the token binding ID must match the ID of the connection.
+	TokenBindingPolicyVerify
+
+	// TokenBindingPolicyRequired is the same as TokenBindingPolicyVerify except token binding must have been used on
+	// the connection.
+	TokenBindingPolicyRequired
+)
+
+// TokenBindingState is the state of Token Binding for the TLS connection over which a response was obtained. The
+// crypto/tls package does not implement Token Binding so the state must be supplied by the caller, for example from
+// the TLS terminating proxy.
+type TokenBindingState struct {
+	// Status is Present if Token Binding was used on the connection, otherwise Supported if it was negotiated but not
+	// used, or NotSupported.
+	Status TokenBindingStatus
+
+	// ID is the Token Binding ID of the connection when the Status is Present.
+	ID []byte
+}
+
+// VerifyTokenBinding handles the token binding part of step 6 of verifying the registering client data of a new
+// credential and step 10 of verifying an authentication assertion, verifying the token binding of the client data
+// against the state of the connection according to the policy. A nil state indicates Token Binding was not used on
+// the connection.
+func (c *CollectedClientData) VerifyTokenBinding(state *TokenBindingState, policy TokenBindingPolicy) error {
+	if policy == TokenBindingPolicyIgnore {
+		return nil
+	}
+
+	connection := state != nil && state.Status == Present
+	client := c.TokenBinding != nil && c.TokenBinding.Status == Present
+
+	if policy == TokenBindingPolicyRequired && !connection {
 		return ErrVerification.
-			WithDetails("Error validating origin").
-			WithInfo(fmt.Sprintf("Expected Values: %s, Received: %s", rpOrigins, fqOrigin))
+			WithCode(CodeTokenBindingRequired).
+			WithDetails("Error validating token binding").
+			WithInfo("Token binding is required but was not used on the connection")
 	}
 
-	// Registration Step 6 and Assertion Step 10. Verify that the value of C.tokenBinding.status
-	// matches the state of Token Binding for the TLS connection over which the assertion was
-	// obtained. If Token Binding was used on that TLS connection, also verify that C.tokenBinding.id
-	// matches the base64url encoding of the Token Binding ID for the connection.
-	if c.TokenBinding != nil {
-		if c.TokenBinding.Status == "" {
-			return ErrParsingData.WithDetails("Error decoding clientData, token binding present without status")
-		}
+	if connection != client {
+		return ErrVerification.
+			WithCode(CodeTokenBindingMismatch).
+			WithDetails("Error validating token binding").
+			WithInfo(fmt.Sprintf("Expected Status: %s, Received: %s", state.status(), c.tokenBindingStatus()))
+	}
 
-		if c.TokenBinding.Status != Present && c.TokenBinding.Status != Supported && c.TokenBinding.Status != NotSupported {
-			return ErrParsingData.
-				WithDetails("Error decoding clientData, token binding present with invalid status").
-				WithInfo(fmt.Sprintf("Got: %s", c.TokenBinding.Status))
-		}
+	if !connection {
+		return nil
+	}
+
+	if len(state.ID) == 0 {
+		return ErrVerification.
+			WithCode(CodeTokenBindingMismatch).
+			WithDetails("Error validating token binding").
+			WithInfo("Token binding was used on the connection without an ID")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(base64.RawURLEncoding.EncodeToString(state.ID)), []byte(strings.TrimRight(c.TokenBinding.ID, "="))) != 1 {
+		return ErrVerification.
+			WithCode(CodeTokenBindingMismatch).
+			WithDetails("Error validating token binding").
+			WithInfo("Token binding ID does not match the ID of the connection")
 	}
-	// Not yet fully implemented by the spec, browsers, and me.
 
 	return nil
 }
+
+func (c *CollectedClientData) tokenBindingStatus() TokenBindingStatus {
+	if c.TokenBinding == nil {
+		return NotSupported
+	}
+
+	return c.TokenBinding.Status
+}
+
+func (s *TokenBindingState) status() TokenBindingStatus {
+	if s == nil || s.Status == "" {
+		return NotSupported
+	}
+
+	return s.Status
+}
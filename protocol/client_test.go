@@ -1,9 +1,11 @@
 package protocol
 
 import (
+	"encoding/base64"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func setupCollectedClientData(challenge URLEncodedBase64, origin string) *CollectedClientData {
@@ -47,6 +49,10 @@ func TestVerifyCollectedClientDataIncorrectChallenge(t *testing.T) {
 	if err = ccd.Verify(bogusChallenge.String(), ccd.Type, []string{ccd.Origin}); err == nil {
 		t.Fatalf("error expected but not received. expected %#v got %#v", ccd.Challenge, bogusChallenge)
 	}
+
+	if code := err.(*Error).Code; code != CodeChallengeMismatch {
+		t.Fatalf("error code mismatch. expected %#v got %#v", CodeChallengeMismatch, code)
+	}
 }
 
 func TestVerifyCollectedClientDataUnexpectedOrigin(t *testing.T) {
@@ -62,6 +68,10 @@ func TestVerifyCollectedClientDataUnexpectedOrigin(t *testing.T) {
 	if err = ccd.Verify(storedChallenge.String(), ccd.Type, expectedOrigins); err == nil {
 		t.Fatalf("error expected but not received. expected %#v got %#v", expectedOrigins, ccd.Origin)
 	}
+
+	if code := err.(*Error).Code; code != CodeOriginMismatch {
+		t.Fatalf("error code mismatch. expected %#v got %#v", CodeOriginMismatch, code)
+	}
 }
 
 func TestVerifyCollectedClientDataWithMultipleExpectedOrigins(t *testing.T) {
@@ -81,6 +91,85 @@ func TestVerifyCollectedClientDataWithMultipleExpectedOrigins(t *testing.T) {
 	}
 }
 
+func TestVerifyCollectedClientDataWithOriginVerifier(t *testing.T) {
+	newChallenge, err := CreateChallenge()
+	require.NoError(t, err)
+
+	ccd := setupCollectedClientData(newChallenge, "ios:bundle-id:com.example.app")
+
+	nativeVerifier := OriginVerifierFunc(func(c *CollectedClientData, rpOrigins []string) error {
+		if c.Origin == "ios:bundle-id:com.example.app" {
+			return nil
+		}
+
+		return VerifyOrigin(c, rpOrigins)
+	})
+
+	assert.NoError(t, ccd.VerifyWithOriginVerifier(newChallenge.String(), ccd.Type, []string{"https://example.com"}, nativeVerifier))
+
+	err = ccd.VerifyWithOriginVerifier(newChallenge.String(), ccd.Type, []string{"https://example.com"}, nil)
+	require.IsType(t, &Error{}, err)
+	assert.Equal(t, CodeOriginInvalid, err.(*Error).Code)
+
+	failing := OriginVerifierFunc(func(c *CollectedClientData, rpOrigins []string) error {
+		return assert.AnError
+	})
+
+	err = ccd.VerifyWithOriginVerifier(newChallenge.String(), ccd.Type, []string{"https://example.com"}, failing)
+	require.IsType(t, &Error{}, err)
+	assert.Equal(t, CodeOriginMismatch, err.(*Error).Code)
+
+	err = ccd.VerifyWithOriginVerifier("bogus", ccd.Type, []string{"https://example.com"}, nativeVerifier)
+	require.IsType(t, &Error{}, err)
+	assert.Equal(t, CodeChallengeMismatch, err.(*Error).Code)
+}
+
+func TestCollectedClientData_VerifyChecks(t *testing.T) {
+	newChallenge, err := CreateChallenge()
+	require.NoError(t, err)
+
+	rpOrigins := []string{"https://example.com"}
+
+	testCases := []struct {
+		name      string
+		ceremony  CeremonyType
+		challenge string
+		origin    string
+		binding   *TokenBinding
+		checks    ClientDataChecks
+		expected  ErrorCode
+	}{
+		{"ShouldPassAll", CreateCeremony, newChallenge.String(), "https://example.com", nil, ClientDataCheckAll, ""},
+		{"ShouldFailType", AssertCeremony, newChallenge.String(), "https://example.com", nil, ClientDataCheckAll, CodeCeremonyMismatch},
+		{"ShouldFailChallenge", CreateCeremony, "bogus", "https://example.com", nil, ClientDataCheckAll, CodeChallengeMismatch},
+		{"ShouldFailOrigin", CreateCeremony, newChallenge.String(), "https://other.com", nil, ClientDataCheckAll, CodeOriginMismatch},
+		{"ShouldFailTokenBinding", CreateCeremony, newChallenge.String(), "https://example.com", &TokenBinding{Status: "bogus"}, ClientDataCheckAll, CodeTokenBindingInvalid},
+		{"ShouldSkipType", AssertCeremony, newChallenge.String(), "https://example.com", nil, ClientDataCheckAll &^ ClientDataCheckType, ""},
+		{"ShouldSkipChallenge", CreateCeremony, "bogus", "https://example.com", nil, ClientDataCheckAll &^ ClientDataCheckChallenge, ""},
+		{"ShouldSkipOrigin", CreateCeremony, newChallenge.String(), "https://other.com", nil, ClientDataCheckAll &^ ClientDataCheckOrigin, ""},
+		{"ShouldSkipTokenBinding", CreateCeremony, newChallenge.String(), "https://example.com", &TokenBinding{Status: "bogus"}, ClientDataCheckAll &^ ClientDataCheckTokenBinding, ""},
+		{"ShouldNotSkipOthers", CreateCeremony, "bogus", "https://other.com", nil, ClientDataCheckAll &^ ClientDataCheckOrigin, CodeChallengeMismatch},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ccd := setupCollectedClientData(newChallenge, tc.origin)
+			ccd.TokenBinding = tc.binding
+
+			err := ccd.VerifyChecks(tc.checks, tc.challenge, tc.ceremony, rpOrigins, nil)
+
+			if tc.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				var e *Error
+
+				require.ErrorAs(t, err, &e)
+				assert.Equal(t, tc.expected, e.Code)
+			}
+		})
+	}
+}
+
 func TestFullyQualifiedOrigin(t *testing.T) {
 	testCases := []struct {
 		name                  string
@@ -113,3 +202,46 @@ func TestFullyQualifiedOrigin(t *testing.T) {
 		})
 	}
 }
+
+func TestCollectedClientData_VerifyTokenBinding(t *testing.T) {
+	id := []byte("token binding id")
+	present := &TokenBinding{Status: Present, ID: base64.RawURLEncoding.EncodeToString(id)}
+
+	testCases := []struct {
+		name     string
+		client   *TokenBinding
+		state    *TokenBindingState
+		policy   TokenBindingPolicy
+		expected ErrorCode
+	}{
+		{"ShouldIgnoreMismatch", present, nil, TokenBindingPolicyIgnore, ""},
+		{"ShouldPassNotUsed", nil, nil, TokenBindingPolicyVerify, ""},
+		{"ShouldPassSupported", &TokenBinding{Status: Supported}, &TokenBindingState{Status: Supported}, TokenBindingPolicyVerify, ""},
+		{"ShouldPassPresent", present, &TokenBindingState{Status: Present, ID: id}, TokenBindingPolicyVerify, ""},
+		{"ShouldPassPresentRequired", present, &TokenBindingState{Status: Present, ID: id}, TokenBindingPolicyRequired, ""},
+		{"ShouldPassPaddedID", &TokenBinding{Status: Present, ID: base64.URLEncoding.EncodeToString(id)}, &TokenBindingState{Status: Present, ID: id}, TokenBindingPolicyVerify, ""},
+		{"ShouldFailClientPresentOnly", present, nil, TokenBindingPolicyVerify, CodeTokenBindingMismatch},
+		{"ShouldFailConnectionPresentOnly", &TokenBinding{Status: Supported}, &TokenBindingState{Status: Present, ID: id}, TokenBindingPolicyVerify, CodeTokenBindingMismatch},
+		{"ShouldFailIDMismatch", present, &TokenBindingState{Status: Present, ID: []byte("other")}, TokenBindingPolicyVerify, CodeTokenBindingMismatch},
+		{"ShouldFailMissingConnectionID", present, &TokenBindingState{Status: Present}, TokenBindingPolicyVerify, CodeTokenBindingMismatch},
+		{"ShouldFailRequiredNotUsed", &TokenBinding{Status: Supported}, &TokenBindingState{Status: Supported}, TokenBindingPolicyRequired, CodeTokenBindingRequired},
+		{"ShouldFailRequiredNoState", nil, nil, TokenBindingPolicyRequired, CodeTokenBindingRequired},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ccd := &CollectedClientData{TokenBinding: tc.client}
+
+			err := ccd.VerifyTokenBinding(tc.state, tc.policy)
+
+			if tc.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				var e *Error
+
+				require.ErrorAs(t, err, &e)
+				assert.Equal(t, tc.expected, e.Code)
+			}
+		})
+	}
+}
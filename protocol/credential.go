@@ -1,10 +1,15 @@
 package protocol
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/base64"
+	"fmt"
 	"io"
 	"net/http"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
 )
 
 // Credential is the basic credential type from the Credential Management specification that is inherited by WebAuthn's
@@ -61,16 +66,31 @@ type ParsedCredentialCreationData struct {
 }
 
 // ParseCredentialCreationResponse is a non-agnostic function for parsing a registration response from the http library
-// from stdlib. It handles some standard cleanup operations.
+// from stdlib. It handles some standard cleanup operations. The body is JSON encoded unless the Content-Type of the
+// request is ContentTypeForm, in which case it's a form with a field per value as posted by legacy frontends.
 func ParseCredentialCreationResponse(response *http.Request) (*ParsedCredentialCreationData, error) {
+	return ParseCredentialCreationResponseWithLimit(response, DefaultResponseBodyLimit)
+}
+
+// ParseCredentialCreationResponseWithLimit is the same as ParseCredentialCreationResponse except the body is limited to the
+// provided number of bytes instead of DefaultResponseBodyLimit. If the limit is less than 1 the body is not limited.
+func ParseCredentialCreationResponseWithLimit(response *http.Request, limit int64) (*ParsedCredentialCreationData, error) {
 	if response == nil || response.Body == nil {
-		return nil, ErrBadRequest.WithDetails("No response given")
+		return nil, ErrBadRequest.WithCode(CodeResponseInvalid).WithDetails("No response given")
 	}
 
 	defer response.Body.Close()
-	defer io.Copy(io.Discard, response.Body)
 
-	return ParseCredentialCreationResponseBody(response.Body)
+	body := limitBody(response.Body, limit)
+
+	defer io.Copy(io.Discard, body)
+
+	reader, err := requestBody(response, body)
+	if err != nil {
+		return nil, decodeBodyError(err, "Parse error for Registration")
+	}
+
+	return ParseCredentialCreationResponseBody(reader)
 }
 
 // ParseCredentialCreationResponseBody is an agnostic version of ParseCredentialCreationResponse. Implementers are
@@ -79,37 +99,54 @@ func ParseCredentialCreationResponseBody(body io.Reader) (pcc *ParsedCredentialC
 	var ccr CredentialCreationResponse
 
 	if err = decodeBody(body, &ccr); err != nil {
-		return nil, ErrBadRequest.WithDetails("Parse error for Registration").WithInfo(err.Error())
+		return nil, decodeBodyError(err, "Parse error for Registration")
 	}
 
 	return ccr.Parse()
 }
 
+// ParseCredentialCreationResponseBytes is the same as ParseCredentialCreationResponseBody except the registration response is a byte slice, which suits
+// frameworks such as fasthttp which do not use the http library from stdlib. The response is limited to
+// DefaultResponseBodyLimit.
+func ParseCredentialCreationResponseBytes(data []byte) (*ParsedCredentialCreationData, error) {
+	return ParseCredentialCreationResponseBytesWithLimit(data, DefaultResponseBodyLimit)
+}
+
+// ParseCredentialCreationResponseBytesWithLimit is the same as ParseCredentialCreationResponseBytes except the response is limited to the
+// provided number of bytes instead of DefaultResponseBodyLimit. If the limit is less than 1 the response is not limited.
+func ParseCredentialCreationResponseBytesWithLimit(data []byte, limit int64) (*ParsedCredentialCreationData, error) {
+	if len(data) == 0 {
+		return nil, ErrBadRequest.WithCode(CodeResponseInvalid).WithDetails("No response given")
+	}
+
+	return ParseCredentialCreationResponseBody(limitBody(bytes.NewReader(data), limit))
+}
+
 // Parse validates and parses the CredentialCreationResponse into a ParsedCredentialCreationData. This receiver
 // is unlikely to be expressly guaranteed under the versioning policy. Users looking for this guarantee should see
 // ParseCredentialCreationResponseBody instead, and this receiver should only be used if that function is inadequate
 // for their use case.
 func (ccr CredentialCreationResponse) Parse() (pcc *ParsedCredentialCreationData, err error) {
 	if ccr.ID == "" {
-		return nil, ErrBadRequest.WithDetails("Parse error for Registration").WithInfo("Missing ID")
+		return nil, ErrBadRequest.WithCode(CodeResponseInvalid).WithDetails("Parse error for Registration").WithInfo("Missing ID")
 	}
 
 	testB64, err := base64.RawURLEncoding.DecodeString(ccr.ID)
 	if err != nil || !(len(testB64) > 0) {
-		return nil, ErrBadRequest.WithDetails("Parse error for Registration").WithInfo("ID not base64.RawURLEncoded")
+		return nil, ErrBadRequest.WithCode(CodeResponseInvalid).WithDetails("Parse error for Registration").WithInfo("ID not base64.RawURLEncoded")
 	}
 
 	if ccr.PublicKeyCredential.Credential.Type == "" {
-		return nil, ErrBadRequest.WithDetails("Parse error for Registration").WithInfo("Missing type")
+		return nil, ErrBadRequest.WithCode(CodeResponseInvalid).WithDetails("Parse error for Registration").WithInfo("Missing type")
 	}
 
 	if ccr.PublicKeyCredential.Credential.Type != "public-key" {
-		return nil, ErrBadRequest.WithDetails("Parse error for Registration").WithInfo("Type not public-key")
+		return nil, ErrBadRequest.WithCode(CodeResponseInvalid).WithDetails("Parse error for Registration").WithInfo("Type not public-key")
 	}
 
 	response, err := ccr.AttestationResponse.Parse()
 	if err != nil {
-		return nil, ErrParsingData.WithDetails("Error parsing attestation response")
+		return nil, ErrParsingData.WithCode(CodeResponseInvalid).WithDetails("Error parsing attestation response")
 	}
 
 	// TODO: Remove this as it's a backwards compatibility layer.
@@ -129,11 +166,11 @@ func (ccr CredentialCreationResponse) Parse() (pcc *ParsedCredentialCreationData
 	}
 
 	return &ParsedCredentialCreationData{
-		ParsedPublicKeyCredential{
+		ParsedPublicKeyCredential: ParsedPublicKeyCredential{
 			ParsedCredential{ccr.ID, ccr.Type}, ccr.RawID, ccr.ClientExtensionResults, attachment,
 		},
-		*response,
-		ccr,
+		Response: *response,
+		Raw:      ccr,
 	}, nil
 }
 
@@ -141,24 +178,25 @@ func (ccr CredentialCreationResponse) Parse() (pcc *ParsedCredentialCreationData
 //
 // Specification: §7.1. Registering a New Credential (https://www.w3.org/TR/webauthn/#sctn-registering-a-new-credential)
 func (pcc *ParsedCredentialCreationData) Verify(storedChallenge string, verifyUser bool, relyingPartyID string, relyingPartyOrigins []string) error {
-	// Handles steps 3 through 6 - Verifying the Client Data against the Relying Party's stored data
-	verifyError := pcc.Response.CollectedClientData.Verify(storedChallenge, CreateCeremony, relyingPartyOrigins)
-	if verifyError != nil {
-		return verifyError
-	}
+	return pcc.VerifyWithTrace(nil, storedChallenge, verifyUser, relyingPartyID, relyingPartyOrigins)
+}
 
-	// Step 7. Compute the hash of response.clientDataJSON using SHA-256.
-	clientDataHash := sha256.Sum256(pcc.Raw.AttestationResponse.ClientDataJSON)
+// VerifyWithTrace is the same as Verify except it records each verification step in the provided *VerificationTrace.
+func (pcc *ParsedCredentialCreationData) VerifyWithTrace(trace *VerificationTrace, storedChallenge string, verifyUser bool, relyingPartyID string, relyingPartyOrigins []string) error {
+	return pcc.VerifyWithPolicy(trace, AttestationPolicy{}, storedChallenge, verifyUser, relyingPartyID, relyingPartyOrigins, nil)
+}
 
-	// Step 8. Perform CBOR decoding on the attestationObject field of the AuthenticatorAttestationResponse
-	// structure to obtain the attestation statement format fmt, the authenticator data authData, and the
-	// attestation statement attStmt.
+// VerifyWithPolicy is the same as VerifyWithTrace except the metadata is verified according to the provided
+// AttestationPolicy, and the origin of the client data is verified by the OriginVerifier, or the StrictOriginVerifier
+// if it's nil.
+func (pcc *ParsedCredentialCreationData) VerifyWithPolicy(trace *VerificationTrace, policy AttestationPolicy, storedChallenge string, verifyUser bool, relyingPartyID string, relyingPartyOrigins []string, originVerifier OriginVerifier) error {
+	if err := pcc.VerifyDeferred(trace, storedChallenge, verifyUser, relyingPartyID, relyingPartyOrigins, originVerifier); err != nil {
+		return err
+	}
 
-	// We do the above step while parsing and decoding the CredentialCreationResponse
-	// Handle steps 9 through 14 - This verifies the attestation object.
-	verifyError = pcc.Response.AttestationObject.Verify(relyingPartyID, clientDataHash[:], verifyUser)
-	if verifyError != nil {
-		return verifyError
+	// Handle steps 13 through 14 - This verifies the attestation statement.
+	if err := pcc.VerifyAttestationWithPolicy(trace, policy); err != nil {
+		return err
 	}
 
 	// Step 15. If validation is successful, obtain a list of acceptable trust anchors (attestation root
@@ -177,7 +215,8 @@ func (pcc *ParsedCredentialCreationData) Verify(storedChallenge string, verifyUs
 	// - Otherwise, use the X.509 certificates returned by the verification procedure to verify that the
 	//   attestation public key correctly chains up to an acceptable root certificate.
 
-	// TODO: We're not supporting trust anchors, self-attestation policy, or acceptable root certs yet.
+	// The attestation root certificates of the metadata statement are used as the trust anchors when
+	// AttestationPolicy.VerifyTrustPath is enabled. ECDAA and a self attestation policy are not supported yet.
 
 	// Step 17. Check that the credentialId is not yet registered to any other user. If registration is
 	// requested for a credential that is already registered to a different user, the Relying Party SHOULD
@@ -200,6 +239,68 @@ func (pcc *ParsedCredentialCreationData) Verify(storedChallenge string, verifyUs
 	return nil
 }
 
+// VerifyDeferred performs every step of VerifyWithPolicy except the verification of the attestation statement and the
+// metadata, i.e. it only verifies the client data and the authenticator data. The registration must not be considered
+// complete until VerifyAttestation has also succeeded.
+func (pcc *ParsedCredentialCreationData) VerifyDeferred(trace *VerificationTrace, storedChallenge string, verifyUser bool, relyingPartyID string, relyingPartyOrigins []string, originVerifier OriginVerifier) error {
+	// Step 7. Compute the hash of response.clientDataJSON using SHA-256.
+	clientDataHash := sha256.Sum256(pcc.Raw.AttestationResponse.ClientDataJSON)
+
+	// Handles steps 3 through 6 - Verifying the Client Data against the Relying Party's stored data
+	verifyError := pcc.Response.CollectedClientData.VerifyWithOriginVerifier(storedChallenge, CreateCeremony, relyingPartyOrigins, originVerifier)
+
+	if verifyError = trace.Step(VerificationStepClientData, verifyError, clientDataTraceInputs(pcc.Response.CollectedClientData, clientDataHash[:])); verifyError != nil {
+		return verifyError
+	}
+
+	// Step 8. Perform CBOR decoding on the attestationObject field of the AuthenticatorAttestationResponse
+	// structure to obtain the attestation statement format fmt, the authenticator data authData, and the
+	// attestation statement attStmt.
+
+	// We do the above step while parsing and decoding the CredentialCreationResponse
+	// Handle steps 9 through 12 - This verifies the authenticator data of the attestation object.
+	return pcc.Response.AttestationObject.verifyAuthData(trace, relyingPartyID, verifyUser)
+}
+
+// VerifyResponseJSON verifies the values which WebAuthn Level 3 clients duplicate from the attestation object in the
+// RegistrationResponseJSON are consistent with the attestation object. Values which are absent, such as in the
+// responses of Level 2 clients, are not verified.
+func (pcc *ParsedCredentialCreationData) VerifyResponseJSON() error {
+	raw := pcc.Raw.AttestationResponse
+
+	if len(raw.AuthenticatorData) != 0 && !bytes.Equal(raw.AuthenticatorData, pcc.Response.AttestationObject.RawAuthData) {
+		return ErrBadRequest.WithCode(CodeResponseInvalid).WithDetails("The authenticator data of the response does not match the attestation object")
+	}
+
+	if raw.PublicKeyAlgorithm != 0 {
+		var key webauthncose.PublicKeyData
+
+		if err := webauthncbor.Unmarshal(pcc.Response.AttestationObject.AuthData.AttData.CredentialPublicKey, &key); err != nil || key.Algorithm != raw.PublicKeyAlgorithm {
+			return ErrBadRequest.
+				WithCode(CodeResponseInvalid).
+				WithDetails("The public key algorithm of the response does not match the credential public key").
+				WithInfo(fmt.Sprintf("Expected Value: %d, Received: %d", key.Algorithm, raw.PublicKeyAlgorithm))
+		}
+	}
+
+	return nil
+}
+
+// VerifyAttestation performs the verification of the attestation statement and the metadata which is skipped by
+// VerifyDeferred. It may be performed asynchronously as it does not depend on any session state, but it may be slow
+// as it can involve verifying certificate chains.
+func (pcc *ParsedCredentialCreationData) VerifyAttestation(trace *VerificationTrace) error {
+	return pcc.VerifyAttestationWithPolicy(trace, AttestationPolicy{})
+}
+
+// VerifyAttestationWithPolicy is the same as VerifyAttestation except the metadata is verified according to the
+// provided AttestationPolicy.
+func (pcc *ParsedCredentialCreationData) VerifyAttestationWithPolicy(trace *VerificationTrace, policy AttestationPolicy) error {
+	clientDataHash := sha256.Sum256(pcc.Raw.AttestationResponse.ClientDataJSON)
+
+	return pcc.Response.AttestationObject.verifyAttestation(trace, clientDataHash[:], policy)
+}
+
 // GetAppID takes a AuthenticationExtensions object or nil. It then performs the following checks in order:
 //
 // 1. Check that the Session Data's AuthenticationExtensions has been provided and if it hasn't return an error.
@@ -236,7 +337,7 @@ func (ppkc ParsedPublicKeyCredential) GetAppID(authExt AuthenticationExtensions,
 	}
 
 	if enableAppID, ok = clientValue.(bool); !ok {
-		return "", ErrBadRequest.WithDetails("Client Output appid did not have the expected type")
+		return "", ErrBadRequest.WithCode(CodeAppIDInvalid).WithDetails("Client Output appid did not have the expected type")
 	}
 
 	if !enableAppID {
@@ -244,11 +345,11 @@ func (ppkc ParsedPublicKeyCredential) GetAppID(authExt AuthenticationExtensions,
 	}
 
 	if value, ok = authExt[ExtensionAppID]; !ok {
-		return "", ErrBadRequest.WithDetails("Session Data does not have an appid but Client Output indicates it should be set")
+		return "", ErrBadRequest.WithCode(CodeAppIDInvalid).WithDetails("Session Data does not have an appid but Client Output indicates it should be set")
 	}
 
 	if appID, ok = value.(string); !ok {
-		return "", ErrBadRequest.WithDetails("Session Data appid did not have the expected type")
+		return "", ErrBadRequest.WithCode(CodeAppIDInvalid).WithDetails("Session Data appid did not have the expected type")
 	}
 
 	return appID, nil
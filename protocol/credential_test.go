@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
 )
@@ -440,3 +441,52 @@ trailing
 }
 `,
 }
+
+func TestParsedCredentialCreationData_VerifyResponseJSON(t *testing.T) {
+	byteAuthData, _ := base64.RawURLEncoding.DecodeString("dKbqkhPJnC90siSSsyDPQCYqlMGpUKA5fyklC2CEHvBBAAAAAAAAAAAAAAAAAAAAAAAAAAAAQOsa7QYSUFukFOLTmgeK6x2ktirNMgwy_6vIwwtegxI2flS1X-JAkZL5dsadg-9bEz2J7PnsbB0B08txvsyUSvKlAQIDJiABIVggLKF5xS0_BntttUIrm2Z2tgZ4uQDwllbdIfrrBMABCNciWCDHwin8Zdkr56iSIh0MrB5qZiEzYLQpEOREhMUkY6q4Vw")
+	byteCredentialPubKey, _ := base64.RawURLEncoding.DecodeString("pSJYIMfCKfxl2SvnqJIiHQysHmpmITNgtCkQ5ESExSRjqrhXAQIDJiABIVggLKF5xS0_BntttUIrm2Z2tgZ4uQDwllbdIfrrBMABCNc")
+
+	testCases := []struct {
+		name      string
+		authData  []byte
+		algorithm int64
+		expected  ErrorCode
+	}{
+		{"ShouldPassLevel2", nil, 0, ""},
+		{"ShouldPassLevel3", byteAuthData, -7, ""},
+		{"ShouldFailAuthenticatorData", byteAuthData[:37], -7, CodeResponseInvalid},
+		{"ShouldFailPublicKeyAlgorithm", byteAuthData, -257, CodeResponseInvalid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pcc := &ParsedCredentialCreationData{
+				Response: ParsedAttestationResponse{
+					AttestationObject: AttestationObject{
+						RawAuthData: byteAuthData,
+						AuthData: AuthenticatorData{
+							AttData: AttestedCredentialData{CredentialPublicKey: byteCredentialPubKey},
+						},
+					},
+				},
+				Raw: CredentialCreationResponse{
+					AttestationResponse: AuthenticatorAttestationResponse{
+						AuthenticatorData:  tc.authData,
+						PublicKeyAlgorithm: tc.algorithm,
+					},
+				},
+			}
+
+			err := pcc.VerifyResponseJSON()
+
+			if tc.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				var e *Error
+
+				require.ErrorAs(t, err, &e)
+				assert.Equal(t, tc.expected, e.Code)
+			}
+		})
+	}
+}
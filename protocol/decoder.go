@@ -6,6 +6,56 @@ import (
 	"io"
 )
 
+// DefaultResponseBodyLimit is the maximum size in bytes of the response body read by ParseCredentialCreationResponse and
+// ParseCredentialRequestResponse. It's significantly larger than any legitimate response.
+const DefaultResponseBodyLimit int64 = 256 * 1024
+
+var errBodyTooLarge = errors.New("The body exceeds the size limit")
+
+// limitBody returns a reader which returns errBodyTooLarge if more than limit bytes are read from the body. Unlike
+// io.LimitReader this ensures a body which is too large results in an error instead of being silently truncated. If the
+// limit is less than 1 the body is returned as is.
+func limitBody(body io.Reader, limit int64) io.Reader {
+	if limit < 1 {
+		return body
+	}
+
+	return &limitedReader{r: body, n: limit}
+}
+
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (n int, err error) {
+	// Read one byte more than the remaining limit so a body which is exactly the limit is not rejected.
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+
+	n, err = l.r.Read(p)
+
+	if int64(n) <= l.n {
+		l.n -= int64(n)
+
+		return n, err
+	}
+
+	n, l.n = int(l.n), 0
+
+	return n, errBodyTooLarge
+}
+
+// decodeBodyError returns the *Error for an error returned by decodeBody.
+func decodeBodyError(err error, details string) *Error {
+	if errors.Is(err, errBodyTooLarge) {
+		return ErrBadRequest.WithCode(CodeResponseTooLarge).WithDetails(details).WithInfo(err.Error())
+	}
+
+	return ErrBadRequest.WithCode(CodeResponseInvalid).WithDetails(details).WithInfo(err.Error())
+}
+
 func decodeBody(body io.Reader, v any) (err error) {
 	decoder := json.NewDecoder(body)
 
@@ -0,0 +1,127 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitBody(t *testing.T) {
+	testCases := []struct {
+		name  string
+		body  string
+		limit int64
+		err   error
+	}{
+		{"ShouldReadBodyBelowLimit", "abc", 4, nil},
+		{"ShouldReadBodyAtLimit", "abcd", 4, nil},
+		{"ShouldErrorBodyAboveLimit", "abcde", 4, errBodyTooLarge},
+		{"ShouldNotLimitZero", "abcde", 0, nil},
+		{"ShouldNotLimitNegative", "abcde", -1, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := io.ReadAll(limitBody(strings.NewReader(tc.body), tc.limit))
+
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err))
+				assert.Len(t, data, int(tc.limit))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.body, string(data))
+			}
+		})
+	}
+}
+
+func TestParseCredentialResponseWithLimit(t *testing.T) {
+	body := `{"id":"` + strings.Repeat("a", 1024) + `"}`
+
+	testCases := []struct {
+		name  string
+		parse func(r *http.Request, limit int64) error
+	}{
+		{
+			"ShouldLimitCreationResponse",
+			func(r *http.Request, limit int64) error {
+				_, err := ParseCredentialCreationResponseWithLimit(r, limit)
+
+				return err
+			},
+		},
+		{
+			"ShouldLimitRequestResponse",
+			func(r *http.Request, limit int64) error {
+				_, err := ParseCredentialRequestResponseWithLimit(r, limit)
+
+				return err
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.parse(httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body)), 512)
+
+			var e *Error
+
+			require.True(t, errors.As(err, &e))
+			assert.Equal(t, CodeResponseTooLarge, e.Code)
+
+			err = tc.parse(httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body)), 4096)
+
+			require.True(t, errors.As(err, &e))
+			assert.NotEqual(t, CodeResponseTooLarge, e.Code)
+		})
+	}
+}
+
+func TestParseCredentialResponseBytesWithLimit(t *testing.T) {
+	body := []byte(`{"id":"` + strings.Repeat("a", 1024) + `"}`)
+
+	testCases := []struct {
+		name  string
+		parse func(data []byte, limit int64) error
+	}{
+		{
+			"ShouldLimitCreationResponse",
+			func(data []byte, limit int64) error {
+				_, err := ParseCredentialCreationResponseBytesWithLimit(data, limit)
+
+				return err
+			},
+		},
+		{
+			"ShouldLimitRequestResponse",
+			func(data []byte, limit int64) error {
+				_, err := ParseCredentialRequestResponseBytesWithLimit(data, limit)
+
+				return err
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var e *Error
+
+			require.True(t, errors.As(tc.parse(body, 512), &e))
+			assert.Equal(t, CodeResponseTooLarge, e.Code)
+
+			require.True(t, errors.As(tc.parse(body, 4096), &e))
+			assert.NotEqual(t, CodeResponseTooLarge, e.Code)
+
+			require.True(t, errors.As(tc.parse(nil, 4096), &e))
+			assert.Equal(t, CodeResponseInvalid, e.Code)
+			assert.Equal(t, "No response given", e.Details)
+		})
+	}
+}
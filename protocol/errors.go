@@ -9,8 +9,188 @@ type Error struct {
 
 	// Information to help debug the error.
 	DevInfo string `json:"debug"`
+
+	// Code is a stable machine-readable code which identifies the specific reason for the error. Unlike Details the
+	// value of Code will not change between releases, so it is suitable for mapping to localized user-facing messages
+	// and metrics labels. It is empty when no specific code applies, in which case Type should be used instead.
+	Code ErrorCode `json:"code,omitempty"`
 }
 
+// ErrorCode is a stable machine-readable code which identifies the specific reason for an Error.
+type ErrorCode string
+
+const (
+	// CodeCeremonyMismatch indicates the clientData type did not match the expected ceremony.
+	CodeCeremonyMismatch ErrorCode = "ceremony_mismatch"
+
+	// CodeChallengeMismatch indicates the clientData challenge did not match the stored challenge.
+	CodeChallengeMismatch ErrorCode = "challenge_mismatch"
+
+	// CodeChallengeBindingMismatch indicates the clientData challenge was not derived from the expected binding.
+	CodeChallengeBindingMismatch ErrorCode = "challenge_binding_mismatch"
+
+	// CodeChallengeReplayed indicates the challenge was already used by a ceremony which completed verification.
+	CodeChallengeReplayed ErrorCode = "challenge_replayed"
+
+	// CodeOriginInvalid indicates the clientData origin could not be parsed.
+	CodeOriginInvalid ErrorCode = "origin_invalid"
+
+	// CodeOriginMismatch indicates the clientData origin did not match any of the allowed origins.
+	CodeOriginMismatch ErrorCode = "origin_mismatch"
+
+	// CodeTopOriginMismatch indicates the clientData was cross-origin and its top origin did not match any of the
+	// allowed top origins.
+	CodeTopOriginMismatch ErrorCode = "top_origin_mismatch"
+
+	// CodeTokenBindingInvalid indicates the clientData token binding was malformed.
+	CodeTokenBindingInvalid ErrorCode = "token_binding_invalid"
+
+	// CodeTokenBindingMismatch indicates the clientData token binding did not match the state of the connection.
+	CodeTokenBindingMismatch ErrorCode = "token_binding_mismatch"
+
+	// CodeTokenBindingRequired indicates token binding was required but not used on the connection.
+	CodeTokenBindingRequired ErrorCode = "token_binding_required"
+
+	// CodeRPIDHashMismatch indicates the authenticator data RP ID hash did not match the expected RP ID.
+	CodeRPIDHashMismatch ErrorCode = "rp_id_hash_mismatch"
+
+	// CodeUPRequired indicates user presence was required but the authenticator did not set the flag.
+	CodeUPRequired ErrorCode = "up_required"
+
+	// CodeUVRequired indicates user verification was required but the authenticator did not set the flag.
+	CodeUVRequired ErrorCode = "uv_required"
+
+	// CodeAuthDataInvalid indicates the authenticator data was malformed.
+	CodeAuthDataInvalid ErrorCode = "auth_data_invalid"
+
+	// CodeResponseInvalid indicates the credential response was missing or malformed.
+	CodeResponseInvalid ErrorCode = "response_invalid"
+
+	// CodeResponseTooLarge indicates the credential response body exceeded the size limit.
+	CodeResponseTooLarge ErrorCode = "response_too_large"
+
+	// CodePublicKeyInvalid indicates the stored credential public key could not be parsed.
+	CodePublicKeyInvalid ErrorCode = "public_key_invalid"
+
+	// CodeSignatureInvalid indicates the assertion signature did not verify.
+	CodeSignatureInvalid ErrorCode = "signature_invalid"
+
+	// CodeAttestationFormatUnsupported indicates the attestation statement format is not supported.
+	CodeAttestationFormatUnsupported ErrorCode = "attestation_format_unsupported"
+
+	// CodeAttestationInvalid indicates the attestation statement failed verification.
+	CodeAttestationInvalid ErrorCode = "attestation_invalid"
+
+	// CodeAuthenticatorStatusUndesired indicates the metadata for the authenticator reports an undesired status.
+	CodeAuthenticatorStatusUndesired ErrorCode = "authenticator_status_undesired"
+
+	// CodeCertificationLevelInsufficient indicates the metadata for the authenticator does not report the required FIDO
+	// certification level.
+	CodeCertificationLevelInsufficient ErrorCode = "certification_level_insufficient"
+
+	// CodeAuthenticatorVersionInsufficient indicates the metadata for the authenticator reports a firmware version lower
+	// than the minimum version required for its AAGUID.
+	CodeAuthenticatorVersionInsufficient ErrorCode = "authenticator_version_insufficient"
+
+	// CodeKeySecurityLevelInsufficient indicates the attestation reports the credential key is not hardware backed.
+	CodeKeySecurityLevelInsufficient ErrorCode = "key_security_level_insufficient"
+
+	// CodeAttestationStale indicates the attestation statement was generated too long ago or in the future, which
+	// indicates it's being replayed.
+	CodeAttestationStale ErrorCode = "attestation_stale"
+
+	// CodeAttestationUnverifiable indicates the attestation statement does not include an attestation certificate which
+	// is required by the policy.
+	CodeAttestationUnverifiable ErrorCode = "attestation_unverifiable"
+
+	// CodeAttestationAnonymized indicates the attestation statement was anonymized by an attestation CA which is
+	// rejected by the policy.
+	CodeAttestationAnonymized ErrorCode = "attestation_anonymized"
+
+	// CodeAttestationChainIncomplete indicates the x5c of the attestation statement does not include the complete
+	// certificate chain which is required by the policy.
+	CodeAttestationChainIncomplete ErrorCode = "attestation_chain_incomplete"
+
+	// CodeAuthenticatorUnknown indicates the authenticator AAGUID was not found in the metadata.
+	CodeAuthenticatorUnknown ErrorCode = "authenticator_unknown"
+
+	// CodeAppIDInvalid indicates the appid extension output or session value was malformed.
+	CodeAppIDInvalid ErrorCode = "appid_invalid"
+
+	// CodeUserSessionMismatch indicates the user ID did not match the user ID in the session.
+	CodeUserSessionMismatch ErrorCode = "user_session_mismatch"
+
+	// CodeSessionExpired indicates the session expired before the ceremony was completed.
+	CodeSessionExpired ErrorCode = "session_expired"
+
+	// CodeSessionNotDiscoverable indicates a discoverable login was attempted with a non-discoverable session.
+	CodeSessionNotDiscoverable ErrorCode = "session_not_discoverable"
+
+	// CodeSessionNotStepUp indicates a step-up login was finished with a session which was not started as one.
+	CodeSessionNotStepUp ErrorCode = "session_not_step_up"
+
+	// CodeStepUpProofInvalid indicates a step-up proof was malformed, forged, or belongs to a different user.
+	CodeStepUpProofInvalid ErrorCode = "step_up_proof_invalid"
+
+	// CodeStepUpProofExpired indicates a step-up proof is older than the maximum age.
+	CodeStepUpProofExpired ErrorCode = "step_up_proof_expired"
+
+	// CodeNoCredentials indicates the user has no registered credentials.
+	CodeNoCredentials ErrorCode = "no_credentials"
+
+	// CodeUserHandleMissing indicates a discoverable login response did not include a user handle.
+	CodeUserHandleMissing ErrorCode = "user_handle_missing"
+
+	// CodeUserHandleMismatch indicates the returned user handle did not match the user ID.
+	CodeUserHandleMismatch ErrorCode = "user_handle_mismatch"
+
+	// CodeUserNotFound indicates the user for a discoverable login could not be found.
+	CodeUserNotFound ErrorCode = "user_not_found"
+
+	// CodeUserInvalid indicates the user entity of a registration is malformed, for example because the user ID is
+	// empty or too long.
+	CodeUserInvalid ErrorCode = "user_invalid"
+
+	// CodeCredentialNotAllowed indicates the credential is not owned by the user or not in the allowed credentials.
+	CodeCredentialNotAllowed ErrorCode = "credential_not_allowed"
+
+	// CodeTransportNotAllowed indicates the credential was not registered with any of the transports allowed by the
+	// session.
+	CodeTransportNotAllowed ErrorCode = "transport_not_allowed"
+
+	// CodeCounterRegressed indicates the signature counter did not increase and the CounterPolicy rejects this.
+	CodeCounterRegressed ErrorCode = "counter_regressed"
+
+	// CodeCredentialNotFound indicates the returned credential ID does not match a credential of the user.
+	CodeCredentialNotFound ErrorCode = "credential_not_found"
+
+	// CodeBackupEligibilityChanged indicates the authenticator data backup eligible flag did not match the backup
+	// eligibility of the stored credential.
+	CodeBackupEligibilityChanged ErrorCode = "backup_eligibility_changed"
+
+	// CodeAlgorithmNotAllowed indicates the credential public key algorithm is not allowed, for example because it's
+	// not FIPS approved.
+	CodeAlgorithmNotAllowed ErrorCode = "algorithm_not_allowed"
+
+	// CodeCredentialInvalid indicates a stored credential is malformed, for example because its flags or transports are
+	// inconsistent.
+	CodeCredentialInvalid ErrorCode = "credential_invalid"
+
+	// CodePolicyRejected indicates a policy hook configured by the Relying Party rejected the ceremony.
+	CodePolicyRejected ErrorCode = "policy_rejected"
+
+	// CodeTrustPolicyRejected indicates a rule of the attestation trust policy configured by the Relying Party rejected
+	// the registration.
+	CodeTrustPolicyRejected ErrorCode = "trust_policy_rejected"
+
+	// CodeCredentialBlocked indicates the credential or its attestation certificate is known to be compromised
+	// according to the BlocklistProvider configured by the Relying Party.
+	CodeCredentialBlocked ErrorCode = "credential_blocked"
+
+	// CodeRateLimited indicates the RateLimiter configured by the Relying Party throttled the beginning of a ceremony.
+	CodeRateLimited ErrorCode = "rate_limited"
+)
+
 var (
 	ErrBadRequest = &Error{
 		Type:    "invalid_request",
@@ -68,8 +248,35 @@ var (
 		Type:    "not_implemented",
 		Details: "This field is not yet supported by this library",
 	}
+	ErrSessionExpired = &Error{
+		Type:    "session_expired",
+		Details: "Session has expired",
+	}
+	ErrPolicy = &Error{
+		Type:    "policy_error",
+		Details: "The ceremony was rejected by the Relying Party policy",
+	}
 )
 
+// errorTypes is the list of the base errors which is used to determine the generic description of an error Type.
+var errorTypes = []*Error{
+	ErrBadRequest,
+	ErrChallengeMismatch,
+	ErrParsingData,
+	ErrAuthData,
+	ErrVerification,
+	ErrAttestation,
+	ErrInvalidAttestation,
+	ErrAttestationCertificate,
+	ErrAssertionSignature,
+	ErrUnsupportedKey,
+	ErrUnsupportedAlgorithm,
+	ErrNotSpecImplemented,
+	ErrNotImplemented,
+	ErrSessionExpired,
+	ErrPolicy,
+}
+
 func (e *Error) Error() string {
 	return e.Details
 }
@@ -87,3 +294,34 @@ func (e *Error) WithInfo(info string) *Error {
 
 	return &err
 }
+
+// WithCode returns a copy of the Error with the Code set to the provided ErrorCode.
+func (e *Error) WithCode(code ErrorCode) *Error {
+	err := *e
+	err.Code = code
+
+	return &err
+}
+
+// Redacted returns a copy of the Error with the Details replaced by the generic description of the error Type and the
+// DevInfo removed. This is intended for errors which are returned to untrusted parties, as the Details and DevInfo
+// may contain values such as the challenge or the origin.
+//
+// The Code is retained as it is a stable value which does not include any request specific values.
+func (e *Error) Redacted() *Error {
+	err := *e
+	err.Details = errorTypeDetails(e.Type)
+	err.DevInfo = ""
+
+	return &err
+}
+
+func errorTypeDetails(errType string) string {
+	for _, e := range errorTypes {
+		if e.Type == errType {
+			return e.Details
+		}
+	}
+
+	return "Error processing the request"
+}
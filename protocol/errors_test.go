@@ -0,0 +1,48 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError_Redacted(t *testing.T) {
+	testCases := []struct {
+		name     string
+		have     *Error
+		expected *Error
+	}{
+		{
+			"ShouldRedactDetailsAndInfo",
+			ErrVerification.WithDetails("Error validating challenge").WithInfo("Expected b Value: \"abc\""),
+			&Error{Type: "verification_error", Details: "Error validating the authenticator response"},
+		},
+		{
+			"ShouldRedactUnknownType",
+			&Error{Type: "example", Details: "Example", DevInfo: "Example Info"},
+			&Error{Type: "example", Details: "Error processing the request"},
+		},
+		{
+			"ShouldRetainCode",
+			ErrVerification.WithCode(CodeUVRequired).WithInfo("User verification required but flag not set by authenticator"),
+			&Error{Type: "verification_error", Details: "Error validating the authenticator response", Code: CodeUVRequired},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := tc.have.Redacted()
+
+			assert.Equal(t, tc.expected, actual)
+			assert.NotEqual(t, tc.have, actual)
+		})
+	}
+}
+
+func TestError_WithCode(t *testing.T) {
+	err := ErrVerification.WithCode(CodeChallengeMismatch)
+
+	assert.Equal(t, CodeChallengeMismatch, err.Code)
+	assert.Equal(t, ErrVerification.Details, err.Details)
+	assert.Equal(t, ErrorCode(""), ErrVerification.Code)
+}
@@ -10,4 +10,5 @@ type AuthenticationExtensionsClientOutputs map[string]interface{}
 const (
 	ExtensionAppID        = "appid"
 	ExtensionAppIDExclude = "appidExclude"
+	ExtensionUVM          = "uvm"
 )
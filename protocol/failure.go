@@ -0,0 +1,142 @@
+package protocol
+
+import (
+	"errors"
+)
+
+// FailureReason is a coarse classification of why a ceremony failed. Unlike the ErrorCode it groups related failures
+// together and is intended as a precise signal for fraud and risk systems.
+type FailureReason string
+
+const (
+	// FailureReasonNone indicates there was no failure.
+	FailureReasonNone FailureReason = ""
+
+	// FailureReasonUnknown indicates the failure could not be classified.
+	FailureReasonUnknown FailureReason = "unknown"
+
+	// FailureReasonMalformedRequest indicates the response was missing, malformed, or not for the expected ceremony.
+	FailureReasonMalformedRequest FailureReason = "malformed_request"
+
+	// FailureReasonBadChallenge indicates the challenge did not match.
+	FailureReasonBadChallenge FailureReason = "bad_challenge"
+
+	// FailureReasonBadOrigin indicates the origin was not allowed.
+	FailureReasonBadOrigin FailureReason = "bad_origin"
+
+	// FailureReasonBadTokenBinding indicates the token binding did not match the connection or was required but not
+	// used.
+	FailureReasonBadTokenBinding FailureReason = "bad_token_binding"
+
+	// FailureReasonBadRPID indicates the RP ID hash did not match.
+	FailureReasonBadRPID FailureReason = "bad_rp_id"
+
+	// FailureReasonUPMissing indicates the user was not present.
+	FailureReasonUPMissing FailureReason = "up_missing"
+
+	// FailureReasonUVMissing indicates user verification was required but not performed.
+	FailureReasonUVMissing FailureReason = "uv_missing"
+
+	// FailureReasonUnknownCredential indicates the credential or user was not found or is not allowed.
+	FailureReasonUnknownCredential FailureReason = "unknown_credential"
+
+	// FailureReasonUserMismatch indicates the user did not match the session or the user handle.
+	FailureReasonUserMismatch FailureReason = "user_mismatch"
+
+	// FailureReasonSessionExpired indicates the session expired.
+	FailureReasonSessionExpired FailureReason = "session_expired"
+
+	// FailureReasonSignatureInvalid indicates the signature or the public key used to verify it was invalid.
+	FailureReasonSignatureInvalid FailureReason = "signature_invalid"
+
+	// FailureReasonStaleCounter indicates the signature counter did not increase.
+	FailureReasonStaleCounter FailureReason = "stale_counter"
+
+	// FailureReasonAttestationRejected indicates the attestation was invalid or the authenticator is not trusted.
+	FailureReasonAttestationRejected FailureReason = "attestation_rejected"
+
+	// FailureReasonPolicyRejected indicates a policy of the Relying Party rejected the ceremony.
+	FailureReasonPolicyRejected FailureReason = "policy_rejected"
+
+	// FailureReasonRateLimited indicates the ceremony was throttled by the Relying Party.
+	FailureReasonRateLimited FailureReason = "rate_limited"
+)
+
+var failureReasons = map[ErrorCode]FailureReason{
+	CodeCeremonyMismatch:                 FailureReasonMalformedRequest,
+	CodeChallengeMismatch:                FailureReasonBadChallenge,
+	CodeChallengeBindingMismatch:         FailureReasonBadChallenge,
+	CodeChallengeReplayed:                FailureReasonBadChallenge,
+	CodeOriginInvalid:                    FailureReasonMalformedRequest,
+	CodeOriginMismatch:                   FailureReasonBadOrigin,
+	CodeTopOriginMismatch:                FailureReasonBadOrigin,
+	CodeTokenBindingInvalid:              FailureReasonMalformedRequest,
+	CodeTokenBindingMismatch:             FailureReasonBadTokenBinding,
+	CodeTokenBindingRequired:             FailureReasonBadTokenBinding,
+	CodeRPIDHashMismatch:                 FailureReasonBadRPID,
+	CodeUPRequired:                       FailureReasonUPMissing,
+	CodeUVRequired:                       FailureReasonUVMissing,
+	CodeAuthDataInvalid:                  FailureReasonMalformedRequest,
+	CodeResponseInvalid:                  FailureReasonMalformedRequest,
+	CodeResponseTooLarge:                 FailureReasonMalformedRequest,
+	CodePublicKeyInvalid:                 FailureReasonSignatureInvalid,
+	CodeSignatureInvalid:                 FailureReasonSignatureInvalid,
+	CodeAttestationFormatUnsupported:     FailureReasonAttestationRejected,
+	CodeAttestationInvalid:               FailureReasonAttestationRejected,
+	CodeAuthenticatorStatusUndesired:     FailureReasonAttestationRejected,
+	CodeAuthenticatorUnknown:             FailureReasonAttestationRejected,
+	CodeCertificationLevelInsufficient:   FailureReasonAttestationRejected,
+	CodeAuthenticatorVersionInsufficient: FailureReasonAttestationRejected,
+	CodeKeySecurityLevelInsufficient:     FailureReasonAttestationRejected,
+	CodeAttestationUnverifiable:          FailureReasonAttestationRejected,
+	CodeAttestationAnonymized:            FailureReasonAttestationRejected,
+	CodeAttestationChainIncomplete:       FailureReasonAttestationRejected,
+	CodeAttestationStale:                 FailureReasonAttestationRejected,
+	CodeAppIDInvalid:                     FailureReasonMalformedRequest,
+	CodeUserSessionMismatch:              FailureReasonUserMismatch,
+	CodeSessionExpired:                   FailureReasonSessionExpired,
+	CodeSessionNotDiscoverable:           FailureReasonUserMismatch,
+	CodeSessionNotStepUp:                 FailureReasonMalformedRequest,
+	CodeStepUpProofInvalid:               FailureReasonMalformedRequest,
+	CodeStepUpProofExpired:               FailureReasonSessionExpired,
+	CodeNoCredentials:                    FailureReasonUnknownCredential,
+	CodeUserHandleMissing:                FailureReasonMalformedRequest,
+	CodeUserHandleMismatch:               FailureReasonUserMismatch,
+	CodeUserNotFound:                     FailureReasonUnknownCredential,
+	CodeUserInvalid:                      FailureReasonMalformedRequest,
+	CodeCredentialNotAllowed:             FailureReasonUnknownCredential,
+	CodeTransportNotAllowed:              FailureReasonUnknownCredential,
+	CodeCounterRegressed:                 FailureReasonStaleCounter,
+	CodeCredentialNotFound:               FailureReasonUnknownCredential,
+	CodeBackupEligibilityChanged:         FailureReasonUnknownCredential,
+	CodeAlgorithmNotAllowed:              FailureReasonSignatureInvalid,
+	CodePolicyRejected:                   FailureReasonPolicyRejected,
+	CodeTrustPolicyRejected:              FailureReasonPolicyRejected,
+	CodeCredentialBlocked:                FailureReasonPolicyRejected,
+	CodeRateLimited:                      FailureReasonRateLimited,
+}
+
+// FailureReason returns the FailureReason for the Error derived from its Code.
+func (e *Error) FailureReason() FailureReason {
+	if reason, ok := failureReasons[e.Code]; ok {
+		return reason
+	}
+
+	return FailureReasonUnknown
+}
+
+// GetFailureReason returns the FailureReason of the provided error. It returns FailureReasonNone if the error is nil
+// and FailureReasonUnknown if the error is not an *Error or could not be classified.
+func GetFailureReason(err error) FailureReason {
+	if err == nil {
+		return FailureReasonNone
+	}
+
+	var e *Error
+
+	if errors.As(err, &e) {
+		return e.FailureReason()
+	}
+
+	return FailureReasonUnknown
+}
@@ -0,0 +1,36 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFailureReason(t *testing.T) {
+	testCases := []struct {
+		name     string
+		have     error
+		expected FailureReason
+	}{
+		{"ShouldHandleNil", nil, FailureReasonNone},
+		{"ShouldHandleNonProtocolError", errors.New("example"), FailureReasonUnknown},
+		{"ShouldHandleErrorWithoutCode", ErrBadRequest, FailureReasonUnknown},
+		{"ShouldHandleOrigin", ErrVerification.WithCode(CodeOriginMismatch), FailureReasonBadOrigin},
+		{"ShouldHandleUV", ErrVerification.WithCode(CodeUVRequired), FailureReasonUVMissing},
+		{"ShouldHandleCounter", ErrVerification.WithCode(CodeCounterRegressed), FailureReasonStaleCounter},
+		{"ShouldHandleRateLimited", ErrPolicy.WithCode(CodeRateLimited), FailureReasonRateLimited},
+		{"ShouldHandleTokenBinding", ErrVerification.WithCode(CodeTokenBindingMismatch), FailureReasonBadTokenBinding},
+		{"ShouldHandlePolicy", ErrPolicy.WithCode(CodePolicyRejected), FailureReasonPolicyRejected},
+		{"ShouldHandleCredential", ErrBadRequest.WithCode(CodeCredentialNotFound), FailureReasonUnknownCredential},
+		{"ShouldHandleSignature", ErrAssertionSignature.WithCode(CodeSignatureInvalid), FailureReasonSignatureInvalid},
+		{"ShouldHandleWrapped", fmt.Errorf("wrapped: %w", ErrVerification.WithCode(CodeChallengeMismatch)), FailureReasonBadChallenge},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, GetFailureReason(tc.have))
+		})
+	}
+}
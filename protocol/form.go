@@ -0,0 +1,83 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+)
+
+// ContentTypeForm is the Content-Type of form encoded credential responses. See ParseCredentialCreationResponse.
+const ContentTypeForm = "application/x-www-form-urlencoded"
+
+var (
+	formCredentialFields = []string{"id", "rawId", "type", "authenticatorAttachment"}
+	formResponseFields   = []string{"clientDataJSON", "attestationObject", "authenticatorData", "signature", "userHandle", "publicKey"}
+)
+
+// requestBody returns the body of the request as a JSON encoded response, which is the body itself unless the
+// Content-Type of the request is ContentTypeForm.
+func requestBody(response *http.Request, body io.Reader) (io.Reader, error) {
+	mediaType, _, err := mime.ParseMediaType(response.Header.Get("Content-Type"))
+	if err != nil || mediaType != ContentTypeForm {
+		return body, nil
+	}
+
+	return formBody(body)
+}
+
+// formBody converts a form encoded response to the equivalent JSON encoded response. The form has a field per value
+// as posted by frontends which don't encode the response as JSON, i.e. the fields of the PublicKeyCredential such as
+// id and rawId, the fields of the response such as clientDataJSON and signature without a prefix, a transports field
+// per transport, and the clientExtensionResults as a JSON encoded object. Other fields such as CSRF tokens are ignored.
+func formBody(body io.Reader) (io.Reader, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	credential := map[string]interface{}{}
+	response := map[string]interface{}{}
+
+	for _, name := range formCredentialFields {
+		if values.Has(name) {
+			credential[name] = values.Get(name)
+		}
+	}
+
+	for _, name := range formResponseFields {
+		if values.Has(name) {
+			response[name] = values.Get(name)
+		}
+	}
+
+	if transports, ok := values["transports"]; ok {
+		response["transports"] = transports
+	}
+
+	if values.Has("clientExtensionResults") {
+		extensions := json.RawMessage(values.Get("clientExtensionResults"))
+
+		if !json.Valid(extensions) {
+			return nil, errors.New("The clientExtensionResults field is not valid JSON")
+		}
+
+		credential["clientExtensionResults"] = extensions
+	}
+
+	credential["response"] = response
+
+	if data, err = json.Marshal(credential); err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(data), nil
+}
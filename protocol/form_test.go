@@ -0,0 +1,95 @@
+package protocol
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// formResponse encodes the JSON encoded response as a form with a field per value.
+func formResponse(t *testing.T, response string) url.Values {
+	var fields map[string]interface{}
+
+	require.NoError(t, json.Unmarshal([]byte(response), &fields))
+
+	values := url.Values{}
+
+	for name, value := range fields {
+		switch v := value.(type) {
+		case string:
+			values.Set(name, v)
+		case map[string]interface{}:
+			if name != "response" {
+				data, err := json.Marshal(v)
+				require.NoError(t, err)
+
+				values.Set(name, string(data))
+
+				continue
+			}
+
+			for field, inner := range v {
+				switch i := inner.(type) {
+				case string:
+					values.Set(field, i)
+				case []interface{}:
+					for _, transport := range i {
+						values.Add(field, transport.(string))
+					}
+				}
+			}
+		}
+	}
+
+	return values
+}
+
+func newFormRequest(values url.Values, contentType string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(values.Encode()))
+
+	r.Header.Set("Content-Type", contentType)
+
+	return r
+}
+
+func TestParseCredentialCreationResponse_Form(t *testing.T) {
+	expected, err := ParseCredentialCreationResponseBytes([]byte(testCredentialRequestResponses["success"]))
+	require.NoError(t, err)
+
+	values := formResponse(t, testCredentialRequestResponses["success"])
+
+	values.Set("csrf_token", "ignored")
+
+	actual, err := ParseCredentialCreationResponse(newFormRequest(values, ContentTypeForm+"; charset=utf-8"))
+	require.NoError(t, err)
+
+	assert.Equal(t, expected.ParsedPublicKeyCredential, actual.ParsedPublicKeyCredential)
+	assert.Equal(t, expected.Response, actual.Response)
+
+	_, err = ParseCredentialCreationResponse(newFormRequest(values, "application/json"))
+	assert.Equal(t, CodeResponseInvalid, err.(*Error).Code)
+
+	values.Set("clientExtensionResults", "{")
+
+	_, err = ParseCredentialCreationResponse(newFormRequest(values, ContentTypeForm))
+	require.Error(t, err)
+	assert.Equal(t, CodeResponseInvalid, err.(*Error).Code)
+	assert.Equal(t, "The clientExtensionResults field is not valid JSON", err.(*Error).DevInfo)
+}
+
+func TestParseCredentialRequestResponse_Form(t *testing.T) {
+	expected, err := ParseCredentialRequestResponseBytes([]byte(testAssertionResponses["success"]))
+	require.NoError(t, err)
+
+	actual, err := ParseCredentialRequestResponse(newFormRequest(formResponse(t, testAssertionResponses["success"]), ContentTypeForm))
+	require.NoError(t, err)
+
+	assert.Equal(t, expected.ParsedPublicKeyCredential, actual.ParsedPublicKeyCredential)
+	assert.Equal(t, expected.Response, actual.Response)
+}
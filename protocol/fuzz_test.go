@@ -0,0 +1,199 @@
+package protocol
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+)
+
+// fuzzCreationResponses returns the registration responses of the tests which were captured from real browsers and
+// authenticators. They're used to seed the corpus of the fuzz targets.
+func fuzzCreationResponses() (responses []string) {
+	responses = append(responses, testAttestationResponses...)
+	responses = append(responses, testAttestationTPMResponses...)
+
+	for _, m := range []map[string]string{
+		androidKeyTestResponse0,
+		androidKeyTestResponse1,
+		appleTestResponse,
+		packedTestResponseES256,
+		packedTestResponseES512,
+		packedTestResponseSolo2,
+		safetyNetTestResponse,
+		u2fTestResponse,
+	} {
+		keys := make([]string, 0, len(m))
+
+		for key := range m {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			responses = append(responses, m[key])
+		}
+	}
+
+	return responses
+}
+
+// fuzzAttestationObjects returns the raw attestation objects and client data of the seed registration responses.
+func fuzzAttestationObjects(f *testing.F) (attestationObjects, clientData [][]byte) {
+	for _, response := range fuzzCreationResponses() {
+		var ccr CredentialCreationResponse
+
+		if err := json.Unmarshal([]byte(response), &ccr); err != nil {
+			f.Fatal(err)
+		}
+
+		attestationObjects = append(attestationObjects, ccr.AttestationResponse.AttestationObject)
+		clientData = append(clientData, ccr.AttestationResponse.ClientDataJSON)
+	}
+
+	return attestationObjects, clientData
+}
+
+func FuzzParseCredentialCreationResponseBody(f *testing.F) {
+	for _, response := range fuzzCreationResponses() {
+		f.Add([]byte(response))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		pcc, err := ParseCredentialCreationResponseBody(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+
+		_ = pcc.Verify("challenge", true, "example.com", []string{"https://example.com"})
+	})
+}
+
+func FuzzParseCredentialRequestResponseBody(f *testing.F) {
+	for _, response := range testAssertionResponses {
+		f.Add([]byte(response))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		par, err := ParseCredentialRequestResponseBody(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+
+		_ = par.Verify("challenge", "example.com", []string{"https://example.com"}, "", false, nil)
+	})
+}
+
+func FuzzCollectedClientData(f *testing.F) {
+	_, clientData := fuzzAttestationObjects(f)
+
+	for _, data := range clientData {
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var c CollectedClientData
+
+		if err := json.Unmarshal(data, &c); err != nil {
+			return
+		}
+
+		_ = c.Verify(c.Challenge, CreateCeremony, []string{c.Origin})
+		_ = c.Verify("challenge", AssertCeremony, []string{"https://example.com"})
+	})
+}
+
+func FuzzAuthenticatorData(f *testing.F) {
+	attestationObjects, _ := fuzzAttestationObjects(f)
+
+	for _, raw := range attestationObjects {
+		var att AttestationObject
+
+		if err := webauthncbor.Unmarshal(raw, &att); err != nil {
+			f.Fatal(err)
+		}
+
+		f.Add(att.RawAuthData)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var a AuthenticatorData
+
+		if err := a.Unmarshal(data); err != nil {
+			return
+		}
+
+		_ = a.Verify(a.RPIDHash, nil, true)
+	})
+}
+
+func FuzzParsePublicKey(f *testing.F) {
+	attestationObjects, _ := fuzzAttestationObjects(f)
+
+	for _, raw := range attestationObjects {
+		var att AttestationObject
+
+		if err := webauthncbor.Unmarshal(raw, &att); err != nil {
+			f.Fatal(err)
+		}
+
+		if err := att.AuthData.Unmarshal(att.RawAuthData); err != nil {
+			f.Fatal(err)
+		}
+
+		f.Add(att.AuthData.AttData.CredentialPublicKey, att.RawAuthData, []byte{0x30, 0x06, 0x02, 0x01, 0x01, 0x02, 0x01, 0x01})
+	}
+
+	f.Fuzz(func(t *testing.T, keyBytes, data, signature []byte) {
+		key, err := webauthncose.ParsePublicKey(keyBytes)
+		if err != nil {
+			return
+		}
+
+		_, _ = webauthncose.VerifySignature(key, data, signature)
+		_ = webauthncose.DisplayPublicKey(keyBytes)
+	})
+}
+
+func FuzzAttestationFormats(f *testing.F) {
+	attestationObjects, clientData := fuzzAttestationObjects(f)
+
+	for i := range attestationObjects {
+		clientDataHash := sha256.Sum256(clientData[i])
+
+		f.Add(attestationObjects[i], clientDataHash[:])
+	}
+
+	formats := make([]string, 0, len(attestationRegistry))
+
+	for format := range attestationRegistry {
+		formats = append(formats, format)
+	}
+
+	sort.Strings(formats)
+
+	f.Fuzz(func(t *testing.T, data, clientDataHash []byte) {
+		var att AttestationObject
+
+		if err := webauthncbor.Unmarshal(data, &att); err != nil {
+			return
+		}
+
+		if err := att.AuthData.Unmarshal(att.RawAuthData); err != nil {
+			return
+		}
+
+		// Every attestation statement is verified by every format so that a statement of one format is also used as
+		// malformed input for the other formats.
+		for _, format := range formats {
+			att.Format = format
+
+			_, _, _ = attestationRegistry[format](att, clientDataHash)
+		}
+	})
+}
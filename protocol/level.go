@@ -0,0 +1,26 @@
+package protocol
+
+// SpecLevel is the level of the WebAuthn specification the ceremonies conform to.
+type SpecLevel int
+
+const (
+	// SpecLevel2 conforms to WebAuthn Level 2. This is the default.
+	SpecLevel2 SpecLevel = iota
+
+	// SpecLevel3 conforms to WebAuthn Level 3. The options include the hints and attestation formats, the top origin
+	// of cross-origin client data is verified, the values duplicated from the attestation object by the registration
+	// response JSON are verified, and the backup eligibility of a credential must not change after registration.
+	SpecLevel3
+)
+
+// String returns the name of the SpecLevel as used by the specification.
+func (l SpecLevel) String() string {
+	switch l {
+	case SpecLevel2:
+		return "Level 2"
+	case SpecLevel3:
+		return "Level 3"
+	default:
+		return "Unknown"
+	}
+}
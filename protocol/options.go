@@ -4,10 +4,16 @@ import (
 	"github.com/go-webauthn/webauthn/protocol/webauthncose"
 )
 
+// CredentialCreation is the options of a registration ceremony. The Response is the
+// PublicKeyCredentialCreationOptionsJSON accepted as is by the PublicKeyCredential.parseCreationOptionsFromJSON method
+// of the browsers and by the startRegistration function of the @simplewebauthn/browser library.
 type CredentialCreation struct {
 	Response PublicKeyCredentialCreationOptions `json:"publicKey"`
 }
 
+// CredentialAssertion is the options of a login ceremony. The Response is the PublicKeyCredentialRequestOptionsJSON
+// accepted as is by the PublicKeyCredential.parseRequestOptionsFromJSON method of the browsers and by the
+// startAuthentication function of the @simplewebauthn/browser library.
 type CredentialAssertion struct {
 	Response PublicKeyCredentialRequestOptions `json:"publicKey"`
 }
@@ -17,26 +23,29 @@ type CredentialAssertion struct {
 // In order to create a Credential via create(), the caller specifies a few parameters in a
 // PublicKeyCredentialCreationOptions object.
 //
-// TODO: There is one field missing from this for WebAuthn Level 3. A string slice named 'attestationFormats'.
+// The Hints and AttestationFormats are part of WebAuthn Level 3, and are only included by the webauthn package when the
+// Config.SpecLevel is SpecLevel3.
 //
 // Specification: §5.4. Options for Credential Creation (https://www.w3.org/TR/webauthn/#dictionary-makecredentialoptions)
 type PublicKeyCredentialCreationOptions struct {
-	RelyingParty           RelyingPartyEntity       `json:"rp"`
-	User                   UserEntity               `json:"user"`
-	Challenge              URLEncodedBase64         `json:"challenge"`
-	Parameters             []CredentialParameter    `json:"pubKeyCredParams,omitempty"`
-	Timeout                int                      `json:"timeout,omitempty"`
-	CredentialExcludeList  []CredentialDescriptor   `json:"excludeCredentials,omitempty"`
-	AuthenticatorSelection AuthenticatorSelection   `json:"authenticatorSelection,omitempty"`
-	Attestation            ConveyancePreference     `json:"attestation,omitempty"`
-	Extensions             AuthenticationExtensions `json:"extensions,omitempty"`
+	RelyingParty           RelyingPartyEntity        `json:"rp"`
+	User                   UserEntity                `json:"user"`
+	Challenge              URLEncodedBase64          `json:"challenge"`
+	Parameters             []CredentialParameter     `json:"pubKeyCredParams,omitempty"`
+	Timeout                int                       `json:"timeout,omitempty"`
+	CredentialExcludeList  []CredentialDescriptor    `json:"excludeCredentials,omitempty"`
+	AuthenticatorSelection AuthenticatorSelection    `json:"authenticatorSelection,omitempty"`
+	Attestation            ConveyancePreference      `json:"attestation,omitempty"`
+	Extensions             AuthenticationExtensions  `json:"extensions,omitempty"`
+	Hints                  []PublicKeyCredentialHint `json:"hints,omitempty"`
+	AttestationFormats     []string                  `json:"attestationFormats,omitempty"`
 }
 
 // The PublicKeyCredentialRequestOptions dictionary supplies get() with the data it needs to generate an assertion.
 // Its challenge member MUST be present, while its other members are OPTIONAL.
 //
-// TODO: There are two fields missing from this for WebAuthn Level 3. A string type named 'attestation', and a string
-// slice named 'attestationFormats'.
+// The Hints are part of WebAuthn Level 3, and are only included by the webauthn package when the Config.SpecLevel is
+// SpecLevel3.
 //
 // Specification: §5.5. Options for Assertion Generation (https://www.w3.org/TR/webauthn/#dictionary-assertion-options)
 type PublicKeyCredentialRequestOptions struct {
@@ -46,6 +55,7 @@ type PublicKeyCredentialRequestOptions struct {
 	AllowedCredentials []CredentialDescriptor      `json:"allowCredentials,omitempty"`
 	UserVerification   UserVerificationRequirement `json:"userVerification,omitempty"`
 	Extensions         AuthenticationExtensions    `json:"extensions,omitempty"`
+	Hints              []PublicKeyCredentialHint   `json:"hints,omitempty"`
 }
 
 // CredentialDescriptor represents the PublicKeyCredentialDescriptor IDL.
@@ -183,6 +193,28 @@ const (
 	PreferEnterpriseAttestation ConveyancePreference = "enterprise"
 )
 
+// PublicKeyCredentialHint is the type representing the PublicKeyCredentialHint IDL.
+//
+// WebAuthn Relying Parties may use the hints to communicate hints to the user agent about how a request may be best
+// completed. The hints are ordered from most preferred to least preferred, and are part of WebAuthn Level 3.
+//
+// Specification: §5.8.7. User-agent Hints Enumeration (https://www.w3.org/TR/webauthn-3/#enumdef-publickeycredentialhint)
+type PublicKeyCredentialHint string
+
+const (
+	// PublicKeyCredentialHintSecurityKey indicates the Relying Party believes the user will satisfy the request with a
+	// physical security key.
+	PublicKeyCredentialHintSecurityKey PublicKeyCredentialHint = "security-key"
+
+	// PublicKeyCredentialHintClientDevice indicates the Relying Party believes the user will satisfy the request with a
+	// platform authenticator attached to the client device.
+	PublicKeyCredentialHintClientDevice PublicKeyCredentialHint = "client-device"
+
+	// PublicKeyCredentialHintHybrid indicates the Relying Party believes the user will satisfy the request with a
+	// general-purpose authenticator such as a smartphone using the hybrid transport.
+	PublicKeyCredentialHintHybrid PublicKeyCredentialHint = "hybrid"
+)
+
 func (a *PublicKeyCredentialRequestOptions) GetAllowedCredentialIDs() [][]byte {
 	var allowedCredentialIDs = make([][]byte, len(a.AllowedCredentials))
 
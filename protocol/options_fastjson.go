@@ -0,0 +1,323 @@
+//go:build webauthn_fastjson
+
+package protocol
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+)
+
+// This file contains specialized JSON marshalers for the options returned by the BeginRegistration and BeginLogin
+// ceremonies which avoid the reflection performed by encoding/json. They're only included when building with the
+// webauthn_fastjson build tag and produce the same output as the reflection based encoding. Fields which have no
+// static type such as the extensions and the user ID still fall back to encoding/json.
+
+// MarshalJSON implements the json.Marshaler interface.
+func (c CredentialCreation) MarshalJSON() ([]byte, error) {
+	buf := make([]byte, 0, 512)
+
+	buf = append(buf, `{"publicKey":`...)
+
+	buf, err := c.Response.appendJSON(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(buf, '}'), nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (c CredentialAssertion) MarshalJSON() ([]byte, error) {
+	buf := make([]byte, 0, 256)
+
+	buf = append(buf, `{"publicKey":`...)
+
+	buf, err := c.Response.appendJSON(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(buf, '}'), nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (o PublicKeyCredentialCreationOptions) MarshalJSON() ([]byte, error) {
+	return o.appendJSON(make([]byte, 0, 512))
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (o PublicKeyCredentialRequestOptions) MarshalJSON() ([]byte, error) {
+	return o.appendJSON(make([]byte, 0, 256))
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (d CredentialDescriptor) MarshalJSON() ([]byte, error) {
+	return d.appendJSON(make([]byte, 0, 128)), nil
+}
+
+func (o PublicKeyCredentialCreationOptions) appendJSON(buf []byte) (_ []byte, err error) {
+	buf = append(buf, `{"rp":{"name":`...)
+	buf = appendJSONString(buf, o.RelyingParty.Name)
+
+	if o.RelyingParty.Icon != "" {
+		buf = append(buf, `,"icon":`...)
+		buf = appendJSONString(buf, o.RelyingParty.Icon)
+	}
+
+	buf = append(buf, `,"id":`...)
+	buf = appendJSONString(buf, o.RelyingParty.ID)
+
+	buf = append(buf, `},"user":{"name":`...)
+	buf = appendJSONString(buf, o.User.Name)
+
+	if o.User.Icon != "" {
+		buf = append(buf, `,"icon":`...)
+		buf = appendJSONString(buf, o.User.Icon)
+	}
+
+	buf = append(buf, `,"displayName":`...)
+	buf = appendJSONString(buf, o.User.DisplayName)
+
+	buf = append(buf, `,"id":`...)
+
+	if buf, err = appendJSONValue(buf, o.User.ID); err != nil {
+		return nil, err
+	}
+
+	buf = append(buf, `},"challenge":`...)
+	buf = appendJSONBase64(buf, o.Challenge)
+
+	if len(o.Parameters) != 0 {
+		buf = append(buf, `,"pubKeyCredParams":[`...)
+
+		for i, param := range o.Parameters {
+			if i != 0 {
+				buf = append(buf, ',')
+			}
+
+			buf = append(buf, `{"type":`...)
+			buf = appendJSONString(buf, string(param.Type))
+			buf = append(buf, `,"alg":`...)
+			buf = strconv.AppendInt(buf, int64(param.Algorithm), 10)
+			buf = append(buf, '}')
+		}
+
+		buf = append(buf, ']')
+	}
+
+	if o.Timeout != 0 {
+		buf = append(buf, `,"timeout":`...)
+		buf = strconv.AppendInt(buf, int64(o.Timeout), 10)
+	}
+
+	if len(o.CredentialExcludeList) != 0 {
+		buf = append(buf, `,"excludeCredentials":`...)
+		buf = appendJSONDescriptors(buf, o.CredentialExcludeList)
+	}
+
+	buf = append(buf, `,"authenticatorSelection":{`...)
+
+	first := true
+
+	field := func(name string) {
+		if !first {
+			buf = append(buf, ',')
+		}
+
+		first = false
+
+		buf = append(buf, name...)
+	}
+
+	if o.AuthenticatorSelection.AuthenticatorAttachment != "" {
+		field(`"authenticatorAttachment":`)
+		buf = appendJSONString(buf, string(o.AuthenticatorSelection.AuthenticatorAttachment))
+	}
+
+	if o.AuthenticatorSelection.RequireResidentKey != nil {
+		field(`"requireResidentKey":`)
+		buf = strconv.AppendBool(buf, *o.AuthenticatorSelection.RequireResidentKey)
+	}
+
+	if o.AuthenticatorSelection.ResidentKey != "" {
+		field(`"residentKey":`)
+		buf = appendJSONString(buf, string(o.AuthenticatorSelection.ResidentKey))
+	}
+
+	if o.AuthenticatorSelection.UserVerification != "" {
+		field(`"userVerification":`)
+		buf = appendJSONString(buf, string(o.AuthenticatorSelection.UserVerification))
+	}
+
+	buf = append(buf, '}')
+
+	if o.Attestation != "" {
+		buf = append(buf, `,"attestation":`...)
+		buf = appendJSONString(buf, string(o.Attestation))
+	}
+
+	if len(o.Extensions) != 0 {
+		buf = append(buf, `,"extensions":`...)
+
+		if buf, err = appendJSONValue(buf, o.Extensions); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(o.Hints) != 0 {
+		buf = append(buf, `,"hints":`...)
+		buf = appendJSONHints(buf, o.Hints)
+	}
+
+	if len(o.AttestationFormats) != 0 {
+		buf = append(buf, `,"attestationFormats":[`...)
+
+		for i, format := range o.AttestationFormats {
+			if i != 0 {
+				buf = append(buf, ',')
+			}
+
+			buf = appendJSONString(buf, format)
+		}
+
+		buf = append(buf, ']')
+	}
+
+	return append(buf, '}'), nil
+}
+
+func (o PublicKeyCredentialRequestOptions) appendJSON(buf []byte) (_ []byte, err error) {
+	buf = append(buf, `{"challenge":`...)
+	buf = appendJSONBase64(buf, o.Challenge)
+
+	if o.Timeout != 0 {
+		buf = append(buf, `,"timeout":`...)
+		buf = strconv.AppendInt(buf, int64(o.Timeout), 10)
+	}
+
+	if o.RelyingPartyID != "" {
+		buf = append(buf, `,"rpId":`...)
+		buf = appendJSONString(buf, o.RelyingPartyID)
+	}
+
+	if len(o.AllowedCredentials) != 0 {
+		buf = append(buf, `,"allowCredentials":`...)
+		buf = appendJSONDescriptors(buf, o.AllowedCredentials)
+	}
+
+	if o.UserVerification != "" {
+		buf = append(buf, `,"userVerification":`...)
+		buf = appendJSONString(buf, string(o.UserVerification))
+	}
+
+	if len(o.Extensions) != 0 {
+		buf = append(buf, `,"extensions":`...)
+
+		if buf, err = appendJSONValue(buf, o.Extensions); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(o.Hints) != 0 {
+		buf = append(buf, `,"hints":`...)
+		buf = appendJSONHints(buf, o.Hints)
+	}
+
+	return append(buf, '}'), nil
+}
+
+func (d CredentialDescriptor) appendJSON(buf []byte) []byte {
+	buf = append(buf, `{"type":`...)
+	buf = appendJSONString(buf, string(d.Type))
+	buf = append(buf, `,"id":`...)
+	buf = appendJSONBase64(buf, d.CredentialID)
+
+	if len(d.Transport) != 0 {
+		buf = append(buf, `,"transports":[`...)
+
+		for i, transport := range d.Transport {
+			if i != 0 {
+				buf = append(buf, ',')
+			}
+
+			buf = appendJSONString(buf, string(transport))
+		}
+
+		buf = append(buf, ']')
+	}
+
+	return append(buf, '}')
+}
+
+func appendJSONDescriptors(buf []byte, descriptors []CredentialDescriptor) []byte {
+	buf = append(buf, '[')
+
+	for i, descriptor := range descriptors {
+		if i != 0 {
+			buf = append(buf, ',')
+		}
+
+		buf = descriptor.appendJSON(buf)
+	}
+
+	return append(buf, ']')
+}
+
+func appendJSONHints(buf []byte, hints []PublicKeyCredentialHint) []byte {
+	buf = append(buf, '[')
+
+	for i, hint := range hints {
+		if i != 0 {
+			buf = append(buf, ',')
+		}
+
+		buf = appendJSONString(buf, string(hint))
+	}
+
+	return append(buf, ']')
+}
+
+func appendJSONBase64(buf []byte, value URLEncodedBase64) []byte {
+	if value == nil {
+		return append(buf, "null"...)
+	}
+
+	buf = append(buf, '"')
+
+	n := len(buf)
+
+	buf = append(buf, make([]byte, base64.RawURLEncoding.EncodedLen(len(value)))...)
+
+	base64.RawURLEncoding.Encode(buf[n:], value)
+
+	return append(buf, '"')
+}
+
+// appendJSONString appends the JSON encoding of the string. Strings which consist only of printable ASCII characters
+// which don't require escaping are appended directly, every other string is encoded by encoding/json to ensure the
+// escaping is identical.
+func appendJSONString(buf []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c < 0x20, c >= 0x80, c == '"', c == '\\', c == '<', c == '>', c == '&':
+			encoded, _ := json.Marshal(s)
+
+			return append(buf, encoded...)
+		}
+	}
+
+	buf = append(buf, '"')
+	buf = append(buf, s...)
+
+	return append(buf, '"')
+}
+
+func appendJSONValue(buf []byte, v interface{}) ([]byte, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(buf, encoded...), nil
+}
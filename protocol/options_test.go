@@ -1,8 +1,14 @@
 package protocol
 
 import (
+	"encoding/json"
 	"reflect"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
 )
 
 func TestPublicKeyCredentialRequestOptions_GetAllowedCredentialIDs(t *testing.T) {
@@ -57,3 +63,134 @@ func TestPublicKeyCredentialRequestOptions_GetAllowedCredentialIDs(t *testing.T)
 		})
 	}
 }
+
+func TestOptions_MarshalJSON(t *testing.T) {
+	requireResidentKey := true
+
+	testCases := []struct {
+		name     string
+		have     interface{}
+		expected string
+	}{
+		{
+			"ShouldMarshalCreation",
+			&CredentialCreation{
+				Response: PublicKeyCredentialCreationOptions{
+					RelyingParty: RelyingPartyEntity{CredentialEntity: CredentialEntity{Name: "Example"}, ID: "example.com"},
+					User: UserEntity{
+						CredentialEntity: CredentialEntity{Name: "john", Icon: "https://example.com/icon.png"},
+						DisplayName:      "John <Doe> & \"Co\"",
+						ID:               URLEncodedBase64("1234"),
+					},
+					Challenge: URLEncodedBase64("challenge"),
+					Parameters: []CredentialParameter{
+						{Type: PublicKeyCredentialType, Algorithm: webauthncose.AlgES256},
+						{Type: PublicKeyCredentialType, Algorithm: webauthncose.AlgRS256},
+					},
+					Timeout: 60000,
+					CredentialExcludeList: []CredentialDescriptor{
+						{Type: PublicKeyCredentialType, CredentialID: []byte("abc"), Transport: []AuthenticatorTransport{USB, NFC}, AttestationType: "packed"},
+					},
+					AuthenticatorSelection: AuthenticatorSelection{
+						AuthenticatorAttachment: CrossPlatform,
+						RequireResidentKey:      &requireResidentKey,
+						ResidentKey:             ResidentKeyRequirementRequired,
+						UserVerification:        VerificationRequired,
+					},
+					Attestation: PreferDirectAttestation,
+					Extensions:  AuthenticationExtensions{"credProps": true},
+				},
+			},
+			`{"publicKey":{"rp":{"name":"Example","id":"example.com"},"user":{"name":"john","icon":"https://example.com/icon.png","displayName":"John \u003cDoe\u003e \u0026 \"Co\"","id":"MTIzNA"},"challenge":"Y2hhbGxlbmdl","pubKeyCredParams":[{"type":"public-key","alg":-7},{"type":"public-key","alg":-257}],"timeout":60000,"excludeCredentials":[{"type":"public-key","id":"YWJj","transports":["usb","nfc"]}],"authenticatorSelection":{"authenticatorAttachment":"cross-platform","requireResidentKey":true,"residentKey":"required","userVerification":"required"},"attestation":"direct","extensions":{"credProps":true}}}`,
+		},
+		{
+			"ShouldMarshalCreationMinimal",
+			CredentialCreation{
+				Response: PublicKeyCredentialCreationOptions{
+					User: UserEntity{ID: "user"},
+				},
+			},
+			`{"publicKey":{"rp":{"name":"","id":""},"user":{"name":"","displayName":"","id":"user"},"challenge":null,"authenticatorSelection":{}}}`,
+		},
+		{
+			"ShouldMarshalAssertion",
+			&CredentialAssertion{
+				Response: PublicKeyCredentialRequestOptions{
+					Challenge:      URLEncodedBase64("challenge"),
+					Timeout:        60000,
+					RelyingPartyID: "example.com",
+					AllowedCredentials: []CredentialDescriptor{
+						{Type: PublicKeyCredentialType, CredentialID: []byte("abc")},
+						{Type: PublicKeyCredentialType, CredentialID: []byte("def"), Transport: []AuthenticatorTransport{Internal}},
+					},
+					UserVerification: VerificationPreferred,
+					Extensions:       AuthenticationExtensions{"appid": "https://example.com"},
+				},
+			},
+			`{"publicKey":{"challenge":"Y2hhbGxlbmdl","timeout":60000,"rpId":"example.com","allowCredentials":[{"type":"public-key","id":"YWJj"},{"type":"public-key","id":"ZGVm","transports":["internal"]}],"userVerification":"preferred","extensions":{"appid":"https://example.com"}}}`,
+		},
+		{
+			"ShouldMarshalLevel3",
+			&CredentialCreation{
+				Response: PublicKeyCredentialCreationOptions{
+					User:               UserEntity{ID: "user"},
+					Hints:              []PublicKeyCredentialHint{PublicKeyCredentialHintSecurityKey, PublicKeyCredentialHintHybrid},
+					AttestationFormats: []string{"packed", "tpm"},
+				},
+			},
+			`{"publicKey":{"rp":{"name":"","id":""},"user":{"name":"","displayName":"","id":"user"},"challenge":null,"authenticatorSelection":{},"hints":["security-key","hybrid"],"attestationFormats":["packed","tpm"]}}`,
+		},
+		{
+			"ShouldMarshalAssertionLevel3",
+			CredentialAssertion{
+				Response: PublicKeyCredentialRequestOptions{
+					Challenge: URLEncodedBase64("challenge"),
+					Hints:     []PublicKeyCredentialHint{PublicKeyCredentialHintClientDevice},
+				},
+			},
+			`{"publicKey":{"challenge":"Y2hhbGxlbmdl","hints":["client-device"]}}`,
+		},
+		{
+			"ShouldMarshalAssertionMinimal",
+			CredentialAssertion{
+				Response: PublicKeyCredentialRequestOptions{
+					Challenge:  URLEncodedBase64("challenge"),
+					Extensions: AuthenticationExtensions{},
+				},
+			},
+			`{"publicKey":{"challenge":"Y2hhbGxlbmdl"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(tc.have)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(data))
+		})
+	}
+}
+
+func BenchmarkCredentialAssertion_MarshalJSON(b *testing.B) {
+	assertion := &CredentialAssertion{
+		Response: PublicKeyCredentialRequestOptions{
+			Challenge:      URLEncodedBase64("d2ViYXV0aG4gY2hhbGxlbmdlIGJlbmNobWFyaw"),
+			Timeout:        60000,
+			RelyingPartyID: "example.com",
+			AllowedCredentials: []CredentialDescriptor{
+				{Type: PublicKeyCredentialType, CredentialID: []byte("credential-1"), Transport: []AuthenticatorTransport{USB, NFC}},
+				{Type: PublicKeyCredentialType, CredentialID: []byte("credential-2"), Transport: []AuthenticatorTransport{Internal}},
+			},
+			UserVerification: VerificationPreferred,
+		},
+	}
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(assertion); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package protocol
+
+import (
+	"sync"
+)
+
+// signedDataPool holds the buffers used to concatenate the authenticator data and the client data hash for signature
+// verification. These are allocated on every registration and login so reusing them reduces the pressure on the
+// garbage collector. The 512 byte capacity fits the authenticator data of most credentials.
+var signedDataPool = sync.Pool{
+	New: func() interface{} {
+		data := make([]byte, 0, 512)
+
+		return &data
+	},
+}
+
+// getSignedData returns a pooled buffer containing the concatenation of the authenticator data and the client data
+// hash. The buffer must be returned with putSignedData once it's no longer referenced.
+func getSignedData(authData, clientDataHash []byte) *[]byte {
+	data := signedDataPool.Get().(*[]byte)
+
+	*data = append(append((*data)[:0], authData...), clientDataHash...)
+
+	return data
+}
+
+func putSignedData(data *[]byte) {
+	signedDataPool.Put(data)
+}
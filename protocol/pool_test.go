@@ -0,0 +1,23 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSignedData(t *testing.T) {
+	authData := make([]byte, 37, 64)
+	clientDataHash := []byte{1, 2, 3}
+
+	data := getSignedData(authData, clientDataHash)
+
+	assert.Equal(t, append(make([]byte, 37), 1, 2, 3), *data)
+	assert.False(t, &authData[0] == &(*data)[0])
+
+	putSignedData(data)
+
+	data = getSignedData([]byte{4}, clientDataHash)
+
+	assert.Equal(t, []byte{4, 1, 2, 3}, *data)
+}
@@ -0,0 +1,64 @@
+package protocol
+
+import (
+	"net/url"
+	"strings"
+)
+
+// RelatedOriginsPath is the path of the well-known URL of the Relying Party ID where clients fetch the
+// RelatedOrigins of the Relying Party.
+const RelatedOriginsPath = "/.well-known/webauthn"
+
+// RelatedOrigins is the document served at the RelatedOriginsPath of the Relying Party ID which lists the origins
+// allowed to use the Relying Party ID in addition to the origins of its own domain, as defined by Related Origin
+// Requests in WebAuthn Level 3. Clients only accept the origins of a limited number of distinct registrable domains
+// from the document, which is at least five, so the origins should be listed in order of priority.
+//
+// See https://www.w3.org/TR/webauthn-3/#sctn-related-origins
+type RelatedOrigins struct {
+	Origins []string `json:"origins"`
+}
+
+// NewRelatedOrigins returns the RelatedOrigins document for the Relying Party ID from the origins of the Relying Party
+// in their original order. The origins of the Relying Party ID and its subdomains don't need to be listed, and are
+// omitted along with the origins of native applications, the origins which are not HTTPS, and the duplicates.
+func NewRelatedOrigins(rpID string, origins []string) RelatedOrigins {
+	document := RelatedOrigins{Origins: []string{}}
+
+	seen := make(map[string]bool, len(origins))
+
+	for _, origin := range origins {
+		if strings.HasPrefix(origin, AndroidOriginPrefix) {
+			continue
+		}
+
+		fqOrigin, err := FullyQualifiedOrigin(origin)
+		if err != nil {
+			continue
+		}
+
+		parsed, err := url.Parse(fqOrigin)
+		if err != nil || !strings.EqualFold(parsed.Scheme, "https") || isRPIDDomain(parsed.Hostname(), rpID) {
+			continue
+		}
+
+		key := strings.ToLower(fqOrigin)
+
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+
+		document.Origins = append(document.Origins, fqOrigin)
+	}
+
+	return document
+}
+
+// isRPIDDomain returns true if the host is the Relying Party ID or one of its subdomains.
+func isRPIDDomain(host, rpID string) bool {
+	host, rpID = strings.ToLower(host), strings.ToLower(rpID)
+
+	return host == rpID || strings.HasSuffix(host, "."+rpID)
+}
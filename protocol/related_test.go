@@ -0,0 +1,39 @@
+package protocol
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRelatedOrigins(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rpID     string
+		origins  []string
+		expected []string
+	}{
+		{"ShouldListOtherDomains", "example.com", []string{"https://example.co.uk", "https://example.de"}, []string{"https://example.co.uk", "https://example.de"}},
+		{"ShouldOmitRPIDDomain", "example.com", []string{"https://example.com", "https://login.example.com", "https://example.de"}, []string{"https://example.de"}},
+		{"ShouldOmitNonHTTPS", "example.com", []string{"http://example.de", "https://example.fr"}, []string{"https://example.fr"}},
+		{"ShouldOmitNativeApplications", "example.com", []string{AndroidOriginPrefix + "abc", "https://example.de"}, []string{"https://example.de"}},
+		{"ShouldOmitDuplicates", "example.com", []string{"https://example.de", "https://EXAMPLE.de/path", "https://example.de:8443"}, []string{"https://example.de", "https://example.de:8443"}},
+		{"ShouldNotMatchSuffixOfOtherDomain", "example.com", []string{"https://myexample.com"}, []string{"https://myexample.com"}},
+		{"ShouldReturnEmptyDocument", "example.com", []string{"https://example.com"}, []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, NewRelatedOrigins(tc.rpID, tc.origins).Origins)
+		})
+	}
+
+	t.Run("ShouldEncodeEmptyOrigins", func(t *testing.T) {
+		data, err := json.Marshal(NewRelatedOrigins("example.com", nil))
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"origins":[]}`, string(data))
+	})
+}
@@ -0,0 +1,93 @@
+package protocol
+
+import (
+	"errors"
+	"net/http"
+)
+
+// HTTPStatus returns the HTTP status code which should be used to respond to a request which failed with the Error:
+//
+//   - http.StatusRequestEntityTooLarge for responses which exceeded the size limit.
+//   - http.StatusTooManyRequests for ceremonies which were throttled.
+//   - http.StatusBadRequest for malformed requests, expired sessions, and user mismatches.
+//   - http.StatusNotImplemented for features which are not implemented.
+//   - http.StatusUnauthorized for responses which failed verification such as an invalid signature or attestation.
+//
+// Errors without a Code are classified by their Type.
+func (e *Error) HTTPStatus() int {
+	switch e.Code {
+	case CodeResponseTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case CodeRateLimited:
+		return http.StatusTooManyRequests
+	}
+
+	if e.Code == "" {
+		switch e.Type {
+		case ErrBadRequest.Type, ErrParsingData.Type, ErrSessionExpired.Type:
+			return http.StatusBadRequest
+		case ErrNotImplemented.Type, ErrNotSpecImplemented.Type:
+			return http.StatusNotImplemented
+		default:
+			return http.StatusUnauthorized
+		}
+	}
+
+	switch e.FailureReason() {
+	case FailureReasonMalformedRequest, FailureReasonSessionExpired, FailureReasonUserMismatch:
+		return http.StatusBadRequest
+	default:
+		return http.StatusUnauthorized
+	}
+}
+
+// GetHTTPStatus returns the HTTP status code for the provided error. It returns http.StatusOK if the error is nil,
+// the HTTPStatus of the error if it's an *Error, and http.StatusInternalServerError otherwise as the error was not
+// caused by the request.
+func GetHTTPStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+
+	var e *Error
+
+	if errors.As(err, &e) {
+		return e.HTTPStatus()
+	}
+
+	return http.StatusInternalServerError
+}
+
+// ErrorBody is a JSON error body which is safe to return to untrusted parties as it never includes the details or
+// debug information of the error, which may contain values such as the challenge or the origin.
+type ErrorBody struct {
+	// Type is the Type of the *Error, or server_error for other errors.
+	Type string `json:"type"`
+
+	// Error is the generic description of the Type.
+	Error string `json:"error"`
+
+	// Code is the Code of the *Error if it has one.
+	Code ErrorCode `json:"code,omitempty"`
+
+	// Reason is the FailureReason of the *Error if it has a Code.
+	Reason FailureReason `json:"reason,omitempty"`
+}
+
+// NewErrorBody returns the ErrorBody for the provided error. Errors which are not an *Error are described only by the
+// HTTP status text, so the details of internal errors such as those returned by a database are never exposed.
+func NewErrorBody(err error) ErrorBody {
+	var e *Error
+
+	if !errors.As(err, &e) {
+		return ErrorBody{Type: "server_error", Error: http.StatusText(http.StatusInternalServerError)}
+	}
+
+	body := ErrorBody{Type: e.Type, Error: errorTypeDetails(e.Type), Code: e.Code}
+
+	if e.Code != "" {
+		body.Reason = e.FailureReason()
+	}
+
+	return body
+}
@@ -0,0 +1,59 @@
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetHTTPStatus(t *testing.T) {
+	testCases := []struct {
+		name     string
+		have     error
+		expected int
+	}{
+		{"ShouldHandleNil", nil, http.StatusOK},
+		{"ShouldHandleNonProtocolError", errors.New("database unavailable"), http.StatusInternalServerError},
+		{"ShouldHandleTooLarge", ErrBadRequest.WithCode(CodeResponseTooLarge), http.StatusRequestEntityTooLarge},
+		{"ShouldHandleRateLimited", ErrPolicy.WithCode(CodeRateLimited), http.StatusTooManyRequests},
+		{"ShouldHandleMalformed", ErrBadRequest.WithCode(CodeResponseInvalid), http.StatusBadRequest},
+		{"ShouldHandleSessionExpired", ErrSessionExpired.WithCode(CodeSessionExpired), http.StatusBadRequest},
+		{"ShouldHandleUserMismatch", ErrBadRequest.WithCode(CodeUserHandleMismatch), http.StatusBadRequest},
+		{"ShouldHandleSignature", ErrAssertionSignature.WithCode(CodeSignatureInvalid), http.StatusUnauthorized},
+		{"ShouldHandleOrigin", ErrVerification.WithCode(CodeOriginMismatch), http.StatusUnauthorized},
+		{"ShouldHandleBadRequestWithoutCode", ErrBadRequest, http.StatusBadRequest},
+		{"ShouldHandleNotImplementedWithoutCode", ErrNotImplemented, http.StatusNotImplemented},
+		{"ShouldHandleVerificationWithoutCode", ErrAssertionSignature, http.StatusUnauthorized},
+		{"ShouldHandleWrapped", fmt.Errorf("wrapped: %w", ErrVerification.WithCode(CodeChallengeMismatch)), http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, GetHTTPStatus(tc.have))
+		})
+	}
+}
+
+func TestNewErrorBody(t *testing.T) {
+	err := ErrVerification.
+		WithCode(CodeOriginMismatch).
+		WithDetails("Error validating origin").
+		WithInfo("Expected Values: [https://example.com], Received: https://evil.example.com")
+
+	data, jsonErr := json.Marshal(NewErrorBody(fmt.Errorf("wrapped: %w", err)))
+	require.NoError(t, jsonErr)
+
+	assert.JSONEq(t, `{"type":"verification_error","error":"Error validating the authenticator response","code":"origin_mismatch","reason":"bad_origin"}`, string(data))
+	assert.NotContains(t, string(data), "evil")
+
+	body := NewErrorBody(errors.New("database unavailable at 10.0.0.1"))
+
+	assert.Equal(t, ErrorBody{Type: "server_error", Error: http.StatusText(http.StatusInternalServerError)}, body)
+
+	assert.Equal(t, ErrorBody{Type: ErrBadRequest.Type, Error: ErrBadRequest.Details}, NewErrorBody(ErrBadRequest.WithDetails("Error reading user.name")))
+}
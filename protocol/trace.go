@@ -0,0 +1,145 @@
+package protocol
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// Names of the individual steps recorded in a VerificationTrace.
+const (
+	VerificationStepParse                = "parse"
+	VerificationStepSession              = "session"
+	VerificationStepUser                 = "user"
+	VerificationStepCredential           = "credential"
+	VerificationStepCredentialOrigin     = "credential_origin"
+	VerificationStepAppID                = "appid"
+	VerificationStepClientData           = "client_data"
+	VerificationStepTopOrigin            = "top_origin"
+	VerificationStepResponseJSON         = "response_json"
+	VerificationStepBackupEligibility    = "backup_eligibility"
+	VerificationStepTokenBinding         = "token_binding"
+	VerificationStepChallengeBinding     = "challenge_binding"
+	VerificationStepChallengeReplay      = "challenge_replay"
+	VerificationStepAuthenticatorData    = "authenticator_data"
+	VerificationStepAttestationStatement = "attestation_statement"
+	VerificationStepMetadata             = "metadata"
+	VerificationStepSignature            = "signature"
+	VerificationStepCounter              = "counter"
+	VerificationStepAlgorithm            = "algorithm"
+	VerificationStepPreVerifyPolicy      = "pre_verify_policy"
+	VerificationStepPostVerifyPolicy     = "post_verify_policy"
+	VerificationStepConnectionPolicy     = "connection_policy"
+	VerificationStepTrustPolicy          = "trust_policy"
+	VerificationStepBlocklist            = "blocklist"
+)
+
+// VerificationStep is the record of an individual step performed while verifying a ceremony.
+type VerificationStep struct {
+	// Name of the step.
+	Name string `json:"name"`
+
+	// Inputs are the inputs of the step. These only ever contain hashes and public identifiers and never contain
+	// secrets such as the challenge.
+	Inputs map[string]string `json:"inputs,omitempty"`
+
+	// Passed is true if the step was successful.
+	Passed bool `json:"passed"`
+
+	// Error is the error the step failed with if it was not successful.
+	Error string `json:"error,omitempty"`
+}
+
+// VerificationTrace records every VerificationStep performed while verifying a ceremony. A nil *VerificationTrace is
+// valid and records nothing.
+type VerificationTrace struct {
+	// Diagnostic continues verification after a failed step as long as the remaining steps do not depend on the result
+	// of the failed step. This is intended for diagnosing interoperability issues as it allows the result of every step
+	// to be observed at once. When enabled the verification may not return an error even if a step failed, so Passed
+	// must be used to determine the result instead.
+	Diagnostic bool `json:"diagnostic"`
+
+	Steps []VerificationStep `json:"steps"`
+}
+
+// IsDiagnostic returns true if the trace is in Diagnostic mode.
+func (t *VerificationTrace) IsDiagnostic() bool {
+	return t != nil && t.Diagnostic
+}
+
+// Passed returns true if every recorded step was successful.
+func (t *VerificationTrace) Passed() bool {
+	for _, step := range t.Steps {
+		if !step.Passed {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Record a VerificationStep with the provided name, result, and inputs.
+func (t *VerificationTrace) Record(name string, err error, inputs map[string]string) {
+	if t == nil {
+		return
+	}
+
+	step := VerificationStep{
+		Name:   name,
+		Inputs: inputs,
+		Passed: err == nil,
+	}
+
+	if err != nil {
+		step.Error = err.Error()
+	}
+
+	t.Steps = append(t.Steps, step)
+}
+
+// Step records a VerificationStep the same as Record and returns the error if verification should stop. This is used
+// for steps which no later step depends on, so in Diagnostic mode nil is always returned.
+func (t *VerificationTrace) Step(name string, err error, inputs map[string]string) error {
+	t.Record(name, err, inputs)
+
+	if t.IsDiagnostic() {
+		return nil
+	}
+
+	return err
+}
+
+func traceHash(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func traceAAGUID(aaguid []byte) string {
+	id, err := uuid.FromBytes(aaguid)
+	if err != nil {
+		return hex.EncodeToString(aaguid)
+	}
+
+	return id.String()
+}
+
+func clientDataTraceInputs(c CollectedClientData, clientDataHash []byte) map[string]string {
+	return map[string]string{
+		"type":             string(c.Type),
+		"origin":           c.Origin,
+		"client_data_hash": hex.EncodeToString(clientDataHash),
+	}
+}
+
+func authenticatorDataTraceInputs(a AuthenticatorData, rpIDHash []byte, verifyUser bool) map[string]string {
+	return map[string]string{
+		"rp_id_hash":          hex.EncodeToString(rpIDHash),
+		"received_rp_id_hash": hex.EncodeToString(a.RPIDHash),
+		"flags":               strconv.Itoa(int(a.Flags)),
+		"counter":             strconv.FormatUint(uint64(a.Counter), 10),
+		"user_verification":   strconv.FormatBool(verifyUser),
+	}
+}
@@ -0,0 +1,104 @@
+package protocol
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerificationTrace_Record(t *testing.T) {
+	trace := &VerificationTrace{}
+
+	trace.Record(VerificationStepClientData, nil, map[string]string{"origin": "https://example.com"})
+	trace.Record(VerificationStepSignature, errors.New("bad signature"), nil)
+
+	assert.Equal(t, []VerificationStep{
+		{Name: VerificationStepClientData, Inputs: map[string]string{"origin": "https://example.com"}, Passed: true},
+		{Name: VerificationStepSignature, Passed: false, Error: "bad signature"},
+	}, trace.Steps)
+}
+
+func TestVerificationTrace_RecordNil(t *testing.T) {
+	var trace *VerificationTrace
+
+	assert.NotPanics(t, func() {
+		trace.Record(VerificationStepClientData, nil, nil)
+	})
+}
+
+func TestVerifyCollectedClientDataWithTrace(t *testing.T) {
+	newChallenge, err := CreateChallenge()
+	if err != nil {
+		t.Fatalf("error creating challenge: %s", err)
+	}
+
+	ccd := setupCollectedClientData(newChallenge, "http://example.com")
+
+	p := &ParsedCredentialAssertionData{}
+	p.Response.CollectedClientData = *ccd
+	p.Raw.AssertionResponse.ClientDataJSON = []byte("{}")
+
+	trace := &VerificationTrace{}
+
+	assert.Error(t, p.VerifyWithTrace(trace, "bogus", "example.com", []string{ccd.Origin}, nil, "", false, nil))
+
+	if assert.Len(t, trace.Steps, 1) {
+		assert.Equal(t, VerificationStepClientData, trace.Steps[0].Name)
+		assert.False(t, trace.Steps[0].Passed)
+		assert.Equal(t, "http://example.com", trace.Steps[0].Inputs["origin"])
+		assert.Equal(t, traceHash([]byte("{}")), trace.Steps[0].Inputs["client_data_hash"])
+		assert.NotContains(t, trace.Steps[0].Inputs, "challenge")
+	}
+}
+
+func TestVerificationTrace_Step(t *testing.T) {
+	testCases := []struct {
+		name       string
+		trace      *VerificationTrace
+		err        error
+		expected   error
+		expectedOK bool
+	}{
+		{"ShouldReturnNilOnSuccess", &VerificationTrace{}, nil, nil, true},
+		{"ShouldReturnError", &VerificationTrace{}, errors.New("bad"), errors.New("bad"), false},
+		{"ShouldReturnErrorNilTrace", nil, errors.New("bad"), errors.New("bad"), true},
+		{"ShouldNotReturnErrorDiagnostic", &VerificationTrace{Diagnostic: true}, errors.New("bad"), nil, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.trace.Step(VerificationStepClientData, tc.err, nil))
+
+			if tc.trace != nil {
+				assert.Equal(t, tc.expectedOK, tc.trace.Passed())
+			}
+		})
+	}
+}
+
+func TestVerifyWithTraceDiagnostic(t *testing.T) {
+	newChallenge, err := CreateChallenge()
+	if err != nil {
+		t.Fatalf("error creating challenge: %s", err)
+	}
+
+	ccd := setupCollectedClientData(newChallenge, "http://example.com")
+
+	p := &ParsedCredentialAssertionData{}
+	p.Response.CollectedClientData = *ccd
+	p.Response.AuthenticatorData.RPIDHash = make([]byte, 32)
+
+	trace := &VerificationTrace{Diagnostic: true}
+
+	assert.Error(t, p.VerifyWithTrace(trace, "bogus", "example.com", []string{ccd.Origin}, nil, "", false, nil))
+	assert.False(t, trace.Passed())
+
+	if assert.Len(t, trace.Steps, 3) {
+		assert.Equal(t, VerificationStepClientData, trace.Steps[0].Name)
+		assert.False(t, trace.Steps[0].Passed)
+		assert.Equal(t, VerificationStepAuthenticatorData, trace.Steps[1].Name)
+		assert.False(t, trace.Steps[1].Passed)
+		assert.Equal(t, VerificationStepSignature, trace.Steps[2].Name)
+	}
+}
@@ -0,0 +1,106 @@
+package protocol
+
+import (
+	"fmt"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+)
+
+// UserVerificationMethod is a USER_VERIFY constant of the FIDO Registry of Predefined Values, which is a bit flag in
+// the entries of the uvm extension.
+//
+// See https://fidoalliance.org/specs/common-specs/fido-registry-v2.2-ps-20220523.html#user-verification-methods
+type UserVerificationMethod uint32
+
+const (
+	UVMPresenceInternal    UserVerificationMethod = 0x00000001
+	UVMFingerprintInternal UserVerificationMethod = 0x00000002
+	UVMPasscodeInternal    UserVerificationMethod = 0x00000004
+	UVMVoiceprintInternal  UserVerificationMethod = 0x00000008
+	UVMFaceprintInternal   UserVerificationMethod = 0x00000010
+	UVMLocationInternal    UserVerificationMethod = 0x00000020
+	UVMEyeprintInternal    UserVerificationMethod = 0x00000040
+	UVMPatternInternal     UserVerificationMethod = 0x00000080
+	UVMHandprintInternal   UserVerificationMethod = 0x00000100
+	UVMNone                UserVerificationMethod = 0x00000200
+	UVMAll                 UserVerificationMethod = 0x00000400
+	UVMPasscodeExternal    UserVerificationMethod = 0x00000800
+	UVMPatternExternal     UserVerificationMethod = 0x00001000
+)
+
+var userVerificationMethodNames = map[UserVerificationMethod]string{
+	UVMPresenceInternal:    "presence_internal",
+	UVMFingerprintInternal: "fingerprint_internal",
+	UVMPasscodeInternal:    "passcode_internal",
+	UVMVoiceprintInternal:  "voiceprint_internal",
+	UVMFaceprintInternal:   "faceprint_internal",
+	UVMLocationInternal:    "location_internal",
+	UVMEyeprintInternal:    "eyeprint_internal",
+	UVMPatternInternal:     "pattern_internal",
+	UVMHandprintInternal:   "handprint_internal",
+	UVMNone:                "none",
+	UVMAll:                 "all",
+	UVMPasscodeExternal:    "passcode_external",
+	UVMPatternExternal:     "pattern_external",
+}
+
+// String returns the name of the method used by the userVerification of the metadata statements, such as
+// fingerprint_internal.
+func (m UserVerificationMethod) String() string {
+	if name, ok := userVerificationMethodNames[m]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("unknown(0x%08x)", uint32(m))
+}
+
+// UVMEntry is an entry of the uvm extension, which is a method the authenticator used to verify the user.
+//
+// Specification: §10.3. User Verification Method Extension (uvm) (https://www.w3.org/TR/webauthn-2/#sctn-uvm-extension)
+type UVMEntry struct {
+	UserVerificationMethod UserVerificationMethod `json:"userVerificationMethod"`
+	KeyProtectionType      uint16                 `json:"keyProtectionType"`
+	MatcherProtectionType  uint16                 `json:"matcherProtectionType"`
+}
+
+// UVM returns the entries of the uvm extension of the authenticator data, which is nil if the authenticator did not
+// include the extension. The entries are in the order the authenticator reported them, which is the most relevant
+// first.
+func (a *AuthenticatorData) UVM() ([]UVMEntry, error) {
+	if len(a.ExtData) == 0 {
+		return nil, nil
+	}
+
+	var extensions map[string]webauthncbor.RawMessage
+
+	if err := webauthncbor.Unmarshal(a.ExtData, &extensions); err != nil {
+		return nil, ErrBadRequest.WithCode(CodeAuthDataInvalid).WithDetails("Error decoding the authenticator extensions").WithInfo(err.Error())
+	}
+
+	raw, ok := extensions[ExtensionUVM]
+	if !ok {
+		return nil, nil
+	}
+
+	var values [][]uint32
+
+	if err := webauthncbor.Unmarshal(raw, &values); err != nil {
+		return nil, ErrBadRequest.WithCode(CodeAuthDataInvalid).WithDetails("Error decoding the uvm extension").WithInfo(err.Error())
+	}
+
+	entries := make([]UVMEntry, len(values))
+
+	for i, value := range values {
+		if len(value) != 3 || value[1] > 0xffff || value[2] > 0xffff {
+			return nil, ErrBadRequest.WithCode(CodeAuthDataInvalid).WithDetails("Malformed uvm extension entry").WithInfo(fmt.Sprintf("Entry: %d", i))
+		}
+
+		entries[i] = UVMEntry{
+			UserVerificationMethod: UserVerificationMethod(value[0]),
+			KeyProtectionType:      uint16(value[1]),
+			MatcherProtectionType:  uint16(value[2]),
+		}
+	}
+
+	return entries, nil
+}
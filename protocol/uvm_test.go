@@ -0,0 +1,65 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+)
+
+func TestAuthenticatorData_UVM(t *testing.T) {
+	extensions := func(t *testing.T, v interface{}) []byte {
+		data, err := webauthncbor.Marshal(v)
+		require.NoError(t, err)
+
+		return data
+	}
+
+	testCases := []struct {
+		name     string
+		extData  func(t *testing.T) []byte
+		expected []UVMEntry
+		err      string
+	}{
+		{"ShouldReturnNilWithoutExtensions", func(t *testing.T) []byte { return nil }, nil, ""},
+		{"ShouldReturnNilWithoutUVM", func(t *testing.T) []byte {
+			return extensions(t, map[string]interface{}{"credProtect": 2})
+		}, nil, ""},
+		{"ShouldParseEntries", func(t *testing.T) []byte {
+			return extensions(t, map[string]interface{}{"uvm": [][]uint32{{2, 2, 4}, {4, 1, 1}}})
+		}, []UVMEntry{
+			{UserVerificationMethod: UVMFingerprintInternal, KeyProtectionType: 2, MatcherProtectionType: 4},
+			{UserVerificationMethod: UVMPasscodeInternal, KeyProtectionType: 1, MatcherProtectionType: 1},
+		}, ""},
+		{"ShouldRejectMalformedEntries", func(t *testing.T) []byte {
+			return extensions(t, map[string]interface{}{"uvm": [][]uint32{{2, 2}}})
+		}, nil, "Malformed uvm extension entry"},
+		{"ShouldRejectMalformedExtensions", func(t *testing.T) []byte { return []byte{0xff} }, nil, "Error decoding the authenticator extensions"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &AuthenticatorData{ExtData: tc.extData(t)}
+
+			entries, err := a.UVM()
+
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Equal(t, tc.err, err.(*Error).Details)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, entries)
+		})
+	}
+}
+
+func TestUserVerificationMethod_String(t *testing.T) {
+	assert.Equal(t, "fingerprint_internal", UVMFingerprintInternal.String())
+	assert.Equal(t, "passcode_external", UVMPasscodeExternal.String())
+	assert.Equal(t, "unknown(0x00010000)", UserVerificationMethod(0x10000).String())
+}
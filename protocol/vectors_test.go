@@ -0,0 +1,104 @@
+package protocol
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/webauthntest/vectors"
+)
+
+func TestVectorsRegistration(t *testing.T) {
+	registration, err := vectors.Registration()
+	require.NoError(t, err)
+
+	for _, vector := range registration {
+		t.Run(vector.Name, func(t *testing.T) {
+			pcc, err := ParseCredentialCreationResponseBody(bytes.NewReader(vector.Response))
+			require.NoError(t, err)
+			assert.Equal(t, vector.Format, pcc.Response.AttestationObject.Format)
+
+			skipUnlessFIPSApproved(t, pcc.Response.AttestationObject.AuthData.AttData.CredentialPublicKey)
+
+			clientDataHash := sha256.Sum256(pcc.Raw.AttestationResponse.ClientDataJSON)
+
+			// The tpm vectors are signed with the RS1 algorithm, which is only accepted by the AllowTPMRS1 policy.
+			policy := AttestationPolicy{AllowTPMRS1: vector.Format == tpmAttestationKey}
+
+			attestationType, _, err := pcc.Response.AttestationObject.verifyStatement(clientDataHash[:])
+			if quirks, enabled := policy.tpmQuirks(); enabled {
+				attestationType, _, err = pcc.Response.AttestationObject.verifyStatementWith(quirks.handler(), clientDataHash[:])
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, vector.AttestationType, attestationType)
+
+			verify := func(challenge, rpID, origin string) error {
+				return pcc.VerifyWithPolicy(nil, policy, challenge, false, rpID, []string{origin}, nil)
+			}
+
+			assert.NoError(t, verify(vector.Challenge, vector.RPID, vector.Origin))
+
+			assert.Error(t, verify(vectorsTestChallenge(t), vector.RPID, vector.Origin))
+			assert.Error(t, verify(vector.Challenge, "invalid.example", vector.Origin))
+			assert.Error(t, verify(vector.Challenge, vector.RPID, "https://invalid.example"))
+		})
+	}
+}
+
+func TestVectorsAuthentication(t *testing.T) {
+	authentication, err := vectors.Authentication()
+	require.NoError(t, err)
+
+	for _, vector := range authentication {
+		t.Run(vector.Name, func(t *testing.T) {
+			credentialPublicKey, err := base64.RawURLEncoding.DecodeString(vector.CredentialPublicKey)
+			require.NoError(t, err)
+
+			skipUnlessFIPSApproved(t, credentialPublicKey)
+
+			par, err := ParseCredentialRequestResponseBody(bytes.NewReader(vector.Response))
+			require.NoError(t, err)
+
+			assert.NoError(t, par.Verify(vector.Challenge, vector.RPID, []string{vector.Origin}, "", false, credentialPublicKey))
+
+			assert.Error(t, par.Verify(vectorsTestChallenge(t), vector.RPID, []string{vector.Origin}, "", false, credentialPublicKey))
+			assert.Error(t, par.Verify(vector.Challenge, "invalid.example", []string{vector.Origin}, "", false, credentialPublicKey))
+			assert.Error(t, par.Verify(vector.Challenge, vector.RPID, []string{"https://invalid.example"}, "", false, credentialPublicKey))
+
+			par.Response.Signature[len(par.Response.Signature)-1] ^= 0xff
+
+			assert.Error(t, par.Verify(vector.Challenge, vector.RPID, []string{vector.Origin}, "", false, credentialPublicKey))
+		})
+	}
+}
+
+// skipUnlessFIPSApproved skips the test when building with the webauthn_fips build tag and the algorithm of the COSE
+// encoded public key is not FIPS approved.
+func skipUnlessFIPSApproved(t *testing.T, publicKey []byte) {
+	if !webauthncose.FIPSMode {
+		return
+	}
+
+	var key webauthncose.PublicKeyData
+
+	require.NoError(t, webauthncbor.Unmarshal(publicKey, &key))
+
+	if !webauthncose.IsFIPSApproved(webauthncose.COSEAlgorithmIdentifier(key.Algorithm)) {
+		t.Skip("the algorithm is not FIPS approved")
+	}
+}
+
+// vectorsTestChallenge returns a new random challenge which does not match the challenge of any vector.
+func vectorsTestChallenge(t *testing.T) string {
+	challenge, err := CreateChallenge()
+	require.NoError(t, err)
+
+	return challenge.String()
+}
@@ -0,0 +1,234 @@
+package protocol
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+)
+
+// The functions in this file are the individual steps of the registration and authentication ceremonies. The Verify
+// methods of CollectedClientData, AuthenticatorData, and ParsedCredentialAssertionData compose them into the default
+// verification performed by the webauthn package, however advanced users may compose them into their own pipelines
+// for example to reorder, skip, or augment individual steps. Every step returns a *Error so the result can be
+// classified the same way regardless of how the steps were composed.
+
+// VerifyCeremonyType handles step 3 of verifying the registering client data of a new credential and step 7 of
+// verifying an authentication assertion, ensuring the type of the client data matches the ceremony.
+func VerifyCeremonyType(c *CollectedClientData, ceremony CeremonyType) error {
+	// Registration Step 3. Verify that the value of C.type is webauthn.create.
+
+	// Assertion Step 7. Verify that the value of C.type is the string webauthn.get.
+	if c.Type != ceremony {
+		return ErrVerification.WithCode(CodeCeremonyMismatch).WithDetails("Error validating ceremony type").WithInfo(fmt.Sprintf("Expected Value: %s, Received: %s", ceremony, c.Type))
+	}
+
+	return nil
+}
+
+// VerifyChallenge handles step 4 of verifying the registering client data of a new credential and step 8 of verifying
+// an authentication assertion, ensuring the challenge of the client data matches the stored challenge. The comparison
+// is performed in constant time.
+func VerifyChallenge(c *CollectedClientData, storedChallenge string) error {
+	// Registration Step 4. Verify that the value of C.challenge matches the challenge
+	// that was sent to the authenticator in the create() call.
+
+	// Assertion Step 8. Verify that the value of C.challenge matches the challenge
+	// that was sent to the authenticator in the PublicKeyCredentialRequestOptions
+	// passed to the get() call.
+	if subtle.ConstantTimeCompare([]byte(storedChallenge), []byte(c.Challenge)) != 1 {
+		return ErrVerification.
+			WithCode(CodeChallengeMismatch).
+			WithDetails("Error validating challenge").
+			WithInfo(fmt.Sprintf("Expected b Value: %#v\nReceived b: %#v\n", storedChallenge, c.Challenge))
+	}
+
+	return nil
+}
+
+// VerifyOrigin handles step 5 of verifying the registering client data of a new credential and step 9 of verifying
+// an authentication assertion, ensuring the fully qualified origin of the client data is one of the origins of the
+// Relying Party.
+func VerifyOrigin(c *CollectedClientData, rpOrigins []string) error {
+	// Registration Step 5 & Assertion Step 9. Verify that the value of C.origin matches
+	// the Relying Party's origin.
+	fqOrigin, err := FullyQualifiedOrigin(c.Origin)
+	if err != nil {
+		return ErrParsingData.WithCode(CodeOriginInvalid).WithDetails("Error decoding clientData origin as URL")
+	}
+
+	for _, origin := range rpOrigins {
+		if strings.EqualFold(fqOrigin, origin) {
+			return nil
+		}
+	}
+
+	return ErrVerification.
+		WithCode(CodeOriginMismatch).
+		WithDetails("Error validating origin").
+		WithInfo(fmt.Sprintf("Expected Values: %s, Received: %s", rpOrigins, fqOrigin))
+}
+
+// VerifyTopOrigin handles the part of step 5 of verifying the registering client data of a new credential and step 9
+// of verifying an authentication assertion added by WebAuthn Level 3, ensuring client data which was collected within
+// a cross-origin iframe has a top origin which is one of the rpTopOrigins. Client data which is not cross-origin is
+// always valid, so an empty rpTopOrigins rejects only cross-origin client data.
+func VerifyTopOrigin(c *CollectedClientData, rpTopOrigins []string) error {
+	// Registration Step 5 & Assertion Step 9. If C.topOrigin is present, verify that the Relying Party expects
+	// this credential to be used within an iframe that is not same-origin with its ancestors, and that the
+	// value of C.topOrigin matches the origin of a page that the Relying Party expects to be sub-framed within.
+	if c.TopOrigin == "" && !c.CrossOrigin {
+		return nil
+	}
+
+	fqTopOrigin, err := FullyQualifiedOrigin(c.TopOrigin)
+	if err != nil {
+		return ErrParsingData.WithCode(CodeOriginInvalid).WithDetails("Error decoding clientData top origin as URL")
+	}
+
+	for _, origin := range rpTopOrigins {
+		if strings.EqualFold(fqTopOrigin, origin) {
+			return nil
+		}
+	}
+
+	return ErrVerification.
+		WithCode(CodeTopOriginMismatch).
+		WithDetails("Error validating top origin").
+		WithInfo(fmt.Sprintf("Expected Values: %s, Received: %s", rpTopOrigins, fqTopOrigin))
+}
+
+// VerifyTokenBindingFormat handles the part of step 6 of verifying the registering client data of a new credential and
+// step 10 of verifying an authentication assertion which does not depend on the connection, ensuring the token binding
+// of the client data has a valid status. The token binding is verified against the connection by VerifyTokenBinding.
+func VerifyTokenBindingFormat(c *CollectedClientData) error {
+	// Registration Step 6 and Assertion Step 10. Verify that the value of C.tokenBinding.status
+	// matches the state of Token Binding for the TLS connection over which the assertion was
+	// obtained. If Token Binding was used on that TLS connection, also verify that C.tokenBinding.id
+	// matches the base64url encoding of the Token Binding ID for the connection.
+	if c.TokenBinding == nil {
+		return nil
+	}
+
+	if c.TokenBinding.Status == "" {
+		return ErrParsingData.WithCode(CodeTokenBindingInvalid).WithDetails("Error decoding clientData, token binding present without status")
+	}
+
+	if c.TokenBinding.Status != Present && c.TokenBinding.Status != Supported && c.TokenBinding.Status != NotSupported {
+		return ErrParsingData.
+			WithCode(CodeTokenBindingInvalid).
+			WithDetails("Error decoding clientData, token binding present with invalid status").
+			WithInfo(fmt.Sprintf("Got: %s", c.TokenBinding.Status))
+	}
+
+	return nil
+}
+
+// OriginVerifier verifies the origin of the client data against the origins of the Relying Party, which is step 5 of
+// verifying the registering client data of a new credential and step 9 of verifying an authentication assertion. It
+// allows deployments such as those behind TLS terminating proxies or using native application origins to customize how
+// the origins are matched. Errors which are not an *Error are returned as an ErrVerification.
+type OriginVerifier interface {
+	VerifyOrigin(c *CollectedClientData, rpOrigins []string) (err error)
+}
+
+// OriginVerifierFunc is an OriginVerifier implemented by a function.
+type OriginVerifierFunc func(c *CollectedClientData, rpOrigins []string) (err error)
+
+// VerifyOrigin calls the function.
+func (f OriginVerifierFunc) VerifyOrigin(c *CollectedClientData, rpOrigins []string) (err error) {
+	return f(c, rpOrigins)
+}
+
+// StrictOriginVerifier is the default OriginVerifier which uses VerifyOrigin, requiring the fully qualified origin of
+// the client data to equal one of the origins of the Relying Party.
+var StrictOriginVerifier OriginVerifier = OriginVerifierFunc(VerifyOrigin)
+
+// VerifyOriginWith verifies the origin of the client data with the OriginVerifier, or the StrictOriginVerifier if
+// it's nil, converting the error to a *Error if necessary.
+func VerifyOriginWith(verifier OriginVerifier, c *CollectedClientData, rpOrigins []string) error {
+	if verifier == nil {
+		verifier = StrictOriginVerifier
+	}
+
+	err := verifier.VerifyOrigin(c, rpOrigins)
+	if err == nil {
+		return nil
+	}
+
+	var e *Error
+
+	if errors.As(err, &e) {
+		return err
+	}
+
+	return ErrVerification.
+		WithCode(CodeOriginMismatch).
+		WithDetails("Error validating origin").
+		WithInfo(err.Error())
+}
+
+// VerifyRPIDHash handles step 9 of verifying the registration of a new credential and step 11 of verifying an
+// authentication assertion, ensuring the RP ID hash of the authenticator data is the SHA-256 hash of either the RP ID
+// or the appid extension value. The appIDHash may be empty if the appid extension was not used. Both hashes are always
+// compared in constant time so the result does not leak which of them matched.
+func VerifyRPIDHash(a *AuthenticatorData, rpIdHash, appIDHash []byte) error {
+	// Registration Step 9 & Assertion Step 11
+	// Verify that the RP ID hash in authData is indeed the SHA-256
+	// hash of the RP ID expected by the RP.
+	if subtle.ConstantTimeCompare(a.RPIDHash, rpIdHash)|subtle.ConstantTimeCompare(a.RPIDHash, appIDHash) != 1 {
+		return ErrVerification.WithCode(CodeRPIDHashMismatch).WithInfo(fmt.Sprintf("RP Hash mismatch. Expected %x and Received %x", a.RPIDHash, rpIdHash))
+	}
+
+	return nil
+}
+
+// VerifyFlags handles steps 10 and 11 of verifying the registration of a new credential and steps 12 and 13 of
+// verifying an authentication assertion, ensuring the user was present and if required was also verified.
+func VerifyFlags(a *AuthenticatorData, userVerificationRequired bool) error {
+	// Registration Step 10 & Assertion Step 12
+	// Verify that the User Present bit of the flags in authData is set.
+	if !a.Flags.UserPresent() {
+		return ErrVerification.WithCode(CodeUPRequired).WithInfo(fmt.Sprintln("User presence flag not set by authenticator"))
+	}
+
+	// Registration Step 11 & Assertion Step 13
+	// If user verification is required for this assertion, verify that
+	// the User Verified bit of the flags in authData is set.
+	if userVerificationRequired && !a.Flags.UserVerified() {
+		return ErrVerification.WithCode(CodeUVRequired).WithInfo(fmt.Sprintln("User verification required but flag not set by authenticator"))
+	}
+
+	return nil
+}
+
+// VerifySignature handles step 16 of verifying an authentication assertion, ensuring the signature is valid for the
+// signed data using the credential public key. The signed data is the binary concatenation of the raw authenticator
+// data and the SHA-256 hash of the raw client data. The credentialBytes is the COSE encoded public key unless fido is
+// true in which case it's an uncompressed FIDO U2F public key, which is the case for credentials registered with the
+// FIDO U2F API and used with the appid extension.
+func VerifySignature(signedData, signature, credentialBytes []byte, fido bool) error {
+	var (
+		key interface{}
+		err error
+	)
+
+	if fido {
+		key, err = webauthncose.ParseFIDOPublicKey(credentialBytes)
+	} else {
+		key, err = webauthncose.ParsePublicKey(credentialBytes)
+	}
+
+	if err != nil {
+		return ErrAssertionSignature.WithCode(CodePublicKeyInvalid).WithDetails(fmt.Sprintf("Error parsing the assertion public key: %+v", err))
+	}
+
+	valid, err := webauthncose.VerifySignature(key, signedData, signature)
+	if !valid || err != nil {
+		return ErrAssertionSignature.WithCode(CodeSignatureInvalid).WithDetails(fmt.Sprintf("Error validating the assertion signature: %+v", err))
+	}
+
+	return nil
+}
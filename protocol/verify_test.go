@@ -0,0 +1,128 @@
+package protocol
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+)
+
+func TestVerifyClientDataSteps(t *testing.T) {
+	c := &CollectedClientData{Type: AssertCeremony, Challenge: "AAAA", Origin: "https://example.com"}
+
+	testCases := []struct {
+		name string
+		err  error
+		code ErrorCode
+	}{
+		{"ShouldPassCeremonyType", VerifyCeremonyType(c, AssertCeremony), ""},
+		{"ShouldFailCeremonyType", VerifyCeremonyType(c, CreateCeremony), CodeCeremonyMismatch},
+		{"ShouldPassChallenge", VerifyChallenge(c, "AAAA"), ""},
+		{"ShouldFailChallenge", VerifyChallenge(c, "AAAB"), CodeChallengeMismatch},
+		{"ShouldFailEmptyChallenge", VerifyChallenge(c, ""), CodeChallengeMismatch},
+		{"ShouldPassOrigin", VerifyOrigin(c, []string{"https://other.com", "https://EXAMPLE.com"}), ""},
+		{"ShouldFailOrigin", VerifyOrigin(c, []string{"https://other.com"}), CodeOriginMismatch},
+		{"ShouldFailInvalidOrigin", VerifyOrigin(&CollectedClientData{Origin: "example"}, []string{"https://example.com"}), CodeOriginInvalid},
+		{"ShouldPassTopOriginSameOrigin", VerifyTopOrigin(c, nil), ""},
+		{"ShouldPassTopOrigin", VerifyTopOrigin(&CollectedClientData{CrossOrigin: true, TopOrigin: "https://top.example.com/page"}, []string{"https://top.example.com"}), ""},
+		{"ShouldFailTopOrigin", VerifyTopOrigin(&CollectedClientData{CrossOrigin: true, TopOrigin: "https://evil.com"}, []string{"https://top.example.com"}), CodeTopOriginMismatch},
+		{"ShouldFailTopOriginNotExpected", VerifyTopOrigin(&CollectedClientData{CrossOrigin: true, TopOrigin: "https://top.example.com"}, nil), CodeTopOriginMismatch},
+		{"ShouldFailCrossOriginWithoutTopOrigin", VerifyTopOrigin(&CollectedClientData{CrossOrigin: true}, []string{"https://top.example.com"}), CodeOriginInvalid},
+		{"ShouldPassTokenBindingFormat", VerifyTokenBindingFormat(&CollectedClientData{TokenBinding: &TokenBinding{Status: Supported}}), ""},
+		{"ShouldFailTokenBindingFormat", VerifyTokenBindingFormat(&CollectedClientData{TokenBinding: &TokenBinding{}}), CodeTokenBindingInvalid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.code == "" {
+				assert.NoError(t, tc.err)
+
+				return
+			}
+
+			require.IsType(t, &Error{}, tc.err)
+			assert.Equal(t, tc.code, tc.err.(*Error).Code)
+		})
+	}
+}
+
+func TestVerifyAuthenticatorDataSteps(t *testing.T) {
+	rpIDHash := sha256.Sum256([]byte("example.com"))
+	appIDHash := sha256.Sum256([]byte("https://example.com"))
+
+	testCases := []struct {
+		name string
+		err  error
+		code ErrorCode
+	}{
+		{"ShouldPassRPIDHash", VerifyRPIDHash(&AuthenticatorData{RPIDHash: rpIDHash[:]}, rpIDHash[:], nil), ""},
+		{"ShouldPassAppIDHash", VerifyRPIDHash(&AuthenticatorData{RPIDHash: appIDHash[:]}, rpIDHash[:], appIDHash[:]), ""},
+		{"ShouldFailRPIDHash", VerifyRPIDHash(&AuthenticatorData{RPIDHash: appIDHash[:]}, rpIDHash[:], nil), CodeRPIDHashMismatch},
+		{"ShouldPassUserPresent", VerifyFlags(&AuthenticatorData{Flags: FlagUserPresent}, false), ""},
+		{"ShouldPassUserVerified", VerifyFlags(&AuthenticatorData{Flags: FlagUserPresent | FlagUserVerified}, true), ""},
+		{"ShouldFailUserNotPresent", VerifyFlags(&AuthenticatorData{Flags: FlagUserVerified}, false), CodeUPRequired},
+		{"ShouldFailUserNotVerified", VerifyFlags(&AuthenticatorData{Flags: FlagUserPresent}, true), CodeUVRequired},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.code == "" {
+				assert.NoError(t, tc.err)
+
+				return
+			}
+
+			require.IsType(t, &Error{}, tc.err)
+			assert.Equal(t, tc.code, tc.err.(*Error).Code)
+		})
+	}
+}
+
+func TestVerifySteps_CustomPipeline(t *testing.T) {
+	body, credentialBytes := benchmarkAssertionResponse(t, webauthncose.AlgES256)
+
+	par, err := ParseCredentialRequestResponseBody(bytes.NewReader(body))
+	require.NoError(t, err)
+
+	rpIDHash := sha256.Sum256([]byte("example.com"))
+	clientDataHash := sha256.Sum256(par.Raw.AssertionResponse.ClientDataJSON)
+	signedData := append(append([]byte{}, par.Raw.AssertionResponse.AuthenticatorData...), clientDataHash[:]...)
+
+	// A pipeline which verifies the signature first and deliberately skips the origin verification.
+	pipeline := func(challenge string, signature []byte) error {
+		steps := []func() error{
+			func() error {
+				return VerifySignature(signedData, signature, credentialBytes, false)
+			},
+			func() error { return VerifyCeremonyType(&par.Response.CollectedClientData, AssertCeremony) },
+			func() error { return VerifyChallenge(&par.Response.CollectedClientData, challenge) },
+			func() error { return VerifyRPIDHash(&par.Response.AuthenticatorData, rpIDHash[:], nil) },
+			func() error { return VerifyFlags(&par.Response.AuthenticatorData, false) },
+		}
+
+		for _, step := range steps {
+			if err := step(); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	assert.NoError(t, pipeline(benchmarkChallenge, par.Response.Signature))
+
+	err = pipeline("invalid", par.Response.Signature)
+	require.IsType(t, &Error{}, err)
+	assert.Equal(t, CodeChallengeMismatch, err.(*Error).Code)
+
+	err = pipeline(benchmarkChallenge, []byte{1, 2, 3})
+	require.IsType(t, &Error{}, err)
+	assert.Equal(t, CodeSignatureInvalid, err.(*Error).Code)
+
+	// The default pipeline composed by Verify produces the same result.
+	assert.NoError(t, par.Verify(benchmarkChallenge, "example.com", []string{"https://example.com"}, "", false, credentialBytes))
+}
@@ -2,27 +2,51 @@ package webauthncbor
 
 import "github.com/fxamacker/cbor/v2"
 
-const nestedLevelsAllowed = 4
+const (
+	// nestedLevelsAllowed is the maximum depth of nested arrays and maps. The deepest structure decoded is the
+	// attestation object of the compound format which contains the array of attestation statements which contain the
+	// x5c array.
+	nestedLevelsAllowed = 5
+
+	// arrayElementsAllowed is the maximum number of elements in an array. The largest array decoded is the x5c
+	// certificate chain.
+	arrayElementsAllowed = 64
+
+	// mapPairsAllowed is the maximum number of key-value pairs in a map.
+	mapPairsAllowed = 64
+)
+
+// RawMessage is a raw encoded CBOR data item, which is used to delay the decoding of a data item until its type is
+// known.
+type RawMessage = cbor.RawMessage
 
 // ctap2CBORDecMode is the cbor.DecMode following the CTAP2 canonical CBOR encoding form
-// (https://fidoalliance.org/specs/fido-v2.0-ps-20190130/fido-client-to-authenticator-protocol-v2.0-ps-20190130.html#message-encoding)
+// (https://fidoalliance.org/specs/fido-v2.0-ps-20190130/fido-client-to-authenticator-protocol-v2.0-ps-20190130.html#message-encoding).
+// It's used for all decoding so that the limits apply to every attestation object, attestation statement, public key,
+// and extension. Duplicate map keys, indefinite lengths, and tags are rejected as they are not valid in the canonical
+// form and would otherwise allow the same data to have more than one encoding.
 var ctap2CBORDecMode, _ = cbor.DecOptions{
-	DupMapKey:       cbor.DupMapKeyEnforcedAPF,
-	MaxNestedLevels: nestedLevelsAllowed,
-	IndefLength:     cbor.IndefLengthForbidden,
-	TagsMd:          cbor.TagsForbidden,
+	DupMapKey:        cbor.DupMapKeyEnforcedAPF,
+	MaxNestedLevels:  nestedLevelsAllowed,
+	MaxArrayElements: arrayElementsAllowed,
+	MaxMapPairs:      mapPairsAllowed,
+	IndefLength:      cbor.IndefLengthForbidden,
+	TagsMd:           cbor.TagsForbidden,
 }.DecMode()
 
 var ctap2CBOREncMode, _ = cbor.CTAP2EncOptions().EncMode()
 
 // Unmarshal parses the CBOR-encoded data into the value pointed to by v
-// following the CTAP2 canonical CBOR encoding form.
+// following the CTAP2 canonical CBOR encoding form. The data must contain exactly one CBOR data item.
 // (https://fidoalliance.org/specs/fido-v2.0-ps-20190130/fido-client-to-authenticator-protocol-v2.0-ps-20190130.html#message-encoding)
 func Unmarshal(data []byte, v interface{}) error {
-	// TODO (james-d-elliott): investigate the specific use case for Unmarshal vs UnmarshalFirst to determine the edge cases where this may be useful.
-	_, err := ctap2CBORDecMode.UnmarshalFirst(data, v)
+	return ctap2CBORDecMode.Unmarshal(data, v)
+}
 
-	return err
+// UnmarshalFirst parses the first CBOR data item into the value pointed to by v following the CTAP2 canonical CBOR
+// encoding form and returns the remaining bytes. The remaining bytes are a sub-slice of data.
+func UnmarshalFirst(data []byte, v interface{}) (rest []byte, err error) {
+	return ctap2CBORDecMode.UnmarshalFirst(data, v)
 }
 
 // Marshal encodes the value pointed to by v
@@ -0,0 +1,75 @@
+package webauthncbor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshal(t *testing.T) {
+	testCases := []struct {
+		name  string
+		have  []byte
+		err   bool
+		first bool
+	}{
+		{"ShouldDecodeMap", []byte{0xa1, 0x01, 0x02}, false, false},
+		{"ShouldDecodeNestedArrays", []byte{0x81, 0x81, 0x81, 0x81, 0x81, 0x00}, false, false},
+		{"ShouldDecodeMaximumArrayElements", append([]byte{0x98, 0x40}, make([]byte, 64)...), false, false},
+		{"ShouldRejectTrailingData", []byte{0xa1, 0x01, 0x02, 0x00}, true, false},
+		{"ShouldRejectDuplicateMapKeys", []byte{0xa2, 0x01, 0x02, 0x01, 0x03}, true, true},
+		{"ShouldRejectIndefiniteLength", []byte{0xbf, 0x01, 0x02, 0xff}, true, true},
+		{"ShouldRejectTags", []byte{0xc1, 0x1a, 0x00, 0x00, 0x00, 0x00}, true, true},
+		{"ShouldRejectExcessiveNesting", []byte{0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x00}, true, true},
+		{"ShouldRejectExcessiveArrayElements", append([]byte{0x98, 0x41}, make([]byte, 65)...), true, true},
+		{"ShouldRejectExcessiveMapPairs", append([]byte{0xb8, 0x41}, mapPairs(65)...), true, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var v interface{}
+
+			err := Unmarshal(tc.have, &v)
+
+			if tc.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			_, err = UnmarshalFirst(tc.have, &v)
+
+			if tc.first {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalFirst(t *testing.T) {
+	var v map[int]int
+
+	rest, err := UnmarshalFirst([]byte{0xa1, 0x01, 0x02, 0x00}, &v)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]int{1: 2}, v)
+	assert.True(t, bytes.Equal([]byte{0x00}, rest))
+}
+
+// mapPairs returns n encoded key-value pairs with unique small integer keys.
+func mapPairs(n int) []byte {
+	pairs := make([]byte, 0, n*3)
+
+	for i := 0; i < n; i++ {
+		if i < 24 {
+			pairs = append(pairs, byte(i), 0x00)
+		} else {
+			pairs = append(pairs, 0x18, byte(i), 0x00)
+		}
+	}
+
+	return pairs
+}
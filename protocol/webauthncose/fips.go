@@ -0,0 +1,27 @@
+package webauthncose
+
+// FIPSAlgorithms are the COSEAlgorithmIdentifier values of the FIPS approved signature algorithms which are accepted
+// when FIPS mode is enabled. EdDSA and ECDSA using the secp256k1 curve are not accepted, and the signatures of the
+// accepted algorithms are verified with the crypto/ecdsa and crypto/rsa packages which are backed by a validated
+// module when building with a boringcrypto enabled toolchain.
+var FIPSAlgorithms = []COSEAlgorithmIdentifier{AlgES256, AlgES384, AlgRS256, AlgPS256}
+
+// IsFIPSApproved returns true if the COSEAlgorithmIdentifier is one of the FIPSAlgorithms.
+func IsFIPSApproved(alg COSEAlgorithmIdentifier) bool {
+	for _, approved := range FIPSAlgorithms {
+		if alg == approved {
+			return true
+		}
+	}
+
+	return false
+}
+
+// verifyFIPSAlgorithm returns ErrUnsupportedAlgorithm if FIPS mode is enabled and the algorithm is not approved.
+func verifyFIPSAlgorithm(alg int64) error {
+	if FIPSMode && !IsFIPSApproved(COSEAlgorithmIdentifier(alg)) {
+		return ErrUnsupportedAlgorithm.WithDetails("Public key algorithm is not FIPS approved")
+	}
+
+	return nil
+}
@@ -0,0 +1,7 @@
+//go:build !webauthn_fips
+
+package webauthncose
+
+// FIPSMode is true when building with the webauthn_fips build tag, in which case only the signatures of the
+// FIPSAlgorithms are verified.
+const FIPSMode = false
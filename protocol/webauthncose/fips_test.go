@@ -0,0 +1,54 @@
+package webauthncose
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsFIPSApproved(t *testing.T) {
+	testCases := []struct {
+		name     string
+		alg      COSEAlgorithmIdentifier
+		expected bool
+	}{
+		{"ShouldApproveES256", AlgES256, true},
+		{"ShouldApproveES384", AlgES384, true},
+		{"ShouldApproveRS256", AlgRS256, true},
+		{"ShouldApprovePS256", AlgPS256, true},
+		{"ShouldNotApproveEdDSA", AlgEdDSA, false},
+		{"ShouldNotApproveES256K", AlgES256K, false},
+		{"ShouldNotApproveRS1", AlgRS1, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsFIPSApproved(tc.alg))
+		})
+	}
+}
+
+func TestVerifySignature_FIPSMode(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	data := []byte("Sample data to sign")
+
+	key := OKPPublicKeyData{
+		PublicKeyData: PublicKeyData{KeyType: int64(OctetKey), Algorithm: int64(AlgEdDSA)},
+		XCoord:        pub,
+	}
+
+	valid, err := VerifySignature(key, data, ed25519.Sign(priv, data))
+
+	if FIPSMode {
+		assert.False(t, valid)
+		assert.Equal(t, ErrUnsupportedAlgorithm.Type, err.(*Error).Type)
+	} else {
+		assert.True(t, valid)
+		assert.NoError(t, err)
+	}
+}
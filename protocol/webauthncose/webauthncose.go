@@ -117,9 +117,14 @@ func (k *EC2PublicKeyData) Verify(data []byte, sig []byte) (bool, error) {
 
 // Verify RSA Public Key Signature.
 func (k *RSAPublicKeyData) Verify(data []byte, sig []byte) (bool, error) {
+	// The exponent is limited to 3 bytes which covers every exponent used in practice.
+	if len(k.Exponent) == 0 || len(k.Exponent) > 3 {
+		return false, ErrUnsupportedKey
+	}
+
 	pubkey := &rsa.PublicKey{
 		N: big.NewInt(0).SetBytes(k.Modulus),
-		E: int(uint(k.Exponent[2]) | uint(k.Exponent[1])<<8 | uint(k.Exponent[0])<<16),
+		E: int(big.NewInt(0).SetBytes(k.Exponent).Int64()),
 	}
 
 	f := HasherFromCOSEAlg(COSEAlgorithmIdentifier(k.PublicKeyData.Algorithm))
@@ -180,28 +185,39 @@ func HasherFromCOSEAlg(coseAlg COSEAlgorithmIdentifier) func() hash.Hash {
 // ParsePublicKey figures out what kind of COSE material was provided and create the data for the new key.
 func ParsePublicKey(keyBytes []byte) (interface{}, error) {
 	pk := PublicKeyData{}
-	// TODO (james-d-elliott): investigate the ignored errors.
-	webauthncbor.Unmarshal(keyBytes, &pk)
+
+	if err := webauthncbor.Unmarshal(keyBytes, &pk); err != nil {
+		return nil, errInvalidPublicKey(err)
+	}
 
 	switch COSEKeyType(pk.KeyType) {
 	case OctetKey:
 		var o OKPPublicKeyData
 
-		webauthncbor.Unmarshal(keyBytes, &o)
+		if err := webauthncbor.Unmarshal(keyBytes, &o); err != nil {
+			return nil, errInvalidPublicKey(err)
+		}
+
 		o.PublicKeyData = pk
 
 		return o, nil
 	case EllipticKey:
 		var e EC2PublicKeyData
 
-		webauthncbor.Unmarshal(keyBytes, &e)
+		if err := webauthncbor.Unmarshal(keyBytes, &e); err != nil {
+			return nil, errInvalidPublicKey(err)
+		}
+
 		e.PublicKeyData = pk
 
 		return e, nil
 	case RSAKey:
 		var r RSAPublicKeyData
 
-		webauthncbor.Unmarshal(keyBytes, &r)
+		if err := webauthncbor.Unmarshal(keyBytes, &r); err != nil {
+			return nil, errInvalidPublicKey(err)
+		}
+
 		r.PublicKeyData = pk
 
 		return r, nil
@@ -210,6 +226,11 @@ func ParsePublicKey(keyBytes []byte) (interface{}, error) {
 	}
 }
 
+// errInvalidPublicKey returns the ErrUnsupportedKey with the error of decoding the COSE key in the details.
+func errInvalidPublicKey(err error) *Error {
+	return ErrUnsupportedKey.WithDetails(fmt.Sprintf("%s: %+v", ErrUnsupportedKey.Details, err))
+}
+
 // ParseFIDOPublicKey is only used when the appID extension is configured by the assertion response.
 func ParseFIDOPublicKey(keyBytes []byte) (data EC2PublicKeyData, err error) {
 	x, y := elliptic.Unmarshal(elliptic.P256(), keyBytes)
@@ -345,10 +366,22 @@ func (k *EC2PublicKeyData) TPMCurveID() tpm2.EllipticCurve {
 func VerifySignature(key interface{}, data []byte, sig []byte) (bool, error) {
 	switch k := key.(type) {
 	case OKPPublicKeyData:
+		if err := verifyFIPSAlgorithm(k.Algorithm); err != nil {
+			return false, err
+		}
+
 		return k.Verify(data, sig)
 	case EC2PublicKeyData:
+		if err := verifyFIPSAlgorithm(k.Algorithm); err != nil {
+			return false, err
+		}
+
 		return k.Verify(data, sig)
 	case RSAPublicKeyData:
+		if err := verifyFIPSAlgorithm(k.Algorithm); err != nil {
+			return false, err
+		}
+
 		return k.Verify(data, sig)
 	default:
 		return false, ErrUnsupportedKey
@@ -363,6 +396,10 @@ func DisplayPublicKey(cpk []byte) string {
 
 	switch k := parsedKey.(type) {
 	case RSAPublicKeyData:
+		if len(k.Exponent) != 3 {
+			return keyCannotDisplay
+		}
+
 		rKey := &rsa.PublicKey{
 			N: big.NewInt(0).SetBytes(k.Modulus),
 			E: int(uint(k.Exponent[2]) | uint(k.Exponent[1])<<8 | uint(k.Exponent[0])<<16),
@@ -2,10 +2,12 @@ package webauthncose
 
 import (
 	"crypto/rand"
+	"crypto/rsa"
 	"encoding/hex"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/ed25519"
 
 	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
@@ -105,6 +107,60 @@ func TestP256SignatureVerification(t *testing.T) {
 	assert.False(t, ok, "verification against bad data is successful!")
 }
 
+func TestRSASignatureVerificationInvalidExponent(t *testing.T) {
+	testCases := []struct {
+		name     string
+		exponent []byte
+		err      error
+	}{
+		{"ShouldRejectEmptyExponent", nil, ErrUnsupportedKey},
+		{"ShouldRejectLongExponent", []byte{0x01, 0x00, 0x00, 0x01}, ErrUnsupportedKey},
+		{"ShouldNotPanicShortExponent", []byte{0x03}, rsa.ErrVerification},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key := RSAPublicKeyData{
+				PublicKeyData: PublicKeyData{
+					KeyType:   3,    // RSA.
+					Algorithm: -257, // "RS256".
+				},
+				Modulus:  []byte{0x01, 0x02, 0x03},
+				Exponent: tc.exponent,
+			}
+
+			ok, err := VerifySignature(key, []byte("webauthnFTW"), []byte{0x01})
+			assert.False(t, ok)
+			assert.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func TestParsePublicKeyInvalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		have []byte
+		err  string
+	}{
+		{"ShouldRejectEmpty", nil, "Unsupported Public Key Type: EOF"},
+		{"ShouldRejectTrailingData", []byte{0xa1, 0x01, 0x02, 0x00}, "Unsupported Public Key Type: cbor: 1 bytes of extraneous data starting at index 3"},
+		{"ShouldRejectUnknownKeyType", []byte{0xa1, 0x01, 0x09}, "Unsupported Public Key Type"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := ParsePublicKey(tc.have)
+			assert.Nil(t, key)
+
+			var e *Error
+
+			require.ErrorAs(t, err, &e)
+			assert.Equal(t, ErrUnsupportedKey.Type, e.Type)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
 func TestOKPDisplayPublicKey(t *testing.T) {
 	// Sample public key generated from ed25519.GenerateKey(rand.Reader).
 	var pub ed25519.PublicKey = []byte{0x7b, 0x88, 0x10, 0x24, 0xad, 0xc9, 0x82, 0xd3, 0x80, 0xb8, 0x77, 0x1e, 0x3b, 0x9b, 0xf8, 0xe4, 0xb3, 0x99, 0x8b, 0xc7, 0xd0, 0x58, 0x30, 0x66, 0x2, 0xce, 0x4d, 0xf, 0x2f, 0xe4, 0xb7, 0x81}
@@ -0,0 +1,160 @@
+// Package webauthndto provides flattened variants of the options and responses of the ceremonies for schemas which
+// cannot easily model the protocol types, such as GraphQL schemas. The DTOs only contain scalars, lists of scalars, and
+// lists of DTOs: the binary values are base64url encoded strings, the entities are flattened into their fields, and
+// the extensions, which have no fixed structure, are JSON encoded strings.
+//
+// The options are converted with NewCreationOptions and NewRequestOptions, and the responses of the client are
+// converted back with the Parse methods whose result is passed to webauthn.CreateCredential or
+// webauthn.ValidateLogin.
+package webauthndto
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// CredentialParameter is the flattened protocol.CredentialParameter.
+type CredentialParameter struct {
+	Type      string `json:"type"`
+	Algorithm int    `json:"alg"`
+}
+
+// CredentialDescriptor is the flattened protocol.CredentialDescriptor.
+type CredentialDescriptor struct {
+	ID         string   `json:"id"`
+	Type       string   `json:"type"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+// CreationOptions is the flattened protocol.PublicKeyCredentialCreationOptions.
+type CreationOptions struct {
+	Challenge       string `json:"challenge"`
+	Timeout         int    `json:"timeout,omitempty"`
+	RPID            string `json:"rpId"`
+	RPName          string `json:"rpName"`
+	UserID          string `json:"userId"`
+	UserName        string `json:"userName"`
+	UserDisplayName string `json:"userDisplayName"`
+
+	Parameters         []CredentialParameter  `json:"pubKeyCredParams,omitempty"`
+	ExcludeCredentials []CredentialDescriptor `json:"excludeCredentials,omitempty"`
+
+	AuthenticatorAttachment string `json:"authenticatorAttachment,omitempty"`
+	ResidentKey             string `json:"residentKey,omitempty"`
+	RequireResidentKey      *bool  `json:"requireResidentKey,omitempty"`
+	UserVerification        string `json:"userVerification,omitempty"`
+	Attestation             string `json:"attestation,omitempty"`
+
+	// ExtensionsJSON is the JSON encoded protocol.AuthenticationExtensions, or empty if there are no extensions.
+	ExtensionsJSON string `json:"extensionsJson,omitempty"`
+}
+
+// RequestOptions is the flattened protocol.PublicKeyCredentialRequestOptions.
+type RequestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	Timeout          int                    `json:"timeout,omitempty"`
+	RPID             string                 `json:"rpId,omitempty"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials,omitempty"`
+	UserVerification string                 `json:"userVerification,omitempty"`
+
+	// ExtensionsJSON is the JSON encoded protocol.AuthenticationExtensions, or empty if there are no extensions.
+	ExtensionsJSON string `json:"extensionsJson,omitempty"`
+}
+
+// NewCreationOptions returns the CreationOptions of the options.
+func NewCreationOptions(options *protocol.PublicKeyCredentialCreationOptions) (dto CreationOptions, err error) {
+	dto = CreationOptions{
+		Challenge:               options.Challenge.String(),
+		Timeout:                 options.Timeout,
+		RPID:                    options.RelyingParty.ID,
+		RPName:                  options.RelyingParty.Name,
+		UserName:                options.User.Name,
+		UserDisplayName:         options.User.DisplayName,
+		ExcludeCredentials:      newCredentialDescriptors(options.CredentialExcludeList),
+		AuthenticatorAttachment: string(options.AuthenticatorSelection.AuthenticatorAttachment),
+		ResidentKey:             string(options.AuthenticatorSelection.ResidentKey),
+		RequireResidentKey:      options.AuthenticatorSelection.RequireResidentKey,
+		UserVerification:        string(options.AuthenticatorSelection.UserVerification),
+		Attestation:             string(options.Attestation),
+	}
+
+	if dto.UserID, err = userID(options.User.ID); err != nil {
+		return dto, err
+	}
+
+	for _, parameter := range options.Parameters {
+		dto.Parameters = append(dto.Parameters, CredentialParameter{Type: string(parameter.Type), Algorithm: int(parameter.Algorithm)})
+	}
+
+	if dto.ExtensionsJSON, err = extensionsJSON(options.Extensions); err != nil {
+		return dto, err
+	}
+
+	return dto, nil
+}
+
+// NewRequestOptions returns the RequestOptions of the options.
+func NewRequestOptions(options *protocol.PublicKeyCredentialRequestOptions) (dto RequestOptions, err error) {
+	dto = RequestOptions{
+		Challenge:        options.Challenge.String(),
+		Timeout:          options.Timeout,
+		RPID:             options.RelyingPartyID,
+		AllowCredentials: newCredentialDescriptors(options.AllowedCredentials),
+		UserVerification: string(options.UserVerification),
+	}
+
+	if dto.ExtensionsJSON, err = extensionsJSON(options.Extensions); err != nil {
+		return dto, err
+	}
+
+	return dto, nil
+}
+
+func newCredentialDescriptors(descriptors []protocol.CredentialDescriptor) (dtos []CredentialDescriptor) {
+	for _, descriptor := range descriptors {
+		dto := CredentialDescriptor{
+			ID:   descriptor.CredentialID.String(),
+			Type: string(descriptor.Type),
+		}
+
+		for _, transport := range descriptor.Transport {
+			dto.Transports = append(dto.Transports, string(transport))
+		}
+
+		dtos = append(dtos, dto)
+	}
+
+	return dtos
+}
+
+// userID returns the base64url encoded user handle. A string is already encoded as it's encoded as is in the JSON.
+func userID(id interface{}) (string, error) {
+	switch value := id.(type) {
+	case protocol.URLEncodedBase64:
+		return value.String(), nil
+	case []byte:
+		return base64.RawURLEncoding.EncodeToString(value), nil
+	case string:
+		return value, nil
+	case nil:
+		return "", nil
+	default:
+		return "", fmt.Errorf("webauthndto: unsupported user ID type %T", id)
+	}
+}
+
+func extensionsJSON(extensions map[string]interface{}) (string, error) {
+	if len(extensions) == 0 {
+		return "", nil
+	}
+
+	data, err := json.Marshal(extensions)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,190 @@
+package webauthndto
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// RegistrationResponse is the flattened protocol.CredentialCreationResponse returned by navigator.credentials.create.
+type RegistrationResponse struct {
+	ID                      string   `json:"id"`
+	RawID                   string   `json:"rawId"`
+	Type                    string   `json:"type"`
+	AuthenticatorAttachment string   `json:"authenticatorAttachment,omitempty"`
+	ClientDataJSON          string   `json:"clientDataJSON"`
+	AttestationObject       string   `json:"attestationObject"`
+	Transports              []string `json:"transports,omitempty"`
+
+	// ClientExtensionResultsJSON is the JSON encoded protocol.AuthenticationExtensionsClientOutputs, or empty if there
+	// are no results.
+	ClientExtensionResultsJSON string `json:"clientExtensionResultsJson,omitempty"`
+}
+
+// AssertionResponse is the flattened protocol.CredentialAssertionResponse returned by navigator.credentials.get.
+type AssertionResponse struct {
+	ID                      string `json:"id"`
+	RawID                   string `json:"rawId"`
+	Type                    string `json:"type"`
+	AuthenticatorAttachment string `json:"authenticatorAttachment,omitempty"`
+	ClientDataJSON          string `json:"clientDataJSON"`
+	AuthenticatorData       string `json:"authenticatorData"`
+	Signature               string `json:"signature"`
+	UserHandle              string `json:"userHandle,omitempty"`
+
+	// ClientExtensionResultsJSON is the JSON encoded protocol.AuthenticationExtensionsClientOutputs, or empty if there
+	// are no results.
+	ClientExtensionResultsJSON string `json:"clientExtensionResultsJson,omitempty"`
+}
+
+// NewRegistrationResponse returns the RegistrationResponse of the response.
+func NewRegistrationResponse(response *protocol.CredentialCreationResponse) (dto RegistrationResponse, err error) {
+	dto = RegistrationResponse{
+		ID:                      response.ID,
+		RawID:                   response.RawID.String(),
+		Type:                    response.Type,
+		AuthenticatorAttachment: response.AuthenticatorAttachment,
+		ClientDataJSON:          response.AttestationResponse.ClientDataJSON.String(),
+		AttestationObject:       response.AttestationResponse.AttestationObject.String(),
+		Transports:              response.AttestationResponse.Transports,
+	}
+
+	if dto.ClientExtensionResultsJSON, err = extensionsJSON(response.ClientExtensionResults); err != nil {
+		return dto, err
+	}
+
+	return dto, nil
+}
+
+// NewAssertionResponse returns the AssertionResponse of the response.
+func NewAssertionResponse(response *protocol.CredentialAssertionResponse) (dto AssertionResponse, err error) {
+	dto = AssertionResponse{
+		ID:                      response.ID,
+		RawID:                   response.RawID.String(),
+		Type:                    response.Type,
+		AuthenticatorAttachment: response.AuthenticatorAttachment,
+		ClientDataJSON:          response.AssertionResponse.ClientDataJSON.String(),
+		AuthenticatorData:       response.AssertionResponse.AuthenticatorData.String(),
+		Signature:               response.AssertionResponse.Signature.String(),
+		UserHandle:              response.AssertionResponse.UserHandle.String(),
+	}
+
+	if dto.ClientExtensionResultsJSON, err = extensionsJSON(response.ClientExtensionResults); err != nil {
+		return dto, err
+	}
+
+	return dto, nil
+}
+
+// CredentialCreationResponse returns the protocol.CredentialCreationResponse of the DTO.
+func (r RegistrationResponse) CredentialCreationResponse() (response *protocol.CredentialCreationResponse, err error) {
+	d := &decoder{details: "Parse error for Registration"}
+
+	response = &protocol.CredentialCreationResponse{
+		PublicKeyCredential: protocol.PublicKeyCredential{
+			Credential:              protocol.Credential{ID: r.ID, Type: r.Type},
+			RawID:                   d.bytes("rawId", r.RawID),
+			AuthenticatorAttachment: r.AuthenticatorAttachment,
+		},
+		AttestationResponse: protocol.AuthenticatorAttestationResponse{
+			AuthenticatorResponse: protocol.AuthenticatorResponse{ClientDataJSON: d.bytes("clientDataJSON", r.ClientDataJSON)},
+			AttestationObject:     d.bytes("attestationObject", r.AttestationObject),
+			Transports:            r.Transports,
+		},
+	}
+
+	response.ClientExtensionResults = d.extensions(r.ClientExtensionResultsJSON)
+
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return response, nil
+}
+
+// Parse returns the protocol.ParsedCredentialCreationData of the DTO which can be passed to webauthn.CreateCredential.
+func (r RegistrationResponse) Parse() (*protocol.ParsedCredentialCreationData, error) {
+	response, err := r.CredentialCreationResponse()
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Parse()
+}
+
+// CredentialAssertionResponse returns the protocol.CredentialAssertionResponse of the DTO.
+func (r AssertionResponse) CredentialAssertionResponse() (response *protocol.CredentialAssertionResponse, err error) {
+	d := &decoder{details: "Parse error for Assertion"}
+
+	response = &protocol.CredentialAssertionResponse{
+		PublicKeyCredential: protocol.PublicKeyCredential{
+			Credential:              protocol.Credential{ID: r.ID, Type: r.Type},
+			RawID:                   d.bytes("rawId", r.RawID),
+			AuthenticatorAttachment: r.AuthenticatorAttachment,
+		},
+		AssertionResponse: protocol.AuthenticatorAssertionResponse{
+			AuthenticatorResponse: protocol.AuthenticatorResponse{ClientDataJSON: d.bytes("clientDataJSON", r.ClientDataJSON)},
+			AuthenticatorData:     d.bytes("authenticatorData", r.AuthenticatorData),
+			Signature:             d.bytes("signature", r.Signature),
+			UserHandle:            d.bytes("userHandle", r.UserHandle),
+		},
+	}
+
+	response.ClientExtensionResults = d.extensions(r.ClientExtensionResultsJSON)
+
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return response, nil
+}
+
+// Parse returns the protocol.ParsedCredentialAssertionData of the DTO which can be passed to webauthn.ValidateLogin or
+// webauthn.ValidateDiscoverableLogin.
+func (r AssertionResponse) Parse() (*protocol.ParsedCredentialAssertionData, error) {
+	response, err := r.CredentialAssertionResponse()
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Parse()
+}
+
+// decoder decodes the fields of a DTO and keeps the first error.
+type decoder struct {
+	details string
+	err     error
+}
+
+func (d *decoder) bytes(name, value string) protocol.URLEncodedBase64 {
+	if d.err != nil || value == "" {
+		return nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		d.err = protocol.ErrBadRequest.WithCode(protocol.CodeResponseInvalid).WithDetails(d.details).WithInfo(name + " not base64.RawURLEncoded")
+
+		return nil
+	}
+
+	return decoded
+}
+
+func (d *decoder) extensions(value string) protocol.AuthenticationExtensionsClientOutputs {
+	if d.err != nil || value == "" {
+		return nil
+	}
+
+	var extensions protocol.AuthenticationExtensionsClientOutputs
+
+	if err := json.Unmarshal([]byte(value), &extensions); err != nil {
+		d.err = protocol.ErrBadRequest.WithCode(protocol.CodeResponseInvalid).WithDetails(d.details).WithInfo("clientExtensionResultsJson is not valid JSON")
+
+		return nil
+	}
+
+	return extensions
+}
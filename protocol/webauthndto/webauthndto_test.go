@@ -0,0 +1,155 @@
+package webauthndto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+type testUser struct {
+	credentials []webauthn.Credential
+}
+
+func (u *testUser) WebAuthnID() []byte {
+	return []byte("1234")
+}
+
+func (u *testUser) WebAuthnName() string {
+	return "john"
+}
+
+func (u *testUser) WebAuthnDisplayName() string {
+	return "John"
+}
+
+func (u *testUser) WebAuthnIcon() string {
+	return ""
+}
+
+func (u *testUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+func TestCeremonies(t *testing.T) {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+	})
+	require.NoError(t, err)
+
+	user := &testUser{}
+	authenticator := &webauthntest.Authenticator{}
+
+	creation, session, err := w.BeginRegistration(user, webauthn.WithExtensions(protocol.AuthenticationExtensions{"credProps": true}))
+	require.NoError(t, err)
+
+	options, err := NewCreationOptions(&creation.Response)
+	require.NoError(t, err)
+	assert.Equal(t, creation.Response.Challenge.String(), options.Challenge)
+	assert.Equal(t, "example.com", options.RPID)
+	assert.Equal(t, "MTIzNA", options.UserID)
+	assert.Equal(t, "john", options.UserName)
+	assert.Equal(t, `{"credProps":true}`, options.ExtensionsJSON)
+	assert.Contains(t, options.Parameters, CredentialParameter{Type: "public-key", Algorithm: int(webauthncose.AlgES256)})
+
+	attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+	require.NoError(t, err)
+
+	registration, err := NewRegistrationResponse(attestation)
+	require.NoError(t, err)
+
+	parsedCreation, err := registration.Parse()
+	require.NoError(t, err)
+
+	credential, err := w.CreateCredential(user, *session, parsedCreation)
+	require.NoError(t, err)
+
+	user.credentials = append(user.credentials, *credential)
+
+	assertion, session, err := w.BeginLogin(user)
+	require.NoError(t, err)
+
+	requestOptions, err := NewRequestOptions(&assertion.Response)
+	require.NoError(t, err)
+	require.Len(t, requestOptions.AllowCredentials, 1)
+	assert.Equal(t, protocol.URLEncodedBase64(credential.ID).String(), requestOptions.AllowCredentials[0].ID)
+	assert.Empty(t, requestOptions.ExtensionsJSON)
+
+	response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+	require.NoError(t, err)
+
+	login, err := NewAssertionResponse(response)
+	require.NoError(t, err)
+	assert.Equal(t, "MTIzNA", login.UserHandle)
+
+	parsedAssertion, err := login.Parse()
+	require.NoError(t, err)
+
+	credential, err = w.ValidateLogin(user, *session, parsedAssertion)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), credential.Authenticator.SignCount)
+}
+
+func TestResponseErrors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		parse func() error
+		info  string
+	}{
+		{"ShouldRejectRegistrationBinary", func() error {
+			_, err := RegistrationResponse{ID: "aWQ", Type: "public-key", ClientDataJSON: "!"}.Parse()
+			return err
+		}, "clientDataJSON not base64.RawURLEncoded"},
+		{"ShouldRejectRegistrationExtensions", func() error {
+			_, err := RegistrationResponse{ID: "aWQ", Type: "public-key", ClientExtensionResultsJSON: "{"}.Parse()
+			return err
+		}, "clientExtensionResultsJson is not valid JSON"},
+		{"ShouldRejectAssertionBinary", func() error {
+			_, err := AssertionResponse{ID: "aWQ", Type: "public-key", Signature: "!"}.Parse()
+			return err
+		}, "signature not base64.RawURLEncoded"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.parse()
+			require.Error(t, err)
+
+			e, ok := err.(*protocol.Error)
+			require.True(t, ok)
+			assert.Equal(t, protocol.CodeResponseInvalid, e.Code)
+			assert.Equal(t, tc.info, e.DevInfo)
+		})
+	}
+}
+
+func TestUserID(t *testing.T) {
+	testCases := []struct {
+		name     string
+		id       interface{}
+		expected string
+		err      bool
+	}{
+		{"ShouldEncodeURLEncodedBase64", protocol.URLEncodedBase64("1234"), "MTIzNA", false},
+		{"ShouldEncodeBytes", []byte("1234"), "MTIzNA", false},
+		{"ShouldKeepString", "MTIzNA", "MTIzNA", false},
+		{"ShouldHandleNil", nil, "", false},
+		{"ShouldRejectOther", 1234, "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := userID(tc.id)
+
+			assert.Equal(t, tc.err, err != nil)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
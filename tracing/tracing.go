@@ -0,0 +1,113 @@
+// Package tracing provides the minimal tracing abstraction used to instrument the WebAuthn ceremonies and the metadata
+// fetches. The interfaces mirror the OpenTelemetry trace API so that an OpenTelemetry TracerProvider can be used with a
+// small adapter, for example:
+//
+//	type otelProvider struct{ trace.TracerProvider }
+//
+//	func (p otelProvider) Tracer(name string) tracing.Tracer { return otelTracer{p.TracerProvider.Tracer(name)} }
+//
+//	type otelTracer struct{ trace.Tracer }
+//
+//	func (t otelTracer) Start(ctx context.Context, name string) (context.Context, tracing.Span) {
+//		ctx, span := t.Tracer.Start(ctx, name)
+//
+//		return ctx, otelSpan{span}
+//	}
+//
+//	type otelSpan struct{ trace.Span }
+//
+//	func (s otelSpan) SetAttributes(attributes ...tracing.Attribute) {
+//		for _, a := range attributes {
+//			s.Span.SetAttributes(attribute.String(a.Key, a.Value))
+//		}
+//	}
+//
+//	func (s otelSpan) RecordError(err error) {
+//		s.Span.RecordError(err)
+//		s.Span.SetStatus(codes.Error, err.Error())
+//	}
+//
+//	func (s otelSpan) End() { s.Span.End() }
+//
+// Tracing is disabled unless a TracerProvider is configured.
+package tracing
+
+import (
+	"context"
+)
+
+// InstrumentationName is the name passed to TracerProvider.Tracer.
+const InstrumentationName = "github.com/go-webauthn/webauthn"
+
+// Attribute keys used by the spans.
+const (
+	AttributeRPID              = "webauthn.rp_id"
+	AttributeAAGUID            = "webauthn.aaguid"
+	AttributeAttestationFormat = "webauthn.attestation.format"
+	AttributeMetadataURL       = "webauthn.metadata.url"
+)
+
+// TracerProvider provides a Tracer. It is the equivalent of the OpenTelemetry trace.TracerProvider.
+type TracerProvider interface {
+	Tracer(name string) Tracer
+}
+
+// Tracer starts spans. It is the equivalent of the OpenTelemetry trace.Tracer.
+type Tracer interface {
+	Start(ctx context.Context, spanName string) (context.Context, Span)
+}
+
+// Span is an individual traced operation. It is the equivalent of the OpenTelemetry trace.Span.
+type Span interface {
+	SetAttributes(attributes ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Attribute is a key value pair describing a Span.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// String returns a new Attribute.
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Start a new Span with the provided attributes using the TracerProvider. If the TracerProvider is nil a Span which
+// does nothing is returned.
+func Start(ctx context.Context, provider TracerProvider, spanName string, attributes ...Attribute) (context.Context, Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if provider == nil {
+		return ctx, noopSpan{}
+	}
+
+	ctx, span := provider.Tracer(InstrumentationName).Start(ctx, spanName)
+
+	if len(attributes) != 0 {
+		span.SetAttributes(attributes...)
+	}
+
+	return ctx, span
+}
+
+// End the Span recording the error if it is not nil.
+func End(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	span.End()
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(_ ...Attribute) {}
+
+func (noopSpan) RecordError(_ error) {}
+
+func (noopSpan) End() {}
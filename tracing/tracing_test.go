@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testProvider struct {
+	names []string
+	spans []*testSpan
+}
+
+func (p *testProvider) Tracer(name string) Tracer {
+	p.names = append(p.names, name)
+
+	return p
+}
+
+func (p *testProvider) Start(ctx context.Context, spanName string) (context.Context, Span) {
+	span := &testSpan{name: spanName}
+
+	p.spans = append(p.spans, span)
+
+	return ctx, span
+}
+
+type testSpan struct {
+	name       string
+	attributes []Attribute
+	err        error
+	ended      bool
+}
+
+func (s *testSpan) SetAttributes(attributes ...Attribute) {
+	s.attributes = append(s.attributes, attributes...)
+}
+
+func (s *testSpan) RecordError(err error) {
+	s.err = err
+}
+
+func (s *testSpan) End() {
+	s.ended = true
+}
+
+func TestStart(t *testing.T) {
+	provider := &testProvider{}
+
+	ctx, span := Start(context.Background(), provider, "example", String(AttributeRPID, "example.com"))
+
+	assert.NotNil(t, ctx)
+	assert.Equal(t, []string{InstrumentationName}, provider.names)
+	assert.Equal(t, &testSpan{name: "example", attributes: []Attribute{{Key: AttributeRPID, Value: "example.com"}}}, span)
+
+	End(span, errors.New("bad"))
+
+	assert.True(t, provider.spans[0].ended)
+	assert.EqualError(t, provider.spans[0].err, "bad")
+}
+
+func TestStartNilProvider(t *testing.T) {
+	ctx, span := Start(context.TODO(), nil, "example")
+
+	assert.NotNil(t, ctx)
+	assert.Equal(t, noopSpan{}, span)
+
+	assert.NotPanics(t, func() {
+		End(span, errors.New("bad"))
+	})
+}
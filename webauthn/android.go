@@ -0,0 +1,62 @@
+package webauthn
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// androidTransports are the transports assumed for registrations from the Android FIDO2 API, which does not report
+// the transports of platform credentials.
+var androidTransports = []protocol.AuthenticatorTransport{protocol.Internal, protocol.Hybrid}
+
+// originVerifier returns the OriginVerifier used to verify the origin of the responses.
+func (config *Config) originVerifier() protocol.OriginVerifier {
+	if config.AndroidCompatibility {
+		return protocol.AndroidOriginVerifier(config.OriginVerifier)
+	}
+
+	return config.OriginVerifier
+}
+
+// compatibleBody returns the body of a response with the leniencies of the AndroidCompatibility applied.
+func (config *Config) compatibleBody(body []byte) []byte {
+	if config == nil || !config.AndroidCompatibility {
+		return body
+	}
+
+	return protocol.NormalizeResponseBase64(body)
+}
+
+// compatibleRequest replaces the body of the request with the body returned by compatibleBody. A body which exceeds
+// the response body limit is left as is so it's rejected by the parser.
+func (config *Config) compatibleRequest(response *http.Request) {
+	if config == nil || !config.AndroidCompatibility || response == nil || response.Body == nil {
+		return
+	}
+
+	reader := response.Body
+
+	if limit := config.responseBodyLimit(); limit > 0 {
+		reader = io.NopCloser(io.LimitReader(response.Body, limit))
+	}
+
+	body, _ := io.ReadAll(reader)
+
+	response.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(config.compatibleBody(body)), response.Body), response.Body}
+}
+
+// compatibleTransports assumes the internal and hybrid transports for a registration without transports when the
+// AndroidCompatibility is enabled, unless the registration was made with a cross-platform authenticator.
+func (config *Config) compatibleTransports(parsedResponse *protocol.ParsedCredentialCreationData) {
+	if !config.AndroidCompatibility || len(parsedResponse.Response.Transports) != 0 || parsedResponse.AuthenticatorAttachment == protocol.CrossPlatform {
+		return
+	}
+
+	parsedResponse.Response.Transports = append([]protocol.AuthenticatorTransport(nil), androidTransports...)
+}
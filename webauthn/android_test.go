@@ -0,0 +1,117 @@
+package webauthn_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+// androidResponse encodes the response the way applications using the Android FIDO2 API commonly do, with the binary
+// fields encoded with padded standard base64 and without the transports.
+func androidResponse(t *testing.T, response interface{}) []byte {
+	data, err := json.Marshal(response)
+	require.NoError(t, err)
+
+	var fields map[string]interface{}
+
+	require.NoError(t, json.Unmarshal(data, &fields))
+
+	reencode := func(values map[string]interface{}, names ...string) {
+		for _, name := range names {
+			value, ok := values[name].(string)
+			if !ok {
+				continue
+			}
+
+			decoded, err := base64.RawURLEncoding.DecodeString(value)
+			require.NoError(t, err)
+
+			values[name] = base64.StdEncoding.EncodeToString(decoded)
+		}
+	}
+
+	inner := fields["response"].(map[string]interface{})
+
+	delete(inner, "transports")
+
+	reencode(fields, "id", "rawId")
+	reencode(inner, "clientDataJSON", "attestationObject", "authenticatorData", "signature", "userHandle")
+
+	data, err = json.Marshal(fields)
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestWebAuthn_AndroidCompatibility(t *testing.T) {
+	hash := sha256.Sum256([]byte("signing certificate"))
+
+	testCases := []struct {
+		name          string
+		compatibility bool
+		attachment    protocol.AuthenticatorAttachment
+		transports    []protocol.AuthenticatorTransport
+		expected      protocol.ErrorCode
+	}{
+		{"ShouldAcceptAndroidResponses", true, "", []protocol.AuthenticatorTransport{protocol.Internal, protocol.Hybrid}, ""},
+		{"ShouldAcceptAndroidResponsesFromSecurityKeys", true, protocol.CrossPlatform, nil, ""},
+		{"ShouldRejectAndroidResponsesWithoutCompatibility", false, "", nil, protocol.CodeResponseInvalid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := webauthn.New(&webauthn.Config{
+				RPID:                 "example.com",
+				RPDisplayName:        "Example",
+				RPOrigins:            []string{"https://example.com", protocol.AndroidOriginPrefix + base64.RawURLEncoding.EncodeToString(hash[:])},
+				AndroidCompatibility: tc.compatibility,
+			})
+			require.NoError(t, err)
+
+			origin := protocol.AndroidOriginPrefix + base64.StdEncoding.EncodeToString(hash[:])
+
+			user := &bytesUser{}
+			authenticator := &webauthntest.Authenticator{Attachment: tc.attachment}
+
+			creation, session, err := w.BeginRegistration(user)
+			require.NoError(t, err)
+
+			attestation, _, err := authenticator.CreateCredential(creation.Response, origin)
+			require.NoError(t, err)
+
+			credential, err := w.FinishRegistrationBytes(user, *session, androidResponse(t, attestation))
+
+			if tc.expected != "" {
+				assertErrorCode(t, tc.expected, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.transports, credential.Transport)
+
+			user.credentials = append(user.credentials, *credential)
+
+			assertion, session, err := w.BeginLogin(user)
+			require.NoError(t, err)
+
+			response, err := authenticator.GetAssertion(assertion.Response, origin)
+			require.NoError(t, err)
+
+			r, err := webauthntest.NewRequest(json.RawMessage(androidResponse(t, response)))
+			require.NoError(t, err)
+
+			_, err = w.FinishLogin(user, *session, r)
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package webauthn
+
+import (
+	"context"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// HighAssurancePolicy returns the provided protocol.AttestationPolicy with the requirements of a high assurance
+// registration enabled. The authenticator must provide a verifiable attestation statement which chains up to one of
+// the attestation root certificates of its metadata, and the metadata must not report an undesired status.
+func HighAssurancePolicy(policy protocol.AttestationPolicy) protocol.AttestationPolicy {
+	policy.RequireMetadata = true
+	policy.VerifyTrustPath = true
+	policy.RequireVerifiableAttestation = true
+
+	return policy
+}
+
+// BeginHighAssuranceRegistration is the same as BeginRegistration except the registration is a high assurance
+// registration, which is useful for enrolling credentials used for step-up authentication in regulated environments.
+// The direct attestation conveyance preference and user verification are requested regardless of the options. The
+// returned SessionData is marked so finishing the registration requires user verification and verifies the
+// attestation according to the HighAssurancePolicy of the AttestationPolicy.
+func (webauthn *WebAuthn) BeginHighAssuranceRegistration(user User, opts ...RegistrationOption) (creation *protocol.CredentialCreation, session *SessionData, err error) {
+	return webauthn.BeginHighAssuranceRegistrationCtx(context.Background(), user, opts...)
+}
+
+// BeginHighAssuranceRegistrationCtx is the same as BeginHighAssuranceRegistration except it accepts a context.Context.
+func (webauthn *WebAuthn) BeginHighAssuranceRegistrationCtx(ctx context.Context, user User, opts ...RegistrationOption) (creation *protocol.CredentialCreation, session *SessionData, err error) {
+	opts = append(opts, WithConveyancePreference(protocol.PreferDirectAttestation), withUserVerificationRequired())
+
+	if creation, session, err = webauthn.BeginRegistrationCtx(ctx, user, opts...); err != nil {
+		return nil, nil, err
+	}
+
+	session.HighAssurance = true
+
+	return creation, session, nil
+}
+
+func withUserVerificationRequired() RegistrationOption {
+	return func(cco *protocol.PublicKeyCredentialCreationOptions) {
+		cco.AuthenticatorSelection.UserVerification = protocol.VerificationRequired
+	}
+}
+
+// registrationPolicy returns the AttestationPolicy used to verify a registration, which is the HighAssurancePolicy
+// if the registration is a high assurance registration.
+func (config *Config) registrationPolicy(ctx context.Context, highAssurance bool) protocol.AttestationPolicy {
+	if highAssurance {
+		return HighAssurancePolicy(config.attestationPolicy(ctx))
+	}
+
+	return config.attestationPolicy(ctx)
+}
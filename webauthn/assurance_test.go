@@ -0,0 +1,157 @@
+package webauthn_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/metadata"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+	"github.com/go-webauthn/webauthn/webauthntest/webauthnmock"
+)
+
+func TestHighAssurancePolicy(t *testing.T) {
+	policy := webauthn.HighAssurancePolicy(protocol.AttestationPolicy{MinimumCertificationLevel: metadata.FidoCertifiedL2})
+
+	assert.Equal(t, protocol.AttestationPolicy{
+		RequireMetadata:              true,
+		VerifyTrustPath:              true,
+		RequireVerifiableAttestation: true,
+		MinimumCertificationLevel:    metadata.FidoCertifiedL2,
+	}, policy)
+}
+
+func TestWebAuthn_BeginHighAssuranceRegistration(t *testing.T) {
+	w := newHighAssuranceWebAuthn(t, nil)
+
+	creation, session, err := w.BeginHighAssuranceRegistration(&bytesUser{},
+		webauthn.WithConveyancePreference(protocol.PreferNoAttestation),
+		webauthn.WithAuthenticatorSelection(protocol.AuthenticatorSelection{UserVerification: protocol.VerificationDiscouraged}),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, protocol.PreferDirectAttestation, creation.Response.Attestation)
+	assert.Equal(t, protocol.VerificationRequired, creation.Response.AuthenticatorSelection.UserVerification)
+	assert.Equal(t, protocol.VerificationRequired, session.UserVerification)
+	assert.True(t, session.HighAssurance)
+
+	_, session, err = w.BeginRegistration(&bytesUser{})
+	require.NoError(t, err)
+
+	assert.False(t, session.HighAssurance)
+}
+
+func TestWebAuthn_FinishHighAssuranceRegistration(t *testing.T) {
+	testCases := []struct {
+		name      string
+		format    string
+		flags     protocol.AuthenticatorFlags
+		known     bool
+		assurance bool
+		expected  protocol.ErrorCode
+	}{
+		{"ShouldAllowVerifiedAttestation", webauthntest.FormatU2F, 0, true, true, ""},
+		{"ShouldRejectUnknownAuthenticator", webauthntest.FormatU2F, 0, false, true, protocol.CodeAuthenticatorUnknown},
+		{"ShouldRejectWithoutUserVerification", webauthntest.FormatU2F, protocol.FlagUserPresent, true, true, protocol.CodeUVRequired},
+		{"ShouldRejectSelfAttestation", webauthntest.FormatPacked, 0, true, true, protocol.CodeAttestationUnverifiable},
+		{"ShouldRejectNoneAttestation", webauthntest.FormatNone, 0, true, true, protocol.CodeAttestationUnverifiable},
+		{"ShouldAllowSelfAttestationWithoutHighAssurance", webauthntest.FormatPacked, 0, false, false, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := newHighAssuranceWebAuthn(t, &tc.known)
+
+			user := &bytesUser{}
+
+			begin := w.BeginRegistration
+			if tc.assurance {
+				begin = w.BeginHighAssuranceRegistration
+			}
+
+			creation, session, err := begin(user)
+			require.NoError(t, err)
+
+			attestation, _, err := (&webauthntest.Authenticator{Format: tc.format, Flags: tc.flags}).CreateCredential(creation.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err := webauthntest.NewRequest(attestation)
+			require.NoError(t, err)
+
+			_, err = w.FinishRegistration(user, *session, r)
+
+			if tc.expected == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			require.IsType(t, &protocol.Error{}, err)
+			assert.Equal(t, tc.expected, err.(*protocol.Error).Code)
+		})
+	}
+}
+
+func TestWebAuthn_FinishHighAssuranceRegistrationDeferred(t *testing.T) {
+	w := newHighAssuranceWebAuthn(t, nil)
+
+	user := &bytesUser{}
+
+	creation, session, err := w.BeginHighAssuranceRegistration(user)
+	require.NoError(t, err)
+
+	attestation, _, err := (&webauthntest.Authenticator{Format: webauthntest.FormatPacked}).CreateCredential(creation.Response, "https://example.com")
+	require.NoError(t, err)
+
+	r, err := webauthntest.NewRequest(attestation)
+	require.NoError(t, err)
+
+	_, deferred, err := w.FinishRegistrationDeferred(user, *session, r)
+	require.NoError(t, err)
+
+	err = deferred.Verify(context.Background())
+
+	require.IsType(t, &protocol.Error{}, err)
+	assert.Equal(t, protocol.CodeAttestationUnverifiable, err.(*protocol.Error).Code)
+}
+
+// newHighAssuranceWebAuthn returns a *webauthn.WebAuthn whose metadata trusts the self-signed attestation certificate
+// of any fido-u2f authenticator if known is true.
+func newHighAssuranceWebAuthn(t *testing.T, known *bool) *webauthn.WebAuthn {
+	t.Helper()
+
+	provider := &webauthnmock.MetadataProviderMock{
+		LookupByAAGUIDFunc: func(_ uuid.UUID) (metadata.MetadataBLOBPayloadEntry, bool) {
+			return metadata.MetadataBLOBPayloadEntry{}, false
+		},
+		LookupByCertificateFunc: func(cert *x509.Certificate) (metadata.MetadataBLOBPayloadEntry, bool) {
+			entry := metadata.MetadataBLOBPayloadEntry{
+				AttestationCertificateKeyIdentifiers: []string{fmt.Sprintf("%x", sha256.Sum256(cert.Raw))},
+			}
+
+			entry.MetadataStatement.AttestationTypes = []metadata.AuthenticatorAttestationType{metadata.BasicFull}
+			entry.MetadataStatement.AttestationRootCertificates = []string{base64.StdEncoding.EncodeToString(cert.Raw)}
+
+			return entry, known != nil && *known
+		},
+	}
+
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:              "example.com",
+		RPDisplayName:     "Example",
+		RPOrigins:         []string{"https://example.com"},
+		AttestationPolicy: protocol.AttestationPolicy{Metadata: provider},
+	})
+	require.NoError(t, err)
+
+	return w
+}
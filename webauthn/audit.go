@@ -0,0 +1,72 @@
+package webauthn
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// AuditHook receives an AuditRecord after every finish ceremony.
+type AuditHook func(record AuditRecord)
+
+// AuditRecord is the structured record of a finish ceremony. It contains every verification step performed along with
+// its inputs and result. The inputs only ever contain hashes and public identifiers and never contain secrets such as
+// the challenge.
+type AuditRecord struct {
+	// Ceremony is the ceremony step this record relates to.
+	Ceremony Ceremony `json:"ceremony"`
+
+	// RPID is the configured Relying Party ID.
+	RPID string `json:"rp_id"`
+
+	// Time is the time the ceremony step started.
+	Time time.Time `json:"time"`
+
+	// Duration is the time taken to perform the ceremony step.
+	Duration time.Duration `json:"duration"`
+
+	// Success is true if the ceremony step completed without an error.
+	Success bool `json:"success"`
+
+	// Error is the error the ceremony step failed with.
+	Error string `json:"error,omitempty"`
+
+	// Code is the protocol.ErrorCode of the error the ceremony step failed with.
+	Code protocol.ErrorCode `json:"code,omitempty"`
+
+	// Steps are the individual verification steps which were performed in order. The steps after a failed step are
+	// not performed.
+	Steps []protocol.VerificationStep `json:"steps"`
+}
+
+func newAuditRecord(o *ceremonyObserver, err error) AuditRecord {
+	record := AuditRecord{
+		Ceremony: o.ceremony,
+		RPID:     o.webauthn.config.RPID,
+		Time:     o.start,
+		Duration: time.Since(o.start),
+		Success:  err == nil,
+		Steps:    o.trace.Steps,
+	}
+
+	if err != nil {
+		record.Error = err.Error()
+
+		var e *protocol.Error
+
+		if errors.As(err, &e) {
+			record.Code = e.Code
+		}
+	}
+
+	return record
+}
+
+func auditHash(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,49 @@
+package webauthn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+func TestWebAuthn_AuditHook(t *testing.T) {
+	var records []AuditRecord
+
+	webauthn, err := New(&Config{
+		RPDisplayName: "Example",
+		RPID:          "example.com",
+		RPOrigins:     []string{"https://example.com"},
+		AuditHook: func(record AuditRecord) {
+			records = append(records, record)
+		},
+	})
+	require.NoError(t, err)
+
+	user := &defaultUser{id: []byte("123")}
+
+	_, _, err = webauthn.BeginRegistration(user)
+	require.NoError(t, err)
+
+	_, err = webauthn.ValidateLogin(user, SessionData{UserID: []byte("ABC")}, nil)
+	require.Error(t, err)
+
+	require.Len(t, records, 1)
+
+	record := records[0]
+
+	assert.Equal(t, CeremonyFinishLogin, record.Ceremony)
+	assert.Equal(t, "example.com", record.RPID)
+	assert.False(t, record.Success)
+	assert.Equal(t, "ID mismatch for User and Session", record.Error)
+	assert.Equal(t, protocol.CodeUserSessionMismatch, record.Code)
+	assert.Equal(t, []protocol.VerificationStep{
+		{
+			Name:   protocol.VerificationStepSession,
+			Inputs: map[string]string{"user_id_hash": auditHash([]byte("123"))},
+			Error:  "ID mismatch for User and Session",
+		},
+	}, record.Steps)
+}
@@ -4,6 +4,18 @@ import (
 	"github.com/go-webauthn/webauthn/protocol"
 )
 
+// CounterPolicy describes how a signature counter which did not increase is handled during login, which is a signal
+// the authenticator may have been cloned.
+type CounterPolicy int
+
+const (
+	// CounterPolicyWarn sets the CloneWarning value of the Authenticator and allows the login.
+	CounterPolicyWarn CounterPolicy = iota
+
+	// CounterPolicyReject fails the login.
+	CounterPolicyReject
+)
+
 type Authenticator struct {
 	// The AAGUID of the authenticator. An AAGUID is defined as an array containing the globally unique
 	// identifier of the authenticator model being sought.
@@ -49,7 +61,7 @@ func SelectAuthenticator(att string, rrk *bool, uv string) protocol.Authenticato
 //	→ Less than or equal to the signature counter value stored in conjunction with credential’s id attribute.
 //	This is a signal that the authenticator may be cloned, see CloneWarning above for more information.
 func (a *Authenticator) UpdateCounter(authDataCount uint32) {
-	if authDataCount <= a.SignCount && (authDataCount != 0 || a.SignCount != 0) {
+	if a.counterRegressed(authDataCount) {
 		a.CloneWarning = true
 
 		return
@@ -57,3 +69,8 @@ func (a *Authenticator) UpdateCounter(authDataCount uint32) {
 
 	a.SignCount = authDataCount
 }
+
+// counterRegressed returns true if the provided signature counter value is a signal the authenticator may be cloned.
+func (a *Authenticator) counterRegressed(authDataCount uint32) bool {
+	return authDataCount <= a.SignCount && (authDataCount != 0 || a.SignCount != 0)
+}
@@ -0,0 +1,39 @@
+package webauthn
+
+import (
+	"context"
+)
+
+// BackupStateTransition describes the change of the backup state of a credential between two logins.
+type BackupStateTransition struct {
+	// UserID is the WebAuthnID of the user who logged in.
+	UserID []byte
+
+	// Credential is the credential used to log in, with the flags updated from the login.
+	Credential *Credential
+
+	// Previous is the backup state stored with the credential before the login.
+	Previous bool
+
+	// Current is the backup state reported by the authenticator during the login.
+	Current bool
+}
+
+// BackupStateHook receives a BackupStateTransition after a login with a credential whose backup state changed, such as
+// a passkey which was just synced to the cloud, so the user can be notified about the new sync destination. It's
+// called synchronously so it should not block.
+type BackupStateHook func(ctx context.Context, transition BackupStateTransition)
+
+// notifyBackupState calls the BackupStateHook if the backup state of the credential differs from the previous one.
+func (webauthn *WebAuthn) notifyBackupState(ctx context.Context, userID []byte, credential *Credential, previous bool) {
+	if webauthn.config.BackupStateHook == nil || credential.Flags.BackupState == previous {
+		return
+	}
+
+	webauthn.config.BackupStateHook(ctx, BackupStateTransition{
+		UserID:     userID,
+		Credential: credential,
+		Previous:   previous,
+		Current:    credential.Flags.BackupState,
+	})
+}
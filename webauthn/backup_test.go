@@ -0,0 +1,81 @@
+package webauthn_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+func TestWebAuthn_BackupStateHook(t *testing.T) {
+	var transitions []webauthn.BackupStateTransition
+
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+		BackupStateHook: func(_ context.Context, transition webauthn.BackupStateTransition) {
+			transitions = append(transitions, transition)
+		},
+	})
+	require.NoError(t, err)
+
+	user := &bytesUser{}
+	authenticator := &webauthntest.Authenticator{Flags: protocol.FlagUserPresent | protocol.FlagUserVerified | protocol.FlagBackupEligible}
+
+	creation, session, err := w.BeginRegistration(user)
+	require.NoError(t, err)
+
+	attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+	require.NoError(t, err)
+
+	r, err := webauthntest.NewRequest(attestation)
+	require.NoError(t, err)
+
+	credential, err := w.FinishRegistration(user, *session, r)
+	require.NoError(t, err)
+
+	login := func(flags protocol.AuthenticatorFlags) {
+		user.credentials = []webauthn.Credential{*credential}
+		authenticator.Flags = flags
+
+		assertion, session, err := w.BeginLogin(user)
+		require.NoError(t, err)
+
+		response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+		require.NoError(t, err)
+
+		r, err := webauthntest.NewRequest(response)
+		require.NoError(t, err)
+
+		credential, err = w.FinishLogin(user, *session, r)
+		require.NoError(t, err)
+	}
+
+	backedUp := protocol.FlagUserPresent | protocol.FlagUserVerified | protocol.FlagBackupEligible | protocol.FlagBackupState
+
+	login(protocol.FlagUserPresent | protocol.FlagUserVerified | protocol.FlagBackupEligible)
+	assert.Empty(t, transitions)
+
+	login(backedUp)
+	require.Len(t, transitions, 1)
+
+	assert.Equal(t, user.WebAuthnID(), transitions[0].UserID)
+	assert.Equal(t, credential.ID, transitions[0].Credential.ID)
+	assert.False(t, transitions[0].Previous)
+	assert.True(t, transitions[0].Current)
+
+	login(backedUp)
+	assert.Len(t, transitions, 1)
+
+	login(protocol.FlagUserPresent | protocol.FlagUserVerified | protocol.FlagBackupEligible)
+	require.Len(t, transitions, 2)
+
+	assert.True(t, transitions[1].Previous)
+	assert.False(t, transitions[1].Current)
+}
@@ -0,0 +1,71 @@
+package webauthn
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// BlocklistProvider looks up the credentials and attestation certificates which are known to be compromised, such as
+// from a threat intelligence feed, so they can be blocked centrally. See Config.BlocklistProvider.
+type BlocklistProvider interface {
+	// CredentialBlocked returns true if the credential with the ID is known to be compromised.
+	CredentialBlocked(ctx context.Context, credentialID []byte) (blocked bool, err error)
+
+	// CertificateBlocked returns true if the attestation certificate, or the intermediate certificate of an
+	// attestation certificate chain, is known to be compromised. It's usually identified by its issuer and serial
+	// number.
+	CertificateBlocked(ctx context.Context, certificate *x509.Certificate) (blocked bool, err error)
+}
+
+// verifyBlocklist ensures neither the credential nor the certificates of its attestation certificate chain are blocked
+// by the BlocklistProvider when it's configured. The certificates which can't be parsed are skipped, as they are either
+// verified by the attestation statement or were when the credential was registered.
+func (webauthn *WebAuthn) verifyBlocklist(ctx context.Context, trace *protocol.VerificationTrace, credentialID []byte, certificates [][]byte) error {
+	provider := webauthn.config.BlocklistProvider
+	if provider == nil {
+		return nil
+	}
+
+	return trace.Step(protocol.VerificationStepBlocklist, blocklistError(ctx, provider, credentialID, certificates), map[string]string{
+		"credential_id": base64.RawURLEncoding.EncodeToString(credentialID),
+		"certificates":  strconv.Itoa(len(certificates)),
+	})
+}
+
+func blocklistError(ctx context.Context, provider BlocklistProvider, credentialID []byte, certificates [][]byte) error {
+	blocked, err := provider.CredentialBlocked(ctx, credentialID)
+	if err != nil {
+		return err
+	}
+
+	if blocked {
+		return protocol.ErrPolicy.
+			WithCode(protocol.CodeCredentialBlocked).
+			WithDetails("Credential is known to be compromised")
+	}
+
+	for _, raw := range certificates {
+		certificate, err := x509.ParseCertificate(raw)
+		if err != nil {
+			continue
+		}
+
+		if blocked, err = provider.CertificateBlocked(ctx, certificate); err != nil {
+			return err
+		}
+
+		if blocked {
+			return protocol.ErrPolicy.
+				WithCode(protocol.CodeCredentialBlocked).
+				WithDetails("Attestation certificate is known to be compromised").
+				WithInfo(fmt.Sprintf("Subject: %s, Issuer: %s, Serial: %s", certificate.Subject, certificate.Issuer, certificate.SerialNumber))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package webauthn_test
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+type testBlocklist struct {
+	credentials  map[string]bool
+	certificates bool
+	err          error
+}
+
+func (b *testBlocklist) CredentialBlocked(_ context.Context, credentialID []byte) (bool, error) {
+	return b.credentials[string(credentialID)], b.err
+}
+
+func (b *testBlocklist) CertificateBlocked(_ context.Context, _ *x509.Certificate) (bool, error) {
+	return b.certificates, nil
+}
+
+func TestWebAuthn_BlocklistProvider(t *testing.T) {
+	errFeed := errors.New("feed unavailable")
+
+	testCases := []struct {
+		name              string
+		format            string
+		blockCertificates bool
+		blockCredential   bool
+		err               error
+		registration      protocol.ErrorCode
+		login             protocol.ErrorCode
+		loginErr          error
+	}{
+		{"ShouldAllowCredentials", webauthntest.FormatU2F, false, false, nil, "", "", nil},
+		{"ShouldRejectBlockedCertificates", webauthntest.FormatU2F, true, false, nil, protocol.CodeCredentialBlocked, "", nil},
+		{"ShouldIgnoreCertificatesWithoutAttestation", webauthntest.FormatNone, true, false, nil, "", "", nil},
+		{"ShouldRejectBlockedCredentials", webauthntest.FormatNone, false, true, nil, "", protocol.CodeCredentialBlocked, nil},
+		{"ShouldFailWhenTheProviderFails", webauthntest.FormatNone, false, false, errFeed, "", "", errFeed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			blocklist := &testBlocklist{certificates: tc.blockCertificates}
+
+			w, err := webauthn.New(&webauthn.Config{
+				RPID:              "example.com",
+				RPDisplayName:     "Example",
+				RPOrigins:         []string{"https://example.com"},
+				BlocklistProvider: blocklist,
+			})
+			require.NoError(t, err)
+
+			user := &bytesUser{}
+			authenticator := &webauthntest.Authenticator{Format: tc.format}
+
+			creation, session, err := w.BeginRegistration(user)
+			require.NoError(t, err)
+
+			attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err := webauthntest.NewRequest(attestation)
+			require.NoError(t, err)
+
+			credential, err := w.FinishRegistration(user, *session, r)
+
+			if tc.registration != "" {
+				assertErrorCode(t, tc.registration, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			user.credentials = append(user.credentials, *credential)
+
+			if tc.blockCredential {
+				blocklist.credentials = map[string]bool{string(credential.ID): true}
+			}
+
+			blocklist.err = tc.err
+
+			assertion, session, err := w.BeginLogin(user)
+			require.NoError(t, err)
+
+			response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err = webauthntest.NewRequest(response)
+			require.NoError(t, err)
+
+			_, err = w.FinishLogin(user, *session, r)
+
+			switch {
+			case tc.login != "":
+				assertErrorCode(t, tc.login, err)
+			case tc.loginErr != nil:
+				assert.ErrorIs(t, err, tc.loginErr)
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package webauthn
+
+import (
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// legacyHybridTransport is the name the hybrid transport was reported as before it was standardized.
+const legacyHybridTransport protocol.AuthenticatorTransport = "cable"
+
+// BrowserCompatibility configures the leniencies for responses of browsers which diverge from the specification. Each
+// leniency is documented with the browsers known to require it and is disabled by default. The responses of Firefox
+// do not currently require any leniency.
+type BrowserCompatibility struct {
+	// LegacyHybridTransport stores the legacy cable transport reported by earlier versions of Chrome and Edge for
+	// registrations made with a phone as the hybrid transport, so the transport is understood by current clients when
+	// the credential is allowed in later ceremonies.
+	LegacyHybridTransport bool
+
+	// BackupEligibilityUpgrade accepts logins with a credential which became backup eligible since it was registered
+	// when the SpecLevel is protocol.SpecLevel3. Safari 16 on iOS 16 and macOS 13 turned the iCloud Keychain
+	// credentials registered with earlier versions into passkeys, which are backup eligible. A credential which is no
+	// longer backup eligible is still rejected.
+	BackupEligibilityUpgrade bool
+}
+
+// browserTransports replaces the legacy transports of the registration when the LegacyHybridTransport leniency is
+// enabled, removing any transport which is duplicated as a result.
+func (config *Config) browserTransports(parsedResponse *protocol.ParsedCredentialCreationData) {
+	if !config.BrowserCompatibility.LegacyHybridTransport || len(parsedResponse.Response.Transports) == 0 {
+		return
+	}
+
+	transports := make([]protocol.AuthenticatorTransport, 0, len(parsedResponse.Response.Transports))
+
+	for _, transport := range parsedResponse.Response.Transports {
+		if transport == legacyHybridTransport {
+			transport = protocol.Hybrid
+		}
+
+		if !containsTransport(transports, transport) {
+			transports = append(transports, transport)
+		}
+	}
+
+	parsedResponse.Response.Transports = transports
+}
+
+// backupEligibilityUpgraded returns true if the credential became backup eligible since it was registered and the
+// BackupEligibilityUpgrade leniency is enabled.
+func (config *Config) backupEligibilityUpgraded(credential Credential, flags protocol.AuthenticatorFlags) bool {
+	return config.BrowserCompatibility.BackupEligibilityUpgrade && !credential.Flags.BackupEligible && flags.HasBackupEligible()
+}
+
+func containsTransport(transports []protocol.AuthenticatorTransport, transport protocol.AuthenticatorTransport) bool {
+	for _, t := range transports {
+		if t == transport {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,71 @@
+package webauthn_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest/vectors"
+)
+
+func TestWebAuthn_BrowserCompatibility(t *testing.T) {
+	testCases := []struct {
+		name          string
+		browser       string
+		compatibility webauthn.BrowserCompatibility
+		transports    []protocol.AuthenticatorTransport
+		expected      protocol.ErrorCode
+	}{
+		{"ShouldPassChrome", "chrome", webauthn.BrowserCompatibility{}, []protocol.AuthenticatorTransport{protocol.Hybrid, protocol.Internal}, ""},
+		{"ShouldPassEdge", "edge", webauthn.BrowserCompatibility{}, []protocol.AuthenticatorTransport{"cable", protocol.Internal}, ""},
+		{"ShouldPassEdgeWithLegacyHybridTransport", "edge", webauthn.BrowserCompatibility{LegacyHybridTransport: true}, []protocol.AuthenticatorTransport{protocol.Hybrid, protocol.Internal}, ""},
+		{"ShouldPassFirefox", "firefox", webauthn.BrowserCompatibility{}, nil, ""},
+		{"ShouldPassSafariWithBackupEligibilityUpgrade", "safari", webauthn.BrowserCompatibility{BackupEligibilityUpgrade: true}, []protocol.AuthenticatorTransport{protocol.Internal}, ""},
+		{"ShouldFailSafariWithoutBackupEligibilityUpgrade", "safari", webauthn.BrowserCompatibility{}, []protocol.AuthenticatorTransport{protocol.Internal}, protocol.CodeBackupEligibilityChanged},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			registration, err := vectors.Load("browser-" + tc.browser + "-registration")
+			require.NoError(t, err)
+
+			authentication, err := vectors.Load("browser-" + tc.browser + "-authentication")
+			require.NoError(t, err)
+
+			w, err := webauthn.New(&webauthn.Config{
+				RPID:                 registration.RPID,
+				RPDisplayName:        "Example",
+				RPOrigins:            []string{registration.Origin},
+				SpecLevel:            protocol.SpecLevel3,
+				BrowserCompatibility: tc.compatibility,
+			})
+			require.NoError(t, err)
+
+			user := &bytesUser{}
+
+			credential, err := w.FinishRegistrationBytes(user, webauthn.SessionData{Challenge: registration.Challenge, UserID: user.WebAuthnID()}, registration.Response)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.transports, credential.Transport)
+
+			user.credentials = append(user.credentials, *credential)
+
+			session := webauthn.SessionData{Challenge: authentication.Challenge, UserID: user.WebAuthnID(), AllowedCredentialIDs: [][]byte{credential.ID}}
+
+			credential, err = w.FinishLoginBytes(user, session, authentication.Response)
+
+			if tc.expected != "" {
+				assertErrorCode(t, tc.expected, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			assert.Equal(t, authentication.CredentialPublicKey, protocol.URLEncodedBase64(credential.PublicKey).String())
+		})
+	}
+}
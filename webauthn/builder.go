@@ -0,0 +1,472 @@
+package webauthn
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// RegistrationOptionsBuilder builds a RegistrationOptions value with a fluent API. The combination of the fields is
+// validated by Build, which is the only way to obtain the RegistrationOptions, so invalid options can't be used.
+type RegistrationOptionsBuilder struct {
+	options RegistrationOptions
+}
+
+// RegistrationOptions is an immutable set of registration options built by a RegistrationOptionsBuilder. It's safe to
+// share between goroutines and ceremonies, as every registration receives its own copy of the values so the
+// registration options returned to the client can't mutate it, or the options of another registration.
+type RegistrationOptions struct {
+	residentKey        protocol.ResidentKeyRequirement
+	requireResidentKey *bool
+	userVerification   protocol.UserVerificationRequirement
+	attachment         protocol.AuthenticatorAttachment
+	attestation        protocol.ConveyancePreference
+	exclusions         []protocol.CredentialDescriptor
+	parameters         []protocol.CredentialParameter
+	extensions         protocol.AuthenticationExtensions
+	hints              []protocol.PublicKeyCredentialHint
+	attestationFormats []string
+	timeout            time.Duration
+}
+
+// NewRegistrationOptions returns a new RegistrationOptionsBuilder. Fields which are not set use the defaults of the
+// Config.
+func NewRegistrationOptions() *RegistrationOptionsBuilder {
+	return &RegistrationOptionsBuilder{}
+}
+
+// ResidentKey sets the resident key requirement. The require resident key option is derived from it unless it's set
+// explicitly with RequireResidentKey.
+func (b *RegistrationOptionsBuilder) ResidentKey(requirement protocol.ResidentKeyRequirement) *RegistrationOptionsBuilder {
+	b.options.residentKey = requirement
+
+	return b
+}
+
+// RequireResidentKey sets the legacy require resident key option, which must agree with the ResidentKey requirement.
+func (b *RegistrationOptionsBuilder) RequireResidentKey(require bool) *RegistrationOptionsBuilder {
+	b.options.requireResidentKey = &require
+
+	return b
+}
+
+// UserVerification sets the user verification requirement.
+func (b *RegistrationOptionsBuilder) UserVerification(requirement protocol.UserVerificationRequirement) *RegistrationOptionsBuilder {
+	b.options.userVerification = requirement
+
+	return b
+}
+
+// AuthenticatorAttachment sets the authenticator attachment.
+func (b *RegistrationOptionsBuilder) AuthenticatorAttachment(attachment protocol.AuthenticatorAttachment) *RegistrationOptionsBuilder {
+	b.options.attachment = attachment
+
+	return b
+}
+
+// Attestation sets the attestation conveyance preference.
+func (b *RegistrationOptionsBuilder) Attestation(preference protocol.ConveyancePreference) *RegistrationOptionsBuilder {
+	b.options.attestation = preference
+
+	return b
+}
+
+// Exclusions sets the credentials to exclude from the registration, see CredentialDescriptors.
+func (b *RegistrationOptionsBuilder) Exclusions(exclusions []protocol.CredentialDescriptor) *RegistrationOptionsBuilder {
+	b.options.exclusions = exclusions
+
+	return b
+}
+
+// CredentialParameters sets the credential parameters.
+func (b *RegistrationOptionsBuilder) CredentialParameters(parameters []protocol.CredentialParameter) *RegistrationOptionsBuilder {
+	b.options.parameters = parameters
+
+	return b
+}
+
+// Extensions sets the extensions.
+func (b *RegistrationOptionsBuilder) Extensions(extensions protocol.AuthenticationExtensions) *RegistrationOptionsBuilder {
+	b.options.extensions = extensions
+
+	return b
+}
+
+// Hints sets the hints to the user agent, see WithHints.
+func (b *RegistrationOptionsBuilder) Hints(hints ...protocol.PublicKeyCredentialHint) *RegistrationOptionsBuilder {
+	b.options.hints = hints
+
+	return b
+}
+
+// AttestationFormats sets the preferred attestation statement formats, see WithAttestationFormats.
+func (b *RegistrationOptionsBuilder) AttestationFormats(formats ...string) *RegistrationOptionsBuilder {
+	b.options.attestationFormats = formats
+
+	return b
+}
+
+// Timeout sets the timeout, instead of the registration timeout of the Config.
+func (b *RegistrationOptionsBuilder) Timeout(timeout time.Duration) *RegistrationOptionsBuilder {
+	b.options.timeout = timeout
+
+	return b
+}
+
+// Build validates the combination of the fields and returns the immutable RegistrationOptions. The builder may be
+// reused afterwards without affecting the returned value.
+func (b *RegistrationOptionsBuilder) Build() (options RegistrationOptions, err error) {
+	options = b.options.clone()
+
+	if err = validateResidentKeyRequirement(options.residentKey); err != nil {
+		return RegistrationOptions{}, err
+	}
+
+	if err = validateUserVerificationRequirement(options.userVerification); err != nil {
+		return RegistrationOptions{}, err
+	}
+
+	switch options.attachment {
+	case "", protocol.Platform, protocol.CrossPlatform:
+	default:
+		return RegistrationOptions{}, fmt.Errorf("the authenticator attachment '%s' is not valid", options.attachment)
+	}
+
+	switch options.attestation {
+	case "", protocol.PreferNoAttestation, protocol.PreferIndirectAttestation, protocol.PreferDirectAttestation, protocol.PreferEnterpriseAttestation:
+	default:
+		return RegistrationOptions{}, fmt.Errorf("the attestation conveyance preference '%s' is not valid", options.attestation)
+	}
+
+	if options.requireResidentKey != nil {
+		switch {
+		case *options.requireResidentKey && options.residentKey == "":
+			options.residentKey = protocol.ResidentKeyRequirementRequired
+		case *options.requireResidentKey != (options.residentKey == protocol.ResidentKeyRequirementRequired):
+			return RegistrationOptions{}, fmt.Errorf("the require resident key option '%t' conflicts with the resident key requirement '%s'", *options.requireResidentKey, options.residentKey)
+		}
+	} else if options.residentKey != "" {
+		options.requireResidentKey = protocol.ResidentKeyNotRequired()
+
+		if options.residentKey == protocol.ResidentKeyRequirementRequired {
+			options.requireResidentKey = protocol.ResidentKeyRequired()
+		}
+	}
+
+	if err = validateDescriptors("exclusions", options.exclusions); err != nil {
+		return RegistrationOptions{}, err
+	}
+
+	for _, parameter := range options.parameters {
+		if parameter.Type != protocol.PublicKeyCredentialType {
+			return RegistrationOptions{}, fmt.Errorf("the credential parameter type '%s' is not valid", parameter.Type)
+		}
+	}
+
+	if err = validateHints(options.hints); err != nil {
+		return RegistrationOptions{}, err
+	}
+
+	if err = validateOptionsTimeout(options.timeout); err != nil {
+		return RegistrationOptions{}, err
+	}
+
+	return options, nil
+}
+
+// Option returns the RegistrationOption which applies a copy of the options to a registration.
+func (o RegistrationOptions) Option() RegistrationOption {
+	return func(cco *protocol.PublicKeyCredentialCreationOptions) {
+		options := o.clone()
+
+		if options.residentKey != "" {
+			cco.AuthenticatorSelection.ResidentKey = options.residentKey
+		}
+
+		if options.requireResidentKey != nil {
+			cco.AuthenticatorSelection.RequireResidentKey = options.requireResidentKey
+		}
+
+		if options.userVerification != "" {
+			cco.AuthenticatorSelection.UserVerification = options.userVerification
+		}
+
+		if options.attachment != "" {
+			cco.AuthenticatorSelection.AuthenticatorAttachment = options.attachment
+		}
+
+		if options.attestation != "" {
+			cco.Attestation = options.attestation
+		}
+
+		if options.exclusions != nil {
+			cco.CredentialExcludeList = options.exclusions
+		}
+
+		if options.parameters != nil {
+			cco.Parameters = options.parameters
+		}
+
+		if options.extensions != nil {
+			cco.Extensions = options.extensions
+		}
+
+		if options.hints != nil {
+			cco.Hints = options.hints
+		}
+
+		if options.attestationFormats != nil {
+			cco.AttestationFormats = options.attestationFormats
+		}
+
+		if options.timeout != 0 {
+			cco.Timeout = int(options.timeout.Milliseconds())
+		}
+	}
+}
+
+func (o RegistrationOptions) clone() RegistrationOptions {
+	if o.requireResidentKey != nil {
+		require := *o.requireResidentKey
+
+		o.requireResidentKey = &require
+	}
+
+	o.exclusions = cloneDescriptors(o.exclusions)
+	o.extensions = cloneExtensions(o.extensions)
+
+	if o.parameters != nil {
+		o.parameters = append([]protocol.CredentialParameter{}, o.parameters...)
+	}
+
+	o.hints = cloneHints(o.hints)
+
+	if o.attestationFormats != nil {
+		o.attestationFormats = append([]string{}, o.attestationFormats...)
+	}
+
+	return o
+}
+
+// LoginOptionsBuilder builds a LoginOptions value with a fluent API. The combination of the fields is validated by
+// Build, which is the only way to obtain the LoginOptions, so invalid options can't be used.
+type LoginOptionsBuilder struct {
+	options LoginOptions
+}
+
+// LoginOptions is an immutable set of login options built by a LoginOptionsBuilder. It's safe to share between
+// goroutines and ceremonies, as every login receives its own copy of the values so the login options returned to the
+// client can't mutate it, the options of another login, or the SessionData derived from them.
+type LoginOptions struct {
+	allowedCredentials []protocol.CredentialDescriptor
+	userVerification   protocol.UserVerificationRequirement
+	extensions         protocol.AuthenticationExtensions
+	hints              []protocol.PublicKeyCredentialHint
+	appID              string
+	timeout            time.Duration
+}
+
+// NewLoginOptions returns a new LoginOptionsBuilder. Fields which are not set use the defaults of the Config.
+func NewLoginOptions() *LoginOptionsBuilder {
+	return &LoginOptionsBuilder{}
+}
+
+// AllowedCredentials sets the credentials allowed to log in, see CredentialDescriptors.
+func (b *LoginOptionsBuilder) AllowedCredentials(allowed []protocol.CredentialDescriptor) *LoginOptionsBuilder {
+	b.options.allowedCredentials = allowed
+
+	return b
+}
+
+// UserVerification sets the user verification requirement.
+func (b *LoginOptionsBuilder) UserVerification(requirement protocol.UserVerificationRequirement) *LoginOptionsBuilder {
+	b.options.userVerification = requirement
+
+	return b
+}
+
+// Extensions sets the extensions.
+func (b *LoginOptionsBuilder) Extensions(extensions protocol.AuthenticationExtensions) *LoginOptionsBuilder {
+	b.options.extensions = extensions
+
+	return b
+}
+
+// Hints sets the hints to the user agent, see WithAssertionHints.
+func (b *LoginOptionsBuilder) Hints(hints ...protocol.PublicKeyCredentialHint) *LoginOptionsBuilder {
+	b.options.hints = hints
+
+	return b
+}
+
+// AppID sets the appid extension which is included if any of the allowed credentials is a FIDO U2F credential, see
+// WithAppIdExtension.
+func (b *LoginOptionsBuilder) AppID(appid string) *LoginOptionsBuilder {
+	b.options.appID = appid
+
+	return b
+}
+
+// Timeout sets the timeout, instead of the login timeout of the Config.
+func (b *LoginOptionsBuilder) Timeout(timeout time.Duration) *LoginOptionsBuilder {
+	b.options.timeout = timeout
+
+	return b
+}
+
+// Build validates the combination of the fields and returns the immutable LoginOptions. The builder may be reused
+// afterwards without affecting the returned value.
+func (b *LoginOptionsBuilder) Build() (options LoginOptions, err error) {
+	options = b.options.clone()
+
+	if err = validateUserVerificationRequirement(options.userVerification); err != nil {
+		return LoginOptions{}, err
+	}
+
+	if err = validateDescriptors("allowed credentials", options.allowedCredentials); err != nil {
+		return LoginOptions{}, err
+	}
+
+	if err = validateHints(options.hints); err != nil {
+		return LoginOptions{}, err
+	}
+
+	if err = validateOptionsTimeout(options.timeout); err != nil {
+		return LoginOptions{}, err
+	}
+
+	return options, nil
+}
+
+// Option returns the LoginOption which applies a copy of the options to a login.
+func (o LoginOptions) Option() LoginOption {
+	return func(cro *protocol.PublicKeyCredentialRequestOptions) {
+		options := o.clone()
+
+		if options.allowedCredentials != nil {
+			cro.AllowedCredentials = options.allowedCredentials
+		}
+
+		if options.userVerification != "" {
+			cro.UserVerification = options.userVerification
+		}
+
+		if options.extensions != nil {
+			cro.Extensions = options.extensions
+		}
+
+		if options.hints != nil {
+			cro.Hints = options.hints
+		}
+
+		if options.appID != "" {
+			WithAppIdExtension(options.appID)(cro)
+		}
+
+		if options.timeout != 0 {
+			cro.Timeout = int(options.timeout.Milliseconds())
+		}
+	}
+}
+
+func (o LoginOptions) clone() LoginOptions {
+	o.allowedCredentials = cloneDescriptors(o.allowedCredentials)
+	o.extensions = cloneExtensions(o.extensions)
+	o.hints = cloneHints(o.hints)
+
+	return o
+}
+
+func cloneHints(hints []protocol.PublicKeyCredentialHint) []protocol.PublicKeyCredentialHint {
+	if hints == nil {
+		return nil
+	}
+
+	return append([]protocol.PublicKeyCredentialHint{}, hints...)
+}
+
+func validateHints(hints []protocol.PublicKeyCredentialHint) error {
+	for _, hint := range hints {
+		switch hint {
+		case protocol.PublicKeyCredentialHintSecurityKey, protocol.PublicKeyCredentialHintClientDevice, protocol.PublicKeyCredentialHintHybrid:
+		default:
+			return fmt.Errorf("the hint '%s' is not valid", hint)
+		}
+	}
+
+	return nil
+}
+
+func validateResidentKeyRequirement(requirement protocol.ResidentKeyRequirement) error {
+	switch requirement {
+	case "", protocol.ResidentKeyRequirementDiscouraged, protocol.ResidentKeyRequirementPreferred, protocol.ResidentKeyRequirementRequired:
+		return nil
+	default:
+		return fmt.Errorf("the resident key requirement '%s' is not valid", requirement)
+	}
+}
+
+func validateUserVerificationRequirement(requirement protocol.UserVerificationRequirement) error {
+	switch requirement {
+	case "", protocol.VerificationDiscouraged, protocol.VerificationPreferred, protocol.VerificationRequired:
+		return nil
+	default:
+		return fmt.Errorf("the user verification requirement '%s' is not valid", requirement)
+	}
+}
+
+func validateDescriptors(name string, descriptors []protocol.CredentialDescriptor) error {
+	for i, descriptor := range descriptors {
+		if descriptor.Type != protocol.PublicKeyCredentialType {
+			return fmt.Errorf("the %s descriptor at index %d has the type '%s' which is not valid", name, i, descriptor.Type)
+		}
+
+		if len(descriptor.CredentialID) == 0 {
+			return fmt.Errorf("the %s descriptor at index %d has an empty credential id", name, i)
+		}
+	}
+
+	return nil
+}
+
+func validateOptionsTimeout(timeout time.Duration) error {
+	if timeout < 0 {
+		return fmt.Errorf("the timeout must not be negative but it is %s", timeout)
+	}
+
+	return nil
+}
+
+func cloneDescriptors(descriptors []protocol.CredentialDescriptor) []protocol.CredentialDescriptor {
+	if descriptors == nil {
+		return nil
+	}
+
+	cloned := make([]protocol.CredentialDescriptor, len(descriptors))
+
+	for i, descriptor := range descriptors {
+		cloned[i] = descriptor
+		cloned[i].CredentialID = append(protocol.URLEncodedBase64{}, descriptor.CredentialID...)
+
+		if descriptor.Transport != nil {
+			cloned[i].Transport = append([]protocol.AuthenticatorTransport{}, descriptor.Transport...)
+		}
+	}
+
+	return cloned
+}
+
+func cloneExtensions(extensions protocol.AuthenticationExtensions) protocol.AuthenticationExtensions {
+	if extensions == nil {
+		return nil
+	}
+
+	cloned := make(protocol.AuthenticationExtensions, len(extensions))
+
+	for key, value := range extensions {
+		cloned[key] = value
+	}
+
+	return cloned
+}
@@ -0,0 +1,165 @@
+package webauthn
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+func TestRegistrationOptionsBuilder(t *testing.T) {
+	testCases := []struct {
+		name               string
+		builder            *RegistrationOptionsBuilder
+		residentKey        protocol.ResidentKeyRequirement
+		requireResidentKey *bool
+		err                string
+	}{
+		{"ShouldBuildEmpty", NewRegistrationOptions(), "", nil, ""},
+		{"ShouldDeriveRequireResidentKey", NewRegistrationOptions().ResidentKey(protocol.ResidentKeyRequirementRequired), protocol.ResidentKeyRequirementRequired, protocol.ResidentKeyRequired(), ""},
+		{"ShouldDeriveNotRequireResidentKey", NewRegistrationOptions().ResidentKey(protocol.ResidentKeyRequirementPreferred), protocol.ResidentKeyRequirementPreferred, protocol.ResidentKeyNotRequired(), ""},
+		{"ShouldDeriveResidentKeyRequirement", NewRegistrationOptions().RequireResidentKey(true), protocol.ResidentKeyRequirementRequired, protocol.ResidentKeyRequired(), ""},
+		{"ShouldAllowMatchingResidentKey", NewRegistrationOptions().ResidentKey(protocol.ResidentKeyRequirementDiscouraged).RequireResidentKey(false), protocol.ResidentKeyRequirementDiscouraged, protocol.ResidentKeyNotRequired(), ""},
+		{"ShouldKeepRequireResidentKeyFalse", NewRegistrationOptions().RequireResidentKey(false), "", protocol.ResidentKeyNotRequired(), ""},
+		{"ShouldRejectRequireResidentKeyConflict", NewRegistrationOptions().ResidentKey(protocol.ResidentKeyRequirementPreferred).RequireResidentKey(true), "", nil, "the require resident key option 'true' conflicts with the resident key requirement 'preferred'"},
+		{"ShouldRejectNotRequireResidentKeyConflict", NewRegistrationOptions().ResidentKey(protocol.ResidentKeyRequirementRequired).RequireResidentKey(false), "", nil, "the require resident key option 'false' conflicts with the resident key requirement 'required'"},
+		{"ShouldRejectInvalidResidentKey", NewRegistrationOptions().ResidentKey("always"), "", nil, "the resident key requirement 'always' is not valid"},
+		{"ShouldRejectInvalidUserVerification", NewRegistrationOptions().UserVerification("always"), "", nil, "the user verification requirement 'always' is not valid"},
+		{"ShouldRejectInvalidAttachment", NewRegistrationOptions().AuthenticatorAttachment("usb"), "", nil, "the authenticator attachment 'usb' is not valid"},
+		{"ShouldRejectInvalidAttestation", NewRegistrationOptions().Attestation("full"), "", nil, "the attestation conveyance preference 'full' is not valid"},
+		{"ShouldRejectInvalidExclusionType", NewRegistrationOptions().Exclusions([]protocol.CredentialDescriptor{{CredentialID: []byte("id")}}), "", nil, "the exclusions descriptor at index 0 has the type '' which is not valid"},
+		{"ShouldRejectEmptyExclusionID", NewRegistrationOptions().Exclusions([]protocol.CredentialDescriptor{{Type: protocol.PublicKeyCredentialType}}), "", nil, "the exclusions descriptor at index 0 has an empty credential id"},
+		{"ShouldRejectInvalidParameterType", NewRegistrationOptions().CredentialParameters([]protocol.CredentialParameter{{Type: "secret"}}), "", nil, "the credential parameter type 'secret' is not valid"},
+		{"ShouldRejectInvalidHint", NewRegistrationOptions().Hints(protocol.PublicKeyCredentialHintHybrid, "nfc"), "", nil, "the hint 'nfc' is not valid"},
+		{"ShouldRejectNegativeTimeout", NewRegistrationOptions().Timeout(-time.Second), "", nil, "the timeout must not be negative but it is -1s"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			options, err := tc.builder.Build()
+
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				assert.Equal(t, RegistrationOptions{}, options)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.residentKey, options.residentKey)
+			assert.Equal(t, tc.requireResidentKey, options.requireResidentKey)
+		})
+	}
+}
+
+func TestRegistrationOptions_Option(t *testing.T) {
+	webauthn, err := New(&Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+	})
+	require.NoError(t, err)
+
+	exclusions := []protocol.CredentialDescriptor{{Type: protocol.PublicKeyCredentialType, CredentialID: []byte("id")}}
+	extensions := protocol.AuthenticationExtensions{"credProps": true}
+
+	builder := NewRegistrationOptions().
+		ResidentKey(protocol.ResidentKeyRequirementRequired).
+		UserVerification(protocol.VerificationRequired).
+		AuthenticatorAttachment(protocol.Platform).
+		Attestation(protocol.PreferDirectAttestation).
+		Exclusions(exclusions).
+		Extensions(extensions).
+		Timeout(time.Second)
+
+	options, err := builder.Build()
+	require.NoError(t, err)
+
+	exclusions[0].CredentialID[0] = 'x'
+	extensions["credProps"] = false
+	builder.Timeout(time.Minute)
+
+	creation, session, err := webauthn.BeginRegistration(&defaultUser{id: []byte("123")}, options.Option())
+	require.NoError(t, err)
+
+	assert.Equal(t, protocol.AuthenticatorSelection{
+		AuthenticatorAttachment: protocol.Platform,
+		RequireResidentKey:      protocol.ResidentKeyRequired(),
+		ResidentKey:             protocol.ResidentKeyRequirementRequired,
+		UserVerification:        protocol.VerificationRequired,
+	}, creation.Response.AuthenticatorSelection)
+	assert.Equal(t, protocol.PreferDirectAttestation, creation.Response.Attestation)
+	assert.Equal(t, protocol.URLEncodedBase64("id"), creation.Response.CredentialExcludeList[0].CredentialID)
+	assert.Equal(t, true, creation.Response.Extensions["credProps"])
+	assert.Equal(t, 1000, creation.Response.Timeout)
+	assert.Equal(t, protocol.VerificationRequired, session.UserVerification)
+
+	creation.Response.CredentialExcludeList[0].CredentialID[0] = 'x'
+	creation.Response.Extensions["credProps"] = false
+
+	creation, _, err = webauthn.BeginRegistration(&defaultUser{id: []byte("123")}, options.Option())
+	require.NoError(t, err)
+
+	assert.Equal(t, protocol.URLEncodedBase64("id"), creation.Response.CredentialExcludeList[0].CredentialID)
+	assert.Equal(t, true, creation.Response.Extensions["credProps"])
+}
+
+func TestLoginOptionsBuilder(t *testing.T) {
+	testCases := []struct {
+		name    string
+		builder *LoginOptionsBuilder
+		err     string
+	}{
+		{"ShouldBuildEmpty", NewLoginOptions(), ""},
+		{"ShouldBuild", NewLoginOptions().UserVerification(protocol.VerificationDiscouraged).AppID("https://example.com").Timeout(time.Second), ""},
+		{"ShouldRejectInvalidUserVerification", NewLoginOptions().UserVerification("always"), "the user verification requirement 'always' is not valid"},
+		{"ShouldRejectEmptyAllowedCredentialID", NewLoginOptions().AllowedCredentials([]protocol.CredentialDescriptor{{Type: protocol.PublicKeyCredentialType}}), "the allowed credentials descriptor at index 0 has an empty credential id"},
+		{"ShouldRejectInvalidHint", NewLoginOptions().Hints("nfc"), "the hint 'nfc' is not valid"},
+		{"ShouldRejectNegativeTimeout", NewLoginOptions().Timeout(-time.Second), "the timeout must not be negative but it is -1s"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			options, err := tc.builder.Build()
+
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				assert.Equal(t, LoginOptions{}, options)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLoginOptions_Option(t *testing.T) {
+	webauthn, err := New(&Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+	})
+	require.NoError(t, err)
+
+	options, err := NewLoginOptions().
+		AllowedCredentials([]protocol.CredentialDescriptor{{Type: protocol.PublicKeyCredentialType, CredentialID: []byte("id"), AttestationType: protocol.CredentialTypeFIDOU2F}}).
+		UserVerification(protocol.VerificationRequired).
+		AppID("https://example.com").
+		Timeout(time.Second).
+		Build()
+	require.NoError(t, err)
+
+	assertion, session, err := webauthn.BeginDiscoverableLogin(options.Option())
+	require.NoError(t, err)
+
+	assert.Equal(t, protocol.VerificationRequired, assertion.Response.UserVerification)
+	assert.Equal(t, "https://example.com", assertion.Response.Extensions[protocol.ExtensionAppID])
+	assert.Equal(t, 1000, assertion.Response.Timeout)
+	assert.Equal(t, [][]byte{[]byte("id")}, session.AllowedCredentialIDs)
+
+	assertion.Response.Extensions[protocol.ExtensionAppID] = "https://example.org"
+
+	assert.Equal(t, "https://example.com", session.Extensions[protocol.ExtensionAppID])
+}
@@ -0,0 +1,101 @@
+package webauthn_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+type bytesUser struct {
+	credentials []webauthn.Credential
+}
+
+func (u *bytesUser) WebAuthnID() []byte {
+	return []byte("1234")
+}
+
+func (u *bytesUser) WebAuthnName() string {
+	return "john"
+}
+
+func (u *bytesUser) WebAuthnDisplayName() string {
+	return "John"
+}
+
+func (u *bytesUser) WebAuthnIcon() string {
+	return ""
+}
+
+func (u *bytesUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+func TestWebAuthn_FinishBytes(t *testing.T) {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:              "example.com",
+		RPDisplayName:     "Example",
+		RPOrigins:         []string{"https://example.com"},
+		ResponseBodyLimit: 4096,
+	})
+	require.NoError(t, err)
+
+	user := &bytesUser{}
+	authenticator := &webauthntest.Authenticator{}
+
+	creation, session, err := w.BeginRegistration(user)
+	require.NoError(t, err)
+
+	attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+	require.NoError(t, err)
+
+	body, err := json.Marshal(attestation)
+	require.NoError(t, err)
+
+	credential, err := w.FinishRegistrationBytes(user, *session, body)
+	require.NoError(t, err)
+
+	user.credentials = append(user.credentials, *credential)
+
+	assertion, session, err := w.BeginLogin(user)
+	require.NoError(t, err)
+
+	response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+	require.NoError(t, err)
+
+	body, err = json.Marshal(response)
+	require.NoError(t, err)
+
+	credential, err = w.FinishLoginBytes(user, *session, body)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), credential.Authenticator.SignCount)
+
+	assertion, session, err = w.BeginDiscoverableLogin()
+	require.NoError(t, err)
+
+	response, err = authenticator.GetAssertion(assertion.Response, "https://example.com")
+	require.NoError(t, err)
+
+	body, err = json.Marshal(response)
+	require.NoError(t, err)
+
+	credential, err = w.FinishDiscoverableLoginBytes(func(_, userHandle []byte) (webauthn.User, error) {
+		return user, nil
+	}, *session, body)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), credential.Authenticator.SignCount)
+
+	_, err = w.FinishLoginBytes(user, *session, []byte(`{"id":"`+strings.Repeat("a", 4096)+`"}`))
+
+	var e *protocol.Error
+
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, protocol.CodeResponseTooLarge, e.Code)
+}
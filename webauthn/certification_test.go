@@ -0,0 +1,170 @@
+package webauthn_test
+
+import (
+	"crypto/x509"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/metadata"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+	"github.com/go-webauthn/webauthn/webauthntest/webauthnmock"
+)
+
+func TestWebAuthn_MinimumCertificationLevel(t *testing.T) {
+	aaguid := uuid.MustParse("7b0c0d9b-7e5c-4b7a-9d4f-2c1a0e6e9c11")
+
+	testCases := []struct {
+		name     string
+		format   string
+		found    bool
+		status   metadata.AuthenticatorStatus
+		minimum  metadata.AuthenticatorStatus
+		expected protocol.ErrorCode
+	}{
+		{"ShouldAllowWithoutMinimum", webauthntest.FormatPacked, true, metadata.NotFidoCertified, "", ""},
+		{"ShouldAllowCertified", webauthntest.FormatPacked, true, metadata.FidoCertifiedL2, metadata.FidoCertifiedL1, ""},
+		{"ShouldRejectInsufficientLevel", webauthntest.FormatPacked, true, metadata.FidoCertifiedL1, metadata.FidoCertifiedL2, protocol.CodeCertificationLevelInsufficient},
+		{"ShouldRejectUncertified", webauthntest.FormatPacked, true, metadata.NotFidoCertified, metadata.FidoCertifiedL1, protocol.CodeCertificationLevelInsufficient},
+		{"ShouldRejectUnknown", webauthntest.FormatPacked, false, "", metadata.FidoCertifiedL1, protocol.CodeAuthenticatorUnknown},
+		{"ShouldRejectNoneFormat", webauthntest.FormatNone, true, metadata.FidoCertifiedL3, metadata.FidoCertifiedL1, protocol.CodeCertificationLevelInsufficient},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := &webauthnmock.MetadataProviderMock{
+				LookupByAAGUIDFunc: func(id uuid.UUID) (metadata.MetadataBLOBPayloadEntry, bool) {
+					assert.Equal(t, aaguid, id)
+
+					return metadata.MetadataBLOBPayloadEntry{
+						AaGUID:        id.String(),
+						StatusReports: []metadata.StatusReport{{Status: tc.status}},
+					}, tc.found
+				},
+				LookupByCertificateFunc: func(_ *x509.Certificate) (metadata.MetadataBLOBPayloadEntry, bool) {
+					return metadata.MetadataBLOBPayloadEntry{}, false
+				},
+			}
+
+			w, err := webauthn.New(&webauthn.Config{
+				RPID:          "example.com",
+				RPDisplayName: "Example",
+				RPOrigins:     []string{"https://example.com"},
+				AttestationPolicy: protocol.AttestationPolicy{
+					Metadata:                  provider,
+					MinimumCertificationLevel: tc.minimum,
+				},
+			})
+			require.NoError(t, err)
+
+			user := &bytesUser{}
+
+			creation, session, err := w.BeginRegistration(user)
+			require.NoError(t, err)
+
+			attestation, _, err := (&webauthntest.Authenticator{AAGUID: aaguid, Format: tc.format}).CreateCredential(creation.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err := webauthntest.NewRequest(attestation)
+			require.NoError(t, err)
+
+			_, err = w.FinishRegistration(user, *session, r)
+
+			if tc.expected == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			require.IsType(t, &protocol.Error{}, err)
+			assert.Equal(t, tc.expected, err.(*protocol.Error).Code)
+			assert.Equal(t, protocol.FailureReasonAttestationRejected, protocol.GetFailureReason(err))
+		})
+	}
+}
+
+func TestNew_MinimumCertificationLevel(t *testing.T) {
+	_, err := webauthn.New(&webauthn.Config{
+		RPID:              "example.com",
+		RPDisplayName:     "Example",
+		RPOrigins:         []string{"https://example.com"},
+		AttestationPolicy: protocol.AttestationPolicy{MinimumCertificationLevel: metadata.Revoked},
+	})
+
+	assert.EqualError(t, err, "error occurred validating the configuration: the field 'AttestationPolicy.MinimumCertificationLevel' must be a FIDO certification status but it is REVOKED")
+}
+
+func TestWebAuthn_MinimumAuthenticatorVersions(t *testing.T) {
+	aaguid := uuid.MustParse("2fc0579f-8113-47ea-b116-bb5a8db9202a")
+
+	testCases := []struct {
+		name     string
+		format   string
+		found    bool
+		version  uint32
+		minimum  map[uuid.UUID]uint32
+		expected protocol.ErrorCode
+	}{
+		{"ShouldAllowWithoutMinimum", webauthntest.FormatPacked, true, 1, nil, ""},
+		{"ShouldAllowOtherAAGUID", webauthntest.FormatPacked, true, 1, map[uuid.UUID]uint32{uuid.Nil: 5}, ""},
+		{"ShouldAllowEqualVersion", webauthntest.FormatPacked, true, 5, map[uuid.UUID]uint32{aaguid: 5}, ""},
+		{"ShouldRejectLowerVersion", webauthntest.FormatPacked, true, 4, map[uuid.UUID]uint32{aaguid: 5}, protocol.CodeAuthenticatorVersionInsufficient},
+		{"ShouldRejectUnknown", webauthntest.FormatPacked, false, 0, map[uuid.UUID]uint32{aaguid: 5}, protocol.CodeAuthenticatorUnknown},
+		{"ShouldRejectNoneFormat", webauthntest.FormatNone, true, 5, map[uuid.UUID]uint32{aaguid: 5}, protocol.CodeAuthenticatorVersionInsufficient},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := &webauthnmock.MetadataProviderMock{
+				LookupByAAGUIDFunc: func(id uuid.UUID) (metadata.MetadataBLOBPayloadEntry, bool) {
+					entry := metadata.MetadataBLOBPayloadEntry{AaGUID: id.String()}
+
+					entry.MetadataStatement.AuthenticatorVersion = tc.version
+
+					return entry, tc.found
+				},
+				LookupByCertificateFunc: func(_ *x509.Certificate) (metadata.MetadataBLOBPayloadEntry, bool) {
+					return metadata.MetadataBLOBPayloadEntry{}, false
+				},
+			}
+
+			w, err := webauthn.New(&webauthn.Config{
+				RPID:          "example.com",
+				RPDisplayName: "Example",
+				RPOrigins:     []string{"https://example.com"},
+				AttestationPolicy: protocol.AttestationPolicy{
+					Metadata:                     provider,
+					MinimumAuthenticatorVersions: tc.minimum,
+				},
+			})
+			require.NoError(t, err)
+
+			user := &bytesUser{}
+
+			creation, session, err := w.BeginRegistration(user)
+			require.NoError(t, err)
+
+			attestation, _, err := (&webauthntest.Authenticator{AAGUID: aaguid, Format: tc.format}).CreateCredential(creation.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err := webauthntest.NewRequest(attestation)
+			require.NoError(t, err)
+
+			_, err = w.FinishRegistration(user, *session, r)
+
+			if tc.expected == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			require.IsType(t, &protocol.Error{}, err)
+			assert.Equal(t, tc.expected, err.(*protocol.Error).Code)
+			assert.Equal(t, protocol.FailureReasonAttestationRejected, protocol.GetFailureReason(err))
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package webauthn
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+type challengeBindingContextKey struct{}
+
+// ContextWithChallengeBinding returns a copy of the context which carries the binding of the challenge, such as the
+// fingerprint of the session or the details of the transaction the ceremony approves. When the Config.ChallengeKey is
+// configured the FinishRegistration and FinishLogin methods verify the challenge was derived from the binding when the
+// request has the returned context. The binding is empty when the context has none.
+func ContextWithChallengeBinding(ctx context.Context, binding []byte) context.Context {
+	return context.WithValue(ctx, challengeBindingContextKey{}, binding)
+}
+
+// ChallengeBindingFromContext returns the binding of the challenge carried by the context, or nil if it has none.
+func ChallengeBindingFromContext(ctx context.Context) []byte {
+	binding, _ := ctx.Value(challengeBindingContextKey{}).([]byte)
+
+	return binding
+}
+
+// WithRegistrationChallengeBinding derives the challenge of the registration from the binding when the
+// Config.ChallengeKey is configured, otherwise it has no effect. The same binding must be supplied to
+// FinishRegistration with ContextWithChallengeBinding.
+func (webauthn *WebAuthn) WithRegistrationChallengeBinding(binding []byte) RegistrationOption {
+	return func(cco *protocol.PublicKeyCredentialCreationOptions) {
+		cco.Challenge = webauthn.config.bindChallenge(cco.Challenge, binding)
+	}
+}
+
+// WithLoginChallengeBinding derives the challenge of the login from the binding when the Config.ChallengeKey is
+// configured, otherwise it has no effect. The same binding must be supplied to FinishLogin with
+// ContextWithChallengeBinding.
+func (webauthn *WebAuthn) WithLoginChallengeBinding(binding []byte) LoginOption {
+	return func(cro *protocol.PublicKeyCredentialRequestOptions) {
+		cro.Challenge = webauthn.config.bindChallenge(cro.Challenge, binding)
+	}
+}
+
+// bindChallenge replaces the binding of a challenge derived by newChallenge.
+func (config *Config) bindChallenge(challenge protocol.URLEncodedBase64, binding []byte) protocol.URLEncodedBase64 {
+	if len(config.ChallengeKey) == 0 || len(challenge) < protocol.MinChallengeLength+sha256.Size {
+		return challenge
+	}
+
+	return protocol.URLEncodedBase64(protocol.DeriveChallenge(config.ChallengeKey, challenge[:len(challenge)-sha256.Size], binding))
+}
+
+func (webauthn *WebAuthn) verifyChallengeBinding(ctx context.Context, trace *protocol.VerificationTrace, clientData *protocol.CollectedClientData) error {
+	if len(webauthn.config.ChallengeKey) == 0 {
+		return nil
+	}
+
+	binding := ChallengeBindingFromContext(ctx)
+
+	var err error
+
+	if challenge, decodeErr := base64.RawURLEncoding.DecodeString(clientData.Challenge); decodeErr != nil || !protocol.VerifyDerivedChallenge(challenge, webauthn.config.ChallengeKey, binding) {
+		err = protocol.ErrVerification.
+			WithCode(protocol.CodeChallengeBindingMismatch).
+			WithDetails("Error validating challenge binding")
+	}
+
+	return trace.Step(protocol.VerificationStepChallengeBinding, err, map[string]string{"binding_hash": auditHash(binding)})
+}
@@ -0,0 +1,104 @@
+package webauthn_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+func TestWebAuthn_ChallengeBinding(t *testing.T) {
+	testCases := []struct {
+		name     string
+		begin    []byte
+		finish   []byte
+		expected bool
+	}{
+		{"ShouldVerifyBinding", []byte("transfer 100 EUR"), []byte("transfer 100 EUR"), true},
+		{"ShouldVerifyEmptyBinding", nil, nil, true},
+		{"ShouldFailOtherBinding", []byte("transfer 100 EUR"), []byte("transfer 900 EUR"), false},
+		{"ShouldFailMissingBinding", []byte("transfer 100 EUR"), nil, false},
+		{"ShouldFailUnexpectedBinding", nil, []byte("transfer 100 EUR"), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := webauthn.New(&webauthn.Config{
+				RPID:          "example.com",
+				RPDisplayName: "Example",
+				RPOrigins:     []string{"https://example.com"},
+				ChallengeKey:  bytes.Repeat([]byte{0x01}, 32),
+			})
+			require.NoError(t, err)
+
+			user := &bytesUser{}
+			authenticator := &webauthntest.Authenticator{}
+
+			creation, session, err := w.BeginRegistration(user, w.WithRegistrationChallengeBinding(tc.begin))
+			require.NoError(t, err)
+			assert.Len(t, creation.Response.Challenge, protocol.ChallengeLength+32)
+			assert.Equal(t, creation.Response.Challenge.String(), session.Challenge)
+
+			attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err := webauthntest.NewRequest(attestation)
+			require.NoError(t, err)
+
+			credential, err := w.FinishRegistration(user, *session, r.WithContext(webauthn.ContextWithChallengeBinding(context.Background(), tc.finish)))
+			assertChallengeBinding(t, tc.expected, err)
+
+			if err != nil {
+				return
+			}
+
+			user.credentials = append(user.credentials, *credential)
+
+			assertion, session, err := w.BeginLogin(user, w.WithLoginChallengeBinding(tc.begin))
+			require.NoError(t, err)
+
+			response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err = webauthntest.NewRequest(response)
+			require.NoError(t, err)
+
+			_, err = w.FinishLogin(user, *session, r.WithContext(webauthn.ContextWithChallengeBinding(context.Background(), tc.finish)))
+			assertChallengeBinding(t, tc.expected, err)
+		})
+	}
+}
+
+func TestWebAuthn_ChallengeBindingWithoutKey(t *testing.T) {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+	})
+	require.NoError(t, err)
+
+	assertion, session, err := w.BeginDiscoverableLogin(w.WithLoginChallengeBinding([]byte("transaction")))
+	require.NoError(t, err)
+	assert.Len(t, assertion.Response.Challenge, protocol.ChallengeLength)
+	assert.Equal(t, assertion.Response.Challenge.String(), session.Challenge)
+}
+
+func assertChallengeBinding(t *testing.T, expected bool, err error) {
+	if expected {
+		require.NoError(t, err)
+
+		return
+	}
+
+	var e *protocol.Error
+
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, protocol.CodeChallengeBindingMismatch, e.Code)
+}
@@ -0,0 +1,195 @@
+package webauthn
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// ErrChallengeNotFound is returned by a ChallengeStore when there is no challenge with the value, or it has expired.
+var ErrChallengeNotFound = errors.New("webauthn: challenge not found")
+
+// ChallengeStore is the storage of the SessionData between the beginning and the end of a ceremony, keyed by its
+// challenge, for deployments which keep the sessions on the server instead of round-tripping them through the client.
+// The challenges are random values, so they can be used as the keys of the store.
+//
+// The whole SessionData must be stored, as the finish methods verify the response against it the same as against a
+// SessionData provided by the caller. The user the challenge was issued to, the options of the begin methods such as
+// WithUserVerification, and the flags of the step-up and recovery logins are therefore all verified.
+type ChallengeStore interface {
+	// PutChallenge stores the session keyed by its Challenge until it's taken or the ttl has elapsed.
+	PutChallenge(ctx context.Context, session SessionData, ttl time.Duration) (err error)
+
+	// TakeChallenge deletes and returns the session with the challenge, or returns ErrChallengeNotFound if there is no
+	// such session or it has expired. It must be atomic, as a challenge must only be taken once.
+	TakeChallenge(ctx context.Context, challenge string) (session SessionData, err error)
+}
+
+// BeginRegistrationWithStore is the same as BeginRegistrationCtx except the SessionData is stored in the ChallengeStore
+// for the timeout of the ceremony instead of being returned.
+func (webauthn *WebAuthn) BeginRegistrationWithStore(ctx context.Context, store ChallengeStore, user User, opts ...RegistrationOption) (*protocol.CredentialCreation, error) {
+	creation, session, err := webauthn.BeginRegistrationCtx(ctx, user, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = store.PutChallenge(ctx, *session, challengeTTL(creation.Response.Timeout, webauthn.config.Timeouts.Registration)); err != nil {
+		return nil, err
+	}
+
+	return creation, nil
+}
+
+// FinishRegistrationWithStore is the same as FinishRegistrationCtx except the SessionData is taken from the
+// ChallengeStore by the challenge of the response instead of being provided. The user must be the one the challenge was
+// issued to.
+func (webauthn *WebAuthn) FinishRegistrationWithStore(ctx context.Context, store ChallengeStore, user User, response *http.Request) (*Credential, error) {
+	ctx, observer := webauthn.startCeremony(connectionContext(ctx, response), CeremonyFinishRegistration, user.WebAuthnID())
+
+	observer.recordRequest(SessionData{}, response)
+
+	webauthn.config.compatibleRequest(response)
+
+	parsedResponse, err := protocol.ParseCredentialCreationResponseWithLimit(response, webauthn.config.responseBodyLimit())
+
+	observer.trace.Record(protocol.VerificationStepParse, err, nil)
+
+	if err != nil {
+		return observer.finish(nil, err)
+	}
+
+	session, err := webauthn.takeChallenge(ctx, observer.trace, store, parsedResponse.Response.CollectedClientData.Challenge)
+
+	observer.recordSession(session)
+
+	if err != nil {
+		return observer.finish(nil, err)
+	}
+
+	return observer.finish(webauthn.createCredential(ctx, observer.trace, user, session, parsedResponse, false))
+}
+
+// BeginLoginWithStore is the same as BeginLoginCtx except the SessionData is stored in the ChallengeStore for the
+// timeout of the ceremony instead of being returned.
+func (webauthn *WebAuthn) BeginLoginWithStore(ctx context.Context, store ChallengeStore, user User, opts ...LoginOption) (*protocol.CredentialAssertion, error) {
+	assertion, session, err := webauthn.BeginLoginCtx(ctx, user, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = store.PutChallenge(ctx, *session, challengeTTL(assertion.Response.Timeout, webauthn.config.Timeouts.Login)); err != nil {
+		return nil, err
+	}
+
+	return assertion, nil
+}
+
+// FinishLoginWithStore is the same as FinishLoginCtx except the SessionData is taken from the ChallengeStore by the
+// challenge of the response instead of being provided. The user must be the one the challenge was issued to.
+func (webauthn *WebAuthn) FinishLoginWithStore(ctx context.Context, store ChallengeStore, user User, response *http.Request) (*Credential, error) {
+	ctx, observer := webauthn.startCeremony(connectionContext(ctx, response), CeremonyFinishLogin, user.WebAuthnID())
+
+	observer.recordRequest(SessionData{}, response)
+
+	webauthn.config.compatibleRequest(response)
+
+	parsedResponse, err := protocol.ParseCredentialRequestResponseWithLimit(response, webauthn.config.responseBodyLimit())
+
+	observer.trace.Record(protocol.VerificationStepParse, err, nil)
+
+	if err != nil {
+		return observer.finish(nil, err)
+	}
+
+	session, err := webauthn.takeChallenge(ctx, observer.trace, store, parsedResponse.Response.CollectedClientData.Challenge)
+
+	observer.recordSession(session)
+
+	if err != nil {
+		return observer.finish(nil, err)
+	}
+
+	return observer.finish(loginCredential(webauthn.validateUserLogin(ctx, observer.trace, user, session, parsedResponse)))
+}
+
+// BeginDiscoverableLoginWithStore is the same as BeginDiscoverableLoginCtx except the SessionData is stored in the
+// ChallengeStore for the timeout of the ceremony instead of being returned.
+func (webauthn *WebAuthn) BeginDiscoverableLoginWithStore(ctx context.Context, store ChallengeStore, opts ...LoginOption) (*protocol.CredentialAssertion, error) {
+	assertion, session, err := webauthn.BeginDiscoverableLoginCtx(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = store.PutChallenge(ctx, *session, challengeTTL(assertion.Response.Timeout, webauthn.config.Timeouts.Login)); err != nil {
+		return nil, err
+	}
+
+	return assertion, nil
+}
+
+// FinishDiscoverableLoginWithStore is the same as FinishDiscoverableLoginCtx except the SessionData is taken from the
+// ChallengeStore by the challenge of the response instead of being provided.
+func (webauthn *WebAuthn) FinishDiscoverableLoginWithStore(ctx context.Context, store ChallengeStore, handler DiscoverableUserHandler, response *http.Request) (*Credential, error) {
+	ctx, observer := webauthn.startCeremony(connectionContext(ctx, response), CeremonyFinishDiscoverableLogin, nil)
+
+	observer.recordRequest(SessionData{}, response)
+
+	webauthn.config.compatibleRequest(response)
+
+	parsedResponse, err := protocol.ParseCredentialRequestResponseWithLimit(response, webauthn.config.responseBodyLimit())
+
+	observer.trace.Record(protocol.VerificationStepParse, err, nil)
+
+	if err != nil {
+		return observer.finish(nil, err)
+	}
+
+	observer.userID = parsedResponse.Response.UserHandle
+
+	session, err := webauthn.takeChallenge(ctx, observer.trace, store, parsedResponse.Response.CollectedClientData.Challenge)
+
+	observer.recordSession(session)
+
+	if err != nil {
+		return observer.finish(nil, err)
+	}
+
+	return observer.finish(loginCredential(webauthn.validateDiscoverableLogin(ctx, observer.trace, handler, session, parsedResponse)))
+}
+
+// challengeTTL returns the time a challenge is stored for, which is the timeout of the ceremony in milliseconds and
+// the grace period of the timeouts.
+func challengeTTL(timeout int, timeouts TimeoutConfig) time.Duration {
+	return time.Duration(timeout)*time.Millisecond + timeouts.Grace
+}
+
+// takeChallenge takes the SessionData of the challenge from the ChallengeStore. The SessionData only has the challenge
+// when it can't be taken, so the challenge is still recorded.
+func (webauthn *WebAuthn) takeChallenge(ctx context.Context, trace *protocol.VerificationTrace, store ChallengeStore, challenge string) (session SessionData, err error) {
+	if session, err = store.TakeChallenge(ctx, challenge); errors.Is(err, ErrChallengeNotFound) {
+		err = protocol.ErrChallengeMismatch.
+			WithCode(protocol.CodeChallengeMismatch).
+			WithDetails("Challenge was not issued or has already been used")
+	}
+
+	if err != nil {
+		trace.Record(protocol.VerificationStepSession, err, nil)
+
+		return SessionData{Challenge: challenge}, err
+	}
+
+	if session.Challenge != challenge {
+		err = protocol.ErrChallengeMismatch.
+			WithCode(protocol.CodeChallengeMismatch).
+			WithDetails("Stored session was not issued for the challenge")
+
+		trace.Record(protocol.VerificationStepSession, err, nil)
+
+		return SessionData{Challenge: challenge}, err
+	}
+
+	return session, nil
+}
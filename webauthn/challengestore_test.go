@@ -0,0 +1,176 @@
+package webauthn_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+type storedChallenge struct {
+	session webauthn.SessionData
+	expires time.Time
+}
+
+type memoryChallengeStore struct {
+	mu         sync.Mutex
+	challenges map[string]storedChallenge
+}
+
+func (s *memoryChallengeStore) PutChallenge(_ context.Context, session webauthn.SessionData, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.challenges == nil {
+		s.challenges = map[string]storedChallenge{}
+	}
+
+	s.challenges[session.Challenge] = storedChallenge{session: session, expires: time.Now().Add(ttl)}
+
+	return nil
+}
+
+func (s *memoryChallengeStore) TakeChallenge(_ context.Context, challenge string) (webauthn.SessionData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.challenges[challenge]
+
+	delete(s.challenges, challenge)
+
+	if !ok || stored.expires.Before(time.Now()) {
+		return webauthn.SessionData{}, webauthn.ErrChallengeNotFound
+	}
+
+	return stored.session, nil
+}
+
+func TestWebAuthn_ChallengeStore(t *testing.T) {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	store := &memoryChallengeStore{}
+	user := &bytesUser{}
+	authenticator := &webauthntest.Authenticator{}
+
+	creation, err := w.BeginRegistrationWithStore(ctx, store, user, webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired))
+	require.NoError(t, err)
+
+	assert.Len(t, store.challenges, 1)
+
+	attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+	require.NoError(t, err)
+
+	r, err := webauthntest.NewRequest(attestation)
+	require.NoError(t, err)
+
+	credential, err := w.FinishRegistrationWithStore(ctx, store, user, r)
+	require.NoError(t, err)
+
+	assert.Empty(t, store.challenges)
+
+	user.credentials = append(user.credentials, *credential)
+
+	t.Run("ShouldRejectReplayedRegistration", func(t *testing.T) {
+		r, err := webauthntest.NewRequest(attestation)
+		require.NoError(t, err)
+
+		_, err = w.FinishRegistrationWithStore(ctx, store, user, r)
+		assertErrorCode(t, protocol.CodeChallengeMismatch, err)
+	})
+
+	t.Run("ShouldLogin", func(t *testing.T) {
+		assertion, err := w.BeginLoginWithStore(ctx, store, user)
+		require.NoError(t, err)
+
+		response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+		require.NoError(t, err)
+
+		r, err := webauthntest.NewRequest(response)
+		require.NoError(t, err)
+
+		_, err = w.FinishLoginWithStore(ctx, store, user, r)
+		require.NoError(t, err)
+
+		r, err = webauthntest.NewRequest(response)
+		require.NoError(t, err)
+
+		_, err = w.FinishLoginWithStore(ctx, store, user, r)
+		assertErrorCode(t, protocol.CodeChallengeMismatch, err)
+	})
+
+	t.Run("ShouldLoginDiscoverable", func(t *testing.T) {
+		assertion, err := w.BeginDiscoverableLoginWithStore(ctx, store)
+		require.NoError(t, err)
+
+		response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+		require.NoError(t, err)
+
+		r, err := webauthntest.NewRequest(response)
+		require.NoError(t, err)
+
+		_, err = w.FinishDiscoverableLoginWithStore(ctx, store, func(_, _ []byte) (webauthn.User, error) {
+			return user, nil
+		}, r)
+		assert.NoError(t, err)
+	})
+
+	t.Run("ShouldRejectOtherUser", func(t *testing.T) {
+		assertion, err := w.BeginLoginWithStore(ctx, store, user)
+		require.NoError(t, err)
+
+		response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+		require.NoError(t, err)
+
+		r, err := webauthntest.NewRequest(response)
+		require.NoError(t, err)
+
+		_, err = w.FinishLoginWithStore(ctx, store, &otherUser{bytesUser: *user}, r)
+		assertErrorCode(t, protocol.CodeUserSessionMismatch, err)
+	})
+
+	t.Run("ShouldRequireUserVerificationOfBegin", func(t *testing.T) {
+		assertion, err := w.BeginLoginWithStore(ctx, store, user, webauthn.WithUserVerification(protocol.VerificationRequired))
+		require.NoError(t, err)
+
+		authenticator.Flags = protocol.FlagUserPresent
+
+		response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+
+		authenticator.Flags = 0
+
+		require.NoError(t, err)
+
+		r, err := webauthntest.NewRequest(response)
+		require.NoError(t, err)
+
+		_, err = w.FinishLoginWithStore(ctx, store, user, r)
+		assertErrorCode(t, protocol.CodeUVRequired, err)
+	})
+
+	t.Run("ShouldRejectUnknownChallenge", func(t *testing.T) {
+		assertion, _, err := w.BeginLogin(user)
+		require.NoError(t, err)
+
+		response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+		require.NoError(t, err)
+
+		r, err := webauthntest.NewRequest(response)
+		require.NoError(t, err)
+
+		_, err = w.FinishLoginWithStore(ctx, store, user, r)
+		assertErrorCode(t, protocol.CodeChallengeMismatch, err)
+	})
+}
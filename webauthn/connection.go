@@ -0,0 +1,61 @@
+package webauthn
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+)
+
+// ConnectionInfo is the metadata of the connection a ceremony response is received over, which is provided to the
+// LoginHooks Connection hook so deployments can implement their own device binding or anomaly rules.
+type ConnectionInfo struct {
+	// RemoteAddr is the network address of the client. It's the RemoteAddr of the request by default, so deployments
+	// behind a proxy should supply the address of the client with ContextWithConnectionInfo instead.
+	RemoteAddr string
+
+	// UserAgent is the User-Agent header of the request.
+	UserAgent string
+
+	// TLSFingerprint is the fingerprint of the TLS client such as the JA3 or JA4 hash. It's not available from the
+	// request, so it must be supplied with ContextWithConnectionInfo.
+	TLSFingerprint string
+
+	// TLS is the state of the TLS connection, or nil if the connection is not using TLS.
+	TLS *tls.ConnectionState
+}
+
+type connectionInfoContextKey struct{}
+
+// ContextWithConnectionInfo returns a copy of the context which carries the ConnectionInfo of the connection the
+// response is received over. The FinishLogin methods use the ConnectionInfo of the request when the context does not
+// carry one.
+func ContextWithConnectionInfo(ctx context.Context, info ConnectionInfo) context.Context {
+	return context.WithValue(ctx, connectionInfoContextKey{}, info)
+}
+
+// ConnectionInfoFromContext returns the ConnectionInfo carried by the context and true, or the zero value and false if
+// it has none.
+func ConnectionInfoFromContext(ctx context.Context) (info ConnectionInfo, ok bool) {
+	info, ok = ctx.Value(connectionInfoContextKey{}).(ConnectionInfo)
+
+	return info, ok
+}
+
+// ConnectionInfoFromRequest returns the ConnectionInfo of the request.
+func ConnectionInfoFromRequest(r *http.Request) ConnectionInfo {
+	return ConnectionInfo{
+		RemoteAddr: r.RemoteAddr,
+		UserAgent:  r.UserAgent(),
+		TLS:        r.TLS,
+	}
+}
+
+// connectionContext returns a copy of the context which carries the ConnectionInfo of the request unless it already
+// carries one.
+func connectionContext(ctx context.Context, r *http.Request) context.Context {
+	if _, ok := ConnectionInfoFromContext(ctx); ok || r == nil {
+		return ctx
+	}
+
+	return ContextWithConnectionInfo(ctx, ConnectionInfoFromRequest(r))
+}
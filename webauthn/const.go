@@ -13,4 +13,11 @@ const (
 const (
 	defaultTimeoutUVD = time.Millisecond * 120000
 	defaultTimeout    = time.Millisecond * 300000
+
+	defaultTimeoutStepUp = time.Minute
+	defaultTimeoutHybrid = time.Minute * 10
+)
+
+const (
+	defaultReceiptLifetime = time.Minute * 5
 )
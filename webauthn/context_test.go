@@ -0,0 +1,108 @@
+package webauthn_test
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/metadata"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+type contextKey struct{}
+
+// contextMetadata is a metadata.ContextProvider which records the contexts of the lookups.
+type contextMetadata struct {
+	values []interface{}
+}
+
+func (p *contextMetadata) LookupByAAGUID(_ uuid.UUID) (metadata.MetadataBLOBPayloadEntry, bool) {
+	p.values = append(p.values, nil)
+
+	return metadata.MetadataBLOBPayloadEntry{}, false
+}
+
+func (p *contextMetadata) LookupByCertificate(_ *x509.Certificate) (metadata.MetadataBLOBPayloadEntry, bool) {
+	p.values = append(p.values, nil)
+
+	return metadata.MetadataBLOBPayloadEntry{}, false
+}
+
+func (p *contextMetadata) LookupByAAGUIDContext(ctx context.Context, _ uuid.UUID) (metadata.MetadataBLOBPayloadEntry, bool) {
+	p.values = append(p.values, ctx.Value(contextKey{}))
+
+	return metadata.MetadataBLOBPayloadEntry{}, false
+}
+
+func (p *contextMetadata) LookupByCertificateContext(ctx context.Context, _ *x509.Certificate) (metadata.MetadataBLOBPayloadEntry, bool) {
+	p.values = append(p.values, ctx.Value(contextKey{}))
+
+	return metadata.MetadataBLOBPayloadEntry{}, false
+}
+
+func TestWebAuthn_Ctx(t *testing.T) {
+	provider := &contextMetadata{}
+
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:              "example.com",
+		RPDisplayName:     "Example",
+		RPOrigins:         []string{"https://example.com"},
+		AttestationPolicy: protocol.AttestationPolicy{Metadata: provider},
+	})
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), contextKey{}, "ceremony")
+
+	user := &bytesUser{}
+	authenticator := &webauthntest.Authenticator{Format: webauthntest.FormatPacked}
+
+	creation, session, err := w.BeginRegistrationCtx(ctx, user)
+	require.NoError(t, err)
+
+	attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+	require.NoError(t, err)
+
+	r, err := webauthntest.NewRequest(attestation)
+	require.NoError(t, err)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, err = w.FinishRegistrationCtx(cancelled, user, *session, r)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, provider.values)
+
+	r, err = webauthntest.NewRequest(attestation)
+	require.NoError(t, err)
+
+	credential, err := w.FinishRegistrationCtx(ctx, user, *session, r)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"ceremony"}, provider.values)
+
+	user.credentials = append(user.credentials, *credential)
+
+	assertion, session, err := w.BeginLoginCtx(ctx, user)
+	require.NoError(t, err)
+
+	response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+	require.NoError(t, err)
+
+	r, err = webauthntest.NewRequest(response)
+	require.NoError(t, err)
+
+	_, err = w.FinishLoginCtx(cancelled, user, *session, r)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	r, err = webauthntest.NewRequest(response)
+	require.NoError(t, err)
+
+	credential, err = w.FinishLoginCtx(ctx, user, *session, r)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), credential.Authenticator.SignCount)
+}
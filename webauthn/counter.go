@@ -0,0 +1,115 @@
+package webauthn
+
+import (
+	"sync"
+)
+
+// CounterAnomaly describes how the signature counter returned by an authenticator during login deviated from the
+// expected behavior of always increasing.
+type CounterAnomaly string
+
+const (
+	// CounterAnomalyNone indicates the signature counter increased.
+	CounterAnomalyNone CounterAnomaly = ""
+
+	// CounterAnomalyRegressed indicates the signature counter was not greater than the stored signature counter, which
+	// is a signal the authenticator may have been cloned.
+	CounterAnomalyRegressed CounterAnomaly = "regressed"
+
+	// CounterAnomalyZero indicates both the signature counter and the stored signature counter were zero, which is the
+	// case for authenticators which do not implement a signature counter such as most synced passkeys.
+	CounterAnomalyZero CounterAnomaly = "zero"
+)
+
+// CounterObservation describes the signature counter evaluated during a single login.
+type CounterObservation struct {
+	// Anomaly is the anomaly observed, or CounterAnomalyNone if the signature counter increased.
+	Anomaly CounterAnomaly
+
+	// Rejected is true if the login was rejected due to the CounterPolicy.
+	Rejected bool
+
+	// AAGUID is the AAGUID of the authenticator which performed the login.
+	AAGUID []byte
+
+	// Stored is the signature counter stored with the credential before the login.
+	Stored uint32
+
+	// Received is the signature counter returned by the authenticator.
+	Received uint32
+}
+
+// CounterMetricsSink is an optional interface a MetricsSink may implement to receive every signature counter
+// evaluated during login, which is useful for exporting the rate of counter anomalies across the fleet of
+// authenticators to decide on an appropriate CounterPolicy. Implementations must be safe for concurrent use and
+// should not block.
+type CounterMetricsSink interface {
+	ObserveCounter(observation CounterObservation)
+}
+
+// CounterStats are the statistics about the signature counters evaluated during login by a *WebAuthn since it was
+// created.
+type CounterStats struct {
+	// Logins is the number of logins which evaluated the signature counter.
+	Logins uint64
+
+	// Regressions is the number of logins where the signature counter regressed.
+	Regressions uint64
+
+	// Rejected is the number of logins which were rejected because the signature counter regressed.
+	Rejected uint64
+
+	// ZeroCounters is the number of logins where the authenticator did not implement a signature counter.
+	ZeroCounters uint64
+}
+
+// CounterStats returns the statistics about the signature counters evaluated during login since the *WebAuthn was
+// created. Logins performed by Diagnose are not included.
+func (webauthn *WebAuthn) CounterStats() CounterStats {
+	webauthn.counters.mu.Lock()
+	defer webauthn.counters.mu.Unlock()
+
+	return webauthn.counters.stats
+}
+
+type counterStats struct {
+	mu    sync.Mutex
+	stats CounterStats
+}
+
+// observeCounter records the CounterObservation in the CounterStats and reports it to the MetricsSink if it
+// implements CounterMetricsSink.
+func (webauthn *WebAuthn) observeCounter(observation CounterObservation) {
+	webauthn.counters.mu.Lock()
+
+	webauthn.counters.stats.Logins++
+
+	switch observation.Anomaly {
+	case CounterAnomalyRegressed:
+		webauthn.counters.stats.Regressions++
+	case CounterAnomalyZero:
+		webauthn.counters.stats.ZeroCounters++
+	}
+
+	if observation.Rejected {
+		webauthn.counters.stats.Rejected++
+	}
+
+	webauthn.counters.mu.Unlock()
+
+	if sink, ok := webauthn.config.MetricsSink.(CounterMetricsSink); ok {
+		sink.ObserveCounter(observation)
+	}
+}
+
+// counterAnomaly returns the CounterAnomaly of the provided signature counter value.
+func (a *Authenticator) counterAnomaly(authDataCount uint32) CounterAnomaly {
+	switch {
+	case a.counterRegressed(authDataCount):
+		return CounterAnomalyRegressed
+	case authDataCount == 0 && a.SignCount == 0:
+		return CounterAnomalyZero
+	default:
+		return CounterAnomalyNone
+	}
+}
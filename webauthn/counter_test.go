@@ -0,0 +1,124 @@
+package webauthn_test
+
+import (
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+type counterMetricsSink struct {
+	mu           sync.Mutex
+	observations []webauthn.CounterObservation
+}
+
+func (s *counterMetricsSink) ObserveCeremony(_ webauthn.CeremonyOutcome) {}
+
+func (s *counterMetricsSink) ObserveCounter(observation webauthn.CounterObservation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.observations = append(s.observations, observation)
+}
+
+func TestWebAuthn_CounterStats(t *testing.T) {
+	testCases := []struct {
+		name     string
+		policy   webauthn.CounterPolicy
+		expected webauthn.CounterStats
+	}{
+		{"ShouldTrackWithWarnPolicy", webauthn.CounterPolicyWarn, webauthn.CounterStats{Logins: 3, Regressions: 1, ZeroCounters: 1}},
+		{"ShouldTrackWithRejectPolicy", webauthn.CounterPolicyReject, webauthn.CounterStats{Logins: 3, Regressions: 1, Rejected: 1, ZeroCounters: 1}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sink := &counterMetricsSink{}
+
+			w, err := webauthn.New(&webauthn.Config{
+				RPID:          "example.com",
+				RPDisplayName: "Example",
+				RPOrigins:     []string{"https://example.com"},
+				MetricsSink:   sink,
+				CounterPolicy: tc.policy,
+			})
+			require.NoError(t, err)
+
+			user := &bytesUser{}
+			authenticator := &webauthntest.Authenticator{}
+
+			creation, session, err := w.BeginRegistration(user)
+			require.NoError(t, err)
+
+			attestation, credential, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err := webauthntest.NewRequest(attestation)
+			require.NoError(t, err)
+
+			registered, err := w.FinishRegistration(user, *session, r)
+			require.NoError(t, err)
+
+			user.credentials = append(user.credentials, *registered)
+
+			login := func() (*webauthn.Credential, error) {
+				assertion, session, err := w.BeginLogin(user)
+				require.NoError(t, err)
+
+				response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+				require.NoError(t, err)
+
+				r, err := webauthntest.NewRequest(response)
+				require.NoError(t, err)
+
+				return w.FinishLogin(user, *session, r)
+			}
+
+			// The counter increased.
+			updated, err := login()
+			require.NoError(t, err)
+
+			user.credentials[0] = *updated
+
+			// The counter regressed.
+			credential.Counter = 0
+
+			_, err = login()
+			if tc.policy == webauthn.CounterPolicyReject {
+				require.IsType(t, &protocol.Error{}, err)
+				assert.Equal(t, protocol.CodeCounterRegressed, err.(*protocol.Error).Code)
+			} else {
+				require.NoError(t, err)
+			}
+
+			// The authenticator does not implement a counter.
+			user.credentials[0].Authenticator.SignCount = 0
+			credential.Counter = math.MaxUint32
+
+			_, err = login()
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, w.CounterStats())
+
+			require.Len(t, sink.observations, 3)
+
+			assert.Equal(t, webauthn.CounterAnomalyNone, sink.observations[0].Anomaly)
+			assert.Equal(t, uint32(0), sink.observations[0].Stored)
+			assert.Equal(t, uint32(1), sink.observations[0].Received)
+
+			assert.Equal(t, webauthn.CounterAnomalyRegressed, sink.observations[1].Anomaly)
+			assert.Equal(t, tc.policy == webauthn.CounterPolicyReject, sink.observations[1].Rejected)
+			assert.Equal(t, uint32(1), sink.observations[1].Stored)
+			assert.Equal(t, uint32(1), sink.observations[1].Received)
+
+			assert.Equal(t, webauthn.CounterAnomalyZero, sink.observations[2].Anomaly)
+			assert.False(t, sink.observations[2].Rejected)
+		})
+	}
+}
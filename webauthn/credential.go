@@ -26,6 +26,33 @@ type Credential struct {
 
 	// The Authenticator information for a given certificate.
 	Authenticator Authenticator `json:"authenticator"`
+
+	// UserVerification tightens the user verification requirement of the login session when the credential is used to
+	// log in, such as to always require user verification for a security key. User verification is required if either
+	// the login session or this requirement requires it, so it never weakens the requirement of the session. It's never
+	// stored by MakeNewCredential, and user verification is always required by a step-up or recovery login regardless.
+	UserVerification protocol.UserVerificationRequirement `json:"userVerification,omitempty"`
+
+	// Origin is the fully qualified origin the credential was registered from. When Config.PinCredentialOrigins is
+	// enabled the credential may only be used to log in from this origin or one of the AllowedOrigins.
+	Origin string `json:"origin,omitempty"`
+
+	// AllowedOrigins are the fully qualified origins other than the Origin the credential may be used to log in from
+	// when Config.PinCredentialOrigins is enabled, which should be a subset of the Config.RPOrigins.
+	AllowedOrigins []string `json:"allowedOrigins,omitempty"`
+
+	// AttestationCertificates is the DER encoded x5c certificate chain of the attestation statement the credential was
+	// registered with, starting with the attestation certificate. It's empty if the attestation statement did not
+	// include a certificate chain. See ExpiringAttestationCertificates.
+	AttestationCertificates [][]byte `json:"attestationCertificates,omitempty"`
+
+	// Recovery is true if the credential was enrolled with BeginRecoveryRegistration as a recovery credential, which
+	// is the only kind of credential allowed by BeginRecoveryLogin.
+	Recovery bool `json:"recovery,omitempty"`
+
+	// Name is the name of the credential chosen by the user, such as with PasskeyManager.RenamePasskey, which allows
+	// the user to tell their credentials apart.
+	Name string `json:"name,omitempty"`
 }
 
 type CredentialFlags struct {
@@ -53,6 +80,27 @@ func (c Credential) Descriptor() (descriptor protocol.CredentialDescriptor) {
 	}
 }
 
+// userVerificationRequired returns true if user verification is required when the credential is used to log in with
+// the session, which is the case if either the session or the credential requires it. It's always required for step-up
+// and recovery logins.
+func (c Credential) userVerificationRequired(session SessionData) bool {
+	if session.StepUp || session.Recovery {
+		return true
+	}
+
+	return c.UserVerification == protocol.VerificationRequired || session.UserVerification == protocol.VerificationRequired
+}
+
+// pinnedOrigins returns the origins the credential may be used to log in from, or nil if the origin the credential was
+// registered from is unknown.
+func (c Credential) pinnedOrigins() []string {
+	if c.Origin == "" {
+		return nil
+	}
+
+	return append([]string{c.Origin}, c.AllowedOrigins...)
+}
+
 // MakeNewCredential will return a credential pointer on successful validation of a registration response.
 func MakeNewCredential(c *protocol.ParsedCredentialCreationData) (*Credential, error) {
 	newCredential := &Credential{
@@ -73,5 +121,24 @@ func MakeNewCredential(c *protocol.ParsedCredentialCreationData) (*Credential, e
 		},
 	}
 
+	if origin, err := protocol.FullyQualifiedOrigin(c.Response.CollectedClientData.Origin); err == nil {
+		newCredential.Origin = origin
+	}
+
+	newCredential.AttestationCertificates = attestationCertificates(c)
+
 	return newCredential, nil
 }
+
+// attestationCertificates returns the DER encoded x5c certificate chain of the attestation statement of the response.
+func attestationCertificates(c *protocol.ParsedCredentialCreationData) (certificates [][]byte) {
+	if x5c, ok := c.Response.AttestationObject.AttStatement["x5c"].([]interface{}); ok {
+		for _, raw := range x5c {
+			if cert, ok := raw.([]byte); ok {
+				certificates = append(certificates, cert)
+			}
+		}
+	}
+
+	return certificates
+}
@@ -0,0 +1,106 @@
+package webauthn
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// DeferredAttestation is the attestation verification of a registration which was finished with
+// FinishRegistrationDeferred or CreateCredentialDeferred. The Credential of the registration must be considered
+// unverified until Verify has returned nil.
+type DeferredAttestation struct {
+	webauthn       *WebAuthn
+	userID         []byte
+	credential     *Credential
+	parsedResponse *protocol.ParsedCredentialCreationData
+	highAssurance  bool
+
+	once sync.Once
+	err  error
+}
+
+// FinishRegistrationDeferred is the same as FinishRegistration except it only verifies the client data and the
+// authenticator data before returning the new Credential. The verification of the attestation statement and the
+// metadata, which may involve slow certificate chain verification, is instead performed by the returned
+// *DeferredAttestation. This allows the registration response to be sent before the attestation has been verified.
+func (webauthn *WebAuthn) FinishRegistrationDeferred(user User, session SessionData, response *http.Request) (*Credential, *DeferredAttestation, error) {
+	return webauthn.FinishRegistrationDeferredCtx(requestContext(response), user, session, response)
+}
+
+// FinishRegistrationDeferredCtx is the same as FinishRegistrationDeferred except the provided context is used instead
+// of the context of the request.
+func (webauthn *WebAuthn) FinishRegistrationDeferredCtx(ctx context.Context, user User, session SessionData, response *http.Request) (*Credential, *DeferredAttestation, error) {
+	ctx, observer := webauthn.startCeremony(ctx, CeremonyFinishRegistrationDeferred, user.WebAuthnID())
+
+	observer.recordRequest(session, response)
+
+	webauthn.config.compatibleRequest(response)
+
+	parsedResponse, err := protocol.ParseCredentialCreationResponseWithLimit(response, webauthn.config.responseBodyLimit())
+
+	observer.trace.Record(protocol.VerificationStepParse, err, nil)
+
+	if err != nil {
+		_, err = observer.finish(nil, err)
+
+		return nil, nil, err
+	}
+
+	return webauthn.createCredentialDeferred(ctx, observer, user, session, parsedResponse)
+}
+
+// CreateCredentialDeferred is the same as CreateCredential except the attestation verification is deferred the same as
+// FinishRegistrationDeferred.
+func (webauthn *WebAuthn) CreateCredentialDeferred(user User, session SessionData, parsedResponse *protocol.ParsedCredentialCreationData) (*Credential, *DeferredAttestation, error) {
+	return webauthn.CreateCredentialDeferredCtx(context.Background(), user, session, parsedResponse)
+}
+
+// CreateCredentialDeferredCtx is the same as CreateCredentialDeferred except it accepts a context.Context.
+func (webauthn *WebAuthn) CreateCredentialDeferredCtx(ctx context.Context, user User, session SessionData, parsedResponse *protocol.ParsedCredentialCreationData) (*Credential, *DeferredAttestation, error) {
+	ctx, observer := webauthn.startCeremony(ctx, CeremonyFinishRegistrationDeferred, user.WebAuthnID())
+
+	observer.recordParsed(session, parsedResponse)
+
+	return webauthn.createCredentialDeferred(ctx, observer, user, session, parsedResponse)
+}
+
+func (webauthn *WebAuthn) createCredentialDeferred(ctx context.Context, observer *ceremonyObserver, user User, session SessionData, parsedResponse *protocol.ParsedCredentialCreationData) (*Credential, *DeferredAttestation, error) {
+	credential, err := observer.finish(webauthn.createCredential(ctx, observer.trace, user, session, parsedResponse, true))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return credential, &DeferredAttestation{
+		webauthn:       webauthn,
+		userID:         user.WebAuthnID(),
+		credential:     credential,
+		parsedResponse: parsedResponse,
+		highAssurance:  session.HighAssurance,
+	}, nil
+}
+
+// Credential returns the Credential the attestation belongs to.
+func (d *DeferredAttestation) Credential() *Credential {
+	return d.credential
+}
+
+// Verify the attestation statement and the metadata of the registration. It's safe to call Verify from any goroutine
+// and more than once, the verification is only performed on the first call and every call returns the same result.
+func (d *DeferredAttestation) Verify(ctx context.Context) error {
+	d.once.Do(func() {
+		ctx, observer := d.webauthn.startCeremony(ctx, CeremonyVerifyAttestation, d.userID)
+
+		if err := ctx.Err(); err != nil {
+			_, d.err = observer.finish(d.credential, err)
+
+			return
+		}
+
+		_, d.err = observer.finish(d.credential, d.parsedResponse.VerifyAttestationWithPolicy(observer.trace, d.webauthn.config.registrationPolicy(ctx, d.highAssurance)))
+	})
+
+	return d.err
+}
@@ -0,0 +1,109 @@
+package webauthn
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+func TestWebAuthn_CreateCredentialDeferred(t *testing.T) {
+	webauthn, err := New(&Config{
+		RPDisplayName: "Example",
+		RPID:          "example.com",
+		RPOrigins:     []string{"https://example.com"},
+	})
+	require.NoError(t, err)
+
+	var failed []Event
+
+	webauthn.Subscribe(EventRegistrationFailed, func(event Event) {
+		failed = append(failed, event)
+	})
+
+	credential, deferred, err := webauthn.CreateCredentialDeferred(&defaultUser{id: []byte("123")}, SessionData{UserID: []byte("ABC")}, &protocol.ParsedCredentialCreationData{})
+
+	assert.Nil(t, credential)
+	assert.Nil(t, deferred)
+	assert.Equal(t, protocol.CodeUserSessionMismatch, err.(*protocol.Error).Code)
+
+	require.Len(t, failed, 1)
+	assert.Equal(t, []byte("123"), failed[0].UserID)
+}
+
+func TestDeferredAttestation_Verify(t *testing.T) {
+	testCases := []struct {
+		name      string
+		statement map[string]interface{}
+		err       bool
+	}{
+		{"ShouldVerifyNoneAttestation", nil, false},
+		{"ShouldFailNoneAttestationWithStatement", map[string]interface{}{"sig": []byte{1}}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				records []AuditRecord
+				events  []Event
+			)
+
+			webauthn, err := New(&Config{
+				RPDisplayName: "Example",
+				RPID:          "example.com",
+				RPOrigins:     []string{"https://example.com"},
+				AuditHook: func(record AuditRecord) {
+					records = append(records, record)
+				},
+			})
+			require.NoError(t, err)
+
+			webauthn.Subscribe(EventAttestationVerified, func(event Event) {
+				events = append(events, event)
+			})
+
+			webauthn.Subscribe(EventRegistrationFailed, func(event Event) {
+				events = append(events, event)
+			})
+
+			credential := &Credential{ID: []byte("id")}
+
+			deferred := &DeferredAttestation{
+				webauthn:   webauthn,
+				userID:     []byte("123"),
+				credential: credential,
+				parsedResponse: &protocol.ParsedCredentialCreationData{
+					Response: protocol.ParsedAttestationResponse{
+						AttestationObject: protocol.AttestationObject{Format: "none", AttStatement: tc.statement},
+					},
+				},
+			}
+
+			assert.Equal(t, credential, deferred.Credential())
+
+			err = deferred.Verify(context.Background())
+
+			assert.Equal(t, err, deferred.Verify(context.Background()))
+
+			require.Len(t, records, 1)
+			assert.Equal(t, CeremonyVerifyAttestation, records[0].Ceremony)
+
+			require.Len(t, events, 1)
+			assert.Equal(t, []byte("123"), events[0].UserID)
+
+			if tc.err {
+				assert.Error(t, err)
+				assert.False(t, records[0].Success)
+				assert.Equal(t, EventRegistrationFailed, events[0].Type)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, records[0].Success)
+				assert.Equal(t, EventAttestationVerified, events[0].Type)
+				assert.Equal(t, credential, events[0].Credential)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package webauthn
+
+import (
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// CredentialDescriptors converts the credentials into protocol.CredentialDescriptor's for use with
+// WithAllowedCredentials and WithExclusions.
+func CredentialDescriptors(credentials []Credential) (descriptors []protocol.CredentialDescriptor) {
+	descriptors = make([]protocol.CredentialDescriptor, len(credentials))
+
+	for i, credential := range credentials {
+		descriptors[i] = credential.Descriptor()
+	}
+
+	return descriptors
+}
+
+// CredentialFilter reports whether a Credential should be included by FilterCredentials.
+type CredentialFilter func(credential Credential) bool
+
+// FilterCredentials returns the credentials which are included by all of the filters, in their original order.
+func FilterCredentials(credentials []Credential, filters ...CredentialFilter) (filtered []Credential) {
+	filtered = make([]Credential, 0, len(credentials))
+
+credentials:
+	for _, credential := range credentials {
+		for _, filter := range filters {
+			if !filter(credential) {
+				continue credentials
+			}
+		}
+
+		filtered = append(filtered, credential)
+	}
+
+	return filtered
+}
+
+// CredentialHasTransport returns a CredentialFilter which includes the credentials which support any of the
+// transports. Credentials whose transports are unknown are only included if includeUnknown is true, as the client may
+// still be able to use them.
+func CredentialHasTransport(includeUnknown bool, transports ...protocol.AuthenticatorTransport) CredentialFilter {
+	return func(credential Credential) bool {
+		if len(credential.Transport) == 0 {
+			return includeUnknown
+		}
+
+		for _, transport := range credential.Transport {
+			for _, t := range transports {
+				if transport == t {
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+}
+
+// CredentialHasAttachment returns a CredentialFilter which includes the credentials registered with an authenticator
+// of any of the attachments. Credentials whose attachment is unknown are only included if includeUnknown is true.
+func CredentialHasAttachment(includeUnknown bool, attachments ...protocol.AuthenticatorAttachment) CredentialFilter {
+	return func(credential Credential) bool {
+		if credential.Authenticator.Attachment == "" {
+			return includeUnknown
+		}
+
+		for _, attachment := range attachments {
+			if credential.Authenticator.Attachment == attachment {
+				return true
+			}
+		}
+
+		return false
+	}
+}
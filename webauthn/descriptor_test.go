@@ -0,0 +1,57 @@
+package webauthn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+func TestCredentialDescriptors(t *testing.T) {
+	credentials := []Credential{
+		{ID: []byte("usb"), AttestationType: "packed", Transport: []protocol.AuthenticatorTransport{protocol.USB, protocol.NFC}},
+		{ID: []byte("unknown")},
+	}
+
+	assert.Equal(t, []protocol.CredentialDescriptor{
+		{Type: protocol.PublicKeyCredentialType, CredentialID: []byte("usb"), AttestationType: "packed", Transport: []protocol.AuthenticatorTransport{protocol.USB, protocol.NFC}},
+		{Type: protocol.PublicKeyCredentialType, CredentialID: []byte("unknown")},
+	}, CredentialDescriptors(credentials))
+
+	assert.Empty(t, CredentialDescriptors(nil))
+}
+
+func TestFilterCredentials(t *testing.T) {
+	credentials := []Credential{
+		{ID: []byte("usb"), Transport: []protocol.AuthenticatorTransport{protocol.USB, protocol.NFC}, Authenticator: Authenticator{Attachment: protocol.CrossPlatform}},
+		{ID: []byte("internal"), Transport: []protocol.AuthenticatorTransport{protocol.Internal, protocol.Hybrid}, Authenticator: Authenticator{Attachment: protocol.Platform}},
+		{ID: []byte("unknown")},
+	}
+
+	testCases := []struct {
+		name     string
+		filters  []CredentialFilter
+		expected []string
+	}{
+		{"ShouldIncludeAllWithoutFilters", nil, []string{"usb", "internal", "unknown"}},
+		{"ShouldFilterTransport", []CredentialFilter{CredentialHasTransport(false, protocol.NFC)}, []string{"usb"}},
+		{"ShouldFilterTransportIncludeUnknown", []CredentialFilter{CredentialHasTransport(true, protocol.Hybrid)}, []string{"internal", "unknown"}},
+		{"ShouldFilterAttachment", []CredentialFilter{CredentialHasAttachment(false, protocol.Platform)}, []string{"internal"}},
+		{"ShouldFilterAttachmentIncludeUnknown", []CredentialFilter{CredentialHasAttachment(true, protocol.CrossPlatform)}, []string{"usb", "unknown"}},
+		{"ShouldRequireAllFilters", []CredentialFilter{CredentialHasTransport(true, protocol.USB), CredentialHasAttachment(false, protocol.CrossPlatform)}, []string{"usb"}},
+		{"ShouldIncludeNone", []CredentialFilter{CredentialHasTransport(false, protocol.BLE)}, []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := []string{}
+
+			for _, credential := range FilterCredentials(credentials, tc.filters...) {
+				actual = append(actual, string(credential.ID))
+			}
+
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package webauthn
+
+import (
+	"net/http"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// VerificationReport is the result of a diagnostic verification of a finish ceremony.
+type VerificationReport struct {
+	// Ceremony is the ceremony step which was diagnosed.
+	Ceremony Ceremony `json:"ceremony"`
+
+	// Passed is true if every step passed.
+	Passed bool `json:"passed"`
+
+	// Steps are the individual verification steps which were performed in order. Unlike the regular finish ceremonies
+	// verification continues after a failed step, and only the steps which depend on the result of a failed step are
+	// not performed.
+	Steps []protocol.VerificationStep `json:"steps"`
+}
+
+// DiagnoseRegistration performs a dry-run of FinishRegistration which does not stop at the first failure, returning a
+// report of every verification step. It's intended for debugging interoperability issues with a specific browser and
+// authenticator combination and must not be used to register credentials.
+func (webauthn *WebAuthn) DiagnoseRegistration(user User, session SessionData, response *http.Request) *VerificationReport {
+	trace := &protocol.VerificationTrace{Diagnostic: true}
+
+	webauthn.config.compatibleRequest(response)
+
+	parsedResponse, err := protocol.ParseCredentialCreationResponseWithLimit(response, webauthn.config.responseBodyLimit())
+
+	trace.Record(protocol.VerificationStepParse, err, nil)
+
+	if err == nil {
+		_, _ = webauthn.createCredential(requestContext(response), trace, user, session, parsedResponse, false)
+	}
+
+	return newVerificationReport(CeremonyFinishRegistration, trace)
+}
+
+// DiagnoseLogin performs a dry-run of FinishLogin which does not stop at the first failure, returning a report of every
+// verification step. It's intended for debugging interoperability issues with a specific browser and authenticator
+// combination and must not be used to authenticate users.
+func (webauthn *WebAuthn) DiagnoseLogin(user User, session SessionData, response *http.Request) *VerificationReport {
+	trace := &protocol.VerificationTrace{Diagnostic: true}
+
+	webauthn.config.compatibleRequest(response)
+
+	parsedResponse, err := protocol.ParseCredentialRequestResponseWithLimit(response, webauthn.config.responseBodyLimit())
+
+	trace.Record(protocol.VerificationStepParse, err, nil)
+
+	if err == nil {
+		_, _ = webauthn.validateUserLogin(requestContext(response), trace, user, session, parsedResponse)
+	}
+
+	return newVerificationReport(CeremonyFinishLogin, trace)
+}
+
+// DiagnoseDiscoverableLogin performs a dry-run of FinishDiscoverableLogin which does not stop at the first failure,
+// returning a report of every verification step. It's intended for debugging interoperability issues with a specific
+// browser and authenticator combination and must not be used to authenticate users.
+func (webauthn *WebAuthn) DiagnoseDiscoverableLogin(handler DiscoverableUserHandler, session SessionData, response *http.Request) *VerificationReport {
+	trace := &protocol.VerificationTrace{Diagnostic: true}
+
+	webauthn.config.compatibleRequest(response)
+
+	parsedResponse, err := protocol.ParseCredentialRequestResponseWithLimit(response, webauthn.config.responseBodyLimit())
+
+	trace.Record(protocol.VerificationStepParse, err, nil)
+
+	if err == nil {
+		_, _ = webauthn.validateDiscoverableLogin(requestContext(response), trace, handler, session, parsedResponse)
+	}
+
+	return newVerificationReport(CeremonyFinishDiscoverableLogin, trace)
+}
+
+func newVerificationReport(ceremony Ceremony, trace *protocol.VerificationTrace) *VerificationReport {
+	return &VerificationReport{
+		Ceremony: ceremony,
+		Passed:   trace.Passed(),
+		Steps:    trace.Steps,
+	}
+}
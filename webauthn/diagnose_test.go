@@ -0,0 +1,29 @@
+package webauthn
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+func TestWebAuthn_DiagnoseLogin(t *testing.T) {
+	webauthn, err := New(&Config{
+		RPDisplayName: "Example",
+		RPID:          "example.com",
+		RPOrigins:     []string{"https://example.com"},
+	})
+	require.NoError(t, err)
+
+	report := webauthn.DiagnoseLogin(&defaultUser{id: []byte("123")}, SessionData{}, &http.Request{})
+
+	assert.Equal(t, CeremonyFinishLogin, report.Ceremony)
+	assert.False(t, report.Passed)
+
+	require.Len(t, report.Steps, 1)
+	assert.Equal(t, protocol.VerificationStepParse, report.Steps[0].Name)
+	assert.False(t, report.Steps[0].Passed)
+}
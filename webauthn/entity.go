@@ -0,0 +1,69 @@
+package webauthn
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+const (
+	// UserIDMaxLength is the maximum length in bytes of the user handle of the user entity.
+	//
+	// Specification: §5.4.3. User Account Parameters for Credential Generation (https://www.w3.org/TR/webauthn/#dom-publickeycredentialuserentity-id)
+	UserIDMaxLength = 64
+
+	// UserNameMaxLength is the length in bytes the name and display name of the user entity are truncated to, which is
+	// the minimum length authenticators store before truncating them themselves.
+	//
+	// Specification: §6.4.1. String Truncation (https://www.w3.org/TR/webauthn/#sctn-strings-truncation)
+	UserNameMaxLength = 64
+)
+
+// newUserEntity returns the name and display name of the user entity of the user, after ensuring the user handle is
+// between 1 and UserIDMaxLength bytes. The control characters are stripped from the names, which must not be empty,
+// and they are truncated to UserNameMaxLength bytes without splitting a character.
+func newUserEntity(user User) (name, displayName string, err error) {
+	if n := len(user.WebAuthnID()); n == 0 || n > UserIDMaxLength {
+		return "", "", protocol.ErrBadRequest.
+			WithCode(protocol.CodeUserInvalid).
+			WithDetails(fmt.Sprintf("User ID must be between 1 and %d bytes", UserIDMaxLength)).
+			WithInfo(fmt.Sprintf("Length: %d", n))
+	}
+
+	if name = userEntityName(user.WebAuthnName()); name == "" {
+		return "", "", protocol.ErrBadRequest.WithCode(protocol.CodeUserInvalid).WithDetails("User name must not be empty")
+	}
+
+	if displayName = userEntityName(user.WebAuthnDisplayName()); displayName == "" {
+		return "", "", protocol.ErrBadRequest.WithCode(protocol.CodeUserInvalid).WithDetails("User display name must not be empty")
+	}
+
+	return name, displayName, nil
+}
+
+// userEntityName strips the control characters and surrounding white space from the name and truncates it to
+// UserNameMaxLength bytes.
+func userEntityName(name string) string {
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+
+		return r
+	}, name))
+
+	if len(name) <= UserNameMaxLength {
+		return name
+	}
+
+	n := UserNameMaxLength
+
+	for n > 0 && !utf8.RuneStart(name[n]) {
+		n--
+	}
+
+	return strings.TrimSpace(name[:n])
+}
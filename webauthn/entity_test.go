@@ -0,0 +1,75 @@
+package webauthn_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+type entityUser struct {
+	bytesUser
+
+	id                []byte
+	name, displayName string
+}
+
+func (u *entityUser) WebAuthnID() []byte {
+	return u.id
+}
+
+func (u *entityUser) WebAuthnName() string {
+	return u.name
+}
+
+func (u *entityUser) WebAuthnDisplayName() string {
+	return u.displayName
+}
+
+func TestWebAuthn_BeginRegistrationUserEntity(t *testing.T) {
+	testCases := []struct {
+		name                string
+		user                *entityUser
+		expectedName        string
+		expectedDisplayName string
+		expected            string
+	}{
+		{"ShouldAllowValidUser", &entityUser{id: []byte("1234"), name: "john", displayName: "John"}, "john", "John", ""},
+		{"ShouldAllowMaximumUserID", &entityUser{id: bytes.Repeat([]byte{1}, 64), name: "john", displayName: "John"}, "john", "John", ""},
+		{"ShouldRejectEmptyUserID", &entityUser{name: "john", displayName: "John"}, "", "", "User ID must be between 1 and 64 bytes"},
+		{"ShouldRejectLongUserID", &entityUser{id: bytes.Repeat([]byte{1}, 65), name: "john", displayName: "John"}, "", "", "User ID must be between 1 and 64 bytes"},
+		{"ShouldRejectEmptyName", &entityUser{id: []byte("1234"), name: " \t", displayName: "John"}, "", "", "User name must not be empty"},
+		{"ShouldRejectEmptyDisplayName", &entityUser{id: []byte("1234"), name: "john", displayName: "\x00\x1b"}, "", "", "User display name must not be empty"},
+		{"ShouldStripControlCharacters", &entityUser{id: []byte("1234"), name: "jo\x00hn\n", displayName: "\x1b[31mJohn"}, "john", "[31mJohn", ""},
+		{"ShouldTruncateLongNames", &entityUser{id: []byte("1234"), name: strings.Repeat("a", 70), displayName: strings.Repeat("a", 63) + "é"}, strings.Repeat("a", 64), strings.Repeat("a", 63), ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := webauthn.New(&webauthn.Config{
+				RPID:          "example.com",
+				RPDisplayName: "Example",
+				RPOrigins:     []string{"https://example.com"},
+			})
+			require.NoError(t, err)
+
+			creation, _, err := w.BeginRegistration(tc.user)
+
+			if tc.expected != "" {
+				assertErrorCode(t, protocol.CodeUserInvalid, err)
+				assert.EqualError(t, err, tc.expected)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedName, creation.Response.User.Name)
+			assert.Equal(t, tc.expectedDisplayName, creation.Response.User.DisplayName)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package webauthn
+
+import (
+	"errors"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// handleError passes the error to the configured ErrorHandler and redacts it if RedactErrors is enabled. It should be
+// called exactly once for every error returned by an exported ceremony method.
+func (webauthn *WebAuthn) handleError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if webauthn.config == nil {
+		return err
+	}
+
+	if webauthn.config.ErrorHandler != nil {
+		webauthn.config.ErrorHandler(err)
+	}
+
+	if !webauthn.config.RedactErrors {
+		return err
+	}
+
+	var e *protocol.Error
+
+	if errors.As(err, &e) {
+		return e.Redacted()
+	}
+
+	return err
+}
@@ -0,0 +1,48 @@
+package webauthn
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+func TestWebAuthn_RedactErrors(t *testing.T) {
+	testCases := []struct {
+		name            string
+		redact          bool
+		expectedDetails string
+	}{
+		{"ShouldNotRedact", false, "ID mismatch for User and Session"},
+		{"ShouldRedact", true, "Error reading the request data"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var handled error
+
+			webauthn := &WebAuthn{
+				config: &Config{
+					RedactErrors: tc.redact,
+					ErrorHandler: func(err error) {
+						handled = err
+					},
+				},
+			}
+
+			_, err := webauthn.ValidateLogin(&defaultUser{id: []byte("123")}, SessionData{UserID: []byte("ABC")}, nil)
+
+			var e *protocol.Error
+
+			require.True(t, errors.As(err, &e))
+			assert.Equal(t, "invalid_request", e.Type)
+			assert.Equal(t, tc.expectedDetails, e.Details)
+
+			require.True(t, errors.As(handled, &e))
+			assert.Equal(t, "ID mismatch for User and Session", e.Details)
+		})
+	}
+}
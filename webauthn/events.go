@@ -0,0 +1,111 @@
+package webauthn
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType represents the type of an Event emitted during the registration and login lifecycle.
+type EventType string
+
+const (
+	// EventRegistrationStarted is emitted when BeginRegistration succeeds.
+	EventRegistrationStarted EventType = "registration_started"
+
+	// EventAttestationVerified is emitted when a registration has been verified and the new credential created.
+	EventAttestationVerified EventType = "attestation_verified"
+
+	// EventRegistrationFailed is emitted when a registration fails verification.
+	EventRegistrationFailed EventType = "registration_failed"
+
+	// EventLoginStarted is emitted when BeginLogin or BeginDiscoverableLogin succeeds.
+	EventLoginStarted EventType = "login_started"
+
+	// EventLoginSucceeded is emitted when a login has been verified.
+	EventLoginSucceeded EventType = "login_succeeded"
+
+	// EventLoginFailed is emitted when a login fails verification.
+	EventLoginFailed EventType = "login_failed"
+
+	// EventCounterRegressed is emitted when the signature counter returned by the authenticator is not greater than
+	// the stored signature counter, indicating the authenticator may have been cloned.
+	EventCounterRegressed EventType = "counter_regressed"
+)
+
+// Event describes something which occurred during the registration and login lifecycle.
+type Event struct {
+	// Type is the type of the event.
+	Type EventType
+
+	// Time is the time the event occurred.
+	Time time.Time
+
+	// UserID is the WebAuthnID of the user if known.
+	UserID []byte
+
+	// Credential is the credential the event relates to if known.
+	Credential *Credential
+
+	// Err is the error which caused the event if any.
+	Err error
+}
+
+// EventHandler is a callback which receives events. Handlers are called synchronously in the order they were
+// subscribed, so they should not block.
+type EventHandler func(event Event)
+
+type eventSubscribers struct {
+	mu       sync.RWMutex
+	handlers map[EventType][]EventHandler
+}
+
+// Subscribe registers the EventHandler to be called every time an Event of the provided EventType is emitted. This
+// is useful for triggering notifications such as informing a user a new passkey was added to their account without
+// wrapping every call site.
+func (webauthn *WebAuthn) Subscribe(eventType EventType, handler EventHandler) {
+	webauthn.subscribers.mu.Lock()
+	defer webauthn.subscribers.mu.Unlock()
+
+	if webauthn.subscribers.handlers == nil {
+		webauthn.subscribers.handlers = map[EventType][]EventHandler{}
+	}
+
+	webauthn.subscribers.handlers[eventType] = append(webauthn.subscribers.handlers[eventType], handler)
+}
+
+func (webauthn *WebAuthn) emit(event Event) {
+	webauthn.subscribers.mu.RLock()
+	handlers := webauthn.subscribers.handlers[event.Type]
+	webauthn.subscribers.mu.RUnlock()
+
+	if len(handlers) == 0 {
+		return
+	}
+
+	if event.Time.IsZero() {
+		event.Time = webauthn.config.now()
+	}
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+// ceremonyEvents returns the EventType to emit when the Ceremony succeeds and fails respectively. An empty EventType
+// indicates no event should be emitted.
+func ceremonyEvents(ceremony Ceremony) (success, failure EventType) {
+	switch ceremony {
+	case CeremonyBeginRegistration:
+		return EventRegistrationStarted, ""
+	case CeremonyFinishRegistration, CeremonyVerifyAttestation:
+		return EventAttestationVerified, EventRegistrationFailed
+	case CeremonyFinishRegistrationDeferred:
+		return "", EventRegistrationFailed
+	case CeremonyBeginLogin, CeremonyBeginDiscoverableLogin:
+		return EventLoginStarted, ""
+	case CeremonyFinishLogin, CeremonyFinishDiscoverableLogin:
+		return EventLoginSucceeded, EventLoginFailed
+	default:
+		return "", ""
+	}
+}
@@ -0,0 +1,75 @@
+package webauthn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+func TestWebAuthn_Subscribe(t *testing.T) {
+	webauthn, err := New(&Config{
+		RPDisplayName: "Example",
+		RPID:          "example.com",
+		RPOrigins:     []string{"https://example.com"},
+	})
+	require.NoError(t, err)
+
+	var (
+		started []Event
+		failed  []Event
+	)
+
+	webauthn.Subscribe(EventRegistrationStarted, func(event Event) {
+		started = append(started, event)
+	})
+
+	webauthn.Subscribe(EventLoginFailed, func(event Event) {
+		failed = append(failed, event)
+	})
+
+	user := &defaultUser{id: []byte("123")}
+
+	_, _, err = webauthn.BeginRegistration(user)
+	require.NoError(t, err)
+
+	_, err = webauthn.ValidateLogin(user, SessionData{UserID: []byte("ABC")}, nil)
+	require.Error(t, err)
+
+	require.Len(t, started, 1)
+	assert.Equal(t, EventRegistrationStarted, started[0].Type)
+	assert.Equal(t, []byte("123"), started[0].UserID)
+	assert.False(t, started[0].Time.IsZero())
+	assert.NoError(t, started[0].Err)
+
+	require.Len(t, failed, 1)
+	assert.Equal(t, EventLoginFailed, failed[0].Type)
+	assert.Equal(t, []byte("123"), failed[0].UserID)
+	assert.Nil(t, failed[0].Credential)
+	assert.Equal(t, protocol.CodeUserSessionMismatch, failed[0].Err.(*protocol.Error).Code)
+}
+
+func TestAuthenticator_counterRegressed(t *testing.T) {
+	testCases := []struct {
+		name      string
+		stored    uint32
+		received  uint32
+		regressed bool
+	}{
+		{"ShouldNotRegressIncrease", 1, 2, false},
+		{"ShouldNotRegressZero", 0, 0, false},
+		{"ShouldRegressEqual", 5, 5, true},
+		{"ShouldRegressDecrease", 5, 1, true},
+		{"ShouldRegressReset", 5, 0, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &Authenticator{SignCount: tc.stored}
+
+			assert.Equal(t, tc.regressed, a.counterRegressed(tc.received))
+		})
+	}
+}
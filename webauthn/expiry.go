@@ -0,0 +1,64 @@
+package webauthn
+
+import (
+	"crypto/x509"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// AttestationCertificateExpiry describes a certificate of the attestation certificate chain of a Credential which
+// expires within the window provided to ExpiringAttestationCertificates.
+type AttestationCertificateExpiry struct {
+	// CredentialID is the ID of the Credential the certificate belongs to.
+	CredentialID []byte
+
+	// Index is the position of the certificate in the AttestationCertificates of the Credential, where 0 is the
+	// attestation certificate itself.
+	Index int
+
+	// Certificate is the parsed certificate.
+	Certificate *x509.Certificate
+
+	// Expired is true if the certificate had already expired at the time of the scan.
+	Expired bool
+}
+
+// ExpiringAttestationCertificates scans the AttestationCertificates of the credentials and returns the certificates
+// which have expired or expire within the window from now, ordered by their expiry. This is useful for enterprises
+// managing fleets of devices to plan the re-enrollment of credentials whose attestation will no longer verify. Every
+// credential is scanned even if a certificate can not be parsed, in which case the first error is returned along with
+// the results.
+func ExpiringAttestationCertificates(credentials []Credential, now time.Time, window time.Duration) (expiring []AttestationCertificateExpiry, err error) {
+	deadline := now.Add(window)
+
+	for _, credential := range credentials {
+		for i, raw := range credential.AttestationCertificates {
+			cert, parseErr := x509.ParseCertificate(raw)
+			if parseErr != nil {
+				if err == nil {
+					err = fmt.Errorf("error parsing attestation certificate %d of credential %x: %w", i, credential.ID, parseErr)
+				}
+
+				continue
+			}
+
+			if cert.NotAfter.After(deadline) {
+				continue
+			}
+
+			expiring = append(expiring, AttestationCertificateExpiry{
+				CredentialID: credential.ID,
+				Index:        i,
+				Certificate:  cert,
+				Expired:      cert.NotAfter.Before(now),
+			})
+		}
+	}
+
+	sort.SliceStable(expiring, func(i, j int) bool {
+		return expiring[i].Certificate.NotAfter.Before(expiring[j].Certificate.NotAfter)
+	})
+
+	return expiring, err
+}
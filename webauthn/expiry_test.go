@@ -0,0 +1,116 @@
+package webauthn_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+func TestExpiringAttestationCertificates(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	expired := newExpiryTestCertificate(t, "expired", now.Add(-time.Hour))
+	soon := newExpiryTestCertificate(t, "soon", now.Add(time.Hour*24*10))
+	later := newExpiryTestCertificate(t, "later", now.Add(time.Hour*24*20))
+	distant := newExpiryTestCertificate(t, "distant", now.Add(time.Hour*24*365))
+
+	credentials := []webauthn.Credential{
+		{ID: []byte("a"), AttestationCertificates: [][]byte{later, distant}},
+		{ID: []byte("b"), AttestationCertificates: [][]byte{distant, soon}},
+		{ID: []byte("c"), AttestationCertificates: [][]byte{expired}},
+		{ID: []byte("d")},
+	}
+
+	expiring, err := webauthn.ExpiringAttestationCertificates(credentials, now, time.Hour*24*30)
+	require.NoError(t, err)
+	require.Len(t, expiring, 3)
+
+	assert.Equal(t, []byte("c"), expiring[0].CredentialID)
+	assert.Equal(t, "expired", expiring[0].Certificate.Subject.CommonName)
+	assert.True(t, expiring[0].Expired)
+
+	assert.Equal(t, []byte("b"), expiring[1].CredentialID)
+	assert.Equal(t, 1, expiring[1].Index)
+	assert.Equal(t, "soon", expiring[1].Certificate.Subject.CommonName)
+	assert.False(t, expiring[1].Expired)
+
+	assert.Equal(t, []byte("a"), expiring[2].CredentialID)
+	assert.Equal(t, 0, expiring[2].Index)
+	assert.Equal(t, "later", expiring[2].Certificate.Subject.CommonName)
+
+	expiring, err = webauthn.ExpiringAttestationCertificates(append(credentials, webauthn.Credential{ID: []byte{0xff}, AttestationCertificates: [][]byte{[]byte("invalid")}}), now, time.Hour*24*30)
+
+	assert.EqualError(t, err, "error parsing attestation certificate 0 of credential ff: x509: malformed certificate")
+	assert.Len(t, expiring, 3)
+}
+
+func TestMakeNewCredential_AttestationCertificates(t *testing.T) {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+	})
+	require.NoError(t, err)
+
+	for _, format := range []string{webauthntest.FormatNone, webauthntest.FormatU2F} {
+		t.Run(format, func(t *testing.T) {
+			user := &bytesUser{}
+
+			creation, session, err := w.BeginRegistration(user)
+			require.NoError(t, err)
+
+			attestation, _, err := (&webauthntest.Authenticator{Format: format}).CreateCredential(creation.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err := webauthntest.NewRequest(attestation)
+			require.NoError(t, err)
+
+			credential, err := w.FinishRegistration(user, *session, r)
+			require.NoError(t, err)
+
+			if format == webauthntest.FormatNone {
+				assert.Empty(t, credential.AttestationCertificates)
+
+				return
+			}
+
+			require.Len(t, credential.AttestationCertificates, 1)
+
+			expiring, err := webauthn.ExpiringAttestationCertificates([]webauthn.Credential{*credential}, time.Now(), time.Hour*24*400)
+			require.NoError(t, err)
+			require.Len(t, expiring, 1)
+
+			assert.Equal(t, "webauthntest U2F Attestation", expiring[0].Certificate.Subject.CommonName)
+		})
+	}
+}
+
+func newExpiryTestCertificate(t *testing.T, name string, notAfter time.Time) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    notAfter.Add(-time.Hour * 24 * 365 * 5),
+		NotAfter:     notAfter,
+	}
+
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return cert
+}
@@ -0,0 +1,49 @@
+package webauthn
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+)
+
+// fips returns true if only the FIPS approved algorithms are accepted, either because the Config enables FIPS or the
+// webauthn_fips build tag was used.
+func (config *Config) fips() bool {
+	return config.FIPS || webauthncose.FIPSMode
+}
+
+// fipsCredentialParameters returns the credential parameters which use a FIPS approved algorithm.
+func fipsCredentialParameters(parameters []protocol.CredentialParameter) []protocol.CredentialParameter {
+	approved := make([]protocol.CredentialParameter, 0, len(parameters))
+
+	for _, parameter := range parameters {
+		if webauthncose.IsFIPSApproved(parameter.Algorithm) {
+			approved = append(approved, parameter)
+		}
+	}
+
+	return approved
+}
+
+// verifyAlgorithm ensures the algorithm of the credential public key is FIPS approved when FIPS is enabled. Public
+// keys which are not COSE encoded are FIDO U2F public keys which always use ES256.
+func (webauthn *WebAuthn) verifyAlgorithm(trace *protocol.VerificationTrace, publicKey []byte) error {
+	if !webauthn.config.fips() {
+		return nil
+	}
+
+	var err error
+
+	alg := credentialAlgorithm(publicKey)
+
+	if alg != 0 && !webauthncose.IsFIPSApproved(alg) {
+		err = protocol.ErrUnsupportedAlgorithm.
+			WithCode(protocol.CodeAlgorithmNotAllowed).
+			WithDetails("Credential public key algorithm is not FIPS approved").
+			WithInfo(fmt.Sprintf("Algorithm: %d", alg))
+	}
+
+	return trace.Step(protocol.VerificationStepAlgorithm, err, map[string]string{"algorithm": strconv.Itoa(int(alg))})
+}
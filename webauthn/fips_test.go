@@ -0,0 +1,107 @@
+package webauthn_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+func TestWebAuthn_FIPS(t *testing.T) {
+	config := &webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+	}
+
+	w, err := webauthn.New(config)
+	require.NoError(t, err)
+
+	config.FIPS = true
+
+	fips, err := webauthn.New(config)
+	require.NoError(t, err)
+
+	user := &bytesUser{}
+
+	t.Run("ShouldOnlyOfferApprovedAlgorithms", func(t *testing.T) {
+		creation, _, err := fips.BeginRegistration(user)
+		require.NoError(t, err)
+		require.NotEmpty(t, creation.Response.Parameters)
+
+		for _, parameter := range creation.Response.Parameters {
+			assert.True(t, webauthncose.IsFIPSApproved(parameter.Algorithm), parameter.Algorithm)
+		}
+	})
+
+	t.Run("ShouldFailWithoutApprovedAlgorithms", func(t *testing.T) {
+		_, _, err := fips.BeginRegistration(user, webauthn.WithCredentialParameters([]protocol.CredentialParameter{
+			{Type: protocol.PublicKeyCredentialType, Algorithm: webauthncose.AlgEdDSA},
+		}))
+
+		require.IsType(t, &protocol.Error{}, err)
+		assert.Equal(t, protocol.CodeAlgorithmNotAllowed, err.(*protocol.Error).Code)
+	})
+
+	t.Run("ShouldRejectTPMRS1", func(t *testing.T) {
+		_, err := webauthn.New(&webauthn.Config{
+			RPID:              "example.com",
+			RPDisplayName:     "Example",
+			RPOrigins:         []string{"https://example.com"},
+			FIPS:              true,
+			AttestationPolicy: protocol.AttestationPolicy{AllowTPMRS1: true},
+		})
+		assert.EqualError(t, err, "error occurred validating the configuration: the field 'AttestationPolicy.AllowTPMRS1' must not be enabled in FIPS mode")
+	})
+
+	if webauthncose.FIPSMode {
+		t.Skip("credentials using an algorithm which is not approved can not be registered with the webauthn_fips build tag")
+	}
+
+	authenticator := &webauthntest.Authenticator{Algorithm: webauthncose.AlgEdDSA}
+
+	creation, session, err := w.BeginRegistration(user)
+	require.NoError(t, err)
+
+	attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+	require.NoError(t, err)
+
+	t.Run("ShouldRejectRegistration", func(t *testing.T) {
+		r, err := webauthntest.NewRequest(attestation)
+		require.NoError(t, err)
+
+		_, err = fips.FinishRegistration(user, *session, r)
+
+		require.IsType(t, &protocol.Error{}, err)
+		assert.Equal(t, protocol.CodeAlgorithmNotAllowed, err.(*protocol.Error).Code)
+	})
+
+	r, err := webauthntest.NewRequest(attestation)
+	require.NoError(t, err)
+
+	credential, err := w.FinishRegistration(user, *session, r)
+	require.NoError(t, err)
+
+	user.credentials = append(user.credentials, *credential)
+
+	t.Run("ShouldRejectLogin", func(t *testing.T) {
+		assertion, session, err := fips.BeginLogin(user)
+		require.NoError(t, err)
+
+		response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+		require.NoError(t, err)
+
+		r, err := webauthntest.NewRequest(response)
+		require.NoError(t, err)
+
+		_, err = fips.FinishLogin(user, *session, r)
+
+		require.IsType(t, &protocol.Error{}, err)
+		assert.Equal(t, protocol.CodeAlgorithmNotAllowed, err.(*protocol.Error).Code)
+	})
+}
@@ -0,0 +1,55 @@
+package webauthn
+
+import (
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// LoginAssurance are the assurance characteristics of a verified login, which session management layers can use
+// alongside the LoginResult VerifiedAt to decide if the authentication is fresh and strong enough for an action.
+type LoginAssurance struct {
+	// UserPresent is true if the authenticator reported the user was present.
+	UserPresent bool `json:"user_present"`
+
+	// UserVerified is true if the authenticator reported the user was verified.
+	UserVerified bool `json:"user_verified"`
+
+	// HardwareBacked is true if the credential is bound to the authenticator, i.e. it's not backup eligible.
+	HardwareBacked bool `json:"hardware_backed"`
+
+	// BackedUp is true if the credential is backed up by a passkey provider.
+	BackedUp bool `json:"backed_up"`
+
+	// Attachment is the authenticator attachment reported by the response, which is empty if it was not reported.
+	Attachment protocol.AuthenticatorAttachment `json:"attachment,omitempty"`
+
+	// AttestationType is the attestation type of the credential when it was registered.
+	AttestationType string `json:"attestation_type,omitempty"`
+
+	// StepUp is true if the login was a step-up re-authentication started with BeginStepUp.
+	StepUp bool `json:"step_up,omitempty"`
+}
+
+// loginAssurance returns the LoginAssurance of a login with the Credential after its flags are updated from the
+// verified response.
+func loginAssurance(session SessionData, credential Credential, parsedResponse *protocol.ParsedCredentialAssertionData) LoginAssurance {
+	return LoginAssurance{
+		UserPresent:     credential.Flags.UserPresent,
+		UserVerified:    credential.Flags.UserVerified,
+		HardwareBacked:  !credential.Flags.BackupEligible,
+		BackedUp:        credential.Flags.BackupState,
+		Attachment:      parsedResponse.AuthenticatorAttachment,
+		AttestationType: credential.AttestationType,
+		StepUp:          session.StepUp,
+	}
+}
+
+// userVerificationMethods returns the entries of the uvm extension of the verified response. A malformed extension is
+// treated as absent, as the extension is informational and the response is otherwise valid.
+func userVerificationMethods(parsedResponse *protocol.ParsedCredentialAssertionData) []protocol.UVMEntry {
+	entries, err := parsedResponse.Response.AuthenticatorData.UVM()
+	if err != nil {
+		return nil
+	}
+
+	return entries
+}
@@ -0,0 +1,68 @@
+package webauthn_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+func TestWebAuthn_LoginResultFreshness(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+		Clock:         func() time.Time { return now },
+	})
+	require.NoError(t, err)
+
+	user := &bytesUser{}
+	authenticator := &webauthntest.Authenticator{Attachment: protocol.Platform}
+
+	creation, session, err := w.BeginRegistration(user)
+	require.NoError(t, err)
+
+	attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+	require.NoError(t, err)
+
+	r, err := webauthntest.NewRequest(attestation)
+	require.NoError(t, err)
+
+	credential, err := w.FinishRegistration(user, *session, r)
+	require.NoError(t, err)
+
+	user.credentials = append(user.credentials, *credential)
+
+	assertion, session, err := w.BeginLogin(user)
+	require.NoError(t, err)
+
+	response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+	require.NoError(t, err)
+
+	r, err = webauthntest.NewRequest(response)
+	require.NoError(t, err)
+
+	result, err := w.FinishLoginResult(user, *session, r)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+
+	assert.Equal(t, now, result.VerifiedAt)
+	assert.Nil(t, result.UserVerificationMethods)
+	assert.Equal(t, webauthn.LoginAssurance{
+		UserPresent:     result.Credential.Flags.UserPresent,
+		UserVerified:    result.Credential.Flags.UserVerified,
+		HardwareBacked:  !result.Credential.Flags.BackupEligible,
+		BackedUp:        result.Credential.Flags.BackupState,
+		Attachment:      protocol.Platform,
+		AttestationType: credential.AttestationType,
+	}, result.Assurance)
+	assert.Equal(t, webauthn.ACRPhishingResistantHardware, result.AuthenticationContext().ACR)
+	assert.Equal(t, webauthn.SAMLPhishingResistantHardware, result.SAMLAuthnContextClassRef())
+}
@@ -0,0 +1,127 @@
+package webauthn
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-webauthn/webauthn/metadata"
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// RegistrationHook is a policy hook called during the registration ceremony with the user and the parsed response,
+// which is useful for injecting custom policy such as only permitting corporate devices. Returning an error fails the
+// registration. If the error is not a *protocol.Error it's converted to a protocol.ErrPolicy with the
+// protocol.CodePolicyRejected code and the error message as the details.
+type RegistrationHook func(ctx context.Context, user User, parsedResponse *protocol.ParsedCredentialCreationData) error
+
+// RegistrationHooks are the policy hooks called by FinishRegistration, CreateCredential, and the deferred variants.
+// The hooks are not called by DiagnoseRegistration.
+type RegistrationHooks struct {
+	// PreVerify is called after the session is verified but before the response is verified, so the parsed response
+	// must not be trusted.
+	PreVerify RegistrationHook
+
+	// PostVerify is called after the response is successfully verified and before the credential is returned. The
+	// attestation statement has not been verified yet when using the deferred variants.
+	PostVerify RegistrationHook
+}
+
+// LoginResult is the result of a successfully verified login which is provided to the LoginHooks PostVerify hook and
+// returned by FinishLoginResult.
+type LoginResult struct {
+	// User is the user who logged in.
+	User User
+
+	// Credential is the credential used to log in with the signature counter and flags updated from the response.
+	Credential *Credential
+
+	// ParsedResponse is the verified response.
+	ParsedResponse *protocol.ParsedCredentialAssertionData
+
+	// CounterAnomaly is the anomaly of the signature counter observed during the login.
+	CounterAnomaly CounterAnomaly
+
+	// Authenticator is the human friendly name and icons of the authenticator model of the Credential, looked up with
+	// AuthenticatorName. It's the zero value if the AAGUID of the authenticator is not known.
+	Authenticator metadata.AuthenticatorName
+
+	// VerifiedAt is the time the response was verified according to the Clock, which is the time of the
+	// authentication for the max-age re-authentication rules of the session.
+	VerifiedAt time.Time
+
+	// UserVerificationMethods are the methods the authenticator used to verify the user as reported by the uvm
+	// extension, which is nil if the authenticator did not report them.
+	UserVerificationMethods []protocol.UVMEntry
+
+	// Assurance are the effective assurance characteristics of the login.
+	Assurance LoginAssurance
+
+	// verified is true once the login has passed every verification step including the hooks, which distinguishes
+	// the results returned by the login methods from the ones constructed by the caller.
+	verified bool
+}
+
+// LoginPreVerifyHook is a policy hook called during the login ceremony with the user and the parsed response before
+// the response is verified. Returning an error fails the login in the same way as a RegistrationHook.
+type LoginPreVerifyHook func(ctx context.Context, user User, parsedResponse *protocol.ParsedCredentialAssertionData) error
+
+// LoginPostVerifyHook is a policy hook called during the login ceremony with the LoginResult after the response is
+// verified. Returning an error vetoes the login in the same way as a RegistrationHook, which can be a
+// protocol.ErrPolicy with a more specific code to distinguish the policy which rejected the login.
+type LoginPostVerifyHook func(ctx context.Context, result *LoginResult) error
+
+// LoginConnectionHook is a policy hook called during the login ceremony with the ConnectionInfo and the LoginResult
+// after the response is verified, which is useful for implementing device binding or anomaly rules as part of the
+// ceremony. Returning an error vetoes the login in the same way as a LoginPostVerifyHook.
+type LoginConnectionHook func(ctx context.Context, connection ConnectionInfo, result *LoginResult) error
+
+// LoginHooks are the policy hooks called by FinishLogin, FinishDiscoverableLogin, and the validate variants. The
+// hooks are not called by DiagnoseLogin or DiagnoseDiscoverableLogin.
+type LoginHooks struct {
+	// PreVerify is called after the credential of the user is found but before the response is verified, so the
+	// parsed response must not be trusted.
+	PreVerify LoginPreVerifyHook
+
+	// PostVerify is called after the response is successfully verified and before the credential is returned.
+	PostVerify LoginPostVerifyHook
+
+	// Connection is called after the response is successfully verified and before PostVerify with the ConnectionInfo
+	// of the connection the response was received over, which is the zero value if it's unknown such as when using
+	// ValidateLogin without ContextWithConnectionInfo.
+	Connection LoginConnectionHook
+}
+
+// runPolicyHook records the result of a policy hook in the *protocol.VerificationTrace as the provided step, converting
+// the error to a *protocol.Error if necessary.
+func runPolicyHook(trace *protocol.VerificationTrace, step string, hook func() error) error {
+	if trace.IsDiagnostic() {
+		return nil
+	}
+
+	return trace.Step(step, policyError(hook()), nil)
+}
+
+func policyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var e *protocol.Error
+
+	if errors.As(err, &e) {
+		return err
+	}
+
+	return protocol.ErrPolicy.WithCode(protocol.CodePolicyRejected).WithDetails(err.Error())
+}
+
+func (webauthn *WebAuthn) runRegistrationHook(ctx context.Context, trace *protocol.VerificationTrace, step string, hook RegistrationHook, user User, parsedResponse *protocol.ParsedCredentialCreationData) error {
+	if hook == nil {
+		return nil
+	}
+
+	return runPolicyHook(trace, step, func() error {
+		return hook(ctx, user, parsedResponse)
+	})
+}
@@ -0,0 +1,316 @@
+package webauthn_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+func TestWebAuthn_RegistrationHooks(t *testing.T) {
+	errCorporate := errors.New("the authenticator is not a corporate device")
+
+	testCases := []struct {
+		name       string
+		origin     string
+		preVerify  error
+		postVerify error
+		pre, post  int
+		expected   protocol.ErrorCode
+		details    string
+	}{
+		{"ShouldCallBothHooks", "https://example.com", nil, nil, 1, 1, "", ""},
+		{"ShouldRejectPreVerify", "https://example.com", errCorporate, nil, 1, 0, protocol.CodePolicyRejected, errCorporate.Error()},
+		{"ShouldRejectPostVerify", "https://example.com", nil, errCorporate, 1, 1, protocol.CodePolicyRejected, errCorporate.Error()},
+		{"ShouldPassProtocolError", "https://example.com", nil, protocol.ErrVerification.WithCode(protocol.CodeAuthenticatorUnknown), 1, 1, protocol.CodeAuthenticatorUnknown, ""},
+		{"ShouldNotCallPostVerifyOnFailure", "https://other.com", nil, nil, 1, 0, protocol.CodeOriginMismatch, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var pre, post int
+
+			user := &bytesUser{}
+
+			w, err := webauthn.New(&webauthn.Config{
+				RPID:          "example.com",
+				RPDisplayName: "Example",
+				RPOrigins:     []string{"https://example.com"},
+				RegistrationHooks: webauthn.RegistrationHooks{
+					PreVerify: func(ctx context.Context, u webauthn.User, parsedResponse *protocol.ParsedCredentialCreationData) error {
+						pre++
+
+						assert.Equal(t, user, u)
+						assert.NotNil(t, parsedResponse)
+
+						return tc.preVerify
+					},
+					PostVerify: func(ctx context.Context, u webauthn.User, parsedResponse *protocol.ParsedCredentialCreationData) error {
+						post++
+
+						assert.Equal(t, user, u)
+						assert.NotNil(t, parsedResponse.Response.AttestationObject.AuthData.AttData.CredentialID)
+
+						return tc.postVerify
+					},
+				},
+			})
+			require.NoError(t, err)
+
+			creation, session, err := w.BeginRegistration(user)
+			require.NoError(t, err)
+
+			attestation, _, err := (&webauthntest.Authenticator{}).CreateCredential(creation.Response, tc.origin)
+			require.NoError(t, err)
+
+			r, err := webauthntest.NewRequest(attestation)
+			require.NoError(t, err)
+
+			credential, err := w.FinishRegistration(user, *session, r)
+
+			assert.Equal(t, tc.pre, pre)
+			assert.Equal(t, tc.post, post)
+
+			if tc.expected == "" {
+				require.NoError(t, err)
+				assert.NotNil(t, credential)
+
+				return
+			}
+
+			require.IsType(t, &protocol.Error{}, err)
+			assert.Equal(t, tc.expected, err.(*protocol.Error).Code)
+			assert.Nil(t, credential)
+
+			if tc.details != "" {
+				assert.Equal(t, tc.details, err.(*protocol.Error).Details)
+				assert.Equal(t, protocol.FailureReasonPolicyRejected, protocol.GetFailureReason(err))
+			}
+		})
+	}
+}
+
+func TestWebAuthn_RegistrationHooksDiagnose(t *testing.T) {
+	hook := func(ctx context.Context, user webauthn.User, parsedResponse *protocol.ParsedCredentialCreationData) error {
+		t.Fatal("the hook must not be called when diagnosing")
+
+		return nil
+	}
+
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:              "example.com",
+		RPDisplayName:     "Example",
+		RPOrigins:         []string{"https://example.com"},
+		RegistrationHooks: webauthn.RegistrationHooks{PreVerify: hook, PostVerify: hook},
+	})
+	require.NoError(t, err)
+
+	user := &bytesUser{}
+
+	creation, session, err := w.BeginRegistration(user)
+	require.NoError(t, err)
+
+	attestation, _, err := (&webauthntest.Authenticator{}).CreateCredential(creation.Response, "https://example.com")
+	require.NoError(t, err)
+
+	r, err := webauthntest.NewRequest(attestation)
+	require.NoError(t, err)
+
+	report := w.DiagnoseRegistration(user, *session, r)
+
+	assert.True(t, report.Passed)
+}
+
+func TestWebAuthn_LoginHooks(t *testing.T) {
+	errLocation := errors.New("the login location is not permitted")
+
+	testCases := []struct {
+		name       string
+		preVerify  error
+		postVerify error
+		pre, post  int
+		expected   protocol.ErrorCode
+	}{
+		{"ShouldCallBothHooks", nil, nil, 1, 1, ""},
+		{"ShouldRejectPreVerify", errLocation, nil, 1, 0, protocol.CodePolicyRejected},
+		{"ShouldVetoPostVerify", nil, errLocation, 1, 1, protocol.CodePolicyRejected},
+		{"ShouldVetoPostVerifyWithPolicyError", nil, protocol.ErrPolicy.WithCode(protocol.CodeCounterRegressed), 1, 1, protocol.CodeCounterRegressed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var pre, post int
+
+			user := &bytesUser{}
+			authenticator := &webauthntest.Authenticator{}
+
+			w, err := webauthn.New(&webauthn.Config{
+				RPID:          "example.com",
+				RPDisplayName: "Example",
+				RPOrigins:     []string{"https://example.com"},
+				LoginHooks: webauthn.LoginHooks{
+					PreVerify: func(ctx context.Context, u webauthn.User, parsedResponse *protocol.ParsedCredentialAssertionData) error {
+						pre++
+
+						assert.Equal(t, user, u)
+						assert.Equal(t, user.credentials[0].ID, []byte(parsedResponse.RawID))
+
+						return tc.preVerify
+					},
+					PostVerify: func(ctx context.Context, result *webauthn.LoginResult) error {
+						post++
+
+						assert.Equal(t, user, result.User)
+						assert.Equal(t, uint32(1), result.Credential.Authenticator.SignCount)
+						assert.Equal(t, webauthn.CounterAnomalyNone, result.CounterAnomaly)
+						assert.NotNil(t, result.ParsedResponse)
+
+						return tc.postVerify
+					},
+				},
+			})
+			require.NoError(t, err)
+
+			creation, session, err := w.BeginRegistration(user)
+			require.NoError(t, err)
+
+			attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err := webauthntest.NewRequest(attestation)
+			require.NoError(t, err)
+
+			credential, err := w.FinishRegistration(user, *session, r)
+			require.NoError(t, err)
+
+			user.credentials = append(user.credentials, *credential)
+
+			assertion, session, err := w.BeginLogin(user)
+			require.NoError(t, err)
+
+			response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err = webauthntest.NewRequest(response)
+			require.NoError(t, err)
+
+			credential, err = w.FinishLogin(user, *session, r)
+
+			assert.Equal(t, tc.pre, pre)
+			assert.Equal(t, tc.post, post)
+
+			if tc.expected == "" {
+				require.NoError(t, err)
+				assert.NotNil(t, credential)
+
+				return
+			}
+
+			require.IsType(t, &protocol.Error{}, err)
+			assert.Equal(t, tc.expected, err.(*protocol.Error).Code)
+			assert.Equal(t, protocol.ErrPolicy.Type, err.(*protocol.Error).Type)
+			assert.Nil(t, credential)
+		})
+	}
+}
+
+func TestWebAuthn_LoginConnectionHook(t *testing.T) {
+	errAnomaly := errors.New("the connection does not match the device")
+
+	fromContext := webauthn.ConnectionInfo{RemoteAddr: "198.51.100.1", TLSFingerprint: "t13d1516h2_8daaf6152771_02713d6af862"}
+
+	testCases := []struct {
+		name       string
+		context    *webauthn.ConnectionInfo
+		connection error
+		expected   webauthn.ConnectionInfo
+		post       int
+		code       protocol.ErrorCode
+	}{
+		{"ShouldUseRequest", nil, nil, webauthn.ConnectionInfo{RemoteAddr: "203.0.113.7:1234", UserAgent: "webauthntest"}, 1, ""},
+		{"ShouldUseContext", &fromContext, nil, fromContext, 1, ""},
+		{"ShouldRejectConnection", nil, errAnomaly, webauthn.ConnectionInfo{RemoteAddr: "203.0.113.7:1234", UserAgent: "webauthntest"}, 0, protocol.CodePolicyRejected},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var connection, post int
+
+			user := &bytesUser{}
+			authenticator := &webauthntest.Authenticator{}
+
+			w, err := webauthn.New(&webauthn.Config{
+				RPID:          "example.com",
+				RPDisplayName: "Example",
+				RPOrigins:     []string{"https://example.com"},
+				LoginHooks: webauthn.LoginHooks{
+					Connection: func(ctx context.Context, info webauthn.ConnectionInfo, result *webauthn.LoginResult) error {
+						connection++
+
+						assert.Equal(t, tc.expected, info)
+						assert.Equal(t, user, result.User)
+						assert.Equal(t, uint32(1), result.Credential.Authenticator.SignCount)
+
+						return tc.connection
+					},
+					PostVerify: func(ctx context.Context, result *webauthn.LoginResult) error {
+						post++
+
+						return nil
+					},
+				},
+			})
+			require.NoError(t, err)
+
+			creation, session, err := w.BeginRegistration(user)
+			require.NoError(t, err)
+
+			attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err := webauthntest.NewRequest(attestation)
+			require.NoError(t, err)
+
+			credential, err := w.FinishRegistration(user, *session, r)
+			require.NoError(t, err)
+
+			user.credentials = append(user.credentials, *credential)
+
+			assertion, session, err := w.BeginLogin(user)
+			require.NoError(t, err)
+
+			response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err = webauthntest.NewRequest(response)
+			require.NoError(t, err)
+
+			r.RemoteAddr = "203.0.113.7:1234"
+			r.Header.Set("User-Agent", "webauthntest")
+
+			if tc.context != nil {
+				r = r.WithContext(webauthn.ContextWithConnectionInfo(r.Context(), *tc.context))
+			}
+
+			_, err = w.FinishLogin(user, *session, r)
+
+			assert.Equal(t, 1, connection)
+			assert.Equal(t, tc.post, post)
+
+			if tc.code == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			require.IsType(t, &protocol.Error{}, err)
+			assert.Equal(t, tc.code, err.(*protocol.Error).Code)
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package webauthn
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// ErrHybridFlowFinished is returned when a HybridFlow which has already finished is completed or failed again.
+var ErrHybridFlowFinished = errors.New("webauthn: the hybrid flow has already finished")
+
+// HybridState is the progress state of a HybridFlow.
+type HybridState string
+
+const (
+	// HybridStatePending indicates the options were sent to the client which is waiting for the user to complete the
+	// ceremony on their phone or tablet.
+	HybridStatePending HybridState = "pending"
+
+	// HybridStateCompleted indicates the ceremony was successfully finished.
+	HybridStateCompleted HybridState = "completed"
+
+	// HybridStateFailed indicates the ceremony failed.
+	HybridStateFailed HybridState = "failed"
+
+	// HybridStateExpired indicates the ceremony was not finished before the session expired.
+	HybridStateExpired HybridState = "expired"
+)
+
+// HybridFlow tracks the progress of a cross-device ceremony using the hybrid transport, previously known as caBLE,
+// where the user scans a QR code and completes the ceremony on their phone or tablet. It's plain data so it can be
+// stored alongside the SessionData and polled by the device which displays the QR code. A HybridFlow is not safe for
+// concurrent use.
+type HybridFlow struct {
+	// State is the progress state of the flow. Use Status to also account for the expiry.
+	State HybridState `json:"state"`
+
+	// Expires is the time the flow expires if it's still pending.
+	Expires time.Time `json:"expires"`
+
+	// UpdatedAt is the time the State last changed.
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Hybrid is true if the completed ceremony used the hybrid transport, see IsHybridRegistration and IsHybridLogin.
+	Hybrid bool `json:"hybrid,omitempty"`
+
+	// Reason is the reason the ceremony failed, if the error was a *protocol.Error.
+	Reason protocol.FailureReason `json:"reason,omitempty"`
+}
+
+// NewHybridFlow returns a pending HybridFlow for the ceremony of the SessionData. It expires with the SessionData, or
+// after Timeouts.Hybrid if the timeout of the session is not enforced.
+func (webauthn *WebAuthn) NewHybridFlow(session *SessionData) *HybridFlow {
+	flow := &HybridFlow{
+		State:     HybridStatePending,
+		Expires:   session.Expires,
+		UpdatedAt: webauthn.config.now(),
+	}
+
+	if flow.Expires.IsZero() {
+		flow.Expires = session.CreatedAt.Add(webauthn.config.Timeouts.Hybrid)
+	}
+
+	return flow
+}
+
+// Status returns the progress state of the flow at the provided time, which is HybridStateExpired if the flow is still
+// pending after it expired.
+func (f *HybridFlow) Status(now time.Time) HybridState {
+	if f.State == HybridStatePending && now.After(f.Expires) {
+		return HybridStateExpired
+	}
+
+	return f.State
+}
+
+// Complete transitions the pending flow to HybridStateCompleted, recording whether the ceremony used the hybrid
+// transport. It returns ErrHybridFlowFinished if the flow is not pending at the provided time.
+func (f *HybridFlow) Complete(now time.Time, hybrid bool) error {
+	if err := f.transition(now); err != nil {
+		return err
+	}
+
+	f.State, f.Hybrid, f.UpdatedAt = HybridStateCompleted, hybrid, now
+
+	return nil
+}
+
+// Fail transitions the pending flow to HybridStateFailed, recording the FailureReason of the error. It returns
+// ErrHybridFlowFinished if the flow is not pending at the provided time.
+func (f *HybridFlow) Fail(now time.Time, err error) error {
+	if e := f.transition(now); e != nil {
+		return e
+	}
+
+	f.State, f.UpdatedAt = HybridStateFailed, now
+
+	var e *protocol.Error
+
+	if errors.As(err, &e) {
+		f.Reason = e.FailureReason()
+	}
+
+	return nil
+}
+
+func (f *HybridFlow) transition(now time.Time) error {
+	switch f.Status(now) {
+	case HybridStatePending:
+		return nil
+	case HybridStateExpired:
+		f.State, f.UpdatedAt = HybridStateExpired, now
+
+		return ErrHybridFlowFinished
+	default:
+		return ErrHybridFlowFinished
+	}
+}
+
+// WithHybridLogin returns a LoginOption which tailors the options of a login for a cross-device ceremony, adding the
+// hybrid hint and using the Timeouts.Hybrid timeout.
+func (webauthn *WebAuthn) WithHybridLogin() LoginOption {
+	return func(cro *protocol.PublicKeyCredentialRequestOptions) {
+		cro.Hints = []protocol.PublicKeyCredentialHint{protocol.PublicKeyCredentialHintHybrid}
+		cro.Timeout = int(webauthn.config.hybridTimeout().Milliseconds())
+	}
+}
+
+// WithHybridRegistration returns a RegistrationOption which tailors the options of a registration for a cross-device
+// ceremony, adding the hybrid hint, preferring a cross-platform authenticator, and using the Timeouts.Hybrid timeout.
+func (webauthn *WebAuthn) WithHybridRegistration() RegistrationOption {
+	return func(cco *protocol.PublicKeyCredentialCreationOptions) {
+		cco.Hints = []protocol.PublicKeyCredentialHint{protocol.PublicKeyCredentialHintHybrid}
+		cco.AuthenticatorSelection.AuthenticatorAttachment = protocol.CrossPlatform
+		cco.Timeout = int(webauthn.config.hybridTimeout().Milliseconds())
+	}
+}
+
+// IsHybridRegistration returns true if the registration response reports the hybrid transport.
+func IsHybridRegistration(parsedResponse *protocol.ParsedCredentialCreationData) bool {
+	return hasTransport(parsedResponse.Response.Transports, protocol.Hybrid)
+}
+
+// IsHybridLogin returns true if the login response likely used the hybrid transport, which is the case when the
+// response reports a cross-platform authenticator attachment and the credential was registered with the hybrid
+// transport. The transport is not reported by login responses, so this is a heuristic.
+func IsHybridLogin(credential Credential, parsedResponse *protocol.ParsedCredentialAssertionData) bool {
+	return parsedResponse.AuthenticatorAttachment == protocol.CrossPlatform && hasTransport(credential.Transport, protocol.Hybrid)
+}
+
+func hasTransport(transports []protocol.AuthenticatorTransport, transport protocol.AuthenticatorTransport) bool {
+	for _, t := range transports {
+		if t == transport {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hybridTimeout returns the effective Timeouts.Hybrid, which may not have been defaulted yet when the option is created
+// before the Config is validated.
+func (config *Config) hybridTimeout() time.Duration {
+	if config.Timeouts.Hybrid == 0 {
+		return defaultTimeoutHybrid
+	}
+
+	return config.Timeouts.Hybrid
+}
@@ -0,0 +1,115 @@
+package webauthn_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+func TestWebAuthn_HybridOptions(t *testing.T) {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+		SpecLevel:     protocol.SpecLevel3,
+		Timeouts: webauthn.TimeoutsConfig{
+			Login: webauthn.TimeoutConfig{Enforce: true},
+		},
+	})
+	require.NoError(t, err)
+
+	user := &bytesUser{credentials: []webauthn.Credential{{ID: []byte("credential")}}}
+
+	creation, _, err := w.BeginRegistration(user, w.WithHybridRegistration())
+	require.NoError(t, err)
+
+	assert.Equal(t, []protocol.PublicKeyCredentialHint{protocol.PublicKeyCredentialHintHybrid}, creation.Response.Hints)
+	assert.Equal(t, protocol.CrossPlatform, creation.Response.AuthenticatorSelection.AuthenticatorAttachment)
+	assert.Equal(t, 600000, creation.Response.Timeout)
+
+	assertion, session, err := w.BeginLogin(user, w.WithHybridLogin())
+	require.NoError(t, err)
+
+	assert.Equal(t, []protocol.PublicKeyCredentialHint{protocol.PublicKeyCredentialHintHybrid}, assertion.Response.Hints)
+	assert.Equal(t, 600000, assertion.Response.Timeout)
+	assert.Equal(t, session.CreatedAt.Add(time.Minute*10), session.Expires)
+
+	flow := w.NewHybridFlow(session)
+
+	assert.Equal(t, webauthn.HybridStatePending, flow.State)
+	assert.Equal(t, session.Expires, flow.Expires)
+}
+
+func TestHybridFlow(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+		Clock:         func() time.Time { return now },
+	})
+	require.NoError(t, err)
+
+	session := &webauthn.SessionData{CreatedAt: now}
+
+	t.Run("ShouldComplete", func(t *testing.T) {
+		flow := w.NewHybridFlow(session)
+
+		assert.Equal(t, now.Add(time.Minute*10), flow.Expires)
+		assert.Equal(t, webauthn.HybridStatePending, flow.Status(now.Add(time.Minute)))
+
+		require.NoError(t, flow.Complete(now.Add(time.Minute), true))
+
+		assert.Equal(t, webauthn.HybridStateCompleted, flow.Status(now.Add(time.Hour)))
+		assert.True(t, flow.Hybrid)
+		assert.Equal(t, now.Add(time.Minute), flow.UpdatedAt)
+
+		assert.Equal(t, webauthn.ErrHybridFlowFinished, flow.Fail(now.Add(time.Minute), nil))
+		assert.Equal(t, webauthn.HybridStateCompleted, flow.State)
+	})
+
+	t.Run("ShouldFail", func(t *testing.T) {
+		flow := w.NewHybridFlow(session)
+
+		require.NoError(t, flow.Fail(now.Add(time.Minute), protocol.ErrVerification.WithCode(protocol.CodeOriginMismatch)))
+
+		assert.Equal(t, webauthn.HybridStateFailed, flow.State)
+		assert.Equal(t, protocol.FailureReasonBadOrigin, flow.Reason)
+
+		assert.Equal(t, webauthn.ErrHybridFlowFinished, flow.Complete(now.Add(time.Minute), true))
+	})
+
+	t.Run("ShouldExpire", func(t *testing.T) {
+		flow := w.NewHybridFlow(session)
+
+		assert.Equal(t, webauthn.HybridStateExpired, flow.Status(now.Add(time.Minute*11)))
+		assert.Equal(t, webauthn.ErrHybridFlowFinished, flow.Complete(now.Add(time.Minute*11), true))
+		assert.Equal(t, webauthn.HybridStateExpired, flow.State)
+	})
+}
+
+func TestIsHybrid(t *testing.T) {
+	registration := &protocol.ParsedCredentialCreationData{}
+
+	assert.False(t, webauthn.IsHybridRegistration(registration))
+
+	registration.Response.Transports = []protocol.AuthenticatorTransport{protocol.Internal, protocol.Hybrid}
+
+	assert.True(t, webauthn.IsHybridRegistration(registration))
+
+	credential := webauthn.Credential{Transport: []protocol.AuthenticatorTransport{protocol.Internal, protocol.Hybrid}}
+	login := &protocol.ParsedCredentialAssertionData{}
+
+	assert.False(t, webauthn.IsHybridLogin(credential, login))
+
+	login.AuthenticatorAttachment = protocol.CrossPlatform
+
+	assert.True(t, webauthn.IsHybridLogin(credential, login))
+	assert.False(t, webauthn.IsHybridLogin(webauthn.Credential{}, login))
+}
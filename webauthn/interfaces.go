@@ -0,0 +1,98 @@
+package webauthn
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// ErrSessionNotFound is returned by a SessionStore when there is no session with the ID.
+var ErrSessionNotFound = errors.New("webauthn: session not found")
+
+// ErrUserNotFound is returned by a UserStore when there is no user with the name or user handle.
+var ErrUserNotFound = errors.New("webauthn: user not found")
+
+// RegistrationCeremony performs the registration ceremony. It's implemented by *WebAuthn, and allows the code which
+// registers credentials to be tested with a mock instead of real authenticator responses.
+type RegistrationCeremony interface {
+	BeginRegistration(user User, opts ...RegistrationOption) (creation *protocol.CredentialCreation, session *SessionData, err error)
+	FinishRegistration(user User, session SessionData, response *http.Request) (credential *Credential, err error)
+}
+
+// LoginCeremony performs the login ceremonies. It's implemented by *WebAuthn, and allows the code which authenticates
+// users to be tested with a mock instead of real authenticator responses.
+type LoginCeremony interface {
+	BeginLogin(user User, opts ...LoginOption) (assertion *protocol.CredentialAssertion, session *SessionData, err error)
+	FinishLogin(user User, session SessionData, response *http.Request) (credential *Credential, err error)
+	BeginDiscoverableLogin(opts ...LoginOption) (assertion *protocol.CredentialAssertion, session *SessionData, err error)
+	FinishDiscoverableLogin(handler DiscoverableUserHandler, session SessionData, response *http.Request) (credential *Credential, err error)
+}
+
+var (
+	_ RegistrationCeremony = (*WebAuthn)(nil)
+	_ LoginCeremony        = (*WebAuthn)(nil)
+)
+
+// UserStore is the storage of the users and their credentials used by the ceremonies. The library does not provide an
+// implementation as the users are owned by the Relying Party.
+type UserStore interface {
+	// LoadUser returns the user with the name, or ErrUserNotFound if there is no such user.
+	LoadUser(ctx context.Context, name string) (user User, err error)
+
+	// LoadUserByHandle returns the user with the user handle which is returned by the authenticator during a
+	// discoverable login, or ErrUserNotFound if there is no such user.
+	LoadUserByHandle(ctx context.Context, userHandle []byte) (user User, err error)
+
+	// SaveCredential saves the credential of the user after a registration, or updates it after a login as the
+	// authenticator data such as the sign count changes.
+	SaveCredential(ctx context.Context, user User, credential *Credential) (err error)
+}
+
+// SessionStore is the storage of the SessionData between the beginning and the end of a ceremony. The ID is chosen by
+// the caller and usually is a random value stored in a cookie.
+type SessionStore interface {
+	// SaveSession saves the session with the ID, replacing an existing session with the same ID.
+	SaveSession(ctx context.Context, id string, session SessionData) (err error)
+
+	// LoadSession returns the session with the ID, or ErrSessionNotFound if there is no such session.
+	LoadSession(ctx context.Context, id string) (session SessionData, err error)
+
+	// DeleteSession deletes the session with the ID. It must be called once the ceremony is finished as a session must
+	// only be used once.
+	DeleteSession(ctx context.Context, id string) (err error)
+}
+
+// DiscoverableUserHandlerFromStore returns a DiscoverableUserHandler which loads the user by the user handle from the
+// UserStore.
+func DiscoverableUserHandlerFromStore(ctx context.Context, store UserStore) DiscoverableUserHandler {
+	return func(_, userHandle []byte) (User, error) {
+		return store.LoadUserByHandle(ctx, userHandle)
+	}
+}
+
+// AssertionVerifier verifies the signature of a login assertion on behalf of the Relying Party, such as by calling the
+// verify operation of a cloud KMS or HSM which holds the credential public key. See Config.AssertionVerifier.
+type AssertionVerifier interface {
+	// VerifyAssertion returns nil if the signature is valid. Errors which are not a *protocol.Error are returned as a
+	// protocol.ErrAssertionSignature.
+	VerifyAssertion(ctx context.Context, assertion AssertionSignature) (err error)
+}
+
+// AssertionSignature is the signature of a login assertion to be verified by an AssertionVerifier.
+type AssertionSignature struct {
+	// Credential which was used to log in.
+	Credential Credential
+
+	// SignedData is the binary concatenation of the authenticator data and the hash of the client data. It's reused
+	// once VerifyAssertion returns, so it must be copied if it's retained.
+	SignedData []byte
+
+	// Signature over the SignedData.
+	Signature []byte
+
+	// AppID is true if the FIDO AppID extension was used, in which case the signature was created with a FIDO U2F
+	// credential.
+	AppID bool
+}
@@ -0,0 +1,148 @@
+package webauthn
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+)
+
+// JSONWebKey is the RFC 7517 JSON Web Key representation of a credential public key. The Kid is the base64url encoded
+// credential ID.
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+	Modulus   string `json:"n,omitempty"`
+	Exponent  string `json:"e,omitempty"`
+}
+
+// JSONWebKeySet is an RFC 7517 JSON Web Key Set.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// UserJWKSet returns the public keys of the credentials of the user as a JSONWebKeySet, so services such as API
+// gateways and token verifiers can consume them directly.
+func UserJWKSet(user User) (set JSONWebKeySet, err error) {
+	return CredentialsJWKSet(user.WebAuthnCredentials())
+}
+
+// CredentialsJWKSet returns the public keys of the credentials as a JSONWebKeySet in their original order. It returns
+// an error if the public key of any of the credentials can't be represented as a JSON Web Key.
+func CredentialsJWKSet(credentials []Credential) (set JSONWebKeySet, err error) {
+	set.Keys = make([]JSONWebKey, len(credentials))
+
+	for i, credential := range credentials {
+		if set.Keys[i], err = credential.JWK(); err != nil {
+			return JSONWebKeySet{}, err
+		}
+	}
+
+	return set, nil
+}
+
+// JWK returns the public key of the Credential as a JSONWebKey.
+func (c Credential) JWK() (jwk JSONWebKey, err error) {
+	key, err := parseCredentialPublicKey(c)
+	if err != nil {
+		return jwk, protocol.ErrUnsupportedKey.
+			WithCode(protocol.CodePublicKeyInvalid).
+			WithDetails(fmt.Sprintf("Error parsing the credential public key: %+v", err))
+	}
+
+	jwk = JSONWebKey{
+		KeyID: base64.RawURLEncoding.EncodeToString(c.ID),
+		Use:   "sig",
+	}
+
+	var alg webauthncose.COSEAlgorithmIdentifier
+
+	switch k := key.(type) {
+	case webauthncose.EC2PublicKeyData:
+		alg = webauthncose.COSEAlgorithmIdentifier(k.Algorithm)
+
+		size, ok := jwkCurveSizes[webauthncose.COSEEllipticCurve(k.Curve)]
+		if !ok || len(k.XCoord) > size || len(k.YCoord) > size {
+			return jwk, protocol.ErrUnsupportedKey.
+				WithCode(protocol.CodePublicKeyInvalid).
+				WithDetails("Credential public key curve is not supported").
+				WithInfo(fmt.Sprintf("Curve: %d", k.Curve))
+		}
+
+		jwk.KeyType = "EC"
+		jwk.Curve = jwkCurves[webauthncose.COSEEllipticCurve(k.Curve)]
+		jwk.X = base64.RawURLEncoding.EncodeToString(leftPad(k.XCoord, size))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(leftPad(k.YCoord, size))
+	case webauthncose.RSAPublicKeyData:
+		alg = webauthncose.COSEAlgorithmIdentifier(k.Algorithm)
+
+		jwk.KeyType = "RSA"
+		jwk.Modulus = base64.RawURLEncoding.EncodeToString(k.Modulus)
+		jwk.Exponent = base64.RawURLEncoding.EncodeToString(k.Exponent)
+	case webauthncose.OKPPublicKeyData:
+		alg = webauthncose.COSEAlgorithmIdentifier(k.Algorithm)
+
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(k.XCoord)
+	}
+
+	var ok bool
+
+	if jwk.Algorithm, ok = jwkAlgorithms[alg]; !ok {
+		return jwk, protocol.ErrUnsupportedAlgorithm.
+			WithCode(protocol.CodeAlgorithmNotAllowed).
+			WithDetails("Credential public key algorithm has no JSON Web Algorithm").
+			WithInfo(fmt.Sprintf("Algorithm: %d", alg))
+	}
+
+	return jwk, nil
+}
+
+// jwkAlgorithms maps the COSE algorithms to the RFC 7518 JSON Web Algorithms.
+var jwkAlgorithms = map[webauthncose.COSEAlgorithmIdentifier]string{
+	webauthncose.AlgES256:  "ES256",
+	webauthncose.AlgES384:  "ES384",
+	webauthncose.AlgES512:  "ES512",
+	webauthncose.AlgES256K: "ES256K",
+	webauthncose.AlgRS1:    "RS1",
+	webauthncose.AlgRS256:  "RS256",
+	webauthncose.AlgRS384:  "RS384",
+	webauthncose.AlgRS512:  "RS512",
+	webauthncose.AlgPS256:  "PS256",
+	webauthncose.AlgPS384:  "PS384",
+	webauthncose.AlgPS512:  "PS512",
+	webauthncose.AlgEdDSA:  "EdDSA",
+}
+
+var jwkCurves = map[webauthncose.COSEEllipticCurve]string{
+	webauthncose.P256:      "P-256",
+	webauthncose.P384:      "P-384",
+	webauthncose.P521:      "P-521",
+	webauthncose.Secp256k1: "secp256k1",
+}
+
+// jwkCurveSizes is the length of the coordinates of the curves, which RFC 7518 requires to be the full length.
+var jwkCurveSizes = map[webauthncose.COSEEllipticCurve]int{
+	webauthncose.P256:      32,
+	webauthncose.P384:      48,
+	webauthncose.P521:      66,
+	webauthncose.Secp256k1: 32,
+}
+
+func leftPad(data []byte, size int) []byte {
+	if len(data) >= size {
+		return data
+	}
+
+	padded := make([]byte, size)
+	copy(padded[size-len(data):], data)
+
+	return padded
+}
@@ -0,0 +1,92 @@
+package webauthn_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+func TestUserJWKSet(t *testing.T) {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+	})
+	require.NoError(t, err)
+
+	user := &bytesUser{}
+
+	algorithms := []webauthncose.COSEAlgorithmIdentifier{webauthncose.AlgES256, webauthncose.AlgRS256}
+
+	if !webauthncose.FIPSMode {
+		algorithms = append(algorithms, webauthncose.AlgEdDSA)
+	}
+
+	var keys []*webauthntest.Credential
+
+	for _, alg := range algorithms {
+		authenticator := &webauthntest.Authenticator{Algorithm: alg}
+
+		creation, session, err := w.BeginRegistration(user)
+		require.NoError(t, err)
+
+		attestation, key, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+		require.NoError(t, err)
+
+		r, err := webauthntest.NewRequest(attestation)
+		require.NoError(t, err)
+
+		credential, err := w.FinishRegistration(user, *session, r)
+		require.NoError(t, err)
+
+		user.credentials = append(user.credentials, *credential)
+		keys = append(keys, key)
+	}
+
+	set, err := webauthn.UserJWKSet(user)
+	require.NoError(t, err)
+	require.Len(t, set.Keys, len(keys))
+
+	for i, jwk := range set.Keys {
+		assert.Equal(t, base64.RawURLEncoding.EncodeToString(keys[i].ID), jwk.KeyID)
+		assert.Equal(t, "sig", jwk.Use)
+
+		switch k := keys[i].PrivateKey.Public().(type) {
+		case *ecdsa.PublicKey:
+			assert.Equal(t, "EC", jwk.KeyType)
+			assert.Equal(t, "ES256", jwk.Algorithm)
+			assert.Equal(t, "P-256", jwk.Curve)
+			assert.Equal(t, base64.RawURLEncoding.EncodeToString(k.X.FillBytes(make([]byte, 32))), jwk.X)
+			assert.Equal(t, base64.RawURLEncoding.EncodeToString(k.Y.FillBytes(make([]byte, 32))), jwk.Y)
+		case ed25519.PublicKey:
+			assert.Equal(t, "OKP", jwk.KeyType)
+			assert.Equal(t, "EdDSA", jwk.Algorithm)
+			assert.Equal(t, base64.RawURLEncoding.EncodeToString(k), jwk.X)
+		default:
+			assert.Equal(t, "RSA", jwk.KeyType)
+			assert.Equal(t, "RS256", jwk.Algorithm)
+			assert.NotEmpty(t, jwk.Modulus)
+			assert.Equal(t, "AQAB", jwk.Exponent)
+		}
+	}
+
+	data, err := json.Marshal(set)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `{"keys":[{"kty":"EC","kid":`)
+
+	user.credentials = append(user.credentials, webauthn.Credential{ID: []byte("invalid"), PublicKey: []byte("key")})
+
+	_, err = webauthn.UserJWKSet(user)
+	require.IsType(t, &protocol.Error{}, err)
+	assert.Equal(t, protocol.CodePublicKeyInvalid, err.(*protocol.Error).Code)
+}
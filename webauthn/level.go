@@ -0,0 +1,50 @@
+package webauthn
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+func (webauthn *WebAuthn) verifyTopOrigin(trace *protocol.VerificationTrace, clientData *protocol.CollectedClientData) error {
+	if webauthn.config.SpecLevel < protocol.SpecLevel3 {
+		return nil
+	}
+
+	return trace.Step(protocol.VerificationStepTopOrigin, protocol.VerifyTopOrigin(clientData, webauthn.config.RPTopOrigins), map[string]string{
+		"cross_origin": strconv.FormatBool(clientData.CrossOrigin),
+		"top_origin":   clientData.TopOrigin,
+	})
+}
+
+func (webauthn *WebAuthn) verifyResponseJSON(trace *protocol.VerificationTrace, parsedResponse *protocol.ParsedCredentialCreationData) error {
+	if webauthn.config.SpecLevel < protocol.SpecLevel3 {
+		return nil
+	}
+
+	return trace.Step(protocol.VerificationStepResponseJSON, parsedResponse.VerifyResponseJSON(), nil)
+}
+
+// verifyBackupEligibility ensures the backup eligible flag of the authenticator data matches the stored credential,
+// as the backup eligibility of a credential is fixed when it's created, unless the BackupEligibilityUpgrade leniency of
+// the BrowserCompatibility accepts the change.
+func (webauthn *WebAuthn) verifyBackupEligibility(trace *protocol.VerificationTrace, credential Credential, flags protocol.AuthenticatorFlags) error {
+	if webauthn.config.SpecLevel < protocol.SpecLevel3 {
+		return nil
+	}
+
+	var err error
+
+	if credential.Flags.BackupEligible != flags.HasBackupEligible() && !webauthn.config.backupEligibilityUpgraded(credential, flags) {
+		err = protocol.ErrVerification.
+			WithCode(protocol.CodeBackupEligibilityChanged).
+			WithDetails("Backup eligibility of the credential changed").
+			WithInfo(fmt.Sprintf("Stored: %t, Received: %t", credential.Flags.BackupEligible, flags.HasBackupEligible()))
+	}
+
+	return trace.Step(protocol.VerificationStepBackupEligibility, err, map[string]string{
+		"stored":   strconv.FormatBool(credential.Flags.BackupEligible),
+		"received": strconv.FormatBool(flags.HasBackupEligible()),
+	})
+}
@@ -0,0 +1,165 @@
+package webauthn_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+func TestWebAuthn_SpecLevelOptions(t *testing.T) {
+	testCases := []struct {
+		name     string
+		level    protocol.SpecLevel
+		included bool
+	}{
+		{"ShouldOmitLevel3OptionsAtLevel2", protocol.SpecLevel2, false},
+		{"ShouldIncludeLevel3OptionsAtLevel3", protocol.SpecLevel3, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := webauthn.New(&webauthn.Config{
+				RPID:          "example.com",
+				RPDisplayName: "Example",
+				RPOrigins:     []string{"https://example.com"},
+				SpecLevel:     tc.level,
+			})
+			require.NoError(t, err)
+
+			user := &bytesUser{credentials: []webauthn.Credential{{ID: []byte("credential")}}}
+
+			creation, _, err := w.BeginRegistration(user, webauthn.WithHints(protocol.PublicKeyCredentialHintSecurityKey), webauthn.WithAttestationFormats("packed"))
+			require.NoError(t, err)
+
+			assertion, _, err := w.BeginLogin(user, webauthn.WithAssertionHints(protocol.PublicKeyCredentialHintClientDevice))
+			require.NoError(t, err)
+
+			if tc.included {
+				assert.Equal(t, []protocol.PublicKeyCredentialHint{protocol.PublicKeyCredentialHintSecurityKey}, creation.Response.Hints)
+				assert.Equal(t, []string{"packed"}, creation.Response.AttestationFormats)
+				assert.Equal(t, []protocol.PublicKeyCredentialHint{protocol.PublicKeyCredentialHintClientDevice}, assertion.Response.Hints)
+			} else {
+				assert.Nil(t, creation.Response.Hints)
+				assert.Nil(t, creation.Response.AttestationFormats)
+				assert.Nil(t, assertion.Response.Hints)
+			}
+		})
+	}
+}
+
+func TestWebAuthn_SpecLevelTopOrigin(t *testing.T) {
+	testCases := []struct {
+		name       string
+		level      protocol.SpecLevel
+		topOrigins []string
+		topOrigin  string
+		expected   protocol.ErrorCode
+	}{
+		{"ShouldIgnoreTopOriginAtLevel2", protocol.SpecLevel2, nil, "https://evil.com", ""},
+		{"ShouldAllowSameOrigin", protocol.SpecLevel3, nil, "", ""},
+		{"ShouldAllowExpectedTopOrigin", protocol.SpecLevel3, []string{"https://top.example.com"}, "https://top.example.com", ""},
+		{"ShouldRejectUnexpectedTopOrigin", protocol.SpecLevel3, []string{"https://top.example.com"}, "https://evil.com", protocol.CodeTopOriginMismatch},
+		{"ShouldRejectCrossOrigin", protocol.SpecLevel3, nil, "https://top.example.com", protocol.CodeTopOriginMismatch},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := webauthn.New(&webauthn.Config{
+				RPID:          "example.com",
+				RPDisplayName: "Example",
+				RPOrigins:     []string{"https://example.com"},
+				SpecLevel:     tc.level,
+				RPTopOrigins:  tc.topOrigins,
+			})
+			require.NoError(t, err)
+
+			user := &bytesUser{}
+			authenticator := &webauthntest.Authenticator{TopOrigin: tc.topOrigin}
+
+			creation, session, err := w.BeginRegistration(user)
+			require.NoError(t, err)
+
+			attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err := webauthntest.NewRequest(attestation)
+			require.NoError(t, err)
+
+			_, err = w.FinishRegistration(user, *session, r)
+			assertErrorCode(t, tc.expected, err)
+		})
+	}
+}
+
+func TestWebAuthn_SpecLevelBackupEligibility(t *testing.T) {
+	testCases := []struct {
+		name     string
+		level    protocol.SpecLevel
+		flags    protocol.AuthenticatorFlags
+		expected protocol.ErrorCode
+	}{
+		{"ShouldAllowUnchanged", protocol.SpecLevel3, protocol.FlagUserPresent | protocol.FlagBackupEligible | protocol.FlagBackupState, ""},
+		{"ShouldIgnoreChangedAtLevel2", protocol.SpecLevel2, protocol.FlagUserPresent, ""},
+		{"ShouldRejectChanged", protocol.SpecLevel3, protocol.FlagUserPresent, protocol.CodeBackupEligibilityChanged},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := webauthn.New(&webauthn.Config{
+				RPID:          "example.com",
+				RPDisplayName: "Example",
+				RPOrigins:     []string{"https://example.com"},
+				SpecLevel:     tc.level,
+			})
+			require.NoError(t, err)
+
+			user := &bytesUser{}
+			authenticator := &webauthntest.Authenticator{Flags: protocol.FlagUserPresent | protocol.FlagBackupEligible}
+
+			creation, session, err := w.BeginRegistration(user)
+			require.NoError(t, err)
+
+			attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err := webauthntest.NewRequest(attestation)
+			require.NoError(t, err)
+
+			credential, err := w.FinishRegistration(user, *session, r)
+			require.NoError(t, err)
+			require.True(t, credential.Flags.BackupEligible)
+
+			user.credentials = append(user.credentials, *credential)
+
+			authenticator.Flags = tc.flags
+
+			assertion, session, err := w.BeginLogin(user)
+			require.NoError(t, err)
+
+			response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err = webauthntest.NewRequest(response)
+			require.NoError(t, err)
+
+			_, err = w.FinishLogin(user, *session, r)
+			assertErrorCode(t, tc.expected, err)
+		})
+	}
+}
+
+func TestWebAuthn_SpecLevelValidate(t *testing.T) {
+	_, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+		SpecLevel:     protocol.SpecLevel(4),
+	})
+
+	assert.EqualError(t, err, "error occurred validating the configuration: the field 'SpecLevel' must be protocol.SpecLevel2 or protocol.SpecLevel3 but it is 4")
+}
@@ -2,8 +2,11 @@ package webauthn
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-webauthn/webauthn/protocol"
@@ -27,32 +30,72 @@ type DiscoverableUserHandler func(rawID, userHandle []byte) (user User, err erro
 //
 // Specification: §5.5. Options for Assertion Generation (https://www.w3.org/TR/webauthn/#dictionary-assertion-options)
 func (webauthn *WebAuthn) BeginLogin(user User, opts ...LoginOption) (*protocol.CredentialAssertion, *SessionData, error) {
-	credentials := user.WebAuthnCredentials()
+	return webauthn.BeginLoginCtx(context.Background(), user, opts...)
+}
 
-	if len(credentials) == 0 { // If the user does not have any credentials, we cannot perform an assertion.
-		return nil, nil, protocol.ErrBadRequest.WithDetails("Found no credentials for user")
-	}
+// BeginLoginCtx is the same as BeginLogin except it accepts a context.Context.
+func (webauthn *WebAuthn) BeginLoginCtx(ctx context.Context, user User, opts ...LoginOption) (*protocol.CredentialAssertion, *SessionData, error) {
+	_, observer := webauthn.startCeremony(ctx, CeremonyBeginLogin, user.WebAuthnID())
 
-	var allowedCredentials = make([]protocol.CredentialDescriptor, len(credentials))
+	assertion, session, err := webauthn.beginUserLogin(ctx, user, opts...)
 
-	for i, credential := range credentials {
-		allowedCredentials[i] = credential.Descriptor()
+	observer.observe(nil, err)
+
+	if err != nil {
+		return nil, nil, webauthn.handleError(err)
 	}
 
-	return webauthn.beginLogin(user.WebAuthnID(), allowedCredentials, opts...)
+	return assertion, session, nil
 }
 
 // BeginDiscoverableLogin begins a client-side discoverable login, previously known as Resident Key logins.
 func (webauthn *WebAuthn) BeginDiscoverableLogin(opts ...LoginOption) (*protocol.CredentialAssertion, *SessionData, error) {
+	return webauthn.BeginDiscoverableLoginCtx(context.Background(), opts...)
+}
+
+// BeginDiscoverableLoginCtx is the same as BeginDiscoverableLogin except it accepts a context.Context.
+func (webauthn *WebAuthn) BeginDiscoverableLoginCtx(ctx context.Context, opts ...LoginOption) (*protocol.CredentialAssertion, *SessionData, error) {
+	_, observer := webauthn.startCeremony(ctx, CeremonyBeginDiscoverableLogin, nil)
+
+	assertion, session, err := webauthn.beginDiscoverableLogin(ctx, opts...)
+
+	observer.observe(nil, err)
+
+	if err != nil {
+		return nil, nil, webauthn.handleError(err)
+	}
+
+	return assertion, session, nil
+}
+
+func (webauthn *WebAuthn) beginUserLogin(ctx context.Context, user User, opts ...LoginOption) (*protocol.CredentialAssertion, *SessionData, error) {
+	if err := webauthn.checkRateLimit(ctx, CeremonyBeginLogin, user.WebAuthnID()); err != nil {
+		return nil, nil, err
+	}
+
+	credentials := user.WebAuthnCredentials()
+
+	if len(credentials) == 0 { // If the user does not have any credentials, we cannot perform an assertion.
+		return nil, nil, protocol.ErrBadRequest.WithCode(protocol.CodeNoCredentials).WithDetails("Found no credentials for user")
+	}
+
+	return webauthn.beginLogin(user.WebAuthnID(), CredentialDescriptors(credentials), opts...)
+}
+
+func (webauthn *WebAuthn) beginDiscoverableLogin(ctx context.Context, opts ...LoginOption) (*protocol.CredentialAssertion, *SessionData, error) {
+	if err := webauthn.checkRateLimit(ctx, CeremonyBeginDiscoverableLogin, nil); err != nil {
+		return nil, nil, err
+	}
+
 	return webauthn.beginLogin(nil, nil, opts...)
 }
 
 func (webauthn *WebAuthn) beginLogin(userID []byte, allowedCredentials []protocol.CredentialDescriptor, opts ...LoginOption) (assertion *protocol.CredentialAssertion, session *SessionData, err error) {
-	if err = webauthn.Config.validate(); err != nil {
+	if err = webauthn.config.validate(); err != nil {
 		return nil, nil, fmt.Errorf(errFmtConfigValidate, err)
 	}
 
-	challenge, err := protocol.CreateChallenge()
+	challenge, err := webauthn.config.newChallenge()
 	if err != nil {
 		return nil, nil, err
 	}
@@ -60,8 +103,8 @@ func (webauthn *WebAuthn) beginLogin(userID []byte, allowedCredentials []protoco
 	assertion = &protocol.CredentialAssertion{
 		Response: protocol.PublicKeyCredentialRequestOptions{
 			Challenge:          challenge,
-			RelyingPartyID:     webauthn.Config.RPID,
-			UserVerification:   webauthn.Config.AuthenticatorSelection.UserVerification,
+			RelyingPartyID:     webauthn.config.RPID,
+			UserVerification:   webauthn.config.AuthenticatorSelection.UserVerification,
 			AllowedCredentials: allowedCredentials,
 		},
 	}
@@ -70,25 +113,30 @@ func (webauthn *WebAuthn) beginLogin(userID []byte, allowedCredentials []protoco
 		opt(&assertion.Response)
 	}
 
+	if webauthn.config.SpecLevel < protocol.SpecLevel3 {
+		assertion.Response.Hints = nil
+	}
+
 	if assertion.Response.Timeout == 0 {
 		switch {
 		case assertion.Response.UserVerification == protocol.VerificationDiscouraged:
-			assertion.Response.Timeout = int(webauthn.Config.Timeouts.Login.TimeoutUVD.Milliseconds())
+			assertion.Response.Timeout = int(webauthn.config.Timeouts.Login.TimeoutUVD.Milliseconds())
 		default:
-			assertion.Response.Timeout = int(webauthn.Config.Timeouts.Login.Timeout.Milliseconds())
+			assertion.Response.Timeout = int(webauthn.config.Timeouts.Login.Timeout.Milliseconds())
 		}
 	}
 
 	session = &SessionData{
-		Challenge:            challenge.String(),
+		Challenge:            assertion.Response.Challenge.String(),
 		UserID:               userID,
 		AllowedCredentialIDs: assertion.Response.GetAllowedCredentialIDs(),
 		UserVerification:     assertion.Response.UserVerification,
-		Extensions:           assertion.Response.Extensions,
+		Extensions:           cloneExtensions(assertion.Response.Extensions),
+		CreatedAt:            webauthn.config.now(),
 	}
 
-	if webauthn.Config.Timeouts.Login.Enforce {
-		session.Expires = time.Now().Add(time.Millisecond * time.Duration(assertion.Response.Timeout))
+	if webauthn.config.Timeouts.Login.Enforce {
+		session.Expires = session.CreatedAt.Add(time.Millisecond * time.Duration(assertion.Response.Timeout))
 	}
 
 	return assertion, session, nil
@@ -122,6 +170,16 @@ func WithAssertionExtensions(extensions protocol.AuthenticationExtensions) Login
 	}
 }
 
+// WithAssertionHints adjusts the hints to the user agent about how the login may be best completed, ordered from most
+// preferred to least preferred. The hints are only included when the Config.SpecLevel is protocol.SpecLevel3.
+//
+// Specification: §5.8.7. User-agent Hints Enumeration (https://www.w3.org/TR/webauthn-3/#enumdef-publickeycredentialhint)
+func WithAssertionHints(hints ...protocol.PublicKeyCredentialHint) LoginOption {
+	return func(cco *protocol.PublicKeyCredentialRequestOptions) {
+		cco.Hints = hints
+	}
+}
+
 // WithAppIdExtension automatically includes the specified appid if the AllowedCredentials contains a credential
 // with the type `fido-u2f`.
 func WithAppIdExtension(appid string) LoginOption {
@@ -140,59 +198,418 @@ func WithAppIdExtension(appid string) LoginOption {
 
 // FinishLogin takes the response from the client and validate it against the user credentials and stored session data.
 func (webauthn *WebAuthn) FinishLogin(user User, session SessionData, response *http.Request) (*Credential, error) {
-	parsedResponse, err := protocol.ParseCredentialRequestResponse(response)
+	return webauthn.FinishLoginCtx(requestContext(response), user, session, response)
+}
+
+// FinishLoginCtx is the same as FinishLogin except the provided context is used instead of the context of the request.
+func (webauthn *WebAuthn) FinishLoginCtx(ctx context.Context, user User, session SessionData, response *http.Request) (*Credential, error) {
+	return loginCredential(webauthn.FinishLoginResultCtx(ctx, user, session, response))
+}
+
+// FinishLoginResult is the same as FinishLogin except it returns the LoginResult of the login, which has the time of the
+// authentication and its assurance for session management layers which enforce re-authentication rules.
+func (webauthn *WebAuthn) FinishLoginResult(user User, session SessionData, response *http.Request) (*LoginResult, error) {
+	return webauthn.FinishLoginResultCtx(requestContext(response), user, session, response)
+}
+
+// FinishLoginResultCtx is the same as FinishLoginResult except the provided context is used instead of the context of
+// the request.
+func (webauthn *WebAuthn) FinishLoginResultCtx(ctx context.Context, user User, session SessionData, response *http.Request) (*LoginResult, error) {
+	ctx, observer := webauthn.startCeremony(connectionContext(ctx, response), CeremonyFinishLogin, user.WebAuthnID())
+
+	observer.recordRequest(session, response)
+
+	webauthn.config.compatibleRequest(response)
+
+	parsedResponse, err := protocol.ParseCredentialRequestResponseWithLimit(response, webauthn.config.responseBodyLimit())
+
+	observer.trace.Record(protocol.VerificationStepParse, err, nil)
+
 	if err != nil {
-		return nil, err
+		return observer.finishLogin(nil, err)
 	}
 
-	return webauthn.ValidateLogin(user, session, parsedResponse)
+	return observer.finishLogin(webauthn.validateUserLogin(ctx, observer.trace, user, session, parsedResponse))
 }
 
 // FinishDiscoverableLogin takes the response from the client and validate it against the handler and stored session data.
 // The handler helps to find out which user must be used to validate the response. This is a function defined in your
 // business code that will retrieve the user from your persistent data.
 func (webauthn *WebAuthn) FinishDiscoverableLogin(handler DiscoverableUserHandler, session SessionData, response *http.Request) (*Credential, error) {
-	parsedResponse, err := protocol.ParseCredentialRequestResponse(response)
+	return webauthn.FinishDiscoverableLoginCtx(requestContext(response), handler, session, response)
+}
+
+// FinishDiscoverableLoginCtx is the same as FinishDiscoverableLogin except the provided context is used instead of the
+// context of the request.
+func (webauthn *WebAuthn) FinishDiscoverableLoginCtx(ctx context.Context, handler DiscoverableUserHandler, session SessionData, response *http.Request) (*Credential, error) {
+	return loginCredential(webauthn.FinishDiscoverableLoginResultCtx(ctx, handler, session, response))
+}
+
+// FinishDiscoverableLoginResult is the same as FinishDiscoverableLogin except it returns the LoginResult of the login.
+// See FinishLoginResult.
+func (webauthn *WebAuthn) FinishDiscoverableLoginResult(handler DiscoverableUserHandler, session SessionData, response *http.Request) (*LoginResult, error) {
+	return webauthn.FinishDiscoverableLoginResultCtx(requestContext(response), handler, session, response)
+}
+
+// FinishDiscoverableLoginResultCtx is the same as FinishDiscoverableLoginResult except the provided context is used
+// instead of the context of the request.
+func (webauthn *WebAuthn) FinishDiscoverableLoginResultCtx(ctx context.Context, handler DiscoverableUserHandler, session SessionData, response *http.Request) (*LoginResult, error) {
+	ctx, observer := webauthn.startCeremony(connectionContext(ctx, response), CeremonyFinishDiscoverableLogin, nil)
+
+	observer.recordRequest(session, response)
+
+	webauthn.config.compatibleRequest(response)
+
+	parsedResponse, err := protocol.ParseCredentialRequestResponseWithLimit(response, webauthn.config.responseBodyLimit())
+
+	observer.trace.Record(protocol.VerificationStepParse, err, nil)
+
 	if err != nil {
-		return nil, err
+		return observer.finishLogin(nil, err)
 	}
 
-	return webauthn.ValidateDiscoverableLogin(handler, session, parsedResponse)
+	observer.userID = parsedResponse.Response.UserHandle
+
+	return observer.finishLogin(webauthn.validateDiscoverableLogin(ctx, observer.trace, handler, session, parsedResponse))
 }
 
-// ValidateLogin takes a parsed response and validates it against the user credentials and session data.
-func (webauthn *WebAuthn) ValidateLogin(user User, session SessionData, parsedResponse *protocol.ParsedCredentialAssertionData) (*Credential, error) {
-	if !bytes.Equal(user.WebAuthnID(), session.UserID) {
-		return nil, protocol.ErrBadRequest.WithDetails("ID mismatch for User and Session")
+// FinishLoginBytes is the same as FinishLogin except the response is the body of the request as a byte slice, for
+// frameworks such as fasthttp which do not use the http library from stdlib.
+func (webauthn *WebAuthn) FinishLoginBytes(user User, session SessionData, body []byte) (*Credential, error) {
+	return webauthn.FinishLoginBytesCtx(context.Background(), user, session, body)
+}
+
+// FinishLoginBytesCtx is the same as FinishLoginBytes except it accepts a context.Context.
+func (webauthn *WebAuthn) FinishLoginBytesCtx(ctx context.Context, user User, session SessionData, body []byte) (*Credential, error) {
+	ctx, observer := webauthn.startCeremony(ctx, CeremonyFinishLogin, user.WebAuthnID())
+
+	observer.recordBody(session, body)
+
+	parsedResponse, err := protocol.ParseCredentialRequestResponseBytesWithLimit(webauthn.config.compatibleBody(body), webauthn.config.responseBodyLimit())
+
+	observer.trace.Record(protocol.VerificationStepParse, err, nil)
+
+	if err != nil {
+		return observer.finish(nil, err)
 	}
 
-	if !session.Expires.IsZero() && session.Expires.Before(time.Now()) {
-		return nil, protocol.ErrBadRequest.WithDetails("Session has Expired")
+	return observer.finish(loginCredential(webauthn.validateUserLogin(ctx, observer.trace, user, session, parsedResponse)))
+}
+
+// FinishDiscoverableLoginBytes is the same as FinishDiscoverableLogin except the response is the body of the request as
+// a byte slice, for frameworks such as fasthttp which do not use the http library from stdlib.
+func (webauthn *WebAuthn) FinishDiscoverableLoginBytes(handler DiscoverableUserHandler, session SessionData, body []byte) (*Credential, error) {
+	return webauthn.FinishDiscoverableLoginBytesCtx(context.Background(), handler, session, body)
+}
+
+// FinishDiscoverableLoginBytesCtx is the same as FinishDiscoverableLoginBytes except it accepts a context.Context.
+func (webauthn *WebAuthn) FinishDiscoverableLoginBytesCtx(ctx context.Context, handler DiscoverableUserHandler, session SessionData, body []byte) (*Credential, error) {
+	ctx, observer := webauthn.startCeremony(ctx, CeremonyFinishDiscoverableLogin, nil)
+
+	observer.recordBody(session, body)
+
+	parsedResponse, err := protocol.ParseCredentialRequestResponseBytesWithLimit(webauthn.config.compatibleBody(body), webauthn.config.responseBodyLimit())
+
+	observer.trace.Record(protocol.VerificationStepParse, err, nil)
+
+	if err != nil {
+		return observer.finish(nil, err)
 	}
 
-	return webauthn.validateLogin(user, session, parsedResponse)
+	observer.userID = parsedResponse.Response.UserHandle
+
+	return observer.finish(loginCredential(webauthn.validateDiscoverableLogin(ctx, observer.trace, handler, session, parsedResponse)))
+}
+
+// ValidateLogin takes a parsed response and validates it against the user credentials and session data.
+func (webauthn *WebAuthn) ValidateLogin(user User, session SessionData, parsedResponse *protocol.ParsedCredentialAssertionData) (*Credential, error) {
+	return webauthn.ValidateLoginCtx(context.Background(), user, session, parsedResponse)
+}
+
+// ValidateLoginCtx is the same as ValidateLogin except it accepts a context.Context.
+func (webauthn *WebAuthn) ValidateLoginCtx(ctx context.Context, user User, session SessionData, parsedResponse *protocol.ParsedCredentialAssertionData) (*Credential, error) {
+	return loginCredential(webauthn.ValidateLoginResultCtx(ctx, user, session, parsedResponse))
+}
+
+// ValidateLoginResult is the same as ValidateLogin except it returns the LoginResult of the login. See
+// FinishLoginResult.
+func (webauthn *WebAuthn) ValidateLoginResult(user User, session SessionData, parsedResponse *protocol.ParsedCredentialAssertionData) (*LoginResult, error) {
+	return webauthn.ValidateLoginResultCtx(context.Background(), user, session, parsedResponse)
+}
+
+// ValidateLoginResultCtx is the same as ValidateLoginResult except it accepts a context.Context.
+func (webauthn *WebAuthn) ValidateLoginResultCtx(ctx context.Context, user User, session SessionData, parsedResponse *protocol.ParsedCredentialAssertionData) (*LoginResult, error) {
+	ctx, observer := webauthn.startCeremony(ctx, CeremonyFinishLogin, user.WebAuthnID())
+
+	observer.recordParsed(session, parsedResponse)
+
+	return observer.finishLogin(webauthn.validateUserLogin(ctx, observer.trace, user, session, parsedResponse))
 }
 
 // ValidateDiscoverableLogin is an overloaded version of ValidateLogin that allows for discoverable credentials.
 func (webauthn *WebAuthn) ValidateDiscoverableLogin(handler DiscoverableUserHandler, session SessionData, parsedResponse *protocol.ParsedCredentialAssertionData) (*Credential, error) {
+	return webauthn.ValidateDiscoverableLoginCtx(context.Background(), handler, session, parsedResponse)
+}
+
+// ValidateDiscoverableLoginCtx is the same as ValidateDiscoverableLogin except it accepts a context.Context.
+func (webauthn *WebAuthn) ValidateDiscoverableLoginCtx(ctx context.Context, handler DiscoverableUserHandler, session SessionData, parsedResponse *protocol.ParsedCredentialAssertionData) (*Credential, error) {
+	return loginCredential(webauthn.ValidateDiscoverableLoginResultCtx(ctx, handler, session, parsedResponse))
+}
+
+// ValidateDiscoverableLoginResult is the same as ValidateDiscoverableLogin except it returns the LoginResult of the
+// login. See FinishLoginResult.
+func (webauthn *WebAuthn) ValidateDiscoverableLoginResult(handler DiscoverableUserHandler, session SessionData, parsedResponse *protocol.ParsedCredentialAssertionData) (*LoginResult, error) {
+	return webauthn.ValidateDiscoverableLoginResultCtx(context.Background(), handler, session, parsedResponse)
+}
+
+// ValidateDiscoverableLoginResultCtx is the same as ValidateDiscoverableLoginResult except it accepts a context.Context.
+func (webauthn *WebAuthn) ValidateDiscoverableLoginResultCtx(ctx context.Context, handler DiscoverableUserHandler, session SessionData, parsedResponse *protocol.ParsedCredentialAssertionData) (*LoginResult, error) {
+	ctx, observer := webauthn.startCeremony(ctx, CeremonyFinishDiscoverableLogin, parsedResponse.Response.UserHandle)
+
+	observer.recordParsed(session, parsedResponse)
+
+	return observer.finishLogin(webauthn.validateDiscoverableLogin(ctx, observer.trace, handler, session, parsedResponse))
+}
+
+func (webauthn *WebAuthn) validateUserLogin(ctx context.Context, trace *protocol.VerificationTrace, user User, session SessionData, parsedResponse *protocol.ParsedCredentialAssertionData) (*LoginResult, error) {
+	err := verifySession(user.WebAuthnID(), session, webauthn.config.now(), webauthn.config.Timeouts.Login.Grace)
+
+	if err = trace.Step(protocol.VerificationStepSession, err, map[string]string{"user_id_hash": auditHash(user.WebAuthnID())}); err != nil {
+		return nil, err
+	}
+
+	return webauthn.validateLogin(ctx, trace, user, session, parsedResponse)
+}
+
+func (webauthn *WebAuthn) validateDiscoverableLogin(ctx context.Context, trace *protocol.VerificationTrace, handler DiscoverableUserHandler, session SessionData, parsedResponse *protocol.ParsedCredentialAssertionData) (*LoginResult, error) {
+	var err error
+
 	if session.UserID != nil {
-		return nil, protocol.ErrBadRequest.WithDetails("Session was not initiated as a client-side discoverable login")
+		err = protocol.ErrBadRequest.WithCode(protocol.CodeSessionNotDiscoverable).WithDetails("Session was not initiated as a client-side discoverable login")
+	} else {
+		err = verifySessionExpiry(session, webauthn.config.now(), webauthn.config.Timeouts.Login.Grace)
+	}
+
+	if err = trace.Step(protocol.VerificationStepSession, err, nil); err != nil {
+		return nil, err
 	}
 
+	user, err := lookupDiscoverableUser(handler, parsedResponse)
+
+	trace.Record(protocol.VerificationStepUser, err, map[string]string{"user_handle_hash": auditHash(parsedResponse.Response.UserHandle)})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return webauthn.validateLogin(ctx, trace, user, session, parsedResponse)
+}
+
+func lookupDiscoverableUser(handler DiscoverableUserHandler, parsedResponse *protocol.ParsedCredentialAssertionData) (User, error) {
 	if parsedResponse.Response.UserHandle == nil {
-		return nil, protocol.ErrBadRequest.WithDetails("Client-side Discoverable Assertion was attempted with a blank User Handle")
+		return nil, protocol.ErrBadRequest.WithCode(protocol.CodeUserHandleMissing).WithDetails("Client-side Discoverable Assertion was attempted with a blank User Handle")
 	}
 
 	user, err := handler(parsedResponse.RawID, parsedResponse.Response.UserHandle)
 	if err != nil {
-		return nil, protocol.ErrBadRequest.WithDetails(fmt.Sprintf("Failed to lookup Client-side Discoverable Credential: %s", err))
+		return nil, protocol.ErrBadRequest.WithCode(protocol.CodeUserNotFound).WithDetails(fmt.Sprintf("Failed to lookup Client-side Discoverable Credential: %s", err))
 	}
 
-	return webauthn.validateLogin(user, session, parsedResponse)
+	return user, nil
 }
 
-// ValidateLogin takes a parsed response and validates it against the user credentials and session data.
-func (webauthn *WebAuthn) validateLogin(user User, session SessionData, parsedResponse *protocol.ParsedCredentialAssertionData) (*Credential, error) {
+func (webauthn *WebAuthn) validateLogin(ctx context.Context, trace *protocol.VerificationTrace, user User, session SessionData, parsedResponse *protocol.ParsedCredentialAssertionData) (*LoginResult, error) {
+	loginCredential, err := lookupLoginCredential(user, session, parsedResponse)
+
+	if err == nil {
+		err = verifyRecoveryCredential(session, loginCredential)
+	}
+
+	if err == nil {
+		err = verifyCredentialTransports(session, loginCredential, parsedResponse.AuthenticatorAttachment)
+	}
+
+	trace.Record(protocol.VerificationStepCredential, err, map[string]string{"credential_id": base64.RawURLEncoding.EncodeToString(parsedResponse.RawID)})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = webauthn.verifyAlgorithm(trace, loginCredential.PublicKey); err != nil {
+		return nil, err
+	}
+
+	if err = webauthn.verifyBlocklist(ctx, trace, loginCredential.ID, loginCredential.AttestationCertificates); err != nil {
+		return nil, err
+	}
+
+	if hook := webauthn.config.LoginHooks.PreVerify; hook != nil {
+		if err = runPolicyHook(trace, protocol.VerificationStepPreVerifyPolicy, func() error { return hook(ctx, user, parsedResponse) }); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = webauthn.verifyChallengeBinding(ctx, trace, &parsedResponse.Response.CollectedClientData); err != nil {
+		return nil, err
+	}
+
+	if err = webauthn.verifyTokenBinding(ctx, trace, &parsedResponse.Response.CollectedClientData); err != nil {
+		return nil, err
+	}
+
+	if err = webauthn.verifyTopOrigin(trace, &parsedResponse.Response.CollectedClientData); err != nil {
+		return nil, err
+	}
+
+	shouldVerifyUser := loginCredential.userVerificationRequired(session)
+
+	rpID := webauthn.config.RPID
+	rpOrigins := webauthn.config.RPOrigins
+
+	appID, err := parsedResponse.GetAppID(session.Extensions, loginCredential.AttestationType)
+
+	trace.Record(protocol.VerificationStepAppID, err, map[string]string{"appid": appID})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	originVerifier := webauthn.config.originVerifier()
+
+	// Handle steps 4 through 16.
+	var validError error
+
+	if verifier := webauthn.config.AssertionVerifier; verifier != nil {
+		validError = parsedResponse.VerifyWithSignatureVerifier(trace, session.Challenge, rpID, rpOrigins, originVerifier, appID, shouldVerifyUser, func(signedData, signature []byte) error {
+			return verifier.VerifyAssertion(ctx, AssertionSignature{Credential: loginCredential, SignedData: signedData, Signature: signature, AppID: appID != ""})
+		})
+	} else {
+		validError = parsedResponse.VerifyWithTrace(trace, session.Challenge, rpID, rpOrigins, originVerifier, appID, shouldVerifyUser, loginCredential.PublicKey)
+	}
+
+	if validError != nil {
+		return nil, validError
+	}
+
+	if webauthn.config.PinCredentialOrigins {
+		if origins := loginCredential.pinnedOrigins(); origins != nil {
+			err = protocol.VerifyOriginWith(originVerifier, &parsedResponse.Response.CollectedClientData, origins)
+
+			if err = trace.Step(protocol.VerificationStepCredentialOrigin, err, map[string]string{"origin": loginCredential.Origin}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err = webauthn.verifyBackupEligibility(trace, loginCredential, parsedResponse.Response.AuthenticatorData.Flags); err != nil {
+		return nil, err
+	}
+
+	// Handle step 17.
+	var counterErr error
+
+	counter := parsedResponse.Response.AuthenticatorData.Counter
+
+	anomaly := loginCredential.Authenticator.counterAnomaly(counter)
+
+	if anomaly == CounterAnomalyRegressed {
+		if !trace.IsDiagnostic() {
+			webauthn.emit(Event{Type: EventCounterRegressed, UserID: user.WebAuthnID(), Credential: &loginCredential})
+		}
+
+		if webauthn.config.CounterPolicy == CounterPolicyReject {
+			counterErr = protocol.ErrVerification.
+				WithCode(protocol.CodeCounterRegressed).
+				WithDetails("Signature counter did not increase").
+				WithInfo(fmt.Sprintf("Stored: %d, Received: %d", loginCredential.Authenticator.SignCount, counter))
+		}
+	}
+
+	if !trace.IsDiagnostic() {
+		webauthn.observeCounter(CounterObservation{
+			Anomaly:  anomaly,
+			Rejected: counterErr != nil,
+			AAGUID:   loginCredential.Authenticator.AAGUID,
+			Stored:   loginCredential.Authenticator.SignCount,
+			Received: counter,
+		})
+	}
+
+	trace.Record(protocol.VerificationStepCounter, counterErr, map[string]string{
+		"stored_counter":   strconv.FormatUint(uint64(loginCredential.Authenticator.SignCount), 10),
+		"received_counter": strconv.FormatUint(uint64(counter), 10),
+	})
+
+	if counterErr != nil {
+		return nil, counterErr
+	}
+
+	loginCredential.Authenticator.UpdateCounter(counter)
+
+	backedUp := loginCredential.Flags.BackupState
+
+	// Update flags from response data.
+	loginCredential.Flags.UserPresent = parsedResponse.Response.AuthenticatorData.Flags.HasUserPresent()
+	loginCredential.Flags.UserVerified = parsedResponse.Response.AuthenticatorData.Flags.HasUserVerified()
+	loginCredential.Flags.BackupEligible = parsedResponse.Response.AuthenticatorData.Flags.HasBackupEligible()
+	loginCredential.Flags.BackupState = parsedResponse.Response.AuthenticatorData.Flags.HasBackupState()
+
+	result := &LoginResult{
+		User:                    user,
+		Credential:              &loginCredential,
+		ParsedResponse:          parsedResponse,
+		CounterAnomaly:          anomaly,
+		VerifiedAt:              webauthn.config.now(),
+		UserVerificationMethods: userVerificationMethods(parsedResponse),
+		Assurance:               loginAssurance(session, loginCredential, parsedResponse),
+	}
+
+	result.Authenticator, _ = webauthn.AuthenticatorName(ctx, loginCredential.Authenticator.AAGUID)
+
+	if hook := webauthn.config.LoginHooks.Connection; hook != nil {
+		connection, _ := ConnectionInfoFromContext(ctx)
+
+		if err = runPolicyHook(trace, protocol.VerificationStepConnectionPolicy, func() error { return hook(ctx, connection, result) }); err != nil {
+			return nil, err
+		}
+	}
+
+	if hook := webauthn.config.LoginHooks.PostVerify; hook != nil {
+		if err = runPolicyHook(trace, protocol.VerificationStepPostVerifyPolicy, func() error { return hook(ctx, result) }); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = webauthn.consumeChallenge(ctx, trace, session, webauthn.config.Timeouts.Login); err != nil {
+		return nil, err
+	}
+
+	if !trace.IsDiagnostic() {
+		webauthn.notifyBackupState(ctx, user.WebAuthnID(), &loginCredential, backedUp)
+	}
+
+	result.verified = true
+
+	return result, nil
+}
+
+// loginCredential returns the Credential of the LoginResult of a login, for the methods which only return the
+// Credential.
+func loginCredential(result *LoginResult, err error) (*Credential, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Credential, nil
+}
+
+// lookupLoginCredential performs steps 1 through 3 of the assertion verification, returning the Credential of the user
+// which was used.
+func lookupLoginCredential(user User, session SessionData, parsedResponse *protocol.ParsedCredentialAssertionData) (Credential, error) {
 	// Step 1. If the allowCredentials option was given when this authentication ceremony was initiated,
 	// verify that credential.id identifies one of the public key credentials that were listed in
 	// allowCredentials.
@@ -218,7 +635,7 @@ func (webauthn *WebAuthn) validateLogin(user User, session SessionData, parsedRe
 		}
 
 		if !credentialsOwned {
-			return nil, protocol.ErrBadRequest.WithDetails("User does not own all credentials from the allowedCredentialList")
+			return Credential{}, protocol.ErrBadRequest.WithCode(protocol.CodeCredentialNotAllowed).WithDetails("User does not own all credentials from the allowedCredentialList")
 		}
 
 		for _, allowedCredentialID := range session.AllowedCredentialIDs {
@@ -230,7 +647,7 @@ func (webauthn *WebAuthn) validateLogin(user User, session SessionData, parsedRe
 		}
 
 		if !credentialFound {
-			return nil, protocol.ErrBadRequest.WithDetails("User does not own the credential returned")
+			return Credential{}, protocol.ErrBadRequest.WithCode(protocol.CodeCredentialNotAllowed).WithDetails("User does not own the credential returned")
 		}
 	}
 
@@ -242,7 +659,7 @@ func (webauthn *WebAuthn) validateLogin(user User, session SessionData, parsedRe
 	userHandle := parsedResponse.Response.UserHandle
 	if len(userHandle) > 0 {
 		if !bytes.Equal(userHandle, user.WebAuthnID()) {
-			return nil, protocol.ErrBadRequest.WithDetails("userHandle and User ID do not match")
+			return Credential{}, protocol.ErrBadRequest.WithCode(protocol.CodeUserHandleMismatch).WithDetails("userHandle and User ID do not match")
 		}
 	}
 
@@ -262,34 +679,8 @@ func (webauthn *WebAuthn) validateLogin(user User, session SessionData, parsedRe
 	}
 
 	if !credentialFound {
-		return nil, protocol.ErrBadRequest.WithDetails("Unable to find the credential for the returned credential ID")
-	}
-
-	shouldVerifyUser := session.UserVerification == protocol.VerificationRequired
-
-	rpID := webauthn.Config.RPID
-	rpOrigins := webauthn.Config.RPOrigins
-
-	appID, err := parsedResponse.GetAppID(session.Extensions, loginCredential.AttestationType)
-	if err != nil {
-		return nil, err
-	}
-
-	// Handle steps 4 through 16.
-	validError := parsedResponse.Verify(session.Challenge, rpID, rpOrigins, appID, shouldVerifyUser, loginCredential.PublicKey)
-	if validError != nil {
-		return nil, validError
+		return loginCredential, protocol.ErrBadRequest.WithCode(protocol.CodeCredentialNotFound).WithDetails("Unable to find the credential for the returned credential ID")
 	}
 
-	// Handle step 17.
-	loginCredential.Authenticator.UpdateCounter(parsedResponse.Response.AuthenticatorData.Counter)
-
-	// TODO: The backup eligible flag shouldn't change. Should decide if we want to error if it does.
-	// Update flags from response data.
-	loginCredential.Flags.UserPresent = parsedResponse.Response.AuthenticatorData.Flags.HasUserPresent()
-	loginCredential.Flags.UserVerified = parsedResponse.Response.AuthenticatorData.Flags.HasUserVerified()
-	loginCredential.Flags.BackupEligible = parsedResponse.Response.AuthenticatorData.Flags.HasBackupEligible()
-	loginCredential.Flags.BackupState = parsedResponse.Response.AuthenticatorData.Flags.HasBackupState()
-
-	return &loginCredential, nil
+	return loginCredential, nil
 }
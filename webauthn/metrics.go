@@ -0,0 +1,198 @@
+package webauthn
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/tracing"
+)
+
+// Ceremony represents an individual step of a WebAuthn ceremony which is reported to the MetricsSink.
+type Ceremony string
+
+const (
+	// CeremonyBeginRegistration is reported by BeginRegistration.
+	CeremonyBeginRegistration Ceremony = "begin_registration"
+
+	// CeremonyFinishRegistration is reported by FinishRegistration and CreateCredential.
+	CeremonyFinishRegistration Ceremony = "finish_registration"
+
+	// CeremonyFinishRegistrationDeferred is reported by FinishRegistrationDeferred and CreateCredentialDeferred.
+	CeremonyFinishRegistrationDeferred Ceremony = "finish_registration_deferred"
+
+	// CeremonyVerifyAttestation is reported by DeferredAttestation.Verify.
+	CeremonyVerifyAttestation Ceremony = "verify_attestation"
+
+	// CeremonyBeginLogin is reported by BeginLogin.
+	CeremonyBeginLogin Ceremony = "begin_login"
+
+	// CeremonyFinishLogin is reported by FinishLogin and ValidateLogin.
+	CeremonyFinishLogin Ceremony = "finish_login"
+
+	// CeremonyBeginDiscoverableLogin is reported by BeginDiscoverableLogin.
+	CeremonyBeginDiscoverableLogin Ceremony = "begin_discoverable_login"
+
+	// CeremonyFinishDiscoverableLogin is reported by FinishDiscoverableLogin and ValidateDiscoverableLogin.
+	CeremonyFinishDiscoverableLogin Ceremony = "finish_discoverable_login"
+)
+
+// CeremonyOutcome describes the outcome of a single Ceremony.
+type CeremonyOutcome struct {
+	// Ceremony is the ceremony step this outcome relates to.
+	Ceremony Ceremony
+
+	// Success is true if the ceremony step completed without an error.
+	Success bool
+
+	// Err is the original error returned by the ceremony step if it failed. It is never redacted.
+	Err error
+
+	// Format is the attestation format of the credential. It is only available for successful finish ceremonies.
+	Format string
+
+	// Algorithm is the COSE algorithm of the credential public key. It is only available for successful finish
+	// ceremonies.
+	Algorithm webauthncose.COSEAlgorithmIdentifier
+
+	// Duration is the time taken to perform the ceremony step.
+	Duration time.Duration
+}
+
+// MetricsSink receives the outcome of every ceremony step performed by the WebAuthn methods. Implementations must be
+// safe for concurrent use and should not block. Implementations may also implement CounterMetricsSink to receive the
+// signature counter evaluated during every login.
+type MetricsSink interface {
+	ObserveCeremony(outcome CeremonyOutcome)
+}
+
+// ceremonyObserver reports the outcome of a single ceremony step to the MetricsSink and the TracerProvider.
+type ceremonyObserver struct {
+	webauthn *WebAuthn
+	ceremony Ceremony
+	userID   []byte
+	start    time.Time
+	span     tracing.Span
+	trace    *protocol.VerificationTrace
+	session  *SessionData
+	body     []byte
+}
+
+func (webauthn *WebAuthn) startCeremony(ctx context.Context, ceremony Ceremony, userID []byte) (context.Context, *ceremonyObserver) {
+	observer := &ceremonyObserver{
+		webauthn: webauthn,
+		ceremony: ceremony,
+		userID:   userID,
+		start:    time.Now(),
+	}
+
+	var (
+		provider tracing.TracerProvider
+		rpID     string
+	)
+
+	if webauthn.config != nil {
+		provider, rpID = webauthn.config.TracerProvider, webauthn.config.RPID
+	}
+
+	if webauthn.config != nil && (webauthn.config.AuditHook != nil || webauthn.config.Recorder != nil) {
+		observer.trace = &protocol.VerificationTrace{}
+	}
+
+	ctx, observer.span = tracing.Start(ctx, provider, "webauthn."+string(ceremony), tracing.String(tracing.AttributeRPID, rpID))
+
+	return ctx, observer
+}
+
+func (o *ceremonyObserver) observe(credential *Credential, err error) {
+	outcome := CeremonyOutcome{
+		Ceremony: o.ceremony,
+		Success:  err == nil,
+		Err:      err,
+		Duration: time.Since(o.start),
+	}
+
+	if credential != nil {
+		outcome.Format = credential.AttestationType
+		outcome.Algorithm = credentialAlgorithm(credential.PublicKey)
+
+		o.span.SetAttributes(credentialAttributes(credential)...)
+	}
+
+	tracing.End(o.span, err)
+
+	if o.webauthn.config != nil && o.webauthn.config.MetricsSink != nil {
+		o.webauthn.config.MetricsSink.ObserveCeremony(outcome)
+	}
+
+	success, failure := ceremonyEvents(o.ceremony)
+
+	switch {
+	case err == nil && success != "":
+		o.webauthn.emit(Event{Type: success, UserID: o.userID, Credential: credential})
+	case err != nil && failure != "":
+		o.webauthn.emit(Event{Type: failure, UserID: o.userID, Err: err})
+	}
+}
+
+// finish reports the outcome of a finish ceremony step and handles the error if one occurred.
+func (o *ceremonyObserver) finish(credential *Credential, err error) (*Credential, error) {
+	o.observe(credential, err)
+
+	if o.trace != nil && o.webauthn.config.AuditHook != nil {
+		o.webauthn.config.AuditHook(newAuditRecord(o, err))
+	}
+
+	if err != nil && o.session != nil {
+		o.webauthn.config.Recorder.Record(newRecording(o, err))
+	}
+
+	if err != nil {
+		return nil, o.webauthn.handleError(err)
+	}
+
+	return credential, nil
+}
+
+// finishLogin is the same as finish except it reports the outcome of a login ceremony step with its LoginResult.
+func (o *ceremonyObserver) finishLogin(result *LoginResult, err error) (*LoginResult, error) {
+	if _, err = o.finish(loginCredential(result, err)); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// requestContext returns the context of the *http.Request if it's available.
+func requestContext(r *http.Request) context.Context {
+	if r == nil {
+		return context.Background()
+	}
+
+	return r.Context()
+}
+
+func credentialAttributes(credential *Credential) []tracing.Attribute {
+	attributes := []tracing.Attribute{tracing.String(tracing.AttributeAttestationFormat, credential.AttestationType)}
+
+	if aaguid, err := uuid.FromBytes(credential.Authenticator.AAGUID); err == nil {
+		attributes = append(attributes, tracing.String(tracing.AttributeAAGUID, aaguid.String()))
+	}
+
+	return attributes
+}
+
+func credentialAlgorithm(publicKey []byte) webauthncose.COSEAlgorithmIdentifier {
+	var key webauthncose.PublicKeyData
+
+	if err := webauthncbor.Unmarshal(publicKey, &key); err != nil {
+		return 0
+	}
+
+	return webauthncose.COSEAlgorithmIdentifier(key.Algorithm)
+}
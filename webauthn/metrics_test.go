@@ -0,0 +1,123 @@
+package webauthn
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/tracing"
+)
+
+type testMetricsSink struct {
+	outcomes []CeremonyOutcome
+}
+
+func (s *testMetricsSink) ObserveCeremony(outcome CeremonyOutcome) {
+	s.outcomes = append(s.outcomes, outcome)
+}
+
+func TestWebAuthn_MetricsSink(t *testing.T) {
+	sink := &testMetricsSink{}
+
+	webauthn, err := New(&Config{
+		RPDisplayName: "Example",
+		RPID:          "example.com",
+		RPOrigins:     []string{"https://example.com"},
+		MetricsSink:   sink,
+	})
+	require.NoError(t, err)
+
+	user := &defaultUser{id: []byte("123")}
+
+	_, _, err = webauthn.BeginRegistration(user)
+	require.NoError(t, err)
+
+	_, _, err = webauthn.BeginLogin(user)
+	require.Error(t, err)
+
+	_, err = webauthn.ValidateLogin(user, SessionData{UserID: []byte("ABC")}, nil)
+	require.Error(t, err)
+
+	require.Len(t, sink.outcomes, 3)
+
+	assert.Equal(t, CeremonyBeginRegistration, sink.outcomes[0].Ceremony)
+	assert.True(t, sink.outcomes[0].Success)
+	assert.NoError(t, sink.outcomes[0].Err)
+
+	assert.Equal(t, CeremonyBeginLogin, sink.outcomes[1].Ceremony)
+	assert.False(t, sink.outcomes[1].Success)
+	assert.Equal(t, protocol.CodeNoCredentials, sink.outcomes[1].Err.(*protocol.Error).Code)
+
+	assert.Equal(t, CeremonyFinishLogin, sink.outcomes[2].Ceremony)
+	assert.False(t, sink.outcomes[2].Success)
+	assert.Equal(t, protocol.CodeUserSessionMismatch, sink.outcomes[2].Err.(*protocol.Error).Code)
+}
+
+type testTracerProvider struct {
+	spans []*testSpan
+}
+
+func (p *testTracerProvider) Tracer(_ string) tracing.Tracer {
+	return p
+}
+
+func (p *testTracerProvider) Start(ctx context.Context, spanName string) (context.Context, tracing.Span) {
+	span := &testSpan{name: spanName}
+
+	p.spans = append(p.spans, span)
+
+	return ctx, span
+}
+
+type testSpan struct {
+	name       string
+	attributes []tracing.Attribute
+	err        error
+	ended      bool
+}
+
+func (s *testSpan) SetAttributes(attributes ...tracing.Attribute) {
+	s.attributes = append(s.attributes, attributes...)
+}
+
+func (s *testSpan) RecordError(err error) {
+	s.err = err
+}
+
+func (s *testSpan) End() {
+	s.ended = true
+}
+
+func TestWebAuthn_TracerProvider(t *testing.T) {
+	provider := &testTracerProvider{}
+
+	webauthn, err := New(&Config{
+		RPDisplayName:  "Example",
+		RPID:           "example.com",
+		RPOrigins:      []string{"https://example.com"},
+		TracerProvider: provider,
+	})
+	require.NoError(t, err)
+
+	user := &defaultUser{id: []byte("123")}
+
+	_, _, err = webauthn.BeginRegistration(user)
+	require.NoError(t, err)
+
+	_, err = webauthn.ValidateLogin(user, SessionData{UserID: []byte("ABC")}, nil)
+	require.Error(t, err)
+
+	require.Len(t, provider.spans, 2)
+
+	assert.Equal(t, "webauthn.begin_registration", provider.spans[0].name)
+	assert.Equal(t, []tracing.Attribute{{Key: tracing.AttributeRPID, Value: "example.com"}}, provider.spans[0].attributes)
+	assert.NoError(t, provider.spans[0].err)
+	assert.True(t, provider.spans[0].ended)
+
+	assert.Equal(t, "webauthn.finish_login", provider.spans[1].name)
+	assert.Equal(t, err, provider.spans[1].err)
+	assert.True(t, provider.spans[1].ended)
+}
@@ -0,0 +1,64 @@
+package webauthn
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/go-webauthn/webauthn/metadata"
+)
+
+// RegistrationResult is the result of a registration finished with FinishRegistrationWithResult.
+type RegistrationResult struct {
+	// Credential is the newly registered Credential.
+	Credential *Credential
+
+	// Authenticator is the human friendly name and icons of the authenticator model which created the Credential. It's
+	// the zero value if the AAGUID of the authenticator is not known.
+	Authenticator metadata.AuthenticatorName
+}
+
+// FinishRegistrationWithResult is the same as FinishRegistration except it also returns the human friendly name and
+// icons of the authenticator model, looked up with AuthenticatorName, so they can be shown to the user.
+func (webauthn *WebAuthn) FinishRegistrationWithResult(user User, session SessionData, response *http.Request) (*RegistrationResult, error) {
+	return webauthn.FinishRegistrationWithResultCtx(requestContext(response), user, session, response)
+}
+
+// FinishRegistrationWithResultCtx is the same as FinishRegistrationWithResult except the provided context is used
+// instead of the context of the request.
+func (webauthn *WebAuthn) FinishRegistrationWithResultCtx(ctx context.Context, user User, session SessionData, response *http.Request) (*RegistrationResult, error) {
+	credential, err := webauthn.FinishRegistrationCtx(ctx, user, session, response)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RegistrationResult{Credential: credential}
+
+	result.Authenticator, _ = webauthn.AuthenticatorName(ctx, credential.Authenticator.AAGUID)
+
+	return result, nil
+}
+
+// AuthenticatorName returns the human friendly name and icons of the authenticator model with the AAGUID. The
+// AuthenticatorCatalog, or the embedded community list of AAGUIDs if none is configured, is merged with the description
+// and icon of the metadata statement from the AttestationPolicy Metadata provider, or the metadata.DefaultProvider if
+// none is configured.
+func (webauthn *WebAuthn) AuthenticatorName(ctx context.Context, aaguid []byte) (name metadata.AuthenticatorName, ok bool) {
+	id, err := uuid.FromBytes(aaguid)
+	if err != nil {
+		return name, false
+	}
+
+	provider := webauthn.config.attestationPolicy(ctx).Metadata
+	if provider == nil {
+		provider = metadata.DefaultProvider
+	}
+
+	catalog := webauthn.config.AuthenticatorCatalog
+	if catalog == nil {
+		catalog = metadata.DefaultCatalog()
+	}
+
+	return catalog.LookupWithProvider(provider, id)
+}
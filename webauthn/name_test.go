@@ -0,0 +1,134 @@
+package webauthn_test
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/metadata"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+	"github.com/go-webauthn/webauthn/webauthntest/webauthnmock"
+)
+
+func TestWebAuthn_FinishRegistrationWithResult(t *testing.T) {
+	testCases := []struct {
+		name     string
+		aaguid   uuid.UUID
+		expected string
+	}{
+		{"ShouldReturnEmbeddedName", uuid.MustParse("ea9b8d66-4d01-1d21-3ce4-b6b48cb575d4"), "Google Password Manager"},
+		{"ShouldReturnMetadataDescription", uuid.MustParse("7b0c0d9b-7e5c-4b7a-9d4f-2c1a0e6e9c11"), "Example Authenticator"},
+		{"ShouldReturnEmptyNameForUnknown", uuid.Nil, ""},
+	}
+
+	provider := &webauthnmock.MetadataProviderMock{
+		LookupByAAGUIDFunc: func(id uuid.UUID) (metadata.MetadataBLOBPayloadEntry, bool) {
+			if id != uuid.MustParse("7b0c0d9b-7e5c-4b7a-9d4f-2c1a0e6e9c11") {
+				return metadata.MetadataBLOBPayloadEntry{}, false
+			}
+
+			return metadata.MetadataBLOBPayloadEntry{
+				AaGUID:            id.String(),
+				MetadataStatement: metadata.MetadataStatement{Description: "Example Authenticator"},
+			}, true
+		},
+		LookupByCertificateFunc: func(_ *x509.Certificate) (metadata.MetadataBLOBPayloadEntry, bool) {
+			return metadata.MetadataBLOBPayloadEntry{}, false
+		},
+	}
+
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:              "example.com",
+		RPDisplayName:     "Example",
+		RPOrigins:         []string{"https://example.com"},
+		AttestationPolicy: protocol.AttestationPolicy{Metadata: provider},
+	})
+	require.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			user := &bytesUser{}
+
+			creation, session, err := w.BeginRegistration(user)
+			require.NoError(t, err)
+
+			attestation, _, err := (&webauthntest.Authenticator{AAGUID: tc.aaguid}).CreateCredential(creation.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err := webauthntest.NewRequest(attestation)
+			require.NoError(t, err)
+
+			result, err := w.FinishRegistrationWithResult(user, *session, r)
+			require.NoError(t, err)
+			require.NotNil(t, result.Credential)
+
+			assert.Equal(t, tc.expected, result.Authenticator.Name)
+		})
+	}
+
+	_, ok := w.AuthenticatorName(context.Background(), []byte("invalid"))
+	assert.False(t, ok)
+}
+
+func TestWebAuthn_AuthenticatorCatalog(t *testing.T) {
+	aaguid := uuid.MustParse("7b0c0d9b-7e5c-4b7a-9d4f-2c1a0e6e9c11")
+
+	var authenticator metadata.AuthenticatorName
+
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+		AuthenticatorCatalog: metadata.DefaultCatalog().With(metadata.CatalogEntry{
+			AAGUID:            aaguid,
+			AuthenticatorName: metadata.AuthenticatorName{Name: "Corporate Key"},
+		}),
+		LoginHooks: webauthn.LoginHooks{
+			PostVerify: func(ctx context.Context, result *webauthn.LoginResult) error {
+				authenticator = result.Authenticator
+
+				return nil
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	user := &bytesUser{}
+	device := &webauthntest.Authenticator{AAGUID: aaguid}
+
+	creation, session, err := w.BeginRegistration(user)
+	require.NoError(t, err)
+
+	attestation, _, err := device.CreateCredential(creation.Response, "https://example.com")
+	require.NoError(t, err)
+
+	r, err := webauthntest.NewRequest(attestation)
+	require.NoError(t, err)
+
+	result, err := w.FinishRegistrationWithResult(user, *session, r)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Corporate Key", result.Authenticator.Name)
+
+	user.credentials = append(user.credentials, *result.Credential)
+
+	assertion, session, err := w.BeginLogin(user)
+	require.NoError(t, err)
+
+	response, err := device.GetAssertion(assertion.Response, "https://example.com")
+	require.NoError(t, err)
+
+	r, err = webauthntest.NewRequest(response)
+	require.NoError(t, err)
+
+	_, err = w.FinishLogin(user, *session, r)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Corporate Key", authenticator.Name)
+}
@@ -0,0 +1,121 @@
+package webauthn
+
+import (
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// Authentication Method Reference values of a login which are registered by RFC 8176.
+//
+// See: https://www.rfc-editor.org/rfc/rfc8176.html#section-2
+const (
+	// AMRHardwareKey is the proof-of-possession of a hardware-secured key.
+	AMRHardwareKey = "hwk"
+
+	// AMRSoftwareKey is the proof-of-possession of a software-secured key.
+	AMRSoftwareKey = "swk"
+
+	// AMRUserPresence is the test of user presence.
+	AMRUserPresence = "user"
+
+	// AMRPIN is a personal identification number or pattern, which is the closest registered value to the user
+	// verification of an authenticator as the method of user verification is not known to the Relying Party.
+	AMRPIN = "pin"
+
+	// AMRMultiFactor is the use of multiple authentication factors, which is the case when the user was verified.
+	AMRMultiFactor = "mfa"
+)
+
+// Authentication Context Class Reference values of the OpenID Extended Authentication Profile.
+//
+// See: https://openid.net/specs/openid-eap-acr-values-1_0.html
+const (
+	// ACRPhishingResistant is a phishing-resistant authentication.
+	ACRPhishingResistant = "phr"
+
+	// ACRPhishingResistantHardware is a phishing-resistant authentication with a hardware-protected key.
+	ACRPhishingResistantHardware = "phrh"
+)
+
+// AuthenticationContext is the authentication context of a login for an OpenID Connect ID Token.
+type AuthenticationContext struct {
+	// ACR is the value of the acr claim.
+	ACR string `json:"acr"`
+
+	// AMR are the values of the amr claim.
+	AMR []string `json:"amr"`
+}
+
+// AuthenticationContextMapper derives the AuthenticationContext from the LoginResult, which is useful for identity
+// providers using this library. The key is considered hardware-backed if the credential is not backup eligible, i.e.
+// it's bound to the authenticator, and a software-secured key otherwise as it's synced by the passkey provider. The
+// zero value uses the ACR values of the OpenID Extended Authentication Profile.
+type AuthenticationContextMapper struct {
+	// PhishingResistantACR is the ACR of a login which did not verify the user with a hardware-backed key. The default
+	// is ACRPhishingResistant.
+	PhishingResistantACR string
+
+	// HardwareACR is the ACR of a login which verified the user with a hardware-backed key. The default is
+	// ACRPhishingResistantHardware.
+	HardwareACR string
+
+	// RequireCrossPlatformHardware only considers a key hardware-backed if the authenticator attachment reported by the
+	// response is cross-platform, such as for deployments which only trust roaming security keys.
+	RequireCrossPlatformHardware bool
+}
+
+// Map returns the AuthenticationContext of the LoginResult.
+func (m AuthenticationContextMapper) Map(result *LoginResult) AuthenticationContext {
+	flags := result.Credential.Flags
+
+	hardware := isHardwareBacked(result, m.RequireCrossPlatformHardware)
+
+	authContext := AuthenticationContext{ACR: m.PhishingResistantACR}
+
+	if authContext.ACR == "" {
+		authContext.ACR = ACRPhishingResistant
+	}
+
+	if hardware {
+		authContext.AMR = append(authContext.AMR, AMRHardwareKey)
+	} else {
+		authContext.AMR = append(authContext.AMR, AMRSoftwareKey)
+	}
+
+	if flags.UserPresent {
+		authContext.AMR = append(authContext.AMR, AMRUserPresence)
+	}
+
+	if flags.UserVerified {
+		authContext.AMR = append(authContext.AMR, AMRPIN, AMRMultiFactor)
+
+		if hardware {
+			authContext.ACR = m.HardwareACR
+
+			if authContext.ACR == "" {
+				authContext.ACR = ACRPhishingResistantHardware
+			}
+		}
+	}
+
+	return authContext
+}
+
+// isHardwareBacked returns true if the credential of the LoginResult is bound to the authenticator, and optionally if
+// the authenticator attachment reported by the response is cross-platform.
+func isHardwareBacked(result *LoginResult, requireCrossPlatform bool) bool {
+	if result.Credential.Flags.BackupEligible {
+		return false
+	}
+
+	if requireCrossPlatform && (result.ParsedResponse == nil || result.ParsedResponse.AuthenticatorAttachment != protocol.CrossPlatform) {
+		return false
+	}
+
+	return true
+}
+
+// AuthenticationContext returns the AuthenticationContext of the LoginResult derived with the zero value of the
+// AuthenticationContextMapper.
+func (r *LoginResult) AuthenticationContext() AuthenticationContext {
+	return AuthenticationContextMapper{}.Map(r)
+}
@@ -0,0 +1,73 @@
+package webauthn_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+func TestAuthenticationContextMapper_Map(t *testing.T) {
+	testCases := []struct {
+		name       string
+		mapper     webauthn.AuthenticationContextMapper
+		flags      webauthn.CredentialFlags
+		attachment protocol.AuthenticatorAttachment
+		expected   webauthn.AuthenticationContext
+	}{
+		{
+			"ShouldMapHardwareUserVerified",
+			webauthn.AuthenticationContextMapper{},
+			webauthn.CredentialFlags{UserPresent: true, UserVerified: true},
+			protocol.CrossPlatform,
+			webauthn.AuthenticationContext{ACR: webauthn.ACRPhishingResistantHardware, AMR: []string{"hwk", "user", "pin", "mfa"}},
+		},
+		{
+			"ShouldMapHardwareUserPresent",
+			webauthn.AuthenticationContextMapper{},
+			webauthn.CredentialFlags{UserPresent: true},
+			protocol.CrossPlatform,
+			webauthn.AuthenticationContext{ACR: webauthn.ACRPhishingResistant, AMR: []string{"hwk", "user"}},
+		},
+		{
+			"ShouldMapSyncedPasskey",
+			webauthn.AuthenticationContextMapper{},
+			webauthn.CredentialFlags{UserPresent: true, UserVerified: true, BackupEligible: true, BackupState: true},
+			protocol.Platform,
+			webauthn.AuthenticationContext{ACR: webauthn.ACRPhishingResistant, AMR: []string{"swk", "user", "pin", "mfa"}},
+		},
+		{
+			"ShouldMapCustomACR",
+			webauthn.AuthenticationContextMapper{PhishingResistantACR: "urn:example:silver", HardwareACR: "urn:example:gold"},
+			webauthn.CredentialFlags{UserPresent: true, UserVerified: true},
+			protocol.Platform,
+			webauthn.AuthenticationContext{ACR: "urn:example:gold", AMR: []string{"hwk", "user", "pin", "mfa"}},
+		},
+		{
+			"ShouldRequireCrossPlatformHardware",
+			webauthn.AuthenticationContextMapper{RequireCrossPlatformHardware: true},
+			webauthn.CredentialFlags{UserPresent: true, UserVerified: true},
+			protocol.Platform,
+			webauthn.AuthenticationContext{ACR: webauthn.ACRPhishingResistant, AMR: []string{"swk", "user", "pin", "mfa"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := &webauthn.LoginResult{
+				Credential:     &webauthn.Credential{Flags: tc.flags},
+				ParsedResponse: &protocol.ParsedCredentialAssertionData{},
+			}
+
+			result.ParsedResponse.AuthenticatorAttachment = tc.attachment
+
+			assert.Equal(t, tc.expected, tc.mapper.Map(result))
+
+			if tc.mapper == (webauthn.AuthenticationContextMapper{}) {
+				assert.Equal(t, tc.expected, result.AuthenticationContext())
+			}
+		})
+	}
+}
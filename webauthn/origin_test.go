@@ -0,0 +1,158 @@
+package webauthn_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+func TestWebAuthn_PinCredentialOrigins(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pin      bool
+		legacy   bool
+		allowed  []string
+		origin   string
+		expected protocol.ErrorCode
+	}{
+		{"ShouldAllowOtherOriginWithoutPinning", false, false, nil, "https://b.example.com", ""},
+		{"ShouldAllowRegisteredOrigin", true, false, nil, "https://a.example.com", ""},
+		{"ShouldRejectOtherOrigin", true, false, nil, "https://b.example.com", protocol.CodeOriginMismatch},
+		{"ShouldAllowAllowedOrigin", true, false, []string{"https://b.example.com"}, "https://b.example.com", ""},
+		{"ShouldAllowLegacyCredential", true, true, nil, "https://b.example.com", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := webauthn.New(&webauthn.Config{
+				RPID:                 "example.com",
+				RPDisplayName:        "Example",
+				RPOrigins:            []string{"https://a.example.com", "https://b.example.com"},
+				PinCredentialOrigins: tc.pin,
+			})
+			require.NoError(t, err)
+
+			user := &bytesUser{}
+			authenticator := &webauthntest.Authenticator{}
+
+			creation, session, err := w.BeginRegistration(user)
+			require.NoError(t, err)
+
+			attestation, _, err := authenticator.CreateCredential(creation.Response, "https://a.example.com/register")
+			require.NoError(t, err)
+
+			r, err := webauthntest.NewRequest(attestation)
+			require.NoError(t, err)
+
+			credential, err := w.FinishRegistration(user, *session, r)
+			require.NoError(t, err)
+
+			assert.Equal(t, "https://a.example.com", credential.Origin)
+
+			credential.AllowedOrigins = tc.allowed
+
+			if tc.legacy {
+				credential.Origin = ""
+			}
+
+			user.credentials = append(user.credentials, *credential)
+
+			assertion, session, err := w.BeginLogin(user)
+			require.NoError(t, err)
+
+			response, err := authenticator.GetAssertion(assertion.Response, tc.origin)
+			require.NoError(t, err)
+
+			r, err = webauthntest.NewRequest(response)
+			require.NoError(t, err)
+
+			_, err = w.FinishLogin(user, *session, r)
+
+			if tc.expected == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			require.IsType(t, &protocol.Error{}, err)
+			assert.Equal(t, tc.expected, err.(*protocol.Error).Code)
+			assert.Equal(t, protocol.FailureReasonBadOrigin, protocol.GetFailureReason(err))
+		})
+	}
+}
+
+func TestWebAuthn_OriginVerifier(t *testing.T) {
+	// The proxy rewrites the scheme as the TLS connection is terminated before the application.
+	verifier := protocol.OriginVerifierFunc(func(c *protocol.CollectedClientData, rpOrigins []string) error {
+		c2 := *c
+		c2.Origin = strings.Replace(c.Origin, "http://", "https://", 1)
+
+		return protocol.VerifyOrigin(&c2, rpOrigins)
+	})
+
+	config := &webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+	}
+
+	strict, err := webauthn.New(config)
+	require.NoError(t, err)
+
+	config.OriginVerifier = verifier
+
+	w, err := webauthn.New(config)
+	require.NoError(t, err)
+
+	user := &bytesUser{}
+	authenticator := &webauthntest.Authenticator{}
+
+	creation, session, err := w.BeginRegistration(user)
+	require.NoError(t, err)
+
+	attestation, _, err := authenticator.CreateCredential(creation.Response, "http://example.com")
+	require.NoError(t, err)
+
+	r, err := webauthntest.NewRequest(attestation)
+	require.NoError(t, err)
+
+	_, err = strict.FinishRegistration(user, *session, r)
+
+	require.IsType(t, &protocol.Error{}, err)
+	assert.Equal(t, protocol.CodeOriginMismatch, err.(*protocol.Error).Code)
+
+	r, err = webauthntest.NewRequest(attestation)
+	require.NoError(t, err)
+
+	credential, err := w.FinishRegistration(user, *session, r)
+	require.NoError(t, err)
+
+	user.credentials = append(user.credentials, *credential)
+
+	assertion, session, err := w.BeginLogin(user)
+	require.NoError(t, err)
+
+	response, err := authenticator.GetAssertion(assertion.Response, "http://example.com")
+	require.NoError(t, err)
+
+	r, err = webauthntest.NewRequest(response)
+	require.NoError(t, err)
+
+	parsedResponse, err := protocol.ParseCredentialRequestResponse(r)
+	require.NoError(t, err)
+
+	_, err = w.ValidateLogin(user, *session, parsedResponse)
+	assert.NoError(t, err)
+
+	// The verifier is configuration of the instance, so it must not be retained by the parsed response.
+	_, err = strict.ValidateLogin(user, *session, parsedResponse)
+
+	require.IsType(t, &protocol.Error{}, err)
+	assert.Equal(t, protocol.CodeOriginMismatch, err.(*protocol.Error).Code)
+}
@@ -0,0 +1,240 @@
+package webauthn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/go-webauthn/webauthn/metadata"
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// ErrPasskeyNotFound is returned by the PasskeyManager when the user has no passkey with the ID.
+var ErrPasskeyNotFound = errors.New("webauthn: passkey not found")
+
+// PasskeyStore is the storage of the users and their passkeys used by the PasskeyManager.
+type PasskeyStore interface {
+	UserStore
+
+	// DeleteCredential deletes the credential with the ID of the user.
+	DeleteCredential(ctx context.Context, user User, credentialID []byte) (err error)
+}
+
+// Passkey is a credential of a user as presented by the PasskeyManager.
+type Passkey struct {
+	// ID of the credential.
+	ID protocol.URLEncodedBase64 `json:"id"`
+
+	// Name of the credential chosen by the user, or the name of the authenticator model if the user didn't choose one.
+	Name string `json:"name"`
+
+	// Authenticator is the human friendly name and icons of the authenticator model which created the credential.
+	Authenticator metadata.AuthenticatorName `json:"authenticator"`
+
+	// Synced is true if the credential is backed up and synced by the passkey provider.
+	Synced bool `json:"synced"`
+
+	// Credential is the underlying Credential.
+	Credential Credential `json:"-"`
+}
+
+// PasskeyManager is a high-level facade over the ceremonies and the storage of the users, their passkeys, and the
+// sessions. It's intended for applications which don't need to customize the ceremonies, and only exchanges JSON with
+// the browser so the protocol types are not needed:
+//
+//	options, err := manager.BeginRegisterPasskey(ctx, "john", sessionID)
+//	// Pass the options to navigator.credentials.create and send the result back.
+//	passkey, err := manager.RegisterPasskey(ctx, "john", sessionID, "Work laptop", response)
+//
+// The ceremonies require a resident key, so every passkey can be used by Authenticate without a username.
+type PasskeyManager struct {
+	webauthn *WebAuthn
+	store    PasskeyStore
+	sessions SessionStore
+}
+
+// NewPasskeyManager returns a PasskeyManager which performs the ceremonies with the *WebAuthn and stores the users,
+// their passkeys, and the sessions in the stores.
+func NewPasskeyManager(webauthn *WebAuthn, store PasskeyStore, sessions SessionStore) *PasskeyManager {
+	return &PasskeyManager{webauthn: webauthn, store: store, sessions: sessions}
+}
+
+// BeginRegisterPasskey begins the registration of a passkey for the user with the name and returns the JSON encoded
+// options for navigator.credentials.create. The session is saved with the session ID.
+func (m *PasskeyManager) BeginRegisterPasskey(ctx context.Context, username, sessionID string) (options []byte, err error) {
+	user, err := m.store.LoadUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	creation, session, err := m.webauthn.BeginRegistrationCtx(ctx, user,
+		WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+		WithExclusions(CredentialDescriptors(user.WebAuthnCredentials())),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = m.sessions.SaveSession(ctx, sessionID, *session); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(creation)
+}
+
+// RegisterPasskey finishes the registration of a passkey for the user with the name with the JSON encoded response of
+// navigator.credentials.create, and saves the passkey with the name. The session is deleted whether the registration
+// succeeds or not.
+func (m *PasskeyManager) RegisterPasskey(ctx context.Context, username, sessionID, name string, response []byte) (passkey *Passkey, err error) {
+	user, err := m.store.LoadUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := m.consumeSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := m.webauthn.FinishRegistrationBytesCtx(ctx, user, session, response)
+	if err != nil {
+		return nil, err
+	}
+
+	credential.Name = name
+
+	if err = m.store.SaveCredential(ctx, user, credential); err != nil {
+		return nil, err
+	}
+
+	return m.passkey(ctx, *credential), nil
+}
+
+// ListPasskeys returns the passkeys of the user with the name.
+func (m *PasskeyManager) ListPasskeys(ctx context.Context, username string) (passkeys []Passkey, err error) {
+	user, err := m.store.LoadUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := user.WebAuthnCredentials()
+
+	passkeys = make([]Passkey, len(credentials))
+
+	for i, credential := range credentials {
+		passkeys[i] = *m.passkey(ctx, credential)
+	}
+
+	return passkeys, nil
+}
+
+// RenamePasskey changes the name of the passkey with the ID of the user with the name.
+func (m *PasskeyManager) RenamePasskey(ctx context.Context, username string, id []byte, name string) (err error) {
+	user, credential, err := m.load(ctx, username, id)
+	if err != nil {
+		return err
+	}
+
+	credential.Name = name
+
+	return m.store.SaveCredential(ctx, user, credential)
+}
+
+// DeletePasskey deletes the passkey with the ID of the user with the name.
+func (m *PasskeyManager) DeletePasskey(ctx context.Context, username string, id []byte) (err error) {
+	user, _, err := m.load(ctx, username, id)
+	if err != nil {
+		return err
+	}
+
+	return m.store.DeleteCredential(ctx, user, id)
+}
+
+// BeginAuthenticate begins the authentication with a passkey and returns the JSON encoded options for
+// navigator.credentials.get. The session is saved with the session ID.
+func (m *PasskeyManager) BeginAuthenticate(ctx context.Context, sessionID string) (options []byte, err error) {
+	assertion, session, err := m.webauthn.BeginDiscoverableLoginCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = m.sessions.SaveSession(ctx, sessionID, *session); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(assertion)
+}
+
+// Authenticate finishes the authentication with the JSON encoded response of navigator.credentials.get and returns
+// the authenticated user and the passkey they used, which is saved with the updated signature counter and flags. The
+// session is deleted whether the authentication succeeds or not.
+func (m *PasskeyManager) Authenticate(ctx context.Context, sessionID string, response []byte) (user User, passkey *Passkey, err error) {
+	session, err := m.consumeSession(ctx, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	handler := func(rawID, userHandle []byte) (User, error) {
+		user, err = m.store.LoadUserByHandle(ctx, userHandle)
+
+		return user, err
+	}
+
+	credential, err := m.webauthn.FinishDiscoverableLoginBytesCtx(ctx, handler, session, response)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = m.store.SaveCredential(ctx, user, credential); err != nil {
+		return nil, nil, err
+	}
+
+	return user, m.passkey(ctx, *credential), nil
+}
+
+func (m *PasskeyManager) consumeSession(ctx context.Context, sessionID string) (session SessionData, err error) {
+	if session, err = m.sessions.LoadSession(ctx, sessionID); err != nil {
+		return session, err
+	}
+
+	if err = m.sessions.DeleteSession(ctx, sessionID); err != nil {
+		return session, err
+	}
+
+	return session, nil
+}
+
+func (m *PasskeyManager) load(ctx context.Context, username string, id []byte) (user User, credential *Credential, err error) {
+	if user, err = m.store.LoadUser(ctx, username); err != nil {
+		return nil, nil, err
+	}
+
+	for _, c := range user.WebAuthnCredentials() {
+		if bytes.Equal(c.ID, id) {
+			c := c
+
+			return user, &c, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("%w: %x", ErrPasskeyNotFound, id)
+}
+
+func (m *PasskeyManager) passkey(ctx context.Context, credential Credential) *Passkey {
+	passkey := &Passkey{
+		ID:         credential.ID,
+		Name:       credential.Name,
+		Synced:     credential.Flags.BackupState,
+		Credential: credential,
+	}
+
+	passkey.Authenticator, _ = m.webauthn.AuthenticatorName(ctx, credential.Authenticator.AAGUID)
+
+	if passkey.Name == "" {
+		passkey.Name = passkey.Authenticator.Name
+	}
+
+	return passkey
+}
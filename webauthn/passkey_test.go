@@ -0,0 +1,135 @@
+package webauthn_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthn/webauthnhttp"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+type passkeyStore struct {
+	user *bytesUser
+}
+
+func (s *passkeyStore) LoadUser(_ context.Context, name string) (webauthn.User, error) {
+	if name != s.user.WebAuthnName() {
+		return nil, webauthn.ErrUserNotFound
+	}
+
+	return s.user, nil
+}
+
+func (s *passkeyStore) LoadUserByHandle(_ context.Context, userHandle []byte) (webauthn.User, error) {
+	if !bytes.Equal(userHandle, s.user.WebAuthnID()) {
+		return nil, webauthn.ErrUserNotFound
+	}
+
+	return s.user, nil
+}
+
+func (s *passkeyStore) SaveCredential(_ context.Context, _ webauthn.User, credential *webauthn.Credential) error {
+	for i := range s.user.credentials {
+		if bytes.Equal(s.user.credentials[i].ID, credential.ID) {
+			s.user.credentials[i] = *credential
+
+			return nil
+		}
+	}
+
+	s.user.credentials = append(s.user.credentials, *credential)
+
+	return nil
+}
+
+func (s *passkeyStore) DeleteCredential(_ context.Context, _ webauthn.User, credentialID []byte) error {
+	for i := range s.user.credentials {
+		if bytes.Equal(s.user.credentials[i].ID, credentialID) {
+			s.user.credentials = append(s.user.credentials[:i], s.user.credentials[i+1:]...)
+
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func TestPasskeyManager(t *testing.T) {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	store := &passkeyStore{user: &bytesUser{}}
+	manager := webauthn.NewPasskeyManager(w, store, webauthnhttp.NewMemorySessionStore())
+	authenticator := &webauthntest.Authenticator{}
+
+	options, err := manager.BeginRegisterPasskey(ctx, "john", "registration")
+	require.NoError(t, err)
+
+	var creation protocol.CredentialCreation
+
+	require.NoError(t, json.Unmarshal(options, &creation))
+	assert.Equal(t, protocol.ResidentKeyRequirementRequired, creation.Response.AuthenticatorSelection.ResidentKey)
+
+	attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+	require.NoError(t, err)
+
+	response, err := json.Marshal(attestation)
+	require.NoError(t, err)
+
+	passkey, err := manager.RegisterPasskey(ctx, "john", "registration", "Laptop", response)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Laptop", passkey.Name)
+
+	_, err = manager.RegisterPasskey(ctx, "john", "registration", "Laptop", response)
+	assert.ErrorIs(t, err, webauthn.ErrSessionNotFound)
+
+	require.NoError(t, manager.RenamePasskey(ctx, "john", passkey.ID, "Work laptop"))
+
+	passkeys, err := manager.ListPasskeys(ctx, "john")
+	require.NoError(t, err)
+	require.Len(t, passkeys, 1)
+
+	assert.Equal(t, "Work laptop", passkeys[0].Name)
+
+	options, err = manager.BeginAuthenticate(ctx, "login")
+	require.NoError(t, err)
+
+	var assertion protocol.CredentialAssertion
+
+	require.NoError(t, json.Unmarshal(options, &assertion))
+
+	assertionResponse, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+	require.NoError(t, err)
+
+	response, err = json.Marshal(assertionResponse)
+	require.NoError(t, err)
+
+	user, passkey, err := manager.Authenticate(ctx, "login", response)
+	require.NoError(t, err)
+
+	assert.Equal(t, store.user, user)
+	assert.Equal(t, "Work laptop", passkey.Name)
+	assert.Equal(t, uint32(1), store.user.credentials[0].Authenticator.SignCount)
+
+	require.NoError(t, manager.DeletePasskey(ctx, "john", passkey.ID))
+
+	assert.ErrorIs(t, manager.DeletePasskey(ctx, "john", passkey.ID), webauthn.ErrPasskeyNotFound)
+	assert.ErrorIs(t, manager.RenamePasskey(ctx, "jane", passkey.ID, "Phone"), webauthn.ErrUserNotFound)
+
+	passkeys, err = manager.ListPasskeys(ctx, "john")
+	require.NoError(t, err)
+	assert.Empty(t, passkeys)
+}
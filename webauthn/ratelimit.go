@@ -0,0 +1,69 @@
+package webauthn
+
+import (
+	"context"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// RateLimitKey identifies the caller beginning a ceremony to the RateLimiter.
+type RateLimitKey struct {
+	// Ceremony is the ceremony being begun.
+	Ceremony Ceremony
+
+	// UserID is the user handle of the user, or nil for a discoverable login.
+	UserID []byte
+
+	// RemoteAddr is the network address of the client from the ConnectionInfo supplied with
+	// ContextWithConnectionInfo, or empty if the context does not carry one.
+	RemoteAddr string
+
+	// Key is the key supplied with ContextWithRateLimitKey, such as an account or API client identifier, or empty if
+	// the context does not carry one.
+	Key string
+}
+
+// RateLimiter throttles the issuance of challenges by the registration and login ceremonies. See Config.RateLimiter.
+type RateLimiter interface {
+	// Allow returns true if the ceremony may be begun by the caller identified by the key. Returning an error fails the
+	// ceremony with the error, which should only be done if the limit can't be determined such as when the storage of
+	// the limiter is unavailable.
+	Allow(ctx context.Context, key RateLimitKey) (allowed bool, err error)
+}
+
+type rateLimitKeyContextKey struct{}
+
+// ContextWithRateLimitKey returns a copy of the context which carries the key the RateLimiter is consulted with in
+// addition to the user and the address of the client.
+func ContextWithRateLimitKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, rateLimitKeyContextKey{}, key)
+}
+
+// checkRateLimit consults the RateLimiter before a challenge is issued, and returns a protocol.ErrPolicy with the
+// protocol.CodeRateLimited code if the ceremony is not allowed.
+func (webauthn *WebAuthn) checkRateLimit(ctx context.Context, ceremony Ceremony, userID []byte) error {
+	if webauthn.config.RateLimiter == nil {
+		return nil
+	}
+
+	key := RateLimitKey{Ceremony: ceremony, UserID: userID}
+
+	if info, ok := ConnectionInfoFromContext(ctx); ok {
+		key.RemoteAddr = info.RemoteAddr
+	}
+
+	key.Key, _ = ctx.Value(rateLimitKeyContextKey{}).(string)
+
+	allowed, err := webauthn.config.RateLimiter.Allow(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return protocol.ErrPolicy.
+			WithCode(protocol.CodeRateLimited).
+			WithDetails("Too many ceremonies were begun, try again later")
+	}
+
+	return nil
+}
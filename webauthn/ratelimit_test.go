@@ -0,0 +1,74 @@
+package webauthn_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+type rateLimiterFunc func(ctx context.Context, key webauthn.RateLimitKey) (bool, error)
+
+func (f rateLimiterFunc) Allow(ctx context.Context, key webauthn.RateLimitKey) (bool, error) {
+	return f(ctx, key)
+}
+
+func TestWebAuthn_RateLimiter(t *testing.T) {
+	var (
+		keys    []webauthn.RateLimitKey
+		allowed = true
+		failure error
+	)
+
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+		RateLimiter: rateLimiterFunc(func(_ context.Context, key webauthn.RateLimitKey) (bool, error) {
+			keys = append(keys, key)
+
+			return allowed, failure
+		}),
+	})
+	require.NoError(t, err)
+
+	user := &bytesUser{credentials: []webauthn.Credential{{ID: []byte("credential")}}}
+
+	ctx := webauthn.ContextWithConnectionInfo(context.Background(), webauthn.ConnectionInfo{RemoteAddr: "192.0.2.1"})
+	ctx = webauthn.ContextWithRateLimitKey(ctx, "tenant")
+
+	_, _, err = w.BeginRegistrationCtx(ctx, user)
+	require.NoError(t, err)
+
+	_, _, err = w.BeginLoginCtx(ctx, user)
+	require.NoError(t, err)
+
+	_, _, err = w.BeginDiscoverableLoginCtx(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []webauthn.RateLimitKey{
+		{Ceremony: webauthn.CeremonyBeginRegistration, UserID: []byte("1234"), RemoteAddr: "192.0.2.1", Key: "tenant"},
+		{Ceremony: webauthn.CeremonyBeginLogin, UserID: []byte("1234"), RemoteAddr: "192.0.2.1", Key: "tenant"},
+		{Ceremony: webauthn.CeremonyBeginDiscoverableLogin},
+	}, keys)
+
+	allowed = false
+
+	_, _, err = w.BeginLoginCtx(ctx, &bytesUser{})
+	assertErrorCode(t, protocol.CodeRateLimited, err)
+
+	_, _, err = w.BeginRegistrationCtx(ctx, user)
+	assertErrorCode(t, protocol.CodeRateLimited, err)
+	assert.Equal(t, http.StatusTooManyRequests, protocol.GetHTTPStatus(err))
+
+	failure = errors.New("limiter unavailable")
+
+	_, _, err = w.BeginDiscoverableLoginCtx(ctx)
+	assert.ErrorIs(t, err, failure)
+}
@@ -0,0 +1,161 @@
+package webauthn
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// ReceiptClaims are the claims of a signed login receipt, which is a compact JWS attesting that a login with the
+// credential was verified by the Relying Party. It allows internal services to trust that the verification happened
+// without verifying the assertion again. The sub claim is the base64url encoding of the user handle of the user who
+// logged in.
+type ReceiptClaims struct {
+	// RPID is the Relying Party ID the credential is scoped to.
+	RPID string `json:"rpid"`
+
+	// CredentialID is the ID of the credential used to log in.
+	CredentialID protocol.URLEncodedBase64 `json:"cid"`
+
+	// UserVerified is true if the authenticator verified the user.
+	UserVerified bool `json:"uv"`
+
+	// Counter is the signature counter of the credential reported by the authenticator.
+	Counter uint32 `json:"ctr"`
+
+	jwt.RegisteredClaims
+}
+
+// ReceiptSigner signs the login receipts.
+type ReceiptSigner struct {
+	// Method is the JWS signing method, such as jwt.SigningMethodES256.
+	Method jwt.SigningMethod
+
+	// Key is the private key, or the secret for the HMAC signing methods, used to sign the receipts.
+	Key interface{}
+
+	// KeyID is the optional kid header of the receipts, which allows the services to select the verification key.
+	KeyID string
+
+	// Issuer is the optional iss claim of the receipts.
+	Issuer string
+
+	// Audience is the optional aud claim of the receipts.
+	Audience []string
+
+	// Lifetime is the duration after which the receipts expire. The default is 5 minutes as the receipts are intended
+	// to be consumed immediately.
+	Lifetime time.Duration
+}
+
+// NewLoginReceipt returns a signed receipt of the login with the LoginResult returned by FinishLoginResult or one of
+// its variants. Only the results of logins verified by this library are accepted, so a receipt can't be signed for a
+// credential without a verified login. The receipt is timestamped with the Clock of the Config.
+func (webauthn *WebAuthn) NewLoginReceipt(signer ReceiptSigner, result *LoginResult) (receipt string, err error) {
+	if signer.Method == nil {
+		return "", fmt.Errorf("error signing the receipt: the signing method must be configured")
+	}
+
+	if result == nil || !result.verified {
+		return "", fmt.Errorf("error signing the receipt: the login result was not returned by a verified login")
+	}
+
+	lifetime := signer.Lifetime
+	if lifetime == 0 {
+		lifetime = defaultReceiptLifetime
+	}
+
+	now := webauthn.config.now()
+
+	claims := &ReceiptClaims{
+		RPID:         webauthn.config.RPID,
+		CredentialID: result.Credential.ID,
+		UserVerified: result.Credential.Flags.UserVerified,
+		Counter:      result.Credential.Authenticator.SignCount,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    signer.Issuer,
+			Subject:   base64.RawURLEncoding.EncodeToString(result.User.WebAuthnID()),
+			Audience:  signer.Audience,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(lifetime)),
+		},
+	}
+
+	token := jwt.NewWithClaims(signer.Method, claims)
+
+	if signer.KeyID != "" {
+		token.Header["kid"] = signer.KeyID
+	}
+
+	if receipt, err = token.SignedString(signer.Key); err != nil {
+		return "", fmt.Errorf("error signing the receipt: %w", err)
+	}
+
+	return receipt, nil
+}
+
+// ReceiptVerifier verifies the login receipts, and is intended to be used by the services which consume them.
+type ReceiptVerifier struct {
+	// Method is the JWS signing method the receipts must be signed with. Receipts signed with any other method are
+	// rejected.
+	Method jwt.SigningMethod
+
+	// Key is the public key, or the secret for the HMAC signing methods, used to verify the receipts.
+	Key interface{}
+
+	// Issuer is the iss claim the receipts must have if configured.
+	Issuer string
+
+	// Audience is the value the aud claim of the receipts must contain if configured.
+	Audience string
+
+	// RPID is the Relying Party ID the receipts must have if configured.
+	RPID string
+
+	// Clock returns the current time used to check the expiry of the receipts. The default is time.Now.
+	Clock func() time.Time
+}
+
+// Verify returns the ReceiptClaims of the receipt if it has a valid signature and has not expired.
+func (v ReceiptVerifier) Verify(receipt string) (claims *ReceiptClaims, err error) {
+	if v.Method == nil {
+		return nil, fmt.Errorf("error verifying the receipt: the signing method must be configured")
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{v.Method.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+
+	if v.Issuer != "" {
+		options = append(options, jwt.WithIssuer(v.Issuer))
+	}
+
+	if v.Audience != "" {
+		options = append(options, jwt.WithAudience(v.Audience))
+	}
+
+	if v.Clock != nil {
+		options = append(options, jwt.WithTimeFunc(v.Clock))
+	}
+
+	claims = &ReceiptClaims{}
+
+	if _, err = jwt.ParseWithClaims(receipt, claims, func(token *jwt.Token) (interface{}, error) {
+		return v.Key, nil
+	}, options...); err != nil {
+		return nil, fmt.Errorf("error verifying the receipt: %w", err)
+	}
+
+	if v.RPID != "" && claims.RPID != v.RPID {
+		return nil, fmt.Errorf("error verifying the receipt: the rpid '%s' does not match the expected rpid '%s'", claims.RPID, v.RPID)
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,134 @@
+package webauthn_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+func TestWebAuthn_NewLoginReceipt(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+		Clock:         func() time.Time { return now },
+	})
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	user := &bytesUser{}
+	authenticator := &webauthntest.Authenticator{}
+
+	creation, session, err := w.BeginRegistration(user)
+	require.NoError(t, err)
+
+	attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+	require.NoError(t, err)
+
+	r, err := webauthntest.NewRequest(attestation)
+	require.NoError(t, err)
+
+	credential, err := w.FinishRegistration(user, *session, r)
+	require.NoError(t, err)
+
+	user.credentials = append(user.credentials, *credential)
+
+	assertion, session, err := w.BeginLogin(user)
+	require.NoError(t, err)
+
+	response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+	require.NoError(t, err)
+
+	r, err = webauthntest.NewRequest(response)
+	require.NoError(t, err)
+
+	result, err := w.FinishLoginResult(user, *session, r)
+	require.NoError(t, err)
+
+	signer := webauthn.ReceiptSigner{
+		Method:   jwt.SigningMethodES256,
+		Key:      key,
+		KeyID:    "receipts",
+		Issuer:   "https://login.example.com",
+		Audience: []string{"payments"},
+	}
+
+	receipt, err := w.NewLoginReceipt(signer, result)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		verifier webauthn.ReceiptVerifier
+		err      error
+	}{
+		{
+			"ShouldVerify",
+			webauthn.ReceiptVerifier{Method: jwt.SigningMethodES256, Key: &key.PublicKey, Issuer: "https://login.example.com", Audience: "payments", RPID: "example.com", Clock: func() time.Time { return now.Add(time.Minute) }},
+			nil,
+		},
+		{
+			"ShouldFailExpired",
+			webauthn.ReceiptVerifier{Method: jwt.SigningMethodES256, Key: &key.PublicKey, Clock: func() time.Time { return now.Add(time.Hour) }},
+			jwt.ErrTokenExpired,
+		},
+		{
+			"ShouldFailMethod",
+			webauthn.ReceiptVerifier{Method: jwt.SigningMethodHS256, Key: []byte("secret"), Clock: func() time.Time { return now }},
+			jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			"ShouldFailAudience",
+			webauthn.ReceiptVerifier{Method: jwt.SigningMethodES256, Key: &key.PublicKey, Audience: "billing", Clock: func() time.Time { return now }},
+			jwt.ErrTokenInvalidAudience,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			claims, err := tc.verifier.Verify(receipt)
+
+			if tc.err != nil {
+				assert.Nil(t, claims)
+				assert.ErrorIs(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			assert.Equal(t, "example.com", claims.RPID)
+			assert.Equal(t, credential.ID, []byte(claims.CredentialID))
+			assert.Equal(t, "MTIzNA", claims.Subject)
+			assert.True(t, claims.UserVerified)
+			assert.Equal(t, result.Credential.Authenticator.SignCount, claims.Counter)
+			assert.Equal(t, now.Unix(), claims.IssuedAt.Unix())
+		})
+	}
+
+	t.Run("ShouldFailUnverifiedResult", func(t *testing.T) {
+		receipt, err := w.NewLoginReceipt(signer, &webauthn.LoginResult{User: user, Credential: credential})
+
+		assert.Empty(t, receipt)
+		assert.EqualError(t, err, "error signing the receipt: the login result was not returned by a verified login")
+	})
+
+	t.Run("ShouldFailRPID", func(t *testing.T) {
+		claims, err := webauthn.ReceiptVerifier{Method: jwt.SigningMethodES256, Key: &key.PublicKey, RPID: "example.org", Clock: func() time.Time { return now }}.Verify(receipt)
+
+		assert.Nil(t, claims)
+		assert.EqualError(t, err, "error verifying the receipt: the rpid 'example.com' does not match the expected rpid 'example.org'")
+	})
+}
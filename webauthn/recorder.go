@@ -0,0 +1,234 @@
+package webauthn
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// Recorder receives a Recording of every finish ceremony which failed. Implementations must be safe for concurrent use
+// and should not block.
+type Recorder interface {
+	Record(recording Recording)
+}
+
+// Recording is a reproducible bundle of a failed finish ceremony which is intended to be attached to bug reports. It
+// contains the credential response as it was received along with the redacted session and the configuration used to
+// verify it.
+type Recording struct {
+	// Ceremony is the ceremony step this recording relates to.
+	Ceremony Ceremony `json:"ceremony"`
+
+	// RPID is the configured Relying Party ID.
+	RPID string `json:"rp_id"`
+
+	// RPOrigins are the configured Relying Party origins.
+	RPOrigins []string `json:"rp_origins"`
+
+	// Time is the time the ceremony step started.
+	Time time.Time `json:"time"`
+
+	// Error is the error the ceremony step failed with.
+	Error string `json:"error"`
+
+	// Code is the protocol.ErrorCode of the error the ceremony step failed with.
+	Code protocol.ErrorCode `json:"code,omitempty"`
+
+	// Session is the redacted SessionData the response was verified against.
+	Session RecordedSession `json:"session"`
+
+	// Response is the credential response. It's the body of the request as it was received by the Finish methods, and
+	// is re-encoded from the parsed response by the CreateCredential and Validate methods.
+	Response json.RawMessage `json:"response,omitempty"`
+
+	// ResponseBody is the body of the request when it's not valid JSON, in which case Response is empty. The body is
+	// truncated to the ResponseBodyLimit.
+	ResponseBody []byte `json:"response_body,omitempty"`
+
+	// Steps are the individual verification steps which were performed in order.
+	Steps []protocol.VerificationStep `json:"steps"`
+}
+
+// RecordedSession is the SessionData of a Recording. The challenge and user ID are replaced with their SHA-256 hash,
+// and the hash of the challenge can be compared to the hash of the challenge in the client data of the response.
+type RecordedSession struct {
+	ChallengeHash        string                               `json:"challenge_hash"`
+	UserIDHash           string                               `json:"user_id_hash"`
+	AllowedCredentialIDs [][]byte                             `json:"allowed_credentials,omitempty"`
+	Expires              time.Time                            `json:"expires"`
+	CreatedAt            time.Time                            `json:"created_at"`
+	UserVerification     protocol.UserVerificationRequirement `json:"userVerification"`
+	Extensions           protocol.AuthenticationExtensions    `json:"extensions,omitempty"`
+	HighAssurance        bool                                 `json:"high_assurance,omitempty"`
+	StepUp               bool                                 `json:"step_up,omitempty"`
+	Recovery             bool                                 `json:"recovery,omitempty"`
+	Transports           []protocol.AuthenticatorTransport    `json:"transports,omitempty"`
+}
+
+// DirectoryRecorder is a Recorder which writes every Recording as an indented JSON file to a directory. The files are
+// only readable by the owner.
+type DirectoryRecorder struct {
+	// Dir is the directory the recordings are written to. It must exist.
+	Dir string
+
+	// ErrorHandler is called with the error if a recording could not be written.
+	ErrorHandler func(err error)
+}
+
+// NewDirectoryRecorder returns a *DirectoryRecorder which writes the recordings to the directory.
+func NewDirectoryRecorder(dir string) *DirectoryRecorder {
+	return &DirectoryRecorder{Dir: dir}
+}
+
+// Record implements the Recorder interface.
+func (r *DirectoryRecorder) Record(recording Recording) {
+	if _, err := r.Write(recording); err != nil && r.ErrorHandler != nil {
+		r.ErrorHandler(err)
+	}
+}
+
+// Write writes the recording to a new file in the directory and returns the name of the file.
+func (r *DirectoryRecorder) Write(recording Recording) (name string, err error) {
+	data, err := json.MarshalIndent(recording, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error encoding the recording: %w", err)
+	}
+
+	pattern := fmt.Sprintf("%s-%s-*.json", recording.Time.UTC().Format("20060102T150405Z"), recording.Ceremony)
+
+	file, err := os.CreateTemp(r.Dir, pattern)
+	if err != nil {
+		return "", fmt.Errorf("error creating the recording file: %w", err)
+	}
+
+	if _, err = file.Write(data); err != nil {
+		_ = file.Close()
+
+		return "", fmt.Errorf("error writing the recording file: %w", err)
+	}
+
+	if err = file.Close(); err != nil {
+		return "", fmt.Errorf("error writing the recording file: %w", err)
+	}
+
+	return file.Name(), nil
+}
+
+// recordRequest retains the session and the body of the request for the Recording if a Recorder is configured. The
+// body of the request is replaced so it can still be parsed.
+func (o *ceremonyObserver) recordRequest(session SessionData, response *http.Request) {
+	if o.webauthn.config == nil || o.webauthn.config.Recorder == nil {
+		return
+	}
+
+	o.session = &session
+
+	if response == nil || response.Body == nil {
+		return
+	}
+
+	reader := response.Body
+
+	if limit := o.webauthn.config.responseBodyLimit(); limit > 0 {
+		reader = io.NopCloser(io.LimitReader(response.Body, limit))
+	}
+
+	o.body, _ = io.ReadAll(reader)
+
+	response.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(o.body), response.Body), response.Body}
+}
+
+// recordBody retains the session and a copy of the body of the response for the Recording if a Recorder is configured.
+// The body is copied as frameworks such as fasthttp reuse the buffer once the request is handled.
+func (o *ceremonyObserver) recordBody(session SessionData, body []byte) {
+	if o.webauthn.config == nil || o.webauthn.config.Recorder == nil {
+		return
+	}
+
+	o.session = &session
+
+	if limit := o.webauthn.config.responseBodyLimit(); limit > 0 && int64(len(body)) > limit {
+		body = body[:limit]
+	}
+
+	o.body = append([]byte(nil), body...)
+}
+
+// recordSession replaces the session retained for the Recording if a Recorder is configured, for the ceremonies which
+// only know the session once the response is parsed.
+func (o *ceremonyObserver) recordSession(session SessionData) {
+	if o.webauthn.config == nil || o.webauthn.config.Recorder == nil {
+		return
+	}
+
+	o.session = &session
+}
+
+// recordParsed retains the session and the re-encoded raw response of the parsed response for the Recording if a
+// Recorder is configured.
+func (o *ceremonyObserver) recordParsed(session SessionData, parsedResponse interface{}) {
+	if o.webauthn.config == nil || o.webauthn.config.Recorder == nil {
+		return
+	}
+
+	o.session = &session
+
+	switch parsed := parsedResponse.(type) {
+	case *protocol.ParsedCredentialCreationData:
+		if parsed != nil {
+			o.body, _ = json.Marshal(parsed.Raw)
+		}
+	case *protocol.ParsedCredentialAssertionData:
+		if parsed != nil {
+			o.body, _ = json.Marshal(parsed.Raw)
+		}
+	}
+}
+
+func newRecording(o *ceremonyObserver, err error) Recording {
+	recording := Recording{
+		Ceremony:  o.ceremony,
+		RPID:      o.webauthn.config.RPID,
+		RPOrigins: o.webauthn.config.RPOrigins,
+		Time:      o.start,
+		Error:     err.Error(),
+		Session: RecordedSession{
+			ChallengeHash:        auditHash([]byte(o.session.Challenge)),
+			UserIDHash:           auditHash(o.session.UserID),
+			AllowedCredentialIDs: o.session.AllowedCredentialIDs,
+			Expires:              o.session.Expires,
+			CreatedAt:            o.session.CreatedAt,
+			UserVerification:     o.session.UserVerification,
+			Extensions:           o.session.Extensions,
+			HighAssurance:        o.session.HighAssurance,
+			StepUp:               o.session.StepUp,
+			Recovery:             o.session.Recovery,
+			Transports:           o.session.Transports,
+		},
+		Steps: o.trace.Steps,
+	}
+
+	if json.Valid(o.body) {
+		recording.Response = o.body
+	} else {
+		recording.ResponseBody = o.body
+	}
+
+	var e *protocol.Error
+
+	if errors.As(err, &e) {
+		recording.Code = e.Code
+	}
+
+	return recording
+}
@@ -0,0 +1,169 @@
+package webauthn
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+type recorderFunc func(recording Recording)
+
+func (f recorderFunc) Record(recording Recording) {
+	f(recording)
+}
+
+func TestWebAuthn_Recorder(t *testing.T) {
+	var recordings []Recording
+
+	config := &Config{
+		RPDisplayName: "Example",
+		RPID:          "example.com",
+		RPOrigins:     []string{"https://example.com"},
+		Recorder: recorderFunc(func(recording Recording) {
+			recordings = append(recordings, recording)
+		}),
+	}
+
+	webauthn, err := New(config)
+	require.NoError(t, err)
+
+	user := &defaultUser{id: []byte("123")}
+	session := SessionData{
+		Challenge:        "challenge",
+		UserID:           []byte("123"),
+		Expires:          time.Unix(1700000000, 0).UTC(),
+		UserVerification: protocol.VerificationRequired,
+	}
+
+	body := `{"id":"abc","rawId":"abc","type":"public-key","response":{}}`
+
+	_, err = webauthn.FinishLogin(user, session, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	require.Error(t, err)
+
+	// The error is the same as without a Recorder as the body is still parsed.
+	_, expected := (&WebAuthn{config: &Config{RPID: "example.com"}}).FinishLogin(user, session, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	assert.Equal(t, expected.Error(), err.Error())
+
+	require.Len(t, recordings, 1)
+
+	recording := recordings[0]
+
+	assert.Equal(t, CeremonyFinishLogin, recording.Ceremony)
+	assert.Equal(t, "example.com", recording.RPID)
+	assert.Equal(t, []string{"https://example.com"}, recording.RPOrigins)
+	assert.Equal(t, err.Error(), recording.Error)
+	assert.JSONEq(t, body, string(recording.Response))
+	assert.Nil(t, recording.ResponseBody)
+	assert.Equal(t, RecordedSession{
+		ChallengeHash:    auditHash([]byte("challenge")),
+		UserIDHash:       auditHash([]byte("123")),
+		Expires:          session.Expires,
+		UserVerification: protocol.VerificationRequired,
+	}, recording.Session)
+	require.Len(t, recording.Steps, 1)
+	assert.Equal(t, protocol.VerificationStepParse, recording.Steps[0].Name)
+
+	encoded, err := json.Marshal(recording)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), `"challenge"`)
+
+	// A failure of a parsed response is recorded with the re-encoded response.
+	_, err = webauthn.ValidateLogin(user, SessionData{UserID: []byte("ABC")}, &protocol.ParsedCredentialAssertionData{
+		Raw: protocol.CredentialAssertionResponse{PublicKeyCredential: protocol.PublicKeyCredential{Credential: protocol.Credential{ID: "abc"}}},
+	})
+	require.Error(t, err)
+
+	require.Len(t, recordings, 2)
+	assert.Equal(t, protocol.CodeUserSessionMismatch, recordings[1].Code)
+	assert.Contains(t, string(recordings[1].Response), `"id":"abc"`)
+
+	_, err = webauthn.ValidateLogin(user, SessionData{UserID: []byte("ABC")}, nil)
+	require.Error(t, err)
+
+	require.Len(t, recordings, 3)
+	assert.Nil(t, recordings[2].Response)
+}
+
+func TestWebAuthn_RecorderResponseBodyLimit(t *testing.T) {
+	var recordings []Recording
+
+	webauthn, err := New(&Config{
+		RPDisplayName:     "Example",
+		RPID:              "example.com",
+		RPOrigins:         []string{"https://example.com"},
+		ResponseBodyLimit: 16,
+		Recorder: recorderFunc(func(recording Recording) {
+			recordings = append(recordings, recording)
+		}),
+	})
+	require.NoError(t, err)
+
+	user := &defaultUser{id: []byte("123")}
+
+	_, err = webauthn.FinishRegistration(user, SessionData{UserID: []byte("123")}, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"id":"`+strings.Repeat("a", 32)+`"}`)))
+	require.Error(t, err)
+	assert.Equal(t, protocol.CodeResponseTooLarge, err.(*protocol.Error).Code)
+
+	require.Len(t, recordings, 1)
+	assert.Equal(t, CeremonyFinishRegistration, recordings[0].Ceremony)
+	assert.Equal(t, protocol.CodeResponseTooLarge, recordings[0].Code)
+	assert.Nil(t, recordings[0].Response)
+	assert.Equal(t, []byte(`{"id":"`+strings.Repeat("a", 9)), recordings[0].ResponseBody)
+}
+
+func TestDirectoryRecorder(t *testing.T) {
+	dir := t.TempDir()
+
+	recorder := NewDirectoryRecorder(dir)
+
+	recording := Recording{
+		Ceremony: CeremonyFinishLogin,
+		RPID:     "example.com",
+		Time:     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Error:    "Session has Expired",
+		Code:     protocol.CodeSessionExpired,
+		Response: json.RawMessage(`{"id":"abc"}`),
+	}
+
+	name, err := recorder.Write(recording)
+	require.NoError(t, err)
+
+	assert.Equal(t, dir, filepath.Dir(name))
+	assert.True(t, strings.HasPrefix(filepath.Base(name), "20240102T030405Z-finish_login-"))
+
+	info, err := os.Stat(name)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+
+	var decoded Recording
+
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, recording.Ceremony, decoded.Ceremony)
+	assert.Equal(t, recording.Code, decoded.Code)
+	assert.JSONEq(t, `{"id":"abc"}`, string(decoded.Response))
+
+	var errs []error
+
+	recorder = &DirectoryRecorder{
+		Dir:          filepath.Join(dir, "missing"),
+		ErrorHandler: func(err error) { errs = append(errs, err) },
+	}
+
+	recorder.Record(recording)
+
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], os.ErrNotExist)
+}
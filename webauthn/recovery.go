@@ -0,0 +1,75 @@
+package webauthn
+
+import (
+	"context"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// BeginRecoveryRegistration is the same as BeginRegistration except the registration enrolls a recovery credential,
+// which is a passkey designated for regaining access to the account when the other credentials of the user are lost.
+// A discoverable credential and user verification are required regardless of the options. The returned SessionData is
+// marked so the Credential returned by finishing the registration is marked as a Recovery credential.
+func (webauthn *WebAuthn) BeginRecoveryRegistration(user User, opts ...RegistrationOption) (creation *protocol.CredentialCreation, session *SessionData, err error) {
+	return webauthn.BeginRecoveryRegistrationCtx(context.Background(), user, opts...)
+}
+
+// BeginRecoveryRegistrationCtx is the same as BeginRecoveryRegistration except it accepts a context.Context.
+func (webauthn *WebAuthn) BeginRecoveryRegistrationCtx(ctx context.Context, user User, opts ...RegistrationOption) (creation *protocol.CredentialCreation, session *SessionData, err error) {
+	opts = append(opts, WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired), withUserVerificationRequired())
+
+	if creation, session, err = webauthn.BeginRegistrationCtx(ctx, user, opts...); err != nil {
+		return nil, nil, err
+	}
+
+	session.Recovery = true
+
+	return creation, session, nil
+}
+
+// BeginRecoveryLogin is the same as BeginLogin except only the Recovery credentials of the user are allowed and user
+// verification is required regardless of the options. The returned SessionData is marked so finishing the login with
+// any other credential fails.
+func (webauthn *WebAuthn) BeginRecoveryLogin(user User, opts ...LoginOption) (*protocol.CredentialAssertion, *SessionData, error) {
+	return webauthn.BeginRecoveryLoginCtx(context.Background(), user, opts...)
+}
+
+// BeginRecoveryLoginCtx is the same as BeginRecoveryLogin except it accepts a context.Context.
+func (webauthn *WebAuthn) BeginRecoveryLoginCtx(ctx context.Context, user User, opts ...LoginOption) (*protocol.CredentialAssertion, *SessionData, error) {
+	credentials := RecoveryCredentials(user.WebAuthnCredentials())
+
+	if len(credentials) == 0 {
+		return nil, nil, webauthn.handleError(protocol.ErrBadRequest.WithCode(protocol.CodeNoCredentials).WithDetails("Found no recovery credentials for user"))
+	}
+
+	opts = append(opts, WithAllowedCredentials(CredentialDescriptors(credentials)), WithUserVerification(protocol.VerificationRequired))
+
+	assertion, session, err := webauthn.BeginLoginCtx(ctx, user, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	session.Recovery = true
+
+	return assertion, session, nil
+}
+
+// RecoveryCredentials returns the credentials which are marked as a Recovery credential.
+func RecoveryCredentials(credentials []Credential) (recovery []Credential) {
+	for _, credential := range credentials {
+		if credential.Recovery {
+			recovery = append(recovery, credential)
+		}
+	}
+
+	return recovery
+}
+
+// verifyRecoveryCredential ensures only a Recovery credential is used to finish a recovery login.
+func verifyRecoveryCredential(session SessionData, credential Credential) error {
+	if !session.Recovery || credential.Recovery {
+		return nil
+	}
+
+	return protocol.ErrBadRequest.WithCode(protocol.CodeCredentialNotAllowed).WithDetails("Credential is not a recovery credential")
+}
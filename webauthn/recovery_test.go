@@ -0,0 +1,111 @@
+package webauthn_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+func TestWebAuthn_Recovery(t *testing.T) {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+	})
+	require.NoError(t, err)
+
+	user := &bytesUser{}
+	primary := &webauthntest.Authenticator{}
+	recovery := &webauthntest.Authenticator{}
+
+	register := func(authenticator *webauthntest.Authenticator, creation *protocol.CredentialCreation, session *webauthn.SessionData) *webauthn.Credential {
+		attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+		require.NoError(t, err)
+
+		r, err := webauthntest.NewRequest(attestation)
+		require.NoError(t, err)
+
+		credential, err := w.FinishRegistration(user, *session, r)
+		require.NoError(t, err)
+
+		user.credentials = append(user.credentials, *credential)
+
+		return credential
+	}
+
+	creation, session, err := w.BeginRegistration(user)
+	require.NoError(t, err)
+
+	assert.False(t, session.Recovery)
+	assert.False(t, register(primary, creation, session).Recovery)
+
+	_, _, err = w.BeginRecoveryLogin(user)
+	assertErrorCode(t, protocol.CodeNoCredentials, err)
+
+	creation, session, err = w.BeginRecoveryRegistration(user, webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementDiscouraged))
+	require.NoError(t, err)
+
+	assert.True(t, session.Recovery)
+	assert.Equal(t, protocol.ResidentKeyRequirementRequired, creation.Response.AuthenticatorSelection.ResidentKey)
+	assert.Equal(t, protocol.VerificationRequired, creation.Response.AuthenticatorSelection.UserVerification)
+	assert.Equal(t, protocol.VerificationRequired, session.UserVerification)
+
+	t.Run("ShouldRequireUserVerificationOfRestoredSession", func(t *testing.T) {
+		restored := *session
+		restored.UserVerification = protocol.VerificationPreferred
+
+		attestation, _, err := (&webauthntest.Authenticator{Flags: protocol.FlagUserPresent}).CreateCredential(creation.Response, "https://example.com")
+		require.NoError(t, err)
+
+		r, err := webauthntest.NewRequest(attestation)
+		require.NoError(t, err)
+
+		_, err = w.FinishRegistration(user, restored, r)
+		assertErrorCode(t, protocol.CodeUVRequired, err)
+	})
+
+	credential := register(recovery, creation, session)
+
+	assert.True(t, credential.Recovery)
+	assert.Len(t, webauthn.RecoveryCredentials(user.credentials), 1)
+
+	assertion, session, err := w.BeginRecoveryLogin(user)
+	require.NoError(t, err)
+
+	assert.True(t, session.Recovery)
+	assert.Equal(t, protocol.VerificationRequired, assertion.Response.UserVerification)
+	require.Len(t, assertion.Response.AllowedCredentials, 1)
+	assert.Equal(t, credential.ID, []byte(assertion.Response.AllowedCredentials[0].CredentialID))
+
+	login := func(authenticator *webauthntest.Authenticator, options protocol.PublicKeyCredentialRequestOptions, session webauthn.SessionData) error {
+		response, err := authenticator.GetAssertion(options, "https://example.com")
+		require.NoError(t, err)
+
+		r, err := webauthntest.NewRequest(response)
+		require.NoError(t, err)
+
+		_, err = w.FinishLogin(user, session, r)
+
+		return err
+	}
+
+	assert.NoError(t, login(recovery, assertion.Response, *session))
+
+	assertion, session, err = w.BeginRecoveryLogin(user)
+	require.NoError(t, err)
+
+	session.AllowedCredentialIDs = nil
+	assertion.Response.AllowedCredentials = nil
+
+	assertErrorCode(t, protocol.CodeCredentialNotAllowed, login(primary, assertion.Response, *session))
+
+	assertion, session, err = w.BeginLogin(user)
+	require.NoError(t, err)
+
+	assert.NoError(t, login(recovery, assertion.Response, *session))
+}
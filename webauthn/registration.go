@@ -1,13 +1,14 @@
 package webauthn
 
 import (
-	"bytes"
+	"context"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/go-webauthn/webauthn/protocol"
 	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/tracing"
 )
 
 // BEGIN REGISTRATION
@@ -20,18 +21,46 @@ type RegistrationOption func(*protocol.PublicKeyCredentialCreationOptions)
 
 // BeginRegistration generates a new set of registration data to be sent to the client and authenticator.
 func (webauthn *WebAuthn) BeginRegistration(user User, opts ...RegistrationOption) (creation *protocol.CredentialCreation, session *SessionData, err error) {
-	if err = webauthn.Config.validate(); err != nil {
+	return webauthn.BeginRegistrationCtx(context.Background(), user, opts...)
+}
+
+// BeginRegistrationCtx is the same as BeginRegistration except it accepts a context.Context.
+func (webauthn *WebAuthn) BeginRegistrationCtx(ctx context.Context, user User, opts ...RegistrationOption) (creation *protocol.CredentialCreation, session *SessionData, err error) {
+	_, observer := webauthn.startCeremony(ctx, CeremonyBeginRegistration, user.WebAuthnID())
+
+	creation, session, err = webauthn.beginRegistration(ctx, user, opts...)
+
+	observer.observe(nil, err)
+
+	if err != nil {
+		return nil, nil, webauthn.handleError(err)
+	}
+
+	return creation, session, nil
+}
+
+func (webauthn *WebAuthn) beginRegistration(ctx context.Context, user User, opts ...RegistrationOption) (creation *protocol.CredentialCreation, session *SessionData, err error) {
+	if err = webauthn.config.validate(); err != nil {
 		return nil, nil, fmt.Errorf(errFmtConfigValidate, err)
 	}
 
-	challenge, err := protocol.CreateChallenge()
+	if err = webauthn.checkRateLimit(ctx, CeremonyBeginRegistration, user.WebAuthnID()); err != nil {
+		return nil, nil, err
+	}
+
+	name, displayName, err := newUserEntity(user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	challenge, err := webauthn.config.newChallenge()
 	if err != nil {
 		return nil, nil, err
 	}
 
 	var entityUserID interface{}
 
-	if webauthn.Config.EncodeUserIDAsString {
+	if webauthn.config.EncodeUserIDAsString {
 		entityUserID = string(user.WebAuthnID())
 	} else {
 		entityUserID = protocol.URLEncodedBase64(user.WebAuthnID())
@@ -39,18 +68,18 @@ func (webauthn *WebAuthn) BeginRegistration(user User, opts ...RegistrationOptio
 
 	entityUser := protocol.UserEntity{
 		ID:          entityUserID,
-		DisplayName: user.WebAuthnDisplayName(),
+		DisplayName: displayName,
 		CredentialEntity: protocol.CredentialEntity{
-			Name: user.WebAuthnName(),
+			Name: name,
 			Icon: user.WebAuthnIcon(),
 		},
 	}
 
 	entityRelyingParty := protocol.RelyingPartyEntity{
-		ID: webauthn.Config.RPID,
+		ID: webauthn.config.RPID,
 		CredentialEntity: protocol.CredentialEntity{
-			Name: webauthn.Config.RPDisplayName,
-			Icon: webauthn.Config.RPIcon,
+			Name: webauthn.config.RPDisplayName,
+			Icon: webauthn.config.RPIcon,
 		},
 	}
 
@@ -62,8 +91,8 @@ func (webauthn *WebAuthn) BeginRegistration(user User, opts ...RegistrationOptio
 			User:                   entityUser,
 			Challenge:              challenge,
 			Parameters:             credentialParams,
-			AuthenticatorSelection: webauthn.Config.AuthenticatorSelection,
-			Attestation:            webauthn.Config.AttestationPreference,
+			AuthenticatorSelection: webauthn.config.AuthenticatorSelection,
+			Attestation:            webauthn.config.AttestationPreference,
 		},
 	}
 
@@ -71,23 +100,34 @@ func (webauthn *WebAuthn) BeginRegistration(user User, opts ...RegistrationOptio
 		opt(&creation.Response)
 	}
 
+	if webauthn.config.SpecLevel < protocol.SpecLevel3 {
+		creation.Response.Hints, creation.Response.AttestationFormats = nil, nil
+	}
+
+	if webauthn.config.fips() {
+		if creation.Response.Parameters = fipsCredentialParameters(creation.Response.Parameters); len(creation.Response.Parameters) == 0 {
+			return nil, nil, protocol.ErrUnsupportedAlgorithm.WithCode(protocol.CodeAlgorithmNotAllowed).WithDetails("None of the credential parameters use a FIPS approved algorithm")
+		}
+	}
+
 	if creation.Response.Timeout == 0 {
 		switch {
 		case creation.Response.AuthenticatorSelection.UserVerification == protocol.VerificationDiscouraged:
-			creation.Response.Timeout = int(webauthn.Config.Timeouts.Registration.TimeoutUVD.Milliseconds())
+			creation.Response.Timeout = int(webauthn.config.Timeouts.Registration.TimeoutUVD.Milliseconds())
 		default:
-			creation.Response.Timeout = int(webauthn.Config.Timeouts.Registration.Timeout.Milliseconds())
+			creation.Response.Timeout = int(webauthn.config.Timeouts.Registration.Timeout.Milliseconds())
 		}
 	}
 
 	session = &SessionData{
-		Challenge:        challenge.String(),
+		Challenge:        creation.Response.Challenge.String(),
 		UserID:           user.WebAuthnID(),
 		UserVerification: creation.Response.AuthenticatorSelection.UserVerification,
+		CreatedAt:        webauthn.config.now(),
 	}
 
-	if webauthn.Config.Timeouts.Registration.Enforce {
-		session.Expires = time.Now().Add(time.Millisecond * time.Duration(creation.Response.Timeout))
+	if webauthn.config.Timeouts.Registration.Enforce {
+		session.Expires = session.CreatedAt.Add(time.Millisecond * time.Duration(creation.Response.Timeout))
 	}
 
 	return creation, session, nil
@@ -101,6 +141,26 @@ func WithAuthenticatorSelection(authenticatorSelection protocol.AuthenticatorSel
 	}
 }
 
+// WithHints adjusts the hints to the user agent about how the registration may be best completed, ordered from most
+// preferred to least preferred. The hints are only included when the Config.SpecLevel is protocol.SpecLevel3.
+//
+// Specification: §5.8.7. User-agent Hints Enumeration (https://www.w3.org/TR/webauthn-3/#enumdef-publickeycredentialhint)
+func WithHints(hints ...protocol.PublicKeyCredentialHint) RegistrationOption {
+	return func(cco *protocol.PublicKeyCredentialCreationOptions) {
+		cco.Hints = hints
+	}
+}
+
+// WithAttestationFormats adjusts the attestation statement formats the Relying Party prefers, ordered from most
+// preferred to least preferred. The formats are only included when the Config.SpecLevel is protocol.SpecLevel3.
+//
+// Specification: §5.4. Options for Credential Creation (https://www.w3.org/TR/webauthn-3/#dom-publickeycredentialcreationoptions-attestationformats)
+func WithAttestationFormats(formats ...string) RegistrationOption {
+	return func(cco *protocol.PublicKeyCredentialCreationOptions) {
+		cco.AttestationFormats = formats
+	}
+}
+
 // WithExclusions adjusts the non-default parameters regarding credentials to exclude from registration.
 func WithExclusions(excludeList []protocol.CredentialDescriptor) RegistrationOption {
 	return func(cco *protocol.PublicKeyCredentialCreationOptions) {
@@ -163,32 +223,147 @@ func WithResidentKeyRequirement(requirement protocol.ResidentKeyRequirement) Reg
 // FinishRegistration takes the response from the authenticator and client and verify the credential against the user's
 // credentials and session data.
 func (webauthn *WebAuthn) FinishRegistration(user User, session SessionData, response *http.Request) (*Credential, error) {
-	parsedResponse, err := protocol.ParseCredentialCreationResponse(response)
+	return webauthn.FinishRegistrationCtx(requestContext(response), user, session, response)
+}
+
+// FinishRegistrationCtx is the same as FinishRegistration except the provided context is used instead of the context of
+// the request.
+func (webauthn *WebAuthn) FinishRegistrationCtx(ctx context.Context, user User, session SessionData, response *http.Request) (*Credential, error) {
+	ctx, observer := webauthn.startCeremony(ctx, CeremonyFinishRegistration, user.WebAuthnID())
+
+	observer.recordRequest(session, response)
+
+	webauthn.config.compatibleRequest(response)
+
+	parsedResponse, err := protocol.ParseCredentialCreationResponseWithLimit(response, webauthn.config.responseBodyLimit())
+
+	observer.trace.Record(protocol.VerificationStepParse, err, nil)
+
 	if err != nil {
-		return nil, err
+		return observer.finish(nil, err)
 	}
 
-	return webauthn.CreateCredential(user, session, parsedResponse)
+	return observer.finish(webauthn.createCredential(ctx, observer.trace, user, session, parsedResponse, false))
+}
+
+// FinishRegistrationBytes is the same as FinishRegistration except the response is the body of the request as a byte
+// slice, for frameworks such as fasthttp which do not use the http library from stdlib.
+func (webauthn *WebAuthn) FinishRegistrationBytes(user User, session SessionData, body []byte) (*Credential, error) {
+	return webauthn.FinishRegistrationBytesCtx(context.Background(), user, session, body)
+}
+
+// FinishRegistrationBytesCtx is the same as FinishRegistrationBytes except it accepts a context.Context.
+func (webauthn *WebAuthn) FinishRegistrationBytesCtx(ctx context.Context, user User, session SessionData, body []byte) (*Credential, error) {
+	ctx, observer := webauthn.startCeremony(ctx, CeremonyFinishRegistration, user.WebAuthnID())
+
+	observer.recordBody(session, body)
+
+	parsedResponse, err := protocol.ParseCredentialCreationResponseBytesWithLimit(webauthn.config.compatibleBody(body), webauthn.config.responseBodyLimit())
+
+	observer.trace.Record(protocol.VerificationStepParse, err, nil)
+
+	if err != nil {
+		return observer.finish(nil, err)
+	}
+
+	return observer.finish(webauthn.createCredential(ctx, observer.trace, user, session, parsedResponse, false))
 }
 
 // CreateCredential verifies a parsed response against the user's credentials and session data.
 func (webauthn *WebAuthn) CreateCredential(user User, session SessionData, parsedResponse *protocol.ParsedCredentialCreationData) (*Credential, error) {
-	if !bytes.Equal(user.WebAuthnID(), session.UserID) {
-		return nil, protocol.ErrBadRequest.WithDetails("ID mismatch for User and Session")
+	return webauthn.CreateCredentialCtx(context.Background(), user, session, parsedResponse)
+}
+
+// CreateCredentialCtx is the same as CreateCredential except it accepts a context.Context.
+func (webauthn *WebAuthn) CreateCredentialCtx(ctx context.Context, user User, session SessionData, parsedResponse *protocol.ParsedCredentialCreationData) (*Credential, error) {
+	ctx, observer := webauthn.startCeremony(ctx, CeremonyFinishRegistration, user.WebAuthnID())
+
+	observer.recordParsed(session, parsedResponse)
+
+	return observer.finish(webauthn.createCredential(ctx, observer.trace, user, session, parsedResponse, false))
+}
+
+func (webauthn *WebAuthn) createCredential(ctx context.Context, trace *protocol.VerificationTrace, user User, session SessionData, parsedResponse *protocol.ParsedCredentialCreationData, deferAttestation bool) (*Credential, error) {
+	err := verifySession(user.WebAuthnID(), session, webauthn.config.now(), webauthn.config.Timeouts.Registration.Grace)
+
+	if err = trace.Step(protocol.VerificationStepSession, err, map[string]string{"user_id_hash": auditHash(user.WebAuthnID())}); err != nil {
+		return nil, err
+	}
+
+	if err = webauthn.runRegistrationHook(ctx, trace, protocol.VerificationStepPreVerifyPolicy, webauthn.config.RegistrationHooks.PreVerify, user, parsedResponse); err != nil {
+		return nil, err
+	}
+
+	if err = webauthn.verifyChallengeBinding(ctx, trace, &parsedResponse.Response.CollectedClientData); err != nil {
+		return nil, err
 	}
 
-	if !session.Expires.IsZero() && session.Expires.Before(time.Now()) {
-		return nil, protocol.ErrBadRequest.WithDetails("Session has Expired")
+	if err = webauthn.verifyTokenBinding(ctx, trace, &parsedResponse.Response.CollectedClientData); err != nil {
+		return nil, err
+	}
+
+	if err = webauthn.verifyTopOrigin(trace, &parsedResponse.Response.CollectedClientData); err != nil {
+		return nil, err
+	}
+
+	if err = webauthn.verifyResponseJSON(trace, parsedResponse); err != nil {
+		return nil, err
 	}
 
-	shouldVerifyUser := session.UserVerification == protocol.VerificationRequired
+	shouldVerifyUser := session.UserVerification == protocol.VerificationRequired || session.HighAssurance || session.Recovery
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if deferAttestation {
+		err = parsedResponse.VerifyDeferred(trace, session.Challenge, shouldVerifyUser, webauthn.config.RPID, webauthn.config.RPOrigins, webauthn.config.originVerifier())
+	} else {
+		_, span := tracing.Start(ctx, webauthn.config.TracerProvider, "webauthn.verify_attestation",
+			tracing.String(tracing.AttributeRPID, webauthn.config.RPID),
+			tracing.String(tracing.AttributeAttestationFormat, parsedResponse.Response.AttestationObject.Format),
+		)
+
+		err = parsedResponse.VerifyWithPolicy(trace, webauthn.config.registrationPolicy(ctx, session.HighAssurance), session.Challenge, shouldVerifyUser, webauthn.config.RPID, webauthn.config.RPOrigins, webauthn.config.originVerifier())
 
-	invalidErr := parsedResponse.Verify(session.Challenge, shouldVerifyUser, webauthn.Config.RPID, webauthn.Config.RPOrigins)
-	if invalidErr != nil {
-		return nil, invalidErr
+		tracing.End(span, err)
 	}
 
-	return MakeNewCredential(parsedResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = webauthn.verifyAlgorithm(trace, parsedResponse.Response.AttestationObject.AuthData.AttData.CredentialPublicKey); err != nil {
+		return nil, err
+	}
+
+	if err = webauthn.verifyTrustPolicy(ctx, trace, parsedResponse); err != nil {
+		return nil, err
+	}
+
+	if err = webauthn.verifyBlocklist(ctx, trace, parsedResponse.Response.AttestationObject.AuthData.AttData.CredentialID, attestationCertificates(parsedResponse)); err != nil {
+		return nil, err
+	}
+
+	if err = webauthn.runRegistrationHook(ctx, trace, protocol.VerificationStepPostVerifyPolicy, webauthn.config.RegistrationHooks.PostVerify, user, parsedResponse); err != nil {
+		return nil, err
+	}
+
+	webauthn.config.compatibleTransports(parsedResponse)
+	webauthn.config.browserTransports(parsedResponse)
+
+	credential, err := MakeNewCredential(parsedResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = webauthn.consumeChallenge(ctx, trace, session, webauthn.config.Timeouts.Registration); err != nil {
+		return nil, err
+	}
+
+	credential.Recovery = session.Recovery
+
+	return credential, nil
 }
 
 func defaultRegistrationCredentialParameters() []protocol.CredentialParameter {
@@ -0,0 +1,12 @@
+package webauthn
+
+import (
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// RelatedOrigins returns the protocol.RelatedOrigins document of the RPOrigins which are outside the domain of the
+// RPID, which must be served at the protocol.RelatedOriginsPath of the RPID for clients to allow these origins to use
+// the RPID. The webauthnhttp.RelatedOriginsHandler serves the document.
+func (webauthn *WebAuthn) RelatedOrigins() protocol.RelatedOrigins {
+	return protocol.NewRelatedOrigins(webauthn.config.RPID, webauthn.config.RPOrigins)
+}
@@ -0,0 +1,57 @@
+package webauthn
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// ErrChallengeReplayed is returned by a ChallengeRegistry when the challenge was already consumed.
+var ErrChallengeReplayed = errors.New("webauthn: challenge replayed")
+
+// ChallengeRegistry is the registry of the challenges consumed by the ceremonies which completed verification, shared
+// by every instance of the application. The SessionData is usually stored by the client or in a cache which isn't
+// invalidated atomically, so without the registry the same response could finish a ceremony once on each instance.
+//
+// The challenge is consumed after the last check which may reject the response, including the policies and hooks, and
+// before the credential is updated or returned, so the responses which fail verification don't consume the challenge.
+type ChallengeRegistry interface {
+	// ConsumeChallenge records the challenge as consumed until it expires, or returns ErrChallengeReplayed if it was
+	// already recorded. It must be atomic across every instance, such as with SET NX in Redis or a unique constraint in
+	// a database, as a challenge must only be consumed once.
+	//
+	// The expires is zero when the timeout of the ceremony is not enforced, as the session never expires and the
+	// response could be replayed at any time. The challenge must then be recorded without an expiration.
+	ConsumeChallenge(ctx context.Context, challenge string, expires time.Time) (err error)
+}
+
+// consumeChallenge consumes the challenge of the SessionData in the ChallengeRegistry when it's configured. The
+// challenge is recorded until the session expires including the grace period, or without an expiration when the
+// session never expires.
+func (webauthn *WebAuthn) consumeChallenge(ctx context.Context, trace *protocol.VerificationTrace, session SessionData, timeouts TimeoutConfig) (err error) {
+	registry := webauthn.config.ChallengeRegistry
+
+	if registry == nil || trace.IsDiagnostic() {
+		return nil
+	}
+
+	var expires time.Time
+
+	metadata := map[string]string{"expires": "never"}
+
+	if !session.Expires.IsZero() {
+		expires = session.Expires.Add(timeouts.Grace)
+
+		metadata["expires"] = expires.Format(time.RFC3339)
+	}
+
+	if err = registry.ConsumeChallenge(ctx, session.Challenge, expires); errors.Is(err, ErrChallengeReplayed) {
+		err = protocol.ErrChallengeMismatch.
+			WithCode(protocol.CodeChallengeReplayed).
+			WithDetails("Challenge has already been used")
+	}
+
+	return trace.Step(protocol.VerificationStepChallengeReplay, err, metadata)
+}
@@ -0,0 +1,245 @@
+package webauthn_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+type memoryChallengeRegistry struct {
+	mu         sync.Mutex
+	challenges map[string]time.Time
+	now        func() time.Time
+}
+
+// ConsumeChallenge records the challenge until it expires like a cache with a TTL, or indefinitely when the expires is
+// zero.
+func (r *memoryChallengeRegistry) ConsumeChallenge(_ context.Context, challenge string, expires time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.challenges == nil {
+		r.challenges = map[string]time.Time{}
+	}
+
+	if recorded, ok := r.challenges[challenge]; ok && (recorded.IsZero() || r.now == nil || r.now().Before(recorded)) {
+		return webauthn.ErrChallengeReplayed
+	}
+
+	r.challenges[challenge] = expires
+
+	return nil
+}
+
+func TestWebAuthn_ChallengeRegistry(t *testing.T) {
+	registry := &memoryChallengeRegistry{}
+
+	replicas := make([]*webauthn.WebAuthn, 2)
+
+	for i := range replicas {
+		w, err := webauthn.New(&webauthn.Config{
+			RPID:              "example.com",
+			RPDisplayName:     "Example",
+			RPOrigins:         []string{"https://example.com"},
+			ChallengeRegistry: registry,
+		})
+		require.NoError(t, err)
+
+		replicas[i] = w
+	}
+
+	user := &bytesUser{}
+	authenticator := &webauthntest.Authenticator{}
+
+	creation, session, err := replicas[0].BeginRegistration(user)
+	require.NoError(t, err)
+
+	attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+	require.NoError(t, err)
+
+	r, err := webauthntest.NewRequest(attestation)
+	require.NoError(t, err)
+
+	credential, err := replicas[0].FinishRegistration(user, *session, r)
+	require.NoError(t, err)
+
+	r, err = webauthntest.NewRequest(attestation)
+	require.NoError(t, err)
+
+	_, err = replicas[1].FinishRegistration(user, *session, r)
+	assertErrorCode(t, protocol.CodeChallengeReplayed, err)
+
+	user.credentials = append(user.credentials, *credential)
+
+	assertion, session, err := replicas[1].BeginLogin(user)
+	require.NoError(t, err)
+
+	response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+	require.NoError(t, err)
+
+	r, err = webauthntest.NewRequest(response)
+	require.NoError(t, err)
+
+	_, err = replicas[1].FinishLogin(user, *session, r)
+	require.NoError(t, err)
+
+	r, err = webauthntest.NewRequest(response)
+	require.NoError(t, err)
+
+	_, err = replicas[0].FinishLogin(user, *session, r)
+	assertErrorCode(t, protocol.CodeChallengeReplayed, err)
+
+	assert.Len(t, registry.challenges, 2)
+
+	for _, expires := range registry.challenges {
+		assert.True(t, expires.IsZero())
+	}
+}
+
+func TestWebAuthn_ChallengeRegistryExpiry(t *testing.T) {
+	now := time.Now()
+
+	testCases := []struct {
+		name    string
+		enforce bool
+		err     protocol.ErrorCode
+	}{
+		{"ShouldRejectReplayAfterTTLWhenNotEnforced", false, protocol.CodeChallengeReplayed},
+		{"ShouldRejectExpiredSessionWhenEnforced", true, protocol.CodeSessionExpired},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := now
+
+			registry := &memoryChallengeRegistry{now: func() time.Time { return clock }}
+
+			w, err := webauthn.New(&webauthn.Config{
+				RPID:              "example.com",
+				RPDisplayName:     "Example",
+				RPOrigins:         []string{"https://example.com"},
+				ChallengeRegistry: registry,
+				Clock:             func() time.Time { return clock },
+				Timeouts: webauthn.TimeoutsConfig{
+					Registration: webauthn.TimeoutConfig{
+						Enforce:    tc.enforce,
+						Timeout:    time.Minute,
+						TimeoutUVD: time.Minute,
+						Grace:      time.Second,
+					},
+				},
+			})
+			require.NoError(t, err)
+
+			user := &bytesUser{}
+
+			creation, session, err := w.BeginRegistration(user)
+			require.NoError(t, err)
+
+			attestation, _, err := (&webauthntest.Authenticator{}).CreateCredential(creation.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err := webauthntest.NewRequest(attestation)
+			require.NoError(t, err)
+
+			_, err = w.FinishRegistration(user, *session, r)
+			require.NoError(t, err)
+
+			if tc.enforce {
+				assert.Equal(t, session.Expires.Add(time.Second), registry.challenges[session.Challenge])
+			} else {
+				assert.True(t, registry.challenges[session.Challenge].IsZero())
+			}
+
+			clock = clock.Add(24 * time.Hour)
+
+			r, err = webauthntest.NewRequest(attestation)
+			require.NoError(t, err)
+
+			_, err = w.FinishRegistration(user, *session, r)
+			assertErrorCode(t, tc.err, err)
+		})
+	}
+}
+
+func TestWebAuthn_ChallengeRegistryRejected(t *testing.T) {
+	registry := &memoryChallengeRegistry{}
+
+	reject := errors.New("rejected")
+
+	rejecting, err := webauthn.New(&webauthn.Config{
+		RPID:              "example.com",
+		RPDisplayName:     "Example",
+		RPOrigins:         []string{"https://example.com"},
+		ChallengeRegistry: registry,
+		RegistrationHooks: webauthn.RegistrationHooks{
+			PostVerify: func(_ context.Context, _ webauthn.User, _ *protocol.ParsedCredentialCreationData) error {
+				return reject
+			},
+		},
+		LoginHooks: webauthn.LoginHooks{
+			PostVerify: func(_ context.Context, _ *webauthn.LoginResult) error {
+				return reject
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	accepting, err := webauthn.New(&webauthn.Config{
+		RPID:              "example.com",
+		RPDisplayName:     "Example",
+		RPOrigins:         []string{"https://example.com"},
+		ChallengeRegistry: registry,
+	})
+	require.NoError(t, err)
+
+	user := &bytesUser{}
+	authenticator := &webauthntest.Authenticator{}
+
+	creation, session, err := accepting.BeginRegistration(user)
+	require.NoError(t, err)
+
+	attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+	require.NoError(t, err)
+
+	r, err := webauthntest.NewRequest(attestation)
+	require.NoError(t, err)
+
+	_, err = rejecting.FinishRegistration(user, *session, r)
+	assertErrorCode(t, protocol.CodePolicyRejected, err)
+
+	r, err = webauthntest.NewRequest(attestation)
+	require.NoError(t, err)
+
+	credential, err := accepting.FinishRegistration(user, *session, r)
+	require.NoError(t, err)
+
+	user.credentials = append(user.credentials, *credential)
+
+	assertion, session, err := accepting.BeginLogin(user)
+	require.NoError(t, err)
+
+	response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+	require.NoError(t, err)
+
+	r, err = webauthntest.NewRequest(response)
+	require.NoError(t, err)
+
+	_, err = rejecting.FinishLogin(user, *session, r)
+	assertErrorCode(t, protocol.CodePolicyRejected, err)
+
+	r, err = webauthntest.NewRequest(response)
+	require.NoError(t, err)
+
+	_, err = accepting.FinishLogin(user, *session, r)
+	require.NoError(t, err)
+}
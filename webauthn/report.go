@@ -0,0 +1,193 @@
+package webauthn
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/go-webauthn/webauthn/metadata"
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// reportDecisionSteps are the verification steps which are policy decisions included in an AttestationReport.
+var reportDecisionSteps = map[string]bool{
+	protocol.VerificationStepPreVerifyPolicy:      true,
+	protocol.VerificationStepAttestationStatement: true,
+	protocol.VerificationStepMetadata:             true,
+	protocol.VerificationStepAlgorithm:            true,
+	protocol.VerificationStepTrustPolicy:          true,
+	protocol.VerificationStepPostVerifyPolicy:     true,
+}
+
+// AttestationReport is the normalized report of a verified registration, which is intended to be serialized as JSON
+// and shipped to a SIEM as compliance evidence of the authenticators which are trusted by the Relying Party. Like the
+// AuditRecord it only contains hashes and public identifiers.
+type AttestationReport struct {
+	// Time is the time the report was created.
+	Time time.Time `json:"time"`
+
+	// RPID is the configured Relying Party ID.
+	RPID string `json:"rp_id"`
+
+	// CredentialID is the ID of the registered credential.
+	CredentialID protocol.URLEncodedBase64 `json:"credential_id"`
+
+	// Format is the attestation statement format, such as "packed" or "none".
+	Format string `json:"format"`
+
+	// AttestationType is the attestation type the attestation statement verified as, such as "basic_full" or
+	// "basic_surrogate". It's only known when the report is created with the steps of the registration.
+	AttestationType string `json:"attestation_type,omitempty"`
+
+	// AAGUID is the AAGUID of the authenticator model.
+	AAGUID string `json:"aaguid"`
+
+	// Authenticator is the human friendly name of the authenticator model if it's known.
+	Authenticator string `json:"authenticator,omitempty"`
+
+	// UserVerified is true if the authenticator verified the user.
+	UserVerified bool `json:"user_verified"`
+
+	// BackupEligible is true if the credential is backup eligible, such as a synced passkey.
+	BackupEligible bool `json:"backup_eligible"`
+
+	// Chain is the attestation certificate chain, starting with the attestation certificate.
+	Chain []AttestationReportCertificate `json:"chain,omitempty"`
+
+	// MetadataListed is true if the authenticator is present in the metadata.
+	MetadataListed bool `json:"mds_listed"`
+
+	// MetadataStatuses are the statuses of the status reports of the authenticator in the metadata.
+	MetadataStatuses []metadata.AuthenticatorStatus `json:"mds_statuses,omitempty"`
+
+	// Decisions are the policy decisions made while verifying the registration in order. It's only known when the
+	// report is created with the steps of the registration.
+	Decisions []AttestationReportDecision `json:"decisions,omitempty"`
+}
+
+// AttestationReportCertificate describes a certificate of the attestation certificate chain of an AttestationReport.
+type AttestationReportCertificate struct {
+	// Subject is the distinguished name of the subject of the certificate.
+	Subject string `json:"subject"`
+
+	// Issuer is the distinguished name of the issuer of the certificate.
+	Issuer string `json:"issuer"`
+
+	// SerialNumber is the hex encoded serial number of the certificate.
+	SerialNumber string `json:"serial_number"`
+
+	// NotBefore is the start of the validity period of the certificate.
+	NotBefore time.Time `json:"not_before"`
+
+	// NotAfter is the end of the validity period of the certificate.
+	NotAfter time.Time `json:"not_after"`
+
+	// Fingerprint is the hex encoded SHA-256 hash of the DER encoded certificate.
+	Fingerprint string `json:"fingerprint"`
+}
+
+// AttestationReportDecision is a policy decision of an AttestationReport.
+type AttestationReportDecision struct {
+	// Name is the name of the verification step which made the decision, such as protocol.VerificationStepMetadata.
+	Name string `json:"name"`
+
+	// Passed is true if the step accepted the registration.
+	Passed bool `json:"passed"`
+
+	// Error is the error the step rejected the registration with.
+	Error string `json:"error,omitempty"`
+}
+
+// NewAttestationReport returns the AttestationReport of the credential returned by FinishRegistration or one of its
+// variants. The steps are the verification steps of the registration, such as the Steps of the AuditRecord passed to
+// the AuditHook, and may be nil in which case the attestation type and the policy decisions are not reported. The
+// metadata is looked up with the AttestationPolicy Metadata provider, or the metadata.DefaultProvider if none is
+// configured.
+func (webauthn *WebAuthn) NewAttestationReport(ctx context.Context, credential *Credential, steps []protocol.VerificationStep) *AttestationReport {
+	report := &AttestationReport{
+		Time:           webauthn.config.now(),
+		RPID:           webauthn.config.RPID,
+		CredentialID:   credential.ID,
+		Format:         credential.AttestationType,
+		AAGUID:         uuid.Nil.String(),
+		UserVerified:   credential.Flags.UserVerified,
+		BackupEligible: credential.Flags.BackupEligible,
+	}
+
+	var certificates []*x509.Certificate
+
+	for _, raw := range credential.AttestationCertificates {
+		certificate, err := x509.ParseCertificate(raw)
+		if err != nil {
+			continue
+		}
+
+		certificates = append(certificates, certificate)
+
+		fingerprint := sha256.Sum256(raw)
+
+		report.Chain = append(report.Chain, AttestationReportCertificate{
+			Subject:      certificate.Subject.String(),
+			Issuer:       certificate.Issuer.String(),
+			SerialNumber: hex.EncodeToString(certificate.SerialNumber.Bytes()),
+			NotBefore:    certificate.NotBefore,
+			NotAfter:     certificate.NotAfter,
+			Fingerprint:  hex.EncodeToString(fingerprint[:]),
+		})
+	}
+
+	provider := webauthn.config.attestationPolicy(ctx).Metadata
+	if provider == nil {
+		provider = metadata.DefaultProvider
+	}
+
+	provider = metadata.WithContext(ctx, provider)
+
+	var (
+		entry metadata.MetadataBLOBPayloadEntry
+		found bool
+	)
+
+	if aaguid, err := uuid.FromBytes(credential.Authenticator.AAGUID); err == nil {
+		report.AAGUID = aaguid.String()
+
+		if aaguid != uuid.Nil {
+			entry, found = provider.LookupByAAGUID(aaguid)
+		}
+	}
+
+	if !found && len(certificates) != 0 {
+		entry, found = provider.LookupByCertificate(certificates[0])
+	}
+
+	if found {
+		report.MetadataListed = true
+
+		for _, status := range entry.StatusReports {
+			report.MetadataStatuses = append(report.MetadataStatuses, status.Status)
+		}
+	}
+
+	if name, ok := webauthn.AuthenticatorName(ctx, credential.Authenticator.AAGUID); ok {
+		report.Authenticator = name.Name
+	}
+
+	for _, step := range steps {
+		// The metadata may classify the attestation type of the statement, such as an anonymized attestation.
+		if step.Name == protocol.VerificationStepAttestationStatement || step.Name == protocol.VerificationStepMetadata {
+			if attestationType := step.Inputs["attestation_type"]; attestationType != "" {
+				report.AttestationType = attestationType
+			}
+		}
+
+		if reportDecisionSteps[step.Name] {
+			report.Decisions = append(report.Decisions, AttestationReportDecision{Name: step.Name, Passed: step.Passed, Error: step.Error})
+		}
+	}
+
+	return report
+}
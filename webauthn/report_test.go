@@ -0,0 +1,178 @@
+package webauthn_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/metadata"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+	"github.com/go-webauthn/webauthn/webauthntest/webauthnmock"
+)
+
+func TestWebAuthn_NewAttestationReport(t *testing.T) {
+	certified := uuid.MustParse("7b0c0d9b-7e5c-4b7a-9d4f-2c1a0e6e9c11")
+	other := uuid.MustParse("cb69481e-8ff7-4039-93ec-0a2729a154a8")
+
+	provider := &webauthnmock.MetadataProviderMock{
+		LookupByAAGUIDFunc: func(id uuid.UUID) (metadata.MetadataBLOBPayloadEntry, bool) {
+			if id != certified {
+				return metadata.MetadataBLOBPayloadEntry{}, false
+			}
+
+			return metadata.MetadataBLOBPayloadEntry{
+				AaGUID:        id.String(),
+				StatusReports: []metadata.StatusReport{{Status: metadata.FidoCertifiedL1}},
+			}, true
+		},
+		LookupByCertificateFunc: func(_ *x509.Certificate) (metadata.MetadataBLOBPayloadEntry, bool) {
+			return metadata.MetadataBLOBPayloadEntry{}, false
+		},
+	}
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name            string
+		authenticator   *webauthntest.Authenticator
+		format          string
+		attestationType string
+		listed          bool
+		statuses        []metadata.AuthenticatorStatus
+	}{
+		{"ShouldReportPacked", &webauthntest.Authenticator{AAGUID: other, Format: webauthntest.FormatPacked}, webauthntest.FormatPacked, string(metadata.BasicSurrogate), false, nil},
+		{"ShouldReportMetadataStatus", &webauthntest.Authenticator{AAGUID: certified}, webauthntest.FormatNone, "", true, []metadata.AuthenticatorStatus{metadata.FidoCertifiedL1}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var steps []protocol.VerificationStep
+
+			w, err := webauthn.New(&webauthn.Config{
+				RPID:              "example.com",
+				RPDisplayName:     "Example",
+				RPOrigins:         []string{"https://example.com"},
+				Clock:             func() time.Time { return now },
+				AttestationPolicy: protocol.AttestationPolicy{Metadata: provider},
+				TrustPolicy:       webauthn.TrustPolicy{Rules: []webauthn.TrustRule{{Name: "uv", UserVerified: true}}},
+				AuditHook: func(record webauthn.AuditRecord) {
+					steps = record.Steps
+				},
+			})
+			require.NoError(t, err)
+
+			user := &bytesUser{}
+
+			creation, session, err := w.BeginRegistration(user)
+			require.NoError(t, err)
+
+			attestation, _, err := tc.authenticator.CreateCredential(creation.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err := webauthntest.NewRequest(attestation)
+			require.NoError(t, err)
+
+			credential, err := w.FinishRegistration(user, *session, r)
+			require.NoError(t, err)
+
+			report := w.NewAttestationReport(context.Background(), credential, steps)
+
+			assert.Equal(t, now, report.Time)
+			assert.Equal(t, "example.com", report.RPID)
+			assert.Equal(t, protocol.URLEncodedBase64(credential.ID), report.CredentialID)
+			assert.Equal(t, tc.format, report.Format)
+			assert.Equal(t, tc.attestationType, report.AttestationType)
+			assert.Equal(t, tc.authenticator.AAGUID.String(), report.AAGUID)
+			assert.True(t, report.UserVerified)
+			assert.False(t, report.BackupEligible)
+			assert.Empty(t, report.Chain)
+			assert.Equal(t, tc.listed, report.MetadataListed)
+			assert.Equal(t, tc.statuses, report.MetadataStatuses)
+
+			var names []string
+
+			for _, decision := range report.Decisions {
+				assert.True(t, decision.Passed)
+
+				names = append(names, decision.Name)
+			}
+
+			assert.Subset(t, names, []string{protocol.VerificationStepAttestationStatement, protocol.VerificationStepMetadata, protocol.VerificationStepTrustPolicy})
+			assert.NotContains(t, names, protocol.VerificationStepSession)
+		})
+	}
+}
+
+func TestWebAuthn_NewAttestationReportChain(t *testing.T) {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+	})
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(0x1234),
+		Subject:      pkix.Name{CommonName: "Example Attestation", Organization: []string{"Example"}},
+		NotBefore:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(2034, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	credential := &webauthn.Credential{
+		ID:                      []byte("credential"),
+		AttestationType:         "packed",
+		AttestationCertificates: [][]byte{der, []byte("invalid")},
+	}
+
+	report := w.NewAttestationReport(context.Background(), credential, nil)
+
+	fingerprint := sha256.Sum256(der)
+
+	require.Len(t, report.Chain, 1)
+	assert.Equal(t, webauthn.AttestationReportCertificate{
+		Subject:      "CN=Example Attestation,O=Example",
+		Issuer:       "CN=Example Attestation,O=Example",
+		SerialNumber: "1234",
+		NotBefore:    template.NotBefore,
+		NotAfter:     template.NotAfter,
+		Fingerprint:  hex.EncodeToString(fingerprint[:]),
+	}, report.Chain[0])
+
+	assert.Equal(t, uuid.Nil.String(), report.AAGUID)
+	assert.Empty(t, report.AttestationType)
+	assert.Empty(t, report.Decisions)
+
+	data, err := json.Marshal(report)
+	require.NoError(t, err)
+
+	var fields map[string]interface{}
+
+	require.NoError(t, json.Unmarshal(data, &fields))
+
+	assert.Equal(t, "Y3JlZGVudGlhbA", fields["credential_id"])
+	assert.Equal(t, "packed", fields["format"])
+	assert.Equal(t, false, fields["mds_listed"])
+	assert.Len(t, fields["chain"], 1)
+	assert.NotContains(t, fields, "decisions")
+}
@@ -0,0 +1,74 @@
+package webauthn
+
+import (
+	"context"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// FinishRegistrationWithResponse is the same as FinishRegistrationCtx except the response was already parsed, such as
+// with protocol.CredentialCreationResponse Parse by frameworks which handle the request body themselves or when
+// replaying a recorded ceremony. Unlike CreateCredentialCtx the verification trace includes the parse step the same
+// as FinishRegistrationCtx, so the audit records of both are alike.
+func (webauthn *WebAuthn) FinishRegistrationWithResponse(ctx context.Context, user User, session SessionData, parsedResponse *protocol.ParsedCredentialCreationData) (*Credential, error) {
+	ctx, observer := webauthn.startCeremony(ctx, CeremonyFinishRegistration, user.WebAuthnID())
+
+	err := parsedResponseError(parsedResponse == nil)
+
+	observer.trace.Record(protocol.VerificationStepParse, err, nil)
+
+	if err != nil {
+		return observer.finish(nil, err)
+	}
+
+	observer.recordParsed(session, parsedResponse)
+
+	return observer.finish(webauthn.createCredential(ctx, observer.trace, user, session, parsedResponse, false))
+}
+
+// FinishLoginWithResponse is the same as FinishLoginCtx except the response was already parsed, such as with
+// protocol.CredentialAssertionResponse Parse. See FinishRegistrationWithResponse.
+func (webauthn *WebAuthn) FinishLoginWithResponse(ctx context.Context, user User, session SessionData, parsedResponse *protocol.ParsedCredentialAssertionData) (*Credential, error) {
+	ctx, observer := webauthn.startCeremony(ctx, CeremonyFinishLogin, user.WebAuthnID())
+
+	err := parsedResponseError(parsedResponse == nil)
+
+	observer.trace.Record(protocol.VerificationStepParse, err, nil)
+
+	if err != nil {
+		return observer.finish(nil, err)
+	}
+
+	observer.recordParsed(session, parsedResponse)
+
+	return observer.finish(loginCredential(webauthn.validateUserLogin(ctx, observer.trace, user, session, parsedResponse)))
+}
+
+// FinishDiscoverableLoginWithResponse is the same as FinishDiscoverableLoginCtx except the response was already
+// parsed, such as with protocol.CredentialAssertionResponse Parse. See FinishRegistrationWithResponse.
+func (webauthn *WebAuthn) FinishDiscoverableLoginWithResponse(ctx context.Context, handler DiscoverableUserHandler, session SessionData, parsedResponse *protocol.ParsedCredentialAssertionData) (*Credential, error) {
+	ctx, observer := webauthn.startCeremony(ctx, CeremonyFinishDiscoverableLogin, nil)
+
+	err := parsedResponseError(parsedResponse == nil)
+
+	observer.trace.Record(protocol.VerificationStepParse, err, nil)
+
+	if err != nil {
+		return observer.finish(nil, err)
+	}
+
+	observer.userID = parsedResponse.Response.UserHandle
+
+	observer.recordParsed(session, parsedResponse)
+
+	return observer.finish(loginCredential(webauthn.validateDiscoverableLogin(ctx, observer.trace, handler, session, parsedResponse)))
+}
+
+// parsedResponseError returns the error of a pre-parsed response which is missing.
+func parsedResponseError(missing bool) error {
+	if !missing {
+		return nil
+	}
+
+	return protocol.ErrBadRequest.WithCode(protocol.CodeResponseInvalid).WithDetails("Parsed response missing")
+}
@@ -0,0 +1,84 @@
+package webauthn_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+func TestWebAuthn_FinishWithResponse(t *testing.T) {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	user := &bytesUser{}
+	authenticator := &webauthntest.Authenticator{}
+
+	creation, session, err := w.BeginRegistration(user)
+	require.NoError(t, err)
+
+	attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+	require.NoError(t, err)
+
+	body, err := json.Marshal(attestation)
+	require.NoError(t, err)
+
+	parsedCreation, err := protocol.ParseCredentialCreationResponseBytes(body)
+	require.NoError(t, err)
+
+	credential, err := w.FinishRegistrationWithResponse(ctx, user, *session, parsedCreation)
+	require.NoError(t, err)
+
+	user.credentials = append(user.credentials, *credential)
+
+	assertion, session, err := w.BeginLogin(user)
+	require.NoError(t, err)
+
+	response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+	require.NoError(t, err)
+
+	body, err = json.Marshal(response)
+	require.NoError(t, err)
+
+	parsedAssertion, err := protocol.ParseCredentialRequestResponseBytes(body)
+	require.NoError(t, err)
+
+	credential, err = w.FinishLoginWithResponse(ctx, user, *session, parsedAssertion)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), credential.Authenticator.SignCount)
+
+	assertion, session, err = w.BeginDiscoverableLogin()
+	require.NoError(t, err)
+
+	response, err = authenticator.GetAssertion(assertion.Response, "https://example.com")
+	require.NoError(t, err)
+
+	body, err = json.Marshal(response)
+	require.NoError(t, err)
+
+	parsedAssertion, err = protocol.ParseCredentialRequestResponseBytes(body)
+	require.NoError(t, err)
+
+	credential, err = w.FinishDiscoverableLoginWithResponse(ctx, func(_, _ []byte) (webauthn.User, error) {
+		return user, nil
+	}, *session, parsedAssertion)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), credential.Authenticator.SignCount)
+
+	_, err = w.FinishRegistrationWithResponse(ctx, user, *session, nil)
+	assertErrorCode(t, protocol.CodeResponseInvalid, err)
+
+	_, err = w.FinishLoginWithResponse(ctx, user, *session, nil)
+	assertErrorCode(t, protocol.CodeResponseInvalid, err)
+}
@@ -0,0 +1,67 @@
+package webauthn
+
+// SAML 2.0 authentication context classes of a login.
+//
+// See: https://docs.oasis-open.org/security/saml/v2.0/saml-authn-context-2.0-os.pdf
+const (
+	// SAMLPasswordProtectedTransport is the class of a login which only tested the presence of the user. It's the
+	// class most service providers expect for a single-factor login.
+	SAMLPasswordProtectedTransport = "urn:oasis:names:tc:SAML:2.0:ac:classes:PasswordProtectedTransport"
+
+	// SAMLMultiFactor is the REFEDS MFA profile which is the class of a login which verified the user.
+	//
+	// See: https://refeds.org/profile/mfa
+	SAMLMultiFactor = "https://refeds.org/profile/mfa"
+
+	// SAMLPhishingResistantHardware is the class of a login which verified the user with a hardware-backed key, which
+	// is the closest registered class to a device-bound key unlocked with a PIN or biometric.
+	SAMLPhishingResistantHardware = "urn:oasis:names:tc:SAML:2.0:ac:classes:SmartcardPKI"
+)
+
+// SAMLAuthnContextMapper chooses the AuthnContextClassRef of a SAML assertion from the LoginResult, which is useful
+// for identity providers using this library. The key is considered hardware-backed under the same conditions as the
+// AuthenticationContextMapper. The zero value uses the SAMLPasswordProtectedTransport, SAMLMultiFactor, and
+// SAMLPhishingResistantHardware classes.
+type SAMLAuthnContextMapper struct {
+	// SingleFactor is the class of a login which did not verify the user. The default is
+	// SAMLPasswordProtectedTransport.
+	SingleFactor string
+
+	// MultiFactor is the class of a login which verified the user with a key which is not hardware-backed. The default
+	// is SAMLMultiFactor.
+	MultiFactor string
+
+	// Hardware is the class of a login which verified the user with a hardware-backed key. The default is
+	// SAMLPhishingResistantHardware.
+	Hardware string
+
+	// RequireCrossPlatformHardware only considers a key hardware-backed if the authenticator attachment reported by the
+	// response is cross-platform.
+	RequireCrossPlatformHardware bool
+}
+
+// Map returns the AuthnContextClassRef of the LoginResult.
+func (m SAMLAuthnContextMapper) Map(result *LoginResult) string {
+	switch {
+	case !result.Credential.Flags.UserVerified:
+		return valueOrDefault(m.SingleFactor, SAMLPasswordProtectedTransport)
+	case isHardwareBacked(result, m.RequireCrossPlatformHardware):
+		return valueOrDefault(m.Hardware, SAMLPhishingResistantHardware)
+	default:
+		return valueOrDefault(m.MultiFactor, SAMLMultiFactor)
+	}
+}
+
+// SAMLAuthnContextClassRef returns the AuthnContextClassRef of the LoginResult chosen with the zero value of the
+// SAMLAuthnContextMapper.
+func (r *LoginResult) SAMLAuthnContextClassRef() string {
+	return SAMLAuthnContextMapper{}.Map(r)
+}
+
+func valueOrDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
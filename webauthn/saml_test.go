@@ -0,0 +1,73 @@
+package webauthn_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+func TestSAMLAuthnContextMapper_Map(t *testing.T) {
+	testCases := []struct {
+		name       string
+		mapper     webauthn.SAMLAuthnContextMapper
+		flags      webauthn.CredentialFlags
+		attachment protocol.AuthenticatorAttachment
+		expected   string
+	}{
+		{
+			"ShouldMapUserPresent",
+			webauthn.SAMLAuthnContextMapper{},
+			webauthn.CredentialFlags{UserPresent: true},
+			protocol.CrossPlatform,
+			webauthn.SAMLPasswordProtectedTransport,
+		},
+		{
+			"ShouldMapHardwareUserVerified",
+			webauthn.SAMLAuthnContextMapper{},
+			webauthn.CredentialFlags{UserPresent: true, UserVerified: true},
+			protocol.CrossPlatform,
+			webauthn.SAMLPhishingResistantHardware,
+		},
+		{
+			"ShouldMapSyncedPasskey",
+			webauthn.SAMLAuthnContextMapper{},
+			webauthn.CredentialFlags{UserPresent: true, UserVerified: true, BackupEligible: true, BackupState: true},
+			protocol.Platform,
+			webauthn.SAMLMultiFactor,
+		},
+		{
+			"ShouldRequireCrossPlatformHardware",
+			webauthn.SAMLAuthnContextMapper{RequireCrossPlatformHardware: true},
+			webauthn.CredentialFlags{UserPresent: true, UserVerified: true},
+			protocol.Platform,
+			webauthn.SAMLMultiFactor,
+		},
+		{
+			"ShouldMapCustomClasses",
+			webauthn.SAMLAuthnContextMapper{SingleFactor: "urn:example:sfa", MultiFactor: "urn:example:mfa", Hardware: "urn:example:hwk"},
+			webauthn.CredentialFlags{UserPresent: true, UserVerified: true},
+			protocol.Platform,
+			"urn:example:hwk",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := &webauthn.LoginResult{
+				Credential:     &webauthn.Credential{Flags: tc.flags},
+				ParsedResponse: &protocol.ParsedCredentialAssertionData{},
+			}
+
+			result.ParsedResponse.AuthenticatorAttachment = tc.attachment
+
+			assert.Equal(t, tc.expected, tc.mapper.Map(result))
+
+			if tc.mapper == (webauthn.SAMLAuthnContextMapper{}) {
+				assert.Equal(t, tc.expected, result.SAMLAuthnContextClassRef())
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package webauthn_test
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+// The members of the JSON types of @simplewebauthn/browser, which are the members of the JSON types of the WebAuthn
+// Level 3 specification.
+var (
+	simpleCreationOptionsMembers = []string{
+		"rp", "user", "challenge", "pubKeyCredParams", "timeout", "excludeCredentials", "authenticatorSelection",
+		"hints", "attestation", "attestationFormats", "extensions",
+	}
+	simpleRequestOptionsMembers = []string{
+		"challenge", "timeout", "rpId", "allowCredentials", "userVerification", "hints", "extensions",
+	}
+	simpleRelyingPartyMembers  = []string{"id", "name"}
+	simpleUserMembers          = []string{"id", "name", "displayName"}
+	simpleDescriptorMembers    = []string{"id", "type", "transports"}
+	simpleSelectionMembers     = []string{"authenticatorAttachment", "residentKey", "requireResidentKey", "userVerification"}
+	simpleCredParameterMembers = []string{"type", "alg"}
+)
+
+// simpleRegistrationResponse is the RegistrationResponseJSON of @simplewebauthn/browser.
+type simpleRegistrationResponse struct {
+	ID       string `json:"id"`
+	RawID    string `json:"rawId"`
+	Response struct {
+		ClientDataJSON     string   `json:"clientDataJSON"`
+		AttestationObject  string   `json:"attestationObject"`
+		AuthenticatorData  string   `json:"authenticatorData,omitempty"`
+		Transports         []string `json:"transports,omitempty"`
+		PublicKeyAlgorithm int      `json:"publicKeyAlgorithm,omitempty"`
+		PublicKey          string   `json:"publicKey,omitempty"`
+	} `json:"response"`
+	AuthenticatorAttachment string                 `json:"authenticatorAttachment,omitempty"`
+	ClientExtensionResults  map[string]interface{} `json:"clientExtensionResults"`
+	Type                    string                 `json:"type"`
+}
+
+// simpleAuthenticationResponse is the AuthenticationResponseJSON of @simplewebauthn/browser.
+type simpleAuthenticationResponse struct {
+	ID       string `json:"id"`
+	RawID    string `json:"rawId"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON"`
+		AuthenticatorData string `json:"authenticatorData"`
+		Signature         string `json:"signature"`
+		UserHandle        string `json:"userHandle,omitempty"`
+	} `json:"response"`
+	AuthenticatorAttachment string                 `json:"authenticatorAttachment,omitempty"`
+	ClientExtensionResults  map[string]interface{} `json:"clientExtensionResults"`
+	Type                    string                 `json:"type"`
+}
+
+func TestSimpleWebAuthnCompatibility(t *testing.T) {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+	})
+	require.NoError(t, err)
+
+	user := &bytesUser{}
+	authenticator := &webauthntest.Authenticator{Attachment: protocol.Platform}
+	required := true
+
+	creation, session, err := w.BeginRegistration(user,
+		webauthn.WithExclusions([]protocol.CredentialDescriptor{{Type: protocol.PublicKeyCredentialType, CredentialID: []byte("excluded"), Transport: []protocol.AuthenticatorTransport{protocol.USB}}}),
+		webauthn.WithAuthenticatorSelection(protocol.AuthenticatorSelection{
+			AuthenticatorAttachment: protocol.Platform,
+			RequireResidentKey:      &required,
+			ResidentKey:             protocol.ResidentKeyRequirementRequired,
+			UserVerification:        protocol.VerificationRequired,
+		}),
+		webauthn.WithExtensions(protocol.AuthenticationExtensions{"credProps": true}),
+	)
+	require.NoError(t, err)
+
+	options := marshalMembers(t, creation.Response)
+
+	assertMembers(t, simpleCreationOptionsMembers, options)
+	assertMembers(t, simpleRelyingPartyMembers, options["rp"])
+	assertMembers(t, simpleUserMembers, options["user"])
+	assertMembers(t, simpleSelectionMembers, options["authenticatorSelection"])
+
+	for _, descriptor := range options["excludeCredentials"].([]interface{}) {
+		assertMembers(t, simpleDescriptorMembers, descriptor)
+	}
+
+	for _, parameter := range options["pubKeyCredParams"].([]interface{}) {
+		assertMembers(t, simpleCredParameterMembers, parameter)
+	}
+
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(user.WebAuthnID()), options["user"].(map[string]interface{})["id"])
+	assert.Equal(t, session.Challenge, options["challenge"])
+
+	attestation, created, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+	require.NoError(t, err)
+
+	var object protocol.AttestationObject
+
+	require.NoError(t, webauthncbor.Unmarshal(attestation.AttestationResponse.AttestationObject, &object))
+
+	publicKey, err := x509.MarshalPKIXPublicKey(created.PrivateKey.Public())
+	require.NoError(t, err)
+
+	registration := simpleRegistrationResponse{
+		ID:                      attestation.ID,
+		RawID:                   attestation.RawID.String(),
+		AuthenticatorAttachment: string(protocol.Platform),
+		ClientExtensionResults:  map[string]interface{}{"credProps": map[string]interface{}{"rk": true}},
+		Type:                    "public-key",
+	}
+
+	registration.Response.ClientDataJSON = attestation.AttestationResponse.ClientDataJSON.String()
+	registration.Response.AttestationObject = attestation.AttestationResponse.AttestationObject.String()
+	registration.Response.AuthenticatorData = base64.RawURLEncoding.EncodeToString(object.RawAuthData)
+	registration.Response.Transports = []string{"hybrid", "internal"}
+	registration.Response.PublicKeyAlgorithm = int(created.Algorithm)
+	registration.Response.PublicKey = base64.RawURLEncoding.EncodeToString(publicKey)
+
+	body, err := json.Marshal(registration)
+	require.NoError(t, err)
+
+	credential, err := w.FinishRegistrationBytes(user, *session, body)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, credential.ID)
+	assert.Equal(t, []protocol.AuthenticatorTransport{protocol.Hybrid, protocol.Internal}, credential.Transport)
+
+	user.credentials = append(user.credentials, *credential)
+
+	assertion, session, err := w.BeginLogin(user,
+		webauthn.WithUserVerification(protocol.VerificationPreferred),
+		webauthn.WithAssertionExtensions(protocol.AuthenticationExtensions{"appid": "https://example.com"}),
+	)
+	require.NoError(t, err)
+
+	options = marshalMembers(t, assertion.Response)
+
+	assertMembers(t, simpleRequestOptionsMembers, options)
+
+	for _, descriptor := range options["allowCredentials"].([]interface{}) {
+		assertMembers(t, simpleDescriptorMembers, descriptor)
+	}
+
+	testCases := []struct {
+		name       string
+		userHandle bool
+	}{
+		{"ShouldAcceptResponseWithUserHandle", true},
+		{"ShouldAcceptResponseWithoutUserHandle", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+			require.NoError(t, err)
+
+			authentication := simpleAuthenticationResponse{
+				ID:                     response.ID,
+				RawID:                  response.RawID.String(),
+				ClientExtensionResults: map[string]interface{}{},
+				Type:                   "public-key",
+			}
+
+			authentication.Response.ClientDataJSON = response.AssertionResponse.ClientDataJSON.String()
+			authentication.Response.AuthenticatorData = response.AssertionResponse.AuthenticatorData.String()
+			authentication.Response.Signature = response.AssertionResponse.Signature.String()
+
+			if tc.userHandle {
+				authentication.Response.UserHandle = response.AssertionResponse.UserHandle.String()
+			}
+
+			body, err := json.Marshal(authentication)
+			require.NoError(t, err)
+
+			_, err = w.FinishLoginBytes(user, *session, body)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func marshalMembers(t *testing.T, v interface{}) map[string]interface{} {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	var members map[string]interface{}
+
+	require.NoError(t, json.Unmarshal(data, &members))
+
+	return members
+}
+
+func assertMembers(t *testing.T, expected []string, v interface{}) {
+	require.IsType(t, map[string]interface{}{}, v)
+
+	for member := range v.(map[string]interface{}) {
+		assert.Contains(t, expected, member)
+	}
+}
@@ -0,0 +1,180 @@
+package webauthn
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// StepUpProof is the proof returned by FinishStepUp that the user recently re-authenticated with user verification,
+// which is useful for protecting sensitive actions such as changing the credentials of the account.
+type StepUpProof struct {
+	// UserID is the user handle of the user who re-authenticated.
+	UserID []byte `json:"user_id"`
+
+	// CredentialID is the ID of the credential used to re-authenticate.
+	CredentialID []byte `json:"credential_id"`
+
+	// AuthenticatedAt is the time the re-authentication was verified.
+	AuthenticatedAt time.Time `json:"authenticated_at"`
+}
+
+// BeginStepUp is the same as BeginLogin except the login is a step-up re-authentication of a user who is already
+// authenticated. User verification is required regardless of the options, and the session always expires after
+// Timeouts.StepUp. The StepUpKey must be configured.
+func (webauthn *WebAuthn) BeginStepUp(user User, opts ...LoginOption) (*protocol.CredentialAssertion, *SessionData, error) {
+	return webauthn.BeginStepUpCtx(context.Background(), user, opts...)
+}
+
+// BeginStepUpCtx is the same as BeginStepUp except it accepts a context.Context.
+func (webauthn *WebAuthn) BeginStepUpCtx(ctx context.Context, user User, opts ...LoginOption) (*protocol.CredentialAssertion, *SessionData, error) {
+	if len(webauthn.config.StepUpKey) == 0 {
+		return nil, nil, fmt.Errorf(errFmtConfigValidate, fmt.Errorf(errFmtFieldEmpty, "StepUpKey"))
+	}
+
+	opts = append(opts, WithUserVerification(protocol.VerificationRequired), func(cro *protocol.PublicKeyCredentialRequestOptions) {
+		cro.Timeout = int(webauthn.config.Timeouts.StepUp.Milliseconds())
+	})
+
+	assertion, session, err := webauthn.BeginLoginCtx(ctx, user, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	session.StepUp = true
+	session.Expires = session.CreatedAt.Add(webauthn.config.Timeouts.StepUp)
+
+	return assertion, session, nil
+}
+
+// FinishStepUp is the same as FinishLogin except the session must have been started with BeginStepUp, and the proof
+// of the re-authentication is returned along with the Credential. The proof is an opaque string which can be stored
+// with the session of the user and later verified with VerifyStepUpProof.
+func (webauthn *WebAuthn) FinishStepUp(user User, session SessionData, response *http.Request) (credential *Credential, proof string, err error) {
+	return webauthn.FinishStepUpCtx(requestContext(response), user, session, response)
+}
+
+// FinishStepUpCtx is the same as FinishStepUp except the provided context is used instead of the context of the
+// request.
+func (webauthn *WebAuthn) FinishStepUpCtx(ctx context.Context, user User, session SessionData, response *http.Request) (credential *Credential, proof string, err error) {
+	ctx, observer := webauthn.startCeremony(connectionContext(ctx, response), CeremonyFinishLogin, user.WebAuthnID())
+
+	observer.recordRequest(session, response)
+
+	webauthn.config.compatibleRequest(response)
+
+	parsedResponse, err := protocol.ParseCredentialRequestResponseWithLimit(response, webauthn.config.responseBodyLimit())
+
+	observer.trace.Record(protocol.VerificationStepParse, err, nil)
+
+	if err != nil {
+		_, err = observer.finish(nil, err)
+
+		return nil, "", err
+	}
+
+	if credential, err = observer.finish(webauthn.validateStepUp(ctx, observer.trace, user, session, parsedResponse)); err != nil {
+		return nil, "", err
+	}
+
+	if proof, err = webauthn.config.signStepUpProof(StepUpProof{
+		UserID:          user.WebAuthnID(),
+		CredentialID:    credential.ID,
+		AuthenticatedAt: webauthn.config.now(),
+	}); err != nil {
+		return nil, "", err
+	}
+
+	return credential, proof, nil
+}
+
+// VerifyStepUpProof verifies the proof was returned by FinishStepUp for the user no longer than maxAge ago, returning
+// the decoded StepUpProof.
+func (webauthn *WebAuthn) VerifyStepUpProof(user User, proof string, maxAge time.Duration) (*StepUpProof, error) {
+	decoded, err := webauthn.config.openStepUpProof(proof)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(decoded.UserID, user.WebAuthnID()) {
+		return nil, protocol.ErrVerification.WithCode(protocol.CodeStepUpProofInvalid).WithDetails("Step-up proof belongs to a different user")
+	}
+
+	if now := webauthn.config.now(); now.Sub(decoded.AuthenticatedAt) > maxAge {
+		return nil, protocol.ErrSessionExpired.
+			WithCode(protocol.CodeStepUpProofExpired).
+			WithDetails("Step-up proof has expired").
+			WithInfo(fmt.Sprintf("Authenticated: %s, Max Age: %s, Received: %s", decoded.AuthenticatedAt.Format(time.RFC3339Nano), maxAge, now.Format(time.RFC3339Nano)))
+	}
+
+	return decoded, nil
+}
+
+func (webauthn *WebAuthn) validateStepUp(ctx context.Context, trace *protocol.VerificationTrace, user User, session SessionData, parsedResponse *protocol.ParsedCredentialAssertionData) (*Credential, error) {
+	if !session.StepUp {
+		err := protocol.ErrBadRequest.WithCode(protocol.CodeSessionNotStepUp).WithDetails("Session was not initiated as a step-up login")
+
+		return nil, trace.Step(protocol.VerificationStepSession, err, map[string]string{"user_id_hash": auditHash(user.WebAuthnID())})
+	}
+
+	return loginCredential(webauthn.validateUserLogin(ctx, trace, user, session, parsedResponse))
+}
+
+// signStepUpProof encodes the StepUpProof as the base64url encoding of its JSON followed by a period and the base64url
+// encoding of the HMAC-SHA256 of the JSON under the StepUpKey.
+func (config *Config) signStepUpProof(proof StepUpProof) (string, error) {
+	payload, err := json.Marshal(proof)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(config.stepUpMAC(payload)), nil
+}
+
+func (config *Config) openStepUpProof(proof string) (*StepUpProof, error) {
+	errInvalid := protocol.ErrVerification.WithCode(protocol.CodeStepUpProofInvalid).WithDetails("Error validating step-up proof")
+
+	if len(config.StepUpKey) == 0 {
+		return nil, errInvalid
+	}
+
+	encodedPayload, encodedMAC, ok := strings.Cut(proof, ".")
+	if !ok {
+		return nil, errInvalid
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, errInvalid
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(encodedMAC)
+	if err != nil || !hmac.Equal(mac, config.stepUpMAC(payload)) {
+		return nil, errInvalid
+	}
+
+	decoded := &StepUpProof{}
+
+	if err = json.Unmarshal(payload, decoded); err != nil {
+		return nil, errInvalid
+	}
+
+	return decoded, nil
+}
+
+func (config *Config) stepUpMAC(payload []byte) []byte {
+	mac := hmac.New(sha256.New, config.StepUpKey)
+
+	mac.Write(payload)
+
+	return mac.Sum(nil)
+}
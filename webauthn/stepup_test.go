@@ -0,0 +1,188 @@
+package webauthn_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+type otherUser struct {
+	bytesUser
+}
+
+func (u *otherUser) WebAuthnID() []byte {
+	return []byte("5678")
+}
+
+func TestWebAuthn_StepUp(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	w, user, authenticator := newStepUpWebAuthn(t, &now)
+
+	assertion, session, err := w.BeginStepUp(user, webauthn.WithUserVerification(protocol.VerificationDiscouraged))
+	require.NoError(t, err)
+
+	assert.Equal(t, protocol.VerificationRequired, assertion.Response.UserVerification)
+	assert.Equal(t, 60000, assertion.Response.Timeout)
+	assert.True(t, session.StepUp)
+	assert.Equal(t, now.Add(time.Minute), session.Expires)
+
+	response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+	require.NoError(t, err)
+
+	r, err := webauthntest.NewRequest(response)
+	require.NoError(t, err)
+
+	credential, proof, err := w.FinishStepUp(user, *session, r)
+	require.NoError(t, err)
+	require.NotEmpty(t, proof)
+
+	decoded, err := w.VerifyStepUpProof(user, proof, time.Minute*5)
+	require.NoError(t, err)
+
+	assert.Equal(t, user.WebAuthnID(), decoded.UserID)
+	assert.Equal(t, credential.ID, decoded.CredentialID)
+	assert.True(t, now.Equal(decoded.AuthenticatedAt))
+
+	testCases := []struct {
+		name     string
+		user     webauthn.User
+		proof    string
+		advance  time.Duration
+		expected protocol.ErrorCode
+	}{
+		{"ShouldRejectOtherUser", &otherUser{}, proof, 0, protocol.CodeStepUpProofInvalid},
+		{"ShouldRejectTamperedProof", user, "e30." + proof[len(proof)-43:], 0, protocol.CodeStepUpProofInvalid},
+		{"ShouldRejectMalformedProof", user, "malformed", 0, protocol.CodeStepUpProofInvalid},
+		{"ShouldRejectExpiredProof", user, proof, time.Minute*5 + time.Second, protocol.CodeStepUpProofExpired},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			now = time.Unix(1700000000, 0).Add(tc.advance)
+
+			_, err := w.VerifyStepUpProof(tc.user, tc.proof, time.Minute*5)
+
+			require.IsType(t, &protocol.Error{}, err)
+			assert.Equal(t, tc.expected, err.(*protocol.Error).Code)
+		})
+	}
+}
+
+func TestWebAuthn_FinishStepUpErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		flags    protocol.AuthenticatorFlags
+		stepUp   bool
+		advance  time.Duration
+		expected protocol.ErrorCode
+	}{
+		{"ShouldRejectWithoutUserVerification", protocol.FlagUserPresent, true, 0, protocol.CodeUVRequired},
+		{"ShouldRejectLoginSession", 0, false, 0, protocol.CodeSessionNotStepUp},
+		{"ShouldRejectExpiredSession", 0, true, time.Minute * 2, protocol.CodeSessionExpired},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Unix(1700000000, 0)
+
+			w, user, authenticator := newStepUpWebAuthn(t, &now)
+
+			authenticator.Flags = tc.flags
+
+			begin := w.BeginStepUp
+			if !tc.stepUp {
+				begin = w.BeginLogin
+			}
+
+			assertion, session, err := begin(user)
+			require.NoError(t, err)
+
+			response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err := webauthntest.NewRequest(response)
+			require.NoError(t, err)
+
+			now = now.Add(tc.advance)
+
+			credential, proof, err := w.FinishStepUp(user, *session, r)
+
+			assert.Nil(t, credential)
+			assert.Empty(t, proof)
+			require.IsType(t, &protocol.Error{}, err)
+			assert.Equal(t, tc.expected, err.(*protocol.Error).Code)
+		})
+	}
+}
+
+func TestWebAuthn_BeginStepUpWithoutKey(t *testing.T) {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+	})
+	require.NoError(t, err)
+
+	_, _, err = w.BeginStepUp(&bytesUser{credentials: []webauthn.Credential{{ID: []byte("id")}}})
+
+	assert.EqualError(t, err, "error occurred validating the configuration: the field 'StepUpKey' must be configured but it is empty")
+
+	_, err = w.VerifyStepUpProof(&bytesUser{}, "e30.e30", time.Minute)
+
+	require.IsType(t, &protocol.Error{}, err)
+	assert.Equal(t, protocol.CodeStepUpProofInvalid, err.(*protocol.Error).Code)
+}
+
+func TestNew_StepUpKeyTooShort(t *testing.T) {
+	_, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+		StepUpKey:     []byte("short"),
+	})
+
+	assert.EqualError(t, err, "error occurred validating the configuration: the field 'StepUpKey' must be at least 32 bytes but it is 5 bytes")
+}
+
+// newStepUpWebAuthn returns a *webauthn.WebAuthn using the time of now as the clock, and a user with a credential
+// registered with the returned authenticator.
+func newStepUpWebAuthn(t *testing.T, now *time.Time) (*webauthn.WebAuthn, *bytesUser, *webauthntest.Authenticator) {
+	t.Helper()
+
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+		StepUpKey:     []byte("0123456789abcdef0123456789abcdef"),
+		Clock: func() time.Time {
+			return *now
+		},
+	})
+	require.NoError(t, err)
+
+	user := &bytesUser{}
+	authenticator := &webauthntest.Authenticator{}
+
+	creation, session, err := w.BeginRegistration(user)
+	require.NoError(t, err)
+
+	attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+	require.NoError(t, err)
+
+	r, err := webauthntest.NewRequest(attestation)
+	require.NoError(t, err)
+
+	credential, err := w.FinishRegistration(user, *session, r)
+	require.NoError(t, err)
+
+	user.credentials = append(user.credentials, *credential)
+
+	return w, user, authenticator
+}
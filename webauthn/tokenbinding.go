@@ -0,0 +1,39 @@
+package webauthn
+
+import (
+	"context"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+type tokenBindingContextKey struct{}
+
+// ContextWithTokenBinding returns a copy of the context which carries the Token Binding state of the TLS connection
+// the response is received over. The state is verified by the FinishRegistration and FinishLogin methods when the
+// request has the returned context, according to the Config.TokenBindingPolicy.
+func ContextWithTokenBinding(ctx context.Context, state *protocol.TokenBindingState) context.Context {
+	return context.WithValue(ctx, tokenBindingContextKey{}, state)
+}
+
+// TokenBindingFromContext returns the Token Binding state carried by the context, or nil if it has none.
+func TokenBindingFromContext(ctx context.Context) *protocol.TokenBindingState {
+	state, _ := ctx.Value(tokenBindingContextKey{}).(*protocol.TokenBindingState)
+
+	return state
+}
+
+func (webauthn *WebAuthn) verifyTokenBinding(ctx context.Context, trace *protocol.VerificationTrace, clientData *protocol.CollectedClientData) error {
+	if webauthn.config.TokenBindingPolicy == protocol.TokenBindingPolicyIgnore {
+		return nil
+	}
+
+	state := TokenBindingFromContext(ctx)
+
+	inputs := map[string]string{"status": string(protocol.NotSupported)}
+
+	if state != nil && state.Status != "" {
+		inputs["status"] = string(state.Status)
+	}
+
+	return trace.Step(protocol.VerificationStepTokenBinding, clientData.VerifyTokenBinding(state, webauthn.config.TokenBindingPolicy), inputs)
+}
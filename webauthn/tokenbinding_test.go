@@ -0,0 +1,89 @@
+package webauthn_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+func TestWebAuthn_TokenBinding(t *testing.T) {
+	testCases := []struct {
+		name     string
+		policy   protocol.TokenBindingPolicy
+		state    *protocol.TokenBindingState
+		expected protocol.ErrorCode
+	}{
+		{"ShouldIgnoreByDefault", protocol.TokenBindingPolicyIgnore, &protocol.TokenBindingState{Status: protocol.Present, ID: []byte("id")}, ""},
+		{"ShouldVerifyNotUsed", protocol.TokenBindingPolicyVerify, nil, ""},
+		{"ShouldFailVerifyMismatch", protocol.TokenBindingPolicyVerify, &protocol.TokenBindingState{Status: protocol.Present, ID: []byte("id")}, protocol.CodeTokenBindingMismatch},
+		{"ShouldFailRequired", protocol.TokenBindingPolicyRequired, nil, protocol.CodeTokenBindingRequired},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := webauthn.New(&webauthn.Config{
+				RPID:               "example.com",
+				RPDisplayName:      "Example",
+				RPOrigins:          []string{"https://example.com"},
+				TokenBindingPolicy: tc.policy,
+			})
+			require.NoError(t, err)
+
+			user := &bytesUser{}
+			authenticator := &webauthntest.Authenticator{}
+
+			creation, session, err := w.BeginRegistration(user)
+			require.NoError(t, err)
+
+			attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err := webauthntest.NewRequest(attestation)
+			require.NoError(t, err)
+
+			r = r.WithContext(webauthn.ContextWithTokenBinding(r.Context(), tc.state))
+
+			credential, err := w.FinishRegistration(user, *session, r)
+			assertErrorCode(t, tc.expected, err)
+
+			if err != nil {
+				return
+			}
+
+			user.credentials = append(user.credentials, *credential)
+
+			assertion, session, err := w.BeginLogin(user)
+			require.NoError(t, err)
+
+			response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err = webauthntest.NewRequest(response)
+			require.NoError(t, err)
+
+			r = r.WithContext(webauthn.ContextWithTokenBinding(r.Context(), tc.state))
+
+			_, err = w.FinishLogin(user, *session, r)
+			assertErrorCode(t, tc.expected, err)
+		})
+	}
+}
+
+func assertErrorCode(t *testing.T, expected protocol.ErrorCode, err error) {
+	if expected == "" {
+		require.NoError(t, err)
+
+		return
+	}
+
+	var e *protocol.Error
+
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, expected, e.Code)
+}
@@ -0,0 +1,95 @@
+package webauthn
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// BeginLoginWithTransports is the same as BeginLogin except only the credentials of the user registered with any of
+// the transports are allowed, such as only protocol.Internal for the flows restricted to device-bound passkeys. The
+// returned SessionData is marked so finishing the login with a credential of any other transport fails.
+func (webauthn *WebAuthn) BeginLoginWithTransports(user User, transports []protocol.AuthenticatorTransport, opts ...LoginOption) (*protocol.CredentialAssertion, *SessionData, error) {
+	return webauthn.BeginLoginWithTransportsCtx(context.Background(), user, transports, opts...)
+}
+
+// BeginLoginWithTransportsCtx is the same as BeginLoginWithTransports except it accepts a context.Context.
+func (webauthn *WebAuthn) BeginLoginWithTransportsCtx(ctx context.Context, user User, transports []protocol.AuthenticatorTransport, opts ...LoginOption) (*protocol.CredentialAssertion, *SessionData, error) {
+	if len(transports) == 0 {
+		return nil, nil, fmt.Errorf(errFmtConfigValidate, fmt.Errorf(errFmtFieldEmpty, "transports"))
+	}
+
+	credentials := FilterCredentials(user.WebAuthnCredentials(), CredentialHasTransport(false, transports...))
+
+	if len(credentials) == 0 {
+		return nil, nil, webauthn.handleError(protocol.ErrBadRequest.WithCode(protocol.CodeNoCredentials).WithDetails("Found no credentials with the allowed transports for user"))
+	}
+
+	opts = append(opts, WithAllowedCredentials(CredentialDescriptors(credentials)))
+
+	assertion, session, err := webauthn.BeginLoginCtx(ctx, user, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	session.Transports = append([]protocol.AuthenticatorTransport(nil), transports...)
+
+	return assertion, session, nil
+}
+
+// BeginDiscoverableLoginWithTransports is the same as BeginDiscoverableLogin except the returned SessionData is marked
+// so finishing the login with a credential which was not registered with any of the transports fails.
+func (webauthn *WebAuthn) BeginDiscoverableLoginWithTransports(transports []protocol.AuthenticatorTransport, opts ...LoginOption) (*protocol.CredentialAssertion, *SessionData, error) {
+	return webauthn.BeginDiscoverableLoginWithTransportsCtx(context.Background(), transports, opts...)
+}
+
+// BeginDiscoverableLoginWithTransportsCtx is the same as BeginDiscoverableLoginWithTransports except it accepts a
+// context.Context.
+func (webauthn *WebAuthn) BeginDiscoverableLoginWithTransportsCtx(ctx context.Context, transports []protocol.AuthenticatorTransport, opts ...LoginOption) (*protocol.CredentialAssertion, *SessionData, error) {
+	if len(transports) == 0 {
+		return nil, nil, fmt.Errorf(errFmtConfigValidate, fmt.Errorf(errFmtFieldEmpty, "transports"))
+	}
+
+	assertion, session, err := webauthn.BeginDiscoverableLoginCtx(ctx, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	session.Transports = append([]protocol.AuthenticatorTransport(nil), transports...)
+
+	return assertion, session, nil
+}
+
+// verifyCredentialTransports ensures the credential was registered with any of the transports of the SessionData. The
+// authenticator attachment reported by the response takes precedence over the registered transports, as a roaming
+// authenticator never asserts with the internal transport and a platform authenticator only asserts with it.
+func verifyCredentialTransports(session SessionData, credential Credential, attachment protocol.AuthenticatorAttachment) error {
+	if len(session.Transports) == 0 {
+		return nil
+	}
+
+	reported := credential
+
+	switch attachment {
+	case protocol.Platform:
+		reported.Transport = []protocol.AuthenticatorTransport{protocol.Internal}
+	case protocol.CrossPlatform:
+		reported.Transport = nil
+
+		for _, transport := range credential.Transport {
+			if transport != protocol.Internal {
+				reported.Transport = append(reported.Transport, transport)
+			}
+		}
+	}
+
+	if CredentialHasTransport(false, session.Transports...)(reported) {
+		return nil
+	}
+
+	return protocol.ErrBadRequest.
+		WithCode(protocol.CodeTransportNotAllowed).
+		WithDetails("Credential was not registered with an allowed transport").
+		WithInfo(fmt.Sprintf("Allowed: %v, Registered: %v, Attachment: %s", session.Transports, credential.Transport, attachment))
+}
@@ -0,0 +1,110 @@
+package webauthn_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+func TestWebAuthn_LoginWithTransports(t *testing.T) {
+	testCases := []struct {
+		name       string
+		attachment protocol.AuthenticatorAttachment
+		registered []protocol.AuthenticatorTransport
+		allowed    []protocol.AuthenticatorTransport
+		expected   protocol.ErrorCode
+	}{
+		{"ShouldAllowPlatformCredentials", protocol.Platform, []protocol.AuthenticatorTransport{protocol.Internal}, []protocol.AuthenticatorTransport{protocol.Internal}, ""},
+		{"ShouldAllowPlatformResponsesWithUnknownTransports", protocol.Platform, nil, []protocol.AuthenticatorTransport{protocol.Internal}, ""},
+		{"ShouldAllowRoamingCredentials", protocol.CrossPlatform, []protocol.AuthenticatorTransport{protocol.USB}, []protocol.AuthenticatorTransport{protocol.USB, protocol.NFC}, ""},
+		{"ShouldRejectRoamingCredentials", protocol.CrossPlatform, []protocol.AuthenticatorTransport{protocol.USB}, []protocol.AuthenticatorTransport{protocol.Internal}, protocol.CodeTransportNotAllowed},
+		{"ShouldRejectRoamingResponsesOfInternalCredentials", protocol.CrossPlatform, []protocol.AuthenticatorTransport{protocol.Internal}, []protocol.AuthenticatorTransport{protocol.Internal}, protocol.CodeTransportNotAllowed},
+		{"ShouldRejectUnknownTransports", "", nil, []protocol.AuthenticatorTransport{protocol.Internal}, protocol.CodeTransportNotAllowed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := webauthn.New(&webauthn.Config{
+				RPID:          "example.com",
+				RPDisplayName: "Example",
+				RPOrigins:     []string{"https://example.com"},
+			})
+			require.NoError(t, err)
+
+			user := &bytesUser{}
+			authenticator := &webauthntest.Authenticator{Attachment: tc.attachment}
+
+			creation, session, err := w.BeginRegistration(user)
+			require.NoError(t, err)
+
+			attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err := webauthntest.NewRequest(attestation)
+			require.NoError(t, err)
+
+			credential, err := w.FinishRegistration(user, *session, r)
+			require.NoError(t, err)
+
+			credential.Transport = tc.registered
+
+			user.credentials = append(user.credentials, *credential)
+
+			assertion, session, err := w.BeginDiscoverableLoginWithTransports(tc.allowed)
+			require.NoError(t, err)
+			assert.Equal(t, tc.allowed, session.Transports)
+
+			response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+			require.NoError(t, err)
+
+			body, err := json.Marshal(response)
+			require.NoError(t, err)
+
+			_, err = w.FinishDiscoverableLoginBytes(func(_, _ []byte) (webauthn.User, error) {
+				return user, nil
+			}, *session, body)
+
+			if tc.expected != "" {
+				assertErrorCode(t, tc.expected, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestWebAuthn_BeginLoginWithTransports(t *testing.T) {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+	})
+	require.NoError(t, err)
+
+	user := &bytesUser{credentials: []webauthn.Credential{
+		{ID: []byte("platform"), Transport: []protocol.AuthenticatorTransport{protocol.Internal, protocol.Hybrid}},
+		{ID: []byte("roaming"), Transport: []protocol.AuthenticatorTransport{protocol.USB}},
+		{ID: []byte("unknown")},
+	}}
+
+	assertion, session, err := w.BeginLoginWithTransports(user, []protocol.AuthenticatorTransport{protocol.Internal})
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]byte{[]byte("platform")}, session.AllowedCredentialIDs)
+	assert.Equal(t, []protocol.AuthenticatorTransport{protocol.Internal}, session.Transports)
+	require.Len(t, assertion.Response.AllowedCredentials, 1)
+
+	_, _, err = w.BeginLoginWithTransports(user, []protocol.AuthenticatorTransport{protocol.NFC})
+	assertErrorCode(t, protocol.CodeNoCredentials, err)
+
+	_, _, err = w.BeginLoginWithTransports(user, nil)
+	assert.EqualError(t, err, "error occurred validating the configuration: the field 'transports' must be configured but it is empty")
+}
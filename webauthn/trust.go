@@ -0,0 +1,206 @@
+package webauthn
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/go-webauthn/webauthn/metadata"
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// TrustPolicy is a declarative attestation trust policy evaluated after a registration is verified. It's plain data,
+// so it can be loaded from the configuration of the Relying Party, and it allows complex enterprise policies to be
+// expressed as a list of rules instead of a combination of the boolean fields of the protocol.AttestationPolicy. The
+// zero value has no rules and accepts every registration.
+type TrustPolicy struct {
+	// Rules of the policy. A registration must satisfy every rule.
+	Rules []TrustRule `json:"rules"`
+}
+
+// TrustRule is a rule of a TrustPolicy. Every field is a requirement which is only enforced when it's set, and a
+// registration satisfies the rule if it satisfies all of them.
+type TrustRule struct {
+	// Name of the rule which is included in the error when the rule rejects a registration.
+	Name string `json:"name"`
+
+	// Formats requires the attestation statement format to be one of the formats, such as "packed" or "tpm".
+	Formats []string `json:"formats,omitempty"`
+
+	// Statuses requires the metadata of the authenticator to have a status report with one of the statuses, such as
+	// metadata.FidoCertifiedL1. Registrations from authenticators which are not present in the metadata are rejected.
+	Statuses []metadata.AuthenticatorStatus `json:"statuses,omitempty"`
+
+	// DenyAAGUIDs rejects the authenticator models with one of the AAGUIDs.
+	DenyAAGUIDs []uuid.UUID `json:"deny_aaguids,omitempty"`
+
+	// UserVerified requires the user verified flag of the authenticator data to be set.
+	UserVerified bool `json:"user_verified,omitempty"`
+
+	// BackupEligible requires the backup eligible flag of the authenticator data to be the value, for example false to
+	// only allow device-bound credentials.
+	BackupEligible *bool `json:"backup_eligible,omitempty"`
+}
+
+// Validate returns an error if a rule of the TrustPolicy can never be satisfied or has no requirements.
+func (policy TrustPolicy) Validate() error {
+	for i, rule := range policy.Rules {
+		if len(rule.Formats) == 0 && len(rule.Statuses) == 0 && len(rule.DenyAAGUIDs) == 0 && !rule.UserVerified && rule.BackupEligible == nil {
+			return fmt.Errorf("the rule %d '%s' has no requirements", i, rule.Name)
+		}
+
+		for _, format := range rule.Formats {
+			if format == "" {
+				return fmt.Errorf("the rule %d '%s' has an empty format", i, rule.Name)
+			}
+		}
+
+		for _, status := range rule.Statuses {
+			if status == "" {
+				return fmt.Errorf("the rule %d '%s' has an empty status", i, rule.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// clone returns a copy of the TrustRule which shares none of its slices and pointers.
+func (rule TrustRule) clone() TrustRule {
+	if rule.Formats != nil {
+		rule.Formats = append([]string(nil), rule.Formats...)
+	}
+
+	if rule.Statuses != nil {
+		rule.Statuses = append([]metadata.AuthenticatorStatus(nil), rule.Statuses...)
+	}
+
+	if rule.DenyAAGUIDs != nil {
+		rule.DenyAAGUIDs = append([]uuid.UUID(nil), rule.DenyAAGUIDs...)
+	}
+
+	if rule.BackupEligible != nil {
+		backupEligible := *rule.BackupEligible
+
+		rule.BackupEligible = &backupEligible
+	}
+
+	return rule
+}
+
+// Evaluate evaluates the TrustPolicy against the verified registration, looking up the metadata of the authenticator
+// with the provider when a rule requires it. It returns a protocol.ErrPolicy with the
+// protocol.CodeTrustPolicyRejected code for the first rule which is not satisfied.
+func (policy TrustPolicy) Evaluate(parsedResponse *protocol.ParsedCredentialCreationData, provider metadata.Provider) error {
+	if len(policy.Rules) == 0 {
+		return nil
+	}
+
+	object := parsedResponse.Response.AttestationObject
+
+	aaguid, err := uuid.FromBytes(object.AuthData.AttData.AAGUID)
+	if err != nil {
+		aaguid = uuid.Nil
+	}
+
+	var (
+		entry   *metadata.MetadataBLOBPayloadEntry
+		fetched bool
+	)
+
+	lookup := func() *metadata.MetadataBLOBPayloadEntry {
+		if !fetched && provider != nil {
+			if e, ok := provider.LookupByAAGUID(aaguid); ok {
+				entry = &e
+			}
+		}
+
+		fetched = true
+
+		return entry
+	}
+
+	for _, rule := range policy.Rules {
+		if reason := rule.evaluate(object.Format, aaguid, object.AuthData.Flags, lookup); reason != "" {
+			return protocol.ErrPolicy.
+				WithCode(protocol.CodeTrustPolicyRejected).
+				WithDetails(fmt.Sprintf("Registration rejected by the trust policy rule '%s'", rule.Name)).
+				WithInfo(reason)
+		}
+	}
+
+	return nil
+}
+
+// evaluate returns the reason the rule is not satisfied, or an empty string if it's satisfied.
+func (rule TrustRule) evaluate(format string, aaguid uuid.UUID, flags protocol.AuthenticatorFlags, lookup func() *metadata.MetadataBLOBPayloadEntry) string {
+	if len(rule.Formats) != 0 && !containsString(rule.Formats, format) {
+		return fmt.Sprintf("Attestation format '%s' is not one of %s", format, strings.Join(rule.Formats, ", "))
+	}
+
+	for _, denied := range rule.DenyAAGUIDs {
+		if denied == aaguid {
+			return fmt.Sprintf("Authenticator AAGUID '%s' is denied", aaguid)
+		}
+	}
+
+	if rule.UserVerified && !flags.HasUserVerified() {
+		return "User verification is required"
+	}
+
+	if rule.BackupEligible != nil && flags.HasBackupEligible() != *rule.BackupEligible {
+		return fmt.Sprintf("Backup eligibility must be %t", *rule.BackupEligible)
+	}
+
+	if len(rule.Statuses) != 0 {
+		entry := lookup()
+
+		if entry == nil {
+			return fmt.Sprintf("Authenticator AAGUID '%s' is not present in the metadata", aaguid)
+		}
+
+		if !hasStatus(*entry, rule.Statuses) {
+			return fmt.Sprintf("Authenticator AAGUID '%s' does not have a status report with one of the required statuses", aaguid)
+		}
+	}
+
+	return ""
+}
+
+func hasStatus(entry metadata.MetadataBLOBPayloadEntry, statuses []metadata.AuthenticatorStatus) bool {
+	for _, report := range entry.StatusReports {
+		for _, status := range statuses {
+			if report.Status == status {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// verifyTrustPolicy evaluates the TrustPolicy against the verified registration.
+func (webauthn *WebAuthn) verifyTrustPolicy(ctx context.Context, trace *protocol.VerificationTrace, parsedResponse *protocol.ParsedCredentialCreationData) error {
+	if len(webauthn.config.TrustPolicy.Rules) == 0 {
+		return nil
+	}
+
+	provider := webauthn.config.attestationPolicy(ctx).Metadata
+	if provider == nil {
+		provider = metadata.DefaultProvider
+	}
+
+	return trace.Step(protocol.VerificationStepTrustPolicy, webauthn.config.TrustPolicy.Evaluate(parsedResponse, provider), nil)
+}
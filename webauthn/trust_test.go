@@ -0,0 +1,121 @@
+package webauthn_test
+
+import (
+	"crypto/x509"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/metadata"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+	"github.com/go-webauthn/webauthn/webauthntest/webauthnmock"
+)
+
+func TestWebAuthn_TrustPolicy(t *testing.T) {
+	certified := uuid.MustParse("7b0c0d9b-7e5c-4b7a-9d4f-2c1a0e6e9c11")
+	denied := uuid.MustParse("cb69481e-8ff7-4039-93ec-0a2729a154a8")
+
+	provider := &webauthnmock.MetadataProviderMock{
+		LookupByAAGUIDFunc: func(id uuid.UUID) (metadata.MetadataBLOBPayloadEntry, bool) {
+			if id != certified {
+				return metadata.MetadataBLOBPayloadEntry{}, false
+			}
+
+			return metadata.MetadataBLOBPayloadEntry{
+				AaGUID:        id.String(),
+				StatusReports: []metadata.StatusReport{{Status: metadata.FidoCertifiedL1}},
+			}, true
+		},
+		LookupByCertificateFunc: func(_ *x509.Certificate) (metadata.MetadataBLOBPayloadEntry, bool) {
+			return metadata.MetadataBLOBPayloadEntry{}, false
+		},
+	}
+
+	deviceBound := false
+
+	testCases := []struct {
+		name          string
+		rule          webauthn.TrustRule
+		authenticator *webauthntest.Authenticator
+		expected      protocol.ErrorCode
+	}{
+		{"ShouldPassFormat", webauthn.TrustRule{Name: "format", Formats: []string{webauthntest.FormatPacked}}, &webauthntest.Authenticator{Format: webauthntest.FormatPacked}, ""},
+		{"ShouldFailFormat", webauthn.TrustRule{Name: "format", Formats: []string{webauthntest.FormatPacked}}, &webauthntest.Authenticator{}, protocol.CodeTrustPolicyRejected},
+		{"ShouldPassStatus", webauthn.TrustRule{Name: "status", Statuses: []metadata.AuthenticatorStatus{metadata.FidoCertifiedL1}}, &webauthntest.Authenticator{AAGUID: certified}, ""},
+		{"ShouldFailStatus", webauthn.TrustRule{Name: "status", Statuses: []metadata.AuthenticatorStatus{metadata.FidoCertifiedL2}}, &webauthntest.Authenticator{AAGUID: certified}, protocol.CodeTrustPolicyRejected},
+		{"ShouldFailStatusWithoutMetadata", webauthn.TrustRule{Name: "status", Statuses: []metadata.AuthenticatorStatus{metadata.FidoCertifiedL1}}, &webauthntest.Authenticator{}, protocol.CodeTrustPolicyRejected},
+		{"ShouldPassAAGUID", webauthn.TrustRule{Name: "aaguid", DenyAAGUIDs: []uuid.UUID{denied}}, &webauthntest.Authenticator{AAGUID: certified}, ""},
+		{"ShouldFailAAGUID", webauthn.TrustRule{Name: "aaguid", DenyAAGUIDs: []uuid.UUID{denied}}, &webauthntest.Authenticator{AAGUID: denied}, protocol.CodeTrustPolicyRejected},
+		{"ShouldPassUserVerified", webauthn.TrustRule{Name: "uv", UserVerified: true}, &webauthntest.Authenticator{}, ""},
+		{"ShouldFailUserVerified", webauthn.TrustRule{Name: "uv", UserVerified: true}, &webauthntest.Authenticator{Flags: protocol.FlagUserPresent}, protocol.CodeTrustPolicyRejected},
+		{"ShouldPassBackupEligible", webauthn.TrustRule{Name: "be", BackupEligible: &deviceBound}, &webauthntest.Authenticator{}, ""},
+		{"ShouldFailBackupEligible", webauthn.TrustRule{Name: "be", BackupEligible: &deviceBound}, &webauthntest.Authenticator{Flags: protocol.FlagUserPresent | protocol.FlagUserVerified | protocol.FlagBackupEligible}, protocol.CodeTrustPolicyRejected},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := webauthn.New(&webauthn.Config{
+				RPID:              "example.com",
+				RPDisplayName:     "Example",
+				RPOrigins:         []string{"https://example.com"},
+				AttestationPolicy: protocol.AttestationPolicy{Metadata: provider},
+				TrustPolicy:       webauthn.TrustPolicy{Rules: []webauthn.TrustRule{tc.rule}},
+			})
+			require.NoError(t, err)
+
+			user := &bytesUser{}
+
+			creation, session, err := w.BeginRegistration(user)
+			require.NoError(t, err)
+
+			attestation, _, err := tc.authenticator.CreateCredential(creation.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err := webauthntest.NewRequest(attestation)
+			require.NoError(t, err)
+
+			_, err = w.FinishRegistration(user, *session, r)
+
+			assertErrorCode(t, tc.expected, err)
+		})
+	}
+}
+
+func TestTrustPolicy_Validate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		have     webauthn.TrustPolicy
+		expected string
+	}{
+		{"ShouldPassEmpty", webauthn.TrustPolicy{}, ""},
+		{"ShouldPassRule", webauthn.TrustPolicy{Rules: []webauthn.TrustRule{{Name: "uv", UserVerified: true}}}, ""},
+		{"ShouldFailNoRequirements", webauthn.TrustPolicy{Rules: []webauthn.TrustRule{{Name: "empty"}}}, "the rule 0 'empty' has no requirements"},
+		{"ShouldFailEmptyFormat", webauthn.TrustPolicy{Rules: []webauthn.TrustRule{{Name: "format", Formats: []string{""}}}}, "the rule 0 'format' has an empty format"},
+		{"ShouldFailEmptyStatus", webauthn.TrustPolicy{Rules: []webauthn.TrustRule{{Name: "status", Statuses: []metadata.AuthenticatorStatus{""}}}}, "the rule 0 'status' has an empty status"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.have.Validate()
+
+			if tc.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expected)
+			}
+		})
+	}
+
+	_, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+		TrustPolicy:   webauthn.TrustPolicy{Rules: []webauthn.TrustRule{{Name: "empty"}}},
+	})
+
+	assert.EqualError(t, err, "error occurred validating the configuration: the field 'TrustPolicy' is invalid: the rule 0 'empty' has no requirements")
+}
@@ -1,27 +1,51 @@
 package webauthn
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/x509"
 	"fmt"
+	"io"
 	"net/url"
 	"time"
 
+	"github.com/google/uuid"
+
+	"github.com/go-webauthn/webauthn/metadata"
 	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/tracing"
 )
 
-// New creates a new WebAuthn object given the proper Config.
+// New creates a new WebAuthn object given the proper Config. The Config is copied so later changes to it do not affect
+// the returned *WebAuthn.
 func New(config *Config) (*WebAuthn, error) {
-	if err := config.validate(); err != nil {
+	c := config.clone()
+
+	if err := c.validate(); err != nil {
 		return nil, fmt.Errorf(errFmtConfigValidate, err)
 	}
 
 	return &WebAuthn{
-		config,
+		config: c,
 	}, nil
 }
 
-// WebAuthn is the primary interface of this package and contains the request handlers that should be called.
+// Config returns a copy of the validated Config of the WebAuthn. The WebAuthn is immutable after New, so modifying the
+// copy has no effect on it; a new WebAuthn must be created with New to change the configuration.
+func (webauthn *WebAuthn) Config() *Config {
+	return webauthn.config.clone()
+}
+
+// WebAuthn is the primary interface of this package and contains the request handlers that should be called. A
+// *WebAuthn created with New is safe for concurrent use by multiple goroutines, so a single instance can serve every
+// handler. It holds no per-ceremony state as all of it is returned to the caller in the SessionData.
 type WebAuthn struct {
-	Config *Config
+	// config is the validated copy of the Config provided to New, which is never modified.
+	config *Config
+
+	subscribers eventSubscribers
+	counters    counterStats
 }
 
 // Config represents the WebAuthn configuration.
@@ -47,12 +71,158 @@ type Config struct {
 
 	// EncodeUserIDAsString ensures the user.id value during registrations is encoded as a raw UTF8 string. This is
 	// useful when you only use printable ASCII characters for the random user.id but the browser library does not
-	// decode the URL Safe Base64 data.
+	// decode the URL Safe Base64 data. It must not be used with browser libraries which decode the user.id, such as
+	// @simplewebauthn/browser.
 	EncodeUserIDAsString bool
 
 	// Timeouts configures various timeouts.
 	Timeouts TimeoutsConfig
 
+	// RedactErrors strips the details and debug information from any *protocol.Error returned by the ceremony methods
+	// leaving only the error type and a generic description. This is recommended for production deployments as the
+	// details may contain values such as the challenge or origin. The original error is still passed to the
+	// ErrorHandler if one is configured.
+	RedactErrors bool
+
+	// ErrorHandler is called with the original error any time one of the ceremony methods returns an error, and is
+	// useful for logging the full context of an error when RedactErrors is enabled.
+	ErrorHandler func(err error)
+
+	// MetricsSink receives the outcome of every ceremony step and is useful for exporting metrics about the success
+	// and failure rate of registrations and logins.
+	MetricsSink MetricsSink
+
+	// TracerProvider enables tracing of the ceremonies when configured. See the tracing package for information on
+	// using an OpenTelemetry TracerProvider.
+	TracerProvider tracing.TracerProvider
+
+	// CounterPolicy determines how a signature counter which did not increase is handled during login. The default
+	// is CounterPolicyWarn.
+	CounterPolicy CounterPolicy
+
+	// AuditHook is called after every finish ceremony with a record of every verification step performed, which is
+	// useful for deployments which must retain evidence of each authentication decision.
+	AuditHook AuditHook
+
+	// BackupStateHook is called after a login with a credential whose backup state changed since it was last used,
+	// which allows notifying users about new sync destinations of their passkeys. It's not called by Diagnose.
+	BackupStateHook BackupStateHook
+
+	// BlocklistProvider blocks the credentials and attestation certificates known to be compromised during
+	// registration and login.
+	BlocklistProvider BlocklistProvider
+
+	// ChallengeRegistry records the challenges of the ceremonies which completed verification, so a response can't be
+	// verified twice by different instances of the application sharing the SessionData. It's not used by Diagnose.
+	ChallengeRegistry ChallengeRegistry
+
+	// ResponseBodyLimit is the maximum size in bytes of the credential response body read by FinishRegistration and
+	// FinishLogin. The default is protocol.DefaultResponseBodyLimit, a negative value disables the limit.
+	ResponseBodyLimit int64
+
+	// AttestationPolicy configures the strictness of the verification of attestation statements against the metadata
+	// during registration, such as requiring the authenticator to be present in the metadata which is expected by the
+	// FIDO2 Server Conformance Test suite.
+	AttestationPolicy protocol.AttestationPolicy
+
+	// AuthenticatorCatalog is the catalog of the human friendly names and icons of authenticator models used by
+	// AuthenticatorName. The default is metadata.DefaultCatalog.
+	AuthenticatorCatalog *metadata.Catalog
+
+	// TrustPolicy is the declarative attestation trust policy evaluated after a registration is verified, which
+	// rejects the registration unless it satisfies every rule.
+	TrustPolicy TrustPolicy
+
+	// Recorder receives a reproducible bundle of every finish ceremony which failed verification, including the
+	// credential response as it was received and the redacted session, which is useful for attaching to bug reports.
+	// See NewDirectoryRecorder for a Recorder which writes the bundles to disk.
+	Recorder Recorder
+
+	// RateLimiter is consulted before a challenge is issued by the registration and login ceremonies, which allows
+	// challenge spamming and user enumeration attempts to be throttled. See RateLimitKey.
+	RateLimiter RateLimiter
+
+	// Clock returns the current time used to set and enforce the expiry of the SessionData and to timestamp events. It's
+	// also used to verify the timestamps of android-safetynet attestation statements unless the AttestationPolicy has a
+	// Clock. The default is time.Now, and it's intended to make the expiry deterministic in tests and simulations.
+	Clock func() time.Time
+
+	// Rand is the source of randomness the challenges are read from. The default is crypto/rand.Reader, and any other
+	// value must only be used in tests and simulations as the challenges must be unpredictable.
+	Rand io.Reader
+
+	// ChallengeLength is the length of bytes of the challenges which must be at least protocol.MinChallengeLength. The
+	// default is protocol.ChallengeLength. It's ignored when the ChallengeSource is configured.
+	ChallengeLength int
+
+	// ChallengeSource generates the challenges of the ceremonies instead of reading them from the Rand.
+	ChallengeSource protocol.ChallengeSource
+
+	// ChallengeKey is the secret key the challenges are derived from when configured, which must be at least 32 bytes.
+	// The challenges are derived with protocol.DeriveChallenge from the challenges generated by the ChallengeSource or
+	// read from the Rand, and commit to the binding configured with WithRegistrationChallengeBinding or
+	// WithLoginChallengeBinding, which is verified with the binding supplied with ContextWithChallengeBinding.
+	ChallengeKey []byte
+
+	// StepUpKey is the secret key the proofs returned by FinishStepUp are authenticated with, which must be at least 32
+	// bytes. It must be configured to use BeginStepUp.
+	StepUpKey []byte
+
+	// AssertionVerifier delegates the verification of the login assertion signatures to an external service such as a
+	// cloud KMS or HSM, for deployments where the credential public keys are not held by the Relying Party. The
+	// signature is verified with the Credential public key when it's nil.
+	AssertionVerifier AssertionVerifier
+
+	// TokenBindingPolicy determines how the token binding of the client data is verified against the Token Binding
+	// state of the connection supplied with ContextWithTokenBinding. The default is protocol.TokenBindingPolicyIgnore.
+	TokenBindingPolicy protocol.TokenBindingPolicy
+
+	// FIPS restricts the accepted credential public key algorithms to webauthncose.FIPSAlgorithms, which excludes
+	// EdDSA and ECDSA using the secp256k1 curve. The registration options only include the approved algorithms and
+	// credentials using any other algorithm are rejected during registration and login. This is always enabled when
+	// building with the webauthn_fips build tag.
+	FIPS bool
+
+	// RegistrationHooks are the policy hooks called during the registration ceremony, which allow rejecting
+	// registrations based on custom policy.
+	RegistrationHooks RegistrationHooks
+
+	// LoginHooks are the policy hooks called during the login ceremony, which allow rejecting logins based on custom
+	// policy.
+	LoginHooks LoginHooks
+
+	// OriginVerifier verifies the origin of the client data against the RPOrigins, allowing deployments such as those
+	// behind TLS terminating proxies or using native application origins to customize how origins are matched. The
+	// default is protocol.StrictOriginVerifier which requires the fully qualified origin to equal one of the RPOrigins.
+	OriginVerifier protocol.OriginVerifier
+
+	// PinCredentialOrigins rejects logins with a credential from any origin other than the Origin it was registered
+	// from or one of its AllowedOrigins, which is useful when the RPOrigins contains several origins sharing the RPID.
+	// Credentials without an Origin, such as those registered by earlier versions, are not pinned.
+	PinCredentialOrigins bool
+
+	// SpecLevel is the level of the WebAuthn specification the ceremonies conform to. The default is
+	// protocol.SpecLevel2. When it's protocol.SpecLevel3 the options include the hints and attestation formats, the
+	// top origin of cross-origin client data must be one of the RPTopOrigins, the values duplicated from the
+	// attestation object by the registration response JSON must match it, and logins are rejected when the backup
+	// eligibility of the credential changed since registration.
+	SpecLevel protocol.SpecLevel
+
+	// RPTopOrigins configures the list of fully qualified origins of the pages the Relying Party expects to be
+	// sub-framed within. Client data which is cross-origin is rejected unless its top origin is one of them. It's only
+	// used when the SpecLevel is protocol.SpecLevel3.
+	RPTopOrigins []string
+
+	// AndroidCompatibility enables the leniencies required by the responses of native Android applications using the
+	// FIDO2 API of Google Play Services. The binary fields of the responses may be encoded with standard base64 or
+	// padding, the certificate hash of android:apk-key-hash origins is compared regardless of its base64 variant, and
+	// registrations without transports which were not made with a cross-platform authenticator are assumed to have the
+	// internal and hybrid transports. The Android origins must still be included in the RPOrigins.
+	AndroidCompatibility bool
+
+	// BrowserCompatibility configures the leniencies for responses of browsers which diverge from the specification.
+	BrowserCompatibility BrowserCompatibility
+
 	validated bool
 
 	// RPIcon sets the icon URL for the Relying Party Server.
@@ -75,6 +245,14 @@ type Config struct {
 type TimeoutsConfig struct {
 	Login        TimeoutConfig
 	Registration TimeoutConfig
+
+	// StepUp is the timeout of step-up logins started with BeginStepUp, which is always enforced at the Relying Party
+	// / Server. The default is 1 minute.
+	StepUp time.Duration
+
+	// Hybrid is the timeout of the ceremonies using the options of WithHybridLogin or WithHybridRegistration, which
+	// allows for the time taken to scan the QR code and connect to the phone or tablet. The default is 10 minutes.
+	Hybrid time.Duration
 }
 
 // TimeoutConfig represents the WebAuthn timeouts configuration for either registration or login..
@@ -89,6 +267,134 @@ type TimeoutConfig struct {
 
 	// TimeoutUVD is the timeout for logins/registrations when the UserVerificationRequirement is set to discouraged.
 	TimeoutUVD time.Duration
+
+	// Grace is the additional time after the timeout during which an enforced session is still accepted, which
+	// accounts for the latency of the network and the time taken to submit the response after the browser finished.
+	Grace time.Duration
+}
+
+// clone returns a copy of the Config which does not share any mutable state with the original. A new slice, map, or
+// pointer field must be copied here, which is enforced by TestConfig_CloneDeepCopy.
+func (config *Config) clone() *Config {
+	c := *config
+
+	if config.RPOrigins != nil {
+		c.RPOrigins = append([]string(nil), config.RPOrigins...)
+	}
+
+	if config.RPTopOrigins != nil {
+		c.RPTopOrigins = append([]string(nil), config.RPTopOrigins...)
+	}
+
+	if config.ChallengeKey != nil {
+		c.ChallengeKey = append([]byte(nil), config.ChallengeKey...)
+	}
+
+	if config.StepUpKey != nil {
+		c.StepUpKey = append([]byte(nil), config.StepUpKey...)
+	}
+
+	if config.AttestationPolicy.Intermediates != nil {
+		c.AttestationPolicy.Intermediates = append([]*x509.Certificate(nil), config.AttestationPolicy.Intermediates...)
+	}
+
+	if config.AttestationPolicy.MinimumAuthenticatorVersions != nil {
+		c.AttestationPolicy.MinimumAuthenticatorVersions = make(map[uuid.UUID]uint32, len(config.AttestationPolicy.MinimumAuthenticatorVersions))
+
+		for aaguid, version := range config.AttestationPolicy.MinimumAuthenticatorVersions {
+			c.AttestationPolicy.MinimumAuthenticatorVersions[aaguid] = version
+		}
+	}
+
+	if config.TrustPolicy.Rules != nil {
+		c.TrustPolicy.Rules = make([]TrustRule, len(config.TrustPolicy.Rules))
+
+		for i, rule := range config.TrustPolicy.Rules {
+			c.TrustPolicy.Rules[i] = rule.clone()
+		}
+	}
+
+	if config.AuthenticatorSelection.RequireResidentKey != nil {
+		requireResidentKey := *config.AuthenticatorSelection.RequireResidentKey
+
+		c.AuthenticatorSelection.RequireResidentKey = &requireResidentKey
+	}
+
+	return &c
+}
+
+// responseBodyLimit returns the effective ResponseBodyLimit.
+func (config *Config) responseBodyLimit() int64 {
+	if config == nil || config.ResponseBodyLimit == 0 {
+		return protocol.DefaultResponseBodyLimit
+	}
+
+	return config.ResponseBodyLimit
+}
+
+// now returns the current time according to the Clock.
+func (config *Config) now() time.Time {
+	if config == nil || config.Clock == nil {
+		return time.Now()
+	}
+
+	return config.Clock()
+}
+
+// rand returns the source of randomness for the challenges.
+func (config *Config) rand() io.Reader {
+	if config == nil || config.Rand == nil {
+		return rand.Reader
+	}
+
+	return config.Rand
+}
+
+// attestationPolicy returns the AttestationPolicy with the metadata lookups performed with the context, and the Clock
+// of the Config unless the policy has one.
+func (config *Config) attestationPolicy(ctx context.Context) protocol.AttestationPolicy {
+	policy := config.AttestationPolicy
+
+	if policy.Clock == nil {
+		policy.Clock = config.Clock
+	}
+
+	if policy.Metadata != nil {
+		policy.Metadata = metadata.WithContext(ctx, policy.Metadata)
+	}
+
+	return policy
+}
+
+// newChallenge returns a new challenge from the ChallengeSource or read from the source of randomness, which is
+// derived from an empty binding when the ChallengeKey is configured.
+func (config *Config) newChallenge() (challenge protocol.URLEncodedBase64, err error) {
+	if challenge, err = config.generateChallenge(); err != nil {
+		return nil, err
+	}
+
+	if len(config.ChallengeKey) != 0 {
+		return protocol.URLEncodedBase64(protocol.DeriveChallenge(config.ChallengeKey, challenge, nil)), nil
+	}
+
+	return challenge, nil
+}
+
+func (config *Config) generateChallenge() (protocol.URLEncodedBase64, error) {
+	if config.ChallengeSource == nil {
+		return protocol.CreateChallengeWithLength(config.rand(), config.ChallengeLength)
+	}
+
+	challenge, err := config.ChallengeSource.NewChallenge()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(challenge) < protocol.MinChallengeLength {
+		return nil, fmt.Errorf("challenge length of %d bytes is less than the minimum of %d bytes", len(challenge), protocol.MinChallengeLength)
+	}
+
+	return protocol.URLEncodedBase64(challenge), nil
 }
 
 // Validate that the config flags in Config are properly set
@@ -141,6 +447,14 @@ func (config *Config) validate() error {
 		config.Timeouts.Registration.TimeoutUVD = defaultTimeoutUVDConfig
 	}
 
+	if config.Timeouts.StepUp == 0 {
+		config.Timeouts.StepUp = defaultTimeoutStepUp
+	}
+
+	if config.Timeouts.Hybrid == 0 {
+		config.Timeouts.Hybrid = defaultTimeoutHybrid
+	}
+
 	if len(config.RPOrigin) > 0 {
 		if len(config.RPOrigins) != 0 {
 			return fmt.Errorf("deprecated field 'RPOrigin' can't be defined at the same tme as the replacement field 'RPOrigins'")
@@ -153,6 +467,50 @@ func (config *Config) validate() error {
 		return fmt.Errorf("must provide at least one value to the 'RPOrigins' field")
 	}
 
+	if level := config.AttestationPolicy.MinimumCertificationLevel; level != "" && metadata.CertificationRank(level) == 0 {
+		return fmt.Errorf("the field 'AttestationPolicy.MinimumCertificationLevel' must be a FIDO certification status but it is %s", level)
+	}
+
+	if config.AttestationPolicy.AllowTPMRS1 && config.fips() {
+		return fmt.Errorf("the field 'AttestationPolicy.AllowTPMRS1' must not be enabled in FIPS mode")
+	}
+
+	if err := config.TrustPolicy.Validate(); err != nil {
+		return fmt.Errorf("the field 'TrustPolicy' is invalid: %w", err)
+	}
+
+	if config.Timeouts.Login.Grace < 0 {
+		return fmt.Errorf("the field 'Timeouts.Login.Grace' must not be negative but it is %s", config.Timeouts.Login.Grace)
+	}
+
+	if config.Timeouts.Registration.Grace < 0 {
+		return fmt.Errorf("the field 'Timeouts.Registration.Grace' must not be negative but it is %s", config.Timeouts.Registration.Grace)
+	}
+
+	if config.ChallengeLength != 0 && config.ChallengeLength < protocol.MinChallengeLength {
+		return fmt.Errorf("the field 'ChallengeLength' must be at least %d but it is %d", protocol.MinChallengeLength, config.ChallengeLength)
+	}
+
+	if len(config.ChallengeKey) != 0 && len(config.ChallengeKey) < 32 {
+		return fmt.Errorf("the field 'ChallengeKey' must be at least 32 bytes but it is %d bytes", len(config.ChallengeKey))
+	}
+
+	if len(config.StepUpKey) != 0 && len(config.StepUpKey) < 32 {
+		return fmt.Errorf("the field 'StepUpKey' must be at least 32 bytes but it is %d bytes", len(config.StepUpKey))
+	}
+
+	if config.SpecLevel < protocol.SpecLevel2 || config.SpecLevel > protocol.SpecLevel3 {
+		return fmt.Errorf("the field 'SpecLevel' must be protocol.SpecLevel2 or protocol.SpecLevel3 but it is %d", config.SpecLevel)
+	}
+
+	if config.Timeouts.StepUp < 0 {
+		return fmt.Errorf("the field 'Timeouts.StepUp' must not be negative but it is %s", config.Timeouts.StepUp)
+	}
+
+	if config.Timeouts.Hybrid < 0 {
+		return fmt.Errorf("the field 'Timeouts.Hybrid' must not be negative but it is %s", config.Timeouts.Hybrid)
+	}
+
 	if config.AuthenticatorSelection.RequireResidentKey == nil {
 		config.AuthenticatorSelection.RequireResidentKey = protocol.ResidentKeyNotRequired()
 	}
@@ -212,4 +570,43 @@ type SessionData struct {
 
 	UserVerification protocol.UserVerificationRequirement `json:"userVerification"`
 	Extensions       protocol.AuthenticationExtensions    `json:"extensions,omitempty"`
+
+	// CreatedAt is the time the ceremony began. When the timeout is enforced the session Expires after the timeout of
+	// the ceremony from this time.
+	CreatedAt time.Time `json:"created_at"`
+
+	// HighAssurance is true if the session belongs to a registration started with BeginHighAssuranceRegistration.
+	HighAssurance bool `json:"high_assurance,omitempty"`
+
+	// StepUp is true if the session belongs to a step-up login started with BeginStepUp.
+	StepUp bool `json:"step_up,omitempty"`
+
+	// Recovery is true if the session belongs to a recovery registration started with BeginRecoveryRegistration or a
+	// recovery login started with BeginRecoveryLogin.
+	Recovery bool `json:"recovery,omitempty"`
+
+	// Transports are the transports allowed for the login when the session belongs to a login started with
+	// BeginLoginWithTransports or BeginDiscoverableLoginWithTransports.
+	Transports []protocol.AuthenticatorTransport `json:"transports,omitempty"`
+}
+
+// verifySession ensures the SessionData belongs to the user with the provided ID and has not expired at the provided
+// time.
+func verifySession(userID []byte, session SessionData, now time.Time, grace time.Duration) error {
+	if !bytes.Equal(userID, session.UserID) {
+		return protocol.ErrBadRequest.WithCode(protocol.CodeUserSessionMismatch).WithDetails("ID mismatch for User and Session")
+	}
+
+	return verifySessionExpiry(session, now, grace)
+}
+
+// verifySessionExpiry ensures the SessionData has not expired at the provided time allowing for the grace period.
+func verifySessionExpiry(session SessionData, now time.Time, grace time.Duration) error {
+	if !session.Expires.IsZero() && session.Expires.Add(grace).Before(now) {
+		return protocol.ErrSessionExpired.
+			WithCode(protocol.CodeSessionExpired).
+			WithInfo(fmt.Sprintf("Expired: %s, Grace: %s, Received: %s", session.Expires.Format(time.RFC3339Nano), grace, now.Format(time.RFC3339Nano)))
+	}
+
+	return nil
 }
@@ -0,0 +1,410 @@
+package webauthn
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+func TestNew(t *testing.T) {
+	config := &Config{
+		RPDisplayName: "Example",
+		RPID:          "example.com",
+		RPOrigins:     []string{"https://example.com"},
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			RequireResidentKey: protocol.ResidentKeyRequired(),
+		},
+		AttestationPolicy: protocol.AttestationPolicy{
+			MinimumAuthenticatorVersions: map[uuid.UUID]uint32{uuid.Nil: 1},
+		},
+		TrustPolicy: TrustPolicy{
+			Rules: []TrustRule{{Name: "formats", Formats: []string{"packed"}}},
+		},
+	}
+
+	webauthn, err := New(config)
+	require.NoError(t, err)
+
+	config.RPID = "example.org"
+	config.RPOrigins[0] = "https://example.org"
+	*config.AuthenticatorSelection.RequireResidentKey = false
+	config.AttestationPolicy.MinimumAuthenticatorVersions[uuid.Nil] = 2
+	config.TrustPolicy.Rules[0].Formats[0] = "none"
+
+	assert.Equal(t, "example.com", webauthn.config.RPID)
+	assert.Equal(t, []string{"https://example.com"}, webauthn.config.RPOrigins)
+	assert.True(t, *webauthn.config.AuthenticatorSelection.RequireResidentKey)
+	assert.Equal(t, uint32(1), webauthn.config.AttestationPolicy.MinimumAuthenticatorVersions[uuid.Nil])
+	assert.Equal(t, []string{"packed"}, webauthn.config.TrustPolicy.Rules[0].Formats)
+	assert.True(t, webauthn.config.validated)
+	assert.False(t, config.validated)
+
+	copied := webauthn.Config()
+
+	copied.RPID = "example.net"
+	copied.RPOrigins[0] = "https://example.net"
+	copied.AttestationPolicy.MinimumAuthenticatorVersions[uuid.Nil] = 3
+
+	assert.Equal(t, "example.com", webauthn.Config().RPID)
+	assert.Equal(t, []string{"https://example.com"}, webauthn.Config().RPOrigins)
+	assert.Equal(t, uint32(1), webauthn.Config().AttestationPolicy.MinimumAuthenticatorVersions[uuid.Nil])
+}
+
+// TestWebAuthn_Concurrency exercises a single *WebAuthn from many goroutines at once. It's intended to be run with the
+// race detector enabled.
+// configShared are the paths of the reference typed fields of the Config which are intentionally shared with the
+// clone, as the values they reference are immutable or safe for concurrent use.
+var configShared = map[string]bool{
+	"AttestationPolicy.Intermediates[]": true,
+	"AuthenticatorCatalog":              true,
+}
+
+func TestConfig_CloneDeepCopy(t *testing.T) {
+	config := &Config{}
+
+	populateConfigValue(reflect.ValueOf(config).Elem(), "")
+
+	cloned := config.clone()
+
+	assertConfigValueCopied(t, reflect.ValueOf(config).Elem(), reflect.ValueOf(cloned).Elem(), "")
+}
+
+// populateConfigValue sets every exported slice, map, and pointer of the value to a non-nil value so the clone can be
+// compared with the original.
+func populateConfigValue(v reflect.Value, path string) {
+	if configShared[path] {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Type().Field(i); field.IsExported() {
+				populateConfigValue(v.Field(i), configFieldPath(path, field.Name))
+			}
+		}
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+
+		populateConfigValue(v.Index(0), path+"[]")
+	case reflect.Map:
+		v.Set(reflect.MakeMapWithSize(v.Type(), 1))
+		v.SetMapIndex(reflect.New(v.Type().Key()).Elem(), reflect.New(v.Type().Elem()).Elem())
+	case reflect.Pointer:
+		v.Set(reflect.New(v.Type().Elem()))
+
+		populateConfigValue(v.Elem(), path)
+	}
+}
+
+// assertConfigValueCopied asserts every slice, map, and pointer of the cloned value references different memory than
+// the original, so a new reference typed field of the Config fails until it's copied by clone or added to
+// configShared.
+func assertConfigValueCopied(t *testing.T, original, cloned reflect.Value, path string) {
+	if configShared[path] {
+		return
+	}
+
+	switch original.Kind() {
+	case reflect.Struct:
+		for i := 0; i < original.NumField(); i++ {
+			if field := original.Type().Field(i); field.IsExported() {
+				assertConfigValueCopied(t, original.Field(i), cloned.Field(i), configFieldPath(path, field.Name))
+			}
+		}
+	case reflect.Slice:
+		assert.NotEqual(t, original.Pointer(), cloned.Pointer(), "the field '%s' is not deep copied", path)
+
+		for i := 0; i < original.Len(); i++ {
+			assertConfigValueCopied(t, original.Index(i), cloned.Index(i), path+"[]")
+		}
+	case reflect.Map, reflect.Pointer:
+		assert.NotEqual(t, original.Pointer(), cloned.Pointer(), "the field '%s' is not deep copied", path)
+
+		if original.Kind() == reflect.Pointer {
+			assertConfigValueCopied(t, original.Elem(), cloned.Elem(), path)
+		}
+	}
+}
+
+func configFieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+func TestWebAuthn_Concurrency(t *testing.T) {
+	webauthn, err := New(&Config{
+		RPDisplayName: "Example",
+		RPID:          "example.com",
+		RPOrigins:     []string{"https://example.com"},
+		Timeouts: TimeoutsConfig{
+			Login:        TimeoutConfig{Enforce: true},
+			Registration: TimeoutConfig{Enforce: true},
+		},
+	})
+	require.NoError(t, err)
+
+	const (
+		goroutines = 16
+		iterations = 25
+	)
+
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		challenges = map[string]bool{}
+	)
+
+	record := func(session *SessionData) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		challenges[session.Challenge] = true
+	}
+
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			webauthn.Subscribe(EventLoginFailed, func(event Event) {})
+
+			user := &defaultUser{id: []byte("123")}
+
+			for j := 0; j < iterations; j++ {
+				_, session, err := webauthn.BeginRegistration(user, WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired))
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				record(session)
+
+				_, session, err = webauthn.BeginDiscoverableLogin()
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				record(session)
+
+				_, err = webauthn.ValidateLogin(user, SessionData{UserID: []byte("ABC")}, nil)
+				assert.Error(t, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Len(t, challenges, goroutines*iterations*2)
+	assert.False(t, *webauthn.config.AuthenticatorSelection.RequireResidentKey)
+}
+
+func TestWebAuthn_ClockAndRand(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	webauthn, err := New(&Config{
+		RPDisplayName: "Example",
+		RPID:          "example.com",
+		RPOrigins:     []string{"https://example.com"},
+		Timeouts: TimeoutsConfig{
+			Login:        TimeoutConfig{Enforce: true, Timeout: time.Minute},
+			Registration: TimeoutConfig{Enforce: true, Timeout: time.Minute},
+		},
+		Clock: func() time.Time { return now },
+		Rand:  bytes.NewReader(bytes.Repeat([]byte{0x01}, protocol.ChallengeLength*2)),
+	})
+	require.NoError(t, err)
+
+	user := &defaultUser{id: []byte("123")}
+
+	_, session, err := webauthn.BeginRegistration(user)
+	require.NoError(t, err)
+
+	assert.Equal(t, "AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE", session.Challenge)
+	assert.Equal(t, now.Add(time.Minute), session.Expires)
+
+	_, session, err = webauthn.BeginDiscoverableLogin()
+	require.NoError(t, err)
+
+	assert.Equal(t, "AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE", session.Challenge)
+	assert.Equal(t, now.Add(time.Minute), session.Expires)
+
+	assert.NoError(t, verifySession(nil, *session, webauthn.config.now(), 0))
+
+	now = now.Add(time.Minute + time.Second)
+
+	err = verifySession(nil, *session, webauthn.config.now(), 0)
+	require.Error(t, err)
+	assert.Equal(t, protocol.CodeSessionExpired, err.(*protocol.Error).Code)
+
+	// The reader is exhausted so no further challenges can be created.
+	_, _, err = webauthn.BeginDiscoverableLogin()
+	assert.Error(t, err)
+
+	// The attestation policy verifies the SafetyNet timestamps with the same clock.
+	assert.Equal(t, now, webauthn.config.attestationPolicy(context.Background()).Clock())
+}
+
+func TestVerifySessionExpiry(t *testing.T) {
+	now := time.Unix(1700000000, 0).UTC()
+
+	testCases := []struct {
+		name    string
+		expires time.Time
+		grace   time.Duration
+		err     bool
+	}{
+		{"ShouldPassWithoutExpiry", time.Time{}, 0, false},
+		{"ShouldPassBeforeExpiry", now.Add(time.Second), 0, false},
+		{"ShouldPassAtExpiry", now, 0, false},
+		{"ShouldFailAfterExpiry", now.Add(-time.Second), 0, true},
+		{"ShouldPassWithinGrace", now.Add(-time.Second), 5 * time.Second, false},
+		{"ShouldFailAfterGrace", now.Add(-10 * time.Second), 5 * time.Second, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verifySessionExpiry(SessionData{CreatedAt: now.Add(-time.Minute), Expires: tc.expires}, now, tc.grace)
+
+			if !tc.err {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			require.Error(t, err)
+			require.IsType(t, &protocol.Error{}, err)
+
+			perr := err.(*protocol.Error)
+
+			assert.Equal(t, protocol.ErrSessionExpired.Type, perr.Type)
+			assert.Equal(t, protocol.CodeSessionExpired, perr.Code)
+			assert.Equal(t, protocol.FailureReasonSessionExpired, protocol.GetFailureReason(err))
+		})
+	}
+}
+
+func TestWebAuthn_SessionExpiryDiscoverable(t *testing.T) {
+	now := time.Unix(1700000000, 0).UTC()
+
+	webauthn, err := New(&Config{
+		RPDisplayName: "Test",
+		RPID:          "example.com",
+		RPOrigins:     []string{"https://example.com"},
+		Timeouts: TimeoutsConfig{
+			Login: TimeoutConfig{Enforce: true, Timeout: time.Minute, Grace: 10 * time.Second},
+		},
+		Clock: func() time.Time { return now },
+	})
+	require.NoError(t, err)
+
+	_, session, err := webauthn.BeginDiscoverableLogin()
+	require.NoError(t, err)
+
+	assert.Equal(t, now, session.CreatedAt)
+	assert.Equal(t, now.Add(time.Minute), session.Expires)
+
+	handler := func(rawID, userHandle []byte) (User, error) {
+		return nil, errors.New("no such user")
+	}
+
+	now = now.Add(time.Minute + 5*time.Second)
+
+	_, err = webauthn.ValidateDiscoverableLogin(handler, *session, &protocol.ParsedCredentialAssertionData{})
+	require.Error(t, err)
+	assert.NotEqual(t, protocol.FailureReasonSessionExpired, protocol.GetFailureReason(err))
+
+	now = now.Add(10 * time.Second)
+
+	_, err = webauthn.ValidateDiscoverableLogin(handler, *session, &protocol.ParsedCredentialAssertionData{})
+	require.Error(t, err)
+	assert.Equal(t, protocol.CodeSessionExpired, err.(*protocol.Error).Code)
+}
+
+func TestConfig_ValidateNegativeGrace(t *testing.T) {
+	_, err := New(&Config{
+		RPDisplayName: "Test",
+		RPID:          "example.com",
+		RPOrigins:     []string{"https://example.com"},
+		Timeouts: TimeoutsConfig{
+			Registration: TimeoutConfig{Grace: -time.Second},
+		},
+	})
+
+	assert.EqualError(t, err, "error occurred validating the configuration: the field 'Timeouts.Registration.Grace' must not be negative but it is -1s")
+}
+
+func TestConfig_ClockAndRandDefaults(t *testing.T) {
+	var config *Config
+
+	assert.WithinDuration(t, time.Now(), config.now(), time.Second)
+	assert.NotNil(t, config.rand())
+
+	config = &Config{Rand: iotest.ErrReader(errors.New("read error"))}
+
+	_, _, err := (&WebAuthn{config: config}).BeginDiscoverableLogin()
+	assert.Error(t, err)
+}
+
+type staticChallengeSource protocol.Challenge
+
+func (s staticChallengeSource) NewChallenge() (protocol.Challenge, error) {
+	return protocol.Challenge(s), nil
+}
+
+func TestWebAuthn_Challenge(t *testing.T) {
+	testCases := []struct {
+		name     string
+		length   int
+		source   protocol.ChallengeSource
+		expected int
+		err      string
+	}{
+		{"ShouldUseDefaultLength", 0, nil, protocol.ChallengeLength, ""},
+		{"ShouldUseConfiguredLength", 48, nil, 48, ""},
+		{"ShouldUseSource", 48, staticChallengeSource(bytes.Repeat([]byte{0x01}, 20)), 20, ""},
+		{"ShouldFailShortLength", 8, nil, 0, "error occurred validating the configuration: the field 'ChallengeLength' must be at least 16 but it is 8"},
+		{"ShouldFailShortSource", 0, staticChallengeSource(bytes.Repeat([]byte{0x01}, 8)), 0, "challenge length of 8 bytes is less than the minimum of 16 bytes"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			webauthn := &WebAuthn{config: &Config{
+				RPDisplayName:   "Example",
+				RPID:            "example.com",
+				RPOrigins:       []string{"https://example.com"},
+				ChallengeLength: tc.length,
+				ChallengeSource: tc.source,
+			}}
+
+			_, session, err := webauthn.BeginDiscoverableLogin()
+
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			challenge, err := base64.RawURLEncoding.DecodeString(session.Challenge)
+			require.NoError(t, err)
+			assert.Len(t, challenge, tc.expected)
+		})
+	}
+}
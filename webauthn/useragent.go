@@ -0,0 +1,139 @@
+package webauthn
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// ClientPlatform is the operating system of a client.
+type ClientPlatform string
+
+const (
+	// ClientPlatformUnknown indicates the operating system of the client could not be determined.
+	ClientPlatformUnknown ClientPlatform = ""
+
+	// ClientPlatformAndroid indicates the client runs on Android.
+	ClientPlatformAndroid ClientPlatform = "Android"
+
+	// ClientPlatformIOS indicates the client runs on iOS or iPadOS.
+	ClientPlatformIOS ClientPlatform = "iOS"
+
+	// ClientPlatformWindows indicates the client runs on Windows.
+	ClientPlatformWindows ClientPlatform = "Windows"
+
+	// ClientPlatformMacOS indicates the client runs on macOS.
+	ClientPlatformMacOS ClientPlatform = "macOS"
+
+	// ClientPlatformChromeOS indicates the client runs on ChromeOS.
+	ClientPlatformChromeOS ClientPlatform = "Chrome OS"
+
+	// ClientPlatformLinux indicates the client runs on Linux.
+	ClientPlatformLinux ClientPlatform = "Linux"
+)
+
+// ClientProfile describes the client a ceremony is started from, and suggests the options which are most likely to
+// lead the user to a suitable authenticator. The suggestions are only a convenience as the client hints and the user
+// agent are supplied by the client, so they must never be relied on to enforce a policy.
+type ClientProfile struct {
+	// Mobile is true if the client is a phone or a tablet, which have a platform authenticator.
+	Mobile bool
+
+	// Platform is the operating system of the client.
+	Platform ClientPlatform
+
+	// Managed is true if the client is a desktop managed by the organization of the Relying Party, where users are
+	// expected to have a security key. It can't be determined from the request, so it must be set by the caller, for
+	// example from a device management header added by a proxy.
+	Managed bool
+}
+
+// ClientProfileFromRequest returns the ClientProfile of the request which starts a ceremony. It uses the
+// Sec-CH-UA-Mobile and Sec-CH-UA-Platform client hints when they're present, and the User-Agent header otherwise.
+func ClientProfileFromRequest(r *http.Request) ClientProfile {
+	profile := ClientProfile{
+		Platform: clientPlatformFromUserAgent(r.UserAgent()),
+	}
+
+	if value := r.Header.Get("Sec-CH-UA-Platform"); value != "" {
+		profile.Platform = clientPlatformFromHint(value)
+	}
+
+	profile.Mobile = profile.Platform == ClientPlatformAndroid || profile.Platform == ClientPlatformIOS
+
+	if value := r.Header.Get("Sec-CH-UA-Mobile"); value == "?1" {
+		profile.Mobile = true
+	}
+
+	return profile
+}
+
+// RegistrationOptions returns the RegistrationOption suggested for the client. Registrations from mobile clients
+// prefer the platform authenticator, and registrations from managed desktops prefer a security key. It returns no
+// options when there's no suggestion.
+func (p ClientProfile) RegistrationOptions() (opts []RegistrationOption) {
+	switch {
+	case p.Mobile:
+		opts = append(opts, withAuthenticatorAttachment(protocol.Platform), WithHints(protocol.PublicKeyCredentialHintClientDevice))
+	case p.Managed:
+		opts = append(opts, withAuthenticatorAttachment(protocol.CrossPlatform), WithHints(protocol.PublicKeyCredentialHintSecurityKey))
+	}
+
+	return opts
+}
+
+// LoginOptions returns the LoginOption suggested for the client. Logins from mobile clients prefer the platform
+// authenticator, and logins from managed desktops prefer a security key. It returns no options when there's no
+// suggestion.
+func (p ClientProfile) LoginOptions() (opts []LoginOption) {
+	switch {
+	case p.Mobile:
+		opts = append(opts, WithAssertionHints(protocol.PublicKeyCredentialHintClientDevice))
+	case p.Managed:
+		opts = append(opts, WithAssertionHints(protocol.PublicKeyCredentialHintSecurityKey))
+	}
+
+	return opts
+}
+
+// withAuthenticatorAttachment adjusts the authenticator attachment of the registration without replacing the other
+// authenticator selection criteria.
+func withAuthenticatorAttachment(attachment protocol.AuthenticatorAttachment) RegistrationOption {
+	return func(cco *protocol.PublicKeyCredentialCreationOptions) {
+		cco.AuthenticatorSelection.AuthenticatorAttachment = attachment
+	}
+}
+
+// clientPlatformFromHint returns the ClientPlatform of the value of the Sec-CH-UA-Platform client hint.
+func clientPlatformFromHint(value string) ClientPlatform {
+	switch platform := ClientPlatform(strings.Trim(value, `"`)); platform {
+	case ClientPlatformAndroid, ClientPlatformIOS, ClientPlatformWindows, ClientPlatformMacOS, ClientPlatformChromeOS, ClientPlatformLinux:
+		return platform
+	case "Chromium OS":
+		return ClientPlatformChromeOS
+	default:
+		return ClientPlatformUnknown
+	}
+}
+
+// clientPlatformFromUserAgent returns the ClientPlatform of the value of the User-Agent header. The order of the
+// checks matters as the user agents of Android and iOS also mention Linux and macOS respectively.
+func clientPlatformFromUserAgent(userAgent string) ClientPlatform {
+	switch {
+	case strings.Contains(userAgent, "Android"):
+		return ClientPlatformAndroid
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"), strings.Contains(userAgent, "iPod"):
+		return ClientPlatformIOS
+	case strings.Contains(userAgent, "Windows"):
+		return ClientPlatformWindows
+	case strings.Contains(userAgent, "CrOS"):
+		return ClientPlatformChromeOS
+	case strings.Contains(userAgent, "Macintosh"):
+		return ClientPlatformMacOS
+	case strings.Contains(userAgent, "Linux"):
+		return ClientPlatformLinux
+	default:
+		return ClientPlatformUnknown
+	}
+}
@@ -0,0 +1,91 @@
+package webauthn_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+func TestClientProfileFromRequest(t *testing.T) {
+	testCases := []struct {
+		name      string
+		userAgent string
+		mobile    string
+		platform  string
+		expected  webauthn.ClientProfile
+	}{
+		{"ShouldDetectAndroidUserAgent", "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36", "", "", webauthn.ClientProfile{Mobile: true, Platform: webauthn.ClientPlatformAndroid}},
+		{"ShouldDetectIOSUserAgent", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1", "", "", webauthn.ClientProfile{Mobile: true, Platform: webauthn.ClientPlatformIOS}},
+		{"ShouldDetectWindowsUserAgent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:120.0) Gecko/20100101 Firefox/120.0", "", "", webauthn.ClientProfile{Platform: webauthn.ClientPlatformWindows}},
+		{"ShouldDetectMacOSUserAgent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Safari/605.1.15", "", "", webauthn.ClientProfile{Platform: webauthn.ClientPlatformMacOS}},
+		{"ShouldDetectChromeOSUserAgent", "Mozilla/5.0 (X11; CrOS x86_64 14541.0.0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36", "", "", webauthn.ClientProfile{Platform: webauthn.ClientPlatformChromeOS}},
+		{"ShouldDetectLinuxUserAgent", "Mozilla/5.0 (X11; Linux x86_64; rv:120.0) Gecko/20100101 Firefox/120.0", "", "", webauthn.ClientProfile{Platform: webauthn.ClientPlatformLinux}},
+		{"ShouldPreferClientHints", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36", "?0", `"Windows"`, webauthn.ClientProfile{Platform: webauthn.ClientPlatformWindows}},
+		{"ShouldDetectMobileClientHint", "", "?1", `"Android"`, webauthn.ClientProfile{Mobile: true, Platform: webauthn.ClientPlatformAndroid}},
+		{"ShouldDetectChromiumOSClientHint", "", "?0", `"Chromium OS"`, webauthn.ClientProfile{Platform: webauthn.ClientPlatformChromeOS}},
+		{"ShouldNotDetectUnknownClientHint", "", "?0", `"Unknown"`, webauthn.ClientProfile{}},
+		{"ShouldNotDetectEmptyUserAgent", "", "", "", webauthn.ClientProfile{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+
+			r.Header.Set("User-Agent", tc.userAgent)
+
+			if tc.mobile != "" {
+				r.Header.Set("Sec-CH-UA-Mobile", tc.mobile)
+			}
+
+			if tc.platform != "" {
+				r.Header.Set("Sec-CH-UA-Platform", tc.platform)
+			}
+
+			assert.Equal(t, tc.expected, webauthn.ClientProfileFromRequest(r))
+		})
+	}
+}
+
+func TestClientProfile_Options(t *testing.T) {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+		SpecLevel:     protocol.SpecLevel3,
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		profile    webauthn.ClientProfile
+		attachment protocol.AuthenticatorAttachment
+		hints      []protocol.PublicKeyCredentialHint
+	}{
+		{"ShouldSuggestPlatformForMobile", webauthn.ClientProfile{Mobile: true, Platform: webauthn.ClientPlatformIOS}, protocol.Platform, []protocol.PublicKeyCredentialHint{protocol.PublicKeyCredentialHintClientDevice}},
+		{"ShouldSuggestSecurityKeyForManagedDesktop", webauthn.ClientProfile{Platform: webauthn.ClientPlatformWindows, Managed: true}, protocol.CrossPlatform, []protocol.PublicKeyCredentialHint{protocol.PublicKeyCredentialHintSecurityKey}},
+		{"ShouldNotSuggestForUnmanagedDesktop", webauthn.ClientProfile{Platform: webauthn.ClientPlatformWindows}, "", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			user := &bytesUser{credentials: []webauthn.Credential{{ID: []byte("credential")}}}
+
+			creation, _, err := w.BeginRegistration(user, append([]webauthn.RegistrationOption{webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired)}, tc.profile.RegistrationOptions()...)...)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.attachment, creation.Response.AuthenticatorSelection.AuthenticatorAttachment)
+			assert.Equal(t, protocol.ResidentKeyRequirementRequired, creation.Response.AuthenticatorSelection.ResidentKey)
+			assert.Equal(t, tc.hints, creation.Response.Hints)
+
+			assertion, _, err := w.BeginLogin(user, tc.profile.LoginOptions()...)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.hints, assertion.Response.Hints)
+		})
+	}
+}
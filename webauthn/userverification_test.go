@@ -0,0 +1,100 @@
+package webauthn_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+func TestWebAuthn_CredentialUserVerification(t *testing.T) {
+	testCases := []struct {
+		name       string
+		credential protocol.UserVerificationRequirement
+		session    protocol.UserVerificationRequirement
+		stepUp     bool
+		recovery   bool
+		expected   protocol.ErrorCode
+	}{
+		{"ShouldFollowSessionWhenEmpty", "", protocol.VerificationPreferred, false, false, ""},
+		{"ShouldFollowSessionRequiredWhenEmpty", "", protocol.VerificationRequired, false, false, protocol.CodeUVRequired},
+		{"ShouldRequireWhenCredentialRequires", protocol.VerificationRequired, protocol.VerificationDiscouraged, false, false, protocol.CodeUVRequired},
+		{"ShouldSkipWhenCredentialDiscourages", protocol.VerificationDiscouraged, protocol.VerificationPreferred, false, false, ""},
+		{"ShouldNotWeakenRequiredSessionWhenCredentialDiscourages", protocol.VerificationDiscouraged, protocol.VerificationRequired, false, false, protocol.CodeUVRequired},
+		{"ShouldNotWeakenRequiredSessionWhenCredentialPrefers", protocol.VerificationPreferred, protocol.VerificationRequired, false, false, protocol.CodeUVRequired},
+		{"ShouldAlwaysRequireForStepUp", protocol.VerificationDiscouraged, protocol.VerificationRequired, true, false, protocol.CodeUVRequired},
+		{"ShouldAlwaysRequireForRecovery", protocol.VerificationDiscouraged, protocol.VerificationDiscouraged, false, true, protocol.CodeUVRequired},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := webauthn.New(&webauthn.Config{
+				RPID:          "example.com",
+				RPDisplayName: "Example",
+				RPOrigins:     []string{"https://example.com"},
+				StepUpKey:     []byte("0123456789abcdef0123456789abcdef"),
+			})
+			require.NoError(t, err)
+
+			user := &bytesUser{}
+			authenticator := &webauthntest.Authenticator{}
+
+			creation, session, err := w.BeginRegistration(user)
+			require.NoError(t, err)
+
+			attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err := webauthntest.NewRequest(attestation)
+			require.NoError(t, err)
+
+			credential, err := w.FinishRegistration(user, *session, r)
+			require.NoError(t, err)
+
+			credential.UserVerification = tc.credential
+			credential.Recovery = tc.recovery
+
+			user.credentials = append(user.credentials, *credential)
+
+			authenticator.Flags = protocol.FlagUserPresent
+
+			var assertion *protocol.CredentialAssertion
+
+			switch {
+			case tc.stepUp:
+				assertion, session, err = w.BeginStepUp(user)
+			case tc.recovery:
+				assertion, session, err = w.BeginRecoveryLogin(user)
+			default:
+				assertion, session, err = w.BeginLogin(user, webauthn.WithUserVerification(tc.session))
+			}
+
+			require.NoError(t, err)
+
+			response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+			require.NoError(t, err)
+
+			r, err = webauthntest.NewRequest(response)
+			require.NoError(t, err)
+
+			if tc.stepUp {
+				_, _, err = w.FinishStepUp(user, *session, r)
+			} else {
+				_, err = w.FinishLogin(user, *session, r)
+			}
+
+			if tc.expected == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			require.IsType(t, &protocol.Error{}, err)
+			assert.Equal(t, tc.expected, err.(*protocol.Error).Code)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package webauthn
+
+import (
+	"fmt"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+)
+
+// ValidateCredential checks a stored Credential is well-formed before it's relied on to log in, such as when importing
+// credentials from another system. It ensures the public key decodes, its algorithm is supported and matches the key
+// type, and the flags, transports, and authenticator values are consistent. It does not check the credential can be
+// used under the Config of a WebAuthn, such as when FIPS is enabled.
+func ValidateCredential(credential Credential) error {
+	if len(credential.ID) == 0 {
+		return protocol.ErrBadRequest.WithCode(protocol.CodeCredentialInvalid).WithDetails("Credential ID is empty")
+	}
+
+	if err := validateCredentialPublicKey(credential); err != nil {
+		return err
+	}
+
+	if credential.Flags.BackupState && !credential.Flags.BackupEligible {
+		return protocol.ErrBadRequest.WithCode(protocol.CodeCredentialInvalid).WithDetails("Credential is backed up but not backup eligible")
+	}
+
+	seen := make(map[protocol.AuthenticatorTransport]bool, len(credential.Transport))
+
+	for _, transport := range credential.Transport {
+		if transport == "" || seen[transport] {
+			return protocol.ErrBadRequest.
+				WithCode(protocol.CodeCredentialInvalid).
+				WithDetails("Credential transports are empty or duplicated").
+				WithInfo(fmt.Sprintf("Transport: '%s'", transport))
+		}
+
+		seen[transport] = true
+	}
+
+	switch credential.Authenticator.Attachment {
+	case "", protocol.Platform, protocol.CrossPlatform:
+	default:
+		return protocol.ErrBadRequest.
+			WithCode(protocol.CodeCredentialInvalid).
+			WithDetails("Credential authenticator attachment is unknown").
+			WithInfo(fmt.Sprintf("Attachment: '%s'", credential.Authenticator.Attachment))
+	}
+
+	if n := len(credential.Authenticator.AAGUID); n != 0 && n != 16 {
+		return protocol.ErrBadRequest.
+			WithCode(protocol.CodeCredentialInvalid).
+			WithDetails("Credential AAGUID is not 16 bytes").
+			WithInfo(fmt.Sprintf("Length: %d", n))
+	}
+
+	return nil
+}
+
+// validateCredentialPublicKey ensures the credential public key is a COSE key using a supported algorithm for its key
+// type. Credentials registered with the FIDO U2F API may instead store an uncompressed P-256 point.
+func validateCredentialPublicKey(credential Credential) error {
+	key, err := parseCredentialPublicKey(credential)
+	if err != nil {
+		return protocol.ErrUnsupportedKey.
+			WithCode(protocol.CodePublicKeyInvalid).
+			WithDetails(fmt.Sprintf("Error parsing the credential public key: %+v", err))
+	}
+
+	var (
+		alg   webauthncose.COSEAlgorithmIdentifier
+		valid bool
+	)
+
+	switch k := key.(type) {
+	case webauthncose.EC2PublicKeyData:
+		alg = webauthncose.COSEAlgorithmIdentifier(k.Algorithm)
+		valid = len(k.XCoord) != 0 && len(k.YCoord) != 0 && isECDSAAlgorithm(alg)
+	case webauthncose.RSAPublicKeyData:
+		alg = webauthncose.COSEAlgorithmIdentifier(k.Algorithm)
+		valid = len(k.Modulus) != 0 && len(k.Exponent) != 0 && isRSAAlgorithm(alg)
+	case webauthncose.OKPPublicKeyData:
+		alg = webauthncose.COSEAlgorithmIdentifier(k.Algorithm)
+		valid = len(k.XCoord) != 0 && alg == webauthncose.AlgEdDSA
+	}
+
+	if !valid {
+		return protocol.ErrUnsupportedAlgorithm.
+			WithCode(protocol.CodeAlgorithmNotAllowed).
+			WithDetails("Credential public key algorithm is not supported for the key type").
+			WithInfo(fmt.Sprintf("Algorithm: %d", alg))
+	}
+
+	return nil
+}
+
+// parseCredentialPublicKey parses the credential public key, falling back to the uncompressed P-256 point stored by
+// credentials registered with the FIDO U2F API.
+func parseCredentialPublicKey(credential Credential) (key interface{}, err error) {
+	if key, err = webauthncose.ParsePublicKey(credential.PublicKey); err == nil || credential.AttestationType != "fido-u2f" {
+		return key, err
+	}
+
+	k, fidoErr := webauthncose.ParseFIDOPublicKey(credential.PublicKey)
+	if fidoErr != nil {
+		return nil, err
+	}
+
+	k.KeyType = int64(webauthncose.EllipticKey)
+	k.Curve = int64(webauthncose.P256)
+
+	return k, nil
+}
+
+func isECDSAAlgorithm(alg webauthncose.COSEAlgorithmIdentifier) bool {
+	switch webauthncose.SigAlgFromCOSEAlg(alg) {
+	case webauthncose.ECDSAWithSHA256, webauthncose.ECDSAWithSHA384, webauthncose.ECDSAWithSHA512:
+		return true
+	default:
+		return false
+	}
+}
+
+func isRSAAlgorithm(alg webauthncose.COSEAlgorithmIdentifier) bool {
+	switch webauthncose.SigAlgFromCOSEAlg(alg) {
+	case webauthncose.SHA1WithRSA, webauthncose.SHA256WithRSA, webauthncose.SHA384WithRSA, webauthncose.SHA512WithRSA,
+		webauthncose.SHA256WithRSAPSS, webauthncose.SHA384WithRSAPSS, webauthncose.SHA512WithRSAPSS:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,88 @@
+package webauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+)
+
+func TestValidateCredential(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	encode := func(kty webauthncose.COSEKeyType, alg webauthncose.COSEAlgorithmIdentifier) []byte {
+		data, err := webauthncbor.Marshal(map[int]interface{}{
+			1:  int64(kty),
+			3:  int64(alg),
+			-1: int64(webauthncose.P256),
+			-2: key.X.FillBytes(make([]byte, 32)),
+			-3: key.Y.FillBytes(make([]byte, 32)),
+		})
+		require.NoError(t, err)
+
+		return data
+	}
+
+	valid := Credential{
+		ID:        []byte("credential"),
+		PublicKey: encode(webauthncose.EllipticKey, webauthncose.AlgES256),
+		Transport: []protocol.AuthenticatorTransport{protocol.USB, protocol.NFC},
+		Flags:     CredentialFlags{UserPresent: true, BackupEligible: true, BackupState: true},
+		Authenticator: Authenticator{
+			AAGUID:     make([]byte, 16),
+			Attachment: protocol.CrossPlatform,
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		modify   func(credential *Credential)
+		expected protocol.ErrorCode
+	}{
+		{"ShouldAcceptValidCredential", func(credential *Credential) {}, ""},
+		{"ShouldAcceptFIDOU2FPublicKey", func(credential *Credential) {
+			credential.AttestationType = "fido-u2f"
+			credential.PublicKey = elliptic.Marshal(elliptic.P256(), key.X, key.Y) //nolint:staticcheck
+		}, ""},
+		{"ShouldRejectEmptyID", func(credential *Credential) { credential.ID = nil }, protocol.CodeCredentialInvalid},
+		{"ShouldRejectUndecodablePublicKey", func(credential *Credential) { credential.PublicKey = []byte("key") }, protocol.CodePublicKeyInvalid},
+		{"ShouldRejectUnsupportedAlgorithm", func(credential *Credential) {
+			credential.PublicKey = encode(webauthncose.EllipticKey, webauthncose.AlgES256K)
+		}, protocol.CodeAlgorithmNotAllowed},
+		{"ShouldRejectAlgorithmForOtherKeyType", func(credential *Credential) {
+			credential.PublicKey = encode(webauthncose.EllipticKey, webauthncose.AlgRS256)
+		}, protocol.CodeAlgorithmNotAllowed},
+		{"ShouldRejectBackupStateWithoutEligibility", func(credential *Credential) { credential.Flags.BackupEligible = false }, protocol.CodeCredentialInvalid},
+		{"ShouldRejectDuplicateTransports", func(credential *Credential) {
+			credential.Transport = []protocol.AuthenticatorTransport{protocol.USB, protocol.USB}
+		}, protocol.CodeCredentialInvalid},
+		{"ShouldRejectUnknownAttachment", func(credential *Credential) { credential.Authenticator.Attachment = "other" }, protocol.CodeCredentialInvalid},
+		{"ShouldRejectInvalidAAGUID", func(credential *Credential) { credential.Authenticator.AAGUID = []byte{1} }, protocol.CodeCredentialInvalid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			credential := valid
+			tc.modify(&credential)
+
+			err := ValidateCredential(credential)
+
+			if tc.expected == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			require.IsType(t, &protocol.Error{}, err)
+			assert.Equal(t, tc.expected, err.(*protocol.Error).Code)
+		})
+	}
+}
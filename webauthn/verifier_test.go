@@ -0,0 +1,105 @@
+package webauthn_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+type assertionVerifierFunc func(ctx context.Context, assertion webauthn.AssertionSignature) error
+
+func (f assertionVerifierFunc) VerifyAssertion(ctx context.Context, assertion webauthn.AssertionSignature) error {
+	return f(ctx, assertion)
+}
+
+func TestWebAuthn_AssertionVerifier(t *testing.T) {
+	config := &webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+	}
+
+	w, err := webauthn.New(config)
+	require.NoError(t, err)
+
+	user := &bytesUser{}
+	authenticator := &webauthntest.Authenticator{}
+
+	creation, session, err := w.BeginRegistration(user)
+	require.NoError(t, err)
+
+	attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+	require.NoError(t, err)
+
+	r, err := webauthntest.NewRequest(attestation)
+	require.NoError(t, err)
+
+	credential, err := w.FinishRegistration(user, *session, r)
+	require.NoError(t, err)
+
+	publicKey := credential.PublicKey
+
+	// The public key is held by the verifier rather than the Relying Party.
+	credential.PublicKey = nil
+	user.credentials = append(user.credentials, *credential)
+
+	login := func(t *testing.T, verifier webauthn.AssertionVerifier) (*webauthn.Credential, error) {
+		c := *config
+		c.AssertionVerifier = verifier
+
+		w, err := webauthn.New(&c)
+		require.NoError(t, err)
+
+		assertion, session, err := w.BeginLogin(user)
+		require.NoError(t, err)
+
+		response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+		require.NoError(t, err)
+
+		r, err := webauthntest.NewRequest(response)
+		require.NoError(t, err)
+
+		return w.FinishLogin(user, *session, r)
+	}
+
+	t.Run("ShouldDelegateVerification", func(t *testing.T) {
+		var called bool
+
+		actual, err := login(t, assertionVerifierFunc(func(ctx context.Context, assertion webauthn.AssertionSignature) error {
+			called = true
+
+			assert.Equal(t, credential.ID, assertion.Credential.ID)
+			assert.False(t, assertion.AppID)
+
+			return protocol.VerifySignature(assertion.SignedData, assertion.Signature, publicKey, assertion.AppID)
+		}))
+
+		require.NoError(t, err)
+		assert.True(t, called)
+		assert.Equal(t, credential.ID, actual.ID)
+	})
+
+	t.Run("ShouldRejectWhenVerifierFails", func(t *testing.T) {
+		_, err := login(t, assertionVerifierFunc(func(ctx context.Context, assertion webauthn.AssertionSignature) error {
+			return errors.New("kms: signature mismatch")
+		}))
+
+		require.IsType(t, &protocol.Error{}, err)
+		assert.Equal(t, protocol.CodeSignatureInvalid, err.(*protocol.Error).Code)
+		assert.Contains(t, err.Error(), "kms: signature mismatch")
+	})
+
+	t.Run("ShouldFailWithoutVerifierOrPublicKey", func(t *testing.T) {
+		_, err := login(t, nil)
+
+		require.IsType(t, &protocol.Error{}, err)
+		assert.Equal(t, protocol.CodePublicKeyInvalid, err.(*protocol.Error).Code)
+	})
+}
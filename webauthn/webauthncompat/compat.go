@@ -0,0 +1,171 @@
+// Package webauthncompat converts the stored credentials and sessions of github.com/duo-labs/webauthn and of other
+// versions of this library to and from the webauthn.Credential and webauthn.SessionData of this version, so projects
+// can switch libraries without migrating their databases by hand:
+//
+//	credential, err := webauthncompat.Credential(duoCredential)
+//
+//	var duoCredential duowebauthn.Credential
+//
+//	err := webauthncompat.CopyCredential(&duoCredential, credential)
+//
+// The package does not import the other libraries. Instead the fields are copied by name, which works because the
+// libraries share their field names and the types of the fields only differ by the package of named types such as
+// protocol.AuthenticatorTransport. The fields which only exist on one side are left unset, for example the Flags and
+// Transport of a credential converted from duo-labs/webauthn, which are updated by the next login and registration
+// respectively.
+//
+// The JSON and gob encodings of the structs of duo-labs/webauthn and older versions of this library can be decoded as
+// is into the structs of this version.
+//
+// The authenticators stored by github.com/koesie10/webauthn, which have a different schema, are converted by
+// FromKoesie.
+package webauthncompat
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// Credential returns the webauthn.Credential of the credential of another library, such as a duowebauthn.Credential
+// or a pointer to it.
+func Credential(v interface{}) (credential webauthn.Credential, err error) {
+	err = convert("Credential", reflect.ValueOf(&credential).Elem(), reflect.ValueOf(v))
+
+	return credential, err
+}
+
+// SessionData returns the webauthn.SessionData of the session of another library, such as a duowebauthn.SessionData or
+// a pointer to it.
+func SessionData(v interface{}) (session webauthn.SessionData, err error) {
+	err = convert("SessionData", reflect.ValueOf(&session).Elem(), reflect.ValueOf(v))
+
+	return session, err
+}
+
+// CopyCredential copies the credential into the credential of another library which dst points to, such as a
+// *duowebauthn.Credential.
+func CopyCredential(dst interface{}, credential webauthn.Credential) error {
+	return copyTo("Credential", dst, credential)
+}
+
+// CopySessionData copies the session into the session of another library which dst points to, such as a
+// *duowebauthn.SessionData.
+func CopySessionData(dst interface{}, session webauthn.SessionData) error {
+	return copyTo("SessionData", dst, session)
+}
+
+func copyTo(name string, dst, src interface{}) error {
+	value := reflect.ValueOf(dst)
+
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("webauthncompat: the destination of the %s must be a non-nil pointer to a struct, got %T", name, dst)
+	}
+
+	return convert(name, value.Elem(), reflect.ValueOf(src))
+}
+
+// convert sets dst to the converted src. The path is the name of the value used in the errors.
+func convert(path string, dst, src reflect.Value) error {
+	for src.Kind() == reflect.Pointer || src.Kind() == reflect.Interface {
+		if src.IsNil() {
+			return nil
+		}
+
+		src = src.Elem()
+	}
+
+	if !src.IsValid() {
+		return nil
+	}
+
+	if dst.Kind() == reflect.Pointer {
+		value := reflect.New(dst.Type().Elem())
+
+		if err := convert(path, value.Elem(), src); err != nil {
+			return err
+		}
+
+		dst.Set(value)
+
+		return nil
+	}
+
+	switch {
+	case dst.Kind() == reflect.Interface && src.Type().AssignableTo(dst.Type()):
+		dst.Set(src)
+
+		return nil
+	case dst.Kind() != src.Kind():
+		return fmt.Errorf("webauthncompat: cannot convert %s from %s to %s", path, src.Type(), dst.Type())
+	case src.Type().ConvertibleTo(dst.Type()):
+		dst.Set(src.Convert(dst.Type()))
+
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Struct:
+		for i := 0; i < dst.NumField(); i++ {
+			field := dst.Type().Field(i)
+
+			if !field.IsExported() {
+				continue
+			}
+
+			value := src.FieldByName(field.Name)
+
+			if !value.IsValid() {
+				continue
+			}
+
+			if err := convert(path+"."+field.Name, dst.Field(i), value); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return nil
+		}
+
+		values := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+
+		for i := 0; i < src.Len(); i++ {
+			if err := convert(fmt.Sprintf("%s[%d]", path, i), values.Index(i), src.Index(i)); err != nil {
+				return err
+			}
+		}
+
+		dst.Set(values)
+	case reflect.Map:
+		if src.IsNil() {
+			return nil
+		}
+
+		values := reflect.MakeMapWithSize(dst.Type(), src.Len())
+
+		iter := src.MapRange()
+
+		for iter.Next() {
+			key := reflect.New(dst.Type().Key()).Elem()
+			value := reflect.New(dst.Type().Elem()).Elem()
+
+			if err := convert(path, key, iter.Key()); err != nil {
+				return err
+			}
+
+			if err := convert(fmt.Sprintf("%s[%v]", path, iter.Key()), value, iter.Value()); err != nil {
+				return err
+			}
+
+			values.SetMapIndex(key, value)
+		}
+
+		dst.Set(values)
+	default:
+		return fmt.Errorf("webauthncompat: cannot convert %s from %s to %s", path, src.Type(), dst.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package webauthncompat
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// The following types mirror the types of github.com/duo-labs/webauthn, where the named types of its protocol package
+// are distinct from the types of this library.
+type (
+	duoUserVerificationRequirement string
+	duoAuthenticationExtensions    map[string]interface{}
+)
+
+type duoAuthenticator struct {
+	AAGUID       []byte
+	SignCount    uint32
+	CloneWarning bool
+}
+
+type duoCredential struct {
+	ID              []byte
+	PublicKey       []byte
+	AttestationType string
+	Authenticator   duoAuthenticator
+}
+
+type duoSessionData struct {
+	Challenge            string                         `json:"challenge"`
+	UserID               []byte                         `json:"user_id"`
+	AllowedCredentialIDs [][]byte                       `json:"allowed_credentials,omitempty"`
+	UserVerification     duoUserVerificationRequirement `json:"userVerification"`
+	Extensions           duoAuthenticationExtensions    `json:"extensions,omitempty"`
+}
+
+type legacyTransport string
+
+type legacyCredential struct {
+	ID              []byte            `json:"id"`
+	PublicKey       []byte            `json:"publicKey"`
+	AttestationType string            `json:"attestationType"`
+	Transport       []legacyTransport `json:"transport"`
+	Authenticator   duoAuthenticator  `json:"authenticator"`
+}
+
+func TestCredential(t *testing.T) {
+	duo := duoCredential{
+		ID:              []byte("id"),
+		PublicKey:       []byte("key"),
+		AttestationType: "packed",
+		Authenticator:   duoAuthenticator{AAGUID: []byte("aaguid"), SignCount: 5, CloneWarning: true},
+	}
+
+	expected := webauthn.Credential{
+		ID:              []byte("id"),
+		PublicKey:       []byte("key"),
+		AttestationType: "packed",
+		Authenticator:   webauthn.Authenticator{AAGUID: []byte("aaguid"), SignCount: 5, CloneWarning: true},
+	}
+
+	testCases := []struct {
+		name  string
+		value interface{}
+	}{
+		{"ShouldConvertValue", duo},
+		{"ShouldConvertPointer", &duo},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			credential, err := Credential(tc.value)
+			require.NoError(t, err)
+			assert.Equal(t, expected, credential)
+		})
+	}
+
+	legacy := legacyCredential{ID: []byte("id"), Transport: []legacyTransport{"usb", "nfc"}}
+
+	credential, err := Credential(legacy)
+	require.NoError(t, err)
+	assert.Equal(t, []protocol.AuthenticatorTransport{protocol.USB, protocol.NFC}, credential.Transport)
+
+	var copied duoCredential
+
+	expected.Transport = []protocol.AuthenticatorTransport{protocol.USB}
+	expected.Flags.BackupEligible = true
+
+	require.NoError(t, CopyCredential(&copied, expected))
+	assert.Equal(t, duo, copied)
+}
+
+func TestSessionData(t *testing.T) {
+	duo := duoSessionData{
+		Challenge:            "challenge",
+		UserID:               []byte("1234"),
+		AllowedCredentialIDs: [][]byte{[]byte("id")},
+		UserVerification:     "required",
+		Extensions:           duoAuthenticationExtensions{"appid": "https://example.com"},
+	}
+
+	session, err := SessionData(duo)
+	require.NoError(t, err)
+	assert.Equal(t, webauthn.SessionData{
+		Challenge:            "challenge",
+		UserID:               []byte("1234"),
+		AllowedCredentialIDs: [][]byte{[]byte("id")},
+		UserVerification:     protocol.VerificationRequired,
+		Extensions:           protocol.AuthenticationExtensions{"appid": "https://example.com"},
+	}, session)
+
+	session.Expires = time.Now()
+
+	var copied duoSessionData
+
+	require.NoError(t, CopySessionData(&copied, session))
+	assert.Equal(t, duo, copied)
+}
+
+func TestConvertErrors(t *testing.T) {
+	_, err := Credential(struct{ SignCount string }{})
+	assert.NoError(t, err)
+
+	_, err = Credential(struct{ ID string }{ID: "id"})
+	assert.EqualError(t, err, "webauthncompat: cannot convert Credential.ID from string to []uint8")
+
+	_, err = Credential(struct{ Authenticator struct{ SignCount string } }{})
+	assert.EqualError(t, err, "webauthncompat: cannot convert Credential.Authenticator.SignCount from string to uint32")
+
+	assert.EqualError(t, CopyCredential(duoCredential{}, webauthn.Credential{}), "webauthncompat: the destination of the Credential must be a non-nil pointer to a struct, got webauthncompat.duoCredential")
+
+	credential, err := Credential(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, webauthn.Credential{}, credential)
+}
+
+func TestDecodeDuoLabsJSON(t *testing.T) {
+	data, err := json.Marshal(duoCredential{
+		ID:            []byte("id"),
+		PublicKey:     []byte("key"),
+		Authenticator: duoAuthenticator{AAGUID: []byte("aaguid"), SignCount: 5},
+	})
+	require.NoError(t, err)
+
+	var credential webauthn.Credential
+
+	require.NoError(t, json.Unmarshal(data, &credential))
+	assert.Equal(t, []byte("id"), credential.ID)
+	assert.Equal(t, []byte("key"), credential.PublicKey)
+	assert.Equal(t, []byte("aaguid"), credential.Authenticator.AAGUID)
+	assert.Equal(t, uint32(5), credential.Authenticator.SignCount)
+}
@@ -0,0 +1,60 @@
+package webauthncompat
+
+import (
+	"fmt"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// KoesieAuthenticator is the interface implemented by the stored credentials of github.com/koesie10/webauthn, which
+// are called authenticators in that library.
+type KoesieAuthenticator interface {
+	WebAuthID() []byte
+	WebAuthCredentialID() []byte
+	WebAuthPublicKey() []byte
+	WebAuthAAGUID() []byte
+	WebAuthSignCount() uint32
+}
+
+// KoesieTransports are the transports of the credentials converted by FromKoesie when no transports are given.
+// github.com/koesie10/webauthn did not store the transports, and only supported the attestation formats of roaming
+// authenticators, so the credentials are assumed to be security keys.
+var KoesieTransports = []protocol.AuthenticatorTransport{protocol.USB, protocol.NFC, protocol.BLE}
+
+// FromKoesie returns the webauthn.Credential of an authenticator stored by github.com/koesie10/webauthn. The public
+// key of the authenticator is the raw COSE key of the attested credential data, which is checked to be a valid key.
+// The transports of the credential are the given transports, or KoesieTransports when none are given. The attestation
+// type and flags of the credential are unknown and left unset.
+func FromKoesie(authenticator KoesieAuthenticator, transports ...protocol.AuthenticatorTransport) (credential webauthn.Credential, err error) {
+	if authenticator == nil {
+		return credential, fmt.Errorf("webauthncompat: the authenticator is nil")
+	}
+
+	if len(authenticator.WebAuthCredentialID()) == 0 {
+		return credential, fmt.Errorf("webauthncompat: the authenticator has no credential id")
+	}
+
+	publicKey := authenticator.WebAuthPublicKey()
+
+	if _, err = webauthncose.ParsePublicKey(publicKey); err != nil {
+		return credential, fmt.Errorf("webauthncompat: the authenticator has an invalid public key: %w", err)
+	}
+
+	if len(transports) == 0 {
+		transports = KoesieTransports
+	}
+
+	credential = webauthn.Credential{
+		ID:        append([]byte(nil), authenticator.WebAuthCredentialID()...),
+		PublicKey: append([]byte(nil), publicKey...),
+		Transport: append([]protocol.AuthenticatorTransport(nil), transports...),
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    append([]byte(nil), authenticator.WebAuthAAGUID()...),
+			SignCount: authenticator.WebAuthSignCount(),
+		},
+	}
+
+	return credential, nil
+}
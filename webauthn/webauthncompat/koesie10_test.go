@@ -0,0 +1,112 @@
+package webauthncompat
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+)
+
+type koesieAuthenticator struct {
+	ID           []byte
+	CredentialID []byte
+	PublicKey    []byte
+	AAGUID       []byte
+	SignCount    uint32
+}
+
+func (a *koesieAuthenticator) WebAuthID() []byte           { return a.ID }
+func (a *koesieAuthenticator) WebAuthCredentialID() []byte { return a.CredentialID }
+func (a *koesieAuthenticator) WebAuthPublicKey() []byte    { return a.PublicKey }
+func (a *koesieAuthenticator) WebAuthAAGUID() []byte       { return a.AAGUID }
+func (a *koesieAuthenticator) WebAuthSignCount() uint32    { return a.SignCount }
+
+func TestFromKoesie(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	publicKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{KeyType: int64(webauthncose.EllipticKey), Algorithm: int64(webauthncose.AlgES256)},
+		Curve:         int64(webauthncose.P256),
+		XCoord:        key.X.FillBytes(make([]byte, 32)),
+		YCoord:        key.Y.FillBytes(make([]byte, 32)),
+	})
+	require.NoError(t, err)
+
+	authenticator := &koesieAuthenticator{
+		ID:           []byte("row"),
+		CredentialID: []byte("credential"),
+		PublicKey:    publicKey,
+		AAGUID:       make([]byte, 16),
+		SignCount:    12,
+	}
+
+	testCases := []struct {
+		name          string
+		authenticator KoesieAuthenticator
+		transports    []protocol.AuthenticatorTransport
+		expected      []protocol.AuthenticatorTransport
+		err           string
+	}{
+		{
+			name:          "ShouldBackfillDefaultTransports",
+			authenticator: authenticator,
+			expected:      []protocol.AuthenticatorTransport{protocol.USB, protocol.NFC, protocol.BLE},
+		},
+		{
+			name:          "ShouldUseGivenTransports",
+			authenticator: authenticator,
+			transports:    []protocol.AuthenticatorTransport{protocol.Internal},
+			expected:      []protocol.AuthenticatorTransport{protocol.Internal},
+		},
+		{
+			name:          "ShouldFailNilAuthenticator",
+			authenticator: nil,
+			err:           "webauthncompat: the authenticator is nil",
+		},
+		{
+			name:          "ShouldFailWithoutCredentialID",
+			authenticator: &koesieAuthenticator{PublicKey: publicKey},
+			err:           "webauthncompat: the authenticator has no credential id",
+		},
+		{
+			name:          "ShouldFailInvalidPublicKey",
+			authenticator: &koesieAuthenticator{CredentialID: []byte("credential"), PublicKey: []byte("key")},
+			err:           "webauthncompat: the authenticator has an invalid public key",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			credential, err := FromKoesie(tc.authenticator, tc.transports...)
+
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, []byte("credential"), credential.ID)
+			assert.Equal(t, publicKey, credential.PublicKey)
+			assert.Equal(t, tc.expected, credential.Transport)
+			assert.Equal(t, make([]byte, 16), credential.Authenticator.AAGUID)
+			assert.Equal(t, uint32(12), credential.Authenticator.SignCount)
+		})
+	}
+
+	credential, err := FromKoesie(authenticator)
+	require.NoError(t, err)
+
+	credential.Transport[0] = protocol.Hybrid
+
+	assert.Equal(t, protocol.USB, KoesieTransports[0])
+}
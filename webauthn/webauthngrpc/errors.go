@@ -0,0 +1,88 @@
+package webauthngrpc
+
+import (
+	"errors"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// Code is a gRPC status code. The values are the values of the codes package of google.golang.org/grpc.
+type Code uint32
+
+// The status codes used by the Server.
+const (
+	CodeInvalidArgument   Code = 3
+	CodeNotFound          Code = 5
+	CodeResourceExhausted Code = 8
+	CodeInternal          Code = 13
+	CodeUnauthenticated   Code = 16
+)
+
+// ErrMissingUsername is returned by BeginRegistration when the request does not contain a username.
+var ErrMissingUsername = errors.New("webauthngrpc: a username is required")
+
+// Error is the error returned by the Server. The Message does not contain the details of internal errors so it can be
+// returned to the callers, while the original error is available with errors.Unwrap.
+type Error struct {
+	Code    Code
+	Message string
+	Err     error
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the original error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode returns the status code of an error returned by the Server, or CodeInternal if it's not an *Error.
+func ErrorCode(err error) Code {
+	var e *Error
+
+	if errors.As(err, &e) {
+		return e.Code
+	}
+
+	return CodeInternal
+}
+
+func newError(err error) *Error {
+	e := &Error{Code: code(err), Message: err.Error(), Err: err}
+
+	if e.Code == CodeInternal {
+		e.Message = "internal error"
+	}
+
+	return e
+}
+
+func code(err error) Code {
+	switch {
+	case errors.Is(err, ErrMissingUsername), errors.Is(err, webauthn.ErrSessionNotFound):
+		return CodeInvalidArgument
+	case errors.Is(err, webauthn.ErrUserNotFound):
+		return CodeNotFound
+	}
+
+	var e *protocol.Error
+
+	if !errors.As(err, &e) {
+		return CodeInternal
+	}
+
+	if e.Code == protocol.CodeResponseTooLarge {
+		return CodeResourceExhausted
+	}
+
+	switch e.FailureReason() {
+	case protocol.FailureReasonMalformedRequest, protocol.FailureReasonSessionExpired, protocol.FailureReasonUserMismatch:
+		return CodeInvalidArgument
+	default:
+		return CodeUnauthenticated
+	}
+}
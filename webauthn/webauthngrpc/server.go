@@ -0,0 +1,280 @@
+// Package webauthngrpc implements the WebAuthn service of webauthn.proto, which allows the ceremonies to be performed
+// by an internal authentication service on behalf of other services.
+//
+// The package does not depend on gRPC. The Server implements the RPCs with the message types of this package, which
+// mirror the messages of webauthn.proto, so the service generated by protoc-gen-go-grpc only has to copy the fields
+// and convert the errors:
+//
+//	func (s *service) BeginLogin(ctx context.Context, req *webauthnpb.BeginLoginRequest) (*webauthnpb.BeginLoginResponse, error) {
+//		resp, err := s.server.BeginLogin(ctx, &webauthngrpc.BeginLoginRequest{Username: req.GetUsername()})
+//		if err != nil {
+//			return nil, status.Error(codes.Code(webauthngrpc.ErrorCode(err)), err.Error())
+//		}
+//
+//		return &webauthnpb.BeginLoginResponse{SessionId: resp.SessionID, OptionsJson: resp.OptionsJSON}, nil
+//	}
+package webauthngrpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// Ceremonies are the ceremonies performed by the Server. It's implemented by *webauthn.WebAuthn.
+type Ceremonies interface {
+	webauthn.RegistrationCeremony
+	webauthn.LoginCeremony
+}
+
+// Server implements the WebAuthn service. The WebAuthn, Users, and Sessions are required.
+type Server struct {
+	// WebAuthn performs the ceremonies which is usually a *webauthn.WebAuthn.
+	WebAuthn Ceremonies
+
+	// Users loads the users and saves their credentials. The users must exist before they can register a credential.
+	Users webauthn.UserStore
+
+	// Sessions stores the sessions between the Begin and Finish RPCs. It must be shared by all the instances of the
+	// service.
+	Sessions webauthn.SessionStore
+}
+
+// BeginRegistrationRequest is the BeginRegistrationRequest message.
+type BeginRegistrationRequest struct {
+	Username string
+}
+
+// BeginRegistrationResponse is the BeginRegistrationResponse message.
+type BeginRegistrationResponse struct {
+	SessionID   string
+	OptionsJSON []byte
+}
+
+// FinishRegistrationRequest is the FinishRegistrationRequest message.
+type FinishRegistrationRequest struct {
+	SessionID      string
+	CredentialJSON []byte
+}
+
+// FinishRegistrationResponse is the FinishRegistrationResponse message.
+type FinishRegistrationResponse struct {
+	UserID       []byte
+	CredentialID []byte
+}
+
+// BeginLoginRequest is the BeginLoginRequest message.
+type BeginLoginRequest struct {
+	Username string
+}
+
+// BeginLoginResponse is the BeginLoginResponse message.
+type BeginLoginResponse struct {
+	SessionID   string
+	OptionsJSON []byte
+}
+
+// FinishLoginRequest is the FinishLoginRequest message.
+type FinishLoginRequest struct {
+	SessionID      string
+	CredentialJSON []byte
+}
+
+// FinishLoginResponse is the FinishLoginResponse message.
+type FinishLoginResponse struct {
+	UserID       []byte
+	CredentialID []byte
+	SignCount    uint32
+	CloneWarning bool
+}
+
+// BeginRegistration implements the BeginRegistration RPC.
+func (s *Server) BeginRegistration(ctx context.Context, req *BeginRegistrationRequest) (*BeginRegistrationResponse, error) {
+	if req.Username == "" {
+		return nil, newError(ErrMissingUsername)
+	}
+
+	user, err := s.Users.LoadUser(ctx, req.Username)
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	creation, session, err := s.WebAuthn.BeginRegistration(user)
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	id, options, err := s.begin(ctx, session, creation)
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	return &BeginRegistrationResponse{SessionID: id, OptionsJSON: options}, nil
+}
+
+// FinishRegistration implements the FinishRegistration RPC.
+func (s *Server) FinishRegistration(ctx context.Context, req *FinishRegistrationRequest) (*FinishRegistrationResponse, error) {
+	session, err := s.takeSession(ctx, req.SessionID)
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	user, err := s.Users.LoadUserByHandle(ctx, session.UserID)
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	r, err := newRequest(ctx, req.CredentialJSON)
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	credential, err := s.WebAuthn.FinishRegistration(user, session, r)
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	if err = s.Users.SaveCredential(ctx, user, credential); err != nil {
+		return nil, newError(err)
+	}
+
+	return &FinishRegistrationResponse{UserID: user.WebAuthnID(), CredentialID: credential.ID}, nil
+}
+
+// BeginLogin implements the BeginLogin RPC. A discoverable login is started when the username is empty.
+func (s *Server) BeginLogin(ctx context.Context, req *BeginLoginRequest) (*BeginLoginResponse, error) {
+	var (
+		assertion interface{}
+		session   *webauthn.SessionData
+		err       error
+	)
+
+	if req.Username == "" {
+		assertion, session, err = s.WebAuthn.BeginDiscoverableLogin()
+	} else {
+		var user webauthn.User
+
+		if user, err = s.Users.LoadUser(ctx, req.Username); err != nil {
+			return nil, newError(err)
+		}
+
+		assertion, session, err = s.WebAuthn.BeginLogin(user)
+	}
+
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	id, options, err := s.begin(ctx, session, assertion)
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	return &BeginLoginResponse{SessionID: id, OptionsJSON: options}, nil
+}
+
+// FinishLogin implements the FinishLogin RPC.
+func (s *Server) FinishLogin(ctx context.Context, req *FinishLoginRequest) (*FinishLoginResponse, error) {
+	session, err := s.takeSession(ctx, req.SessionID)
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	r, err := newRequest(ctx, req.CredentialJSON)
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	var (
+		user       webauthn.User
+		credential *webauthn.Credential
+	)
+
+	if len(session.UserID) == 0 {
+		credential, err = s.WebAuthn.FinishDiscoverableLogin(func(_, userHandle []byte) (found webauthn.User, err error) {
+			if found, err = s.Users.LoadUserByHandle(ctx, userHandle); err != nil {
+				return nil, err
+			}
+
+			user = found
+
+			return found, nil
+		}, session, r)
+	} else {
+		if user, err = s.Users.LoadUserByHandle(ctx, session.UserID); err != nil {
+			return nil, newError(err)
+		}
+
+		credential, err = s.WebAuthn.FinishLogin(user, session, r)
+	}
+
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	if err = s.Users.SaveCredential(ctx, user, credential); err != nil {
+		return nil, newError(err)
+	}
+
+	return &FinishLoginResponse{
+		UserID:       user.WebAuthnID(),
+		CredentialID: credential.ID,
+		SignCount:    credential.Authenticator.SignCount,
+		CloneWarning: credential.Authenticator.CloneWarning,
+	}, nil
+}
+
+// begin saves the session with a new random ID and returns the ID and the JSON encoded options.
+func (s *Server) begin(ctx context.Context, session *webauthn.SessionData, options interface{}) (id string, data []byte, err error) {
+	if data, err = json.Marshal(options); err != nil {
+		return "", nil, err
+	}
+
+	raw := make([]byte, 32)
+
+	if _, err = rand.Read(raw); err != nil {
+		return "", nil, err
+	}
+
+	id = base64.RawURLEncoding.EncodeToString(raw)
+
+	if err = s.Sessions.SaveSession(ctx, id, *session); err != nil {
+		return "", nil, err
+	}
+
+	return id, data, nil
+}
+
+// takeSession loads and deletes the session as a session must only be used once.
+func (s *Server) takeSession(ctx context.Context, id string) (session webauthn.SessionData, err error) {
+	if id == "" {
+		return session, webauthn.ErrSessionNotFound
+	}
+
+	if session, err = s.Sessions.LoadSession(ctx, id); err != nil {
+		return session, err
+	}
+
+	if err = s.Sessions.DeleteSession(ctx, id); err != nil {
+		return session, err
+	}
+
+	return session, nil
+}
+
+// newRequest returns the request passed to the ceremonies for the JSON encoded credential.
+func newRequest(ctx context.Context, credential []byte) (*http.Request, error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, "/", bytes.NewReader(credential))
+	if err != nil {
+		return nil, err
+	}
+
+	r.Header.Set("Content-Type", "application/json")
+
+	return r, nil
+}
@@ -0,0 +1,177 @@
+package webauthngrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthn/webauthnhttp"
+	"github.com/go-webauthn/webauthn/webauthntest"
+	"github.com/go-webauthn/webauthn/webauthntest/webauthnmock"
+)
+
+func newTestServer(t *testing.T) (*Server, *[]webauthn.Credential) {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+	})
+	require.NoError(t, err)
+
+	credentials := &[]webauthn.Credential{}
+
+	user := &webauthnmock.UserMock{
+		WebAuthnIDFunc:          func() []byte { return []byte("1234") },
+		WebAuthnNameFunc:        func() string { return "john" },
+		WebAuthnDisplayNameFunc: func() string { return "John" },
+		WebAuthnIconFunc:        func() string { return "" },
+		WebAuthnCredentialsFunc: func() []webauthn.Credential { return *credentials },
+	}
+
+	users := &webauthnmock.UserStoreMock{
+		LoadUserFunc: func(ctx context.Context, name string) (webauthn.User, error) {
+			if name != "john" {
+				return nil, webauthn.ErrUserNotFound
+			}
+
+			return user, nil
+		},
+		LoadUserByHandleFunc: func(ctx context.Context, userHandle []byte) (webauthn.User, error) {
+			if string(userHandle) != "1234" {
+				return nil, webauthn.ErrUserNotFound
+			}
+
+			return user, nil
+		},
+		SaveCredentialFunc: func(ctx context.Context, user webauthn.User, credential *webauthn.Credential) error {
+			for i := range *credentials {
+				if string((*credentials)[i].ID) == string(credential.ID) {
+					(*credentials)[i] = *credential
+
+					return nil
+				}
+			}
+
+			*credentials = append(*credentials, *credential)
+
+			return nil
+		},
+	}
+
+	return &Server{WebAuthn: w, Users: users, Sessions: webauthnhttp.NewMemorySessionStore()}, credentials
+}
+
+func TestServer(t *testing.T) {
+	server, credentials := newTestServer(t)
+	authenticator := &webauthntest.Authenticator{}
+	ctx := context.Background()
+
+	begin, err := server.BeginRegistration(ctx, &BeginRegistrationRequest{Username: "john"})
+	require.NoError(t, err)
+	require.NotEmpty(t, begin.SessionID)
+
+	var creation protocol.CredentialCreation
+
+	require.NoError(t, json.Unmarshal(begin.OptionsJSON, &creation))
+
+	attestation, created, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+	require.NoError(t, err)
+
+	data, err := json.Marshal(attestation)
+	require.NoError(t, err)
+
+	finish, err := server.FinishRegistration(ctx, &FinishRegistrationRequest{SessionID: begin.SessionID, CredentialJSON: data})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1234"), finish.UserID)
+	assert.Equal(t, created.ID, finish.CredentialID)
+	require.Len(t, *credentials, 1)
+
+	_, err = server.FinishRegistration(ctx, &FinishRegistrationRequest{SessionID: begin.SessionID, CredentialJSON: data})
+	assert.Equal(t, CodeInvalidArgument, ErrorCode(err))
+
+	testCases := []struct {
+		name     string
+		username string
+	}{
+		{"ShouldLoginWithUsername", "john"},
+		{"ShouldLoginDiscoverable", ""},
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			begin, err := server.BeginLogin(ctx, &BeginLoginRequest{Username: tc.username})
+			require.NoError(t, err)
+
+			var assertion protocol.CredentialAssertion
+
+			require.NoError(t, json.Unmarshal(begin.OptionsJSON, &assertion))
+
+			response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+			require.NoError(t, err)
+
+			data, err := json.Marshal(response)
+			require.NoError(t, err)
+
+			finish, err := server.FinishLogin(ctx, &FinishLoginRequest{SessionID: begin.SessionID, CredentialJSON: data})
+			require.NoError(t, err)
+			assert.Equal(t, []byte("1234"), finish.UserID)
+			assert.Equal(t, created.ID, finish.CredentialID)
+			assert.Equal(t, uint32(i+1), finish.SignCount)
+			assert.False(t, finish.CloneWarning)
+		})
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	server, _ := newTestServer(t)
+	ctx := context.Background()
+
+	_, err := server.BeginRegistration(ctx, &BeginRegistrationRequest{})
+	assert.Equal(t, CodeInvalidArgument, ErrorCode(err))
+	assert.True(t, errors.Is(err, ErrMissingUsername))
+
+	_, err = server.BeginLogin(ctx, &BeginLoginRequest{Username: "jane"})
+	assert.Equal(t, CodeNotFound, ErrorCode(err))
+
+	_, err = server.FinishLogin(ctx, &FinishLoginRequest{})
+	assert.Equal(t, CodeInvalidArgument, ErrorCode(err))
+
+	begin, err := server.BeginLogin(ctx, &BeginLoginRequest{})
+	require.NoError(t, err)
+
+	_, err = server.FinishLogin(ctx, &FinishLoginRequest{SessionID: begin.SessionID, CredentialJSON: []byte("{}")})
+	assert.Equal(t, CodeInvalidArgument, ErrorCode(err))
+}
+
+func TestErrorCode(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected Code
+		message  string
+	}{
+		{"ShouldHandleSessionNotFound", webauthn.ErrSessionNotFound, CodeInvalidArgument, webauthn.ErrSessionNotFound.Error()},
+		{"ShouldHandleUserNotFound", webauthn.ErrUserNotFound, CodeNotFound, webauthn.ErrUserNotFound.Error()},
+		{"ShouldHandleTooLarge", protocol.ErrBadRequest.WithCode(protocol.CodeResponseTooLarge), CodeResourceExhausted, protocol.ErrBadRequest.Error()},
+		{"ShouldHandleVerification", protocol.ErrAssertionSignature, CodeUnauthenticated, protocol.ErrAssertionSignature.Error()},
+		{"ShouldHideInternal", errors.New("database unavailable"), CodeInternal, "internal error"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := newError(tc.err)
+
+			assert.Equal(t, tc.expected, ErrorCode(err))
+			assert.Equal(t, tc.message, err.Error())
+			assert.True(t, errors.Is(err, tc.err))
+		})
+	}
+
+	assert.Equal(t, CodeInternal, ErrorCode(errors.New("other")))
+}
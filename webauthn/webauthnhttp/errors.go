@@ -0,0 +1,84 @@
+package webauthnhttp
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+var (
+	// ErrInvalidRequest is returned when the body of a request to a begin handler is not valid JSON.
+	ErrInvalidRequest = errors.New("webauthnhttp: invalid request")
+
+	// ErrMissingUsername is returned by the BeginRegistrationHandler when the request does not contain a username.
+	ErrMissingUsername = errors.New("webauthnhttp: a username is required")
+
+	// ErrNoSession is returned by the finish handlers when the request does not belong to a ceremony in progress.
+	ErrNoSession = errors.New("webauthnhttp: there is no ceremony in progress")
+
+	// ErrMethodNotAllowed is returned when the request does not use a method allowed by the handler.
+	ErrMethodNotAllowed = errors.New("webauthnhttp: method not allowed")
+
+	// ErrUnsupportedMediaType is returned when the request has a Content-Type other than application/json.
+	ErrUnsupportedMediaType = errors.New("webauthnhttp: unsupported media type")
+)
+
+// ErrorResponse is the body of the responses written by WriteError.
+type ErrorResponse struct {
+	// Error is the description of the error. It's a generic description for the errors which are not caused by the
+	// request, so the details of internal errors are never exposed.
+	Error string `json:"error"`
+
+	// Code is the protocol.ErrorCode of the error if it's a *protocol.Error.
+	Code protocol.ErrorCode `json:"code,omitempty"`
+}
+
+// StatusCode returns the HTTP status code for the error returned by a ceremony or a store:
+//
+//   - http.StatusBadRequest for malformed requests and missing sessions.
+//   - http.StatusNotFound for webauthn.ErrUserNotFound.
+//   - http.StatusMethodNotAllowed for ErrMethodNotAllowed.
+//   - http.StatusUnsupportedMediaType for ErrUnsupportedMediaType.
+//   - protocol.GetHTTPStatus(err) for any other error, which is the HTTPStatus of a *protocol.Error and
+//     http.StatusInternalServerError otherwise.
+func StatusCode(err error) int {
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case errors.Is(err, ErrInvalidRequest), errors.Is(err, ErrMissingUsername), errors.Is(err, ErrNoSession),
+		errors.Is(err, webauthn.ErrSessionNotFound):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrMethodNotAllowed):
+		return http.StatusMethodNotAllowed
+	case errors.Is(err, ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, webauthn.ErrUserNotFound):
+		return http.StatusNotFound
+	}
+
+	return protocol.GetHTTPStatus(err)
+}
+
+// NewErrorResponse returns the ErrorResponse of the error. It's used by WriteError and by the adapters which render
+// the errors with the facilities of a framework.
+func NewErrorResponse(err error) ErrorResponse {
+	response := ErrorResponse{Error: err.Error()}
+
+	var e *protocol.Error
+
+	switch status := StatusCode(err); {
+	case errors.As(err, &e):
+		response.Code = e.Code
+	case status == http.StatusInternalServerError:
+		response.Error = http.StatusText(status)
+	}
+
+	return response
+}
+
+// WriteError responds with StatusCode(err) and the JSON encoded ErrorResponse of the error.
+func WriteError(w http.ResponseWriter, err error) {
+	writeJSON(w, StatusCode(err), NewErrorResponse(err))
+}
@@ -0,0 +1,329 @@
+// Package webauthnhttp provides net/http handlers for the registration and login ceremonies. The handlers take care of
+// the JSON encoding, the storage of the session between the beginning and the end of a ceremony, and the mapping of
+// errors to HTTP status codes, so small applications only have to provide the storage of their users:
+//
+//	opts := &webauthnhttp.Options{
+//		WebAuthn: w,
+//		Users:    users,
+//		Sessions: webauthnhttp.NewMemorySessionStore(),
+//	}
+//
+//	mux.Handle("/webauthn/register/begin", webauthnhttp.BeginRegistrationHandler(opts))
+//	mux.Handle("/webauthn/register/finish", webauthnhttp.FinishRegistrationHandler(opts))
+//	mux.Handle("/webauthn/login/begin", webauthnhttp.BeginLoginHandler(opts))
+//	mux.Handle("/webauthn/login/finish", webauthnhttp.FinishLoginHandler(opts))
+//
+// The begin handlers expect a JSON body with the username, such as {"username":"john"}, and respond with the options
+// for navigator.credentials. The finish handlers expect the JSON encoded credential returned by navigator.credentials.
+package webauthnhttp
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// DefaultCookieName is the default name of the cookie which contains the ID of the session.
+const DefaultCookieName = "webauthn_session"
+
+// Paths of the handlers used by the router adapters, relative to the path the adapters are mounted at.
+const (
+	PathBeginRegistration  = "/register/begin"
+	PathFinishRegistration = "/register/finish"
+	PathBeginLogin         = "/login/begin"
+	PathFinishLogin        = "/login/finish"
+)
+
+// maxBeginRequestSize is the maximum size of the body of the requests to the begin handlers.
+const maxBeginRequestSize = 4096
+
+// Ceremonies are the ceremonies performed by the handlers. It's implemented by *webauthn.WebAuthn.
+type Ceremonies interface {
+	webauthn.RegistrationCeremony
+	webauthn.LoginCeremony
+}
+
+// Options configures the handlers. The WebAuthn, Users, and Sessions are required, and the same Options must be used
+// by all the handlers.
+type Options struct {
+	// WebAuthn performs the ceremonies which is usually a *webauthn.WebAuthn.
+	WebAuthn Ceremonies
+
+	// Users loads the users and saves their credentials. The users must exist before they can register a credential.
+	Users webauthn.UserStore
+
+	// Sessions stores the sessions between the begin and finish handlers.
+	Sessions webauthn.SessionStore
+
+	// CookieName is the name of the cookie which contains the ID of the session. The default is DefaultCookieName.
+	CookieName string
+
+	// CookieSecure sets the Secure attribute of the cookie, which should be enabled unless the application is served
+	// over plain HTTP during development. The attribute is always set when the request was received over TLS.
+	CookieSecure bool
+
+	// RegistrationOptions returns the options passed to BeginRegistration for the request.
+	RegistrationOptions func(r *http.Request, user webauthn.User) []webauthn.RegistrationOption
+
+	// LoginOptions returns the options passed to BeginLogin for the request. The user is nil for a discoverable login.
+	LoginOptions func(r *http.Request, user webauthn.User) []webauthn.LoginOption
+
+	// Success is called by the finish handlers after the credential was saved, and is usually used to establish the
+	// session of the application. The default responds with {"status":"ok"}.
+	Success func(w http.ResponseWriter, r *http.Request, user webauthn.User, credential *webauthn.Credential)
+
+	// Error is called with the error when a handler fails. The default responds with StatusCode(err) and the JSON
+	// encoded ErrorResponse.
+	Error func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// BeginRequest is the body of the requests to the begin handlers.
+type BeginRequest struct {
+	// Username is the name of the user. It's required for the registration, and a discoverable login is started when
+	// it's empty for the login.
+	Username string `json:"username"`
+}
+
+// SuccessResponse is the default body of the responses of the finish handlers.
+type SuccessResponse struct {
+	Status string `json:"status"`
+}
+
+// BeginRegistrationHandler returns the handler which begins the registration of a credential for the user.
+func BeginRegistrationHandler(opts *Options) http.Handler {
+	return post(opts, func(w http.ResponseWriter, r *http.Request) error {
+		request, err := decodeBeginRequest(r)
+		if err != nil {
+			return err
+		}
+
+		if request.Username == "" {
+			return ErrMissingUsername
+		}
+
+		user, err := opts.Users.LoadUser(r.Context(), request.Username)
+		if err != nil {
+			return err
+		}
+
+		var options []webauthn.RegistrationOption
+
+		if opts.RegistrationOptions != nil {
+			options = opts.RegistrationOptions(r, user)
+		}
+
+		creation, session, err := opts.WebAuthn.BeginRegistration(user, options...)
+		if err != nil {
+			return err
+		}
+
+		if err = saveSession(w, r, opts, session); err != nil {
+			return err
+		}
+
+		writeJSON(w, http.StatusOK, creation)
+
+		return nil
+	})
+}
+
+// FinishRegistrationHandler returns the handler which finishes the registration started by the
+// BeginRegistrationHandler and saves the new credential.
+func FinishRegistrationHandler(opts *Options) http.Handler {
+	return post(opts, func(w http.ResponseWriter, r *http.Request) error {
+		session, err := takeSession(w, r, opts)
+		if err != nil {
+			return err
+		}
+
+		user, err := opts.Users.LoadUserByHandle(r.Context(), session.UserID)
+		if err != nil {
+			return err
+		}
+
+		credential, err := opts.WebAuthn.FinishRegistration(user, session, r)
+		if err != nil {
+			return err
+		}
+
+		if err = opts.Users.SaveCredential(r.Context(), user, credential); err != nil {
+			return err
+		}
+
+		success(w, r, opts, user, credential)
+
+		return nil
+	})
+}
+
+// BeginLoginHandler returns the handler which begins the login of the user, or a discoverable login when the username
+// is empty.
+func BeginLoginHandler(opts *Options) http.Handler {
+	return post(opts, func(w http.ResponseWriter, r *http.Request) error {
+		request, err := decodeBeginRequest(r)
+		if err != nil {
+			return err
+		}
+
+		var user webauthn.User
+
+		if request.Username != "" {
+			if user, err = opts.Users.LoadUser(r.Context(), request.Username); err != nil {
+				return err
+			}
+		}
+
+		var options []webauthn.LoginOption
+
+		if opts.LoginOptions != nil {
+			options = opts.LoginOptions(r, user)
+		}
+
+		assertion, session, err := beginLogin(opts, user, options)
+		if err != nil {
+			return err
+		}
+
+		if err = saveSession(w, r, opts, session); err != nil {
+			return err
+		}
+
+		writeJSON(w, http.StatusOK, assertion)
+
+		return nil
+	})
+}
+
+// FinishLoginHandler returns the handler which finishes the login started by the BeginLoginHandler and saves the
+// updated credential.
+func FinishLoginHandler(opts *Options) http.Handler {
+	return post(opts, func(w http.ResponseWriter, r *http.Request) error {
+		session, err := takeSession(w, r, opts)
+		if err != nil {
+			return err
+		}
+
+		var (
+			user       webauthn.User
+			credential *webauthn.Credential
+		)
+
+		if len(session.UserID) == 0 {
+			credential, err = opts.WebAuthn.FinishDiscoverableLogin(func(_, userHandle []byte) (found webauthn.User, err error) {
+				if found, err = opts.Users.LoadUserByHandle(r.Context(), userHandle); err != nil {
+					return nil, err
+				}
+
+				user = found
+
+				return found, nil
+			}, session, r)
+		} else {
+			if user, err = opts.Users.LoadUserByHandle(r.Context(), session.UserID); err != nil {
+				return err
+			}
+
+			credential, err = opts.WebAuthn.FinishLogin(user, session, r)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err = opts.Users.SaveCredential(r.Context(), user, credential); err != nil {
+			return err
+		}
+
+		success(w, r, opts, user, credential)
+
+		return nil
+	})
+}
+
+func beginLogin(opts *Options, user webauthn.User, options []webauthn.LoginOption) (*protocol.CredentialAssertion, *webauthn.SessionData, error) {
+	if user == nil {
+		return opts.WebAuthn.BeginDiscoverableLogin(options...)
+	}
+
+	return opts.WebAuthn.BeginLogin(user, options...)
+}
+
+func decodeBeginRequest(r *http.Request) (request BeginRequest, err error) {
+	if r.Body == nil {
+		return request, ErrInvalidRequest
+	}
+
+	if err = json.NewDecoder(io.LimitReader(r.Body, maxBeginRequestSize)).Decode(&request); err != nil {
+		return request, ErrInvalidRequest
+	}
+
+	request.Username = strings.TrimSpace(request.Username)
+
+	return request, nil
+}
+
+func success(w http.ResponseWriter, r *http.Request, opts *Options, user webauthn.User, credential *webauthn.Credential) {
+	if opts.Success != nil {
+		opts.Success(w, r, user, credential)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, SuccessResponse{Status: "ok"})
+}
+
+// post adapts the handler function to an http.Handler which only allows the POST method with a JSON body, and handles
+// the errors.
+func post(opts *Options, handler func(w http.ResponseWriter, r *http.Request) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+
+		switch {
+		case r.Method != http.MethodPost:
+			w.Header().Set("Allow", http.MethodPost)
+
+			err = ErrMethodNotAllowed
+		case !isJSON(r):
+			err = ErrUnsupportedMediaType
+		default:
+			err = handler(w, r)
+		}
+
+		if err == nil {
+			return
+		}
+
+		if opts.Error != nil {
+			opts.Error(w, r, err)
+
+			return
+		}
+
+		WriteError(w, err)
+	})
+}
+
+// isJSON returns true if the request has no Content-Type, or the application/json Content-Type.
+func isJSON(r *http.Request) bool {
+	value := r.Header.Get("Content-Type")
+	if value == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(value)
+
+	return err == nil && mediaType == "application/json"
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,316 @@
+package webauthnhttp_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthn/webauthnhttp"
+	"github.com/go-webauthn/webauthn/webauthntest"
+)
+
+type testUser struct {
+	id          []byte
+	name        string
+	credentials []webauthn.Credential
+}
+
+func (u *testUser) WebAuthnID() []byte {
+	return u.id
+}
+
+func (u *testUser) WebAuthnName() string {
+	return u.name
+}
+
+func (u *testUser) WebAuthnDisplayName() string {
+	return u.name
+}
+
+func (u *testUser) WebAuthnIcon() string {
+	return ""
+}
+
+func (u *testUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+type testUserStore struct {
+	mu    sync.Mutex
+	users []*testUser
+}
+
+func (s *testUserStore) LoadUser(_ context.Context, name string) (webauthn.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.users {
+		if user.name == name {
+			return user, nil
+		}
+	}
+
+	return nil, webauthn.ErrUserNotFound
+}
+
+func (s *testUserStore) LoadUserByHandle(_ context.Context, userHandle []byte) (webauthn.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.users {
+		if bytes.Equal(user.id, userHandle) {
+			return user, nil
+		}
+	}
+
+	return nil, webauthn.ErrUserNotFound
+}
+
+func (s *testUserStore) SaveCredential(_ context.Context, user webauthn.User, credential *webauthn.Credential) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := user.(*testUser)
+
+	for i := range u.credentials {
+		if bytes.Equal(u.credentials[i].ID, credential.ID) {
+			u.credentials[i] = *credential
+
+			return nil
+		}
+	}
+
+	u.credentials = append(u.credentials, *credential)
+
+	return nil
+}
+
+type testClient struct {
+	t      *testing.T
+	server *httptest.Server
+	client *http.Client
+}
+
+func newTestClient(t *testing.T, opts *webauthnhttp.Options) *testClient {
+	mux := http.NewServeMux()
+
+	mux.Handle(webauthnhttp.PathBeginRegistration, webauthnhttp.BeginRegistrationHandler(opts))
+	mux.Handle(webauthnhttp.PathFinishRegistration, webauthnhttp.FinishRegistrationHandler(opts))
+	mux.Handle(webauthnhttp.PathBeginLogin, webauthnhttp.BeginLoginHandler(opts))
+	mux.Handle(webauthnhttp.PathFinishLogin, webauthnhttp.FinishLoginHandler(opts))
+
+	server := httptest.NewServer(mux)
+
+	t.Cleanup(server.Close)
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+
+	return &testClient{t: t, server: server, client: &http.Client{Jar: jar}}
+}
+
+func (c *testClient) post(path string, body interface{}, v interface{}) int {
+	data, err := json.Marshal(body)
+	require.NoError(c.t, err)
+
+	resp, err := c.client.Post(c.server.URL+path, "application/json", bytes.NewReader(data))
+	require.NoError(c.t, err)
+
+	defer resp.Body.Close()
+
+	if v != nil {
+		require.NoError(c.t, json.NewDecoder(resp.Body).Decode(v))
+	}
+
+	return resp.StatusCode
+}
+
+func newTestOptions(t *testing.T) (*webauthnhttp.Options, *testUserStore) {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+	})
+	require.NoError(t, err)
+
+	users := &testUserStore{users: []*testUser{{id: []byte("1234"), name: "john"}}}
+
+	return &webauthnhttp.Options{
+		WebAuthn: w,
+		Users:    users,
+		Sessions: webauthnhttp.NewMemorySessionStore(),
+	}, users
+}
+
+func TestHandlers(t *testing.T) {
+	opts, users := newTestOptions(t)
+	client := newTestClient(t, opts)
+	authenticator := &webauthntest.Authenticator{}
+
+	var creation protocol.CredentialCreation
+
+	require.Equal(t, http.StatusOK, client.post("/register/begin", webauthnhttp.BeginRequest{Username: "john"}, &creation))
+
+	attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+	require.NoError(t, err)
+
+	var result webauthnhttp.SuccessResponse
+
+	require.Equal(t, http.StatusOK, client.post("/register/finish", attestation, &result))
+	assert.Equal(t, "ok", result.Status)
+	require.Len(t, users.users[0].credentials, 1)
+
+	testCases := []struct {
+		name     string
+		username string
+		counter  uint32
+	}{
+		{"ShouldLoginWithUsername", "john", 1},
+		{"ShouldLoginDiscoverable", "", 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var assertion protocol.CredentialAssertion
+
+			require.Equal(t, http.StatusOK, client.post("/login/begin", webauthnhttp.BeginRequest{Username: tc.username}, &assertion))
+
+			response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+			require.NoError(t, err)
+
+			var result webauthnhttp.SuccessResponse
+
+			require.Equal(t, http.StatusOK, client.post("/login/finish", response, &result))
+			assert.Equal(t, "ok", result.Status)
+			assert.Equal(t, tc.counter, users.users[0].credentials[0].Authenticator.SignCount)
+
+			var e webauthnhttp.ErrorResponse
+
+			assert.Equal(t, http.StatusBadRequest, client.post("/login/finish", response, &e))
+			assert.Equal(t, webauthnhttp.ErrNoSession.Error(), e.Error)
+		})
+	}
+}
+
+func TestHandlersErrors(t *testing.T) {
+	opts, _ := newTestOptions(t)
+	client := newTestClient(t, opts)
+
+	t.Run("ShouldRejectMethod", func(t *testing.T) {
+		resp, err := client.client.Get(client.server.URL + "/login/begin")
+		require.NoError(t, err)
+
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+		assert.Equal(t, http.MethodPost, resp.Header.Get("Allow"))
+	})
+
+	t.Run("ShouldRejectInvalidRequest", func(t *testing.T) {
+		resp, err := client.client.Post(client.server.URL+"/login/begin", "application/json", bytes.NewReader([]byte("{")))
+		require.NoError(t, err)
+
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("ShouldRejectMediaType", func(t *testing.T) {
+		resp, err := client.client.Post(client.server.URL+"/login/begin", "text/plain", bytes.NewReader([]byte("{}")))
+		require.NoError(t, err)
+
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+	})
+
+	t.Run("ShouldRequireUsername", func(t *testing.T) {
+		var e webauthnhttp.ErrorResponse
+
+		assert.Equal(t, http.StatusBadRequest, client.post("/register/begin", webauthnhttp.BeginRequest{Username: " "}, &e))
+		assert.Equal(t, webauthnhttp.ErrMissingUsername.Error(), e.Error)
+	})
+
+	t.Run("ShouldRejectUnknownUser", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, client.post("/login/begin", webauthnhttp.BeginRequest{Username: "jane"}, nil))
+	})
+
+	t.Run("ShouldRejectWithoutSession", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, client.post("/register/finish", struct{}{}, nil))
+	})
+
+	t.Run("ShouldRejectInvalidResponse", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, client.post("/login/begin", webauthnhttp.BeginRequest{}, nil))
+
+		var e webauthnhttp.ErrorResponse
+
+		assert.Equal(t, http.StatusBadRequest, client.post("/login/finish", struct{}{}, &e))
+		assert.NotEmpty(t, e.Code)
+	})
+}
+
+func TestHandlersCallbacks(t *testing.T) {
+	opts, _ := newTestOptions(t)
+
+	var called error
+
+	opts.Error = func(w http.ResponseWriter, r *http.Request, err error) {
+		called = err
+
+		w.WriteHeader(http.StatusTeapot)
+	}
+
+	client := newTestClient(t, opts)
+
+	assert.Equal(t, http.StatusTeapot, client.post("/register/begin", webauthnhttp.BeginRequest{}, nil))
+	assert.Equal(t, webauthnhttp.ErrMissingUsername, called)
+}
+
+func TestStatusCode(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"ShouldHandleNil", nil, http.StatusOK},
+		{"ShouldHandleMissingUsername", webauthnhttp.ErrMissingUsername, http.StatusBadRequest},
+		{"ShouldHandleNoSession", webauthnhttp.ErrNoSession, http.StatusBadRequest},
+		{"ShouldHandleSessionNotFound", fmt.Errorf("load: %w", webauthn.ErrSessionNotFound), http.StatusBadRequest},
+		{"ShouldHandleUserNotFound", webauthn.ErrUserNotFound, http.StatusNotFound},
+		{"ShouldHandleMethodNotAllowed", webauthnhttp.ErrMethodNotAllowed, http.StatusMethodNotAllowed},
+		{"ShouldHandleUnsupportedMediaType", webauthnhttp.ErrUnsupportedMediaType, http.StatusUnsupportedMediaType},
+		{"ShouldHandleTooLarge", protocol.ErrBadRequest.WithCode(protocol.CodeResponseTooLarge), http.StatusRequestEntityTooLarge},
+		{"ShouldHandleVerification", protocol.ErrAssertionSignature, http.StatusUnauthorized},
+		{"ShouldHandleInternal", errors.New("database unavailable"), http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, webauthnhttp.StatusCode(tc.err))
+		})
+	}
+}
+
+func TestWriteErrorHidesInternalErrors(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	webauthnhttp.WriteError(w, errors.New("database unavailable"))
+
+	var e webauthnhttp.ErrorResponse
+
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&e))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusText(http.StatusInternalServerError), e.Error)
+}
@@ -0,0 +1,43 @@
+package webauthnhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// relatedOriginsMaxAge is the number of seconds clients may cache the RelatedOrigins document.
+const relatedOriginsMaxAge = "86400"
+
+// RelatedOriginsHandler returns the handler which serves the RelatedOrigins document, which must be mounted at the
+// protocol.RelatedOriginsPath of the RPID:
+//
+//	mux.Handle(protocol.RelatedOriginsPath, webauthnhttp.RelatedOriginsHandler(w.RelatedOrigins()))
+//
+// The handler only allows the GET and HEAD methods, and the document is encoded once when the handler is created.
+func RelatedOriginsHandler(document protocol.RelatedOrigins) http.Handler {
+	if document.Origins == nil {
+		document.Origins = []string{}
+	}
+
+	body, _ := json.Marshal(document)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", strings.Join([]string{http.MethodGet, http.MethodHead}, ", "))
+
+			WriteError(w, ErrMethodNotAllowed)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age="+relatedOriginsMaxAge)
+
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(body))
+	})
+}
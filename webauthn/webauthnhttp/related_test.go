@@ -0,0 +1,60 @@
+package webauthnhttp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthn/webauthnhttp"
+)
+
+func TestRelatedOriginsHandler(t *testing.T) {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com", "https://example.co.uk", "https://example.de"},
+	})
+	require.NoError(t, err)
+
+	handler := webauthnhttp.RelatedOriginsHandler(w.RelatedOrigins())
+
+	testCases := []struct {
+		name   string
+		method string
+		status int
+		body   string
+	}{
+		{"ShouldServeDocument", http.MethodGet, http.StatusOK, `{"origins":["https://example.co.uk","https://example.de"]}`},
+		{"ShouldServeHeaders", http.MethodHead, http.StatusOK, ""},
+		{"ShouldRejectPost", http.MethodPost, http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, httptest.NewRequest(tc.method, protocol.RelatedOriginsPath, nil))
+
+			assert.Equal(t, tc.status, recorder.Code)
+
+			if tc.status != http.StatusOK {
+				assert.Equal(t, "GET, HEAD", recorder.Header().Get("Allow"))
+
+				return
+			}
+
+			assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+			if tc.body == "" {
+				assert.Empty(t, recorder.Body.String())
+			} else {
+				assert.JSONEq(t, tc.body, recorder.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package webauthnhttp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// MemorySessionStore is a webauthn.SessionStore which keeps the sessions in memory. It's only suitable for applications
+// with a single instance, and the expired sessions are removed when new sessions are saved.
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]webauthn.SessionData
+}
+
+// NewMemorySessionStore returns a new empty *MemorySessionStore.
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: map[string]webauthn.SessionData{}}
+}
+
+// SaveSession implements the webauthn.SessionStore interface.
+func (s *MemorySessionStore) SaveSession(_ context.Context, id string, session webauthn.SessionData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	for key, value := range s.sessions {
+		if !value.Expires.IsZero() && value.Expires.Before(now) {
+			delete(s.sessions, key)
+		}
+	}
+
+	s.sessions[id] = session
+
+	return nil
+}
+
+// LoadSession implements the webauthn.SessionStore interface.
+func (s *MemorySessionStore) LoadSession(_ context.Context, id string) (webauthn.SessionData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return session, webauthn.ErrSessionNotFound
+	}
+
+	return session, nil
+}
+
+// DeleteSession implements the webauthn.SessionStore interface.
+func (s *MemorySessionStore) DeleteSession(_ context.Context, id string) error {
+	s.mu.Lock()
+	delete(s.sessions, id)
+	s.mu.Unlock()
+
+	return nil
+}
+
+var _ webauthn.SessionStore = (*MemorySessionStore)(nil)
+
+// saveSession saves the session with a new random ID and sets the cookie with the ID.
+func saveSession(w http.ResponseWriter, r *http.Request, opts *Options, session *webauthn.SessionData) error {
+	raw := make([]byte, 32)
+
+	if _, err := rand.Read(raw); err != nil {
+		return err
+	}
+
+	id := base64.RawURLEncoding.EncodeToString(raw)
+
+	if err := opts.Sessions.SaveSession(r.Context(), id, *session); err != nil {
+		return err
+	}
+
+	http.SetCookie(w, cookie(r, opts, id, 0))
+
+	return nil
+}
+
+// takeSession loads the session of the request, and deletes it along with its cookie as a session must only be used
+// once.
+func takeSession(w http.ResponseWriter, r *http.Request, opts *Options) (session webauthn.SessionData, err error) {
+	c, err := r.Cookie(cookieName(opts))
+	if err != nil || c.Value == "" {
+		return session, ErrNoSession
+	}
+
+	http.SetCookie(w, cookie(r, opts, "", -1))
+
+	if session, err = opts.Sessions.LoadSession(r.Context(), c.Value); err != nil {
+		return session, err
+	}
+
+	if err = opts.Sessions.DeleteSession(r.Context(), c.Value); err != nil {
+		return session, err
+	}
+
+	return session, nil
+}
+
+func cookie(r *http.Request, opts *Options, value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     cookieName(opts),
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   opts.CookieSecure || r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	}
+}
+
+func cookieName(opts *Options) string {
+	if opts.CookieName == "" {
+		return DefaultCookieName
+	}
+
+	return opts.CookieName
+}
@@ -0,0 +1,28 @@
+// Package webauthnchi mounts the webauthnhttp handlers on a chi router. The package does not import chi as its
+// handlers are plain net/http handlers, instead the Router interface is implemented by chi.Router:
+//
+//	r.Route("/webauthn", func(r chi.Router) {
+//		webauthnchi.Mount(r, opts)
+//	})
+package webauthnchi
+
+import (
+	"net/http"
+
+	"github.com/go-webauthn/webauthn/webauthn/webauthnhttp"
+)
+
+// Router is the subset of the chi.Router interface used by Mount.
+type Router interface {
+	Post(pattern string, h http.HandlerFunc)
+}
+
+// Mount registers the handlers of the ceremonies on the router at the webauthnhttp paths such as
+// webauthnhttp.PathBeginRegistration. The errors are rendered by webauthnhttp.WriteError unless the Options has an
+// Error function.
+func Mount(r Router, opts *webauthnhttp.Options) {
+	r.Post(webauthnhttp.PathBeginRegistration, webauthnhttp.BeginRegistrationHandler(opts).ServeHTTP)
+	r.Post(webauthnhttp.PathFinishRegistration, webauthnhttp.FinishRegistrationHandler(opts).ServeHTTP)
+	r.Post(webauthnhttp.PathBeginLogin, webauthnhttp.BeginLoginHandler(opts).ServeHTTP)
+	r.Post(webauthnhttp.PathFinishLogin, webauthnhttp.FinishLoginHandler(opts).ServeHTTP)
+}
@@ -0,0 +1,64 @@
+package webauthnchi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthn/webauthnhttp"
+	"github.com/go-webauthn/webauthn/webauthntest/webauthnmock"
+)
+
+type testRouter struct {
+	routes map[string]http.HandlerFunc
+}
+
+func (r *testRouter) Post(pattern string, h http.HandlerFunc) {
+	r.routes[pattern] = h
+}
+
+func TestMount(t *testing.T) {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+	})
+	require.NoError(t, err)
+
+	router := &testRouter{routes: map[string]http.HandlerFunc{}}
+
+	Mount(router, &webauthnhttp.Options{
+		WebAuthn: w,
+		Users:    &webauthnmock.UserStoreMock{},
+		Sessions: webauthnhttp.NewMemorySessionStore(),
+	})
+
+	require.Len(t, router.routes, 4)
+
+	for _, path := range []string{webauthnhttp.PathBeginRegistration, webauthnhttp.PathFinishRegistration, webauthnhttp.PathBeginLogin, webauthnhttp.PathFinishLogin} {
+		require.Contains(t, router.routes, path)
+	}
+
+	rec := httptest.NewRecorder()
+
+	router.routes[webauthnhttp.PathBeginLogin](rec, httptest.NewRequest(http.MethodPost, webauthnhttp.PathBeginLogin, strings.NewReader("{}")))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	rec = httptest.NewRecorder()
+
+	router.routes[webauthnhttp.PathBeginRegistration](rec, httptest.NewRequest(http.MethodPost, webauthnhttp.PathBeginRegistration, strings.NewReader("{}")))
+
+	var e webauthnhttp.ErrorResponse
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&e))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, webauthnhttp.ErrMissingUsername.Error(), e.Error)
+}
@@ -0,0 +1,29 @@
+// Package webauthnecho registers the webauthnhttp handlers on an echo router. The package does not import echo so the
+// module doesn't depend on it, instead the Route function wraps the plain net/http handlers with echo.WrapHandler:
+//
+//	group := e.Group("/webauthn")
+//
+//	webauthnecho.Register(func(path string, h http.Handler) {
+//		group.POST(path, echo.WrapHandler(h))
+//	}, opts)
+package webauthnecho
+
+import (
+	"net/http"
+
+	"github.com/go-webauthn/webauthn/webauthn/webauthnhttp"
+)
+
+// Route registers the handler for the POST requests at the path, which is usually the POST method of an *echo.Echo or
+// *echo.Group with the handler wrapped by echo.WrapHandler.
+type Route func(path string, h http.Handler)
+
+// Register registers the handlers of the ceremonies with the route at the webauthnhttp paths such as
+// webauthnhttp.PathBeginRegistration. The errors are rendered by webauthnhttp.WriteError unless the Options has an
+// Error function, as the handlers write their own responses instead of returning the errors to echo.
+func Register(route Route, opts *webauthnhttp.Options) {
+	route(webauthnhttp.PathBeginRegistration, webauthnhttp.BeginRegistrationHandler(opts))
+	route(webauthnhttp.PathFinishRegistration, webauthnhttp.FinishRegistrationHandler(opts))
+	route(webauthnhttp.PathBeginLogin, webauthnhttp.BeginLoginHandler(opts))
+	route(webauthnhttp.PathFinishLogin, webauthnhttp.FinishLoginHandler(opts))
+}
@@ -0,0 +1,33 @@
+// Package webauthnfiber registers the webauthnhttp handlers on a fiber router. The package does not import fiber so
+// the module doesn't depend on it, instead the Route function converts the plain net/http handlers to fiber handlers
+// with the adaptor middleware of fiber:
+//
+//	group := app.Group("/webauthn")
+//
+//	webauthnfiber.Register(func(path string, h http.Handler) {
+//		group.Post(path, adaptor.HTTPHandler(h))
+//	}, opts)
+//
+// The adaptor copies the fasthttp request to an *http.Request. Applications which avoid the copy can instead call the
+// Bytes variants of the finish methods such as webauthn.WebAuthn FinishRegistrationBytes with the body of the request.
+package webauthnfiber
+
+import (
+	"net/http"
+
+	"github.com/go-webauthn/webauthn/webauthn/webauthnhttp"
+)
+
+// Route registers the handler for the POST requests at the path, which is usually the Post method of a fiber.Router
+// with the handler converted by adaptor.HTTPHandler.
+type Route func(path string, h http.Handler)
+
+// Register registers the handlers of the ceremonies with the route at the webauthnhttp paths such as
+// webauthnhttp.PathBeginRegistration. The errors are rendered by webauthnhttp.WriteError unless the Options has an
+// Error function.
+func Register(route Route, opts *webauthnhttp.Options) {
+	route(webauthnhttp.PathBeginRegistration, webauthnhttp.BeginRegistrationHandler(opts))
+	route(webauthnhttp.PathFinishRegistration, webauthnhttp.FinishRegistrationHandler(opts))
+	route(webauthnhttp.PathBeginLogin, webauthnhttp.BeginLoginHandler(opts))
+	route(webauthnhttp.PathFinishLogin, webauthnhttp.FinishLoginHandler(opts))
+}
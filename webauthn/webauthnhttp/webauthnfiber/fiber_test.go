@@ -0,0 +1,138 @@
+package webauthnfiber
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthn/webauthnhttp"
+	"github.com/go-webauthn/webauthn/webauthntest"
+	"github.com/go-webauthn/webauthn/webauthntest/webauthnmock"
+)
+
+type testClient struct {
+	t      *testing.T
+	routes map[string]http.Handler
+	cookie *http.Cookie
+}
+
+func (c *testClient) post(path string, body interface{}, v interface{}) int {
+	data, err := json.Marshal(body)
+	require.NoError(c.t, err)
+
+	r := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data))
+	r.Header.Set("Content-Type", "application/json")
+
+	if c.cookie != nil {
+		r.AddCookie(c.cookie)
+	}
+
+	require.Contains(c.t, c.routes, path)
+
+	rec := httptest.NewRecorder()
+
+	c.routes[path].ServeHTTP(rec, r)
+
+	resp := rec.Result()
+
+	defer resp.Body.Close()
+
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == webauthnhttp.DefaultCookieName {
+			c.cookie = cookie
+		}
+	}
+
+	if v != nil {
+		require.NoError(c.t, json.NewDecoder(resp.Body).Decode(v))
+	}
+
+	return resp.StatusCode
+}
+
+func TestRegister(t *testing.T) {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+	})
+	require.NoError(t, err)
+
+	var credentials []webauthn.Credential
+
+	user := &webauthnmock.UserMock{
+		WebAuthnIDFunc:          func() []byte { return []byte("1234") },
+		WebAuthnNameFunc:        func() string { return "john" },
+		WebAuthnDisplayNameFunc: func() string { return "John" },
+		WebAuthnIconFunc:        func() string { return "" },
+		WebAuthnCredentialsFunc: func() []webauthn.Credential { return credentials },
+	}
+
+	users := &webauthnmock.UserStoreMock{
+		LoadUserFunc: func(ctx context.Context, name string) (webauthn.User, error) {
+			if name != "john" {
+				return nil, webauthn.ErrUserNotFound
+			}
+
+			return user, nil
+		},
+		LoadUserByHandleFunc: func(ctx context.Context, userHandle []byte) (webauthn.User, error) {
+			return user, nil
+		},
+		SaveCredentialFunc: func(ctx context.Context, user webauthn.User, credential *webauthn.Credential) error {
+			credentials = []webauthn.Credential{*credential}
+
+			return nil
+		},
+	}
+
+	client := &testClient{t: t, routes: map[string]http.Handler{}}
+
+	Register(func(path string, h http.Handler) {
+		client.routes[path] = h
+	}, &webauthnhttp.Options{WebAuthn: w, Users: users, Sessions: webauthnhttp.NewMemorySessionStore()})
+	authenticator := &webauthntest.Authenticator{}
+
+	var creation protocol.CredentialCreation
+
+	require.Equal(t, http.StatusOK, client.post(webauthnhttp.PathBeginRegistration, webauthnhttp.BeginRequest{Username: "john"}, &creation))
+
+	attestation, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+	require.NoError(t, err)
+
+	var result webauthnhttp.SuccessResponse
+
+	require.Equal(t, http.StatusOK, client.post(webauthnhttp.PathFinishRegistration, attestation, &result))
+	assert.Equal(t, "ok", result.Status)
+	require.Len(t, credentials, 1)
+
+	for i, username := range []string{"john", ""} {
+		var assertion protocol.CredentialAssertion
+
+		require.Equal(t, http.StatusOK, client.post(webauthnhttp.PathBeginLogin, webauthnhttp.BeginRequest{Username: username}, &assertion))
+
+		response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusOK, client.post(webauthnhttp.PathFinishLogin, response, &result))
+		assert.Equal(t, uint32(i+1), credentials[0].Authenticator.SignCount)
+
+		var e webauthnhttp.ErrorResponse
+
+		assert.Equal(t, http.StatusBadRequest, client.post(webauthnhttp.PathFinishLogin, response, &e))
+		assert.Equal(t, webauthnhttp.ErrNoSession.Error(), e.Error)
+	}
+
+	var e webauthnhttp.ErrorResponse
+
+	assert.Equal(t, http.StatusNotFound, client.post(webauthnhttp.PathBeginLogin, webauthnhttp.BeginRequest{Username: "jane"}, &e))
+	assert.Equal(t, webauthn.ErrUserNotFound.Error(), e.Error)
+}
@@ -0,0 +1,29 @@
+// Package webauthngin registers the webauthnhttp handlers on a gin router. The package does not import gin so the
+// module doesn't depend on it, instead the Route function wraps the plain net/http handlers with gin.WrapH:
+//
+//	group := router.Group("/webauthn")
+//
+//	webauthngin.Register(func(path string, h http.Handler) {
+//		group.POST(path, gin.WrapH(h))
+//	}, opts)
+package webauthngin
+
+import (
+	"net/http"
+
+	"github.com/go-webauthn/webauthn/webauthn/webauthnhttp"
+)
+
+// Route registers the handler for the POST requests at the path, which is usually the POST method of a gin.IRoutes
+// with the handler wrapped by gin.WrapH.
+type Route func(path string, h http.Handler)
+
+// Register registers the handlers of the ceremonies with the route at the webauthnhttp paths such as
+// webauthnhttp.PathBeginRegistration. The errors are rendered by webauthnhttp.WriteError unless the Options has an
+// Error function.
+func Register(route Route, opts *webauthnhttp.Options) {
+	route(webauthnhttp.PathBeginRegistration, webauthnhttp.BeginRegistrationHandler(opts))
+	route(webauthnhttp.PathFinishRegistration, webauthnhttp.FinishRegistrationHandler(opts))
+	route(webauthnhttp.PathBeginLogin, webauthnhttp.BeginLoginHandler(opts))
+	route(webauthnhttp.PathFinishLogin, webauthnhttp.FinishLoginHandler(opts))
+}
@@ -0,0 +1,75 @@
+package webauthngin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthn/webauthnhttp"
+	"github.com/go-webauthn/webauthn/webauthntest/webauthnmock"
+)
+
+func TestRegister(t *testing.T) {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+	})
+	require.NoError(t, err)
+
+	routes := map[string]http.Handler{}
+
+	Register(func(path string, h http.Handler) {
+		routes[path] = h
+	}, &webauthnhttp.Options{
+		WebAuthn: w,
+		Users:    &webauthnmock.UserStoreMock{},
+		Sessions: webauthnhttp.NewMemorySessionStore(),
+	})
+
+	require.Len(t, routes, 4)
+
+	testCases := []struct {
+		name     string
+		path     string
+		body     string
+		expected int
+		err      error
+	}{
+		{"ShouldBeginDiscoverableLogin", webauthnhttp.PathBeginLogin, "{}", http.StatusOK, nil},
+		{"ShouldRenderMissingUsername", webauthnhttp.PathBeginRegistration, "{}", http.StatusBadRequest, webauthnhttp.ErrMissingUsername},
+		{"ShouldRenderNoSession", webauthnhttp.PathFinishLogin, "{}", http.StatusBadRequest, webauthnhttp.ErrNoSession},
+		{"ShouldRenderNoSessionRegistration", webauthnhttp.PathFinishRegistration, "{}", http.StatusBadRequest, webauthnhttp.ErrNoSession},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Contains(t, routes, tc.path)
+
+			r := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+			r.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+
+			routes[tc.path].ServeHTTP(rec, r)
+
+			assert.Equal(t, tc.expected, rec.Code)
+			assert.True(t, strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json"))
+
+			if tc.err == nil {
+				return
+			}
+
+			var e webauthnhttp.ErrorResponse
+
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&e))
+			assert.Equal(t, tc.err.Error(), e.Error)
+		})
+	}
+}
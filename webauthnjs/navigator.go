@@ -0,0 +1,210 @@
+//go:build js && wasm
+
+package webauthnjs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"syscall/js"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// ErrNotSupported is returned when the browser does not support WebAuthn.
+var ErrNotSupported = errors.New("webauthnjs: navigator.credentials is not available")
+
+// Error is a DOMException raised by navigator.credentials. The Name identifies the error, for example NotAllowedError
+// when the user cancelled the ceremony or it timed out, and InvalidStateError when the authenticator already contains
+// an excluded credential.
+type Error struct {
+	Name    string
+	Message string
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return "webauthnjs: " + e.Name + ": " + e.Message
+}
+
+// CreateCredential calls navigator.credentials.create with the options and returns the created credential. The
+// ceremony is aborted when the context is done. It blocks until the promise is settled so it must not be called from
+// the goroutine of a js.Func callback.
+func CreateCredential(ctx context.Context, options protocol.PublicKeyCredentialCreationOptions) (*protocol.CredentialCreationResponse, error) {
+	credential, err := call(ctx, "create", options, creationBinaryPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	response := credential.Get("response")
+
+	result := &protocol.CredentialCreationResponse{
+		PublicKeyCredential: publicKeyCredential(credential),
+		AttestationResponse: protocol.AuthenticatorAttestationResponse{
+			AuthenticatorResponse: protocol.AuthenticatorResponse{ClientDataJSON: bytesOf(response.Get("clientDataJSON"))},
+			AttestationObject:     bytesOf(response.Get("attestationObject")),
+		},
+	}
+
+	if response.Get("getTransports").Type() == js.TypeFunction {
+		transports := response.Call("getTransports")
+
+		for i := 0; i < transports.Length(); i++ {
+			result.AttestationResponse.Transports = append(result.AttestationResponse.Transports, transports.Index(i).String())
+		}
+	}
+
+	return result, nil
+}
+
+// GetAssertion calls navigator.credentials.get with the options and returns the assertion. The ceremony is aborted
+// when the context is done. It blocks until the promise is settled so it must not be called from the goroutine of a
+// js.Func callback.
+func GetAssertion(ctx context.Context, options protocol.PublicKeyCredentialRequestOptions) (*protocol.CredentialAssertionResponse, error) {
+	credential, err := call(ctx, "get", options, requestBinaryPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	response := credential.Get("response")
+
+	return &protocol.CredentialAssertionResponse{
+		PublicKeyCredential: publicKeyCredential(credential),
+		AssertionResponse: protocol.AuthenticatorAssertionResponse{
+			AuthenticatorResponse: protocol.AuthenticatorResponse{ClientDataJSON: bytesOf(response.Get("clientDataJSON"))},
+			AuthenticatorData:     bytesOf(response.Get("authenticatorData")),
+			Signature:             bytesOf(response.Get("signature")),
+			UserHandle:            bytesOf(response.Get("userHandle")),
+		},
+	}, nil
+}
+
+// call calls the method of navigator.credentials with the options and waits for the credential.
+func call(ctx context.Context, method string, options interface{}, paths []string) (js.Value, error) {
+	navigator := js.Global().Get("navigator")
+	if navigator.IsUndefined() || navigator.IsNull() {
+		return js.Value{}, ErrNotSupported
+	}
+
+	credentials := navigator.Get("credentials")
+	if credentials.IsUndefined() || credentials.IsNull() {
+		return js.Value{}, ErrNotSupported
+	}
+
+	value, err := decodeOptions(options, paths)
+	if err != nil {
+		return js.Value{}, err
+	}
+
+	controller := js.Global().Get("AbortController").New()
+
+	promise := credentials.Call(method, map[string]interface{}{
+		"publicKey": toJS(value),
+		"signal":    controller.Get("signal"),
+	})
+
+	type result struct {
+		value js.Value
+		err   error
+	}
+
+	done := make(chan result, 1)
+
+	resolve := js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		done <- result{value: args[0]}
+
+		return nil
+	})
+
+	defer resolve.Release()
+
+	reject := js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		done <- result{err: &Error{Name: args[0].Get("name").String(), Message: args[0].Get("message").String()}}
+
+		return nil
+	})
+
+	defer reject.Release()
+
+	promise.Call("then", resolve, reject)
+
+	select {
+	case r := <-done:
+		if r.err == nil && r.value.IsNull() {
+			return js.Value{}, &Error{Name: "NotAllowedError", Message: "no credential was returned"}
+		}
+
+		return r.value, r.err
+	case <-ctx.Done():
+		controller.Call("abort")
+
+		// The promise is rejected by the abort, and the callbacks must not be released before they are called.
+		<-done
+
+		return js.Value{}, ctx.Err()
+	}
+}
+
+func publicKeyCredential(credential js.Value) protocol.PublicKeyCredential {
+	result := protocol.PublicKeyCredential{
+		Credential: protocol.Credential{ID: credential.Get("id").String(), Type: credential.Get("type").String()},
+		RawID:      bytesOf(credential.Get("rawId")),
+	}
+
+	if attachment := credential.Get("authenticatorAttachment"); attachment.Type() == js.TypeString {
+		result.AuthenticatorAttachment = attachment.String()
+	}
+
+	if credential.Get("getClientExtensionResults").Type() == js.TypeFunction {
+		data := js.Global().Get("JSON").Call("stringify", credential.Call("getClientExtensionResults")).String()
+
+		_ = json.Unmarshal([]byte(data), &result.ClientExtensionResults)
+	}
+
+	return result
+}
+
+// toJS converts the value returned by decodeOptions to a JavaScript value where the byte slices are Uint8Arrays.
+func toJS(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		array := js.Global().Get("Uint8Array").New(len(v))
+
+		js.CopyBytesToJS(array, v)
+
+		return array
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+
+		for key, item := range v {
+			result[key] = toJS(item)
+		}
+
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+
+		for i, item := range v {
+			result[i] = toJS(item)
+		}
+
+		return result
+	default:
+		return v
+	}
+}
+
+// bytesOf returns the bytes of an ArrayBuffer, or nil if the value is null or undefined.
+func bytesOf(value js.Value) []byte {
+	if value.IsUndefined() || value.IsNull() {
+		return nil
+	}
+
+	array := js.Global().Get("Uint8Array").New(value)
+
+	data := make([]byte, array.Length())
+
+	js.CopyBytesToGo(data, array)
+
+	return data
+}
@@ -0,0 +1,133 @@
+//go:build js && wasm
+
+package webauthnjs
+
+import (
+	"context"
+	"errors"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// fakeNavigator echoes the binary members of the options in the returned credentials to check their conversion.
+const fakeNavigator = `
+const buffer = (bytes) => bytes.buffer.slice(bytes.byteOffset, bytes.byteOffset + bytes.byteLength);
+
+return {
+	credentials: {
+		create: (options) => Promise.resolve({
+			id: "aWQ",
+			type: "public-key",
+			rawId: buffer(options.publicKey.user.id),
+			authenticatorAttachment: "platform",
+			getClientExtensionResults: () => ({credProps: {rk: true}}),
+			response: {
+				clientDataJSON: buffer(options.publicKey.challenge),
+				attestationObject: buffer(options.publicKey.excludeCredentials[0].id),
+				getTransports: () => ["usb", "nfc"],
+			},
+		}),
+		get: (options) => {
+			if (options.publicKey.rpId === "reject.example.com") {
+				return Promise.reject({name: "NotAllowedError", message: "The operation was cancelled."});
+			}
+
+			if (options.publicKey.rpId === "wait.example.com") {
+				return new Promise((resolve, reject) => {
+					options.signal.addEventListener("abort", () => reject({name: "AbortError", message: "aborted"}));
+				});
+			}
+
+			return Promise.resolve({
+				id: "aWQ",
+				type: "public-key",
+				rawId: buffer(options.publicKey.allowCredentials[0].id),
+				authenticatorAttachment: null,
+				getClientExtensionResults: () => ({}),
+				response: {
+					clientDataJSON: buffer(options.publicKey.challenge),
+					authenticatorData: new Uint8Array([1, 2, 3]).buffer,
+					signature: new Uint8Array([4, 5, 6]).buffer,
+					userHandle: null,
+				},
+			});
+		},
+	},
+};
+`
+
+func setupNavigator(t *testing.T) {
+	previous := js.Global().Get("navigator")
+
+	js.Global().Set("navigator", js.Global().Get("Function").New(fakeNavigator).Invoke())
+
+	t.Cleanup(func() {
+		js.Global().Set("navigator", previous)
+	})
+}
+
+func TestCreateCredential(t *testing.T) {
+	setupNavigator(t)
+
+	response, err := CreateCredential(context.Background(), protocol.PublicKeyCredentialCreationOptions{
+		User:                  protocol.UserEntity{ID: protocol.URLEncodedBase64("1234")},
+		Challenge:             protocol.URLEncodedBase64("challenge"),
+		CredentialExcludeList: []protocol.CredentialDescriptor{{Type: protocol.PublicKeyCredentialType, CredentialID: []byte("excluded")}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "aWQ", response.ID)
+	assert.Equal(t, "public-key", response.Type)
+	assert.Equal(t, "platform", response.AuthenticatorAttachment)
+	assert.Equal(t, protocol.URLEncodedBase64("1234"), response.RawID)
+	assert.Equal(t, protocol.URLEncodedBase64("challenge"), response.AttestationResponse.ClientDataJSON)
+	assert.Equal(t, protocol.URLEncodedBase64("excluded"), response.AttestationResponse.AttestationObject)
+	assert.Equal(t, []string{"usb", "nfc"}, response.AttestationResponse.Transports)
+	assert.Equal(t, map[string]interface{}{"rk": true}, response.ClientExtensionResults["credProps"])
+}
+
+func TestGetAssertion(t *testing.T) {
+	setupNavigator(t)
+
+	response, err := GetAssertion(context.Background(), protocol.PublicKeyCredentialRequestOptions{
+		Challenge:          protocol.URLEncodedBase64("challenge"),
+		AllowedCredentials: []protocol.CredentialDescriptor{{Type: protocol.PublicKeyCredentialType, CredentialID: []byte("allowed")}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, protocol.URLEncodedBase64("allowed"), response.RawID)
+	assert.Empty(t, response.AuthenticatorAttachment)
+	assert.Equal(t, protocol.URLEncodedBase64("challenge"), response.AssertionResponse.ClientDataJSON)
+	assert.Equal(t, protocol.URLEncodedBase64{1, 2, 3}, response.AssertionResponse.AuthenticatorData)
+	assert.Equal(t, protocol.URLEncodedBase64{4, 5, 6}, response.AssertionResponse.Signature)
+	assert.Nil(t, response.AssertionResponse.UserHandle)
+}
+
+func TestGetAssertionErrors(t *testing.T) {
+	setupNavigator(t)
+
+	_, err := GetAssertion(context.Background(), protocol.PublicKeyCredentialRequestOptions{RelyingPartyID: "reject.example.com"})
+
+	var e *Error
+
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "NotAllowedError", e.Name)
+	assert.Equal(t, "webauthnjs: NotAllowedError: The operation was cancelled.", err.Error())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = GetAssertion(ctx, protocol.PublicKeyCredentialRequestOptions{RelyingPartyID: "wait.example.com"})
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	js.Global().Set("navigator", js.Undefined())
+
+	_, err = GetAssertion(context.Background(), protocol.PublicKeyCredentialRequestOptions{})
+	assert.Equal(t, ErrNotSupported, err)
+}
@@ -0,0 +1,90 @@
+// Package webauthnjs calls navigator.credentials from frontends compiled to WebAssembly with GOOS=js and GOARCH=wasm.
+// The options returned by the ceremonies of the webauthn package are passed as is, and the results are returned as the
+// protocol types which are sent to the Relying Party:
+//
+//	response, err := webauthnjs.CreateCredential(ctx, creation.Response)
+//	if err != nil {
+//		return err
+//	}
+//
+//	body, err := json.Marshal(response)
+//
+// Only the helpers which convert the options are available on other platforms.
+package webauthnjs
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Paths of the binary members of the options which are ArrayBuffers in the browser API and base64url encoded strings
+// in the JSON encoding of the protocol types. A path ending with [] is a list of dictionaries.
+var (
+	creationBinaryPaths = []string{"challenge", "user.id", "excludeCredentials[].id"}
+	requestBinaryPaths  = []string{"challenge", "allowCredentials[].id"}
+)
+
+// decodeOptions returns the JSON encoding of the options as a generic value, with the binary members at the paths
+// decoded to byte slices.
+func decodeOptions(options interface{}, paths []string) (map[string]interface{}, error) {
+	data, err := json.Marshal(options)
+	if err != nil {
+		return nil, err
+	}
+
+	var value map[string]interface{}
+
+	if err = json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		if err = decodePath(value, strings.Split(path, ".")); err != nil {
+			return nil, fmt.Errorf("webauthnjs: error decoding %s: %w", path, err)
+		}
+	}
+
+	return value, nil
+}
+
+func decodePath(value map[string]interface{}, path []string) error {
+	key := path[0]
+
+	if name := strings.TrimSuffix(key, "[]"); name != key {
+		items, _ := value[name].([]interface{})
+
+		for _, item := range items {
+			if dictionary, ok := item.(map[string]interface{}); ok {
+				if err := decodePath(dictionary, path[1:]); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	if len(path) > 1 {
+		if dictionary, ok := value[key].(map[string]interface{}); ok {
+			return decodePath(dictionary, path[1:])
+		}
+
+		return nil
+	}
+
+	encoded, ok := value[key].(string)
+	if !ok {
+		return nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil {
+		return err
+	}
+
+	value[key] = decoded
+
+	return nil
+}
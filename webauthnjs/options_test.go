@@ -0,0 +1,51 @@
+package webauthnjs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+func TestDecodeOptions(t *testing.T) {
+	creation := protocol.PublicKeyCredentialCreationOptions{
+		RelyingParty: protocol.RelyingPartyEntity{ID: "example.com"},
+		User: protocol.UserEntity{
+			CredentialEntity: protocol.CredentialEntity{Name: "john"},
+			ID:               protocol.URLEncodedBase64("1234"),
+		},
+		Challenge: protocol.URLEncodedBase64("challenge"),
+		CredentialExcludeList: []protocol.CredentialDescriptor{
+			{Type: protocol.PublicKeyCredentialType, CredentialID: []byte("credential")},
+		},
+	}
+
+	value, err := decodeOptions(creation, creationBinaryPaths)
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte("challenge"), value["challenge"])
+	assert.Equal(t, []byte("1234"), value["user"].(map[string]interface{})["id"])
+	assert.Equal(t, "john", value["user"].(map[string]interface{})["name"])
+	assert.Equal(t, "example.com", value["rp"].(map[string]interface{})["id"])
+	assert.Equal(t, []byte("credential"), value["excludeCredentials"].([]interface{})[0].(map[string]interface{})["id"])
+
+	request := protocol.PublicKeyCredentialRequestOptions{
+		Challenge:      protocol.URLEncodedBase64("challenge"),
+		RelyingPartyID: "example.com",
+	}
+
+	value, err = decodeOptions(request, requestBinaryPaths)
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte("challenge"), value["challenge"])
+	assert.Equal(t, "example.com", value["rpId"])
+	assert.NotContains(t, value, "allowCredentials")
+}
+
+func TestDecodeOptionsError(t *testing.T) {
+	_, err := decodeOptions(map[string]interface{}{"challenge": "!"}, requestBinaryPaths)
+
+	assert.EqualError(t, err, "webauthnjs: error decoding challenge: illegal base64 data at input byte 0")
+}
@@ -0,0 +1,381 @@
+// Package webauthntest provides a virtual authenticator which creates valid attestation and assertion responses so that
+// applications can test their registration and login handlers without a browser or a physical authenticator.
+package webauthntest
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/webauthntest/ctap2"
+)
+
+// Attestation statement formats supported by the Authenticator.
+const (
+	FormatNone   = "none"
+	FormatPacked = "packed"
+	FormatU2F    = "fido-u2f"
+)
+
+var (
+	// ErrNoCredential is returned by GetAssertion when the Authenticator has no credential which can be used.
+	ErrNoCredential = errors.New("webauthntest: no credential available for the request")
+
+	// ErrUnsupportedAlgorithm is returned by CreateCredential when none of the requested algorithms are supported.
+	ErrUnsupportedAlgorithm = errors.New("webauthntest: unsupported algorithm")
+)
+
+// Authenticator is a virtual authenticator. The zero value is a usable authenticator which creates ES256 credentials
+// with the none attestation format. The configuration must not be changed while the Authenticator is in use, however
+// it is safe to create credentials and assertions from multiple goroutines.
+type Authenticator struct {
+	// AAGUID of the authenticator. It's ignored by the fido-u2f format which always uses the zero AAGUID.
+	AAGUID uuid.UUID
+
+	// Algorithm of the created credentials which must be ES256, RS256, or EdDSA. If it's zero the first supported
+	// algorithm of the credential parameters is used.
+	Algorithm webauthncose.COSEAlgorithmIdentifier
+
+	// Format of the attestation statement which must be one of FormatNone, FormatPacked, or FormatU2F. The default is
+	// FormatNone. The packed format uses self attestation.
+	Format string
+
+	// Flags of the authenticator data. The default is protocol.FlagUserPresent and protocol.FlagUserVerified. The
+	// protocol.FlagAttestedCredentialData flag is always set for attestations.
+	Flags protocol.AuthenticatorFlags
+
+	// Attachment is the authenticator attachment reported by the responses.
+	Attachment protocol.AuthenticatorAttachment
+
+	// TopOrigin is the top origin of the client data which is also marked as cross-origin when it's set, emulating a
+	// ceremony performed within a cross-origin iframe.
+	TopOrigin string
+
+	mu              sync.Mutex
+	credentials     []*Credential
+	attestationKey  *ecdsa.PrivateKey
+	attestationCert []byte
+}
+
+// Credential is a credential created by the Authenticator.
+type Credential = ctap2.Credential
+
+// Credentials returns the credentials created by the Authenticator.
+func (a *Authenticator) Credentials() []*Credential {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return append([]*Credential(nil), a.credentials...)
+}
+
+// CreateCredential performs the authenticatorMakeCredential operation for the options returned by
+// webauthn.BeginRegistration and returns the response the browser would send to the relying party.
+func (a *Authenticator) CreateCredential(options protocol.PublicKeyCredentialCreationOptions, origin string) (response *protocol.CredentialCreationResponse, credential *Credential, err error) {
+	alg, err := a.algorithm(options.Parameters)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if credential, err = ctap2.NewCredential(options.RelyingParty.ID, ctap2.UserHandle(options.User.ID), alg); err != nil {
+		return nil, nil, err
+	}
+
+	publicKey, err := credential.PublicKey()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	aaguid := a.AAGUID[:]
+
+	if a.format() == FormatU2F {
+		aaguid = make([]byte, 16)
+	}
+
+	clientDataJSON, err := a.clientData(protocol.CreateCeremony, options.Challenge, origin)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	authData := authenticatorData(credential.RPID, a.flags()|protocol.FlagAttestedCredentialData, 0)
+
+	authData = append(authData, aaguid...)
+	authData = append(authData, byte(len(credential.ID)>>8), byte(len(credential.ID)))
+	authData = append(authData, credential.ID...)
+	authData = append(authData, publicKey...)
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+
+	statement, err := a.attestationStatement(credential, authData, clientDataHash[:])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attestationObject, err := webauthncbor.Marshal(struct {
+		Format       string                 `cbor:"fmt"`
+		AttStatement map[string]interface{} `cbor:"attStmt"`
+		AuthData     []byte                 `cbor:"authData"`
+	}{a.format(), statement, authData})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	a.mu.Lock()
+	a.credentials = append(a.credentials, credential)
+	a.mu.Unlock()
+
+	return &protocol.CredentialCreationResponse{
+		PublicKeyCredential: a.publicKeyCredential(credential.ID),
+		AttestationResponse: protocol.AuthenticatorAttestationResponse{
+			AuthenticatorResponse: protocol.AuthenticatorResponse{ClientDataJSON: clientDataJSON},
+			AttestationObject:     attestationObject,
+		},
+	}, credential, nil
+}
+
+// GetAssertion performs the authenticatorGetAssertion operation for the options returned by webauthn.BeginLogin or
+// webauthn.BeginDiscoverableLogin and returns the response the browser would send to the relying party. The first
+// allowed credential is used, or if no credentials are allowed the first credential created for the relying party.
+func (a *Authenticator) GetAssertion(options protocol.PublicKeyCredentialRequestOptions, origin string) (*protocol.CredentialAssertionResponse, error) {
+	a.mu.Lock()
+
+	credential := a.findCredential(options)
+
+	if credential == nil {
+		a.mu.Unlock()
+
+		return nil, ErrNoCredential
+	}
+
+	credential.Counter++
+
+	authData := authenticatorData(credential.RPID, a.flags(), credential.Counter)
+
+	a.mu.Unlock()
+
+	clientDataJSON, err := a.clientData(protocol.AssertCeremony, options.Challenge, origin)
+	if err != nil {
+		return nil, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+
+	signature, err := credential.Sign(append(authData, clientDataHash[:]...))
+	if err != nil {
+		return nil, err
+	}
+
+	return &protocol.CredentialAssertionResponse{
+		PublicKeyCredential: a.publicKeyCredential(credential.ID),
+		AssertionResponse: protocol.AuthenticatorAssertionResponse{
+			AuthenticatorResponse: protocol.AuthenticatorResponse{ClientDataJSON: clientDataJSON},
+			AuthenticatorData:     authData,
+			Signature:             signature,
+			UserHandle:            credential.UserHandle,
+		},
+	}, nil
+}
+
+// NewRequest returns a *http.Request with the JSON encoded response as the body which can be passed to
+// webauthn.FinishRegistration or webauthn.FinishLogin.
+func NewRequest(response interface{}) (*http.Request, error) {
+	body, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+
+	r.Header.Set("Content-Type", "application/json")
+
+	return r, nil
+}
+
+func (a *Authenticator) findCredential(options protocol.PublicKeyCredentialRequestOptions) *Credential {
+	for _, credential := range a.credentials {
+		if options.RelyingPartyID != "" && credential.RPID != options.RelyingPartyID {
+			continue
+		}
+
+		if len(options.AllowedCredentials) == 0 {
+			return credential
+		}
+
+		for _, allowed := range options.AllowedCredentials {
+			if bytes.Equal(allowed.CredentialID, credential.ID) {
+				return credential
+			}
+		}
+	}
+
+	return nil
+}
+
+func (a *Authenticator) publicKeyCredential(id []byte) protocol.PublicKeyCredential {
+	return protocol.PublicKeyCredential{
+		Credential: protocol.Credential{
+			ID:   base64.RawURLEncoding.EncodeToString(id),
+			Type: string(protocol.PublicKeyCredentialType),
+		},
+		RawID:                   id,
+		AuthenticatorAttachment: string(a.Attachment),
+	}
+}
+
+func (a *Authenticator) format() string {
+	if a.Format == "" {
+		return FormatNone
+	}
+
+	return a.Format
+}
+
+func (a *Authenticator) flags() protocol.AuthenticatorFlags {
+	if a.Flags == 0 {
+		return protocol.FlagUserPresent | protocol.FlagUserVerified
+	}
+
+	return a.Flags
+}
+
+func (a *Authenticator) algorithm(parameters []protocol.CredentialParameter) (webauthncose.COSEAlgorithmIdentifier, error) {
+	if a.format() == FormatU2F && a.Algorithm != 0 && a.Algorithm != webauthncose.AlgES256 {
+		return 0, ErrUnsupportedAlgorithm
+	}
+
+	for _, parameter := range parameters {
+		if a.Algorithm != 0 && parameter.Algorithm != a.Algorithm {
+			continue
+		}
+
+		switch parameter.Algorithm {
+		case webauthncose.AlgES256:
+			return parameter.Algorithm, nil
+		case webauthncose.AlgRS256, webauthncose.AlgEdDSA:
+			if a.format() != FormatU2F {
+				return parameter.Algorithm, nil
+			}
+		}
+	}
+
+	return 0, ErrUnsupportedAlgorithm
+}
+
+func (a *Authenticator) attestationStatement(credential *Credential, authData, clientDataHash []byte) (map[string]interface{}, error) {
+	switch a.format() {
+	case FormatNone:
+		return map[string]interface{}{}, nil
+	case FormatPacked:
+		signature, err := credential.Sign(append(append([]byte(nil), authData...), clientDataHash...))
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]interface{}{
+			"alg": int64(credential.Algorithm),
+			"sig": signature,
+		}, nil
+	case FormatU2F:
+		if err := a.initAttestation(); err != nil {
+			return nil, err
+		}
+
+		key := credential.PrivateKey.Public().(*ecdsa.PublicKey)
+
+		data := []byte{0x00}
+
+		data = append(data, authData[:32]...)
+		data = append(data, clientDataHash...)
+		data = append(data, credential.ID...)
+		data = append(data, 0x04)
+		data = append(data, key.X.FillBytes(make([]byte, 32))...)
+		data = append(data, key.Y.FillBytes(make([]byte, 32))...)
+
+		digest := sha256.Sum256(data)
+
+		signature, err := ecdsa.SignASN1(rand.Reader, a.attestationKey, digest[:])
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]interface{}{
+			"sig": signature,
+			"x5c": []interface{}{a.attestationCert},
+		}, nil
+	default:
+		return nil, fmt.Errorf("webauthntest: unsupported attestation format '%s'", a.Format)
+	}
+}
+
+// initAttestation creates the self-signed attestation certificate used by the fido-u2f format.
+func (a *Authenticator) initAttestation() (err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.attestationKey != nil {
+		return nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "webauthntest U2F Attestation"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour * 24 * 365),
+	}
+
+	if a.attestationCert, err = x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key); err != nil {
+		return err
+	}
+
+	a.attestationKey = key
+
+	return nil
+}
+
+func authenticatorData(rpID string, flags protocol.AuthenticatorFlags, counter uint32) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+
+	data := make([]byte, 0, 128)
+
+	data = append(data, rpIDHash[:]...)
+	data = append(data, byte(flags))
+
+	data = append(data, 0, 0, 0, 0)
+
+	binary.BigEndian.PutUint32(data[len(data)-4:], counter)
+
+	return data
+}
+
+func (a *Authenticator) clientData(ceremony protocol.CeremonyType, challenge protocol.URLEncodedBase64, origin string) ([]byte, error) {
+	return json.Marshal(protocol.CollectedClientData{
+		Type:        ceremony,
+		Challenge:   challenge.String(),
+		Origin:      origin,
+		CrossOrigin: a.TopOrigin != "",
+		TopOrigin:   a.TopOrigin,
+	})
+}
@@ -0,0 +1,116 @@
+package webauthntest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+type testUser struct {
+	credentials []webauthn.Credential
+}
+
+func (u *testUser) WebAuthnID() []byte {
+	return []byte("1234")
+}
+
+func (u *testUser) WebAuthnName() string {
+	return "john"
+}
+
+func (u *testUser) WebAuthnDisplayName() string {
+	return "John"
+}
+
+func (u *testUser) WebAuthnIcon() string {
+	return ""
+}
+
+func (u *testUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+func TestAuthenticator(t *testing.T) {
+	testCases := []struct {
+		name      string
+		algorithm webauthncose.COSEAlgorithmIdentifier
+		format    string
+	}{
+		{"ShouldRegisterAndLoginES256None", webauthncose.AlgES256, FormatNone},
+		{"ShouldRegisterAndLoginRS256None", webauthncose.AlgRS256, FormatNone},
+		{"ShouldRegisterAndLoginEdDSANone", webauthncose.AlgEdDSA, FormatNone},
+		{"ShouldRegisterAndLoginES256Packed", webauthncose.AlgES256, FormatPacked},
+		{"ShouldRegisterAndLoginRS256Packed", webauthncose.AlgRS256, FormatPacked},
+		{"ShouldRegisterAndLoginEdDSAPacked", webauthncose.AlgEdDSA, FormatPacked},
+		{"ShouldRegisterAndLoginES256U2F", webauthncose.AlgES256, FormatU2F},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if webauthncose.FIPSMode && !webauthncose.IsFIPSApproved(tc.algorithm) {
+				t.Skip("the algorithm is not FIPS approved")
+			}
+
+			w, err := webauthn.New(&webauthn.Config{
+				RPID:          "example.com",
+				RPDisplayName: "Example",
+				RPOrigins:     []string{"https://example.com"},
+			})
+			require.NoError(t, err)
+
+			authenticator := &Authenticator{Algorithm: tc.algorithm, Format: tc.format}
+			user := &testUser{}
+
+			creation, session, err := w.BeginRegistration(user)
+			require.NoError(t, err)
+
+			attestation, created, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+			require.NoError(t, err)
+			assert.Equal(t, tc.algorithm, created.Algorithm)
+
+			r, err := NewRequest(attestation)
+			require.NoError(t, err)
+
+			credential, err := w.FinishRegistration(user, *session, r)
+			require.NoError(t, err)
+			assert.Equal(t, created.ID, credential.ID)
+			assert.Equal(t, tc.format, credential.AttestationType)
+
+			user.credentials = append(user.credentials, *credential)
+
+			for i := uint32(1); i <= 2; i++ {
+				assertion, session, err := w.BeginLogin(user)
+				require.NoError(t, err)
+
+				response, err := authenticator.GetAssertion(assertion.Response, "https://example.com")
+				require.NoError(t, err)
+
+				r, err := NewRequest(response)
+				require.NoError(t, err)
+
+				credential, err := w.FinishLogin(user, *session, r)
+				require.NoError(t, err)
+				assert.Equal(t, i, credential.Authenticator.SignCount)
+			}
+		})
+	}
+}
+
+func TestAuthenticatorErrors(t *testing.T) {
+	authenticator := &Authenticator{Format: FormatU2F}
+
+	_, _, err := authenticator.CreateCredential(protocol.PublicKeyCredentialCreationOptions{
+		Parameters: []protocol.CredentialParameter{
+			{Type: protocol.PublicKeyCredentialType, Algorithm: webauthncose.AlgRS256},
+		},
+	}, "https://example.com")
+	assert.Equal(t, ErrUnsupportedAlgorithm, err)
+
+	_, err = authenticator.GetAssertion(protocol.PublicKeyCredentialRequestOptions{RelyingPartyID: "example.com"}, "https://example.com")
+	assert.Equal(t, ErrNoCredential, err)
+}
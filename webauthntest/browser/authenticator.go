@@ -0,0 +1,110 @@
+package browser
+
+import (
+	"context"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// Protocols supported by the virtual authenticators.
+const (
+	ProtocolCTAP2 = "ctap2"
+	ProtocolU2F   = "u2f"
+)
+
+// VirtualAuthenticatorOptions are the options of a virtual authenticator which mirror the
+// WebAuthn.VirtualAuthenticatorOptions type of the DevTools protocol.
+type VirtualAuthenticatorOptions struct {
+	// Protocol is either ProtocolCTAP2 or ProtocolU2F. The default is ProtocolCTAP2.
+	Protocol string `json:"protocol"`
+
+	// CTAP2Version is the CTAP2 version such as "ctap2_0" or "ctap2_1" which is only used by ProtocolCTAP2.
+	CTAP2Version string `json:"ctap2Version,omitempty"`
+
+	// Transport of the authenticator. The default is protocol.Internal.
+	Transport protocol.AuthenticatorTransport `json:"transport"`
+
+	// HasResidentKey enables discoverable credentials.
+	HasResidentKey bool `json:"hasResidentKey"`
+
+	// HasUserVerification enables user verification.
+	HasUserVerification bool `json:"hasUserVerification"`
+
+	// HasLargeBlob enables the largeBlob extension.
+	HasLargeBlob bool `json:"hasLargeBlob,omitempty"`
+
+	// HasCredBlob enables the credBlob extension.
+	HasCredBlob bool `json:"hasCredBlob,omitempty"`
+
+	// HasMinPinLength enables the minPinLength extension.
+	HasMinPinLength bool `json:"hasMinPinLength,omitempty"`
+
+	// HasPRF enables the prf extension.
+	HasPRF bool `json:"hasPrf,omitempty"`
+
+	// AutomaticPresenceSimulation simulates the user touching the authenticator for every operation. The browser
+	// default is true.
+	AutomaticPresenceSimulation *bool `json:"automaticPresenceSimulation,omitempty"`
+
+	// IsUserVerified determines if the user verification succeeds.
+	IsUserVerified bool `json:"isUserVerified"`
+
+	// DefaultBackupEligibility is the backup eligible flag of the created credentials.
+	DefaultBackupEligibility bool `json:"defaultBackupEligibility,omitempty"`
+
+	// DefaultBackupState is the backup state flag of the created credentials.
+	DefaultBackupState bool `json:"defaultBackupState,omitempty"`
+}
+
+// VirtualCredential is a credential of a virtual authenticator which mirrors the WebAuthn.Credential type of the
+// DevTools protocol. The binary values are base64 encoded.
+type VirtualCredential struct {
+	CredentialID         string `json:"credentialId"`
+	IsResidentCredential bool   `json:"isResidentCredential"`
+	RPID                 string `json:"rpId,omitempty"`
+	PrivateKey           string `json:"privateKey"`
+	UserHandle           string `json:"userHandle,omitempty"`
+	SignCount            uint32 `json:"signCount"`
+	LargeBlob            string `json:"largeBlob,omitempty"`
+}
+
+// VirtualAuthenticator is a virtual authenticator added to a Page.
+type VirtualAuthenticator struct {
+	page *Page
+
+	// ID is the authenticator ID assigned by the browser.
+	ID string
+}
+
+// Credentials returns the credentials of the authenticator.
+func (a *VirtualAuthenticator) Credentials(ctx context.Context) ([]VirtualCredential, error) {
+	var result struct {
+		Credentials []VirtualCredential `json:"credentials"`
+	}
+
+	if err := a.page.call(ctx, "WebAuthn.getCredentials", map[string]interface{}{"authenticatorId": a.ID}, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Credentials, nil
+}
+
+// AddCredential adds the credential to the authenticator.
+func (a *VirtualAuthenticator) AddCredential(ctx context.Context, credential VirtualCredential) error {
+	return a.page.call(ctx, "WebAuthn.addCredential", map[string]interface{}{"authenticatorId": a.ID, "credential": credential}, nil)
+}
+
+// ClearCredentials removes every credential of the authenticator.
+func (a *VirtualAuthenticator) ClearCredentials(ctx context.Context) error {
+	return a.page.call(ctx, "WebAuthn.clearCredentials", map[string]interface{}{"authenticatorId": a.ID}, nil)
+}
+
+// SetUserVerified determines if the user verification of the following operations succeeds.
+func (a *VirtualAuthenticator) SetUserVerified(ctx context.Context, verified bool) error {
+	return a.page.call(ctx, "WebAuthn.setUserVerified", map[string]interface{}{"authenticatorId": a.ID, "isUserVerified": verified}, nil)
+}
+
+// Remove removes the authenticator from the page.
+func (a *VirtualAuthenticator) Remove(ctx context.Context) error {
+	return a.page.call(ctx, "WebAuthn.removeVirtualAuthenticator", map[string]interface{}{"authenticatorId": a.ID}, nil)
+}
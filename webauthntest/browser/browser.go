@@ -0,0 +1,242 @@
+// Package browser drives the virtual authenticator of a headless Chrome or Chromium via the WebAuthn domain of the
+// DevTools protocol, so that the registration and login ceremonies can be tested against a real browser in CI.
+//
+// The package only depends on the standard library and talks to the browser using a minimal DevTools protocol client.
+// Tests using it should skip when Launch returns ErrNotFound so they still pass on machines without a browser:
+//
+//	b, err := browser.Launch(ctx, nil)
+//	if errors.Is(err, browser.ErrNotFound) {
+//		t.Skip(err)
+//	}
+package browser
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// EnvExecPath is the environment variable which contains the path of the browser executable.
+const EnvExecPath = "CHROME_PATH"
+
+// ErrNotFound is returned by Launch when the browser executable could not be found.
+var ErrNotFound = errors.New("browser: executable not found")
+
+// execNames are the names of the browser executable which are looked up in the PATH in order.
+var execNames = []string{
+	"google-chrome",
+	"google-chrome-stable",
+	"chromium",
+	"chromium-browser",
+	"chrome",
+	"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome",
+}
+
+// Options configures the browser started by Launch.
+type Options struct {
+	// ExecPath is the path of the browser executable. The default is the value of the CHROME_PATH environment variable
+	// or otherwise the first well known executable name found in the PATH.
+	ExecPath string
+
+	// Args are additional command line arguments passed to the browser.
+	Args []string
+
+	// Output receives the logs the browser writes to the standard error which are useful for debugging. They're
+	// discarded by default.
+	Output io.Writer
+}
+
+// Browser is a headless browser process started by Launch.
+type Browser struct {
+	cmd  *exec.Cmd
+	dir  string
+	conn *conn
+	exit chan struct{}
+}
+
+// Launch starts a new headless browser with a temporary profile and connects to its DevTools endpoint. The context
+// only bounds the start of the browser, and the browser must be stopped with Close.
+func Launch(ctx context.Context, opts *Options) (browser *Browser, err error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	path, err := execPath(opts.ExecPath)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "webauthntest-browser-")
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{
+		"--headless=new",
+		"--remote-debugging-port=0",
+		"--user-data-dir=" + dir,
+		"--no-first-run",
+		"--no-default-browser-check",
+		"--disable-gpu",
+		"--disable-extensions",
+	}
+
+	// The sandbox can't be used by the root user which is common in CI containers.
+	if os.Geteuid() == 0 {
+		args = append(args, "--no-sandbox")
+	}
+
+	args = append(append(args, opts.Args...), "about:blank")
+
+	cmd := exec.Command(path, args...)
+
+	output := opts.Output
+	if output == nil {
+		output = io.Discard
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		_ = os.RemoveAll(dir)
+
+		return nil, err
+	}
+
+	if err = cmd.Start(); err != nil {
+		_ = os.RemoveAll(dir)
+
+		return nil, err
+	}
+
+	browser = &Browser{cmd: cmd, dir: dir, exit: make(chan struct{})}
+
+	defer func() {
+		if err != nil {
+			_ = browser.Close()
+		}
+	}()
+
+	found := make(chan string, 1)
+
+	go func() {
+		endpoint, _ := devToolsURL(io.TeeReader(stderr, output))
+
+		found <- endpoint
+
+		// The output must be drained so the browser does not block writing to it.
+		_, _ = io.Copy(output, stderr)
+
+		_ = cmd.Wait()
+
+		close(browser.exit)
+	}()
+
+	var endpoint string
+
+	select {
+	case endpoint = <-found:
+	case <-ctx.Done():
+		return browser, ctx.Err()
+	}
+
+	if endpoint == "" {
+		return browser, errors.New("browser: exited before the devtools endpoint was available")
+	}
+
+	ws, err := dialWebSocket(ctx, endpoint)
+	if err != nil {
+		return browser, fmt.Errorf("browser: error connecting to the devtools endpoint: %w", err)
+	}
+
+	browser.conn = newConn(ws)
+
+	return browser, nil
+}
+
+// execPath returns the path of the browser executable.
+func execPath(path string) (string, error) {
+	if path == "" {
+		path = os.Getenv(EnvExecPath)
+	}
+
+	if path != "" {
+		return exec.LookPath(path)
+	}
+
+	for _, name := range execNames {
+		if found, err := exec.LookPath(name); err == nil {
+			return found, nil
+		}
+	}
+
+	return "", ErrNotFound
+}
+
+// devToolsURL returns the WebSocket URL of the DevTools endpoint which is printed by the browser when it's listening.
+func devToolsURL(r io.Reader) (string, error) {
+	const prefix = "DevTools listening on "
+
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); strings.HasPrefix(line, prefix) {
+			return strings.TrimPrefix(line, prefix), nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return "", io.ErrUnexpectedEOF
+}
+
+// NewPage opens a new blank page.
+func (b *Browser) NewPage(ctx context.Context) (*Page, error) {
+	var target struct {
+		TargetID string `json:"targetId"`
+	}
+
+	if err := b.conn.Call(ctx, "", "Target.createTarget", map[string]interface{}{"url": "about:blank"}, &target); err != nil {
+		return nil, err
+	}
+
+	var session struct {
+		SessionID string `json:"sessionId"`
+	}
+
+	if err := b.conn.Call(ctx, "", "Target.attachToTarget", map[string]interface{}{"targetId": target.TargetID, "flatten": true}, &session); err != nil {
+		return nil, err
+	}
+
+	return &Page{conn: b.conn, targetID: target.TargetID, sessionID: session.SessionID}, nil
+}
+
+// Close stops the browser and removes its temporary profile.
+func (b *Browser) Close() error {
+	if b.conn != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+
+		_ = b.conn.Call(ctx, "", "Browser.close", nil, nil)
+
+		cancel()
+
+		_ = b.conn.Close()
+	}
+
+	select {
+	case <-b.exit:
+	case <-time.After(5 * time.Second):
+		_ = b.cmd.Process.Kill()
+
+		<-b.exit
+	}
+
+	return os.RemoveAll(b.dir)
+}
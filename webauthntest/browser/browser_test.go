@@ -0,0 +1,452 @@
+package browser
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// fakeDevTools is a DevTools endpoint which answers the commands with the handler.
+type fakeDevTools struct {
+	t       *testing.T
+	server  *httptest.Server
+	handler func(s *fakeSession, msg incoming)
+}
+
+// fakeSession is the server side of a WebSocket connection to the fakeDevTools. The frames written by the server are
+// not masked as required by RFC 6455.
+type fakeSession struct {
+	conn net.Conn
+	ws   *wsConn
+}
+
+func newFakeDevTools(t *testing.T, handler func(s *fakeSession, msg incoming)) *fakeDevTools {
+	f := &fakeDevTools{t: t, handler: handler}
+
+	f.server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+
+	t.Cleanup(f.server.Close)
+
+	return f
+}
+
+func (f *fakeDevTools) url() string {
+	return "ws" + strings.TrimPrefix(f.server.URL, "http") + "/devtools/browser/fake"
+}
+
+func (f *fakeDevTools) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Upgrade") != "websocket" || r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	conn, rw, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		return
+	}
+
+	defer conn.Close()
+
+	_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+	_ = rw.Flush()
+
+	s := &fakeSession{conn: conn, ws: &wsConn{conn: conn, reader: rw.Reader}}
+
+	for {
+		fin, opcode, payload, err := s.ws.readFrame()
+		if err != nil {
+			return
+		}
+
+		switch {
+		case opcode == opClose:
+			return
+		case opcode == opPong:
+			s.write(opText, true, []byte(`{"method":"Test.pong","params":{"data":"`+string(payload)+`"}}`))
+
+			continue
+		case !fin || opcode != opText:
+			f.t.Errorf("unexpected frame from the client: fin %t opcode %d", fin, opcode)
+
+			return
+		}
+
+		var msg incoming
+
+		if err = json.Unmarshal(payload, &msg); err != nil {
+			f.t.Errorf("invalid message from the client: %v", err)
+
+			return
+		}
+
+		f.handler(s, msg)
+	}
+}
+
+func (s *fakeSession) write(opcode byte, fin bool, payload []byte) {
+	header := []byte{opcode, 0}
+
+	if fin {
+		header[0] |= 0x80
+	}
+
+	switch n := len(payload); {
+	case n < 126:
+		header[1] = byte(n)
+	case n <= 0xffff:
+		header[1] = 126
+		header = append(header, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(n))
+	default:
+		header[1] = 127
+		header = append(header, make([]byte, 8)...)
+		binary.BigEndian.PutUint64(header[2:], uint64(n))
+	}
+
+	_, _ = s.conn.Write(append(header, payload...))
+}
+
+func (s *fakeSession) reply(msg incoming, result interface{}) {
+	data, _ := json.Marshal(map[string]interface{}{"id": msg.ID, "sessionId": msg.SessionID, "result": result})
+
+	s.write(opText, true, data)
+}
+
+func (s *fakeSession) event(sessionID, method string, params interface{}) {
+	data, _ := json.Marshal(map[string]interface{}{"sessionId": sessionID, "method": method, "params": params})
+
+	s.write(opText, true, data)
+}
+
+func dialFake(t *testing.T, f *fakeDevTools) *conn {
+	ws, err := dialWebSocket(context.Background(), f.url())
+	require.NoError(t, err)
+
+	c := newConn(ws)
+
+	t.Cleanup(func() { _ = c.Close() })
+
+	return c
+}
+
+func TestConnCall(t *testing.T) {
+	large := strings.Repeat("a", 70000)
+
+	f := newFakeDevTools(t, func(s *fakeSession, msg incoming) {
+		switch msg.Method {
+		case "Test.echo":
+			s.reply(msg, map[string]json.RawMessage{"echo": msg.Params})
+		case "Test.fragmented":
+			data, _ := json.Marshal(map[string]interface{}{"id": msg.ID, "result": map[string]string{"value": "fragmented"}})
+
+			s.write(opText, false, data[:10])
+			s.write(opPing, true, []byte("ping"))
+			s.write(opContinuation, true, data[10:])
+		case "Test.error":
+			data, _ := json.Marshal(map[string]interface{}{"id": msg.ID, "error": Error{Code: -32000, Message: "Not allowed", Data: "details"}})
+
+			s.write(opText, true, data)
+		case "Test.close":
+			s.write(opClose, true, nil)
+		}
+	})
+
+	c := dialFake(t, f)
+
+	ctx := context.Background()
+
+	for _, value := range []string{"small", strings.Repeat("b", 300), large} {
+		var result struct {
+			Echo struct {
+				Value string `json:"value"`
+			} `json:"echo"`
+		}
+
+		require.NoError(t, c.Call(ctx, "", "Test.echo", map[string]string{"value": value}, &result))
+		assert.Equal(t, value, result.Echo.Value)
+	}
+
+	pong := c.Subscribe("", "Test.pong")
+	defer c.Unsubscribe(pong)
+
+	var fragmented struct {
+		Value string `json:"value"`
+	}
+
+	require.NoError(t, c.Call(ctx, "", "Test.fragmented", nil, &fragmented))
+	assert.Equal(t, "fragmented", fragmented.Value)
+
+	params, err := c.Wait(ctx, pong)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"data":"ping"}`, string(params))
+
+	err = c.Call(ctx, "", "Test.error", nil, nil)
+	assert.EqualError(t, err, "browser: Not allowed (-32000): details")
+
+	var e *Error
+
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, -32000, e.Code)
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, c.Call(timeout, "", "Test.unanswered", nil, nil), context.DeadlineExceeded)
+
+	assert.ErrorIs(t, c.Call(ctx, "", "Test.close", nil, nil), ErrClosed)
+	assert.ErrorIs(t, c.Call(ctx, "", "Test.echo", nil, nil), ErrClosed)
+}
+
+func TestPage(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		methods []string
+	)
+
+	f := newFakeDevTools(t, func(s *fakeSession, msg incoming) {
+		mu.Lock()
+		methods = append(methods, msg.Method)
+		mu.Unlock()
+
+		switch msg.Method {
+		case "Target.createTarget":
+			s.reply(msg, map[string]string{"targetId": "target"})
+		case "Target.attachToTarget":
+			assert.JSONEq(t, `{"targetId":"target","flatten":true}`, string(msg.Params))
+
+			s.reply(msg, map[string]string{"sessionId": "session"})
+		case "Page.navigate":
+			assert.Equal(t, "session", msg.SessionID)
+
+			s.reply(msg, map[string]string{"frameId": "frame"})
+			s.event("other", "Page.loadEventFired", nil)
+			s.event("session", "Page.loadEventFired", map[string]float64{"timestamp": 1})
+		case "Runtime.evaluate":
+			var params struct {
+				Expression string `json:"expression"`
+			}
+
+			assert.NoError(t, json.Unmarshal(msg.Params, &params))
+
+			switch {
+			case params.Expression == "1 + 1":
+				s.reply(msg, map[string]interface{}{"result": map[string]interface{}{"type": "number", "value": 2}})
+			case strings.Contains(params.Expression, `"get"`):
+				s.reply(msg, map[string]interface{}{"result": map[string]interface{}{"type": "string", "value": `{"id":"abc"}`}})
+			default:
+				s.reply(msg, map[string]interface{}{
+					"result":           map[string]string{"type": "object"},
+					"exceptionDetails": map[string]interface{}{"text": "Uncaught", "exception": map[string]string{"description": "NotAllowedError: denied"}},
+				})
+			}
+		case "WebAuthn.addVirtualAuthenticator":
+			assert.JSONEq(t, `{"options":{"protocol":"ctap2","transport":"internal","hasResidentKey":true,"hasUserVerification":true,"isUserVerified":true}}`, string(msg.Params))
+
+			s.reply(msg, map[string]string{"authenticatorId": "authenticator"})
+		case "WebAuthn.getCredentials":
+			assert.JSONEq(t, `{"authenticatorId":"authenticator"}`, string(msg.Params))
+
+			s.reply(msg, map[string]interface{}{"credentials": []VirtualCredential{{CredentialID: "YWJj", IsResidentCredential: true, SignCount: 1}}})
+		default:
+			s.reply(msg, struct{}{})
+		}
+	})
+
+	b := &Browser{conn: dialFake(t, f)}
+
+	ctx := context.Background()
+
+	page, err := b.NewPage(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, page.Navigate(ctx, "http://localhost/"))
+
+	var sum int
+
+	require.NoError(t, page.Evaluate(ctx, "1 + 1", &sum))
+	assert.Equal(t, 2, sum)
+
+	assert.EqualError(t, page.Evaluate(ctx, "throw", nil), "browser: NotAllowedError: denied")
+
+	authenticator, err := page.AddVirtualAuthenticator(ctx, VirtualAuthenticatorOptions{
+		HasResidentKey:      true,
+		HasUserVerification: true,
+		IsUserVerified:      true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "authenticator", authenticator.ID)
+
+	credentials, err := authenticator.Credentials(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []VirtualCredential{{CredentialID: "YWJj", IsResidentCredential: true, SignCount: 1}}, credentials)
+
+	response, err := page.GetAssertion(ctx, &protocol.CredentialAssertion{})
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":"abc"}`, string(response))
+
+	_, err = page.CreateCredential(ctx, &protocol.CredentialCreation{})
+	assert.EqualError(t, err, "browser: NotAllowedError: denied")
+
+	require.NoError(t, authenticator.Remove(ctx))
+	require.NoError(t, page.Close(ctx))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []string{
+		"Target.createTarget",
+		"Target.attachToTarget",
+		"Page.enable",
+		"Page.navigate",
+		"Runtime.evaluate",
+		"Runtime.evaluate",
+		"WebAuthn.enable",
+		"WebAuthn.addVirtualAuthenticator",
+		"WebAuthn.getCredentials",
+		"Runtime.evaluate",
+		"Runtime.evaluate",
+		"WebAuthn.removeVirtualAuthenticator",
+		"Target.closeTarget",
+	}, methods)
+}
+
+func TestDevToolsURL(t *testing.T) {
+	endpoint, err := devToolsURL(strings.NewReader("[0101/000000.000000:WARNING:fake.cc(1)] warning\n\nDevTools listening on ws://127.0.0.1:9222/devtools/browser/abc\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "ws://127.0.0.1:9222/devtools/browser/abc", endpoint)
+
+	_, err = devToolsURL(strings.NewReader("error while loading shared libraries\n"))
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestLaunchNotFound(t *testing.T) {
+	t.Setenv(EnvExecPath, "")
+	t.Setenv("PATH", t.TempDir())
+
+	_, err := Launch(context.Background(), nil)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+// TestChrome performs a registration and a login against a headless Chrome. It's skipped when Chrome is not installed.
+func TestChrome(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the browser test in short mode")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	b, err := Launch(ctx, nil)
+	if errors.Is(err, ErrNotFound) {
+		t.Skip(err)
+	}
+
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = b.Close() })
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	server := &httptest.Server{Listener: listener, Config: &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "<!DOCTYPE html><title>webauthn</title>")
+	})}}
+
+	server.Start()
+
+	t.Cleanup(server.Close)
+
+	origin := "http://localhost:" + strings.TrimPrefix(server.URL, "http://127.0.0.1:")
+
+	w, err := webauthn.New(&webauthn.Config{
+		RPDisplayName: "Example",
+		RPID:          "localhost",
+		RPOrigins:     []string{origin},
+	})
+	require.NoError(t, err)
+
+	page, err := b.NewPage(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, page.Navigate(ctx, origin+"/"))
+
+	authenticator, err := page.AddVirtualAuthenticator(ctx, VirtualAuthenticatorOptions{
+		HasResidentKey:      true,
+		HasUserVerification: true,
+		IsUserVerified:      true,
+	})
+	require.NoError(t, err)
+
+	user := &testUser{id: []byte("1234"), name: "john"}
+
+	creation, session, err := w.BeginRegistration(user, webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired))
+	require.NoError(t, err)
+
+	response, err := page.CreateCredential(ctx, creation)
+	require.NoError(t, err)
+
+	credential, err := w.FinishRegistration(user, *session, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(response))))
+	require.NoError(t, err)
+
+	user.credentials = append(user.credentials, *credential)
+
+	credentials, err := authenticator.Credentials(ctx)
+	require.NoError(t, err)
+	require.Len(t, credentials, 1)
+	assert.True(t, credentials[0].IsResidentCredential)
+
+	assertion, session, err := w.BeginDiscoverableLogin()
+	require.NoError(t, err)
+
+	response, err = page.GetAssertion(ctx, assertion)
+	require.NoError(t, err)
+
+	credential, err = w.FinishDiscoverableLogin(func(_, userHandle []byte) (webauthn.User, error) {
+		return user, nil
+	}, *session, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(response))))
+	require.NoError(t, err)
+	assert.True(t, credential.Flags.UserVerified)
+}
+
+type testUser struct {
+	id          []byte
+	name        string
+	credentials []webauthn.Credential
+}
+
+func (u *testUser) WebAuthnID() []byte {
+	return u.id
+}
+
+func (u *testUser) WebAuthnName() string {
+	return u.name
+}
+
+func (u *testUser) WebAuthnDisplayName() string {
+	return u.name
+}
+
+func (u *testUser) WebAuthnIcon() string {
+	return ""
+}
+
+func (u *testUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
@@ -0,0 +1,243 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrClosed is returned by the commands sent after the connection to the browser was closed.
+var ErrClosed = errors.New("browser: connection closed")
+
+// Error is an error returned by the browser in response to a DevTools protocol command.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.Data != "" {
+		return fmt.Sprintf("browser: %s (%d): %s", e.Message, e.Code, e.Data)
+	}
+
+	return fmt.Sprintf("browser: %s (%d)", e.Message, e.Code)
+}
+
+// message is a DevTools protocol command, response, or event.
+type message struct {
+	ID        int64           `json:"id,omitempty"`
+	SessionID string          `json:"sessionId,omitempty"`
+	Method    string          `json:"method,omitempty"`
+	Params    interface{}     `json:"params,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     *Error          `json:"error,omitempty"`
+}
+
+// incoming is a message received from the browser. The params are kept raw so events can be decoded by the
+// subscriber.
+type incoming struct {
+	ID        int64           `json:"id"`
+	SessionID string          `json:"sessionId"`
+	Method    string          `json:"method"`
+	Params    json.RawMessage `json:"params"`
+	Result    json.RawMessage `json:"result"`
+	Error     *Error          `json:"error"`
+}
+
+type subscription struct {
+	sessionID string
+	method    string
+	events    chan json.RawMessage
+}
+
+// conn is a connection to the DevTools protocol endpoint of the browser which multiplexes the commands of the browser
+// and of the attached page sessions.
+type conn struct {
+	ws *wsConn
+
+	mu            sync.Mutex
+	nextID        int64
+	pending       map[int64]chan *incoming
+	subscriptions map[*subscription]struct{}
+	err           error
+
+	done chan struct{}
+}
+
+func newConn(ws *wsConn) *conn {
+	c := &conn{
+		ws:            ws,
+		pending:       map[int64]chan *incoming{},
+		subscriptions: map[*subscription]struct{}{},
+		done:          make(chan struct{}),
+	}
+
+	go c.read()
+
+	return c
+}
+
+// read dispatches the messages received from the browser until the connection is closed.
+func (c *conn) read() {
+	var err error
+
+	for {
+		var data []byte
+
+		if data, err = c.ws.ReadMessage(); err != nil {
+			break
+		}
+
+		var msg incoming
+
+		if err = json.Unmarshal(data, &msg); err != nil {
+			break
+		}
+
+		c.dispatch(&msg)
+	}
+
+	c.mu.Lock()
+	c.err = fmt.Errorf("%w: %v", ErrClosed, err)
+	c.pending = map[int64]chan *incoming{}
+	c.mu.Unlock()
+
+	close(c.done)
+}
+
+func (c *conn) dispatch(msg *incoming) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if msg.ID != 0 {
+		if ch, ok := c.pending[msg.ID]; ok {
+			delete(c.pending, msg.ID)
+
+			ch <- msg
+		}
+
+		return
+	}
+
+	for s := range c.subscriptions {
+		if s.sessionID != msg.SessionID || s.method != msg.Method {
+			continue
+		}
+
+		select {
+		case s.events <- msg.Params:
+		default:
+		}
+	}
+}
+
+// Call sends the command to the browser, or to the page session if the sessionID is not empty, and decodes the result
+// into v unless it's nil.
+func (c *conn) Call(ctx context.Context, sessionID, method string, params, v interface{}) error {
+	ch := make(chan *incoming, 1)
+
+	c.mu.Lock()
+
+	if c.err != nil {
+		err := c.err
+
+		c.mu.Unlock()
+
+		return err
+	}
+
+	c.nextID++
+
+	id := c.nextID
+
+	c.pending[id] = ch
+
+	c.mu.Unlock()
+
+	data, err := json.Marshal(message{ID: id, SessionID: sessionID, Method: method, Params: params})
+	if err != nil {
+		c.forget(id)
+
+		return err
+	}
+
+	if err = c.ws.WriteMessage(data); err != nil {
+		c.forget(id)
+
+		return err
+	}
+
+	select {
+	case msg := <-ch:
+		if msg.Error != nil {
+			return msg.Error
+		}
+
+		if v == nil {
+			return nil
+		}
+
+		return json.Unmarshal(msg.Result, v)
+	case <-c.done:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		return c.err
+	case <-ctx.Done():
+		c.forget(id)
+
+		return ctx.Err()
+	}
+}
+
+func (c *conn) forget(id int64) {
+	c.mu.Lock()
+	delete(c.pending, id)
+	c.mu.Unlock()
+}
+
+// Subscribe returns the subscription for the events with the method of the session. The subscription must be removed
+// with Unsubscribe once the events are no longer needed.
+func (c *conn) Subscribe(sessionID, method string) *subscription {
+	s := &subscription{sessionID: sessionID, method: method, events: make(chan json.RawMessage, 16)}
+
+	c.mu.Lock()
+	c.subscriptions[s] = struct{}{}
+	c.mu.Unlock()
+
+	return s
+}
+
+// Unsubscribe removes the subscription.
+func (c *conn) Unsubscribe(s *subscription) {
+	c.mu.Lock()
+	delete(c.subscriptions, s)
+	c.mu.Unlock()
+}
+
+// Wait returns the params of the next event of the subscription.
+func (c *conn) Wait(ctx context.Context, s *subscription) (json.RawMessage, error) {
+	select {
+	case params := <-s.events:
+		return params, nil
+	case <-c.done:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		return nil, c.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close closes the connection.
+func (c *conn) Close() error {
+	err := c.ws.Close()
+
+	<-c.done
+
+	return err
+}
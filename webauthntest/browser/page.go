@@ -0,0 +1,201 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// Page is a page of the browser which is used to perform the ceremonies.
+type Page struct {
+	conn      *conn
+	targetID  string
+	sessionID string
+}
+
+// Navigate navigates the page to the URL and waits for it to load. The origin of the URL is the origin of the
+// ceremonies performed by the page, and it must be a secure context such as https or http://localhost.
+func (p *Page) Navigate(ctx context.Context, url string) error {
+	if err := p.call(ctx, "Page.enable", nil, nil); err != nil {
+		return err
+	}
+
+	loaded := p.conn.Subscribe(p.sessionID, "Page.loadEventFired")
+	defer p.conn.Unsubscribe(loaded)
+
+	var result struct {
+		ErrorText string `json:"errorText"`
+	}
+
+	if err := p.call(ctx, "Page.navigate", map[string]interface{}{"url": url}, &result); err != nil {
+		return err
+	}
+
+	if result.ErrorText != "" {
+		return fmt.Errorf("browser: error navigating to '%s': %s", url, result.ErrorText)
+	}
+
+	_, err := p.conn.Wait(ctx, loaded)
+
+	return err
+}
+
+// Evaluate evaluates the JavaScript expression in the page, awaiting it if it's a promise, and decodes its JSON
+// serializable result into v unless it's nil.
+func (p *Page) Evaluate(ctx context.Context, expression string, v interface{}) error {
+	var result struct {
+		Result struct {
+			Value json.RawMessage `json:"value"`
+		} `json:"result"`
+		ExceptionDetails *struct {
+			Text      string `json:"text"`
+			Exception struct {
+				Description string `json:"description"`
+			} `json:"exception"`
+		} `json:"exceptionDetails"`
+	}
+
+	params := map[string]interface{}{
+		"expression":    expression,
+		"awaitPromise":  true,
+		"returnByValue": true,
+	}
+
+	if err := p.call(ctx, "Runtime.evaluate", params, &result); err != nil {
+		return err
+	}
+
+	if details := result.ExceptionDetails; details != nil {
+		if details.Exception.Description != "" {
+			return fmt.Errorf("browser: %s", details.Exception.Description)
+		}
+
+		return fmt.Errorf("browser: %s", details.Text)
+	}
+
+	if v == nil || len(result.Result.Value) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(result.Result.Value, v)
+}
+
+// CreateCredential calls navigator.credentials.create in the page with the options returned by BeginRegistration. It
+// returns the JSON encoded credential which is the body of the request expected by FinishRegistration.
+func (p *Page) CreateCredential(ctx context.Context, options *protocol.CredentialCreation) ([]byte, error) {
+	return p.ceremony(ctx, "create", options)
+}
+
+// GetAssertion calls navigator.credentials.get in the page with the options returned by BeginLogin or
+// BeginDiscoverableLogin. It returns the JSON encoded credential which is the body of the request expected by
+// FinishLogin and FinishDiscoverableLogin.
+func (p *Page) GetAssertion(ctx context.Context, options *protocol.CredentialAssertion) ([]byte, error) {
+	return p.ceremony(ctx, "get", options)
+}
+
+func (p *Page) ceremony(ctx context.Context, method string, options interface{}) ([]byte, error) {
+	data, err := json.Marshal(options)
+	if err != nil {
+		return nil, err
+	}
+
+	var response string
+
+	if err = p.Evaluate(ctx, fmt.Sprintf("(%s)(%q, %s)", ceremonyScript, method, data), &response); err != nil {
+		return nil, err
+	}
+
+	return []byte(response), nil
+}
+
+// AddVirtualAuthenticator enables the WebAuthn domain for the page and adds a virtual authenticator to it. The
+// navigator.credentials calls of the page only use the virtual authenticators once the domain is enabled.
+func (p *Page) AddVirtualAuthenticator(ctx context.Context, opts VirtualAuthenticatorOptions) (*VirtualAuthenticator, error) {
+	if opts.Protocol == "" {
+		opts.Protocol = ProtocolCTAP2
+	}
+
+	if opts.Transport == "" {
+		opts.Transport = protocol.Internal
+	}
+
+	if err := p.call(ctx, "WebAuthn.enable", nil, nil); err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		AuthenticatorID string `json:"authenticatorId"`
+	}
+
+	if err := p.call(ctx, "WebAuthn.addVirtualAuthenticator", map[string]interface{}{"options": opts}, &result); err != nil {
+		return nil, err
+	}
+
+	return &VirtualAuthenticator{page: p, ID: result.AuthenticatorID}, nil
+}
+
+// Close closes the page.
+func (p *Page) Close(ctx context.Context) error {
+	err := p.conn.Call(ctx, "", "Target.closeTarget", map[string]interface{}{"targetId": p.targetID}, nil)
+
+	if errors.Is(err, ErrClosed) {
+		return nil
+	}
+
+	return err
+}
+
+func (p *Page) call(ctx context.Context, method string, params, v interface{}) error {
+	return p.conn.Call(ctx, p.sessionID, method, params, v)
+}
+
+// ceremonyScript calls navigator.credentials with the JSON encoded options, decoding the base64url encoded binary
+// values of the options and encoding the binary values of the returned credential.
+const ceremonyScript = `async (method, options) => {
+  const decode = (value) => {
+    const base64 = value.replace(/-/g, '+').replace(/_/g, '/');
+    const padded = base64 + '='.repeat((4 - (base64.length % 4)) % 4);
+
+    return Uint8Array.from(atob(padded), (c) => c.charCodeAt(0)).buffer;
+  };
+
+  const encode = (buffer) => btoa(String.fromCharCode(...new Uint8Array(buffer)))
+    .replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '');
+
+  const descriptors = (values) => (values || []).map((value) => ({ ...value, id: decode(value.id) }));
+
+  const publicKey = options.publicKey;
+
+  publicKey.challenge = decode(publicKey.challenge);
+
+  if (method === 'create') {
+    publicKey.user.id = decode(publicKey.user.id);
+    publicKey.excludeCredentials = descriptors(publicKey.excludeCredentials);
+  } else {
+    publicKey.allowCredentials = descriptors(publicKey.allowCredentials);
+  }
+
+  const credential = await navigator.credentials[method]({ publicKey });
+  const response = { clientDataJSON: encode(credential.response.clientDataJSON) };
+
+  if (method === 'create') {
+    response.attestationObject = encode(credential.response.attestationObject);
+    response.transports = credential.response.getTransports ? credential.response.getTransports() : undefined;
+  } else {
+    response.authenticatorData = encode(credential.response.authenticatorData);
+    response.signature = encode(credential.response.signature);
+    response.userHandle = credential.response.userHandle ? encode(credential.response.userHandle) : undefined;
+  }
+
+  return JSON.stringify({
+    id: credential.id,
+    rawId: encode(credential.rawId),
+    type: credential.type,
+    authenticatorAttachment: credential.authenticatorAttachment || undefined,
+    clientExtensionResults: credential.getClientExtensionResults(),
+    response,
+  });
+}`
@@ -0,0 +1,281 @@
+package browser
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// The WebSocket opcodes which are used by the DevTools protocol.
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+)
+
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// maxMessageSize is the maximum size of a message received from the browser.
+const maxMessageSize = 64 << 20
+
+// wsConn is a minimal client implementation of the WebSocket protocol (RFC 6455) which is just enough to talk to the
+// DevTools endpoint of the browser. It avoids a dependency on a WebSocket library for a test helper.
+type wsConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+
+	mu sync.Mutex
+}
+
+func dialWebSocket(ctx context.Context, rawURL string) (*wsConn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme != "ws" {
+		return nil, fmt.Errorf("unsupported websocket scheme '%s'", u.Scheme)
+	}
+
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "tcp", u.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	raw := make([]byte, 16)
+
+	if _, err = rand.Read(raw); err != nil {
+		_ = conn.Close()
+
+		return nil, err
+	}
+
+	key := base64.StdEncoding.EncodeToString(raw)
+
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        u,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header: http.Header{
+			"Upgrade":               {"websocket"},
+			"Connection":            {"Upgrade"},
+			"Sec-WebSocket-Key":     {key},
+			"Sec-WebSocket-Version": {"13"},
+		},
+		Host: u.Host,
+	}
+
+	if err = req.Write(conn); err != nil {
+		_ = conn.Close()
+
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+
+	res, err := http.ReadResponse(reader, req)
+	if err != nil {
+		_ = conn.Close()
+
+		return nil, err
+	}
+
+	_ = res.Body.Close()
+
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		_ = conn.Close()
+
+		return nil, fmt.Errorf("unexpected websocket handshake status '%s'", res.Status)
+	}
+
+	if res.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		_ = conn.Close()
+
+		return nil, errors.New("invalid websocket handshake accept key")
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+
+	return &wsConn{conn: conn, reader: reader}, nil
+}
+
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + websocketGUID))
+
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// WriteMessage writes a single masked text frame.
+func (c *wsConn) WriteMessage(data []byte) error {
+	return c.writeFrame(opText, data)
+}
+
+func (c *wsConn) writeFrame(opcode byte, data []byte) error {
+	header := make([]byte, 2, 14)
+
+	header[0] = 0x80 | opcode
+
+	switch n := len(data); {
+	case n < 126:
+		header[1] = 0x80 | byte(n)
+	case n <= 0xffff:
+		header[1] = 0x80 | 126
+		header = header[:4]
+		binary.BigEndian.PutUint16(header[2:], uint16(n))
+	default:
+		header[1] = 0x80 | 127
+		header = header[:10]
+		binary.BigEndian.PutUint64(header[2:], uint64(n))
+	}
+
+	mask := make([]byte, 4)
+
+	if _, err := rand.Read(mask); err != nil {
+		return err
+	}
+
+	header = append(header, mask...)
+
+	payload := make([]byte, len(data))
+
+	for i := range data {
+		payload[i] = data[i] ^ mask[i%4]
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.conn.Write(header); err != nil {
+		return err
+	}
+
+	_, err := c.conn.Write(payload)
+
+	return err
+}
+
+// ReadMessage reads the next text or binary message, reassembling fragmented messages and answering pings. It returns
+// io.EOF when the connection was closed by the browser.
+func (c *wsConn) ReadMessage() ([]byte, error) {
+	var message []byte
+
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+
+		switch opcode {
+		case opPing:
+			if err = c.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+
+			continue
+		case opPong:
+			continue
+		case opClose:
+			_ = c.writeFrame(opClose, nil)
+
+			return nil, io.EOF
+		case opText, opBinary, opContinuation:
+			if len(message)+len(payload) > maxMessageSize {
+				return nil, errors.New("websocket message too large")
+			}
+
+			message = append(message, payload...)
+		default:
+			return nil, fmt.Errorf("unsupported websocket opcode %d", opcode)
+		}
+
+		if fin {
+			return message, nil
+		}
+	}
+}
+
+func (c *wsConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	header := make([]byte, 2)
+
+	if _, err = io.ReadFull(c.reader, header); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin, opcode = header[0]&0x80 != 0, header[0]&0x0f
+
+	masked, length := header[1]&0x80 != 0, uint64(header[1]&0x7f)
+
+	switch length {
+	case 126:
+		extended := make([]byte, 2)
+
+		if _, err = io.ReadFull(c.reader, extended); err != nil {
+			return false, 0, nil, err
+		}
+
+		length = uint64(binary.BigEndian.Uint16(extended))
+	case 127:
+		extended := make([]byte, 8)
+
+		if _, err = io.ReadFull(c.reader, extended); err != nil {
+			return false, 0, nil, err
+		}
+
+		length = binary.BigEndian.Uint64(extended)
+	}
+
+	if length > maxMessageSize {
+		return false, 0, nil, errors.New("websocket frame too large")
+	}
+
+	var mask []byte
+
+	if masked {
+		mask = make([]byte, 4)
+
+		if _, err = io.ReadFull(c.reader, mask); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	payload = make([]byte, length)
+
+	if _, err = io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+
+	return fin, opcode, payload, nil
+}
+
+// Close closes the underlying connection.
+func (c *wsConn) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,444 @@
+// Package ctap2 provides a software authenticator which implements the authenticatorMakeCredential and
+// authenticatorGetAssertion operations of CTAP2 over in-memory keys. Unlike the webauthntest.Authenticator, which also
+// emulates the client, it operates on the client data hash and returns the raw responses of the authenticator along
+// with the CTAP2 status codes as errors, so it can emulate authenticators for load testing or kiosk scenarios where the
+// client is implemented by the application.
+//
+// Specification: §6. Authenticator API (https://fidoalliance.org/specs/fido-v2.1-ps-20210615/fido-client-to-authenticator-protocol-v2.1-ps-20210615.html#authenticator-api)
+package ctap2
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+)
+
+// Attestation statement formats supported by the Authenticator.
+const (
+	FormatNone   = "none"
+	FormatPacked = "packed"
+)
+
+// Authenticator is a software CTAP2 authenticator. The zero value is a usable authenticator which supports ES256,
+// RS256, and EdDSA credentials with the none attestation format, but neither discoverable credentials nor user
+// verification. The configuration must not be changed while the Authenticator is in use, however it is safe to
+// perform operations from multiple goroutines.
+type Authenticator struct {
+	// AAGUID of the authenticator.
+	AAGUID uuid.UUID
+
+	// Algorithms supported by the authenticator. The default is ES256, RS256, and EdDSA. The first of the credential
+	// parameters of the request which is supported is used, as the parameters are in the order of preference of the
+	// relying party.
+	Algorithms []webauthncose.COSEAlgorithmIdentifier
+
+	// Format of the attestation statement which must be FormatNone or FormatPacked. The default is FormatNone. The
+	// packed format uses self attestation.
+	Format string
+
+	// ResidentKeys enables discoverable credentials, which is the rk option of the authenticatorGetInfo response.
+	ResidentKeys bool
+
+	// UserVerification enables the built-in user verification, which is the uv option of the authenticatorGetInfo
+	// response.
+	UserVerification bool
+
+	// BackupEligible sets the backup eligibility and backup state flags of the authenticator data, emulating a synced
+	// passkey provider.
+	BackupEligible bool
+
+	// MaxCredentials is the number of discoverable credentials which can be stored. Zero means there is no limit.
+	MaxCredentials int
+
+	// Presence performs the test of user presence for the relying party and returns false if the user did not consent.
+	// The default is a user who always consents. It's called with the lock of the Authenticator held, so it must not
+	// call the Authenticator.
+	Presence func(rpID string) bool
+
+	mu          sync.Mutex
+	credentials []*Credential
+	next        *assertionState
+}
+
+// Options are the options of a request.
+type Options struct {
+	// ResidentKey is the rk option which requests a discoverable credential. It's only valid for MakeCredential.
+	ResidentKey bool
+
+	// UserVerification is the uv option which requests user verification by the authenticator.
+	UserVerification bool
+
+	// SkipUserPresence is the up option set to false, which requests an assertion without a test of user presence.
+	// It's only valid for GetAssertion.
+	SkipUserPresence bool
+}
+
+// MakeCredentialRequest is the request of the authenticatorMakeCredential operation.
+type MakeCredentialRequest struct {
+	ClientDataHash   []byte
+	RP               protocol.RelyingPartyEntity
+	User             protocol.UserEntity
+	PubKeyCredParams []protocol.CredentialParameter
+	ExcludeList      []protocol.CredentialDescriptor
+	Options          Options
+}
+
+// MakeCredentialResponse is the response of the authenticatorMakeCredential operation.
+type MakeCredentialResponse struct {
+	Format   string
+	AuthData []byte
+	AttStmt  map[string]interface{}
+
+	// Credential is the credential which was created.
+	Credential *Credential
+}
+
+// AttestationObject returns the CBOR encoded attestation object of the response.
+func (r *MakeCredentialResponse) AttestationObject() ([]byte, error) {
+	return webauthncbor.Marshal(struct {
+		Format       string                 `cbor:"fmt"`
+		AttStatement map[string]interface{} `cbor:"attStmt"`
+		AuthData     []byte                 `cbor:"authData"`
+	}{r.Format, r.AttStmt, r.AuthData})
+}
+
+// GetAssertionRequest is the request of the authenticatorGetAssertion operation.
+type GetAssertionRequest struct {
+	RPID           string
+	ClientDataHash []byte
+	AllowList      []protocol.CredentialDescriptor
+	Options        Options
+}
+
+// GetAssertionResponse is the response of the authenticatorGetAssertion and authenticatorGetNextAssertion operations.
+type GetAssertionResponse struct {
+	CredentialID []byte
+	AuthData     []byte
+	Signature    []byte
+
+	// UserHandle is the user handle of a discoverable credential. It's empty for other credentials.
+	UserHandle []byte
+
+	// NumberOfCredentials is the number of discoverable credentials of the relying party when the allow list is empty,
+	// in which case the remaining credentials can be used with GetNextAssertion. It's only set by GetAssertion.
+	NumberOfCredentials int
+}
+
+type assertionState struct {
+	request     GetAssertionRequest
+	credentials []*Credential
+}
+
+// Credentials returns the credentials created by the Authenticator.
+func (a *Authenticator) Credentials() []*Credential {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return append([]*Credential(nil), a.credentials...)
+}
+
+// MakeCredential performs the authenticatorMakeCredential operation.
+//
+// Specification: §6.1. authenticatorMakeCredential (https://fidoalliance.org/specs/fido-v2.1-ps-20210615/fido-client-to-authenticator-protocol-v2.1-ps-20210615.html#authenticatorMakeCredential)
+func (a *Authenticator) MakeCredential(request MakeCredentialRequest) (response *MakeCredentialResponse, err error) {
+	userHandle := UserHandle(request.User.ID)
+
+	if len(request.ClientDataHash) == 0 || request.RP.ID == "" || len(userHandle) == 0 || len(request.PubKeyCredParams) == 0 {
+		return nil, StatusMissingParameter
+	}
+
+	alg, err := a.algorithm(request.PubKeyCredParams)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case request.Options.ResidentKey && !a.ResidentKeys:
+		return nil, StatusUnsupportedOption
+	case request.Options.UserVerification && !a.UserVerification, request.Options.SkipUserPresence:
+		return nil, StatusInvalidOption
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, descriptor := range request.ExcludeList {
+		if a.find(request.RP.ID, descriptor.CredentialID) != nil {
+			a.presence(request.RP.ID)
+
+			return nil, StatusCredentialExcluded
+		}
+	}
+
+	replace := -1
+
+	if request.Options.ResidentKey {
+		discoverable := 0
+
+		for i, credential := range a.credentials {
+			if !credential.Discoverable {
+				continue
+			}
+
+			if credential.RPID == request.RP.ID && bytes.Equal(credential.UserHandle, userHandle) {
+				replace = i
+
+				continue
+			}
+
+			discoverable++
+		}
+
+		if a.MaxCredentials != 0 && discoverable >= a.MaxCredentials {
+			return nil, StatusKeyStoreFull
+		}
+	}
+
+	if !a.presence(request.RP.ID) {
+		return nil, StatusOperationDenied
+	}
+
+	credential, err := NewCredential(request.RP.ID, userHandle, alg)
+	if err != nil {
+		return nil, err
+	}
+
+	credential.Discoverable = request.Options.ResidentKey
+
+	publicKey, err := credential.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	authData := a.authenticatorData(credential.RPID, a.flags(true, request.Options.UserVerification)|protocol.FlagAttestedCredentialData, 0)
+
+	authData = append(authData, a.AAGUID[:]...)
+	authData = append(authData, byte(len(credential.ID)>>8), byte(len(credential.ID)))
+	authData = append(authData, credential.ID...)
+	authData = append(authData, publicKey...)
+
+	response = &MakeCredentialResponse{
+		Format:     a.format(),
+		AuthData:   authData,
+		Credential: credential,
+	}
+
+	switch response.Format {
+	case FormatNone:
+		response.AttStmt = map[string]interface{}{}
+	case FormatPacked:
+		signature, err := credential.Sign(append(append([]byte(nil), authData...), request.ClientDataHash...))
+		if err != nil {
+			return nil, err
+		}
+
+		response.AttStmt = map[string]interface{}{
+			"alg": int64(credential.Algorithm),
+			"sig": signature,
+		}
+	default:
+		return nil, fmt.Errorf("ctap2: unsupported attestation format '%s'", a.Format)
+	}
+
+	// A discoverable credential of the same user and relying party is overwritten.
+	if replace != -1 {
+		a.credentials = append(a.credentials[:replace], a.credentials[replace+1:]...)
+	}
+
+	a.credentials = append(a.credentials, credential)
+
+	return response, nil
+}
+
+// GetAssertion performs the authenticatorGetAssertion operation. When the allow list is empty the discoverable
+// credentials of the relying party are used, the most recently created first.
+//
+// Specification: §6.2. authenticatorGetAssertion (https://fidoalliance.org/specs/fido-v2.1-ps-20210615/fido-client-to-authenticator-protocol-v2.1-ps-20210615.html#authenticatorGetAssertion)
+func (a *Authenticator) GetAssertion(request GetAssertionRequest) (*GetAssertionResponse, error) {
+	if request.RPID == "" || len(request.ClientDataHash) == 0 {
+		return nil, StatusMissingParameter
+	}
+
+	if request.Options.ResidentKey || (request.Options.UserVerification && !a.UserVerification) {
+		return nil, StatusInvalidOption
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.next = nil
+
+	var credentials []*Credential
+
+	if len(request.AllowList) == 0 {
+		for i := len(a.credentials) - 1; i >= 0; i-- {
+			if a.credentials[i].Discoverable && a.credentials[i].RPID == request.RPID {
+				credentials = append(credentials, a.credentials[i])
+			}
+		}
+	} else {
+		for _, descriptor := range request.AllowList {
+			if credential := a.find(request.RPID, descriptor.CredentialID); credential != nil {
+				credentials = append(credentials, credential)
+
+				break
+			}
+		}
+	}
+
+	if len(credentials) == 0 {
+		return nil, StatusNoCredentials
+	}
+
+	if !request.Options.SkipUserPresence && !a.presence(request.RPID) {
+		return nil, StatusOperationDenied
+	}
+
+	response, err := a.assert(request, credentials[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(request.AllowList) == 0 {
+		response.NumberOfCredentials = len(credentials)
+
+		if len(credentials) > 1 {
+			a.next = &assertionState{request: request, credentials: credentials[1:]}
+		}
+	}
+
+	return response, nil
+}
+
+// GetNextAssertion performs the authenticatorGetNextAssertion operation which returns an assertion for the next
+// discoverable credential of the previous GetAssertion. StatusNotAllowed is returned when there are no more
+// credentials.
+//
+// Specification: §6.3. authenticatorGetNextAssertion (https://fidoalliance.org/specs/fido-v2.1-ps-20210615/fido-client-to-authenticator-protocol-v2.1-ps-20210615.html#authenticatorGetNextAssertion)
+func (a *Authenticator) GetNextAssertion() (*GetAssertionResponse, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.next == nil {
+		return nil, StatusNotAllowed
+	}
+
+	credential := a.next.credentials[0]
+	request := a.next.request
+
+	if a.next.credentials = a.next.credentials[1:]; len(a.next.credentials) == 0 {
+		a.next = nil
+	}
+
+	return a.assert(request, credential)
+}
+
+func (a *Authenticator) assert(request GetAssertionRequest, credential *Credential) (*GetAssertionResponse, error) {
+	credential.Counter++
+
+	authData := a.authenticatorData(credential.RPID, a.flags(!request.Options.SkipUserPresence, request.Options.UserVerification), credential.Counter)
+
+	signature, err := credential.Sign(append(authData, request.ClientDataHash...))
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetAssertionResponse{
+		CredentialID: credential.ID,
+		AuthData:     authData,
+		Signature:    signature,
+	}
+
+	if credential.Discoverable {
+		response.UserHandle = credential.UserHandle
+	}
+
+	return response, nil
+}
+
+func (a *Authenticator) find(rpID string, id []byte) *Credential {
+	for _, credential := range a.credentials {
+		if credential.RPID == rpID && bytes.Equal(credential.ID, id) {
+			return credential
+		}
+	}
+
+	return nil
+}
+
+func (a *Authenticator) presence(rpID string) bool {
+	if a.Presence == nil {
+		return true
+	}
+
+	return a.Presence(rpID)
+}
+
+func (a *Authenticator) algorithm(parameters []protocol.CredentialParameter) (webauthncose.COSEAlgorithmIdentifier, error) {
+	supported := a.Algorithms
+
+	if len(supported) == 0 {
+		supported = []webauthncose.COSEAlgorithmIdentifier{webauthncose.AlgES256, webauthncose.AlgRS256, webauthncose.AlgEdDSA}
+	}
+
+	for _, parameter := range parameters {
+		if parameter.Type != protocol.PublicKeyCredentialType {
+			continue
+		}
+
+		for _, alg := range supported {
+			if parameter.Algorithm == alg {
+				return alg, nil
+			}
+		}
+	}
+
+	return 0, StatusUnsupportedAlgorithm
+}
+
+func (a *Authenticator) format() string {
+	if a.Format == "" {
+		return FormatNone
+	}
+
+	return a.Format
+}
+
+func (a *Authenticator) flags(up, uv bool) (flags protocol.AuthenticatorFlags) {
+	if up {
+		flags |= protocol.FlagUserPresent
+	}
+
+	if uv {
+		flags |= protocol.FlagUserVerified
+	}
+
+	if a.BackupEligible {
+		flags |= protocol.FlagBackupEligible | protocol.FlagBackupState
+	}
+
+	return flags
+}
+
+func (a *Authenticator) authenticatorData(rpID string, flags protocol.AuthenticatorFlags, counter uint32) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+
+	data := make([]byte, 0, 128)
+
+	data = append(data, rpIDHash[:]...)
+	data = append(data, byte(flags))
+
+	data = append(data, 0, 0, 0, 0)
+
+	binary.BigEndian.PutUint32(data[len(data)-4:], counter)
+
+	return data
+}
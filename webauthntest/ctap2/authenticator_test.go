@@ -0,0 +1,261 @@
+package ctap2
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+)
+
+func makeCredentialRequest(user string, rk bool) MakeCredentialRequest {
+	clientDataHash := sha256.Sum256([]byte("client data"))
+
+	return MakeCredentialRequest{
+		ClientDataHash: clientDataHash[:],
+		RP:             protocol.RelyingPartyEntity{ID: "example.com"},
+		User:           protocol.UserEntity{ID: []byte(user)},
+		PubKeyCredParams: []protocol.CredentialParameter{
+			{Type: protocol.PublicKeyCredentialType, Algorithm: webauthncose.AlgES256},
+		},
+		Options: Options{ResidentKey: rk},
+	}
+}
+
+func getAssertionRequest(allow ...[]byte) GetAssertionRequest {
+	clientDataHash := sha256.Sum256([]byte("client data"))
+
+	request := GetAssertionRequest{
+		RPID:           "example.com",
+		ClientDataHash: clientDataHash[:],
+	}
+
+	for _, id := range allow {
+		request.AllowList = append(request.AllowList, protocol.CredentialDescriptor{Type: protocol.PublicKeyCredentialType, CredentialID: id})
+	}
+
+	return request
+}
+
+func TestAuthenticator_MakeCredentialGetAssertion(t *testing.T) {
+	testCases := []struct {
+		name   string
+		alg    webauthncose.COSEAlgorithmIdentifier
+		format string
+	}{
+		{"ShouldUseES256None", webauthncose.AlgES256, FormatNone},
+		{"ShouldUseRS256None", webauthncose.AlgRS256, FormatNone},
+		{"ShouldUseEdDSANone", webauthncose.AlgEdDSA, FormatNone},
+		{"ShouldUseES256Packed", webauthncose.AlgES256, FormatPacked},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if webauthncose.FIPSMode && !webauthncose.IsFIPSApproved(tc.alg) {
+				t.Skip("the algorithm is not FIPS approved")
+			}
+
+			authenticator := &Authenticator{Format: tc.format, UserVerification: true, BackupEligible: true}
+
+			request := makeCredentialRequest("user", false)
+			request.PubKeyCredParams = []protocol.CredentialParameter{{Type: protocol.PublicKeyCredentialType, Algorithm: tc.alg}}
+			request.Options.UserVerification = true
+
+			created, err := authenticator.MakeCredential(request)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.format, created.Format)
+			assert.Equal(t, tc.alg, created.Credential.Algorithm)
+
+			_, err = created.AttestationObject()
+			require.NoError(t, err)
+
+			var authData protocol.AuthenticatorData
+
+			require.NoError(t, authData.Unmarshal(created.AuthData))
+
+			assert.Equal(t, protocol.FlagUserPresent|protocol.FlagUserVerified|protocol.FlagBackupEligible|protocol.FlagBackupState|protocol.FlagAttestedCredentialData, authData.Flags)
+			assert.Equal(t, created.Credential.ID, authData.AttData.CredentialID)
+
+			key, err := webauthncose.ParsePublicKey(authData.AttData.CredentialPublicKey)
+			require.NoError(t, err)
+
+			assertion, err := authenticator.GetAssertion(getAssertionRequest([]byte("unknown"), created.Credential.ID))
+			require.NoError(t, err)
+
+			assert.Equal(t, created.Credential.ID, assertion.CredentialID)
+			assert.Empty(t, assertion.UserHandle)
+			assert.Equal(t, 0, assertion.NumberOfCredentials)
+
+			require.NoError(t, authData.Unmarshal(assertion.AuthData))
+
+			assert.Equal(t, uint32(1), authData.Counter)
+			assert.Equal(t, protocol.FlagUserPresent|protocol.FlagBackupEligible|protocol.FlagBackupState, authData.Flags)
+
+			valid, err := webauthncose.VerifySignature(key, append(assertion.AuthData, getAssertionRequest().ClientDataHash...), assertion.Signature)
+			require.NoError(t, err)
+			assert.True(t, valid)
+		})
+	}
+}
+
+func TestAuthenticator_DiscoverableCredentials(t *testing.T) {
+	authenticator := &Authenticator{ResidentKeys: true}
+
+	_, err := authenticator.MakeCredential(makeCredentialRequest("first", true))
+	require.NoError(t, err)
+
+	_, err = authenticator.MakeCredential(makeCredentialRequest("second", false))
+	require.NoError(t, err)
+
+	_, err = authenticator.MakeCredential(makeCredentialRequest("third", true))
+	require.NoError(t, err)
+
+	_, err = authenticator.MakeCredential(makeCredentialRequest("first", true))
+	require.NoError(t, err)
+
+	assert.Len(t, authenticator.Credentials(), 3)
+
+	assertion, err := authenticator.GetAssertion(getAssertionRequest())
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte("first"), assertion.UserHandle)
+	assert.Equal(t, 2, assertion.NumberOfCredentials)
+
+	assertion, err = authenticator.GetNextAssertion()
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte("third"), assertion.UserHandle)
+	assert.Equal(t, 0, assertion.NumberOfCredentials)
+
+	_, err = authenticator.GetNextAssertion()
+	assert.Equal(t, StatusNotAllowed, err)
+}
+
+func TestAuthenticator_Status(t *testing.T) {
+	existing := &Authenticator{}
+
+	created, err := existing.MakeCredential(makeCredentialRequest("user", false))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		have     func() error
+		expected Status
+	}{
+		{
+			"ShouldFailMissingClientDataHash",
+			func() error {
+				request := makeCredentialRequest("user", false)
+				request.ClientDataHash = nil
+
+				_, err := (&Authenticator{}).MakeCredential(request)
+
+				return err
+			},
+			StatusMissingParameter,
+		},
+		{
+			"ShouldFailUnsupportedAlgorithm",
+			func() error {
+				_, err := (&Authenticator{Algorithms: []webauthncose.COSEAlgorithmIdentifier{webauthncose.AlgEdDSA}}).MakeCredential(makeCredentialRequest("user", false))
+
+				return err
+			},
+			StatusUnsupportedAlgorithm,
+		},
+		{
+			"ShouldFailUnsupportedResidentKey",
+			func() error {
+				_, err := (&Authenticator{}).MakeCredential(makeCredentialRequest("user", true))
+
+				return err
+			},
+			StatusUnsupportedOption,
+		},
+		{
+			"ShouldFailUnsupportedUserVerification",
+			func() error {
+				request := makeCredentialRequest("user", false)
+				request.Options.UserVerification = true
+
+				_, err := (&Authenticator{}).MakeCredential(request)
+
+				return err
+			},
+			StatusInvalidOption,
+		},
+		{
+			"ShouldFailExcluded",
+			func() error {
+				request := makeCredentialRequest("user", false)
+				request.ExcludeList = []protocol.CredentialDescriptor{{Type: protocol.PublicKeyCredentialType, CredentialID: created.Credential.ID}}
+
+				_, err := existing.MakeCredential(request)
+
+				return err
+			},
+			StatusCredentialExcluded,
+		},
+		{
+			"ShouldFailKeyStoreFull",
+			func() error {
+				authenticator := &Authenticator{ResidentKeys: true, MaxCredentials: 1}
+
+				if _, err := authenticator.MakeCredential(makeCredentialRequest("first", true)); err != nil {
+					return err
+				}
+
+				_, err := authenticator.MakeCredential(makeCredentialRequest("second", true))
+
+				return err
+			},
+			StatusKeyStoreFull,
+		},
+		{
+			"ShouldFailPresenceDenied",
+			func() error {
+				authenticator := &Authenticator{Presence: func(rpID string) bool { return false }}
+
+				_, err := authenticator.MakeCredential(makeCredentialRequest("user", false))
+
+				return err
+			},
+			StatusOperationDenied,
+		},
+		{
+			"ShouldFailNoCredentials",
+			func() error {
+				_, err := existing.GetAssertion(getAssertionRequest([]byte("unknown")))
+
+				return err
+			},
+			StatusNoCredentials,
+		},
+		{
+			"ShouldFailNoDiscoverableCredentials",
+			func() error {
+				_, err := existing.GetAssertion(getAssertionRequest())
+
+				return err
+			},
+			StatusNoCredentials,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.have()
+
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+}
+
+func TestStatus_Error(t *testing.T) {
+	assert.EqualError(t, StatusNoCredentials, "ctap2: CTAP2_ERR_NO_CREDENTIALS (0x2E)")
+	assert.EqualError(t, Status(0x7F), "ctap2: 0x7F (0x7F)")
+}
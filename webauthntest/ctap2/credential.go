@@ -0,0 +1,137 @@
+package ctap2
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"math/big"
+	"strings"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+)
+
+// Credential is a credential source held by an authenticator: the private key and the metadata of a public key
+// credential.
+type Credential struct {
+	// ID of the credential.
+	ID []byte
+
+	// RPID is the relying party the credential is scoped to.
+	RPID string
+
+	// UserHandle of the user the credential was created for.
+	UserHandle []byte
+
+	// Algorithm of the credential.
+	Algorithm webauthncose.COSEAlgorithmIdentifier
+
+	// PrivateKey of the credential.
+	PrivateKey crypto.Signer
+
+	// Counter is the signature counter of the credential. It's incremented before each assertion, so it can be changed
+	// between assertions to test the handling of cloned authenticators.
+	Counter uint32
+
+	// Discoverable is true for a discoverable credential (resident key) which can be used without being allowed by the
+	// relying party.
+	Discoverable bool
+}
+
+// NewCredential returns a credential with a random 32 byte ID and a new private key for the algorithm which must be
+// ES256, RS256, or EdDSA. Otherwise StatusUnsupportedAlgorithm is returned.
+func NewCredential(rpID string, userHandle []byte, alg webauthncose.COSEAlgorithmIdentifier) (credential *Credential, err error) {
+	credential = &Credential{
+		ID:         make([]byte, 32),
+		RPID:       rpID,
+		UserHandle: userHandle,
+		Algorithm:  alg,
+	}
+
+	if _, err = rand.Read(credential.ID); err != nil {
+		return nil, err
+	}
+
+	switch alg {
+	case webauthncose.AlgES256:
+		credential.PrivateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case webauthncose.AlgRS256:
+		credential.PrivateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	case webauthncose.AlgEdDSA:
+		_, credential.PrivateKey, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, StatusUnsupportedAlgorithm
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return credential, nil
+}
+
+// PublicKey returns the public key of the credential encoded in the COSE_Key format.
+func (c *Credential) PublicKey() ([]byte, error) {
+	switch k := c.PrivateKey.Public().(type) {
+	case *ecdsa.PublicKey:
+		return webauthncbor.Marshal(map[int]interface{}{
+			1:  int64(webauthncose.EllipticKey),
+			3:  int64(c.Algorithm),
+			-1: int64(webauthncose.P256),
+			-2: k.X.FillBytes(make([]byte, 32)),
+			-3: k.Y.FillBytes(make([]byte, 32)),
+		})
+	case *rsa.PublicKey:
+		return webauthncbor.Marshal(map[int]interface{}{
+			1:  int64(webauthncose.RSAKey),
+			3:  int64(c.Algorithm),
+			-1: k.N.Bytes(),
+			-2: big.NewInt(int64(k.E)).FillBytes(make([]byte, 3)),
+		})
+	case ed25519.PublicKey:
+		return webauthncbor.Marshal(map[int]interface{}{
+			1:  int64(webauthncose.OctetKey),
+			3:  int64(c.Algorithm),
+			-1: int64(webauthncose.Ed25519),
+			-2: []byte(k),
+		})
+	default:
+		return nil, StatusUnsupportedAlgorithm
+	}
+}
+
+// Sign returns the signature of the data with the private key of the credential.
+func (c *Credential) Sign(data []byte) ([]byte, error) {
+	if c.Algorithm == webauthncose.AlgEdDSA {
+		return c.PrivateKey.Sign(rand.Reader, data, crypto.Hash(0))
+	}
+
+	digest := sha256.Sum256(data)
+
+	return c.PrivateKey.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+// UserHandle returns the user handle of the user entity ID. The ID is a base64url encoded string when the options were
+// decoded from JSON.
+func UserHandle(id interface{}) []byte {
+	switch value := id.(type) {
+	case protocol.URLEncodedBase64:
+		return value
+	case []byte:
+		return value
+	case string:
+		if decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "=")); err == nil {
+			return decoded
+		}
+
+		return []byte(value)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,69 @@
+package ctap2
+
+import (
+	"fmt"
+)
+
+// Status is a CTAP2 status code returned by the operations of the Authenticator as an error.
+//
+// Specification: §8.2. Status codes (https://fidoalliance.org/specs/fido-v2.1-ps-20210615/fido-client-to-authenticator-protocol-v2.1-ps-20210615.html#error-responses)
+type Status byte
+
+const (
+	// StatusInvalidParameter is CTAP1_ERR_INVALID_PARAMETER.
+	StatusInvalidParameter Status = 0x02
+
+	// StatusMissingParameter is CTAP2_ERR_MISSING_PARAMETER.
+	StatusMissingParameter Status = 0x14
+
+	// StatusCredentialExcluded is CTAP2_ERR_CREDENTIAL_EXCLUDED.
+	StatusCredentialExcluded Status = 0x19
+
+	// StatusUnsupportedAlgorithm is CTAP2_ERR_UNSUPPORTED_ALGORITHM.
+	StatusUnsupportedAlgorithm Status = 0x26
+
+	// StatusOperationDenied is CTAP2_ERR_OPERATION_DENIED.
+	StatusOperationDenied Status = 0x27
+
+	// StatusKeyStoreFull is CTAP2_ERR_KEY_STORE_FULL.
+	StatusKeyStoreFull Status = 0x28
+
+	// StatusUnsupportedOption is CTAP2_ERR_UNSUPPORTED_OPTION.
+	StatusUnsupportedOption Status = 0x2B
+
+	// StatusInvalidOption is CTAP2_ERR_INVALID_OPTION.
+	StatusInvalidOption Status = 0x2C
+
+	// StatusNoCredentials is CTAP2_ERR_NO_CREDENTIALS.
+	StatusNoCredentials Status = 0x2E
+
+	// StatusNotAllowed is CTAP2_ERR_NOT_ALLOWED.
+	StatusNotAllowed Status = 0x30
+)
+
+var statusNames = map[Status]string{
+	StatusInvalidParameter:     "CTAP1_ERR_INVALID_PARAMETER",
+	StatusMissingParameter:     "CTAP2_ERR_MISSING_PARAMETER",
+	StatusCredentialExcluded:   "CTAP2_ERR_CREDENTIAL_EXCLUDED",
+	StatusUnsupportedAlgorithm: "CTAP2_ERR_UNSUPPORTED_ALGORITHM",
+	StatusOperationDenied:      "CTAP2_ERR_OPERATION_DENIED",
+	StatusKeyStoreFull:         "CTAP2_ERR_KEY_STORE_FULL",
+	StatusUnsupportedOption:    "CTAP2_ERR_UNSUPPORTED_OPTION",
+	StatusInvalidOption:        "CTAP2_ERR_INVALID_OPTION",
+	StatusNoCredentials:        "CTAP2_ERR_NO_CREDENTIALS",
+	StatusNotAllowed:           "CTAP2_ERR_NOT_ALLOWED",
+}
+
+// String returns the name of the status code as it appears in the specification.
+func (s Status) String() string {
+	if name, ok := statusNames[s]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("0x%02X", byte(s))
+}
+
+// Error implements the error interface.
+func (s Status) Error() string {
+	return fmt.Sprintf("ctap2: %s (0x%02X)", s.String(), byte(s))
+}
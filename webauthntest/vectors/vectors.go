@@ -0,0 +1,130 @@
+// Package vectors provides known-good registration and authentication payloads captured from browsers, platform
+// authenticators and security keys, along with the values required to verify them. They're intended for use in
+// table-driven tests so every verifier is checked against real payloads.
+//
+// The package intentionally does not depend on the protocol package so that it can be used by its tests.
+package vectors
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Ceremony is the ceremony a Vector was captured from.
+type Ceremony string
+
+const (
+	// CeremonyRegistration is the ceremony of a Vector containing a credential creation response.
+	CeremonyRegistration Ceremony = "registration"
+
+	// CeremonyAuthentication is the ceremony of a Vector containing a credential assertion response.
+	CeremonyAuthentication Ceremony = "authentication"
+)
+
+// ErrNotFound is returned by Load when no Vector with the provided name exists.
+var ErrNotFound = errors.New("vectors: test vector not found")
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// Vector is a single known-good payload and the values it's expected to verify against.
+type Vector struct {
+	// Name uniquely identifies the Vector and matches the name of the fixture file.
+	Name string `json:"name"`
+
+	// Description describes the source of the payload.
+	Description string `json:"description"`
+
+	// Ceremony is the ceremony the payload was captured from.
+	Ceremony Ceremony `json:"ceremony"`
+
+	// RPID is the Relying Party ID the payload is scoped to.
+	RPID string `json:"rpId"`
+
+	// Origin is the origin of the collected client data.
+	Origin string `json:"origin"`
+
+	// Challenge is the base64url encoded challenge the payload was created for.
+	Challenge string `json:"challenge"`
+
+	// Format is the attestation statement format of a registration payload.
+	Format string `json:"format,omitempty"`
+
+	// AttestationType is the attestation type the attestation statement of a registration payload is expected to
+	// verify as, and is empty for the none format.
+	AttestationType string `json:"attestationType,omitempty"`
+
+	// CredentialPublicKey is the base64url encoded COSE public key of the credential which signed an authentication
+	// payload.
+	CredentialPublicKey string `json:"credentialPublicKey,omitempty"`
+
+	// Response is the JSON encoded credential response as sent by the client.
+	Response json.RawMessage `json:"response"`
+}
+
+// All returns every Vector sorted by name.
+func All() (vectors []Vector, err error) {
+	entries, err := fixtures.ReadDir("fixtures")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		var vector Vector
+
+		if vector, err = Load(strings.TrimSuffix(entry.Name(), ".json")); err != nil {
+			return nil, err
+		}
+
+		vectors = append(vectors, vector)
+	}
+
+	sort.Slice(vectors, func(i, j int) bool {
+		return vectors[i].Name < vectors[j].Name
+	})
+
+	return vectors, nil
+}
+
+// Registration returns every Vector of the registration ceremony sorted by name.
+func Registration() ([]Vector, error) {
+	return filter(CeremonyRegistration)
+}
+
+// Authentication returns every Vector of the authentication ceremony sorted by name.
+func Authentication() ([]Vector, error) {
+	return filter(CeremonyAuthentication)
+}
+
+// Load returns the Vector with the provided name.
+func Load(name string) (vector Vector, err error) {
+	raw, err := fixtures.ReadFile(path.Join("fixtures", name+".json"))
+	if err != nil {
+		return vector, ErrNotFound
+	}
+
+	if err = json.Unmarshal(raw, &vector); err != nil {
+		return vector, err
+	}
+
+	return vector, nil
+}
+
+func filter(ceremony Ceremony) (vectors []Vector, err error) {
+	all, err := All()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, vector := range all {
+		if vector.Ceremony == ceremony {
+			vectors = append(vectors, vector)
+		}
+	}
+
+	return vectors, nil
+}
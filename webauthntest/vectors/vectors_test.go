@@ -0,0 +1,69 @@
+package vectors
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAll(t *testing.T) {
+	all, err := All()
+	require.NoError(t, err)
+	require.NotEmpty(t, all)
+
+	registration, err := Registration()
+	require.NoError(t, err)
+
+	authentication, err := Authentication()
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, registration)
+	assert.NotEmpty(t, authentication)
+	assert.Equal(t, len(all), len(registration)+len(authentication))
+
+	for i, vector := range all {
+		t.Run(vector.Name, func(t *testing.T) {
+			if i > 0 {
+				assert.True(t, all[i-1].Name < vector.Name)
+			}
+
+			assert.NotEmpty(t, vector.Description)
+			assert.NotEmpty(t, vector.RPID)
+			assert.NotEmpty(t, vector.Origin)
+			assert.True(t, json.Valid(vector.Response))
+
+			_, err := base64.RawURLEncoding.DecodeString(vector.Challenge)
+			assert.NoError(t, err)
+
+			switch vector.Ceremony {
+			case CeremonyRegistration:
+				assert.NotEmpty(t, vector.Format)
+				assert.Empty(t, vector.CredentialPublicKey)
+			case CeremonyAuthentication:
+				assert.Empty(t, vector.Format)
+
+				_, err = base64.RawURLEncoding.DecodeString(vector.CredentialPublicKey)
+				assert.NoError(t, err)
+			default:
+				t.Errorf("unknown ceremony %q", vector.Ceremony)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	vector, err := Load("fido-u2f")
+	require.NoError(t, err)
+	assert.Equal(t, "fido-u2f", vector.Name)
+	assert.Equal(t, CeremonyRegistration, vector.Ceremony)
+	assert.Equal(t, "localhost", vector.RPID)
+
+	_, err = Load("unknown")
+	assert.Equal(t, ErrNotFound, err)
+
+	_, err = Load("../vectors")
+	assert.Equal(t, ErrNotFound, err)
+}
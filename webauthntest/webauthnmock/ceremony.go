@@ -0,0 +1,367 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package webauthnmock
+
+import (
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"net/http"
+	"sync"
+)
+
+// Ensure, that LoginCeremonyMock does implement webauthn.LoginCeremony.
+// If this is not the case, regenerate this file with moq.
+var _ webauthn.LoginCeremony = &LoginCeremonyMock{}
+
+// LoginCeremonyMock is a mock implementation of webauthn.LoginCeremony.
+//
+//	func TestSomethingThatUsesLoginCeremony(t *testing.T) {
+//
+//		// make and configure a mocked webauthn.LoginCeremony
+//		mockedLoginCeremony := &LoginCeremonyMock{
+//			BeginDiscoverableLoginFunc: func(opts ...webauthn.LoginOption) (*protocol.CredentialAssertion, *webauthn.SessionData, error) {
+//				panic("mock out the BeginDiscoverableLogin method")
+//			},
+//			BeginLoginFunc: func(user webauthn.User, opts ...webauthn.LoginOption) (*protocol.CredentialAssertion, *webauthn.SessionData, error) {
+//				panic("mock out the BeginLogin method")
+//			},
+//			FinishDiscoverableLoginFunc: func(handler webauthn.DiscoverableUserHandler, session webauthn.SessionData, response *http.Request) (*webauthn.Credential, error) {
+//				panic("mock out the FinishDiscoverableLogin method")
+//			},
+//			FinishLoginFunc: func(user webauthn.User, session webauthn.SessionData, response *http.Request) (*webauthn.Credential, error) {
+//				panic("mock out the FinishLogin method")
+//			},
+//		}
+//
+//		// use mockedLoginCeremony in code that requires webauthn.LoginCeremony
+//		// and then make assertions.
+//
+//	}
+type LoginCeremonyMock struct {
+	// BeginDiscoverableLoginFunc mocks the BeginDiscoverableLogin method.
+	BeginDiscoverableLoginFunc func(opts ...webauthn.LoginOption) (*protocol.CredentialAssertion, *webauthn.SessionData, error)
+
+	// BeginLoginFunc mocks the BeginLogin method.
+	BeginLoginFunc func(user webauthn.User, opts ...webauthn.LoginOption) (*protocol.CredentialAssertion, *webauthn.SessionData, error)
+
+	// FinishDiscoverableLoginFunc mocks the FinishDiscoverableLogin method.
+	FinishDiscoverableLoginFunc func(handler webauthn.DiscoverableUserHandler, session webauthn.SessionData, response *http.Request) (*webauthn.Credential, error)
+
+	// FinishLoginFunc mocks the FinishLogin method.
+	FinishLoginFunc func(user webauthn.User, session webauthn.SessionData, response *http.Request) (*webauthn.Credential, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// BeginDiscoverableLogin holds details about calls to the BeginDiscoverableLogin method.
+		BeginDiscoverableLogin []struct {
+			// Opts is the opts argument value.
+			Opts []webauthn.LoginOption
+		}
+		// BeginLogin holds details about calls to the BeginLogin method.
+		BeginLogin []struct {
+			// User is the user argument value.
+			User webauthn.User
+			// Opts is the opts argument value.
+			Opts []webauthn.LoginOption
+		}
+		// FinishDiscoverableLogin holds details about calls to the FinishDiscoverableLogin method.
+		FinishDiscoverableLogin []struct {
+			// Handler is the handler argument value.
+			Handler webauthn.DiscoverableUserHandler
+			// Session is the session argument value.
+			Session webauthn.SessionData
+			// Response is the response argument value.
+			Response *http.Request
+		}
+		// FinishLogin holds details about calls to the FinishLogin method.
+		FinishLogin []struct {
+			// User is the user argument value.
+			User webauthn.User
+			// Session is the session argument value.
+			Session webauthn.SessionData
+			// Response is the response argument value.
+			Response *http.Request
+		}
+	}
+	lockBeginDiscoverableLogin  sync.RWMutex
+	lockBeginLogin              sync.RWMutex
+	lockFinishDiscoverableLogin sync.RWMutex
+	lockFinishLogin             sync.RWMutex
+}
+
+// BeginDiscoverableLogin calls BeginDiscoverableLoginFunc.
+func (mock *LoginCeremonyMock) BeginDiscoverableLogin(opts ...webauthn.LoginOption) (*protocol.CredentialAssertion, *webauthn.SessionData, error) {
+	if mock.BeginDiscoverableLoginFunc == nil {
+		panic("LoginCeremonyMock.BeginDiscoverableLoginFunc: method is nil but LoginCeremony.BeginDiscoverableLogin was just called")
+	}
+	callInfo := struct {
+		Opts []webauthn.LoginOption
+	}{
+		Opts: opts,
+	}
+	mock.lockBeginDiscoverableLogin.Lock()
+	mock.calls.BeginDiscoverableLogin = append(mock.calls.BeginDiscoverableLogin, callInfo)
+	mock.lockBeginDiscoverableLogin.Unlock()
+	return mock.BeginDiscoverableLoginFunc(opts...)
+}
+
+// BeginDiscoverableLoginCalls gets all the calls that were made to BeginDiscoverableLogin.
+// Check the length with:
+//
+//	len(mockedLoginCeremony.BeginDiscoverableLoginCalls())
+func (mock *LoginCeremonyMock) BeginDiscoverableLoginCalls() []struct {
+	Opts []webauthn.LoginOption
+} {
+	var calls []struct {
+		Opts []webauthn.LoginOption
+	}
+	mock.lockBeginDiscoverableLogin.RLock()
+	calls = mock.calls.BeginDiscoverableLogin
+	mock.lockBeginDiscoverableLogin.RUnlock()
+	return calls
+}
+
+// BeginLogin calls BeginLoginFunc.
+func (mock *LoginCeremonyMock) BeginLogin(user webauthn.User, opts ...webauthn.LoginOption) (*protocol.CredentialAssertion, *webauthn.SessionData, error) {
+	if mock.BeginLoginFunc == nil {
+		panic("LoginCeremonyMock.BeginLoginFunc: method is nil but LoginCeremony.BeginLogin was just called")
+	}
+	callInfo := struct {
+		User webauthn.User
+		Opts []webauthn.LoginOption
+	}{
+		User: user,
+		Opts: opts,
+	}
+	mock.lockBeginLogin.Lock()
+	mock.calls.BeginLogin = append(mock.calls.BeginLogin, callInfo)
+	mock.lockBeginLogin.Unlock()
+	return mock.BeginLoginFunc(user, opts...)
+}
+
+// BeginLoginCalls gets all the calls that were made to BeginLogin.
+// Check the length with:
+//
+//	len(mockedLoginCeremony.BeginLoginCalls())
+func (mock *LoginCeremonyMock) BeginLoginCalls() []struct {
+	User webauthn.User
+	Opts []webauthn.LoginOption
+} {
+	var calls []struct {
+		User webauthn.User
+		Opts []webauthn.LoginOption
+	}
+	mock.lockBeginLogin.RLock()
+	calls = mock.calls.BeginLogin
+	mock.lockBeginLogin.RUnlock()
+	return calls
+}
+
+// FinishDiscoverableLogin calls FinishDiscoverableLoginFunc.
+func (mock *LoginCeremonyMock) FinishDiscoverableLogin(handler webauthn.DiscoverableUserHandler, session webauthn.SessionData, response *http.Request) (*webauthn.Credential, error) {
+	if mock.FinishDiscoverableLoginFunc == nil {
+		panic("LoginCeremonyMock.FinishDiscoverableLoginFunc: method is nil but LoginCeremony.FinishDiscoverableLogin was just called")
+	}
+	callInfo := struct {
+		Handler  webauthn.DiscoverableUserHandler
+		Session  webauthn.SessionData
+		Response *http.Request
+	}{
+		Handler:  handler,
+		Session:  session,
+		Response: response,
+	}
+	mock.lockFinishDiscoverableLogin.Lock()
+	mock.calls.FinishDiscoverableLogin = append(mock.calls.FinishDiscoverableLogin, callInfo)
+	mock.lockFinishDiscoverableLogin.Unlock()
+	return mock.FinishDiscoverableLoginFunc(handler, session, response)
+}
+
+// FinishDiscoverableLoginCalls gets all the calls that were made to FinishDiscoverableLogin.
+// Check the length with:
+//
+//	len(mockedLoginCeremony.FinishDiscoverableLoginCalls())
+func (mock *LoginCeremonyMock) FinishDiscoverableLoginCalls() []struct {
+	Handler  webauthn.DiscoverableUserHandler
+	Session  webauthn.SessionData
+	Response *http.Request
+} {
+	var calls []struct {
+		Handler  webauthn.DiscoverableUserHandler
+		Session  webauthn.SessionData
+		Response *http.Request
+	}
+	mock.lockFinishDiscoverableLogin.RLock()
+	calls = mock.calls.FinishDiscoverableLogin
+	mock.lockFinishDiscoverableLogin.RUnlock()
+	return calls
+}
+
+// FinishLogin calls FinishLoginFunc.
+func (mock *LoginCeremonyMock) FinishLogin(user webauthn.User, session webauthn.SessionData, response *http.Request) (*webauthn.Credential, error) {
+	if mock.FinishLoginFunc == nil {
+		panic("LoginCeremonyMock.FinishLoginFunc: method is nil but LoginCeremony.FinishLogin was just called")
+	}
+	callInfo := struct {
+		User     webauthn.User
+		Session  webauthn.SessionData
+		Response *http.Request
+	}{
+		User:     user,
+		Session:  session,
+		Response: response,
+	}
+	mock.lockFinishLogin.Lock()
+	mock.calls.FinishLogin = append(mock.calls.FinishLogin, callInfo)
+	mock.lockFinishLogin.Unlock()
+	return mock.FinishLoginFunc(user, session, response)
+}
+
+// FinishLoginCalls gets all the calls that were made to FinishLogin.
+// Check the length with:
+//
+//	len(mockedLoginCeremony.FinishLoginCalls())
+func (mock *LoginCeremonyMock) FinishLoginCalls() []struct {
+	User     webauthn.User
+	Session  webauthn.SessionData
+	Response *http.Request
+} {
+	var calls []struct {
+		User     webauthn.User
+		Session  webauthn.SessionData
+		Response *http.Request
+	}
+	mock.lockFinishLogin.RLock()
+	calls = mock.calls.FinishLogin
+	mock.lockFinishLogin.RUnlock()
+	return calls
+}
+
+// Ensure, that RegistrationCeremonyMock does implement webauthn.RegistrationCeremony.
+// If this is not the case, regenerate this file with moq.
+var _ webauthn.RegistrationCeremony = &RegistrationCeremonyMock{}
+
+// RegistrationCeremonyMock is a mock implementation of webauthn.RegistrationCeremony.
+//
+//	func TestSomethingThatUsesRegistrationCeremony(t *testing.T) {
+//
+//		// make and configure a mocked webauthn.RegistrationCeremony
+//		mockedRegistrationCeremony := &RegistrationCeremonyMock{
+//			BeginRegistrationFunc: func(user webauthn.User, opts ...webauthn.RegistrationOption) (*protocol.CredentialCreation, *webauthn.SessionData, error) {
+//				panic("mock out the BeginRegistration method")
+//			},
+//			FinishRegistrationFunc: func(user webauthn.User, session webauthn.SessionData, response *http.Request) (*webauthn.Credential, error) {
+//				panic("mock out the FinishRegistration method")
+//			},
+//		}
+//
+//		// use mockedRegistrationCeremony in code that requires webauthn.RegistrationCeremony
+//		// and then make assertions.
+//
+//	}
+type RegistrationCeremonyMock struct {
+	// BeginRegistrationFunc mocks the BeginRegistration method.
+	BeginRegistrationFunc func(user webauthn.User, opts ...webauthn.RegistrationOption) (*protocol.CredentialCreation, *webauthn.SessionData, error)
+
+	// FinishRegistrationFunc mocks the FinishRegistration method.
+	FinishRegistrationFunc func(user webauthn.User, session webauthn.SessionData, response *http.Request) (*webauthn.Credential, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// BeginRegistration holds details about calls to the BeginRegistration method.
+		BeginRegistration []struct {
+			// User is the user argument value.
+			User webauthn.User
+			// Opts is the opts argument value.
+			Opts []webauthn.RegistrationOption
+		}
+		// FinishRegistration holds details about calls to the FinishRegistration method.
+		FinishRegistration []struct {
+			// User is the user argument value.
+			User webauthn.User
+			// Session is the session argument value.
+			Session webauthn.SessionData
+			// Response is the response argument value.
+			Response *http.Request
+		}
+	}
+	lockBeginRegistration  sync.RWMutex
+	lockFinishRegistration sync.RWMutex
+}
+
+// BeginRegistration calls BeginRegistrationFunc.
+func (mock *RegistrationCeremonyMock) BeginRegistration(user webauthn.User, opts ...webauthn.RegistrationOption) (*protocol.CredentialCreation, *webauthn.SessionData, error) {
+	if mock.BeginRegistrationFunc == nil {
+		panic("RegistrationCeremonyMock.BeginRegistrationFunc: method is nil but RegistrationCeremony.BeginRegistration was just called")
+	}
+	callInfo := struct {
+		User webauthn.User
+		Opts []webauthn.RegistrationOption
+	}{
+		User: user,
+		Opts: opts,
+	}
+	mock.lockBeginRegistration.Lock()
+	mock.calls.BeginRegistration = append(mock.calls.BeginRegistration, callInfo)
+	mock.lockBeginRegistration.Unlock()
+	return mock.BeginRegistrationFunc(user, opts...)
+}
+
+// BeginRegistrationCalls gets all the calls that were made to BeginRegistration.
+// Check the length with:
+//
+//	len(mockedRegistrationCeremony.BeginRegistrationCalls())
+func (mock *RegistrationCeremonyMock) BeginRegistrationCalls() []struct {
+	User webauthn.User
+	Opts []webauthn.RegistrationOption
+} {
+	var calls []struct {
+		User webauthn.User
+		Opts []webauthn.RegistrationOption
+	}
+	mock.lockBeginRegistration.RLock()
+	calls = mock.calls.BeginRegistration
+	mock.lockBeginRegistration.RUnlock()
+	return calls
+}
+
+// FinishRegistration calls FinishRegistrationFunc.
+func (mock *RegistrationCeremonyMock) FinishRegistration(user webauthn.User, session webauthn.SessionData, response *http.Request) (*webauthn.Credential, error) {
+	if mock.FinishRegistrationFunc == nil {
+		panic("RegistrationCeremonyMock.FinishRegistrationFunc: method is nil but RegistrationCeremony.FinishRegistration was just called")
+	}
+	callInfo := struct {
+		User     webauthn.User
+		Session  webauthn.SessionData
+		Response *http.Request
+	}{
+		User:     user,
+		Session:  session,
+		Response: response,
+	}
+	mock.lockFinishRegistration.Lock()
+	mock.calls.FinishRegistration = append(mock.calls.FinishRegistration, callInfo)
+	mock.lockFinishRegistration.Unlock()
+	return mock.FinishRegistrationFunc(user, session, response)
+}
+
+// FinishRegistrationCalls gets all the calls that were made to FinishRegistration.
+// Check the length with:
+//
+//	len(mockedRegistrationCeremony.FinishRegistrationCalls())
+func (mock *RegistrationCeremonyMock) FinishRegistrationCalls() []struct {
+	User     webauthn.User
+	Session  webauthn.SessionData
+	Response *http.Request
+} {
+	var calls []struct {
+		User     webauthn.User
+		Session  webauthn.SessionData
+		Response *http.Request
+	}
+	mock.lockFinishRegistration.RLock()
+	calls = mock.calls.FinishRegistration
+	mock.lockFinishRegistration.RUnlock()
+	return calls
+}
@@ -0,0 +1,11 @@
+// Package webauthnmock provides mock implementations of the interfaces of the webauthn and metadata packages, so that
+// code which uses the ceremonies, the stores, or the metadata can be unit tested without real authenticator responses
+// or cryptography. Each mock has a function field per method which is called by the method, and records the calls.
+//
+// The mocks are generated with moq (https://github.com/matryer/moq) which generates mocks without any dependency.
+package webauthnmock
+
+//go:generate moq -out ceremony.go -pkg webauthnmock ../../webauthn LoginCeremony RegistrationCeremony
+//go:generate moq -out metadata.go -pkg webauthnmock ../../metadata Provider:MetadataProviderMock
+//go:generate moq -out store.go -pkg webauthnmock ../../webauthn SessionStore UserStore
+//go:generate moq -out user.go -pkg webauthnmock ../../webauthn User
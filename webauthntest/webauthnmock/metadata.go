@@ -0,0 +1,121 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package webauthnmock
+
+import (
+	"crypto/x509"
+	"github.com/go-webauthn/webauthn/metadata"
+	"github.com/google/uuid"
+	"sync"
+)
+
+// Ensure, that MetadataProviderMock does implement metadata.Provider.
+// If this is not the case, regenerate this file with moq.
+var _ metadata.Provider = &MetadataProviderMock{}
+
+// MetadataProviderMock is a mock implementation of metadata.Provider.
+//
+//	func TestSomethingThatUsesProvider(t *testing.T) {
+//
+//		// make and configure a mocked metadata.Provider
+//		mockedProvider := &MetadataProviderMock{
+//			LookupByAAGUIDFunc: func(aaguid uuid.UUID) (metadata.MetadataBLOBPayloadEntry, bool) {
+//				panic("mock out the LookupByAAGUID method")
+//			},
+//			LookupByCertificateFunc: func(cert *x509.Certificate) (metadata.MetadataBLOBPayloadEntry, bool) {
+//				panic("mock out the LookupByCertificate method")
+//			},
+//		}
+//
+//		// use mockedProvider in code that requires metadata.Provider
+//		// and then make assertions.
+//
+//	}
+type MetadataProviderMock struct {
+	// LookupByAAGUIDFunc mocks the LookupByAAGUID method.
+	LookupByAAGUIDFunc func(aaguid uuid.UUID) (metadata.MetadataBLOBPayloadEntry, bool)
+
+	// LookupByCertificateFunc mocks the LookupByCertificate method.
+	LookupByCertificateFunc func(cert *x509.Certificate) (metadata.MetadataBLOBPayloadEntry, bool)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// LookupByAAGUID holds details about calls to the LookupByAAGUID method.
+		LookupByAAGUID []struct {
+			// Aaguid is the aaguid argument value.
+			Aaguid uuid.UUID
+		}
+		// LookupByCertificate holds details about calls to the LookupByCertificate method.
+		LookupByCertificate []struct {
+			// Cert is the cert argument value.
+			Cert *x509.Certificate
+		}
+	}
+	lockLookupByAAGUID      sync.RWMutex
+	lockLookupByCertificate sync.RWMutex
+}
+
+// LookupByAAGUID calls LookupByAAGUIDFunc.
+func (mock *MetadataProviderMock) LookupByAAGUID(aaguid uuid.UUID) (metadata.MetadataBLOBPayloadEntry, bool) {
+	if mock.LookupByAAGUIDFunc == nil {
+		panic("MetadataProviderMock.LookupByAAGUIDFunc: method is nil but Provider.LookupByAAGUID was just called")
+	}
+	callInfo := struct {
+		Aaguid uuid.UUID
+	}{
+		Aaguid: aaguid,
+	}
+	mock.lockLookupByAAGUID.Lock()
+	mock.calls.LookupByAAGUID = append(mock.calls.LookupByAAGUID, callInfo)
+	mock.lockLookupByAAGUID.Unlock()
+	return mock.LookupByAAGUIDFunc(aaguid)
+}
+
+// LookupByAAGUIDCalls gets all the calls that were made to LookupByAAGUID.
+// Check the length with:
+//
+//	len(mockedProvider.LookupByAAGUIDCalls())
+func (mock *MetadataProviderMock) LookupByAAGUIDCalls() []struct {
+	Aaguid uuid.UUID
+} {
+	var calls []struct {
+		Aaguid uuid.UUID
+	}
+	mock.lockLookupByAAGUID.RLock()
+	calls = mock.calls.LookupByAAGUID
+	mock.lockLookupByAAGUID.RUnlock()
+	return calls
+}
+
+// LookupByCertificate calls LookupByCertificateFunc.
+func (mock *MetadataProviderMock) LookupByCertificate(cert *x509.Certificate) (metadata.MetadataBLOBPayloadEntry, bool) {
+	if mock.LookupByCertificateFunc == nil {
+		panic("MetadataProviderMock.LookupByCertificateFunc: method is nil but Provider.LookupByCertificate was just called")
+	}
+	callInfo := struct {
+		Cert *x509.Certificate
+	}{
+		Cert: cert,
+	}
+	mock.lockLookupByCertificate.Lock()
+	mock.calls.LookupByCertificate = append(mock.calls.LookupByCertificate, callInfo)
+	mock.lockLookupByCertificate.Unlock()
+	return mock.LookupByCertificateFunc(cert)
+}
+
+// LookupByCertificateCalls gets all the calls that were made to LookupByCertificate.
+// Check the length with:
+//
+//	len(mockedProvider.LookupByCertificateCalls())
+func (mock *MetadataProviderMock) LookupByCertificateCalls() []struct {
+	Cert *x509.Certificate
+} {
+	var calls []struct {
+		Cert *x509.Certificate
+	}
+	mock.lockLookupByCertificate.RLock()
+	calls = mock.calls.LookupByCertificate
+	mock.lockLookupByCertificate.RUnlock()
+	return calls
+}
@@ -0,0 +1,163 @@
+package webauthnmock_test
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-webauthn/webauthn/metadata"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/go-webauthn/webauthn/webauthntest"
+	"github.com/go-webauthn/webauthn/webauthntest/webauthnmock"
+)
+
+// finishRegistration is an example of application code which depends on the interfaces of the webauthn package.
+func finishRegistration(ctx context.Context, ceremony webauthn.RegistrationCeremony, users webauthn.UserStore, sessions webauthn.SessionStore, name, sessionID string, r *http.Request) error {
+	user, err := users.LoadUser(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	session, err := sessions.LoadSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if err = sessions.DeleteSession(ctx, sessionID); err != nil {
+		return err
+	}
+
+	credential, err := ceremony.FinishRegistration(user, session, r)
+	if err != nil {
+		return err
+	}
+
+	return users.SaveCredential(ctx, user, credential)
+}
+
+func TestMocks(t *testing.T) {
+	user := &webauthnmock.UserMock{
+		WebAuthnIDFunc: func() []byte {
+			return []byte("1234")
+		},
+	}
+
+	session := webauthn.SessionData{Challenge: "challenge", UserID: []byte("1234")}
+	credential := &webauthn.Credential{ID: []byte("credential")}
+
+	ceremony := &webauthnmock.RegistrationCeremonyMock{
+		FinishRegistrationFunc: func(user webauthn.User, session webauthn.SessionData, response *http.Request) (*webauthn.Credential, error) {
+			return credential, nil
+		},
+	}
+
+	users := &webauthnmock.UserStoreMock{
+		LoadUserFunc: func(ctx context.Context, name string) (webauthn.User, error) {
+			if name != "john" {
+				return nil, webauthn.ErrUserNotFound
+			}
+
+			return user, nil
+		},
+		SaveCredentialFunc: func(ctx context.Context, user webauthn.User, credential *webauthn.Credential) error {
+			return nil
+		},
+	}
+
+	sessions := &webauthnmock.SessionStoreMock{
+		LoadSessionFunc: func(ctx context.Context, id string) (webauthn.SessionData, error) {
+			return session, nil
+		},
+		DeleteSessionFunc: func(ctx context.Context, id string) error {
+			return nil
+		},
+	}
+
+	ctx := context.Background()
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+
+	require.NoError(t, finishRegistration(ctx, ceremony, users, sessions, "john", "id", r))
+
+	require.Len(t, ceremony.FinishRegistrationCalls(), 1)
+	assert.Equal(t, session, ceremony.FinishRegistrationCalls()[0].Session)
+	assert.Equal(t, r, ceremony.FinishRegistrationCalls()[0].Response)
+
+	require.Len(t, users.SaveCredentialCalls(), 1)
+	assert.Equal(t, credential, users.SaveCredentialCalls()[0].Credential)
+
+	require.Len(t, sessions.DeleteSessionCalls(), 1)
+	assert.Equal(t, "id", sessions.DeleteSessionCalls()[0].Id)
+
+	err := finishRegistration(ctx, ceremony, users, sessions, "jane", "id", r)
+	assert.True(t, errors.Is(err, webauthn.ErrUserNotFound))
+	assert.Len(t, ceremony.FinishRegistrationCalls(), 1)
+
+	handler := webauthn.DiscoverableUserHandlerFromStore(ctx, &webauthnmock.UserStoreMock{
+		LoadUserByHandleFunc: func(ctx context.Context, userHandle []byte) (webauthn.User, error) {
+			return user, nil
+		},
+	})
+
+	found, err := handler([]byte("credential"), []byte("1234"))
+	require.NoError(t, err)
+	assert.Equal(t, user, found)
+}
+
+func TestMetadataProviderMock(t *testing.T) {
+	aaguid := uuid.MustParse("7b0c0d9b-7e5c-4b7a-9d4f-2c1a0e6e9c11")
+
+	provider := &webauthnmock.MetadataProviderMock{
+		LookupByAAGUIDFunc: func(aaguid uuid.UUID) (metadata.MetadataBLOBPayloadEntry, bool) {
+			return metadata.MetadataBLOBPayloadEntry{}, false
+		},
+		LookupByCertificateFunc: func(cert *x509.Certificate) (metadata.MetadataBLOBPayloadEntry, bool) {
+			return metadata.MetadataBLOBPayloadEntry{}, false
+		},
+	}
+
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+		AttestationPolicy: protocol.AttestationPolicy{
+			RequireMetadata: true,
+			Metadata:        provider,
+		},
+	})
+	require.NoError(t, err)
+
+	user := &webauthnmock.UserMock{
+		WebAuthnIDFunc:          func() []byte { return []byte("1234") },
+		WebAuthnNameFunc:        func() string { return "john" },
+		WebAuthnDisplayNameFunc: func() string { return "John" },
+		WebAuthnCredentialsFunc: func() []webauthn.Credential { return nil },
+		WebAuthnIconFunc:        func() string { return "" },
+	}
+
+	authenticator := &webauthntest.Authenticator{AAGUID: aaguid, Format: webauthntest.FormatPacked}
+
+	creation, session, err := w.BeginRegistration(user)
+	require.NoError(t, err)
+
+	response, _, err := authenticator.CreateCredential(creation.Response, "https://example.com")
+	require.NoError(t, err)
+
+	r, err := webauthntest.NewRequest(response)
+	require.NoError(t, err)
+
+	_, err = w.FinishRegistration(user, *session, r)
+	require.Error(t, err)
+	assert.Equal(t, protocol.CodeAuthenticatorUnknown, err.(*protocol.Error).Code)
+
+	require.Len(t, provider.LookupByAAGUIDCalls(), 1)
+	assert.Equal(t, aaguid, provider.LookupByAAGUIDCalls()[0].Aaguid)
+	assert.Empty(t, provider.LookupByCertificateCalls())
+}
@@ -0,0 +1,366 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package webauthnmock
+
+import (
+	"context"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"sync"
+)
+
+// Ensure, that SessionStoreMock does implement webauthn.SessionStore.
+// If this is not the case, regenerate this file with moq.
+var _ webauthn.SessionStore = &SessionStoreMock{}
+
+// SessionStoreMock is a mock implementation of webauthn.SessionStore.
+//
+//	func TestSomethingThatUsesSessionStore(t *testing.T) {
+//
+//		// make and configure a mocked webauthn.SessionStore
+//		mockedSessionStore := &SessionStoreMock{
+//			DeleteSessionFunc: func(ctx context.Context, id string) error {
+//				panic("mock out the DeleteSession method")
+//			},
+//			LoadSessionFunc: func(ctx context.Context, id string) (webauthn.SessionData, error) {
+//				panic("mock out the LoadSession method")
+//			},
+//			SaveSessionFunc: func(ctx context.Context, id string, session webauthn.SessionData) error {
+//				panic("mock out the SaveSession method")
+//			},
+//		}
+//
+//		// use mockedSessionStore in code that requires webauthn.SessionStore
+//		// and then make assertions.
+//
+//	}
+type SessionStoreMock struct {
+	// DeleteSessionFunc mocks the DeleteSession method.
+	DeleteSessionFunc func(ctx context.Context, id string) error
+
+	// LoadSessionFunc mocks the LoadSession method.
+	LoadSessionFunc func(ctx context.Context, id string) (webauthn.SessionData, error)
+
+	// SaveSessionFunc mocks the SaveSession method.
+	SaveSessionFunc func(ctx context.Context, id string, session webauthn.SessionData) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// DeleteSession holds details about calls to the DeleteSession method.
+		DeleteSession []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Id is the id argument value.
+			Id string
+		}
+		// LoadSession holds details about calls to the LoadSession method.
+		LoadSession []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Id is the id argument value.
+			Id string
+		}
+		// SaveSession holds details about calls to the SaveSession method.
+		SaveSession []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Id is the id argument value.
+			Id string
+			// Session is the session argument value.
+			Session webauthn.SessionData
+		}
+	}
+	lockDeleteSession sync.RWMutex
+	lockLoadSession   sync.RWMutex
+	lockSaveSession   sync.RWMutex
+}
+
+// DeleteSession calls DeleteSessionFunc.
+func (mock *SessionStoreMock) DeleteSession(ctx context.Context, id string) error {
+	if mock.DeleteSessionFunc == nil {
+		panic("SessionStoreMock.DeleteSessionFunc: method is nil but SessionStore.DeleteSession was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Id  string
+	}{
+		Ctx: ctx,
+		Id:  id,
+	}
+	mock.lockDeleteSession.Lock()
+	mock.calls.DeleteSession = append(mock.calls.DeleteSession, callInfo)
+	mock.lockDeleteSession.Unlock()
+	return mock.DeleteSessionFunc(ctx, id)
+}
+
+// DeleteSessionCalls gets all the calls that were made to DeleteSession.
+// Check the length with:
+//
+//	len(mockedSessionStore.DeleteSessionCalls())
+func (mock *SessionStoreMock) DeleteSessionCalls() []struct {
+	Ctx context.Context
+	Id  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		Id  string
+	}
+	mock.lockDeleteSession.RLock()
+	calls = mock.calls.DeleteSession
+	mock.lockDeleteSession.RUnlock()
+	return calls
+}
+
+// LoadSession calls LoadSessionFunc.
+func (mock *SessionStoreMock) LoadSession(ctx context.Context, id string) (webauthn.SessionData, error) {
+	if mock.LoadSessionFunc == nil {
+		panic("SessionStoreMock.LoadSessionFunc: method is nil but SessionStore.LoadSession was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Id  string
+	}{
+		Ctx: ctx,
+		Id:  id,
+	}
+	mock.lockLoadSession.Lock()
+	mock.calls.LoadSession = append(mock.calls.LoadSession, callInfo)
+	mock.lockLoadSession.Unlock()
+	return mock.LoadSessionFunc(ctx, id)
+}
+
+// LoadSessionCalls gets all the calls that were made to LoadSession.
+// Check the length with:
+//
+//	len(mockedSessionStore.LoadSessionCalls())
+func (mock *SessionStoreMock) LoadSessionCalls() []struct {
+	Ctx context.Context
+	Id  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		Id  string
+	}
+	mock.lockLoadSession.RLock()
+	calls = mock.calls.LoadSession
+	mock.lockLoadSession.RUnlock()
+	return calls
+}
+
+// SaveSession calls SaveSessionFunc.
+func (mock *SessionStoreMock) SaveSession(ctx context.Context, id string, session webauthn.SessionData) error {
+	if mock.SaveSessionFunc == nil {
+		panic("SessionStoreMock.SaveSessionFunc: method is nil but SessionStore.SaveSession was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Id      string
+		Session webauthn.SessionData
+	}{
+		Ctx:     ctx,
+		Id:      id,
+		Session: session,
+	}
+	mock.lockSaveSession.Lock()
+	mock.calls.SaveSession = append(mock.calls.SaveSession, callInfo)
+	mock.lockSaveSession.Unlock()
+	return mock.SaveSessionFunc(ctx, id, session)
+}
+
+// SaveSessionCalls gets all the calls that were made to SaveSession.
+// Check the length with:
+//
+//	len(mockedSessionStore.SaveSessionCalls())
+func (mock *SessionStoreMock) SaveSessionCalls() []struct {
+	Ctx     context.Context
+	Id      string
+	Session webauthn.SessionData
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Id      string
+		Session webauthn.SessionData
+	}
+	mock.lockSaveSession.RLock()
+	calls = mock.calls.SaveSession
+	mock.lockSaveSession.RUnlock()
+	return calls
+}
+
+// Ensure, that UserStoreMock does implement webauthn.UserStore.
+// If this is not the case, regenerate this file with moq.
+var _ webauthn.UserStore = &UserStoreMock{}
+
+// UserStoreMock is a mock implementation of webauthn.UserStore.
+//
+//	func TestSomethingThatUsesUserStore(t *testing.T) {
+//
+//		// make and configure a mocked webauthn.UserStore
+//		mockedUserStore := &UserStoreMock{
+//			LoadUserFunc: func(ctx context.Context, name string) (webauthn.User, error) {
+//				panic("mock out the LoadUser method")
+//			},
+//			LoadUserByHandleFunc: func(ctx context.Context, userHandle []byte) (webauthn.User, error) {
+//				panic("mock out the LoadUserByHandle method")
+//			},
+//			SaveCredentialFunc: func(ctx context.Context, user webauthn.User, credential *webauthn.Credential) error {
+//				panic("mock out the SaveCredential method")
+//			},
+//		}
+//
+//		// use mockedUserStore in code that requires webauthn.UserStore
+//		// and then make assertions.
+//
+//	}
+type UserStoreMock struct {
+	// LoadUserFunc mocks the LoadUser method.
+	LoadUserFunc func(ctx context.Context, name string) (webauthn.User, error)
+
+	// LoadUserByHandleFunc mocks the LoadUserByHandle method.
+	LoadUserByHandleFunc func(ctx context.Context, userHandle []byte) (webauthn.User, error)
+
+	// SaveCredentialFunc mocks the SaveCredential method.
+	SaveCredentialFunc func(ctx context.Context, user webauthn.User, credential *webauthn.Credential) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// LoadUser holds details about calls to the LoadUser method.
+		LoadUser []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Name is the name argument value.
+			Name string
+		}
+		// LoadUserByHandle holds details about calls to the LoadUserByHandle method.
+		LoadUserByHandle []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// UserHandle is the userHandle argument value.
+			UserHandle []byte
+		}
+		// SaveCredential holds details about calls to the SaveCredential method.
+		SaveCredential []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// User is the user argument value.
+			User webauthn.User
+			// Credential is the credential argument value.
+			Credential *webauthn.Credential
+		}
+	}
+	lockLoadUser         sync.RWMutex
+	lockLoadUserByHandle sync.RWMutex
+	lockSaveCredential   sync.RWMutex
+}
+
+// LoadUser calls LoadUserFunc.
+func (mock *UserStoreMock) LoadUser(ctx context.Context, name string) (webauthn.User, error) {
+	if mock.LoadUserFunc == nil {
+		panic("UserStoreMock.LoadUserFunc: method is nil but UserStore.LoadUser was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Name string
+	}{
+		Ctx:  ctx,
+		Name: name,
+	}
+	mock.lockLoadUser.Lock()
+	mock.calls.LoadUser = append(mock.calls.LoadUser, callInfo)
+	mock.lockLoadUser.Unlock()
+	return mock.LoadUserFunc(ctx, name)
+}
+
+// LoadUserCalls gets all the calls that were made to LoadUser.
+// Check the length with:
+//
+//	len(mockedUserStore.LoadUserCalls())
+func (mock *UserStoreMock) LoadUserCalls() []struct {
+	Ctx  context.Context
+	Name string
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Name string
+	}
+	mock.lockLoadUser.RLock()
+	calls = mock.calls.LoadUser
+	mock.lockLoadUser.RUnlock()
+	return calls
+}
+
+// LoadUserByHandle calls LoadUserByHandleFunc.
+func (mock *UserStoreMock) LoadUserByHandle(ctx context.Context, userHandle []byte) (webauthn.User, error) {
+	if mock.LoadUserByHandleFunc == nil {
+		panic("UserStoreMock.LoadUserByHandleFunc: method is nil but UserStore.LoadUserByHandle was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		UserHandle []byte
+	}{
+		Ctx:        ctx,
+		UserHandle: userHandle,
+	}
+	mock.lockLoadUserByHandle.Lock()
+	mock.calls.LoadUserByHandle = append(mock.calls.LoadUserByHandle, callInfo)
+	mock.lockLoadUserByHandle.Unlock()
+	return mock.LoadUserByHandleFunc(ctx, userHandle)
+}
+
+// LoadUserByHandleCalls gets all the calls that were made to LoadUserByHandle.
+// Check the length with:
+//
+//	len(mockedUserStore.LoadUserByHandleCalls())
+func (mock *UserStoreMock) LoadUserByHandleCalls() []struct {
+	Ctx        context.Context
+	UserHandle []byte
+} {
+	var calls []struct {
+		Ctx        context.Context
+		UserHandle []byte
+	}
+	mock.lockLoadUserByHandle.RLock()
+	calls = mock.calls.LoadUserByHandle
+	mock.lockLoadUserByHandle.RUnlock()
+	return calls
+}
+
+// SaveCredential calls SaveCredentialFunc.
+func (mock *UserStoreMock) SaveCredential(ctx context.Context, user webauthn.User, credential *webauthn.Credential) error {
+	if mock.SaveCredentialFunc == nil {
+		panic("UserStoreMock.SaveCredentialFunc: method is nil but UserStore.SaveCredential was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		User       webauthn.User
+		Credential *webauthn.Credential
+	}{
+		Ctx:        ctx,
+		User:       user,
+		Credential: credential,
+	}
+	mock.lockSaveCredential.Lock()
+	mock.calls.SaveCredential = append(mock.calls.SaveCredential, callInfo)
+	mock.lockSaveCredential.Unlock()
+	return mock.SaveCredentialFunc(ctx, user, credential)
+}
+
+// SaveCredentialCalls gets all the calls that were made to SaveCredential.
+// Check the length with:
+//
+//	len(mockedUserStore.SaveCredentialCalls())
+func (mock *UserStoreMock) SaveCredentialCalls() []struct {
+	Ctx        context.Context
+	User       webauthn.User
+	Credential *webauthn.Credential
+} {
+	var calls []struct {
+		Ctx        context.Context
+		User       webauthn.User
+		Credential *webauthn.Credential
+	}
+	mock.lockSaveCredential.RLock()
+	calls = mock.calls.SaveCredential
+	mock.lockSaveCredential.RUnlock()
+	return calls
+}
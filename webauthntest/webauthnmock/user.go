@@ -0,0 +1,216 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package webauthnmock
+
+import (
+	"github.com/go-webauthn/webauthn/webauthn"
+	"sync"
+)
+
+// Ensure, that UserMock does implement webauthn.User.
+// If this is not the case, regenerate this file with moq.
+var _ webauthn.User = &UserMock{}
+
+// UserMock is a mock implementation of webauthn.User.
+//
+//	func TestSomethingThatUsesUser(t *testing.T) {
+//
+//		// make and configure a mocked webauthn.User
+//		mockedUser := &UserMock{
+//			WebAuthnCredentialsFunc: func() []webauthn.Credential {
+//				panic("mock out the WebAuthnCredentials method")
+//			},
+//			WebAuthnDisplayNameFunc: func() string {
+//				panic("mock out the WebAuthnDisplayName method")
+//			},
+//			WebAuthnIDFunc: func() []byte {
+//				panic("mock out the WebAuthnID method")
+//			},
+//			WebAuthnIconFunc: func() string {
+//				panic("mock out the WebAuthnIcon method")
+//			},
+//			WebAuthnNameFunc: func() string {
+//				panic("mock out the WebAuthnName method")
+//			},
+//		}
+//
+//		// use mockedUser in code that requires webauthn.User
+//		// and then make assertions.
+//
+//	}
+type UserMock struct {
+	// WebAuthnCredentialsFunc mocks the WebAuthnCredentials method.
+	WebAuthnCredentialsFunc func() []webauthn.Credential
+
+	// WebAuthnDisplayNameFunc mocks the WebAuthnDisplayName method.
+	WebAuthnDisplayNameFunc func() string
+
+	// WebAuthnIDFunc mocks the WebAuthnID method.
+	WebAuthnIDFunc func() []byte
+
+	// WebAuthnIconFunc mocks the WebAuthnIcon method.
+	WebAuthnIconFunc func() string
+
+	// WebAuthnNameFunc mocks the WebAuthnName method.
+	WebAuthnNameFunc func() string
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// WebAuthnCredentials holds details about calls to the WebAuthnCredentials method.
+		WebAuthnCredentials []struct {
+		}
+		// WebAuthnDisplayName holds details about calls to the WebAuthnDisplayName method.
+		WebAuthnDisplayName []struct {
+		}
+		// WebAuthnID holds details about calls to the WebAuthnID method.
+		WebAuthnID []struct {
+		}
+		// WebAuthnIcon holds details about calls to the WebAuthnIcon method.
+		WebAuthnIcon []struct {
+		}
+		// WebAuthnName holds details about calls to the WebAuthnName method.
+		WebAuthnName []struct {
+		}
+	}
+	lockWebAuthnCredentials sync.RWMutex
+	lockWebAuthnDisplayName sync.RWMutex
+	lockWebAuthnID          sync.RWMutex
+	lockWebAuthnIcon        sync.RWMutex
+	lockWebAuthnName        sync.RWMutex
+}
+
+// WebAuthnCredentials calls WebAuthnCredentialsFunc.
+func (mock *UserMock) WebAuthnCredentials() []webauthn.Credential {
+	if mock.WebAuthnCredentialsFunc == nil {
+		panic("UserMock.WebAuthnCredentialsFunc: method is nil but User.WebAuthnCredentials was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockWebAuthnCredentials.Lock()
+	mock.calls.WebAuthnCredentials = append(mock.calls.WebAuthnCredentials, callInfo)
+	mock.lockWebAuthnCredentials.Unlock()
+	return mock.WebAuthnCredentialsFunc()
+}
+
+// WebAuthnCredentialsCalls gets all the calls that were made to WebAuthnCredentials.
+// Check the length with:
+//
+//	len(mockedUser.WebAuthnCredentialsCalls())
+func (mock *UserMock) WebAuthnCredentialsCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockWebAuthnCredentials.RLock()
+	calls = mock.calls.WebAuthnCredentials
+	mock.lockWebAuthnCredentials.RUnlock()
+	return calls
+}
+
+// WebAuthnDisplayName calls WebAuthnDisplayNameFunc.
+func (mock *UserMock) WebAuthnDisplayName() string {
+	if mock.WebAuthnDisplayNameFunc == nil {
+		panic("UserMock.WebAuthnDisplayNameFunc: method is nil but User.WebAuthnDisplayName was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockWebAuthnDisplayName.Lock()
+	mock.calls.WebAuthnDisplayName = append(mock.calls.WebAuthnDisplayName, callInfo)
+	mock.lockWebAuthnDisplayName.Unlock()
+	return mock.WebAuthnDisplayNameFunc()
+}
+
+// WebAuthnDisplayNameCalls gets all the calls that were made to WebAuthnDisplayName.
+// Check the length with:
+//
+//	len(mockedUser.WebAuthnDisplayNameCalls())
+func (mock *UserMock) WebAuthnDisplayNameCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockWebAuthnDisplayName.RLock()
+	calls = mock.calls.WebAuthnDisplayName
+	mock.lockWebAuthnDisplayName.RUnlock()
+	return calls
+}
+
+// WebAuthnID calls WebAuthnIDFunc.
+func (mock *UserMock) WebAuthnID() []byte {
+	if mock.WebAuthnIDFunc == nil {
+		panic("UserMock.WebAuthnIDFunc: method is nil but User.WebAuthnID was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockWebAuthnID.Lock()
+	mock.calls.WebAuthnID = append(mock.calls.WebAuthnID, callInfo)
+	mock.lockWebAuthnID.Unlock()
+	return mock.WebAuthnIDFunc()
+}
+
+// WebAuthnIDCalls gets all the calls that were made to WebAuthnID.
+// Check the length with:
+//
+//	len(mockedUser.WebAuthnIDCalls())
+func (mock *UserMock) WebAuthnIDCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockWebAuthnID.RLock()
+	calls = mock.calls.WebAuthnID
+	mock.lockWebAuthnID.RUnlock()
+	return calls
+}
+
+// WebAuthnIcon calls WebAuthnIconFunc.
+func (mock *UserMock) WebAuthnIcon() string {
+	if mock.WebAuthnIconFunc == nil {
+		panic("UserMock.WebAuthnIconFunc: method is nil but User.WebAuthnIcon was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockWebAuthnIcon.Lock()
+	mock.calls.WebAuthnIcon = append(mock.calls.WebAuthnIcon, callInfo)
+	mock.lockWebAuthnIcon.Unlock()
+	return mock.WebAuthnIconFunc()
+}
+
+// WebAuthnIconCalls gets all the calls that were made to WebAuthnIcon.
+// Check the length with:
+//
+//	len(mockedUser.WebAuthnIconCalls())
+func (mock *UserMock) WebAuthnIconCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockWebAuthnIcon.RLock()
+	calls = mock.calls.WebAuthnIcon
+	mock.lockWebAuthnIcon.RUnlock()
+	return calls
+}
+
+// WebAuthnName calls WebAuthnNameFunc.
+func (mock *UserMock) WebAuthnName() string {
+	if mock.WebAuthnNameFunc == nil {
+		panic("UserMock.WebAuthnNameFunc: method is nil but User.WebAuthnName was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockWebAuthnName.Lock()
+	mock.calls.WebAuthnName = append(mock.calls.WebAuthnName, callInfo)
+	mock.lockWebAuthnName.Unlock()
+	return mock.WebAuthnNameFunc()
+}
+
+// WebAuthnNameCalls gets all the calls that were made to WebAuthnName.
+// Check the length with:
+//
+//	len(mockedUser.WebAuthnNameCalls())
+func (mock *UserMock) WebAuthnNameCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockWebAuthnName.RLock()
+	calls = mock.calls.WebAuthnName
+	mock.lockWebAuthnName.RUnlock()
+	return calls
+}